@@ -0,0 +1,118 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+const (
+	pidFileName       = "twitchpoint.pid"
+	backgroundLogFile = "logs/background.log"
+	// attachTimeout bounds how long attach waits for the background
+	// instance to shut down cleanly.
+	attachTimeout = 15 * time.Second
+)
+
+func pidFilePath(cfg *config.Config) string {
+	return filepath.Join(filepath.Dir(cfg.Path()), pidFileName)
+}
+
+// detachToBackground hands farming over to a headless copy of this
+// binary running in its own session, so the shell gets its terminal
+// back. This process's farmer is stopped first so the two never farm
+// at the same time; session counters restart, today's totals
+// (history.db) carry over.
+func detachToBackground(f *farmer.Farmer, cfg *config.Config) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(backgroundLogFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	f.Stop()
+
+	cmd := exec.Command(exe, "--headless", "--background", "--config", cfg.Path())
+	cmd.Stdout = out
+	cmd.Stderr = out
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setsid: true}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	fmt.Printf("TwitchPoint keeps farming in the background (pid %d, output in %s).\n", cmd.Process.Pid, backgroundLogFile)
+	fmt.Println("Reattach with: twitchpoint attach")
+	return cmd.Process.Release()
+}
+
+// writePidFile records this process as the background instance and
+// returns a func that removes the file again.
+func writePidFile(cfg *config.Config) func() {
+	path := pidFilePath(cfg)
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())+"\n"), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not write %s: %v\n", path, err)
+		return func() {}
+	}
+	return func() { os.Remove(path) }
+}
+
+// attachBackground stops the background instance (if one is running)
+// and waits for it to exit, so this process can take over with the TUI.
+func attachBackground(cfg *config.Config) error {
+	path := pidFilePath(cfg)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		fmt.Println("No background instance running — starting normally.")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || !isTwitchpoint(pid) {
+		// Left behind by a crash; the PID may belong to something else now.
+		os.Remove(path)
+		fmt.Println("No background instance running — starting normally.")
+		return nil
+	}
+
+	proc, _ := os.FindProcess(pid) // always succeeds on Unix
+	fmt.Printf("Taking over from the background instance (pid %d)...\n", pid)
+	if err := proc.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("stop pid %d: %w", pid, err)
+	}
+	for deadline := time.Now().Add(attachTimeout); time.Now().Before(deadline); time.Sleep(200 * time.Millisecond) {
+		if proc.Signal(syscall.Signal(0)) != nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("background instance (pid %d) did not stop within %v", pid, attachTimeout)
+}
+
+// isTwitchpoint reports whether pid is alive and, where /proc tells us,
+// running the same executable as this process.
+func isTwitchpoint(pid int) bool {
+	proc, _ := os.FindProcess(pid)
+	if proc.Signal(syscall.Signal(0)) != nil {
+		return false
+	}
+	target, err := os.Readlink(fmt.Sprintf("/proc/%d/exe", pid))
+	if err != nil {
+		return true // no /proc (macOS): trust the pid file
+	}
+	self, err := os.Executable()
+	return err != nil || target == self
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// On Windows 'q' hides the console to the tray instead (ui_windows.go),
+// so there is no background instance to write a pid file for or attach to.
+
+func writePidFile(cfg *config.Config) func() { return func() {} }
+
+func attachBackground(cfg *config.Config) error {
+	fmt.Println("attach is not needed on Windows — use the tray icon's Show Console.")
+	return nil
+}
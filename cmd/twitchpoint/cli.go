@@ -0,0 +1,404 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/remote"
+	"github.com/miwi/twitchpoint/internal/twitch"
+	"github.com/miwi/twitchpoint/internal/web"
+)
+
+// cliCommands are the subcommands that script against a farmer: the
+// read commands and pause/resume talk to a running instance through its
+// web API, login and channel edits also work without one.
+var cliCommands = map[string]func(cli *cliEnv, args []string) error{
+	"status":   cmdStatus,
+	"channels": cmdChannels,
+	"drops":    cmdDrops,
+	"login":    cmdLogin,
+	"pause":    cmdPause,
+	"resume":   cmdPause,
+}
+
+// cliEnv is what a subcommand gets: where the instance is, the local
+// config (for commands that fall back to editing it) and the output
+// format.
+type cliEnv struct {
+	name       string
+	configPath string
+	url        string
+	webToken   string
+	json       bool
+}
+
+// runCLI runs subcommand name and exits with 1 if it fails.
+func runCLI(name string, args []string, configPath string) {
+	cli := &cliEnv{name: name, configPath: configPath}
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.StringVar(&cli.configPath, "config", configPath, "Path to config file: .json, .yaml/.yml or .toml (default: config.json)")
+	fs.StringVar(&cli.url, "url", "", "Web API of the running instance (default: web_bind:web_port from the config)")
+	fs.StringVar(&cli.webToken, "web-token", os.Getenv("TWITCHPOINT_WEB_TOKEN"), "web_token of the instance (default $TWITCHPOINT_WEB_TOKEN, then the config's)")
+	fs.BoolVar(&cli.json, "json", false, "Print the raw API response as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: twitchpoint %s [flags]\n", cliUsage[name])
+		fs.PrintDefaults()
+	}
+
+	if err := cliCommands[name](cli, parseInterleaved(fs, args)); err != nil {
+		if errors.Is(err, errUsage) {
+			fs.Usage()
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "%s: %v\n", name, err)
+		os.Exit(1)
+	}
+}
+
+var cliUsage = map[string]string{
+	"status":   "status",
+	"channels": "channels [list | add <channel> | remove <channel>]",
+	"drops":    "drops",
+	"login":    "login",
+	"pause":    "pause",
+	"resume":   "resume",
+}
+
+var errUsage = errors.New("usage")
+
+// parseInterleaved parses args with fs, allowing flags after positional
+// arguments ("channels add foo --json"). Returns the positional ones.
+// Everything after "--" is positional, even if it looks like a flag.
+func parseInterleaved(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		rest := fs.Args()
+		if len(rest) == 0 {
+			return positional
+		}
+		if len(rest) < len(args) && args[len(args)-len(rest)-1] == "--" {
+			return append(positional, rest...)
+		}
+		positional = append(positional, rest[0])
+		args = rest[1:]
+	}
+}
+
+// loadConfig loads the local config the commands fall back on.
+func (cli *cliEnv) loadConfig() (*config.Config, error) {
+	cfg, err := config.Load(cli.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if err := twitch.SetProxy(cfg.GetProxyURL()); err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	return cfg, nil
+}
+
+// api returns a client for the running instance: --url, or the local
+// config's web server (a 0.0.0.0 bind is reached over loopback).
+func (cli *cliEnv) api() (*remote.API, error) {
+	base, token := cli.url, cli.webToken
+	if base == "" || token == "" {
+		if cfg, err := config.Load(cli.configPath); err == nil {
+			if base == "" {
+				bind := cfg.GetWebBind()
+				if bind == "" || bind == "0.0.0.0" || bind == "::" {
+					bind = "127.0.0.1"
+				}
+				port := cfg.GetWebPort()
+				if port <= 0 {
+					port = 8080
+				}
+				base = fmt.Sprintf("%s:%d", bind, port)
+			}
+			if token == "" {
+				token = cfg.GetWebToken()
+			}
+		}
+	}
+	if base == "" {
+		base = "127.0.0.1:8080"
+	}
+	return remote.NewAPI(base, token)
+}
+
+// get fetches path from the running instance into out and, with --json,
+// prints it as is. Reports whether the caller should still print it.
+func (cli *cliEnv) get(path string, out interface{}) (bool, error) {
+	api, err := cli.api()
+	if err != nil {
+		return false, err
+	}
+	if err := api.Do(http.MethodGet, path, nil, out); err != nil {
+		return false, describeAPIError(api, err)
+	}
+	if cli.json {
+		return false, printJSON(out)
+	}
+	return true, nil
+}
+
+// unreachable reports whether err means no instance is listening, as
+// opposed to one rejecting the request or not answering in time (the
+// config must not be edited under a running farmer).
+func unreachable(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+func describeAPIError(api *remote.API, err error) error {
+	if unreachable(err) {
+		return fmt.Errorf("no twitchpoint instance at %s (is it running with the web server enabled? see --url): %w", api.Base(), err)
+	}
+	return err
+}
+
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+// cmdStatus prints the farmer's totals. GET /api/stats
+func cmdStatus(cli *cliEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var s web.StatsResponse
+	if show, err := cli.get("/api/stats", &s); !show {
+		return err
+	}
+	state := "farming"
+	if s.Paused {
+		state = "paused"
+	}
+	fmt.Printf("TwitchPoint Farmer v%s — %s (%s), up %s\n", s.Version, s.User, state, s.Uptime)
+	fmt.Printf("Points:   %d earned, %d spent, %d/h\n", s.TotalPoints, s.TotalSpent, s.PointsPerHour)
+	fmt.Printf("Claims:   %d bonus, %d moments\n", s.TotalClaims, s.TotalMoments)
+	fmt.Printf("Channels: %d online, %d watching, %d total (capacity %d)\n", s.ChannelsOnline, s.ChannelsWatching, s.ChannelsTotal, s.ChannelCapacity)
+	fmt.Printf("Drops:    %d active\n", s.ActiveDrops)
+	return nil
+}
+
+// cmdChannels lists, adds or removes channels. Adding and removing go
+// through the running instance when there is one (taking effect right
+// away) and edit the config otherwise.
+func cmdChannels(cli *cliEnv, args []string) error {
+	if len(args) == 0 {
+		args = []string{"list"}
+	}
+	switch {
+	case args[0] == "list" && len(args) == 1:
+		return listChannels(cli)
+	case args[0] == "add" && len(args) == 2:
+		return liveOrOffline(cli, http.MethodPost, "/api/channels", map[string]string{"login": args[1]}, func(cfg *config.Config) error {
+			return addChannelToConfig(cfg, args[1])
+		})
+	case args[0] == "remove" && len(args) == 2:
+		return liveOrOffline(cli, http.MethodDelete, "/api/channels/"+url.PathEscape(strings.ToLower(args[1])), nil, func(cfg *config.Config) error {
+			return removeChannelFromConfig(cfg, args[1])
+		})
+	}
+	return errUsage
+}
+
+func listChannels(cli *cliEnv) error {
+	var chans []web.ChannelResponse
+	if show, err := cli.get("/api/channels", &chans); !show {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHANNEL\tPRIO\tSTATUS\tGAME\tBALANCE\tEARNED\tPTS/H")
+	for _, ch := range chans {
+		status := "offline"
+		switch {
+		case ch.IsWatching:
+			status = "watching"
+		case ch.IsOnline:
+			status = "online"
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%s\t%s\t%d\t%d\t%d\n", ch.Login, ch.Priority, status, ch.GameName, ch.Balance, ch.Earned, ch.PointsPerHour)
+	}
+	return tw.Flush()
+}
+
+// liveOrOffline sends a change to the running instance, or applies it
+// to the config with offline when none answers.
+func liveOrOffline(cli *cliEnv, method, path string, body interface{}, offline func(cfg *config.Config) error) error {
+	api, err := cli.api()
+	if err != nil {
+		return err
+	}
+	var resp map[string]string
+	err = api.Do(method, path, body, &resp)
+	if unreachable(err) {
+		cfg, err := cli.loadConfig()
+		if err != nil {
+			return err
+		}
+		return offline(cfg)
+	}
+	if err != nil {
+		return err
+	}
+	if cli.json {
+		return printJSON(resp)
+	}
+	verb := "Added"
+	if method == http.MethodDelete {
+		verb = "Removed"
+	}
+	fmt.Printf("%s channel %s\n", verb, resp["login"])
+	if resp["warning"] != "" {
+		fmt.Printf("Warning: %s\n", resp["warning"])
+	}
+	return nil
+}
+
+// cmdDrops lists the drops being farmed. GET /api/drops
+func cmdDrops(cli *cliEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	var rows []drops.ActiveDrop
+	if show, err := cli.get("/api/drops", &rows); !show {
+		return err
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GAME\tDROP\tCHANNEL\tPROGRESS\tENDS")
+	for _, d := range rows {
+		progress := fmt.Sprintf("%d/%d min (%d%%)", d.Progress, d.Required, d.Percent)
+		if d.IsClaimed {
+			progress = "claimed"
+		}
+		channel := d.ChannelLogin
+		if channel == "" {
+			channel = "-"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", d.GameName, d.DropName, channel, progress, d.EndAt.Local().Format("2006-01-02 15:04"))
+	}
+	return tw.Flush()
+}
+
+// cmdLogin runs the device-code login and saves the token to the
+// config. A running instance picks it up on restart.
+func cmdLogin(cli *cliEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	cfg, err := cli.loadConfig()
+	if err != nil {
+		return err
+	}
+	return loginToConfig(cfg)
+}
+
+// cmdPause pauses or resumes all farming on the running instance.
+// POST /api/pause, POST /api/resume
+func cmdPause(cli *cliEnv, args []string) error {
+	if len(args) != 0 {
+		return errUsage
+	}
+	api, err := cli.api()
+	if err != nil {
+		return err
+	}
+	var resp struct {
+		Paused bool `json:"paused"`
+	}
+	if err := api.Do(http.MethodPost, "/api/"+cli.name, nil, &resp); err != nil {
+		return describeAPIError(api, err)
+	}
+	if cli.json {
+		return printJSON(resp)
+	}
+	if resp.Paused {
+		fmt.Println("Farming paused")
+	} else {
+		fmt.Println("Farming resumed")
+	}
+	return nil
+}
+
+// addChannelToConfig validates ref (login, twitch.tv URL or channel ID)
+// against Twitch and persists BOTH login and ID. Storing the ID is
+// critical: it makes future startups robust against the streamer
+// renaming or briefly unpublishing the channel (rename-detection in
+// addChannelFromEntry only works when the ID is known).
+func addChannelToConfig(cfg *config.Config, ref string) error {
+	token := cfg.GetAuthToken()
+	if token == "" {
+		return fmt.Errorf("cannot add channel: no auth token. Run twitchpoint login first or set --token")
+	}
+	info, err := twitch.NewGQLClient(token).ResolveChannel(ref)
+	if err != nil {
+		return fmt.Errorf("channel %q not found on Twitch: %w", ref, err)
+	}
+	added := cfg.AddChannel(info.Login)
+	cfg.SetChannelID(info.Login, info.ID)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	if added {
+		fmt.Printf("Added channel %s (id=%s) to config\n", info.Login, info.ID)
+	} else {
+		fmt.Printf("Channel %s already in config — ID refreshed to %s\n", info.Login, info.ID)
+	}
+	return nil
+}
+
+// removeChannelFromConfig drops a channel from the config. Useful for
+// cleaning up legacy entries (added before ID-tracking, where the
+// streamer has since renamed/deleted) that fail to resolve at startup.
+// Matches the case-insensitive login lookup the registry uses; takes
+// effect on next start.
+func removeChannelFromConfig(cfg *config.Config, login string) error {
+	channel := strings.ToLower(login)
+	if !cfg.RemoveChannel(channel) {
+		fmt.Printf("Channel %q not found in config\n", channel)
+		return nil
+	}
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("Removed channel %q from config\n", channel)
+	return nil
+}
+
+// loginToConfig runs the Device Code OAuth flow and saves the token.
+func loginToConfig(cfg *config.Config) error {
+	token, err := twitch.DeviceCodeLogin(twitch.TVClientID)
+	if err != nil {
+		return fmt.Errorf("login failed: %w", err)
+	}
+	cfg.SetAuthToken(token)
+	if err := cfg.Save(); err != nil {
+		return fmt.Errorf("failed to save config: %w", err)
+	}
+	fmt.Printf("Token saved to %s\n", cfg.Path())
+	return nil
+}
+
+// fatalIf exits like log.Fatalf when err is set, with the message
+// capitalized the way main's other fatal errors are.
+func fatalIf(err error) {
+	if err == nil {
+		return
+	}
+	msg := err.Error()
+	log.Fatal(strings.ToUpper(msg[:1]) + msg[1:])
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestParseInterleaved(t *testing.T) {
+	for _, tc := range []struct {
+		args       []string
+		positional []string
+		json       bool
+	}{
+		{[]string{"channels", "add", "foo", "--json"}, []string{"channels", "add", "foo"}, true},
+		{[]string{"--json", "list"}, []string{"list"}, true},
+		{[]string{"remove", "--", "-odd-login"}, []string{"remove", "-odd-login"}, false},
+		{[]string{"remove", "--", "--json"}, []string{"remove", "--json"}, false},
+		{[]string{"--json", "--", "x", "--", "y"}, []string{"x", "--", "y"}, true},
+		{nil, nil, false},
+	} {
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		asJSON := fs.Bool("json", false, "")
+		got := parseInterleaved(fs, tc.args)
+		if !reflect.DeepEqual(got, tc.positional) || *asJSON != tc.json {
+			t.Errorf("parseInterleaved(%q) = %q, json=%v; want %q, json=%v", tc.args, got, *asJSON, tc.positional, tc.json)
+		}
+	}
+}
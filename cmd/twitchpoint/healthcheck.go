@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// healthcheckTimeout bounds --healthcheck; Docker's HEALTHCHECK default
+// timeout is 30s.
+const healthcheckTimeout = 10 * time.Second
+
+// runHealthcheck queries /healthz on the instance the config points at
+// (web_bind:web_port, loopback for a 0.0.0.0 bind), prints each check
+// and exits 0 when the farmer is healthy, 1 when it isn't or can't be
+// reached — for Docker's HEALTHCHECK and systemd's ExecStartPost or
+// watchdog scripts.
+func runHealthcheck(configPath string) {
+	cli := &cliEnv{configPath: configPath}
+	api, err := cli.api()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: %v\n", err)
+		os.Exit(1)
+	}
+	client := &http.Client{Timeout: healthcheckTimeout}
+	resp, err := client.Get(api.Base() + "/healthz")
+	if err != nil {
+		if unreachable(err) {
+			err = fmt.Errorf("no twitchpoint instance at %s (is it running with the web server enabled?): %w", api.Base(), err)
+		}
+		fmt.Fprintf(os.Stderr, "healthcheck: %v\n", err)
+		os.Exit(1)
+	}
+	var h farmer.Healthz
+	err = json.NewDecoder(resp.Body).Decode(&h)
+	resp.Body.Close()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "healthcheck: %s/healthz: %s\n", api.Base(), resp.Status)
+		os.Exit(1)
+	}
+
+	verdict := "healthy"
+	if !h.OK || resp.StatusCode != http.StatusOK {
+		verdict = "unhealthy"
+	}
+	fmt.Printf("%s (%s)\n", verdict, h.State)
+	for _, c := range h.Checks {
+		mark := "ok  "
+		if !c.OK {
+			mark = "FAIL"
+		}
+		fmt.Printf("  %s %-7s %s\n", mark, c.Name, c.Detail)
+	}
+	if verdict != "healthy" {
+		os.Exit(1)
+	}
+}
@@ -3,15 +3,25 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/signal"
+	"runtime"
 	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata" // time_zone works on Windows and in minimal Docker images
 
 	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/desktop"
 	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/notify"
+	"github.com/miwi/twitchpoint/internal/remote"
+	"github.com/miwi/twitchpoint/internal/systemd"
+	"github.com/miwi/twitchpoint/internal/telegram"
 	"github.com/miwi/twitchpoint/internal/twitch"
+	"github.com/miwi/twitchpoint/internal/ui"
 	"github.com/miwi/twitchpoint/internal/web"
 )
 
@@ -19,20 +29,78 @@ const appVersion = "2.1.6"
 
 func main() {
 	web.Version = appVersion
-	configPath := flag.String("config", "", "Path to config file (default: config.json)")
-	addChannel := flag.String("add-channel", "", "Add a channel to config (validates against Twitch + persists ID) and exit")
-	removeChannel := flag.String("remove-channel", "", "Remove a channel from config and exit (use for renamed/deleted channels)")
+	configPath := flag.String("config", "", "Path to config file: .json, .yaml/.yml or .toml (default: config.json)")
+	importFollows := flag.Bool("import-follows", false, "Add every channel you follow to config and exit")
+	importLiveOnly := flag.Bool("import-live-only", false, "With --import-follows: only channels live right now")
+	importMinDays := flag.Int("import-min-days", 0, "With --import-follows: only channels followed for at least this many days")
 	setToken := flag.String("token", "", "Set auth token and exit")
-	forceLogin := flag.Bool("login", false, "Force re-login via Twitch Device Code OAuth")
 	headless := flag.Bool("headless", false, "Run without TUI (for Docker/servers)")
+	background := flag.Bool("background", false, "Internal: headless instance started by quit_to_background")
+	service := flag.String("service", "", "Windows: install, uninstall or run as a Windows service")
+	connect := flag.String("connect", "", "Run the TUI against the web API of an instance elsewhere (same as attach <url>)")
+	webToken := flag.String("web-token", os.Getenv("TWITCHPOINT_WEB_TOKEN"), "With --connect: web_token of the remote instance (default $TWITCHPOINT_WEB_TOKEN)")
+	healthcheck := flag.Bool("healthcheck", false, "Query the running instance's /healthz and exit 0 if healthy, 1 if not (for Docker/systemd)")
+	installUnit := flag.Bool("install-systemd-unit", false, "Linux: write a systemd user service running this binary headless with this config, and exit")
 	flag.Parse()
 
+	// --healthcheck: liveness probe against the instance this config
+	// runs, before anything else touches the config or Twitch.
+	if *healthcheck {
+		runHealthcheck(*configPath)
+		return
+	}
+
+	// --service install|uninstall|run (Windows only): separate from the
+	// tray autostart, runs at boot without a logged-in session.
+	if *service != "" {
+		runService(*service, *configPath)
+		return
+	}
+
+	// "twitchpoint attach <url>" / --connect <url>: terminal UI for a
+	// remote headless instance. Needs no local config or token.
+	if *connect != "" {
+		connectRemote(*connect, *webToken)
+		return
+	}
+	if flag.Arg(0) == "attach" && flag.NArg() > 1 {
+		runRemote(flag.Args()[1:])
+		return
+	}
+
+	// "twitchpoint replay <capture>": run a pubsub_record_file capture
+	// through the PubSub parsers offline and print the events.
+	if flag.Arg(0) == "replay" {
+		runReplay(flag.Args()[1:])
+		return
+	}
+
+	// "twitchpoint status|channels|drops|login|pause|resume": one-shot
+	// commands for scripts, mostly against a running instance's web API.
+	if _, ok := cliCommands[flag.Arg(0)]; ok {
+		runCLI(flag.Arg(0), flag.Args()[1:], *configPath)
+		return
+	}
+
 	// Load config
 	cfg, err := config.Load(*configPath)
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// Before anything talks to Twitch — --import-follows included.
+	if err := twitch.SetProxy(cfg.GetProxyURL()); err != nil {
+		log.Fatalf("Invalid proxy_url: %v", err)
+	}
+	if err := cfg.ApplyTimeZone(); err != nil {
+		log.Fatalf("Invalid time_zone: %v", err)
+	}
+
+	if *installUnit {
+		fatalIf(installSystemdUnit(cfg))
+		return
+	}
+
 	// Handle --token flag (manual token override)
 	if *setToken != "" {
 		cfg.SetAuthToken(*setToken)
@@ -43,69 +111,44 @@ func main() {
 		return
 	}
 
-	// Handle --add-channel flag — validates the channel exists on Twitch
-	// and persists BOTH login and ID. Storing the ID is critical: it
-	// makes future startups robust against the streamer renaming or
-	// briefly unpublishing the channel (rename-detection in
-	// addChannelFromEntry only works when the ID is known).
-	if *addChannel != "" {
-		channel := strings.ToLower(*addChannel)
+	// Handle --import-follows — bulk "channels add" for the account's
+	// follows. The follow query already carries the IDs.
+	if *importFollows {
 		token := cfg.GetAuthToken()
 		if token == "" {
-			log.Fatalf("Cannot add channel: no auth token. Run --login first or set --token.")
+			log.Fatalf("Cannot import follows: no auth token. Run \"twitchpoint login\" first or set --token.")
 		}
-		gql := twitch.NewGQLClient(token)
-		info, err := gql.GetChannelInfo(channel)
+		follows, err := twitch.NewGQLClient(token).GetFollowedChannels()
 		if err != nil {
-			log.Fatalf("Channel %q not found on Twitch: %v", channel, err)
-		}
-		added := cfg.AddChannel(info.Login)
-		cfg.SetChannelID(info.Login, info.ID)
-		if err := cfg.Save(); err != nil {
-			log.Fatalf("Failed to save config: %v", err)
+			log.Fatalf("Could not fetch followed channels: %v", err)
 		}
-		if added {
-			fmt.Printf("Added channel %s (id=%s) to config\n", info.Login, info.ID)
-		} else {
-			fmt.Printf("Channel %s already in config — ID refreshed to %s\n", info.Login, info.ID)
+		matched := twitch.FilterFollows(follows, *importLiveOnly, time.Duration(*importMinDays)*24*time.Hour, time.Now())
+		added := 0
+		for _, fc := range matched {
+			if cfg.AddChannel(fc.Login) {
+				cfg.SetChannelID(fc.Login, fc.ID)
+				fmt.Printf("Added channel %s (id=%s)\n", strings.ToLower(fc.Login), fc.ID)
+				added++
+			}
 		}
-		return
-	}
-
-	// Handle --remove-channel flag — drops a channel from config. Useful
-	// for cleaning up legacy entries (added before ID-tracking, where
-	// the streamer has since renamed/deleted) that fail to resolve at
-	// startup. Matches the case-insensitive login lookup the registry
-	// uses; takes effect on next start.
-	if *removeChannel != "" {
-		channel := strings.ToLower(*removeChannel)
-		if cfg.RemoveChannel(channel) {
+		if added > 0 {
 			if err := cfg.Save(); err != nil {
 				log.Fatalf("Failed to save config: %v", err)
 			}
-			fmt.Printf("Removed channel %q from config\n", channel)
-		} else {
-			fmt.Printf("Channel %q not found in config\n", channel)
 		}
+		fmt.Printf("Imported %d channels (%d followed, %d matched the filter)\n", added, len(follows), len(matched))
 		return
 	}
 
-	// Handle --login flag (force re-login via Device Code OAuth)
-	if *forceLogin {
-		token, err := twitch.DeviceCodeLogin(twitch.TVClientID)
-		if err != nil {
-			log.Fatalf("Login failed: %v", err)
-		}
-		cfg.SetAuthToken(token)
-		if err := cfg.Save(); err != nil {
-			log.Fatalf("Failed to save config: %v", err)
-		}
-		fmt.Printf("Token saved to %s\n", cfg.Path())
-		return
+	// Without a terminal (docker run without -t, a systemd unit, output
+	// piped to a file) there's nothing to draw the TUI on: run headless.
+	if !*headless && runtime.GOOS != "windows" && !stdoutIsTerminal() {
+		*headless = true
 	}
 
-	// First-run setup: auto-login via Device Code OAuth if no token
-	if cfg.GetAuthToken() == "" {
+	// First-run setup: auto-login via Device Code OAuth if no token.
+	// Headless instances log in through the web UI instead (below).
+	if cfg.GetAuthToken() == "" && !*headless {
 		fmt.Println("Welcome to TwitchPoint Farmer!")
 		fmt.Println()
 		token, err := twitch.DeviceCodeLogin(twitch.TVClientID)
@@ -120,11 +163,40 @@ func main() {
 		fmt.Println()
 	}
 
-	// Start farmer
+	// "twitchpoint attach": take over from a quit_to_background instance.
+	if flag.Arg(0) == "attach" {
+		if err := attachBackground(cfg); err != nil {
+			log.Fatalf("Attach failed: %v", err)
+		}
+	}
+
+	// Start farmer. Headless mode brings the web UI up first so a
+	// missing or rejected token can be replaced from the browser.
 	f := farmer.New(cfg, appVersion)
+	var webServer *web.Server
+	if *headless {
+		// The event log goes to stdout in log_format, for docker logs
+		// and journald (a background instance has the debug log).
+		if !*background {
+			f.SetLogOutput(os.Stdout)
+		}
+		webServer = startHeadlessWeb(f, cfg)
+		if cfg.GetAuthToken() == "" && !waitWebLogin(f, cfg, webServer) {
+			return
+		}
+	}
 	if err := f.Start(); err != nil {
 		// Auth failure likely means token was created with old Client-ID — auto re-login
-		if strings.Contains(err.Error(), "auth validation failed") {
+		if *headless && strings.Contains(err.Error(), "auth validation failed") {
+			f.Warnf("Warning: auth token expired or invalid")
+			if !waitWebLogin(f, cfg, webServer) {
+				return
+			}
+			if err := f.Start(); err != nil {
+				fmt.Fprintf(os.Stderr, "Failed to start farmer: %v\n", err)
+				os.Exit(1)
+			}
+		} else if strings.Contains(err.Error(), "auth validation failed") {
 			fmt.Println("Auth token expired or invalid (Client-ID changed). Re-authenticating...")
 			fmt.Println()
 			token, err := twitch.DeviceCodeLogin(twitch.TVClientID)
@@ -153,9 +225,22 @@ func main() {
 	}
 	defer f.Stop()
 
+	if tg, ok := cfg.GetTelegram(); ok {
+		telegram.New(tg, f).Start()
+	}
+	notify.Start(cfg.GetNotifiers(), f)
+	if d, ok := cfg.GetDesktop(); ok {
+		desktop.Start(d, f)
+	}
+	systemd.Start(f)
+
+	if *background {
+		defer writePidFile(cfg)()
+	}
+
 	// Headless mode: no TUI, just farmer + web server + wait for signal
 	if *headless {
-		runHeadless(f, cfg)
+		runHeadless(f, webServer)
 		return
 	}
 
@@ -163,8 +248,74 @@ func main() {
 	runUI(f, cfg)
 }
 
-func runHeadless(f *farmer.Farmer, cfg *config.Config) {
-	// Start web server (force-enable in headless mode)
+// runRemote runs the terminal UI as a thin client of the instance at
+// args[0]. Quitting only disconnects; the remote farmer keeps running.
+func runRemote(args []string) {
+	fs := flag.NewFlagSet("attach", flag.ExitOnError)
+	webToken := fs.String("web-token", os.Getenv("TWITCHPOINT_WEB_TOKEN"), "web_token of the remote instance (default $TWITCHPOINT_WEB_TOKEN)")
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: twitchpoint attach <url> [--web-token TOKEN]")
+		fs.PrintDefaults()
+	}
+	// Accept the flag before or after the URL.
+	url := args[0]
+	if strings.HasPrefix(url, "-") {
+		fs.Parse(args)
+		url = fs.Arg(0)
+	} else {
+		fs.Parse(args[1:])
+	}
+	if url == "" {
+		fs.Usage()
+		os.Exit(2)
+	}
+	connectRemote(url, *webToken)
+}
+
+// connectRemote runs the TUI on the instance at url until the user
+// quits.
+func connectRemote(url, webToken string) {
+	client, err := remote.Dial(url, webToken)
+	if err != nil {
+		log.Fatalf("Attach failed: %v", err)
+	}
+	log.SetOutput(io.Discard)
+	if err := ui.RunBackend(client); err != nil {
+		fmt.Fprintf(os.Stderr, "UI error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// runReplay prints the events a PubSub capture produces, one per line,
+// without logging in or connecting anywhere.
+func runReplay(args []string) {
+	if len(args) != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: twitchpoint replay <capture.jsonl>")
+		os.Exit(2)
+	}
+	file, err := os.Open(args[0])
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+	defer file.Close()
+
+	n, err := twitch.ReplayPubSub(file, func(ev twitch.FarmerEvent) {
+		if ev.Type == twitch.EventError {
+			fmt.Printf("%-16s %v\n", ev.Type, ev.Data)
+			return
+		}
+		fmt.Printf("%-16s channel=%s %+v\n", ev.Type, ev.ChannelID, ev.Data)
+	})
+	fmt.Printf("%d messages replayed\n", n)
+	if err != nil {
+		log.Fatalf("Replay failed: %v", err)
+	}
+}
+
+// startHeadlessWeb starts the web server for headless mode (force-enabled:
+// it's the only UI there is). It answers the login endpoint right away
+// and the rest of the API once the farmer has started.
+func startHeadlessWeb(f *farmer.Farmer, cfg *config.Config) *web.Server {
 	port := cfg.GetWebPort()
 	if port <= 0 {
 		port = 8080
@@ -172,18 +323,51 @@ func runHeadless(f *farmer.Farmer, cfg *config.Config) {
 	webServer := web.New(f, port)
 	go func() {
 		if err := webServer.Start(); err != nil {
-			fmt.Fprintf(os.Stderr, "Web server error: %v\n", err)
+			f.Errorf("Error: web server: %v", err)
 		}
 	}()
+	return webServer
+}
 
-	fmt.Printf("TwitchPoint Farmer v%s (headless)\n", appVersion)
-	fmt.Printf("Web UI: http://%s\n", webServer.Addr())
-	fmt.Println("Press Ctrl+C to stop.")
+// waitWebLogin blocks until a device-code login started from the web UI
+// has saved a token. Returns false if interrupted first.
+func waitWebLogin(f *farmer.Farmer, cfg *config.Config, webServer *web.Server) bool {
+	loggedIn := f.AwaitLogin()
+	f.Logf("Not logged in — open the Web UI at http://%s (or POST /api/auth/device) to log in with Twitch", webServer.Addr())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+	select {
+	case <-loggedIn:
+		f.Logf("Token saved to %s", cfg.Path())
+		return true
+	case <-sig:
+		return false
+	}
+}
+
+// runHeadless blocks until SIGINT or SIGTERM. Its lines go through the
+// event log, so stdout stays in log_format.
+func runHeadless(f *farmer.Farmer, webServer *web.Server) {
+	f.Logf("TwitchPoint Farmer v%s (headless)", appVersion)
+	if f.Config().GetWebAPIOnly() {
+		f.Logf("Web API: http://%s/api/ (web_api_only)", webServer.Addr())
+	} else {
+		f.Logf("Web UI: http://%s", webServer.Addr())
+	}
 
 	// Block until SIGINT or SIGTERM
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
 	<-sig
 
-	fmt.Println("\nShutting down...")
+	f.Logf("Shutting down...")
+}
+
+// stdoutIsTerminal reports whether stdout is a terminal the TUI can
+// draw on.
+func stdoutIsTerminal() bool {
+	fi, err := os.Stdout.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
 }
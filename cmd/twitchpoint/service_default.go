@@ -0,0 +1,15 @@
+//go:build !windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+// runService: --service is Windows-only. Use systemd, launchd or
+// --headless under a supervisor elsewhere.
+func runService(cmd, configPath string) {
+	fmt.Fprintln(os.Stderr, "--service is only available on Windows; use --headless under systemd/launchd instead")
+	os.Exit(2)
+}
@@ -0,0 +1,224 @@
+//go:build windows
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+	"golang.org/x/sys/windows/svc/eventlog"
+	"golang.org/x/sys/windows/svc/mgr"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/notify"
+	"github.com/miwi/twitchpoint/internal/telegram"
+	"github.com/miwi/twitchpoint/internal/twitch"
+	"github.com/miwi/twitchpoint/internal/web"
+)
+
+const (
+	serviceName        = "TwitchPoint"
+	serviceDisplayName = "TwitchPoint Farmer"
+	serviceDescription = "Farms Twitch channel points and drops in the background."
+	// serviceStopTimeout bounds how long uninstall waits for a running
+	// service to stop before deleting it.
+	serviceStopTimeout = 15 * time.Second
+)
+
+// runService handles --service install|uninstall|run. install and
+// uninstall need an elevated prompt; run is what the service control
+// manager starts.
+func runService(cmd, configPath string) {
+	var err error
+	switch cmd {
+	case "install":
+		err = installService(configPath)
+	case "uninstall":
+		err = uninstallService()
+	case "run":
+		err = svc.Run(serviceName, &farmService{configPath: configPath})
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown --service command %q (want install, uninstall or run)\n", cmd)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Service %s failed: %v\n", cmd, err)
+		os.Exit(1)
+	}
+}
+
+// installService registers this executable as an auto-start service
+// running "--service run", with the config path pinned as an absolute
+// path — services start in System32.
+func installService(configPath string) error {
+	exe, err := os.Executable()
+	if err != nil {
+		return err
+	}
+	args := []string{"--service", "run"}
+	if configPath != "" {
+		abs, err := filepath.Abs(configPath)
+		if err != nil {
+			return err
+		}
+		args = append(args, "--config", abs)
+	}
+
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	if s, err := m.OpenService(serviceName); err == nil {
+		s.Close()
+		return fmt.Errorf("service %s already installed", serviceName)
+	}
+	s, err := m.CreateService(serviceName, exe, mgr.Config{
+		DisplayName: serviceDisplayName,
+		Description: serviceDescription,
+		StartType:   mgr.StartAutomatic,
+	}, args...)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+
+	// Restart after a crash, like the tray autostart would on next login.
+	restart := []mgr.RecoveryAction{{Type: mgr.ServiceRestart, Delay: time.Minute}}
+	if err := s.SetRecoveryActions(restart, uint32((24 * time.Hour).Seconds())); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not set recovery actions: %v\n", err)
+	}
+
+	if err := eventlog.InstallAsEventCreate(serviceName, eventlog.Error|eventlog.Warning|eventlog.Info); err != nil {
+		s.Delete()
+		return fmt.Errorf("register event log source: %w", err)
+	}
+	fmt.Printf("Service %s installed. Start it with: sc start %s\n", serviceName, serviceName)
+	return nil
+}
+
+// uninstallService stops the service if it is running, then removes it
+// and its event log source.
+func uninstallService() error {
+	m, err := mgr.Connect()
+	if err != nil {
+		return fmt.Errorf("connect to service manager (run as administrator): %w", err)
+	}
+	defer m.Disconnect()
+
+	s, err := m.OpenService(serviceName)
+	if err != nil {
+		return fmt.Errorf("service %s is not installed", serviceName)
+	}
+	defer s.Close()
+
+	if status, err := s.Control(svc.Stop); err == nil {
+		for deadline := time.Now().Add(serviceStopTimeout); status.State != svc.Stopped && time.Now().Before(deadline); {
+			time.Sleep(300 * time.Millisecond)
+			if status, err = s.Query(); err != nil {
+				break
+			}
+		}
+	}
+
+	if err := s.Delete(); err != nil {
+		return err
+	}
+	if err := eventlog.Remove(serviceName); err != nil {
+		fmt.Fprintf(os.Stderr, "Could not remove event log source: %v\n", err)
+	}
+	fmt.Printf("Service %s removed.\n", serviceName)
+	return nil
+}
+
+// farmService runs the farmer and web server under the service control
+// manager, the same way --headless does under a terminal.
+type farmService struct {
+	configPath string
+}
+
+// Execute implements svc.Handler.
+func (s *farmService) Execute(_ []string, req <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	// startup_delay_seconds can hold start-up for minutes; tell the SCM
+	// to wait that long before it considers the service hung.
+	status <- svc.Status{State: svc.StartPending, WaitHint: uint32((config.MaxStartupDelay + time.Minute) / time.Millisecond)}
+
+	elog, err := eventlog.Open(serviceName)
+	if err != nil {
+		return true, 1
+	}
+	defer elog.Close()
+
+	f, err := s.start(elog)
+	if err != nil {
+		elog.Error(1, fmt.Sprintf("TwitchPoint failed to start: %v", err))
+		return true, 1
+	}
+
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for c := range req {
+		switch c.Cmd {
+		case svc.Interrogate:
+			status <- c.CurrentStatus
+		case svc.Stop, svc.Shutdown:
+			status <- svc.Status{State: svc.StopPending}
+			f.Stop()
+			elog.Info(1, "TwitchPoint stopped")
+			return false, 0
+		}
+	}
+	return false, 0
+}
+
+// start loads the config, moves into its directory (so logs/ and
+// history.db end up next to it rather than in System32) and starts
+// the farmer and the web UI.
+func (s *farmService) start(elog *eventlog.Log) (*farmer.Farmer, error) {
+	cfg, err := config.Load(s.configPath)
+	if err != nil {
+		return nil, fmt.Errorf("load config: %w", err)
+	}
+	if dir, err := filepath.Abs(filepath.Dir(cfg.Path())); err == nil {
+		if err := os.Chdir(dir); err != nil {
+			return nil, err
+		}
+	}
+	if err := twitch.SetProxy(cfg.GetProxyURL()); err != nil {
+		return nil, fmt.Errorf("invalid proxy_url: %w", err)
+	}
+	if err := cfg.ApplyTimeZone(); err != nil {
+		return nil, fmt.Errorf("invalid time_zone: %w", err)
+	}
+	if cfg.GetAuthToken() == "" {
+		return nil, fmt.Errorf("no auth token in %s — run \"twitchpoint login\" first", cfg.Path())
+	}
+
+	f := farmer.New(cfg, appVersion)
+	if err := f.Start(); err != nil {
+		return nil, err
+	}
+	if tg, ok := cfg.GetTelegram(); ok {
+		telegram.New(tg, f).Start()
+	}
+	notify.Start(cfg.GetNotifiers(), f)
+
+	// Force-enable the web UI, as in headless mode: it's the only way to
+	// see a service.
+	port := cfg.GetWebPort()
+	if port <= 0 {
+		port = 8080
+	}
+	webServer := web.New(f, port)
+	go func() {
+		if err := webServer.Start(); err != nil {
+			elog.Error(2, fmt.Sprintf("Web server error: %v", err))
+		}
+	}()
+	elog.Info(1, fmt.Sprintf("TwitchPoint v%s started (config %s, web UI http://%s)", appVersion, cfg.Path(), webServer.Addr()))
+	return f, nil
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/systemd"
+)
+
+// systemdUnitName is the user service --install-systemd-unit writes.
+const systemdUnitName = "twitchpoint.service"
+
+// installSystemdUnit writes a systemd user service that runs this binary
+// headless with cfg, in the config's directory like the Windows service
+// (so logs/ and history.db land next to it), and prints how to enable
+// it. An existing unit is replaced.
+func installSystemdUnit(cfg *config.Config) error {
+	if runtime.GOOS != "linux" {
+		return errors.New("systemd units are for Linux; see --service on Windows")
+	}
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	configPath, err := filepath.Abs(cfg.Path())
+	if err != nil {
+		return fmt.Errorf("resolve config path: %w", err)
+	}
+	home, err := os.UserConfigDir()
+	if err != nil {
+		return fmt.Errorf("locate config directory: %w", err)
+	}
+
+	unitDir := filepath.Join(home, "systemd", "user")
+	if err := os.MkdirAll(unitDir, 0755); err != nil {
+		return fmt.Errorf("create %s: %w", unitDir, err)
+	}
+	path := filepath.Join(unitDir, systemdUnitName)
+	if err := os.WriteFile(path, []byte(systemd.Unit(exe, configPath, filepath.Dir(configPath))), 0644); err != nil {
+		return fmt.Errorf("write unit: %w", err)
+	}
+	fmt.Printf("Wrote %s\n\n", path)
+	fmt.Println("Enable and start it with:")
+	fmt.Println("  systemctl --user daemon-reload")
+	fmt.Println("  systemctl --user enable --now twitchpoint")
+	fmt.Println()
+	fmt.Println("To keep it running while you're logged out:")
+	fmt.Println("  loginctl enable-linger")
+	return nil
+}
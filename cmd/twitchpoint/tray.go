@@ -0,0 +1,115 @@
+//go:build windows || linux || (darwin && cgo)
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/energye/systray"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// addTrayMenu builds the tray menu every platform shares: version
+// header, live stats, Open Web UI, then the platform's own items
+// (extra, may be nil) and Quit. Call it from the systray onReady
+// callback; it keeps the stats fresh until the farmer stops.
+func addTrayMenu(f *farmer.Farmer, cfg *config.Config, webPort int, extra func(), quit func()) {
+	// Header
+	mTitle := systray.AddMenuItem("TwitchPoint Farmer v"+appVersion, "")
+	mTitle.Disable()
+
+	systray.AddSeparator()
+
+	// Stats (updated periodically)
+	mPoints := systray.AddMenuItem("Points: ...", "")
+	mPoints.Disable()
+	mChannels := systray.AddMenuItem("Channels: ...", "")
+	mChannels.Disable()
+
+	systray.AddSeparator()
+
+	// Open Web UI. cfg.GetWebEnabled() — was a direct field read,
+	// which races against TUI Drops-tab Settings panel toggling
+	// SetWebEnabled at runtime. onReady runs beside the TUI goroutine
+	// so the read needs the lock.
+	if cfg.GetWebEnabled() {
+		mWebUI := systray.AddMenuItem("Open Web UI", "Open web dashboard in browser")
+		mWebUI.Click(func() {
+			openBrowser(fmt.Sprintf("http://localhost:%d", webPort))
+		})
+	}
+
+	// Pause / resume all farming, e.g. while watching Twitch yourself.
+	// The title follows the state, whichever UI toggled it.
+	mPause := systray.AddMenuItem("Pause farming", "Stop heartbeats and claims until resumed")
+	updatePause := func() {
+		if f.IsPaused() {
+			mPause.SetTitle("Resume farming")
+		} else {
+			mPause.SetTitle("Pause farming")
+		}
+	}
+	mPause.Click(func() {
+		if f.IsPaused() {
+			f.Resume()
+		} else {
+			f.Pause()
+		}
+		updatePause()
+	})
+
+	if extra != nil {
+		extra()
+	}
+
+	systray.AddSeparator()
+
+	// Quit
+	mQuit := systray.AddMenuItem("Quit", "Stop farming and exit")
+	mQuit.Click(quit)
+
+	// Periodic stats update
+	go func() {
+		ticker := time.NewTicker(5 * time.Second)
+		defer ticker.Stop()
+
+		updateStats := func() {
+			stats := f.GetStats()
+			drops := f.GetActiveDrops()
+
+			pointsText := fmt.Sprintf("Points: %s  |  Claims: %d",
+				formatNumber(stats.TotalPointsEarned), stats.TotalClaimsMade)
+			channelsText := fmt.Sprintf("Channels: %d/%d/%d  |  Drops: %d",
+				stats.ChannelsWatching, stats.ChannelsOnline, stats.ChannelsTotal, len(drops))
+
+			mPoints.SetTitle(pointsText)
+			mChannels.SetTitle(channelsText)
+			updatePause()
+
+			today := f.GetDailySummary()
+			systray.SetTooltip(fmt.Sprintf("TwitchPoint - today: +%s pts, %d claims, %d drop min",
+				formatNumber(today.Points), today.Claims, today.DropMinutes))
+		}
+
+		time.Sleep(2 * time.Second)
+		updateStats()
+
+		for {
+			select {
+			case <-ticker.C:
+				updateStats()
+			case <-f.Done():
+				return
+			}
+		}
+	}()
+}
+
+func formatNumber(n int) string {
+	if n < 1000 {
+		return fmt.Sprintf("%d", n)
+	}
+	return fmt.Sprintf("%d,%03d", n/1000, n%1000)
+}
@@ -0,0 +1,14 @@
+//go:build darwin && cgo
+
+package main
+
+import "os/exec"
+
+// trayAvailable: the menu bar is always there on macOS.
+func trayAvailable() bool {
+	return true
+}
+
+func openBrowser(url string) {
+	exec.Command("open", url).Start()
+}
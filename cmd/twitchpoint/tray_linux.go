@@ -0,0 +1,25 @@
+//go:build linux
+
+package main
+
+import (
+	"os/exec"
+
+	"github.com/godbus/dbus/v5"
+)
+
+// trayAvailable reports whether a D-Bus session bus is reachable, which
+// the StatusNotifierItem (AppIndicator) tray needs. The systray package
+// doesn't check this itself and crashes without one.
+func trayAvailable() bool {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return false
+	}
+	conn.Close()
+	return true
+}
+
+func openBrowser(url string) {
+	exec.Command("xdg-open", url).Start()
+}
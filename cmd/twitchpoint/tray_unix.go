@@ -0,0 +1,54 @@
+//go:build linux || (darwin && cgo)
+
+package main
+
+import (
+	_ "embed"
+	"os"
+	"syscall"
+
+	"github.com/energye/systray"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+//go:embed icon.png
+var trayIconPNG []byte
+
+// runUI runs the TUI with a tray icon (Linux AppIndicator / macOS menu
+// bar) beside it. The tray loop owns the main goroutine — Cocoa needs
+// the main thread — and the TUI runs on another; quitting the TUI
+// removes the icon. Without a tray host (no D-Bus session: SSH, a
+// server) it's the plain TUI.
+func runUI(f *farmer.Farmer, cfg *config.Config) {
+	if !trayAvailable() {
+		runTerminalUI(f, cfg)
+		return
+	}
+
+	webPort := cfg.GetWebPort()
+	if webPort <= 0 {
+		webPort = 8080
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		runTerminalUI(f, cfg)
+		systray.Quit()
+	}()
+
+	onReady := func() {
+		systray.SetIcon(trayIconPNG)
+		systray.SetTitle("TwitchPoint")
+		systray.SetTooltip("TwitchPoint Farmer")
+
+		// Quit goes through the TUI so it restores the terminal; SIGTERM
+		// makes bubbletea quit like Ctrl+C, and main stops the farmer.
+		addTrayMenu(f, cfg, webPort, nil, func() {
+			syscall.Kill(os.Getpid(), syscall.SIGTERM)
+		})
+	}
+	systray.Run(onReady, func() {})
+	<-done
+}
@@ -14,7 +14,10 @@ import (
 	"github.com/miwi/twitchpoint/internal/web"
 )
 
-func runUI(f *farmer.Farmer, cfg *config.Config) {
+// runTerminalUI starts the web server (if enabled) and runs the TUI
+// until the user quits. runUI wraps it with a tray icon where one is
+// available.
+func runTerminalUI(f *farmer.Farmer, cfg *config.Config) {
 	// Start web server if enabled
 	if cfg.GetWebEnabled() {
 		port := cfg.GetWebPort()
@@ -33,6 +36,23 @@ func runUI(f *farmer.Farmer, cfg *config.Config) {
 	// Silence Go's default logger before TUI starts
 	log.SetOutput(io.Discard)
 
+	// With quit_to_background, 'q' leaves farming to a background
+	// process instead of stopping it.
+	if cfg.GetQuitToBackground() {
+		detached, err := ui.RunDetachable(f)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "UI error: %v\n", err)
+			os.Exit(1)
+		}
+		if detached {
+			if err := detachToBackground(f, cfg); err != nil {
+				fmt.Fprintf(os.Stderr, "Could not continue in the background: %v\n", err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
 	// Run TUI (blocking)
 	if err := ui.Run(f); err != nil {
 		fmt.Fprintf(os.Stderr, "UI error: %v\n", err)
@@ -0,0 +1,13 @@
+//go:build !windows && !linux && !(darwin && cgo)
+
+package main
+
+import (
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// runUI: no tray on this platform (BSDs, macOS built without cgo).
+func runUI(f *farmer.Farmer, cfg *config.Config) {
+	runTerminalUI(f, cfg)
+}
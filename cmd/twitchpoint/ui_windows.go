@@ -8,7 +8,6 @@ import (
 	"io"
 	"log"
 	"os"
-	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/energye/systray"
@@ -50,7 +49,7 @@ func runUI(f *farmer.Farmer, cfg *config.Config) {
 	// Run bubbletea TUI — 'q' hides console instead of quitting.
 	// TUI stays running (hidden), tray keeps the app alive.
 	// Only "Quit" from tray actually exits (via os.Exit).
-	m := ui.NewModel(f)
+	m := ui.NewModel(ui.Local{Farmer: f})
 	m.OnQuit = hideConsole
 	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
@@ -72,110 +71,40 @@ func startTray(f *farmer.Farmer, cfg *config.Config, webPort int) {
 			}
 		})
 
-		// Header
-		mTitle := systray.AddMenuItem("TwitchPoint Farmer v"+appVersion, "")
-		mTitle.Disable()
-
-		systray.AddSeparator()
-
-		// Stats (updated periodically)
-		mPoints := systray.AddMenuItem("Points: ...", "")
-		mPoints.Disable()
-		mChannels := systray.AddMenuItem("Channels: ...", "")
-		mChannels.Disable()
-
-		systray.AddSeparator()
-
-		// Open Web UI. cfg.GetWebEnabled() — was a direct field read,
-		// which races against TUI Drops-tab Settings panel toggling
-		// SetWebEnabled at runtime. Tray-init runs in its own goroutine
-		// (startTray) so the read needs the lock.
-		if cfg.GetWebEnabled() {
-			mWebUI := systray.AddMenuItem("Open Web UI", "Open web dashboard in browser")
-			mWebUI.Click(func() {
-				openBrowser(fmt.Sprintf("http://localhost:%d", webPort))
-			})
-		}
-
-		// Console toggle — console is visible on startup (TUI runs in it)
-		mConsole := systray.AddMenuItem("Hide Console", "Hide or show the TUI console")
-		mConsole.Click(func() {
-			if isConsoleVisible() {
-				hideConsole()
-				mConsole.SetTitle("Show Console")
-			} else {
-				showConsoleWindow()
-				mConsole.SetTitle("Hide Console")
-			}
-		})
-
-		systray.AddSeparator()
-
-		// Auto-start toggle
-		mAutoStart := systray.AddMenuItemCheckbox("Start with Windows", "Auto-start on login", isAutoStartEnabled())
-		mAutoStart.Click(func() {
-			enabled, err := toggleAutoStart()
-			if err == nil {
-				if enabled {
-					mAutoStart.Check()
+		addTrayMenu(f, cfg, webPort, func() {
+			// Console toggle — console is visible on startup (TUI runs in it)
+			mConsole := systray.AddMenuItem("Hide Console", "Hide or show the TUI console")
+			mConsole.Click(func() {
+				if isConsoleVisible() {
+					hideConsole()
+					mConsole.SetTitle("Show Console")
 				} else {
-					mAutoStart.Uncheck()
+					showConsoleWindow()
+					mConsole.SetTitle("Hide Console")
 				}
-			}
-		})
-
-		systray.AddSeparator()
+			})
 
-		// Quit
-		mQuit := systray.AddMenuItem("Quit", "Stop farming and exit")
-		mQuit.Click(func() {
+			systray.AddSeparator()
+
+			// Auto-start toggle
+			mAutoStart := systray.AddMenuItemCheckbox("Start with Windows", "Auto-start on login", isAutoStartEnabled())
+			mAutoStart.Click(func() {
+				enabled, err := toggleAutoStart()
+				if err == nil {
+					if enabled {
+						mAutoStart.Check()
+					} else {
+						mAutoStart.Uncheck()
+					}
+				}
+			})
+		}, func() {
 			f.Stop()
 			systray.Quit()
 			os.Exit(0)
 		})
-
-		// Periodic stats update
-		go func() {
-			ticker := time.NewTicker(5 * time.Second)
-			defer ticker.Stop()
-
-			updateStats := func() {
-				stats := f.GetStats()
-				drops := f.GetActiveDrops()
-
-				pointsText := fmt.Sprintf("Points: %s  |  Claims: %d",
-					formatNumber(stats.TotalPointsEarned), stats.TotalClaimsMade)
-				channelsText := fmt.Sprintf("Channels: %d/%d/%d  |  Drops: %d",
-					stats.ChannelsWatching, stats.ChannelsOnline, stats.ChannelsTotal, len(drops))
-
-				mPoints.SetTitle(pointsText)
-				mChannels.SetTitle(channelsText)
-
-				systray.SetTooltip(fmt.Sprintf("TwitchPoint - %s pts, %d channels",
-					formatNumber(stats.TotalPointsEarned), stats.ChannelsWatching))
-			}
-
-			time.Sleep(2 * time.Second)
-			updateStats()
-
-			for {
-				select {
-				case <-ticker.C:
-					updateStats()
-				case <-f.Done():
-					return
-				}
-			}
-		}()
 	}
 
 	onExit := func() {}
 	systray.Run(onReady, onExit)
 }
-
-func formatNumber(n int) string {
-	if n < 1000 {
-		return fmt.Sprintf("%d", n)
-	}
-	return fmt.Sprintf("%d,%03d", n/1000, n%1000)
-}
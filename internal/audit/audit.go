@@ -0,0 +1,137 @@
+// Package audit appends every action the farmer takes on the account —
+// bonus, Moment and drop claims, raid joins, redemptions — to a JSON
+// Lines file (audit.jsonl next to config.json), one object per line,
+// for users who want a complete record of what was done in their name.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Results (Entry.Result).
+const (
+	ResultOK    = "ok"
+	ResultError = "error"
+)
+
+// Entry is one line of the audit log.
+type Entry struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"` // bonus_claim, moment_claim, raid_join, drop_claim, redemption
+	ChannelID string    `json:"channel_id,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	Detail    string    `json:"detail,omitempty"` // raid target, drop and campaign, reward title
+	Points    int       `json:"points,omitempty"` // redemption cost
+	Result    string    `json:"result"`           // ResultOK or ResultError
+	Error     string    `json:"error,omitempty"`
+}
+
+// Query selects the entries Read returns. A zero From/To leaves that end
+// open, empty Kinds and Channel match everything and Limit 0 returns
+// all matches.
+type Query struct {
+	From, To time.Time
+	Kinds    map[string]bool
+	Channel  string // display name or channel ID, case-insensitive
+	Limit    int
+}
+
+// Log is an append-only audit file. The file is opened for each write,
+// so it can be moved away or deleted at any time.
+type Log struct {
+	path string
+	mu   sync.Mutex // serializes appends
+}
+
+// New returns the audit log at path. Nothing is created until the first
+// Append.
+func New(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the file the log is written to.
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append writes e as one line. A zero Time is set to now.
+func (l *Log) Append(e Entry) error {
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, err := os.OpenFile(l.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read returns the entries matching q, newest first. A missing file is
+// an empty log; lines that don't parse (a write cut short by a crash)
+// are skipped.
+func (l *Log) Read(q Query) ([]Entry, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []Entry
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		var e Entry
+		if json.Unmarshal(sc.Bytes(), &e) != nil || !q.matches(e) {
+			continue
+		}
+		out = append(out, e)
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", l.path, err)
+	}
+
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	if q.Limit > 0 && len(out) > q.Limit {
+		out = out[:q.Limit]
+	}
+	return out, nil
+}
+
+func (q Query) matches(e Entry) bool {
+	if !q.From.IsZero() && e.Time.Before(q.From) {
+		return false
+	}
+	if !q.To.IsZero() && !e.Time.Before(q.To) {
+		return false
+	}
+	if len(q.Kinds) > 0 && !q.Kinds[e.Kind] {
+		return false
+	}
+	if q.Channel != "" && !strings.EqualFold(q.Channel, e.Channel) && q.Channel != e.ChannelID {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,66 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	l := New(path)
+
+	if got, err := l.Read(Query{}); err != nil || len(got) != 0 {
+		t.Fatalf("Read of a missing file = %v, %v; want empty", got, err)
+	}
+
+	base := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	entries := []Entry{
+		{Time: base, Kind: "bonus_claim", ChannelID: "1", Channel: "Alpha", Result: ResultOK},
+		{Time: base.Add(time.Minute), Kind: "raid_join", ChannelID: "2", Channel: "Beta", Detail: "Gamma", Result: ResultOK},
+		{Time: base.Add(2 * time.Minute), Kind: "bonus_claim", ChannelID: "2", Channel: "Beta", Result: ResultError, Error: "timeout"},
+	}
+	for _, e := range entries {
+		if err := l.Append(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A torn last line is skipped.
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"time":"2026-03-01T12:03:00Z","ki`)
+	f.Close()
+
+	all, err := l.Read(Query{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 || all[0].Error != "timeout" || all[2].Channel != "Alpha" {
+		t.Fatalf("Read = %+v, want the three entries newest first", all)
+	}
+
+	tests := []struct {
+		name string
+		q    Query
+		want int
+	}{
+		{"kind", Query{Kinds: map[string]bool{"bonus_claim": true}}, 2},
+		{"channel name", Query{Channel: "beta"}, 2},
+		{"channel id", Query{Channel: "1"}, 1},
+		{"from", Query{From: base.Add(time.Minute)}, 2},
+		{"to", Query{To: base.Add(time.Minute)}, 1},
+		{"limit", Query{Limit: 1}, 1},
+	}
+	for _, tt := range tests {
+		got, err := l.Read(tt.q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(got) != tt.want {
+			t.Errorf("%s: got %d entries, want %d", tt.name, len(got), tt.want)
+		}
+	}
+}
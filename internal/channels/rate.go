@@ -0,0 +1,71 @@
+package channels
+
+import "time"
+
+const (
+	// RateWindowSpan is how far back points/hour looks.
+	RateWindowSpan = time.Hour
+	// rateMinSpan keeps the first minutes from extrapolating a single
+	// bonus chest into thousands per hour: until a window has been open
+	// this long, the rate is computed as if it had.
+	rateMinSpan = 10 * time.Minute
+)
+
+type rateSample struct {
+	at     time.Time
+	points int
+}
+
+// RateWindow is a rolling window of earned points for computing a
+// points/hour rate. Not safe for concurrent use — owners guard it with
+// their own mutex.
+type RateWindow struct {
+	start   time.Time
+	samples []rateSample
+}
+
+// NewRateWindow returns a window that started tracking at start.
+func NewRateWindow(start time.Time) RateWindow {
+	return RateWindow{start: start}
+}
+
+// Add records points earned at now and prunes samples that fell out of
+// the window.
+func (w *RateWindow) Add(points int, now time.Time) {
+	if points <= 0 {
+		return
+	}
+	w.samples = append(w.samples, rateSample{at: now, points: points})
+	cutoff := now.Add(-RateWindowSpan)
+	i := 0
+	for i < len(w.samples) && !w.samples[i].at.After(cutoff) {
+		i++
+	}
+	w.samples = w.samples[i:]
+}
+
+// PerHour returns the points/hour rate over the window ending at now.
+// A window younger than RateWindowSpan divides by its actual age
+// (floored at rateMinSpan) rather than the full hour.
+func (w *RateWindow) PerHour(now time.Time) int {
+	cutoff := now.Add(-RateWindowSpan)
+	sum := 0
+	for _, s := range w.samples {
+		if s.at.After(cutoff) {
+			sum += s.points
+		}
+	}
+	if sum == 0 {
+		return 0
+	}
+	span := RateWindowSpan
+	if !w.start.IsZero() {
+		if age := now.Sub(w.start); age < span {
+			span = age
+		}
+	}
+	if span < rateMinSpan {
+		span = rateMinSpan
+	}
+	return int(float64(sum) * float64(time.Hour) / float64(span))
+}
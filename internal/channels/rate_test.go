@@ -0,0 +1,60 @@
+package channels
+
+import (
+	"testing"
+	"time"
+)
+
+var rateT0 = time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRateWindow_FullHour(t *testing.T) {
+	w := NewRateWindow(rateT0)
+	w.Add(100, rateT0.Add(70*time.Minute))
+	w.Add(200, rateT0.Add(100*time.Minute))
+	// Window open for 2h: both samples inside the last hour count, over 1h.
+	if got := w.PerHour(rateT0.Add(2 * time.Hour)); got != 300 {
+		t.Fatalf("PerHour = %d, want 300", got)
+	}
+	// 15 min later the first sample has aged out.
+	if got := w.PerHour(rateT0.Add(2*time.Hour + 15*time.Minute)); got != 200 {
+		t.Fatalf("PerHour after expiry = %d, want 200", got)
+	}
+}
+
+func TestRateWindow_YoungWindowScalesByAge(t *testing.T) {
+	w := NewRateWindow(rateT0)
+	w.Add(50, rateT0.Add(20*time.Minute))
+	// 30 min old window: 50 points in 30 min = 100/h.
+	if got := w.PerHour(rateT0.Add(30 * time.Minute)); got != 100 {
+		t.Fatalf("PerHour = %d, want 100", got)
+	}
+}
+
+// TestRateWindow_MinSpanDampensStartup: one bonus chest a minute after
+// start must not read as 3000/h.
+func TestRateWindow_MinSpanDampensStartup(t *testing.T) {
+	w := NewRateWindow(rateT0)
+	w.Add(50, rateT0.Add(time.Minute))
+	if got := w.PerHour(rateT0.Add(time.Minute)); got != 300 {
+		t.Fatalf("PerHour = %d, want 300 (50 over the 10 min floor)", got)
+	}
+}
+
+func TestRateWindow_PrunesOnAdd(t *testing.T) {
+	w := NewRateWindow(rateT0)
+	w.Add(10, rateT0)
+	w.Add(10, rateT0.Add(30*time.Minute))
+	w.Add(10, rateT0.Add(2*time.Hour))
+	if len(w.samples) != 1 {
+		t.Fatalf("samples = %d, want 1 after pruning", len(w.samples))
+	}
+}
+
+func TestState_SnapshotPointsPerHour(t *testing.T) {
+	s := NewState("alice", "Alice", "111")
+	s.AddPointsEarned(50, 1050)
+	// Window just opened, so the 10-minute floor applies.
+	if got := s.Snapshot().PointsPerHour; got != 300 {
+		t.Fatalf("PointsPerHour = %d, want 300", got)
+	}
+}
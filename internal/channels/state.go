@@ -5,6 +5,7 @@
 package channels
 
 import (
+	"fmt"
 	"sync"
 	"time"
 )
@@ -21,7 +22,8 @@ type State struct {
 	ChannelID   string
 
 	// Priority
-	Priority int // 1 = always watch, 2 = rotate
+	Priority int  // 1 = always watch, 2 = rotate
+	Paused   bool // tracked but never watched (user toggle)
 
 	// Status
 	IsOnline    bool
@@ -31,16 +33,47 @@ type State struct {
 	GameID      string
 	ViewerCount int
 
+	// ViewersChangedAt is when ViewerCount last moved (or the stream came
+	// up). A count frozen for long marks a ghost stream.
+	ViewersChangedAt time.Time
+
+	// IsRerun marks a rebroadcast (twitch.ChannelInfo.IsRerun), as of
+	// the last channel info fetch.
+	IsRerun bool
+
 	// Points
 	PointsBalance       int
 	PointsEarnedSession int
+	PointsSpentSession  int // balance drops the bot didn't cause (manual redemptions, bets)
 	ClaimsMade          int
 	LastClaimTime       time.Time
 
+	// PointsMultiplier is the channel's points multiplier (1.2 with a
+	// tier 1 sub) and SubTier the user's subscription tier there (0 =
+	// not subscribed), both as of the last balance refresh. 0 until the
+	// first one.
+	PointsMultiplier float64
+	SubTier          int
+
+	// earnRate feeds Snapshot.PointsPerHour.
+	earnRate RateWindow
+
+	// balanceLiveAt is when PubSub last reported the balance. A GQL poll
+	// landing shortly after may have been read before that update, so
+	// SetBalance ignores it rather than book a phantom spend.
+	balanceLiveAt time.Time
+
 	// Timing
 	OnlineSince   time.Time
 	WatchingSince time.Time
 
+	// Spade heartbeats, mirrored from the SpadeTracker while watched.
+	// HeartbeatBenchedUntil keeps a channel whose heartbeats kept
+	// failing out of rotation for a while.
+	HeartbeatOKAt         time.Time
+	HeartbeatFailures     int
+	HeartbeatBenchedUntil time.Time
+
 	// Streak-Hunt tracking. StreakClaimedAt is set when a WATCH_STREAK
 	// PubSub event fires for this channel; the rotation logic compares
 	// it to OnlineSince to decide if the current stream's streak is
@@ -49,6 +82,16 @@ type State struct {
 	// stream "unclaimed" without an explicit reset.
 	StreakClaimedAt time.Time
 
+	// Hype Train (hype-train-events-v1). HypeTrainUntil is when the
+	// train runs out unless another contribution extends it; it also
+	// covers a missed hype-train-end event.
+	HypeTrainLevel int
+	HypeTrainUntil time.Time
+
+	// PubSubWarning names a PubSub topic of the channel whose LISTEN
+	// keeps failing, and why; "" when all are subscribed.
+	PubSubWarning string
+
 	// Drops
 	HasActiveDrop bool
 	DropName      string
@@ -66,6 +109,7 @@ func NewState(login, displayName, channelID string) *State {
 		Login:       login,
 		DisplayName: displayName,
 		ChannelID:   channelID,
+		earnRate:    NewRateWindow(time.Now()),
 	}
 }
 
@@ -76,6 +120,13 @@ func (s *State) SetPriority(p int) {
 	s.Priority = p
 }
 
+// SetPaused toggles whether the rotation may watch this channel.
+func (s *State) SetPaused(p bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.Paused = p
+}
+
 // SetIsTemporary toggles the temporary-channel flag (used when a drops-only
 // channel is promoted to permanent or vice versa).
 func (s *State) SetIsTemporary(t bool) {
@@ -98,10 +149,10 @@ func (s *State) SetOnline(broadcastID, gameName string, viewers int) {
 	if !s.IsOnline {
 		s.OnlineSince = time.Now()
 	}
+	s.setViewersLocked(viewers)
 	s.IsOnline = true
 	s.BroadcastID = broadcastID
 	s.GameName = gameName
-	s.ViewerCount = viewers
 }
 
 // SetOnlineWithGameID is like SetOnline but also stores the game ID and
@@ -121,11 +172,27 @@ func (s *State) SetOnlineWithGameID(broadcastID, gameName, gameID string, viewer
 			s.OnlineSince = streamStartedAt
 		}
 	}
+	s.setViewersLocked(viewers)
 	s.IsOnline = true
 	s.BroadcastID = broadcastID
 	s.GameName = gameName
 	s.GameID = gameID
-	s.ViewerCount = viewers
+}
+
+// SetMultiplier records the channel's points multiplier and the user's
+// subscription tier there.
+func (s *State) SetMultiplier(multiplier float64, subTier int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PointsMultiplier = multiplier
+	s.SubTier = subTier
+}
+
+// SetRerun records whether the current stream is a rebroadcast.
+func (s *State) SetRerun(r bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.IsRerun = r
 }
 
 // SetOffline marks the channel as offline.
@@ -138,6 +205,10 @@ func (s *State) SetOffline() {
 	s.GameName = ""
 	s.GameID = ""
 	s.ViewerCount = 0
+	s.ViewersChangedAt = time.Time{}
+	s.IsRerun = false
+	s.HypeTrainLevel = 0
+	s.HypeTrainUntil = time.Time{}
 }
 
 // SetWatching marks the channel as actively being watched (Spade).
@@ -153,14 +224,47 @@ func (s *State) SetWatching(watching bool) {
 	}
 }
 
-// AddPointsEarned records earned points.
-func (s *State) AddPointsEarned(points int, totalBalance int) {
+// SetHeartbeat records the Spade heartbeat health of the channel: when
+// Twitch last accepted one and how many failed in a row since.
+func (s *State) SetHeartbeat(okAt time.Time, failures int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HeartbeatOKAt = okAt
+	s.HeartbeatFailures = failures
+}
+
+// BenchHeartbeats keeps the channel out of the points rotation until
+// until, after its heartbeats kept failing.
+func (s *State) BenchHeartbeats(until time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HeartbeatBenchedUntil = until
+}
+
+// balanceStaleGrace is how long after a PubSub balance update polled
+// balances are distrusted. Covers the GQL round-trip plus the 500 ms
+// refresh spacing with plenty of margin.
+const balanceStaleGrace = 10 * time.Second
+
+// AddPointsEarned records earned points. If the reported balance is
+// below what the previous balance plus the gain predicts, the gap was
+// spent in between and is returned (0 otherwise).
+func (s *State) AddPointsEarned(points int, totalBalance int) int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.PointsEarnedSession += points
-	if totalBalance > 0 {
-		s.PointsBalance = totalBalance
+	s.earnRate.Add(points, time.Now())
+	if totalBalance <= 0 {
+		return 0
+	}
+	s.balanceLiveAt = time.Now()
+	spent := 0
+	if expected := s.PointsBalance + points; s.PointsBalance > 0 && totalBalance < expected {
+		spent = expected - totalBalance
+		s.PointsSpentSession += spent
 	}
+	s.PointsBalance = totalBalance
+	return spent
 }
 
 // RecordClaim records a bonus claim.
@@ -180,17 +284,70 @@ func (s *State) MarkStreakClaimed() {
 	s.StreakClaimedAt = time.Now()
 }
 
-// SetBalance sets the points balance.
-func (s *State) SetBalance(balance int) {
+// SetHypeTrain records a running Hype Train at the given level, expiring
+// at until.
+func (s *State) SetHypeTrain(level int, until time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.HypeTrainLevel = level
+	s.HypeTrainUntil = until
+}
+
+// ClearHypeTrain records that the channel's Hype Train ended.
+func (s *State) ClearHypeTrain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.HypeTrainLevel = 0
+	s.HypeTrainUntil = time.Time{}
+}
+
+// SetBalance sets the points balance from a GQL poll and returns the
+// points spent since the last known balance (0 if it didn't drop).
+// Ignored within balanceStaleGrace of a PubSub update, which is fresher.
+func (s *State) SetBalance(balance int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.balanceLiveAt.IsZero() && time.Since(s.balanceLiveAt) < balanceStaleGrace {
+		return 0
+	}
+	return s.applyBalanceLocked(balance)
+}
+
+// SetLiveBalance sets the points balance from a PubSub message and
+// returns the points spent since the last known balance.
+func (s *State) SetLiveBalance(balance int) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.balanceLiveAt = time.Now()
+	return s.applyBalanceLocked(balance)
+}
+
+// applyBalanceLocked stores balance and books any drop as spent. The
+// first balance seen (PointsBalance still 0) is a baseline, not a
+// change. Caller holds s.mu.
+func (s *State) applyBalanceLocked(balance int) int {
+	spent := 0
+	if s.PointsBalance > 0 && balance < s.PointsBalance {
+		spent = s.PointsBalance - balance
+		s.PointsSpentSession += spent
+	}
 	s.PointsBalance = balance
+	return spent
 }
 
 // SetViewerCount updates the viewer count.
 func (s *State) SetViewerCount(count int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
+	s.setViewersLocked(count)
+}
+
+// setViewersLocked stores the viewer count, restarting ViewersChangedAt
+// when it moved or the stream just came up. Caller holds s.mu.
+func (s *State) setViewersLocked(count int) {
+	if count != s.ViewerCount || !s.IsOnline || s.ViewersChangedAt.IsZero() {
+		s.ViewersChangedAt = time.Now()
+	}
 	s.ViewerCount = count
 }
 
@@ -221,22 +378,40 @@ type Snapshot struct {
 	DisplayName         string
 	ChannelID           string
 	Priority            int
+	Paused              bool
 	IsOnline            bool
 	IsWatching          bool
 	BroadcastID         string
 	GameName            string
 	GameID              string
 	ViewerCount         int
+	ViewersChangedAt    time.Time
+	IsRerun             bool
 	PointsBalance       int
 	PointsEarnedSession int
+	PointsSpentSession  int
+	PointsPerHour       int // earned over the last RateWindowSpan
 	ClaimsMade          int
 	LastClaimTime       time.Time
+	PointsMultiplier    float64
+	SubTier             int
 	OnlineSince         time.Time
 	WatchingSince       time.Time
 
+	// Spade heartbeats
+	HeartbeatOKAt         time.Time
+	HeartbeatFailures     int
+	HeartbeatBenchedUntil time.Time
+
 	// Streak-Hunt
 	StreakClaimedAt time.Time
 
+	// Hype Train
+	HypeTrainLevel int
+	HypeTrainUntil time.Time
+
+	PubSubWarning string
+
 	// Drops
 	HasActiveDrop bool
 	DropName      string
@@ -253,28 +428,76 @@ func (s *State) Snapshot() Snapshot {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 	return Snapshot{
-		Login:               s.Login,
-		DisplayName:         s.DisplayName,
-		ChannelID:           s.ChannelID,
-		Priority:            s.Priority,
-		IsOnline:            s.IsOnline,
-		IsWatching:          s.IsWatching,
-		BroadcastID:         s.BroadcastID,
-		GameName:            s.GameName,
-		GameID:              s.GameID,
-		ViewerCount:         s.ViewerCount,
-		PointsBalance:       s.PointsBalance,
-		PointsEarnedSession: s.PointsEarnedSession,
-		ClaimsMade:          s.ClaimsMade,
-		LastClaimTime:       s.LastClaimTime,
-		OnlineSince:         s.OnlineSince,
-		WatchingSince:       s.WatchingSince,
-		StreakClaimedAt:     s.StreakClaimedAt,
-		HasActiveDrop:       s.HasActiveDrop,
-		DropName:            s.DropName,
-		DropProgress:        s.DropProgress,
-		DropRequired:        s.DropRequired,
-		IsTemporary:         s.IsTemporary,
-		CampaignID:          s.CampaignID,
+		Login:                 s.Login,
+		DisplayName:           s.DisplayName,
+		ChannelID:             s.ChannelID,
+		Priority:              s.Priority,
+		Paused:                s.Paused,
+		IsOnline:              s.IsOnline,
+		IsWatching:            s.IsWatching,
+		BroadcastID:           s.BroadcastID,
+		GameName:              s.GameName,
+		GameID:                s.GameID,
+		ViewerCount:           s.ViewerCount,
+		ViewersChangedAt:      s.ViewersChangedAt,
+		IsRerun:               s.IsRerun,
+		PointsBalance:         s.PointsBalance,
+		PointsEarnedSession:   s.PointsEarnedSession,
+		PointsSpentSession:    s.PointsSpentSession,
+		PointsPerHour:         s.earnRate.PerHour(time.Now()),
+		ClaimsMade:            s.ClaimsMade,
+		LastClaimTime:         s.LastClaimTime,
+		PointsMultiplier:      s.PointsMultiplier,
+		SubTier:               s.SubTier,
+		OnlineSince:           s.OnlineSince,
+		WatchingSince:         s.WatchingSince,
+		HeartbeatOKAt:         s.HeartbeatOKAt,
+		HeartbeatFailures:     s.HeartbeatFailures,
+		HeartbeatBenchedUntil: s.HeartbeatBenchedUntil,
+		StreakClaimedAt:       s.StreakClaimedAt,
+		HypeTrainLevel:        s.HypeTrainLevel,
+		HypeTrainUntil:        s.HypeTrainUntil,
+		PubSubWarning:         s.PubSubWarning,
+		HasActiveDrop:         s.HasActiveDrop,
+		DropName:              s.DropName,
+		DropProgress:          s.DropProgress,
+		DropRequired:          s.DropRequired,
+		IsTemporary:           s.IsTemporary,
+		CampaignID:            s.CampaignID,
 	}
 }
+
+// SetPubSubWarning records why a PubSub topic of the channel keeps
+// failing to subscribe; "" clears it.
+func (s *State) SetPubSubWarning(w string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.PubSubWarning = w
+}
+
+// HeartbeatBenched reports whether the channel is kept out of rotation
+// at now for failing heartbeats.
+func (s Snapshot) HeartbeatBenched(now time.Time) bool {
+	return now.Before(s.HeartbeatBenchedUntil)
+}
+
+// InactiveReason tells why a live channel looks like a ghost stream at
+// now — fewer than minViewers viewers, or a viewer count frozen for
+// staleAfter — or returns "" if it doesn't. Zero limits are off.
+func (s Snapshot) InactiveReason(now time.Time, minViewers int, staleAfter time.Duration) string {
+	switch {
+	case !s.IsOnline:
+		return ""
+	case minViewers > 0 && s.ViewerCount < minViewers:
+		return fmt.Sprintf("%d viewers, below %d", s.ViewerCount, minViewers)
+	case staleAfter > 0 && !s.ViewersChangedAt.IsZero() && now.Sub(s.ViewersChangedAt) >= staleAfter:
+		return fmt.Sprintf("viewer count stuck at %d for %v", s.ViewerCount, now.Sub(s.ViewersChangedAt).Round(time.Minute))
+	}
+	return ""
+}
+
+// InHypeTrain reports whether a Hype Train is running on the channel at
+// now.
+func (s Snapshot) InHypeTrain(now time.Time) bool {
+	return s.HypeTrainLevel > 0 && now.Before(s.HypeTrainUntil)
+}
@@ -78,3 +78,76 @@ func TestState_SetOnlineWithGameID_FallsBackToNow(t *testing.T) {
 			snap.OnlineSince, before, after)
 	}
 }
+
+func TestState_SetBalance_BooksDropAsSpent(t *testing.T) {
+	s := NewState("alice", "Alice", "111")
+	if spent := s.SetBalance(1000); spent != 0 {
+		t.Fatalf("first balance is a baseline, got spent=%d", spent)
+	}
+	if spent := s.SetBalance(1200); spent != 0 {
+		t.Fatalf("increase should not count as spent, got %d", spent)
+	}
+	if spent := s.SetBalance(700); spent != 500 {
+		t.Fatalf("drop 1200→700 should be spent=500, got %d", spent)
+	}
+	if got := s.Snapshot().PointsSpentSession; got != 500 {
+		t.Errorf("PointsSpentSession = %d, want 500", got)
+	}
+}
+
+func TestState_AddPointsEarned_DetectsSpendBetweenEvents(t *testing.T) {
+	s := NewState("alice", "Alice", "111")
+	s.AddPointsEarned(10, 1010)
+	// +50 arrives but balance only rose to 560 — 500 went somewhere.
+	if spent := s.AddPointsEarned(50, 560); spent != 500 {
+		t.Fatalf("expected spent=500, got %d", spent)
+	}
+	snap := s.Snapshot()
+	if snap.PointsBalance != 560 || snap.PointsEarnedSession != 60 {
+		t.Errorf("balance=%d earned=%d, want 560/60", snap.PointsBalance, snap.PointsEarnedSession)
+	}
+}
+
+func TestState_SetBalance_IgnoresStalePollAfterLiveUpdate(t *testing.T) {
+	s := NewState("alice", "Alice", "111")
+	s.SetBalance(1000)
+	s.AddPointsEarned(50, 1050)
+	// Poll read before the PubSub update lands afterwards — must not
+	// book the 50 as spent or roll the balance back.
+	if spent := s.SetBalance(1000); spent != 0 {
+		t.Fatalf("stale poll booked spent=%d", spent)
+	}
+	if got := s.Snapshot().PointsBalance; got != 1050 {
+		t.Errorf("balance rolled back to %d", got)
+	}
+	if spent := s.SetLiveBalance(550); spent != 500 {
+		t.Errorf("live points-spent update should book 500, got %d", spent)
+	}
+}
+
+func TestSnapshot_InactiveReason(t *testing.T) {
+	s := NewState("alice", "Alice", "111")
+	if r := s.Snapshot().InactiveReason(time.Now(), 5, time.Minute); r != "" {
+		t.Fatalf("offline channel flagged: %q", r)
+	}
+	s.SetOnline("b1", "G", 3)
+	now := time.Now()
+	if r := s.Snapshot().InactiveReason(now, 5, 0); r == "" {
+		t.Error("3 viewers under a floor of 5 should be inactive")
+	}
+	if r := s.Snapshot().InactiveReason(now, 0, 0); r != "" {
+		t.Errorf("zero limits flagged: %q", r)
+	}
+	later := now.Add(30 * time.Minute)
+	if r := s.Snapshot().InactiveReason(later, 0, 30*time.Minute); r == "" {
+		t.Error("viewer count frozen for 30m should be inactive")
+	}
+	s.SetViewerCount(3) // unchanged count doesn't restart the clock
+	if r := s.Snapshot().InactiveReason(later, 0, 30*time.Minute); r == "" {
+		t.Error("repeating the same count should not reset staleness")
+	}
+	s.SetViewerCount(4)
+	if r := s.Snapshot().InactiveReason(time.Now(), 0, 30*time.Minute); r != "" {
+		t.Errorf("moving viewer count flagged: %q", r)
+	}
+}
@@ -0,0 +1,87 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// The Check* functions report why a value can't be stored in a setting,
+// worded after its config key. The matching setters refuse exactly the
+// values these reject, so a caller applying several settings at once can
+// check them all before changing anything.
+
+// CheckRotationIntervalMinutes checks a rotation_interval_minutes value.
+func CheckRotationIntervalMinutes(m int) error {
+	if m < 0 || m > MaxRotationIntervalMinutes {
+		return fmt.Errorf("rotation_interval_minutes must be between 0 (default) and %d", MaxRotationIntervalMinutes)
+	}
+	return nil
+}
+
+// CheckStreakWindowMinutes checks a streak_window_minutes value.
+func CheckStreakWindowMinutes(m int) error {
+	if m < 0 || m > MaxStreakWindowMinutes {
+		return fmt.Errorf("streak_window_minutes must be between 0 (default) and %d", MaxStreakWindowMinutes)
+	}
+	return nil
+}
+
+// CheckDropCheckMinutes checks a drop_check_minutes value.
+func CheckDropCheckMinutes(m int) error {
+	if m < 0 || m > MaxDropCheckMinutes {
+		return fmt.Errorf("drop_check_minutes must be between 0 (default) and %d", MaxDropCheckMinutes)
+	}
+	return nil
+}
+
+// CheckDropMinProgressPercent checks a drop_min_progress_percent value.
+func CheckDropMinProgressPercent(pct int) error {
+	if pct < 0 || pct > 100 {
+		return errors.New("drop_min_progress_percent must be between 0 (off) and 100")
+	}
+	return nil
+}
+
+// CheckDropAutoSelect checks a drop_auto_select mode.
+func CheckDropAutoSelect(mode string) error {
+	if _, ok := validAutoSelect(mode); !ok {
+		return fmt.Errorf("drop_auto_select must be %s, %s or %s", AutoSelectOff, AutoSelectAllowed, AutoSelectDirectory)
+	}
+	return nil
+}
+
+// CheckWebPort checks a web_port value.
+func CheckWebPort(port int) error {
+	if port < 1 || port > 65535 {
+		return errors.New("web_port must be between 1 and 65535")
+	}
+	return nil
+}
+
+// CheckTransport checks a transport value.
+func CheckTransport(t string) error {
+	switch strings.ToLower(strings.TrimSpace(t)) {
+	case TransportPubSub, TransportEventSub, TransportAuto:
+		return nil
+	}
+	return fmt.Errorf("transport must be %s, %s or %s", TransportPubSub, TransportEventSub, TransportAuto)
+}
+
+// CheckNetworkProfile checks a network_profile value.
+func CheckNetworkProfile(p string) error {
+	switch strings.ToLower(strings.TrimSpace(p)) {
+	case NetworkProfileDefault, NetworkProfileFlaky:
+		return nil
+	}
+	return fmt.Errorf("network_profile must be %s or %s", NetworkProfileDefault, NetworkProfileFlaky)
+}
+
+// CheckIrcMode checks an irc_mode value.
+func CheckIrcMode(m string) error {
+	switch strings.ToLower(strings.TrimSpace(m)) {
+	case IrcModeAll, IrcModeWatching, IrcModeOff:
+		return nil
+	}
+	return fmt.Errorf("irc_mode must be %s, %s or %s", IrcModeAll, IrcModeWatching, IrcModeOff)
+}
@@ -1,21 +1,311 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+	"unicode"
 )
 
 const defaultConfigFile = "config.json"
 
+// Stream-status transports (Config.Transport). Only stream up/down moves
+// between them — points, claims, drops and raids stay on PubSub.
+const (
+	TransportPubSub   = "pubsub"   // video-playback-by-id topics (default)
+	TransportEventSub = "eventsub" // EventSub WebSocket, PubSub for channels it can't take
+	TransportAuto     = "auto"     // PubSub, failing over to EventSub while PubSub is down
+)
+
+// IRC presence modes (Config.IrcMode).
+const (
+	IrcModeAll      = "all"      // join every tracked channel (default)
+	IrcModeWatching = "watching" // join only the channels holding a Spade slot
+	IrcModeOff      = "off"      // no IRC, like irc_enabled false
+)
+
+// Rerun handling (Config.RerunMode) — what the points rotation and the
+// drop pick do with rebroadcast streams.
+const (
+	RerunModeWatch        = "watch"        // treat reruns like live streams (default)
+	RerunModeDeprioritize = "deprioritize" // reruns only get points slots no live stream wants
+	RerunModeSkip         = "skip"         // reruns get no points slot and are never the drop pick
+)
+
+// Network profiles (Config.NetworkProfile) — reconnect, retry and
+// timeout tuning for the Twitch clients.
+const (
+	NetworkProfileDefault = "default" // stable wired / Wi-Fi connection
+	NetworkProfileFlaky   = "flaky"   // mobile hotspots and other connections that drop out
+)
+
+// Log levels (Config.LogLevel) — the least severe entry shown in the
+// TUI / Web UI feed. The debug log file gets every level.
+const (
+	LogLevelDebug = "debug"
+	LogLevelInfo  = "info" // default
+	LogLevelWarn  = "warn"
+	LogLevelError = "error"
+)
+
+// Log formats (Config.LogFormat) of the debug log file and headless
+// mode's stdout.
+const (
+	LogFormatText = "text" // "[time] LEVEL message" (default)
+	LogFormatJSON = "json" // one JSON object per line
+)
+
+// Drop auto-select modes (Config.DropAutoSelect and per-campaign
+// overrides) — how far the drops selector may reach for a channel.
+const (
+	AutoSelectOff       = "off"       // only channels already in channel_configs
+	AutoSelectAllowed   = "allowed"   // campaign allow list; own channels for unrestricted campaigns
+	AutoSelectDirectory = "directory" // allow list, else the game's drops-enabled directory (default)
+)
+
+// Channel modes (ChannelEntry.Mode) — which kind of farming a configured
+// channel takes part in.
+const (
+	ChannelModeBoth   = ""       // points and drops (default)
+	ChannelModePoints = "points" // points only: never used for drop matching
+	ChannelModeDrops  = "drops"  // drops only: watched only while it serves an active campaign
+)
+
+// Per-channel presence mechanisms, switched off with
+// SetChannelFeatureEnabled (ChannelEntry.Disable*).
+const (
+	ChannelFeatureIRC    = "irc"    // chat join: viewer-list presence
+	ChannelFeaturePubSub = "pubsub" // channel topics: stream up/down, raids, Hype Trains
+	ChannelFeatureSpade  = "spade"  // minute-watched heartbeats from the points rotation
+)
+
 // ChannelEntry holds per-channel config.
 type ChannelEntry struct {
 	ID       string `json:"id,omitempty"` // Twitch channel ID (persisted, survives renames)
 	Login    string `json:"login"`
 	Priority int    `json:"priority"` // 1 = always watch, 2 = rotate (default)
+	// DisableMoments opts this channel out of Moments auto-claim.
+	// Stored inverted so existing configs default to claiming.
+	DisableMoments bool `json:"disable_moments,omitempty"`
+	// Paused keeps the channel tracked (balance, PubSub events, claims)
+	// but out of the watch rotation.
+	Paused bool `json:"paused,omitempty"`
+	// Redeem lists custom rewards to redeem automatically once the
+	// balance allows it. Tried in order; at most one per check.
+	Redeem []RedeemRule `json:"redeem,omitempty"`
+	// Goal is a target balance (e.g. the price of a reward to save up
+	// for). Once reached the channel drops to the back of the watch
+	// rotation. 0 = no goal.
+	Goal int `json:"goal,omitempty"`
+	// MaxPoints caps the balance worth farming: at or over it the
+	// channel gets no points slot until spending brings it back under.
+	// 0 = no cap.
+	MaxPoints int `json:"max_points,omitempty"`
+	// Mode restricts the channel to points or drops farming; see the
+	// ChannelMode* constants. Empty = both.
+	Mode string `json:"mode,omitempty"`
+	// DisableIRC, DisablePubSub and DisableSpade switch off one presence
+	// mechanism for this channel (see ChannelFeature*). Stored inverted
+	// so existing configs keep everything on.
+	DisableIRC    bool `json:"disable_irc,omitempty"`
+	DisablePubSub bool `json:"disable_pubsub,omitempty"`
+	DisableSpade  bool `json:"disable_spade,omitempty"`
+}
+
+// featureFlag returns the Disable* field for a ChannelFeature*, or nil
+// for an unknown feature.
+func (e *ChannelEntry) featureFlag(feature string) *bool {
+	switch feature {
+	case ChannelFeatureIRC:
+		return &e.DisableIRC
+	case ChannelFeaturePubSub:
+		return &e.DisablePubSub
+	case ChannelFeatureSpade:
+		return &e.DisableSpade
+	}
+	return nil
+}
+
+// RedeemRule redeems the custom reward titled Reward (case-insensitive)
+// whenever the channel balance is at least MinBalance. Input is sent as
+// the text for rewards that require viewer input; without it such
+// rewards are skipped.
+type RedeemRule struct {
+	Reward     string `json:"reward"`
+	MinBalance int    `json:"min_balance"`
+	Input      string `json:"input,omitempty"`
+}
+
+// PointsClaimEvent teaches the farmer a community-points event type it
+// has no code for: when a message of Type arrives, the claim ID found at
+// IDPath (a dotted path into the message's data object) is claimed the
+// way a bonus chest is.
+type PointsClaimEvent struct {
+	Type        string `json:"type"`
+	IDPath      string `json:"id_path,omitempty"`      // default "claim.id"
+	ChannelPath string `json:"channel_path,omitempty"` // default "channel_id", falling back to "claim.channel_id"
+}
+
+// Defaults for PointsClaimEvent paths.
+const (
+	DefaultPointsClaimIDPath      = "claim.id"
+	DefaultPointsClaimChannelPath = "channel_id"
+)
+
+// LiveHook configures what happens when a watched-for channel goes live
+// (stream_live push event, optional command). Command is an argv list —
+// no shell — whose elements may contain {login}, {url}, {quality},
+// {channel_id}, {game} and {broadcast_id}.
+type LiveHook struct {
+	Command  []string `json:"command,omitempty"`
+	Quality  string   `json:"quality,omitempty"`  // Streamlink quality for {quality}; default "best"
+	Channels []string `json:"channels,omitempty"` // logins to fire for; empty = all P1 channels
+}
+
+// Telegram configures the Telegram bot: notifications go to ChatID,
+// and commands are only accepted from it.
+type Telegram struct {
+	BotToken     string   `json:"bot_token"`
+	ChatID       int64    `json:"chat_id"`                 // 0 = not set up yet: the bot only logs incoming chat IDs
+	Notify       []string `json:"notify,omitempty"`        // TelegramNotify* kinds to send; empty = all
+	LiveChannels []string `json:"live_channels,omitempty"` // logins for go-live messages; empty = all P1 channels
+}
+
+// Notification kinds (Telegram.Notify, Notifier.Notify).
+const (
+	NotifyDrops = "drops" // a drop was claimed
+	NotifyLive  = "live"  // a covered channel went live
+	NotifyAuth  = "auth"  // Twitch rejected the auth token
+	NotifyBonus = "bonus" // a large points gain (desktop notifications only)
+
+	TelegramNotifyDrops = NotifyDrops
+	TelegramNotifyLive  = NotifyLive
+	TelegramNotifyAuth  = NotifyAuth
+)
+
+// Notifies reports whether notifications of kind are enabled.
+func (t Telegram) Notifies(kind string) bool {
+	return notifies(t.Notify, kind)
+}
+
+// CoversLive reports whether a channel going live is announced: the
+// listed channels, or every P1 channel when none are listed (the same
+// rule as live_hook).
+func (t Telegram) CoversLive(login string, priority int) bool {
+	return LiveHook{Channels: t.LiveChannels}.Covers(login, priority)
+}
+
+// Push notification backends (Notifier.Type).
+const (
+	NotifierNtfy     = "ntfy"
+	NotifierGotify   = "gotify"
+	NotifierPushover = "pushover"
+)
+
+// Notifier is a push notification backend (Config.Notifiers) for phone
+// alerts without a chat bot: an ntfy topic, a Gotify server or a
+// Pushover account.
+type Notifier struct {
+	Type         string   `json:"type"`                    // Notifier* backend
+	URL          string   `json:"url,omitempty"`           // ntfy: topic URL (https://ntfy.sh/<topic>); gotify: server URL
+	Token        string   `json:"token,omitempty"`         // ntfy: access token (optional); gotify: app token; pushover: API token
+	User         string   `json:"user,omitempty"`          // pushover: user or group key
+	Notify       []string `json:"notify,omitempty"`        // Notify* kinds to send; empty = all
+	LiveChannels []string `json:"live_channels,omitempty"` // logins for go-live messages; empty = all P1 channels
+}
+
+// Notifies reports whether notifications of kind are enabled.
+func (n Notifier) Notifies(kind string) bool {
+	return notifies(n.Notify, kind)
+}
+
+// CoversLive reports whether a channel going live is announced, like
+// Telegram.CoversLive.
+func (n Notifier) CoversLive(login string, priority int) bool {
+	return LiveHook{Channels: n.LiveChannels}.Covers(login, priority)
+}
+
+// DefaultMinBonus is Desktop.MinBonus when unset.
+const DefaultMinBonus = 1000
+
+// Desktop configures desktop notifications (Config.Desktop): Windows
+// toasts, libnotify on Linux, the macOS Notification Center.
+type Desktop struct {
+	Enabled    bool     `json:"enabled"`
+	Notify     []string `json:"notify,omitempty"`      // NotifyDrops, NotifyBonus, NotifyAuth; empty = all
+	MinBonus   int      `json:"min_bonus,omitempty"`   // points one gain must reach for a bonus notification; 0 = DefaultMinBonus
+	QuietHours string   `json:"quiet_hours,omitempty"` // Window ("23:00-08:00") without notifications; empty = none
+}
+
+// Notifies reports whether notifications of kind are enabled.
+func (d Desktop) Notifies(kind string) bool {
+	return notifies(d.Notify, kind)
+}
+
+// Idle-only farming bounds (Schedule.IdleMinutes).
+const (
+	DefaultIdleMinutes = 10
+	MaxIdleMinutes     = 240
+)
+
+// Schedule limits when the farmer farms (Config.Schedule). Outside of it
+// farming is paused as by the P key: no Spade heartbeats and no claims.
+type Schedule struct {
+	PauseWindows []string `json:"pause_windows,omitempty"` // Windows ("02:00-08:00") with farming paused
+	IdleOnly     bool     `json:"idle_only,omitempty"`     // farm only while the computer has no user input
+	IdleMinutes  int      `json:"idle_minutes,omitempty"`  // minutes without input that count as idle; 0 = DefaultIdleMinutes
+}
+
+// Validate checks the windows and the idle minutes.
+func (s Schedule) Validate() error {
+	for _, w := range s.PauseWindows {
+		if _, err := ParseWindow(w); err != nil {
+			return err
+		}
+	}
+	if s.IdleMinutes < 0 || s.IdleMinutes > MaxIdleMinutes {
+		return fmt.Errorf("idle_minutes must be between 0 (default) and %d", MaxIdleMinutes)
+	}
+	return nil
+}
+
+// IdleLimit returns IdleMinutes, or DefaultIdleMinutes when unset.
+func (s Schedule) IdleLimit() int {
+	if s.IdleMinutes <= 0 {
+		return DefaultIdleMinutes
+	}
+	return s.IdleMinutes
+}
+
+// PauseWindow returns the pause window t falls in. Windows that don't
+// parse are ignored (Validate reports them).
+func (s Schedule) PauseWindow(t time.Time) (Window, bool) {
+	for _, str := range s.PauseWindows {
+		w, err := ParseWindow(str)
+		if err == nil && w.Contains(t) {
+			return w, true
+		}
+	}
+	return Window{}, false
+}
+
+// notifies reports whether kind is in the notify list; an empty list
+// enables every kind.
+func notifies(list []string, kind string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, k := range list {
+		if strings.EqualFold(strings.TrimSpace(k), kind) {
+			return true
+		}
+	}
+	return false
 }
 
 // Config holds the application configuration.
@@ -23,50 +313,121 @@ type ChannelEntry struct {
 // Concurrency: all public methods acquire mu (Lock for mutators,
 // RLock for readers). Slice getters return defensive copies — callers
 // can iterate without holding the lock. Save() takes RLock during
-// marshal and writes via temp-file + atomic rename. Internal callers
-// that already hold mu use the *Locked helpers.
+// marshal and writes via temp-file + atomic rename; SaveSoon() debounces
+// bursts of runtime changes into one Save. Internal callers that already
+// hold mu use the *Locked helpers.
+//
+// Save policy: changes made while running (farmer, web API, TUI) call
+// SaveSoon, whose failures are reported through SetSaveErrorHook; Save
+// is for callers that must know the file was written before going on
+// (CLI commands, a new auth token) and handle its error themselves.
+//
+// Fields tagged reload:"restart" are only read at startup: Reload
+// stores an edit to them, but it takes effect on the next start.
 //
 // The mu field is intentionally lowercase so encoding/json skips it
 // (sync.RWMutex zero-value is fine — no init needed).
 type Config struct {
-	AuthToken          string         `json:"auth_token"`
-	Channels           []string       `json:"channels,omitempty"`            // legacy: simple list
-	ChannelConfigs     []ChannelEntry `json:"channel_configs,omitempty"`     // new: with priority
-	WebEnabled         bool           `json:"web_enabled"`                   // enable web UI
-	WebPort            int            `json:"web_port"`                      // web server port (default 8080)
-	WebBind            string         `json:"web_bind,omitempty"`            // web bind address (default 127.0.0.1; set to 0.0.0.0 for LAN access)
-	IrcEnabled         bool           `json:"irc_enabled"`                   // enable IRC for viewer presence (default true)
-	DropsEnabled       bool           `json:"drops_enabled"`                 // enable drop mining (default true)
-	AutoClaim          bool           `json:"auto_claim"`                    // claim 100%-complete drops automatically (default true)
-	DisabledCampaigns  []string       `json:"disabled_campaigns,omitempty"`  // campaign IDs to skip
-	CompletedCampaigns []string       `json:"completed_campaigns,omitempty"` // campaign IDs already fully claimed
-	PinnedCampaignID   string         `json:"pinned_campaign_id,omitempty"`  // v1.7.0 (deprecated v1.8.0; ignored by selector but kept for backward compat)
-	GamesToWatch       []string       `json:"games_to_watch,omitempty"`      // v1.8.0 ordered priority list of game names; empty = remaining_time fallback
+	AuthToken               string             `json:"auth_token" reload:"restart"`
+	Channels                []string           `json:"channels,omitempty"`                                  // legacy: simple list
+	ChannelConfigs          []ChannelEntry     `json:"channel_configs,omitempty"`                           // new: with priority
+	WebEnabled              bool               `json:"web_enabled" reload:"restart"`                        // enable web UI
+	WebPort                 int                `json:"web_port" reload:"restart"`                           // web server port (default 8080)
+	WebBind                 string             `json:"web_bind,omitempty" reload:"restart"`                 // web bind address (default 127.0.0.1; set to 0.0.0.0 for LAN access)
+	WebToken                string             `json:"web_token,omitempty"`                                 // bearer token for sensitive web endpoints (log download); empty = loopback clients only
+	WebLanguage             string             `json:"web_language,omitempty"`                              // language of web API error messages when the request names none: en, de, fr, es; empty = en
+	WebAPIOnly              bool               `json:"web_api_only,omitempty"`                              // serve the web API without the dashboard's pages
+	IrcEnabled              bool               `json:"irc_enabled"`                                         // enable IRC for viewer presence (default true)
+	IrcSkipTempChannels     bool               `json:"irc_skip_temp_channels,omitempty"`                    // temp drop channels get no IRC JOIN
+	IrcMode                 string             `json:"irc_mode,omitempty"`                                  // "all" (default), "watching" or "off"
+	IrcAnonymous            bool               `json:"irc_anonymous,omitempty" reload:"restart"`            // join IRC as a justinfan guest; the token isn't sent
+	IrcAuthToken            string             `json:"irc_auth_token,omitempty" reload:"restart"`           // OAuth token of a secondary account for IRC presence; empty = auth_token
+	DropsEnabled            bool               `json:"drops_enabled"`                                       // enable drop mining (default true)
+	AutoClaim               bool               `json:"auto_claim"`                                          // claim 100%-complete drops automatically (default true)
+	DisabledCampaigns       []string           `json:"disabled_campaigns,omitempty"`                        // campaign IDs to skip
+	CompletedCampaigns      []string           `json:"completed_campaigns,omitempty"`                       // campaign IDs already fully claimed
+	PinnedCampaignID        string             `json:"pinned_campaign_id,omitempty"`                        // v1.7.0 (deprecated v1.8.0; ignored by selector but kept for backward compat)
+	GamesToWatch            []string           `json:"games_to_watch,omitempty"`                            // v1.8.0 ordered priority list of game names; empty = remaining_time fallback
+	GameAliases             map[string]string  `json:"game_aliases,omitempty"`                              // alternate game name -> the name it should match (see GameKey)
+	Blacklist               []string           `json:"blacklist,omitempty"`                                 // logins and game names auto-selection never adds a channel for (see IsBlacklisted)
+	DropsGamesAllow         []string           `json:"drops_games_allow,omitempty"`                         // only farm campaigns of these games; empty = any game
+	DropsGamesDeny          []string           `json:"drops_games_deny,omitempty"`                          // never farm campaigns of these games
+	OptInCampaigns          []string           `json:"opt_in_campaigns,omitempty"`                          // campaign IDs enabled from the campaign browser; bypass the games_to_watch whitelist
+	Transport               string             `json:"transport,omitempty" reload:"restart"`                // stream up/down transport: "pubsub" (default), "eventsub" or "auto"
+	DropAutoSelect          string             `json:"drop_auto_select,omitempty"`                          // "off", "allowed" or "directory" (default)
+	CampaignAutoSelect      map[string]string  `json:"campaign_auto_select,omitempty"`                      // campaign ID -> auto-select override
+	DropMinProgressPercent  int                `json:"drop_min_progress_percent,omitempty"`                 // skip campaigns with less existing progress; 0 = off
+	MaxTempChannels         int                `json:"max_temp_channels,omitempty"`                         // temporary drop channels tracked at once; 0 = DefaultMaxTempChannels
+	NetworkProfile          string             `json:"network_profile,omitempty" reload:"restart"`          // "default" or "flaky"
+	LogLevel                string             `json:"log_level,omitempty"`                                 // UI feed level: "debug", "info" (default), "warn" or "error"
+	LogFormat               string             `json:"log_format,omitempty" reload:"restart"`               // debug log file and headless stdout format: "text" (default) or "json"
+	PubSubRecordFile        string             `json:"pubsub_record_file,omitempty" reload:"restart"`       // append raw PubSub messages here for replay; empty = off
+	StartupDelaySeconds     int                `json:"startup_delay_seconds,omitempty"`                     // random wait of up to this long before connecting; 0 = none
+	ConnectStaggerSeconds   int                `json:"connect_stagger_seconds,omitempty"`                   // gap between PubSub, IRC and drops start-up; 0 = all at once
+	HeartbeatJitterSeconds  *int               `json:"heartbeat_jitter_seconds,omitempty" reload:"restart"` // ± random spread on the 60s Spade heartbeat; unset = default (10), 0 = off
+	ProxyURL                string             `json:"proxy_url,omitempty" reload:"restart"`                // http:// or socks5:// proxy for all Twitch traffic; empty = direct
+	TimeZone                string             `json:"time_zone,omitempty"`                                 // IANA zone for days, schedules and displayed times; empty = system local
+	APITimestamps           string             `json:"api_timestamps,omitempty"`                            // web API timestamps: "local" (default, in time_zone) or "utc"
+	LiveHook                *LiveHook          `json:"live_hook,omitempty"`                                 // go-live event filter + optional command (Streamlink etc.)
+	Telegram                *Telegram          `json:"telegram,omitempty" reload:"restart"`                 // Telegram bot notifications + commands
+	Notifiers               []Notifier         `json:"notifiers,omitempty" reload:"restart"`                // ntfy / Gotify / Pushover push notifications
+	Desktop                 *Desktop           `json:"desktop,omitempty" reload:"restart"`                  // desktop notifications (toasts)
+	Schedule                *Schedule          `json:"schedule,omitempty"`                                  // pause windows and idle-only farming
+	DisableNotifications    bool               `json:"disable_notifications,omitempty"`                     // mute Telegram and push notifications (commands still work)
+	DisableUpdateCheck      bool               `json:"disable_update_check,omitempty"`                      // don't poll GitHub for new releases
+	DisableConfigLock       bool               `json:"disable_config_lock,omitempty"`                       // save without locking config.json.lock (filesystems without advisory locks)
+	RotationIntervalMinutes int                `json:"rotation_interval_minutes,omitempty"`                 // points rotation interval; 0 = default (5)
+	DropCheckMinutes        int                `json:"drop_check_minutes,omitempty"`                        // drops inventory check interval; 0 = default (15), adapted at runtime
+	StreakWindowMinutes     int                `json:"streak_window_minutes,omitempty"`                     // Streak-Hunt window after stream start; 0 = default (30)
+	StreakPreservation      bool               `json:"streak_preservation,omitempty"`                       // streak candidates outrank P0 and are rotated in on stream-up
+	MinViewers              int                `json:"min_viewers,omitempty"`                               // rotation skips live channels with fewer viewers; 0 = off
+	RerunMode               string             `json:"rerun_mode,omitempty"`                                // "watch" (default), "deprioritize" or "skip"
+	WeightSubMultipliers    bool               `json:"weight_sub_multipliers,omitempty"`                    // rotation favors channels with a points multiplier (subscriptions)
+	StaleViewersMinutes     int                `json:"stale_viewers_minutes,omitempty"`                     // rotation skips channels whose viewer count hasn't moved this long; 0 = off
+	PointsClaimEvents       []PointsClaimEvent `json:"points_claim_events,omitempty"`                       // new community-points event types to claim like bonus chests
+	QuitToBackground        bool               `json:"quit_to_background,omitempty"`                        // Linux/macOS: 'q' detaches the TUI and keeps farming in a background process
+	AuditLog                bool               `json:"audit_log,omitempty"`                                 // append every claim, raid join and redemption to audit.jsonl
 
 	path   string       // file path, not serialized
 	mu     sync.RWMutex // guards all mutable fields above; not serialized
 	saveMu sync.Mutex   // serializes Save() — separate from mu so concurrent reads aren't blocked during marshal+rename
+
+	// The file as last read or written, guarded by saveMu: Reload
+	// merges external edits against it. bad is the last version Reload
+	// could not parse, so it is reported once.
+	base []byte
+	bad  []byte
+
+	// format is the file's syntax, set by Load from the path. env holds
+	// the settings taken from TWITCHPOINT_* variables (see applyEnv),
+	// guarded by saveMu.
+	format fileFormat
+	env    map[string]envValue
+
+	// SaveSoon state, guarded by debounceMu
+	debounceMu  sync.Mutex
+	saveTimer   *time.Timer // fires the debounced save; reused
+	savePending bool        // changes not yet written by Save
+	onSaveError func(error) // reports failed debounced saves
 }
 
+// saveDebounce is how long SaveSoon waits for further changes before
+// writing.
+const saveDebounce = 500 * time.Millisecond
+
 // Load reads the config from the given path. If path is empty, uses the default.
+// A .yaml/.yml or .toml path is read (and saved) as YAML or TOML.
 // Returns a default config if the file doesn't exist.
+// TWITCHPOINT_* environment variables override the file (see env.go).
 // Auto-saves if migration adds new fields.
 func Load(path string) (*Config, error) {
 	if path == "" {
-		exe, err := os.Executable()
-		if err != nil {
-			path = defaultConfigFile
-		} else {
-			path = filepath.Join(filepath.Dir(exe), defaultConfigFile)
-		}
-		// If executable-relative doesn't exist, fall back to CWD
-		if _, err := os.Stat(path); os.IsNotExist(err) {
-			path = defaultConfigFile
-		}
+		path = defaultPath()
 	}
 
 	cfg := &Config{
 		path:         path,
+		format:       formatOf(path),
 		WebEnabled:   true,        // default
 		WebPort:      8080,        // default
 		WebBind:      "127.0.0.1", // default — localhost-only; set "0.0.0.0" for LAN
@@ -77,6 +438,9 @@ func Load(path string) (*Config, error) {
 
 	data, err := os.ReadFile(path)
 	if os.IsNotExist(err) {
+		if err := cfg.applyEnv(nil); err != nil {
+			return nil, err
+		}
 		return cfg, nil
 	}
 	if err != nil {
@@ -85,7 +449,14 @@ func Load(path string) (*Config, error) {
 
 	// Parse raw JSON to detect missing fields
 	var raw map[string]json.RawMessage
-	if err := json.Unmarshal(data, &raw); err != nil {
+	data, err = cfg.format.toJSON(data)
+	if err == nil {
+		err = json.Unmarshal(data, &raw)
+	}
+	if err != nil {
+		if _, bakErr := os.Stat(path + ".bak"); bakErr == nil {
+			return nil, fmt.Errorf("parsing config: %w (the previous version is in %s.bak)", err, path)
+		}
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
 
@@ -93,30 +464,15 @@ func Load(path string) (*Config, error) {
 		return nil, fmt.Errorf("parsing config: %w", err)
 	}
 	cfg.path = path
-
-	// Set defaults if not in file
-	if _, hasWebEnabled := raw["web_enabled"]; !hasWebEnabled {
-		cfg.WebEnabled = true
-	}
-	if _, hasPort := raw["web_port"]; !hasPort {
-		cfg.WebPort = 8080
-	}
-	if _, hasIrc := raw["irc_enabled"]; !hasIrc {
-		cfg.IrcEnabled = true
-	}
-	if _, hasDrops := raw["drops_enabled"]; !hasDrops {
-		cfg.DropsEnabled = true
-	}
-	if _, hasAutoClaim := raw["auto_claim"]; !hasAutoClaim {
-		cfg.AutoClaim = true
-	}
-	if _, hasBind := raw["web_bind"]; !hasBind || strings.TrimSpace(cfg.WebBind) == "" {
-		cfg.WebBind = "127.0.0.1"
-	}
+	cfg.applyDefaults(raw)
+	cfg.base = data
 
 	// Migrate legacy channels and detect if new fields need to be written.
 	// Load is single-goroutine — no lock needed yet.
 	needsSave := cfg.migrate()
+	if err := cfg.applyEnv(raw); err != nil {
+		return nil, err
+	}
 
 	// Remove stale exclusive_drops field from config
 	if _, hasExclusiveDrops := raw["exclusive_drops"]; hasExclusiveDrops {
@@ -133,14 +489,42 @@ func Load(path string) (*Config, error) {
 		needsSave = true
 	}
 
-	// Auto-save to add new fields to existing config
-	if needsSave {
-		_ = cfg.Save() // ignore error, not critical
+	// Auto-save to add new fields to existing config. A YAML or TOML
+	// file is left as written until something changes at runtime.
+	if needsSave && cfg.format == formatJSON {
+		if err := cfg.Save(); err != nil {
+			// The file loaded fine; leave the upgrade pending so the
+			// next save or Flush writes it, reporting it if that fails.
+			cfg.savePending = true
+		}
 	}
 
 	return cfg, nil
 }
 
+// applyDefaults sets the defaults of the fields the file (raw) leaves
+// out. Caller holds mu or owns c.
+func (c *Config) applyDefaults(raw map[string]json.RawMessage) {
+	if _, hasWebEnabled := raw["web_enabled"]; !hasWebEnabled {
+		c.WebEnabled = true
+	}
+	if _, hasPort := raw["web_port"]; !hasPort {
+		c.WebPort = 8080
+	}
+	if _, hasIrc := raw["irc_enabled"]; !hasIrc {
+		c.IrcEnabled = true
+	}
+	if _, hasDrops := raw["drops_enabled"]; !hasDrops {
+		c.DropsEnabled = true
+	}
+	if _, hasAutoClaim := raw["auto_claim"]; !hasAutoClaim {
+		c.AutoClaim = true
+	}
+	if _, hasBind := raw["web_bind"]; !hasBind || strings.TrimSpace(c.WebBind) == "" {
+		c.WebBind = "127.0.0.1"
+	}
+}
+
 // migrate converts legacy Channels list to ChannelConfigs.
 // Returns true if any changes were made. Caller is single-goroutine
 // (Load), no lock needed.
@@ -167,9 +551,12 @@ func (c *Config) migrate() bool {
 
 // Save writes the config back to disk using a temp-file + atomic rename
 // so concurrent readers (other processes, file watchers) never see a
-// torn write.
+// torn write. The version it replaces is kept as config.json.bak. A
+// YAML or TOML file is written in its own format. YAML comments are
+// kept; TOML comments are not, so the first rewrite of a TOML file with
+// comments keeps it as config.toml.orig.
 //
-// Two layers of locking:
+// Three layers of locking:
 //   - saveMu serializes the WHOLE Save call (marshal → temp write →
 //     rename). Without it, two concurrent Saves can interleave like
 //     "A marshal, B marshal, B rename, A rename" — A's older snapshot
@@ -178,16 +565,37 @@ func (c *Config) migrate() bool {
 //     from racing with the read but other concurrent readers (UI, web
 //     /api/*) can still proceed. Released before the I/O so disk
 //     latency doesn't stall live readers.
+//   - an advisory lock on config.json.lock around the write, so other
+//     instances sharing the file (a service plus a CLI login, say)
+//     take turns. Best effort: without it the save goes ahead. Off
+//     with disable_config_lock, and for a config without a path.
 func (c *Config) Save() error {
 	c.saveMu.Lock()
 	defer c.saveMu.Unlock()
 
+	// This write covers whatever SaveSoon was waiting to write.
+	c.debounceMu.Lock()
+	c.savePending = false
+	c.debounceMu.Unlock()
+
 	c.mu.RLock()
-	data, err := json.MarshalIndent(c, "", "  ")
+	data, err := c.marshalFile()
+	lock := c.path != "" && !c.DisableConfigLock
 	c.mu.RUnlock()
 	if err != nil {
 		return fmt.Errorf("marshaling config: %w", err)
 	}
+	out, err := c.format.fromJSON(data)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+	if old, err := os.ReadFile(c.path); err == nil {
+		out = c.format.keepComments(old, out)
+	}
+	// What Reload will read back from out.
+	if data, err = c.format.toJSON(out); err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
 
 	// Write to a temp file in the same directory (so rename stays
 	// atomic across the same filesystem) then rename over the target.
@@ -200,11 +608,16 @@ func (c *Config) Save() error {
 
 	cleanup := func() { _ = os.Remove(tmpPath) }
 
-	if _, err := tmp.Write(data); err != nil {
+	if _, err := tmp.Write(out); err != nil {
 		_ = tmp.Close()
 		cleanup()
 		return fmt.Errorf("writing temp config: %w", err)
 	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		cleanup()
+		return fmt.Errorf("syncing temp config: %w", err)
+	}
 	if err := tmp.Chmod(0600); err != nil {
 		_ = tmp.Close()
 		cleanup()
@@ -214,13 +627,97 @@ func (c *Config) Save() error {
 		cleanup()
 		return fmt.Errorf("closing temp config: %w", err)
 	}
+
+	if lock {
+		unlock := lockFile(c.path + ".lock")
+		defer unlock()
+	}
+	if old, err := os.ReadFile(c.path); err == nil && len(old) > 0 && !bytes.Equal(old, out) {
+		_ = os.WriteFile(c.path+".bak", old, 0600)
+		if _, err := os.Stat(c.path + ".orig"); os.IsNotExist(err) && c.format.dropsComments(old) {
+			_ = os.WriteFile(c.path+".orig", old, 0600)
+		}
+	}
 	if err := os.Rename(tmpPath, c.path); err != nil {
 		cleanup()
 		return fmt.Errorf("renaming temp config: %w", err)
 	}
+	// Our own write is not an external edit for Reload.
+	c.base = data
 	return nil
 }
 
+// defaultPath finds the config file when no path is given: the first of
+// configFileNames next to the executable, then in the working directory.
+// Without one it is config.json in the working directory.
+func defaultPath() string {
+	var dirs []string
+	if exe, err := os.Executable(); err == nil {
+		dirs = append(dirs, filepath.Dir(exe))
+	}
+	dirs = append(dirs, ".")
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if _, err := os.Stat(path); err == nil {
+				if dir == "." {
+					return name
+				}
+				return path
+			}
+		}
+	}
+	return defaultConfigFile
+}
+
+// SaveSoon saves the config once no further SaveSoon call has come in
+// for saveDebounce, so a burst of runtime changes (bulk adds, priority
+// clicks, campaign toggles) ends in a single write of the final state.
+// Failures go to the SetSaveErrorHook callback. Call Flush before
+// exiting so a pending save isn't lost.
+func (c *Config) SaveSoon() {
+	c.debounceMu.Lock()
+	defer c.debounceMu.Unlock()
+	c.savePending = true
+	if c.saveTimer == nil {
+		c.saveTimer = time.AfterFunc(saveDebounce, c.saveDebounced)
+		return
+	}
+	c.saveTimer.Reset(saveDebounce)
+}
+
+// Flush writes a save scheduled by SaveSoon right away. Without one
+// pending it does nothing.
+func (c *Config) Flush() error {
+	c.debounceMu.Lock()
+	pending := c.savePending
+	if c.saveTimer != nil {
+		c.saveTimer.Stop()
+	}
+	c.debounceMu.Unlock()
+	if !pending {
+		return nil
+	}
+	return c.Save()
+}
+
+func (c *Config) saveDebounced() {
+	err := c.Flush()
+	c.debounceMu.Lock()
+	hook := c.onSaveError
+	c.debounceMu.Unlock()
+	if err != nil && hook != nil {
+		hook(err)
+	}
+}
+
+// SetSaveErrorHook sets the callback for saves SaveSoon couldn't write.
+func (c *Config) SetSaveErrorHook(fn func(error)) {
+	c.debounceMu.Lock()
+	defer c.debounceMu.Unlock()
+	c.onSaveError = fn
+}
+
 // Path returns the config file path. Path is set once at Load and never
 // mutated afterwards — no lock needed.
 func (c *Config) Path() string {
@@ -247,16 +744,25 @@ func (c *Config) GetChannelEntries() []ChannelEntry {
 	return result
 }
 
-// UpdateChannelLogin updates the login for a channel identified by ID.
-// Returns true if the channel was found and updated.
-func (c *Config) UpdateChannelLogin(channelID, newLogin string) bool {
+// RenameChannel moves the channel with ID channelID from oldLogin to
+// newLogin, if its entry still has oldLogin: a compare-and-swap, so of
+// two callers racing to apply the same rename only one gets true. An
+// entry from before IDs were stored is matched by oldLogin and gets the
+// ID attached.
+func (c *Config) RenameChannel(channelID, oldLogin, newLogin string) bool {
+	oldLogin, newLogin = strings.ToLower(oldLogin), strings.ToLower(newLogin)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for i, cc := range c.ChannelConfigs {
-		if cc.ID == channelID {
-			c.ChannelConfigs[i].Login = newLogin
-			return true
+		if cc.ID != channelID && (cc.ID != "" || cc.Login != oldLogin) {
+			continue
+		}
+		if cc.Login != oldLogin {
+			return false
 		}
+		c.ChannelConfigs[i].ID = channelID
+		c.ChannelConfigs[i].Login = newLogin
+		return true
 	}
 	return false
 }
@@ -304,120 +810,386 @@ func (c *Config) SetPriority(login string, priority int) bool {
 	return false
 }
 
-// AddChannel adds a channel if not already present.
-func (c *Config) AddChannel(login string) bool {
+// IsMomentsEnabled reports whether Moments auto-claim is on for a
+// channel. Channels not in config (temp drop channels, untracked
+// channels delivered via the user-level topic) default to enabled.
+func (c *Config) IsMomentsEnabled(login string) bool {
 	login = strings.ToLower(login)
-	c.mu.Lock()
-	defer c.mu.Unlock()
+	c.mu.RLock()
+	defer c.mu.RUnlock()
 	for _, cc := range c.ChannelConfigs {
 		if cc.Login == login {
-			return false
+			return !cc.DisableMoments
 		}
 	}
-	c.ChannelConfigs = append(c.ChannelConfigs, ChannelEntry{
-		Login:    login,
-		Priority: 2,
-	})
 	return true
 }
 
-// RemoveChannel removes a channel. Returns true if found and removed.
-func (c *Config) RemoveChannel(login string) bool {
+// SetMomentsEnabled toggles Moments auto-claim for a channel. Returns
+// false if the channel is not in config.
+func (c *Config) SetMomentsEnabled(login string, enabled bool) bool {
 	login = strings.ToLower(login)
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	for i, cc := range c.ChannelConfigs {
 		if cc.Login == login {
-			c.ChannelConfigs = append(c.ChannelConfigs[:i], c.ChannelConfigs[i+1:]...)
+			c.ChannelConfigs[i].DisableMoments = !enabled
 			return true
 		}
 	}
 	return false
 }
 
-// IsCampaignDisabled returns true if the campaign ID is in the disabled list.
-func (c *Config) IsCampaignDisabled(campaignID string) bool {
+// IsChannelFeatureEnabled reports whether a presence mechanism
+// (ChannelFeature*) is on for a channel. Channels not in config (temp
+// drop channels) have everything on.
+func (c *Config) IsChannelFeatureEnabled(login, feature string) bool {
+	login = strings.ToLower(login)
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.isCampaignDisabledLocked(campaignID)
-}
-
-// isCampaignDisabledLocked is the unlocked variant for callers that
-// already hold mu (mutators that need to dedup-check before append).
-func (c *Config) isCampaignDisabledLocked(campaignID string) bool {
-	for _, id := range c.DisabledCampaigns {
-		if id == campaignID {
-			return true
+	for i := range c.ChannelConfigs {
+		if c.ChannelConfigs[i].Login == login {
+			flag := c.ChannelConfigs[i].featureFlag(feature)
+			return flag == nil || !*flag
 		}
 	}
-	return false
+	return true
 }
 
-// SetCampaignEnabled adds or removes a campaign ID from the disabled list.
-// enabled=true removes from disabled, enabled=false adds to disabled.
-func (c *Config) SetCampaignEnabled(campaignID string, enabled bool) {
+// SetChannelFeatureEnabled turns a presence mechanism on or off for a
+// channel. Returns false if the channel is not in config or the feature
+// is unknown.
+func (c *Config) SetChannelFeatureEnabled(login, feature string, enabled bool) bool {
+	login = strings.ToLower(login)
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	if enabled {
-		// Remove from disabled list
-		for i, id := range c.DisabledCampaigns {
-			if id == campaignID {
-				c.DisabledCampaigns = append(c.DisabledCampaigns[:i], c.DisabledCampaigns[i+1:]...)
-				return
+	for i := range c.ChannelConfigs {
+		if c.ChannelConfigs[i].Login == login {
+			flag := c.ChannelConfigs[i].featureFlag(feature)
+			if flag == nil {
+				return false
 			}
+			*flag = !enabled
+			return true
 		}
-	} else {
-		// Add to disabled list if not already present
-		if !c.isCampaignDisabledLocked(campaignID) {
-			c.DisabledCampaigns = append(c.DisabledCampaigns, campaignID)
+	}
+	return false
+}
+
+// GetRedeemRules returns a copy of a channel's auto-redeem rules, or nil
+// for channels not in config.
+func (c *Config) GetRedeemRules(login string) []RedeemRule {
+	login = strings.ToLower(login)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login && len(cc.Redeem) > 0 {
+			rules := make([]RedeemRule, len(cc.Redeem))
+			copy(rules, cc.Redeem)
+			return rules
 		}
 	}
+	return nil
 }
 
-// IsCampaignCompleted checks if a campaign has been fully claimed.
-func (c *Config) IsCampaignCompleted(campaignID string) bool {
+// GetPointsGoal returns a channel's target balance, or 0 when it has
+// none (including channels not in config).
+func (c *Config) GetPointsGoal(login string) int {
+	login = strings.ToLower(login)
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.isCampaignCompletedLocked(campaignID)
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			return cc.Goal
+		}
+	}
+	return 0
 }
 
-// isCampaignCompletedLocked is the unlocked variant for callers that
-// already hold mu (e.g. MarkCampaignCompleted's append guard).
-func (c *Config) isCampaignCompletedLocked(campaignID string) bool {
-	for _, id := range c.CompletedCampaigns {
-		if id == campaignID {
+// SetPointsGoal sets a channel's target balance (0 clears it). Returns
+// false if the channel is not in config.
+func (c *Config) SetPointsGoal(login string, goal int) bool {
+	login = strings.ToLower(login)
+	if goal < 0 {
+		goal = 0
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			c.ChannelConfigs[i].Goal = goal
 			return true
 		}
 	}
 	return false
 }
 
-// MarkCampaignCompleted adds a campaign ID to the completed list.
-func (c *Config) MarkCampaignCompleted(campaignID string) {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	if !c.isCampaignCompletedLocked(campaignID) {
-		c.CompletedCampaigns = append(c.CompletedCampaigns, campaignID)
+// GetMaxPoints returns a channel's balance cap (0 = none).
+func (c *Config) GetMaxPoints(login string) int {
+	login = strings.ToLower(login)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			return cc.MaxPoints
+		}
 	}
+	return 0
 }
 
-// SetPinnedCampaign atomically sets the pinned campaign. Pass empty string to clear.
-// Only one campaign can be pinned at a time — calling this overwrites any previous pin.
-func (c *Config) SetPinnedCampaign(campaignID string) {
+// SetMaxPoints sets a channel's balance cap (0 clears it). Returns false
+// if the channel is not in config.
+func (c *Config) SetMaxPoints(login string, limit int) bool {
+	login = strings.ToLower(login)
+	if limit < 0 {
+		limit = 0
+	}
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.PinnedCampaignID = campaignID
+	for i, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			c.ChannelConfigs[i].MaxPoints = limit
+			return true
+		}
+	}
+	return false
 }
 
-// IsCampaignPinned returns true if the given campaign is the currently pinned one.
-func (c *Config) IsCampaignPinned(campaignID string) bool {
-	c.mu.RLock()
-	defer c.mu.RUnlock()
-	return c.PinnedCampaignID != "" && c.PinnedCampaignID == campaignID
+// validChannelMode normalizes a channel mode; ok is false for anything
+// but the ChannelMode* constants ("both" is accepted for the default).
+func validChannelMode(mode string) (string, bool) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case ChannelModeBoth, "both":
+		return ChannelModeBoth, true
+	case ChannelModePoints, ChannelModeDrops:
+		return m, true
+	}
+	return "", false
 }
 
-// GetPinnedCampaign returns the currently pinned campaign ID, or empty string if none.
-func (c *Config) GetPinnedCampaign() string {
+// GetChannelMode returns a channel's mode. Channels not in config
+// (temporary drop channels) and unknown values mean ChannelModeBoth.
+func (c *Config) GetChannelMode(login string) string {
+	login = strings.ToLower(login)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			m, _ := validChannelMode(cc.Mode)
+			return m
+		}
+	}
+	return ChannelModeBoth
+}
+
+// SetChannelMode sets a channel's mode. Returns false if the channel is
+// not in config or the mode is unknown.
+func (c *Config) SetChannelMode(login, mode string) bool {
+	m, ok := validChannelMode(mode)
+	if !ok {
+		return false
+	}
+	login = strings.ToLower(login)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			c.ChannelConfigs[i].Mode = m
+			return true
+		}
+	}
+	return false
+}
+
+// IsChannelPaused reports whether a channel is excluded from the watch
+// rotation. Channels not in config are never paused.
+func (c *Config) IsChannelPaused(login string) bool {
+	login = strings.ToLower(login)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			return cc.Paused
+		}
+	}
+	return false
+}
+
+// SetChannelPaused pauses or resumes a channel. Returns false if the
+// channel is not in config.
+func (c *Config) SetChannelPaused(login string, paused bool) bool {
+	login = strings.ToLower(login)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			c.ChannelConfigs[i].Paused = paused
+			return true
+		}
+	}
+	return false
+}
+
+// AddChannel adds a channel if not already present.
+func (c *Config) AddChannel(login string) bool {
+	login = strings.ToLower(login)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			return false
+		}
+	}
+	c.ChannelConfigs = append(c.ChannelConfigs, ChannelEntry{
+		Login:    login,
+		Priority: 2,
+	})
+	return true
+}
+
+// RemoveChannel removes a channel. Returns true if found and removed.
+func (c *Config) RemoveChannel(login string) bool {
+	login = strings.ToLower(login)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, cc := range c.ChannelConfigs {
+		if cc.Login == login {
+			c.ChannelConfigs = append(c.ChannelConfigs[:i], c.ChannelConfigs[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// IsCampaignDisabled returns true if the campaign ID is in the disabled list.
+func (c *Config) IsCampaignDisabled(campaignID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isCampaignDisabledLocked(campaignID)
+}
+
+// isCampaignDisabledLocked is the unlocked variant for callers that
+// already hold mu (mutators that need to dedup-check before append).
+func (c *Config) isCampaignDisabledLocked(campaignID string) bool {
+	for _, id := range c.DisabledCampaigns {
+		if id == campaignID {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCampaignEnabled adds or removes a campaign ID from the disabled list.
+// enabled=true removes from disabled, enabled=false adds to disabled.
+func (c *Config) SetCampaignEnabled(campaignID string, enabled bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if enabled {
+		// Remove from disabled list
+		for i, id := range c.DisabledCampaigns {
+			if id == campaignID {
+				c.DisabledCampaigns = append(c.DisabledCampaigns[:i], c.DisabledCampaigns[i+1:]...)
+				return
+			}
+		}
+	} else {
+		// Add to disabled list if not already present
+		if !c.isCampaignDisabledLocked(campaignID) {
+			c.DisabledCampaigns = append(c.DisabledCampaigns, campaignID)
+		}
+	}
+}
+
+// IsCampaignOptedIn returns true if the user explicitly enabled the
+// campaign from the campaign browser.
+func (c *Config) IsCampaignOptedIn(campaignID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isCampaignOptedInLocked(campaignID)
+}
+
+// isCampaignOptedInLocked is the unlocked variant for callers that
+// already hold mu.
+func (c *Config) isCampaignOptedInLocked(campaignID string) bool {
+	for _, id := range c.OptInCampaigns {
+		if id == campaignID {
+			return true
+		}
+	}
+	return false
+}
+
+// SetCampaignOptIn adds or removes a campaign ID from the opt-in list.
+// Opting in also clears the campaign from the disabled list — an
+// explicit "farm this" must not be silently overridden by an older
+// disable toggle.
+func (c *Config) SetCampaignOptIn(campaignID string, optIn bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !optIn {
+		for i, id := range c.OptInCampaigns {
+			if id == campaignID {
+				c.OptInCampaigns = append(c.OptInCampaigns[:i], c.OptInCampaigns[i+1:]...)
+				return
+			}
+		}
+		return
+	}
+	if !c.isCampaignOptedInLocked(campaignID) {
+		c.OptInCampaigns = append(c.OptInCampaigns, campaignID)
+	}
+	for i, id := range c.DisabledCampaigns {
+		if id == campaignID {
+			c.DisabledCampaigns = append(c.DisabledCampaigns[:i], c.DisabledCampaigns[i+1:]...)
+			break
+		}
+	}
+}
+
+// IsCampaignCompleted checks if a campaign has been fully claimed.
+func (c *Config) IsCampaignCompleted(campaignID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.isCampaignCompletedLocked(campaignID)
+}
+
+// isCampaignCompletedLocked is the unlocked variant for callers that
+// already hold mu (e.g. MarkCampaignCompleted's append guard).
+func (c *Config) isCampaignCompletedLocked(campaignID string) bool {
+	for _, id := range c.CompletedCampaigns {
+		if id == campaignID {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkCampaignCompleted adds a campaign ID to the completed list.
+func (c *Config) MarkCampaignCompleted(campaignID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.isCampaignCompletedLocked(campaignID) {
+		c.CompletedCampaigns = append(c.CompletedCampaigns, campaignID)
+	}
+}
+
+// SetPinnedCampaign atomically sets the pinned campaign. Pass empty string to clear.
+// Only one campaign can be pinned at a time — calling this overwrites any previous pin.
+func (c *Config) SetPinnedCampaign(campaignID string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.PinnedCampaignID = campaignID
+}
+
+// IsCampaignPinned returns true if the given campaign is the currently pinned one.
+func (c *Config) IsCampaignPinned(campaignID string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.PinnedCampaignID != "" && c.PinnedCampaignID == campaignID
+}
+
+// GetPinnedCampaign returns the currently pinned campaign ID, or empty string if none.
+func (c *Config) GetPinnedCampaign() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.PinnedCampaignID
@@ -432,6 +1204,45 @@ func (c *Config) GetGamesToWatch() []string {
 	return out
 }
 
+// NormalizeGameName folds a game name for matching: case, trademark
+// signs, apostrophes, punctuation and whitespace runs are ignored, so
+// "Tom Clancy's Rainbow Six® Siege" and "tom clancys rainbow six: siege"
+// compare equal. Letters and digits of any script are kept.
+func NormalizeGameName(name string) string {
+	var b strings.Builder
+	gap := false
+	for _, r := range strings.ToLower(name) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if gap && b.Len() > 0 {
+				b.WriteByte(' ')
+			}
+			gap = false
+			b.WriteRune(r)
+		case r == '\'' || r == '’' || r == '™' || r == '®' || r == '©':
+			// Dropped without a gap: "Clancy's" -> "clancys".
+		default:
+			gap = true
+		}
+	}
+	return b.String()
+}
+
+// GameKey is what drop matching compares game names by when no game ID
+// is available: the normalized name, mapped through game_aliases so
+// editions or renamed titles can be pointed at the name campaigns use.
+func (c *Config) GameKey(name string) string {
+	key := NormalizeGameName(name)
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for alias, target := range c.GameAliases {
+		if NormalizeGameName(alias) == key {
+			return NormalizeGameName(target)
+		}
+	}
+	return key
+}
+
 // AddGameToWatch appends a game to the end of the priority list if not already present (case-insensitive).
 func (c *Config) AddGameToWatch(game string) {
 	game = strings.TrimSpace(game)
@@ -500,6 +1311,86 @@ func (c *Config) SetGamesToWatch(games []string) {
 	c.GamesToWatch = out
 }
 
+// GetBlacklist returns the auto-selection blacklist (defensive copy).
+func (c *Config) GetBlacklist() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]string, len(c.Blacklist))
+	copy(out, c.Blacklist)
+	return out
+}
+
+// SetBlacklist replaces the blacklist, trimming entries and dropping
+// blanks and case-insensitive duplicates.
+func (c *Config) SetBlacklist(entries []string) {
+	out := make([]string, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		key := strings.ToLower(strings.TrimSpace(e))
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		out = append(out, strings.TrimSpace(e))
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Blacklist = out
+}
+
+// IsBlacklisted reports whether auto-selection must not add a channel:
+// an entry equals its login (ignoring case) or names the game it
+// streams (compared by GameKey). Configured channels are the user's own
+// choice; callers only ask about channels they would add themselves.
+func (c *Config) IsBlacklisted(login, gameName string) bool {
+	entries := c.GetBlacklist()
+	if len(entries) == 0 {
+		return false
+	}
+	game := ""
+	if gameName != "" {
+		game = c.GameKey(gameName)
+	}
+	for _, e := range entries {
+		if strings.EqualFold(e, login) || (game != "" && c.GameKey(e) == game) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDropGameDenied reports whether drops_games_deny names gameName
+// (compared by GameKey): its campaigns are never farmed.
+func (c *Config) IsDropGameDenied(gameName string) bool {
+	c.mu.RLock()
+	deny := c.DropsGamesDeny
+	c.mu.RUnlock()
+	return c.gameListed(deny, gameName)
+}
+
+// IsDropGameAllowed reports whether drops_games_allow lets the drop
+// engine farm campaigns of gameName: it is empty or names the game.
+func (c *Config) IsDropGameAllowed(gameName string) bool {
+	c.mu.RLock()
+	allow := c.DropsGamesAllow
+	c.mu.RUnlock()
+	return len(allow) == 0 || c.gameListed(allow, gameName)
+}
+
+// gameListed reports whether one of games names gameName (by GameKey).
+func (c *Config) gameListed(games []string, gameName string) bool {
+	if len(games) == 0 || gameName == "" {
+		return false
+	}
+	key := c.GameKey(gameName)
+	for _, g := range games {
+		if c.GameKey(g) == key {
+			return true
+		}
+	}
+	return false
+}
+
 // UnmarkCampaignCompleted removes a campaign ID from the completed list.
 // Used by daily-rolling-campaign scrub when Twitch resets a campaign's drops.
 func (c *Config) UnmarkCampaignCompleted(campaignID string) {
@@ -536,9 +1427,9 @@ func (c *Config) GetDropsEnabled() bool {
 	return c.DropsEnabled
 }
 
-// SetDropsEnabled toggles the drops-mining-enabled flag. The change
-// takes effect on the next farmer restart — the running drops loops
-// don't honor it dynamically.
+// SetDropsEnabled toggles the drops-mining-enabled flag. The running
+// drops loops don't honor it on their own; Farmer.SetSubsystemEnabled
+// starts or stops them along with the change.
 func (c *Config) SetDropsEnabled(v bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -565,48 +1456,798 @@ func (c *Config) SetAutoClaim(v bool) {
 	c.AutoClaim = v
 }
 
-// GetIrcEnabled returns the IRC-presence-enabled flag.
+// GetIrcEnabled returns the IRC-presence-enabled flag: irc_enabled,
+// unless irc_mode is "off".
 func (c *Config) GetIrcEnabled() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.IrcEnabled
+	return c.IrcEnabled && normalizeIrcMode(c.IrcMode) != IrcModeOff
 }
 
-// SetIrcEnabled toggles the IRC-presence-enabled flag (restart required).
+// SetIrcEnabled toggles the IRC-presence-enabled flag. Like
+// SetDropsEnabled it takes a Farmer.SetSubsystemEnabled call to apply to
+// a running farmer. Switching it on clears an irc_mode of "off".
 func (c *Config) SetIrcEnabled(v bool) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 	c.IrcEnabled = v
+	if v && normalizeIrcMode(c.IrcMode) == IrcModeOff {
+		c.IrcMode = ""
+	}
 }
 
-// GetWebEnabled returns the web-UI-enabled flag.
-func (c *Config) GetWebEnabled() bool {
+// GetIrcMode returns which channels IRC joins, normalized to one of the
+// IrcMode* constants: IrcModeOff whenever IRC is disabled, IrcModeAll
+// for unknown values.
+func (c *Config) GetIrcMode() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.WebEnabled
+	if !c.IrcEnabled {
+		return IrcModeOff
+	}
+	return normalizeIrcMode(c.IrcMode)
 }
 
-// SetWebEnabled toggles the web-UI-enabled flag (restart required).
-func (c *Config) SetWebEnabled(v bool) {
+// SetIrcMode sets the IRC presence mode; "off" also clears irc_enabled
+// and the others set it. Returns false for anything but the IrcMode*
+// constants.
+func (c *Config) SetIrcMode(m string) bool {
+	if CheckIrcMode(m) != nil {
+		return false
+	}
+	m = strings.ToLower(strings.TrimSpace(m))
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	c.WebEnabled = v
+	c.IrcEnabled = m != IrcModeOff
+	c.IrcMode = m
+	return true
 }
 
-// GetWebPort returns the configured web server port.
-func (c *Config) GetWebPort() int {
+func normalizeIrcMode(m string) string {
+	switch m = strings.ToLower(strings.TrimSpace(m)); m {
+	case IrcModeWatching, IrcModeOff:
+		return m
+	}
+	return IrcModeAll
+}
+
+// GetIrcSkipTempChannels reports whether temporary drop channels are
+// kept out of IRC presence.
+func (c *Config) GetIrcSkipTempChannels() bool {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
-	return c.WebPort
+	return c.IrcSkipTempChannels
 }
 
-// GetWebBind returns the configured web server bind address.
-func (c *Config) GetWebBind() string {
+// SetIrcSkipTempChannels toggles IRC presence for temporary drop
+// channels. Live — the farmer re-syncs IRC on change.
+func (c *Config) SetIrcSkipTempChannels(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.IrcSkipTempChannels = v
+}
+
+// GetIrcAnonymous reports whether IRC logs in as an anonymous justinfan
+// guest instead of with the auth token.
+func (c *Config) GetIrcAnonymous() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.IrcAnonymous
+}
+
+// GetIrcAuthToken returns the token of the account IRC logs in as
+// instead of the main one; empty when IRC uses auth_token.
+func (c *Config) GetIrcAuthToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.TrimSpace(c.IrcAuthToken)
+}
+
+// GetWebEnabled returns the web-UI-enabled flag.
+func (c *Config) GetWebEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.WebEnabled
+}
+
+// SetWebEnabled toggles the web-UI-enabled flag (restart required).
+func (c *Config) SetWebEnabled(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.WebEnabled = v
+}
+
+// GetWebPort returns the configured web server port.
+func (c *Config) GetWebPort() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.WebPort
+}
+
+// SetWebPort sets the web server port (takes effect after a restart).
+// Returns false outside 1..65535.
+func (c *Config) SetWebPort(port int) bool {
+	if CheckWebPort(port) != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.WebPort = port
+	return true
+}
+
+// GetWebBind returns the configured web server bind address.
+func (c *Config) GetWebBind() string {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 	return c.WebBind
 }
 
+// GetWebToken returns the bearer token guarding sensitive web endpoints.
+func (c *Config) GetWebToken() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.WebToken
+}
+
+// GetWebAPIOnly reports whether the web server leaves out the dashboard
+// and serves only the API (and /healthz).
+func (c *Config) GetWebAPIOnly() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.WebAPIOnly
+}
+
+// GetWebLanguage returns the default language of web API error
+// messages, lowercased; empty when unset.
+func (c *Config) GetWebLanguage() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.ToLower(strings.TrimSpace(c.WebLanguage))
+}
+
+// GetTransport returns the stream-status transport, normalized to one
+// of the Transport* constants. Unknown values fall back to PubSub.
+func (c *Config) GetTransport() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch t := strings.ToLower(strings.TrimSpace(c.Transport)); t {
+	case TransportEventSub, TransportAuto:
+		return t
+	}
+	return TransportPubSub
+}
+
+// SetTransport sets the stream-status transport. Returns false for
+// anything but the Transport* constants.
+func (c *Config) SetTransport(t string) bool {
+	if CheckTransport(t) != nil {
+		return false
+	}
+	t = strings.ToLower(strings.TrimSpace(t))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Transport = t
+	return true
+}
+
+// GetNetworkProfile returns the network profile, normalized to one of
+// the NetworkProfile* constants. Unknown values fall back to the default.
+func (c *Config) GetNetworkProfile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if p := strings.ToLower(strings.TrimSpace(c.NetworkProfile)); p == NetworkProfileFlaky {
+		return p
+	}
+	return NetworkProfileDefault
+}
+
+// SetNetworkProfile sets the network profile. Returns false for
+// anything but the NetworkProfile* constants.
+func (c *Config) SetNetworkProfile(p string) bool {
+	if CheckNetworkProfile(p) != nil {
+		return false
+	}
+	p = strings.ToLower(strings.TrimSpace(p))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.NetworkProfile = p
+	return true
+}
+
+// GetLogLevel returns the UI feed's log level, normalized to one of the
+// LogLevel* constants, or "" when unset or unknown (the build's default
+// applies).
+func (c *Config) GetLogLevel() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch l := strings.ToLower(strings.TrimSpace(c.LogLevel)); l {
+	case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+		return l
+	}
+	return ""
+}
+
+// GetLogFormat returns the log format. Unknown values fall back to
+// text.
+func (c *Config) GetLogFormat() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if strings.EqualFold(strings.TrimSpace(c.LogFormat), LogFormatJSON) {
+		return LogFormatJSON
+	}
+	return LogFormatText
+}
+
+// GetPubSubRecordFile returns the PubSub capture file ("" = no
+// recording).
+func (c *Config) GetPubSubRecordFile() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.TrimSpace(c.PubSubRecordFile)
+}
+
+// GetProxyURL returns the configured outbound proxy ("" = direct).
+func (c *Config) GetProxyURL() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.TrimSpace(c.ProxyURL)
+}
+
+// systemLocal is the host's time zone, kept from before ApplyTimeZone
+// replaces time.Local.
+var systemLocal = time.Local
+
+// GetTimeZone returns the time_zone location, or the host's zone when
+// unset. An unknown zone name is an error.
+func (c *Config) GetTimeZone() (*time.Location, error) {
+	c.mu.RLock()
+	name := strings.TrimSpace(c.TimeZone)
+	c.mu.RUnlock()
+	if name == "" {
+		return systemLocal, nil
+	}
+	return time.LoadLocation(name)
+}
+
+// ApplyTimeZone makes time_zone the process's local time zone
+// (time.Local), so daily totals, history days and schedules follow it
+// rather than the host's. An unknown zone leaves time.Local as it is.
+func (c *Config) ApplyTimeZone() error {
+	loc, err := c.GetTimeZone()
+	if err != nil {
+		return err
+	}
+	time.Local = loc
+	return nil
+}
+
+// GetAPITimestampsUTC reports whether the web API writes timestamps in
+// UTC rather than in the local time zone.
+func (c *Config) GetAPITimestampsUTC() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return strings.EqualFold(c.APITimestamps, "utc")
+}
+
+// MaxStartupDelay caps startup_delay_seconds.
+const MaxStartupDelay = 10 * time.Minute
+
+// MaxConnectStagger caps connect_stagger_seconds.
+const MaxConnectStagger = time.Minute
+
+// GetStartupDelay returns the longest random wait before the farmer
+// first contacts Twitch (0 = none), capped at MaxStartupDelay.
+func (c *Config) GetStartupDelay() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampSeconds(c.StartupDelaySeconds, MaxStartupDelay)
+}
+
+// GetConnectStagger returns the gap between starting the farmer's
+// connections (0 = all at once), capped at MaxConnectStagger.
+func (c *Config) GetConnectStagger() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampSeconds(c.ConnectStaggerSeconds, MaxConnectStagger)
+}
+
+// clampSeconds converts a seconds setting to a duration: 0 for unset or
+// negative values, capped at max.
+func clampSeconds(s int, max time.Duration) time.Duration {
+	if s <= 0 {
+		return 0
+	}
+	return min(time.Duration(s)*time.Second, max)
+}
+
+// MaxHeartbeatJitter caps heartbeat_jitter_seconds: past half a minute
+// a heartbeat could land in the wrong minute.
+const MaxHeartbeatJitter = 30 * time.Second
+
+// GetHeartbeatJitter returns how far each Spade heartbeat may land
+// before or after its 60s mark (0 = on the exact mark), capped at
+// MaxHeartbeatJitter. ok is false when heartbeat_jitter_seconds is
+// unset and the tracker's default applies.
+func (c *Config) GetHeartbeatJitter() (jitter time.Duration, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.HeartbeatJitterSeconds == nil {
+		return 0, false
+	}
+	return clampSeconds(*c.HeartbeatJitterSeconds, MaxHeartbeatJitter), true
+}
+
+// MaxRotationIntervalMinutes caps rotation_interval_minutes.
+const MaxRotationIntervalMinutes = 60
+
+// GetRotationInterval returns how often the points rotation re-evaluates
+// the watch slots, or 0 when unset (the rotation's default applies).
+// Values above MaxRotationIntervalMinutes are capped.
+func (c *Config) GetRotationInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampMinutes(c.RotationIntervalMinutes, MaxRotationIntervalMinutes)
+}
+
+// SetRotationIntervalMinutes sets the points rotation interval (0 =
+// default). Returns false outside 0..MaxRotationIntervalMinutes.
+func (c *Config) SetRotationIntervalMinutes(m int) bool {
+	if CheckRotationIntervalMinutes(m) != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.RotationIntervalMinutes = m
+	return true
+}
+
+// GetRotationIntervalMinutes returns the raw setting (0 = default).
+func (c *Config) GetRotationIntervalMinutes() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.RotationIntervalMinutes
+}
+
+// MaxDropCheckMinutes caps drop_check_minutes.
+const MaxDropCheckMinutes = 120
+
+// GetDropCheckInterval returns the base interval of the drops inventory
+// check, or 0 when unset (the drops default applies). Values above
+// MaxDropCheckMinutes are capped.
+func (c *Config) GetDropCheckInterval() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampMinutes(c.DropCheckMinutes, MaxDropCheckMinutes)
+}
+
+// SetDropCheckMinutes sets the drops check interval (0 = default).
+// Returns false outside 0..MaxDropCheckMinutes.
+func (c *Config) SetDropCheckMinutes(m int) bool {
+	if CheckDropCheckMinutes(m) != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DropCheckMinutes = m
+	return true
+}
+
+// GetDropCheckMinutes returns the raw setting (0 = default).
+func (c *Config) GetDropCheckMinutes() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.DropCheckMinutes
+}
+
+// GetAuditLog reports whether actions are appended to the audit log.
+func (c *Config) GetAuditLog() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.AuditLog
+}
+
+// clampMinutes converts a minutes setting to a duration: 0 for unset or
+// negative values, capped at max.
+func clampMinutes(m, max int) time.Duration {
+	if m <= 0 {
+		return 0
+	}
+	if m > max {
+		m = max
+	}
+	return time.Duration(m) * time.Minute
+}
+
+// MaxStreakWindowMinutes caps streak_window_minutes. Past two hours the
+// Streak-Hunt slot would just be a second P1.
+const MaxStreakWindowMinutes = 120
+
+// GetStreakWindow returns how long after stream start a channel stays a
+// Streak-Hunt candidate, or 0 when unset (the rotation's default
+// applies). Values above MaxStreakWindowMinutes are capped.
+func (c *Config) GetStreakWindow() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampMinutes(c.StreakWindowMinutes, MaxStreakWindowMinutes)
+}
+
+// GetStreakWindowMinutes returns the raw setting (0 = default).
+func (c *Config) GetStreakWindowMinutes() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.StreakWindowMinutes
+}
+
+// SetStreakWindowMinutes sets the Streak-Hunt window (0 = default).
+// Returns false outside 0..MaxStreakWindowMinutes.
+func (c *Config) SetStreakWindowMinutes(m int) bool {
+	if CheckStreakWindowMinutes(m) != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.StreakWindowMinutes = m
+	return true
+}
+
+// GetMinViewers returns the viewer floor below which a live channel
+// counts as inactive and gets no points slot (0 = off).
+func (c *Config) GetMinViewers() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return max(c.MinViewers, 0)
+}
+
+// GetRerunMode returns how rerun streams are handled, normalized to one
+// of the RerunMode* constants (RerunModeWatch for unknown values).
+func (c *Config) GetRerunMode() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	switch m := strings.ToLower(strings.TrimSpace(c.RerunMode)); m {
+	case RerunModeDeprioritize, RerunModeSkip:
+		return m
+	}
+	return RerunModeWatch
+}
+
+// MaxStaleViewersMinutes caps stale_viewers_minutes.
+const MaxStaleViewersMinutes = 24 * 60
+
+// GetStaleViewersAfter returns how long a live channel's viewer count
+// may stay unchanged before it counts as inactive, or 0 when off.
+// Values above MaxStaleViewersMinutes are capped.
+func (c *Config) GetStaleViewersAfter() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return clampMinutes(c.StaleViewersMinutes, MaxStaleViewersMinutes)
+}
+
+// GetStreakPreservation reports whether Streak-Hunt candidates outrank
+// P0 and take a slot as soon as their stream comes up.
+func (c *Config) GetStreakPreservation() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.StreakPreservation
+}
+
+// GetWeightSubMultipliers reports whether the rotation favors channels
+// whose points multiplier (from a subscription) is above 1.
+func (c *Config) GetWeightSubMultipliers() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.WeightSubMultipliers
+}
+
+// SetStreakPreservation toggles streak_preservation.
+func (c *Config) SetStreakPreservation(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.StreakPreservation = v
+}
+
+// GetQuitToBackground reports whether 'q' in the TUI should hand the
+// farmer to a background process instead of stopping it (non-Windows;
+// Windows hides the console to the tray instead).
+func (c *Config) GetQuitToBackground() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.QuitToBackground
+}
+
+// SetQuitToBackground toggles quit_to_background (read when the TUI
+// starts).
+func (c *Config) SetQuitToBackground(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.QuitToBackground = v
+}
+
+// GetNotificationsEnabled reports whether notifications (Telegram and
+// notifiers) are sent; disable_notifications mutes them.
+func (c *Config) GetNotificationsEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.DisableNotifications
+}
+
+// SetNotificationsEnabled mutes or unmutes notifications. Read on every
+// notification, so it applies right away.
+func (c *Config) SetNotificationsEnabled(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DisableNotifications = !v
+}
+
+// GetUpdateCheckEnabled reports whether the farmer polls GitHub for new
+// releases; disable_update_check turns it off.
+func (c *Config) GetUpdateCheckEnabled() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return !c.DisableUpdateCheck
+}
+
+// SetUpdateCheckEnabled toggles the update check.
+func (c *Config) SetUpdateCheckEnabled(v bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DisableUpdateCheck = !v
+}
+
+// GetPointsClaimEvent returns the points_claim_events rule for a
+// community-points event type (case-insensitive), paths defaulted.
+func (c *Config) GetPointsClaimEvent(typ string) (PointsClaimEvent, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, e := range c.PointsClaimEvents {
+		if !strings.EqualFold(strings.TrimSpace(e.Type), typ) {
+			continue
+		}
+		if strings.TrimSpace(e.IDPath) == "" {
+			e.IDPath = DefaultPointsClaimIDPath
+		}
+		if strings.TrimSpace(e.ChannelPath) == "" {
+			e.ChannelPath = DefaultPointsClaimChannelPath
+		}
+		return e, true
+	}
+	return PointsClaimEvent{}, false
+}
+
+// defaultLiveHookQuality is passed as {quality} when LiveHook.Quality is
+// unset.
+const defaultLiveHookQuality = "best"
+
+// GetLiveHook returns a copy of the live hook config with defaults
+// filled in. Without a live_hook block it covers P1 channels and runs
+// no command.
+func (c *Config) GetLiveHook() LiveHook {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	h := LiveHook{Quality: defaultLiveHookQuality}
+	if c.LiveHook == nil {
+		return h
+	}
+	h.Command = append([]string(nil), c.LiveHook.Command...)
+	h.Channels = append([]string(nil), c.LiveHook.Channels...)
+	if q := strings.TrimSpace(c.LiveHook.Quality); q != "" {
+		h.Quality = q
+	}
+	return h
+}
+
+// GetNotifiers returns a copy of the push notification backends.
+func (c *Config) GetNotifiers() []Notifier {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	out := make([]Notifier, len(c.Notifiers))
+	for i, n := range c.Notifiers {
+		n.Type = strings.ToLower(strings.TrimSpace(n.Type))
+		n.URL = strings.TrimSpace(n.URL)
+		n.Token = strings.TrimSpace(n.Token)
+		n.User = strings.TrimSpace(n.User)
+		n.Notify = append([]string(nil), n.Notify...)
+		n.LiveChannels = append([]string(nil), n.LiveChannels...)
+		out[i] = n
+	}
+	return out
+}
+
+// GetDesktop returns a copy of the desktop notification config, with
+// MinBonus defaulted. ok is false when they are off.
+func (c *Config) GetDesktop() (d Desktop, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Desktop == nil {
+		return Desktop{}, false
+	}
+	d = *c.Desktop
+	d.Notify = append([]string(nil), d.Notify...)
+	if d.MinBonus <= 0 {
+		d.MinBonus = DefaultMinBonus
+	}
+	return d, d.Enabled
+}
+
+// GetSchedule returns a copy of the farming schedule. The zero Schedule
+// farms around the clock.
+func (c *Config) GetSchedule() Schedule {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var s Schedule
+	if c.Schedule != nil {
+		s = *c.Schedule
+		s.PauseWindows = append([]string(nil), s.PauseWindows...)
+	}
+	return s
+}
+
+// SetSchedule replaces the farming schedule; an empty one is removed
+// from the config. Returns false (and changes nothing) when s doesn't
+// validate.
+func (c *Config) SetSchedule(s Schedule) bool {
+	if s.Validate() != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(s.PauseWindows) == 0 && !s.IdleOnly && s.IdleMinutes == 0 {
+		c.Schedule = nil
+		return true
+	}
+	s.PauseWindows = append([]string(nil), s.PauseWindows...)
+	c.Schedule = &s
+	return true
+}
+
+// GetTelegram returns a copy of the Telegram bot config. ok is false
+// when no bot token is set.
+func (c *Config) GetTelegram() (t Telegram, ok bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.Telegram == nil {
+		return Telegram{}, false
+	}
+	t = *c.Telegram
+	t.BotToken = strings.TrimSpace(t.BotToken)
+	t.Notify = append([]string(nil), t.Notify...)
+	t.LiveChannels = append([]string(nil), t.LiveChannels...)
+	return t, t.BotToken != ""
+}
+
+// Covers reports whether the hook fires for a channel: the listed
+// logins when Channels is set, otherwise every P1 (always-watch) channel.
+func (h LiveHook) Covers(login string, priority int) bool {
+	if len(h.Channels) == 0 {
+		return priority == 1
+	}
+	for _, l := range h.Channels {
+		if strings.EqualFold(strings.TrimSpace(l), login) {
+			return true
+		}
+	}
+	return false
+}
+
+// validAutoSelect normalizes an auto-select mode; ok is false for
+// anything but the AutoSelect* constants.
+func validAutoSelect(mode string) (string, bool) {
+	switch m := strings.ToLower(strings.TrimSpace(mode)); m {
+	case AutoSelectOff, AutoSelectAllowed, AutoSelectDirectory:
+		return m, true
+	}
+	return "", false
+}
+
+// GetDropAutoSelect returns the global drop auto-select mode. Unset or
+// unknown values mean AutoSelectDirectory (the pre-switch behavior).
+func (c *Config) GetDropAutoSelect() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.dropAutoSelectLocked()
+}
+
+func (c *Config) dropAutoSelectLocked() string {
+	if m, ok := validAutoSelect(c.DropAutoSelect); ok {
+		return m
+	}
+	return AutoSelectDirectory
+}
+
+// SetDropAutoSelect sets the global drop auto-select mode. Returns
+// false (and changes nothing) for an unknown mode.
+func (c *Config) SetDropAutoSelect(mode string) bool {
+	m, ok := validAutoSelect(mode)
+	if !ok {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DropAutoSelect = m
+	return true
+}
+
+// DefaultMaxTempChannels is max_temp_channels when unset: the drop pick
+// plus the previous one until the new pick is confirmed.
+const DefaultMaxTempChannels = 2
+
+// GetMaxTempChannels returns how many temporary drop channels may be
+// tracked at once (at least 1).
+func (c *Config) GetMaxTempChannels() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.MaxTempChannels <= 0 {
+		return DefaultMaxTempChannels
+	}
+	return c.MaxTempChannels
+}
+
+// GetDropMinProgressPercent returns the minimum existing campaign
+// progress (0-100) the drops selector requires; 0 means no threshold.
+func (c *Config) GetDropMinProgressPercent() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if c.DropMinProgressPercent < 0 {
+		return 0
+	}
+	if c.DropMinProgressPercent > 100 {
+		return 100
+	}
+	return c.DropMinProgressPercent
+}
+
+// SetDropMinProgressPercent sets drop_min_progress_percent. Returns
+// false outside 0..100.
+func (c *Config) SetDropMinProgressPercent(pct int) bool {
+	if CheckDropMinProgressPercent(pct) != nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.DropMinProgressPercent = pct
+	return true
+}
+
+// GetCampaignAutoSelect returns the effective auto-select mode for a
+// campaign: its override if one is set, otherwise the global mode.
+func (c *Config) GetCampaignAutoSelect(campaignID string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	if m, ok := validAutoSelect(c.CampaignAutoSelect[campaignID]); ok {
+		return m
+	}
+	return c.dropAutoSelectLocked()
+}
+
+// GetCampaignAutoSelectOverride returns a campaign's override, or ""
+// when it follows the global mode.
+func (c *Config) GetCampaignAutoSelectOverride(campaignID string) string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	m, _ := validAutoSelect(c.CampaignAutoSelect[campaignID])
+	return m
+}
+
+// SetCampaignAutoSelect sets a per-campaign override; "" clears it.
+// Returns false for an unknown mode.
+func (c *Config) SetCampaignAutoSelect(campaignID, mode string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if strings.TrimSpace(mode) == "" {
+		delete(c.CampaignAutoSelect, campaignID)
+		return true
+	}
+	m, ok := validAutoSelect(mode)
+	if !ok {
+		return false
+	}
+	if c.CampaignAutoSelect == nil {
+		c.CampaignAutoSelect = make(map[string]string)
+	}
+	c.CampaignAutoSelect[campaignID] = m
+	return true
+}
+
 // HasChannel checks if a channel is in the config.
 func (c *Config) HasChannel(login string) bool {
 	login = strings.ToLower(login)
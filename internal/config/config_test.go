@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestSetAndGetPinnedCampaign(t *testing.T) {
@@ -153,6 +155,420 @@ func TestUnmarkCampaignCompleted(t *testing.T) {
 	}
 }
 
+func TestMomentsEnabledDefaultsAndOptOut(t *testing.T) {
+	c := &Config{ChannelConfigs: []ChannelEntry{{Login: "alpha", Priority: 2}}}
+
+	if !c.IsMomentsEnabled("alpha") {
+		t.Fatal("configured channel should default to Moments enabled")
+	}
+	if !c.IsMomentsEnabled("untracked") {
+		t.Fatal("channel not in config should default to Moments enabled")
+	}
+
+	if !c.SetMomentsEnabled("ALPHA", false) {
+		t.Fatal("SetMomentsEnabled should find the channel case-insensitively")
+	}
+	if c.IsMomentsEnabled("alpha") {
+		t.Fatal("opt-out not applied")
+	}
+	if c.SetMomentsEnabled("untracked", false) {
+		t.Fatal("SetMomentsEnabled on an absent channel should return false")
+	}
+}
+
+func TestPointsGoal(t *testing.T) {
+	c := &Config{ChannelConfigs: []ChannelEntry{{Login: "alpha", Priority: 2}}}
+	if !c.SetPointsGoal("Alpha", 50000) || c.GetPointsGoal("alpha") != 50000 {
+		t.Fatal("goal not stored")
+	}
+	if !c.SetPointsGoal("alpha", -1) || c.GetPointsGoal("alpha") != 0 {
+		t.Fatal("negative goal should clear")
+	}
+	if c.SetPointsGoal("untracked", 10) || c.GetPointsGoal("untracked") != 0 {
+		t.Fatal("channel not in config has no goal")
+	}
+}
+
+// TestRenameChannel: the rename only applies while the entry still has
+// the old login, and attaches the ID to an entry that had none.
+func TestRenameChannel(t *testing.T) {
+	c := &Config{ChannelConfigs: []ChannelEntry{{Login: "alpha"}, {Login: "beta", ID: "2"}}}
+
+	if !c.RenameChannel("1", "Alpha", "alpha_new") {
+		t.Fatal("rename of an entry without ID failed")
+	}
+	if e := c.ChannelConfigs[0]; e.Login != "alpha_new" || e.ID != "1" {
+		t.Fatalf("entry = %+v, want alpha_new with ID 1", e)
+	}
+	if c.RenameChannel("1", "alpha", "alpha_other") {
+		t.Fatal("second rename from the stale login succeeded")
+	}
+	if c.RenameChannel("2", "gamma", "beta_new") || c.ChannelConfigs[1].Login != "beta" {
+		t.Fatal("rename with the wrong old login applied")
+	}
+	if c.RenameChannel("3", "delta", "delta_new") {
+		t.Fatal("rename of an unknown channel succeeded")
+	}
+}
+
+func TestChannelMode(t *testing.T) {
+	c := &Config{ChannelConfigs: []ChannelEntry{{Login: "alpha", Priority: 2}}}
+	if c.GetChannelMode("alpha") != ChannelModeBoth {
+		t.Fatal("default mode should be both")
+	}
+	if !c.SetChannelMode("Alpha", " Drops") || c.GetChannelMode("alpha") != ChannelModeDrops {
+		t.Fatal("drops mode not stored")
+	}
+	if c.SetChannelMode("alpha", "chat") || c.GetChannelMode("alpha") != ChannelModeDrops {
+		t.Fatal("unknown mode should be rejected")
+	}
+	if !c.SetChannelMode("alpha", "both") || c.ChannelConfigs[0].Mode != "" {
+		t.Fatal("both should store the empty default")
+	}
+	if c.SetChannelMode("untracked", ChannelModePoints) || c.GetChannelMode("untracked") != ChannelModeBoth {
+		t.Fatal("channel not in config has the default mode")
+	}
+}
+
+func TestChannelFeatures(t *testing.T) {
+	c := &Config{ChannelConfigs: []ChannelEntry{{Login: "alpha", Priority: 2}}}
+	for _, f := range []string{ChannelFeatureIRC, ChannelFeaturePubSub, ChannelFeatureSpade} {
+		if !c.IsChannelFeatureEnabled("alpha", f) {
+			t.Fatalf("%s should default to on", f)
+		}
+	}
+	if !c.SetChannelFeatureEnabled("Alpha", ChannelFeatureIRC, false) || !c.ChannelConfigs[0].DisableIRC {
+		t.Fatal("irc not switched off")
+	}
+	if c.IsChannelFeatureEnabled("alpha", ChannelFeatureIRC) || !c.IsChannelFeatureEnabled("alpha", ChannelFeatureSpade) {
+		t.Fatal("only irc should be off")
+	}
+	if c.SetChannelFeatureEnabled("alpha", "chat", false) || c.SetChannelFeatureEnabled("untracked", ChannelFeatureIRC, false) {
+		t.Fatal("unknown feature or channel should be rejected")
+	}
+	if !c.IsChannelFeatureEnabled("untracked", ChannelFeatureSpade) {
+		t.Fatal("channel not in config has everything on")
+	}
+}
+
+func TestGetTransportNormalizes(t *testing.T) {
+	for in, want := range map[string]string{
+		"":          TransportPubSub,
+		"pubsub":    TransportPubSub,
+		" EventSub": TransportEventSub,
+		"AUTO":      TransportAuto,
+		"websocket": TransportPubSub,
+	} {
+		c := &Config{Transport: in}
+		if got := c.GetTransport(); got != want {
+			t.Fatalf("GetTransport(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGetNetworkProfileNormalizes(t *testing.T) {
+	for in, want := range map[string]string{
+		"":        NetworkProfileDefault,
+		"default": NetworkProfileDefault,
+		" Flaky ": NetworkProfileFlaky,
+		"hotspot": NetworkProfileDefault,
+	} {
+		c := &Config{NetworkProfile: in}
+		if got := c.GetNetworkProfile(); got != want {
+			t.Fatalf("GetNetworkProfile(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestGetStreakWindowClamps(t *testing.T) {
+	for in, want := range map[int]time.Duration{
+		0:   0,
+		-5:  0,
+		10:  10 * time.Minute,
+		500: 120 * time.Minute,
+	} {
+		c := &Config{StreakWindowMinutes: in}
+		if got := c.GetStreakWindow(); got != want {
+			t.Fatalf("GetStreakWindow(%d) = %v, want %v", in, got, want)
+		}
+	}
+}
+
+func TestGetHeartbeatJitterClamps(t *testing.T) {
+	for in, want := range map[int]time.Duration{
+		0:   0,
+		-3:  0,
+		15:  15 * time.Second,
+		120: 30 * time.Second,
+	} {
+		c := &Config{HeartbeatJitterSeconds: &in}
+		if got, ok := c.GetHeartbeatJitter(); got != want || !ok {
+			t.Fatalf("GetHeartbeatJitter(%d) = %v, %v; want %v, true", in, got, ok, want)
+		}
+	}
+	if _, ok := (&Config{}).GetHeartbeatJitter(); ok {
+		t.Fatal("unset heartbeat_jitter_seconds reported as set")
+	}
+}
+
+func TestViewerFloorSettings(t *testing.T) {
+	c := &Config{MinViewers: -2, StaleViewersMinutes: 5000}
+	if got := c.GetMinViewers(); got != 0 {
+		t.Fatalf("GetMinViewers = %d, want 0", got)
+	}
+	if got := c.GetStaleViewersAfter(); got != MaxStaleViewersMinutes*time.Minute {
+		t.Fatalf("GetStaleViewersAfter = %v, want the cap", got)
+	}
+}
+
+func TestMaxTempChannels(t *testing.T) {
+	for _, tc := range []struct{ set, want int }{
+		{0, DefaultMaxTempChannels},
+		{-1, DefaultMaxTempChannels},
+		{1, 1},
+		{5, 5},
+	} {
+		c := &Config{MaxTempChannels: tc.set}
+		if got := c.GetMaxTempChannels(); got != tc.want {
+			t.Errorf("max_temp_channels %d: GetMaxTempChannels = %d, want %d", tc.set, got, tc.want)
+		}
+	}
+}
+
+func TestTimeZoneSettings(t *testing.T) {
+	c := &Config{}
+	if loc, err := c.GetTimeZone(); err != nil || loc != time.Local {
+		t.Fatalf("unset time_zone = %v, %v; want time.Local", loc, err)
+	}
+	c.TimeZone = "Europe/Berlin"
+	if loc, err := c.GetTimeZone(); err != nil || loc.String() != "Europe/Berlin" {
+		t.Fatalf("time_zone Europe/Berlin = %v, %v", loc, err)
+	}
+	c.TimeZone = "Mars/Olympus"
+	if _, err := c.GetTimeZone(); err == nil {
+		t.Fatal("unknown time_zone accepted")
+	}
+
+	if c.GetAPITimestampsUTC() {
+		t.Fatal("api_timestamps defaults to UTC")
+	}
+	c.APITimestamps = "UTC"
+	if !c.GetAPITimestampsUTC() {
+		t.Fatal("api_timestamps UTC not honored")
+	}
+}
+
+// TestApplyTimeZone: time_zone replaces time.Local, clearing it brings
+// back the host's zone, and an unknown zone changes nothing.
+func TestApplyTimeZone(t *testing.T) {
+	host := time.Local
+	t.Cleanup(func() { time.Local = host })
+
+	c := &Config{TimeZone: "Asia/Kolkata"}
+	if err := c.ApplyTimeZone(); err != nil || time.Local.String() != "Asia/Kolkata" {
+		t.Fatalf("ApplyTimeZone = %v, time.Local = %v", err, time.Local)
+	}
+	c.TimeZone = "Mars/Olympus"
+	if err := c.ApplyTimeZone(); err == nil || time.Local.String() != "Asia/Kolkata" {
+		t.Fatalf("unknown zone: err %v, time.Local = %v", err, time.Local)
+	}
+	c.TimeZone = ""
+	if err := c.ApplyTimeZone(); err != nil || time.Local != host {
+		t.Fatalf("unset time_zone: err %v, time.Local = %v, want %v", err, time.Local, host)
+	}
+}
+
+func TestDropMinProgressPercent(t *testing.T) {
+	c := &Config{}
+	if !c.SetDropMinProgressPercent(50) || c.GetDropMinProgressPercent() != 50 {
+		t.Fatalf("SetDropMinProgressPercent(50): got %d", c.GetDropMinProgressPercent())
+	}
+	if c.SetDropMinProgressPercent(101) || c.SetDropMinProgressPercent(-1) || c.GetDropMinProgressPercent() != 50 {
+		t.Fatal("out-of-range threshold accepted")
+	}
+	c.DropMinProgressPercent = 250 // hand-edited config.json
+	if got := c.GetDropMinProgressPercent(); got != 100 {
+		t.Fatalf("GetDropMinProgressPercent clamps to 100, got %d", got)
+	}
+}
+
+func TestRotationIntervalAndEnumSetters(t *testing.T) {
+	c := &Config{}
+	if c.GetRotationInterval() != 0 {
+		t.Fatal("unset rotation interval should be 0")
+	}
+	if !c.SetRotationIntervalMinutes(10) || c.GetRotationInterval() != 10*time.Minute {
+		t.Fatalf("SetRotationIntervalMinutes(10): got %v", c.GetRotationInterval())
+	}
+	if c.SetRotationIntervalMinutes(61) || c.SetRotationIntervalMinutes(-1) || c.RotationIntervalMinutes != 10 {
+		t.Fatalf("out-of-range interval accepted: %d", c.RotationIntervalMinutes)
+	}
+	if c.GetDropCheckInterval() != 0 || !c.SetDropCheckMinutes(30) || c.GetDropCheckInterval() != 30*time.Minute {
+		t.Fatalf("SetDropCheckMinutes(30): got %v", c.GetDropCheckInterval())
+	}
+	if c.SetDropCheckMinutes(MaxDropCheckMinutes+1) || c.GetDropCheckMinutes() != 30 {
+		t.Fatalf("out-of-range drop check interval accepted: %d", c.GetDropCheckMinutes())
+	}
+	if !c.SetTransport(" EventSub ") || c.GetTransport() != TransportEventSub {
+		t.Fatalf("SetTransport: got %q", c.GetTransport())
+	}
+	if c.SetTransport("carrier-pigeon") || c.GetTransport() != TransportEventSub {
+		t.Fatal("unknown transport accepted")
+	}
+	if c.SetNetworkProfile("lossy") || !c.SetNetworkProfile("flaky") || c.GetNetworkProfile() != NetworkProfileFlaky {
+		t.Fatalf("SetNetworkProfile: got %q", c.GetNetworkProfile())
+	}
+}
+
+func TestCheckMatchesSetters(t *testing.T) {
+	c := &Config{}
+	for _, port := range []int{0, 1, 8080, 65535, 65536} {
+		if ok := c.SetWebPort(port); ok != (CheckWebPort(port) == nil) {
+			t.Errorf("web port %d: setter %v, check %v", port, ok, CheckWebPort(port))
+		}
+	}
+	for _, m := range []int{-1, 0, MaxStreakWindowMinutes, MaxStreakWindowMinutes + 1} {
+		if ok := c.SetStreakWindowMinutes(m); ok != (CheckStreakWindowMinutes(m) == nil) {
+			t.Errorf("streak window %d: setter %v, check %v", m, ok, CheckStreakWindowMinutes(m))
+		}
+	}
+	for _, m := range []string{"all", " Watching ", "off", "some"} {
+		if ok := c.SetIrcMode(m); ok != (CheckIrcMode(m) == nil) {
+			t.Errorf("irc mode %q: setter %v, check %v", m, ok, CheckIrcMode(m))
+		}
+	}
+	for _, m := range []string{"off", " Allowed ", "sometimes"} {
+		if ok := c.SetDropAutoSelect(m); ok != (CheckDropAutoSelect(m) == nil) {
+			t.Errorf("auto-select %q: setter %v, check %v", m, ok, CheckDropAutoSelect(m))
+		}
+	}
+	if err := CheckWebPort(0); err == nil || !strings.Contains(err.Error(), "web_port") {
+		t.Fatalf("CheckWebPort(0) = %v, want an error naming web_port", err)
+	}
+	if c.WebPort != 65535 {
+		t.Fatalf("refused port overwrote web_port: %d", c.WebPort)
+	}
+}
+
+func TestStartupDelayAndStagger(t *testing.T) {
+	c := &Config{StartupDelaySeconds: -5, ConnectStaggerSeconds: 3}
+	if c.GetStartupDelay() != 0 || c.GetConnectStagger() != 3*time.Second {
+		t.Fatalf("delay = %v, stagger = %v", c.GetStartupDelay(), c.GetConnectStagger())
+	}
+	c.StartupDelaySeconds, c.ConnectStaggerSeconds = 3600, 600 // hand-edited config.json
+	if c.GetStartupDelay() != MaxStartupDelay || c.GetConnectStagger() != MaxConnectStagger {
+		t.Fatalf("caps: delay = %v, stagger = %v", c.GetStartupDelay(), c.GetConnectStagger())
+	}
+}
+
+func TestLogLevelAndFormat(t *testing.T) {
+	c := &Config{LogLevel: " WARN ", LogFormat: "Json"}
+	if c.GetLogLevel() != LogLevelWarn || c.GetLogFormat() != LogFormatJSON {
+		t.Fatalf("level = %q, format = %q", c.GetLogLevel(), c.GetLogFormat())
+	}
+	c.LogLevel, c.LogFormat = "verbose", "xml"
+	if c.GetLogLevel() != "" || c.GetLogFormat() != LogFormatText {
+		t.Fatalf("unknown values: level = %q, format = %q", c.GetLogLevel(), c.GetLogFormat())
+	}
+}
+
+func TestLiveHookDefaultsAndCoverage(t *testing.T) {
+	c := &Config{}
+	h := c.GetLiveHook()
+	if h.Quality != "best" || len(h.Command) != 0 {
+		t.Fatalf("default hook = %+v", h)
+	}
+	if !h.Covers("any", 1) || h.Covers("any", 2) {
+		t.Fatal("default hook should cover exactly the P1 channels")
+	}
+
+	c.LiveHook = &LiveHook{Quality: " 720p60 ", Channels: []string{"StreamerA"}}
+	h = c.GetLiveHook()
+	if h.Quality != "720p60" {
+		t.Fatalf("quality = %q", h.Quality)
+	}
+	if !h.Covers("streamera", 2) || h.Covers("streamerb", 1) {
+		t.Fatal("channel list should replace the P1 default")
+	}
+}
+
+func TestNormalizeGameName(t *testing.T) {
+	for in, want := range map[string]string{
+		"Tom Clancy's Rainbow Six® Siege": "tom clancys rainbow six siege",
+		"  ARENA BREAKOUT:  Infinite ":    "arena breakout infinite",
+		"Rust™":                           "rust",
+		"Half-Life 2":                     "half life 2",
+		"原神":                              "原神",
+	} {
+		if got := NormalizeGameName(in); got != want {
+			t.Errorf("NormalizeGameName(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	c := &Config{GameAliases: map[string]string{"Warzone 2.0": "Call of Duty: Warzone"}}
+	if got := c.GameKey("warzone 2.0"); got != "call of duty warzone" {
+		t.Fatalf("aliased GameKey = %q", got)
+	}
+	if got := c.GameKey("Rust"); got != "rust" {
+		t.Fatalf("plain GameKey = %q", got)
+	}
+}
+
+func TestTelegramConfig(t *testing.T) {
+	c := &Config{}
+	if _, ok := c.GetTelegram(); ok {
+		t.Fatal("no telegram block should be disabled")
+	}
+	c.Telegram = &Telegram{BotToken: "  "}
+	if _, ok := c.GetTelegram(); ok {
+		t.Fatal("telegram without a bot token should be disabled")
+	}
+	c.Telegram.BotToken = " 123:abc "
+	tg, ok := c.GetTelegram()
+	if !ok || tg.BotToken != "123:abc" {
+		t.Fatalf("GetTelegram = %+v, %v", tg, ok)
+	}
+	if !tg.Notifies(TelegramNotifyDrops) || !tg.Notifies(TelegramNotifyLive) {
+		t.Fatal("empty notify list should send everything")
+	}
+	if !tg.CoversLive("any", 1) || tg.CoversLive("any", 2) {
+		t.Fatal("default live coverage should be the P1 channels")
+	}
+
+	c.Telegram.Notify = []string{"Drops"}
+	c.Telegram.LiveChannels = []string{"streamera"}
+	tg, _ = c.GetTelegram()
+	if !tg.Notifies(TelegramNotifyDrops) || tg.Notifies(TelegramNotifyLive) {
+		t.Fatal("notify list should restrict the kinds")
+	}
+	if !tg.CoversLive("StreamerA", 2) || tg.CoversLive("streamerb", 1) {
+		t.Fatal("live_channels should replace the P1 default")
+	}
+}
+
+func TestDropAutoSelectDefaultsAndOverrides(t *testing.T) {
+	c := &Config{}
+	if got := c.GetDropAutoSelect(); got != AutoSelectDirectory {
+		t.Fatalf("default mode = %q, want directory", got)
+	}
+	if c.SetDropAutoSelect("sometimes") {
+		t.Fatal("unknown mode accepted")
+	}
+	if !c.SetDropAutoSelect(" OFF ") || c.GetDropAutoSelect() != AutoSelectOff {
+		t.Fatalf("mode not normalized: %q", c.DropAutoSelect)
+	}
+
+	if got := c.GetCampaignAutoSelect("cmp-1"); got != AutoSelectOff {
+		t.Fatalf("campaign without override = %q, want global off", got)
+	}
+	if !c.SetCampaignAutoSelect("cmp-1", "allowed") || c.GetCampaignAutoSelect("cmp-1") != AutoSelectAllowed {
+		t.Fatal("override not applied")
+	}
+	if !c.SetCampaignAutoSelect("cmp-1", "") || c.GetCampaignAutoSelectOverride("cmp-1") != "" {
+		t.Fatal("empty mode should clear the override")
+	}
+}
+
 // TestConcurrent_NoRaces hammers the public API from many goroutines
 // at once. Run with `go test -race` to catch lock omissions or
 // races against the slice-getter copies. With the mu RWMutex in
@@ -205,3 +621,181 @@ func TestConcurrent_NoRaces(t *testing.T) {
 		t.Fatalf("expected at least 3 channels (alpha/beta/gamma), got %d", len(c.GetChannelEntries()))
 	}
 }
+
+func TestSaveSoonDebouncesAndFlushes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	c := &Config{path: path}
+
+	for _, login := range []string{"alpha", "beta", "gamma"} {
+		c.AddChannel(login)
+		c.SaveSoon()
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("SaveSoon wrote before the debounce, stat err = %v", err)
+	}
+	if err := c.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if got := loaded.GetChannelLogins(); len(got) != 3 {
+		t.Fatalf("flushed channels = %v, want alpha, beta, gamma", got)
+	}
+
+	// Nothing pending: Flush is a no-op even if the file is gone.
+	os.Remove(path)
+	if err := c.Flush(); err != nil {
+		t.Fatalf("idle Flush: %v", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("idle Flush wrote the file")
+	}
+
+	// Left alone, the debounced save happens by itself.
+	c.RemoveChannel("beta")
+	c.SaveSoon()
+	deadline := time.Now().Add(5 * saveDebounce)
+	for {
+		if _, err := os.Stat(path); err == nil {
+			if loaded, err := Load(path); err == nil && len(loaded.GetChannelLogins()) == 2 {
+				break
+			}
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("debounced save never happened")
+		}
+		time.Sleep(saveDebounce / 5)
+	}
+}
+
+func TestSaveKeepsBackupAndSurvivesConcurrentSaves(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	c := &Config{path: path}
+	c.AddChannel("alpha")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".bak"); !os.IsNotExist(err) {
+		t.Fatalf("first save wrote a backup, stat err = %v", err)
+	}
+
+	// Two instances sharing the file, saving in parallel.
+	other, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	for _, cfg := range []*Config{c, other} {
+		go func(cfg *Config) {
+			var err error
+			for i := 0; i < 50 && err == nil; i++ {
+				cfg.SetPriority("alpha", 1+i%2)
+				err = cfg.Save()
+			}
+			done <- err
+		}(cfg)
+	}
+	for i := 0; i < 2; i++ {
+		if err := <-done; err != nil {
+			t.Fatalf("Save: %v", err)
+		}
+	}
+
+	for _, p := range []string{path, path + ".bak"} {
+		loaded, err := Load(p)
+		if err != nil {
+			t.Fatalf("Load %s: %v", filepath.Base(p), err)
+		}
+		if got := loaded.GetChannelLogins(); len(got) != 1 || got[0] != "alpha" {
+			t.Fatalf("%s channels = %v, want [alpha]", filepath.Base(p), got)
+		}
+	}
+	if matches, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".config-*.tmp")); len(matches) != 0 {
+		t.Errorf("temp files left behind: %v", matches)
+	}
+
+	// A corrupted file points at the backup.
+	os.WriteFile(path, []byte("{"), 0600)
+	if _, err := Load(path); err == nil || !strings.Contains(err.Error(), ".bak") {
+		t.Errorf("Load of a corrupted config = %v, want a hint at the backup", err)
+	}
+}
+
+// TestSaveLockFile: the lock file sits next to the config, and there
+// is none with disable_config_lock or without a path.
+func TestSaveLockFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	c := &Config{path: path}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".lock"); err != nil {
+		t.Errorf("no lock file after Save: %v", err)
+	}
+
+	path = filepath.Join(dir, "unlocked.json")
+	c = &Config{path: path, DisableConfigLock: true}
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path + ".lock"); !os.IsNotExist(err) {
+		t.Errorf("disable_config_lock: lock file stat err = %v, want none", err)
+	}
+
+	t.Chdir(dir)
+	_ = (&Config{}).Save()
+	if _, err := os.Stat(".lock"); !os.IsNotExist(err) {
+		t.Errorf("Save without a path left a .lock, stat err = %v", err)
+	}
+}
+
+func TestGetPointsClaimEvent(t *testing.T) {
+	c := &Config{PointsClaimEvents: []PointsClaimEvent{
+		{Type: "goal-contribution-back"},
+		{Type: "bonus-drop", IDPath: "bonus.id", ChannelPath: "bonus.channel"},
+	}}
+	e, ok := c.GetPointsClaimEvent("Goal-Contribution-Back")
+	if !ok || e.IDPath != DefaultPointsClaimIDPath || e.ChannelPath != DefaultPointsClaimChannelPath {
+		t.Fatalf("defaulted rule = %+v, %v", e, ok)
+	}
+	if e, ok := c.GetPointsClaimEvent("bonus-drop"); !ok || e.IDPath != "bonus.id" || e.ChannelPath != "bonus.channel" {
+		t.Fatalf("explicit rule = %+v, %v", e, ok)
+	}
+	if _, ok := c.GetPointsClaimEvent("reward-redeemed"); ok {
+		t.Fatal("unconfigured type matched")
+	}
+}
+
+func TestGetWebLanguage(t *testing.T) {
+	c := &Config{WebLanguage: " DE "}
+	if got := c.GetWebLanguage(); got != "de" {
+		t.Fatalf("GetWebLanguage = %q, want de", got)
+	}
+}
+
+func TestIrcMode(t *testing.T) {
+	c := &Config{IrcEnabled: true}
+	if got := c.GetIrcMode(); got != IrcModeAll {
+		t.Fatalf("default mode = %q", got)
+	}
+	if !c.SetIrcMode("Watching") || c.GetIrcMode() != IrcModeWatching || !c.GetIrcEnabled() {
+		t.Fatalf("watching: mode=%q enabled=%v", c.GetIrcMode(), c.GetIrcEnabled())
+	}
+	if !c.SetIrcMode("off") || c.GetIrcEnabled() || c.GetIrcMode() != IrcModeOff {
+		t.Fatalf("off: mode=%q enabled=%v", c.GetIrcMode(), c.GetIrcEnabled())
+	}
+	c.SetIrcEnabled(true)
+	if c.GetIrcMode() != IrcModeAll {
+		t.Fatalf("re-enabling left mode %q", c.GetIrcMode())
+	}
+	if c.SetIrcMode("some") {
+		t.Fatal("unknown mode accepted")
+	}
+	c = &Config{IrcEnabled: true, IrcMode: "off"}
+	if c.GetIrcEnabled() {
+		t.Fatal(`irc_mode "off" should disable IRC`)
+	}
+}
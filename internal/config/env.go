@@ -0,0 +1,254 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// envOverride is a TWITCHPOINT_* environment variable that replaces a
+// config setting, for containers and templated setups.
+type envOverride struct {
+	name string // environment variable
+	key  string // config key it overrides
+	set  func(c *Config, v string) error
+}
+
+var envOverrides = []envOverride{
+	{"TWITCHPOINT_AUTH_TOKEN", "auth_token", func(c *Config, v string) error {
+		c.AuthToken = v
+		return nil
+	}},
+	{"TWITCHPOINT_IRC_AUTH_TOKEN", "irc_auth_token", func(c *Config, v string) error {
+		c.IrcAuthToken = v
+		return nil
+	}},
+	{"TWITCHPOINT_WEB_PORT", "web_port", func(c *Config, v string) error {
+		port, err := strconv.Atoi(v)
+		if err != nil || port < 1 || port > 65535 {
+			return fmt.Errorf("%q is not a port number", v)
+		}
+		c.WebPort = port
+		return nil
+	}},
+	{"TWITCHPOINT_WEB_BIND", "web_bind", func(c *Config, v string) error {
+		c.WebBind = v
+		return nil
+	}},
+	{"TWITCHPOINT_WEB_TOKEN", "web_token", func(c *Config, v string) error {
+		c.WebToken = v
+		return nil
+	}},
+	{"TWITCHPOINT_WEB_API_ONLY", "web_api_only", func(c *Config, v string) error {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", v)
+		}
+		c.WebAPIOnly = b
+		return nil
+	}},
+	{"TWITCHPOINT_LOG_LEVEL", "log_level", func(c *Config, v string) error {
+		switch strings.ToLower(v) {
+		case LogLevelDebug, LogLevelInfo, LogLevelWarn, LogLevelError:
+		default:
+			return fmt.Errorf("%q is not debug, info, warn or error", v)
+		}
+		c.LogLevel = v
+		return nil
+	}},
+	{"TWITCHPOINT_LOG_FORMAT", "log_format", func(c *Config, v string) error {
+		switch strings.ToLower(v) {
+		case LogFormatText, LogFormatJSON:
+		default:
+			return fmt.Errorf("%q is not text or json", v)
+		}
+		c.LogFormat = v
+		return nil
+	}},
+}
+
+// envChannels lists the channels to farm, comma or space separated. It
+// replaces the channel list when the config is loaded or reloaded;
+// channels also in the file keep their settings.
+const envChannels = "TWITCHPOINT_CHANNELS"
+
+// channelsKey is the config key envChannels overrides.
+const channelsKey = "channel_configs"
+
+// envValue is a setting taken from the environment: the value the file
+// has for it (nil when the file leaves it out) and the value the
+// environment set, both as JSON.
+type envValue struct {
+	file, env json.RawMessage
+}
+
+// applyEnv applies the envOverrides and envChannels that are set. file
+// holds the settings as read from the file; the values it has for
+// overridden keys are kept so Save writes them back instead of the
+// environment's. Caller holds mu or owns c.
+func (c *Config) applyEnv(file map[string]json.RawMessage) error {
+	c.env = nil
+	for _, o := range envOverrides {
+		v := strings.TrimSpace(os.Getenv(o.name))
+		if v == "" {
+			continue
+		}
+		if err := o.set(c, v); err != nil {
+			return fmt.Errorf("%s: %w", o.name, err)
+		}
+		if c.env == nil {
+			c.env = make(map[string]envValue)
+		}
+		c.env[o.key] = envValue{file: file[o.key]}
+	}
+	if err := c.applyEnvChannels(); err != nil {
+		return err
+	}
+	if c.env == nil {
+		return nil
+	}
+	data, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	for key, e := range c.env {
+		e.env = m[key]
+		c.env[key] = e
+	}
+	return nil
+}
+
+// applyEnvChannels replaces the channel list with TWITCHPOINT_CHANNELS,
+// if set, keeping the file's list for marshalFile. Caller holds mu or
+// owns c.
+func (c *Config) applyEnvChannels() error {
+	v := os.Getenv(envChannels)
+	logins := strings.FieldsFunc(strings.ToLower(v), func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	})
+	if len(logins) == 0 {
+		return nil
+	}
+	var file json.RawMessage
+	if len(c.ChannelConfigs) > 0 {
+		data, err := json.Marshal(c.ChannelConfigs)
+		if err != nil {
+			return err
+		}
+		file = data
+	}
+	if c.env == nil {
+		c.env = make(map[string]envValue)
+	}
+	c.env[channelsKey] = envValue{file: file}
+
+	existing := make(map[string]ChannelEntry, len(c.ChannelConfigs))
+	for _, cc := range c.ChannelConfigs {
+		existing[cc.Login] = cc
+	}
+	entries := make([]ChannelEntry, 0, len(logins))
+	seen := make(map[string]bool, len(logins))
+	for _, login := range logins {
+		if seen[login] {
+			continue
+		}
+		seen[login] = true
+		if cc, ok := existing[login]; ok {
+			entries = append(entries, cc)
+		} else {
+			entries = append(entries, ChannelEntry{Login: login, Priority: 2})
+		}
+	}
+	c.ChannelConfigs = entries
+	return nil
+}
+
+// marshalFile marshals the config as written to the file: indented,
+// with the file's own values for settings the environment overrides,
+// unless they were changed at runtime since. Caller holds mu (read).
+func (c *Config) marshalFile() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil || len(c.env) == 0 {
+		return data, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	for key, e := range c.env {
+		if key == channelsKey {
+			list, err := envChannelsFile(e, m[key])
+			if err != nil {
+				return nil, err
+			}
+			if list == nil {
+				delete(m, key)
+			} else {
+				m[key] = list
+			}
+			continue
+		}
+		if !sameJSON(m[key], e.env) {
+			continue // set at runtime (a new login token, say): keep it
+		}
+		if e.file == nil {
+			delete(m, key)
+		} else {
+			m[key] = e.file
+		}
+	}
+	return json.MarshalIndent(m, "", "  ")
+}
+
+// envChannelsFile returns the channel list to write to the file when
+// TWITCHPOINT_CHANNELS replaced it with e.env and cur is the list now.
+// The file's channels are kept; channels the environment added are
+// left out unless changed at runtime; channels added, changed or
+// removed at runtime are written as they are now. nil for an empty
+// list.
+func envChannelsFile(e envValue, cur json.RawMessage) (json.RawMessage, error) {
+	file, fileOrder, err := channelsByLogin(e.file)
+	if err != nil {
+		return nil, err
+	}
+	env, _, err := channelsByLogin(e.env)
+	if err != nil {
+		return nil, err
+	}
+	now, nowOrder, err := channelsByLogin(cur)
+	if err != nil {
+		return nil, err
+	}
+	var out []json.RawMessage
+	for _, login := range fileOrder {
+		entry, inNow := now[login]
+		envEntry, inEnv := env[login]
+		switch {
+		case inNow && inEnv && sameJSON(entry, envEntry):
+			out = append(out, file[login]) // as the environment left it
+		case inNow:
+			out = append(out, entry) // changed at runtime
+		case !inEnv:
+			out = append(out, file[login]) // not in the environment's list
+		} // else removed at runtime
+	}
+	for _, login := range nowOrder {
+		if _, ok := file[login]; ok {
+			continue
+		}
+		if envEntry, ok := env[login]; ok && sameJSON(now[login], envEntry) {
+			continue // the environment's, unchanged
+		}
+		out = append(out, now[login])
+	}
+	if len(out) == 0 {
+		return nil, nil
+	}
+	return json.Marshal(out)
+}
@@ -0,0 +1,177 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFileNames are looked for, in this order, when no --config path
+// is given.
+var configFileNames = []string{defaultConfigFile, "config.yaml", "config.yml", "config.toml"}
+
+// fileFormat is the syntax of the config file, chosen by its extension.
+// Everything inside the package works on JSON: a YAML or TOML file is
+// converted to JSON when read and back when written, with the same key
+// names as config.json.
+type fileFormat int
+
+const (
+	formatJSON fileFormat = iota
+	formatYAML
+	formatTOML
+)
+
+func formatOf(path string) fileFormat {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	}
+	return formatJSON
+}
+
+// toJSON converts the file contents to JSON. JSON is returned as is.
+func (f fileFormat) toJSON(data []byte) ([]byte, error) {
+	var m map[string]interface{}
+	switch f {
+	case formatYAML:
+		if err := yaml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	case formatTOML:
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+	default:
+		return data, nil
+	}
+	if m == nil {
+		m = map[string]interface{}{} // empty file
+	}
+	return json.Marshal(m)
+}
+
+// fromJSON converts indented JSON to the file's format. Keys come out
+// sorted, and null values are left out (TOML has none).
+func (f fileFormat) fromJSON(data []byte) ([]byte, error) {
+	if f == formatJSON {
+		return data, nil
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	v = plainValue(v)
+
+	var buf bytes.Buffer
+	if f == formatYAML {
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(v); err != nil {
+			return nil, err
+		}
+		if err := enc.Close(); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+	enc := toml.NewEncoder(&buf)
+	enc.Indent = ""
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// plainValue turns decoded JSON numbers into int64 (or float64) so YAML
+// and TOML write them as numbers, and drops null values.
+func plainValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case json.Number:
+		if n, err := v.Int64(); err == nil {
+			return n
+		}
+		n, _ := v.Float64()
+		return n
+	case map[string]interface{}:
+		for k, e := range v {
+			if e == nil {
+				delete(v, k)
+				continue
+			}
+			v[k] = plainValue(e)
+		}
+	case []interface{}:
+		for i, e := range v {
+			v[i] = plainValue(e)
+		}
+	}
+	return v
+}
+
+// keepComments returns out, the new contents of a file that held old,
+// with old's comments: YAML comments are moved to the same keys (and
+// list items, by position) in out. TOML comments can't be kept; see
+// dropsComments.
+func (f fileFormat) keepComments(old, out []byte) []byte {
+	if f != formatYAML {
+		return out
+	}
+	var from, to yaml.Node
+	if yaml.Unmarshal(old, &from) != nil || yaml.Unmarshal(out, &to) != nil {
+		return out
+	}
+	copyComments(&from, &to)
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if enc.Encode(&to) != nil || enc.Close() != nil {
+		return out
+	}
+	return buf.Bytes()
+}
+
+func copyComments(from, to *yaml.Node) {
+	to.HeadComment, to.LineComment, to.FootComment = from.HeadComment, from.LineComment, from.FootComment
+	if from.Kind != to.Kind {
+		return
+	}
+	switch to.Kind {
+	case yaml.DocumentNode, yaml.SequenceNode:
+		for i := 0; i < len(to.Content) && i < len(from.Content); i++ {
+			copyComments(from.Content[i], to.Content[i])
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(to.Content); i += 2 {
+			for j := 0; j+1 < len(from.Content); j += 2 {
+				if from.Content[j].Value == to.Content[i].Value {
+					copyComments(from.Content[j], to.Content[i])
+					copyComments(from.Content[j+1], to.Content[i+1])
+					break
+				}
+			}
+		}
+	}
+}
+
+// dropsComments reports whether rewriting data, a file in this format,
+// loses comments: a TOML file with comment lines.
+func (f fileFormat) dropsComments(data []byte) bool {
+	if f != formatTOML {
+		return false
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,236 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const yamlConfig = `# farm these
+auth_token: tok
+web_port: 9090
+channel_configs:
+  - login: alpha
+    priority: 1
+  - login: beta
+    priority: 2
+    paused: true
+`
+
+const tomlConfig = `auth_token = "tok"
+web_port = 9090
+
+[[channel_configs]]
+login = "alpha"
+priority = 1
+
+[[channel_configs]]
+login = "beta"
+priority = 2
+paused = true
+`
+
+func TestLoadYAMLAndTOML(t *testing.T) {
+	for name, body := range map[string]string{"config.yaml": yamlConfig, "config.toml": tomlConfig} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), name)
+			if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			c, err := Load(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if c.GetAuthToken() != "tok" || c.WebPort != 9090 || !c.GetWebEnabled() {
+				t.Errorf("token/port/web = %q/%d/%v", c.GetAuthToken(), c.WebPort, c.GetWebEnabled())
+			}
+			if got := c.GetChannelLogins(); !reflect.DeepEqual(got, []string{"alpha", "beta"}) {
+				t.Errorf("channels = %v", got)
+			}
+			if c.GetPriority("alpha") != 1 || !c.IsChannelPaused("beta") {
+				t.Error("channel settings not read")
+			}
+			// Loading doesn't rewrite the file.
+			if data, _ := os.ReadFile(path); string(data) != body {
+				t.Errorf("Load rewrote the file:\n%s", data)
+			}
+
+			// Saved in the same format, and read back the same.
+			c.AddChannel("gamma")
+			if err := c.Save(); err != nil {
+				t.Fatal(err)
+			}
+			data, _ := os.ReadFile(path)
+			if strings.HasPrefix(strings.TrimSpace(string(data)), "{") {
+				t.Fatalf("saved as JSON:\n%s", data)
+			}
+			again, err := Load(path)
+			if err != nil {
+				t.Fatalf("reloading the saved file: %v\n%s", err, data)
+			}
+			if got := again.GetChannelLogins(); !reflect.DeepEqual(got, []string{"alpha", "beta", "gamma"}) {
+				t.Errorf("channels after save = %v", got)
+			}
+			if again.WebPort != 9090 || !again.IsChannelPaused("beta") {
+				t.Error("settings lost in the round trip")
+			}
+			if res, err := c.Reload(); err != nil || len(res.Changed) != 0 {
+				t.Errorf("Reload after own Save = %+v, %v; want no change", res, err)
+			}
+		})
+	}
+}
+
+func TestEnvOverrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yamlConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TWITCHPOINT_AUTH_TOKEN", "env-tok")
+	t.Setenv("TWITCHPOINT_WEB_PORT", "8181")
+	t.Setenv("TWITCHPOINT_CHANNELS", "beta, Delta")
+	t.Setenv("TWITCHPOINT_LOG_FORMAT", "JSON")
+	t.Setenv("TWITCHPOINT_WEB_API_ONLY", "true")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.GetAuthToken() != "env-tok" || c.WebPort != 8181 {
+		t.Errorf("token/port = %q/%d, want the environment's", c.GetAuthToken(), c.WebPort)
+	}
+	if c.GetLogFormat() != LogFormatJSON || !c.GetWebAPIOnly() {
+		t.Errorf("log_format/web_api_only = %q/%v, want the environment's", c.GetLogFormat(), c.GetWebAPIOnly())
+	}
+	if got := c.GetChannelLogins(); !reflect.DeepEqual(got, []string{"beta", "delta"}) {
+		t.Errorf("channels = %v, want [beta delta]", got)
+	}
+	if !c.IsChannelPaused("beta") {
+		t.Error("beta lost its settings from the file")
+	}
+
+	// The environment's values are not written to the file...
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "env-tok") || strings.Contains(string(data), "8181") {
+		t.Fatalf("environment values saved:\n%s", data)
+	}
+	// ...and survive a reload of an edited file.
+	editFile(t, path, func(e *Config) { e.MinViewers = 5 })
+	if _, err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if c.GetAuthToken() != "env-tok" || c.WebPort != 8181 || c.MinViewers != 5 {
+		t.Errorf("after reload token/port/min = %q/%d/%d", c.GetAuthToken(), c.WebPort, c.MinViewers)
+	}
+
+	// A token set at runtime (a new login) is saved.
+	c.SetAuthToken("new-tok")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); !strings.Contains(string(data), "new-tok") {
+		t.Errorf("runtime token not saved:\n%s", data)
+	}
+
+	t.Setenv("TWITCHPOINT_WEB_PORT", "http")
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted TWITCHPOINT_WEB_PORT=http")
+	}
+	t.Setenv("TWITCHPOINT_WEB_PORT", "")
+	t.Setenv("TWITCHPOINT_LOG_FORMAT", "logfmt")
+	if _, err := Load(path); err == nil {
+		t.Error("Load accepted TWITCHPOINT_LOG_FORMAT=logfmt")
+	}
+}
+
+// TestEnvChannels_File: TWITCHPOINT_CHANNELS replaces the list in use,
+// not the file's. Runtime adds and removals are saved, and a reload
+// applies the environment's list again.
+func TestEnvChannels_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yamlConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TWITCHPOINT_CHANNELS", "beta delta")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddChannel("gamma")
+	c.RemoveChannel("beta")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("TWITCHPOINT_CHANNELS", "")
+	saved, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// alpha is the file's, delta only the environment's.
+	if got := saved.GetChannelLogins(); !reflect.DeepEqual(got, []string{"alpha", "gamma"}) {
+		t.Errorf("channels in the file = %v, want [alpha gamma]", got)
+	}
+	if saved.GetPriority("alpha") != 1 {
+		t.Error("alpha lost its settings in the file")
+	}
+
+	t.Setenv("TWITCHPOINT_CHANNELS", "beta delta")
+	editFile(t, path, func(e *Config) { e.MinViewers = 5 })
+	if _, err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetChannelLogins(); !reflect.DeepEqual(got, []string{"beta", "delta"}) {
+		t.Errorf("channels after reload = %v, want [beta delta]", got)
+	}
+}
+
+// TestSave_Comments: YAML comments survive a save; a TOML file with
+// comments is kept as config.toml.orig the first time it is rewritten.
+func TestSave_Comments(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	body := strings.Replace(yamlConfig, "web_port: 9090", "web_port: 9090 # behind the proxy", 1)
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddChannel("gamma")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, comment := range []string{"# farm these", "# behind the proxy"} {
+		if !strings.Contains(string(data), comment) {
+			t.Errorf("%q lost:\n%s", comment, data)
+		}
+	}
+
+	path = filepath.Join(dir, "config.toml")
+	body = "# farm these\n" + tomlConfig
+	if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if c, err = Load(path); err != nil {
+		t.Fatal(err)
+	}
+	c.AddChannel("gamma")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	c.AddChannel("delta")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if orig, _ := os.ReadFile(path + ".orig"); string(orig) != body {
+		t.Errorf("config.toml.orig = %q, want the commented original", orig)
+	}
+}
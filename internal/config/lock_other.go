@@ -0,0 +1,9 @@
+//go:build !unix && !windows
+
+package config
+
+// lockFile does nothing where there are no file locks (plan9, wasm):
+// saves go ahead unlocked.
+func lockFile(path string) (unlock func()) {
+	return func() {}
+}
@@ -0,0 +1,289 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ReloadResult describes what Reload took from an edited config file.
+type ReloadResult struct {
+	// Changed lists the config keys the edit changed, sorted.
+	Changed []string
+	// Restart lists the keys of Changed that take effect on the next
+	// start (fields tagged reload:"restart").
+	Restart []string
+	// Conflicts lists the keys — "channel_configs[<login>]" for a
+	// channel — changed both in the file and at runtime since the last
+	// save. The file's version won.
+	Conflicts []string
+}
+
+// Reload applies edits made to the config file by someone else (a text
+// editor, a script) since it was last read or saved. It merges three
+// ways against that last version: keys only the file changed are taken
+// from it, keys only changed at runtime (waiting for SaveSoon) are
+// kept, and keys changed on both sides take the file's version.
+// channel_configs is merged per channel. When runtime changes were
+// kept, the merged result is saved back. A file that doesn't parse
+// (half-written by an editor) is reported and retried once it changes
+// again. The file is compared by content, not modification time,
+// which is too coarse to tell two quick writes apart. Settings from the
+// environment stay in force whatever the file says.
+func (c *Config) Reload() (ReloadResult, error) {
+	c.saveMu.Lock()
+	defer c.saveMu.Unlock()
+
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return ReloadResult{}, fmt.Errorf("reading config: %w", err)
+	}
+	if bytes.Equal(data, c.bad) {
+		return ReloadResult{}, nil
+	}
+	file, err := c.format.toJSON(data)
+	if err != nil {
+		c.bad = data
+		return ReloadResult{}, fmt.Errorf("parsing config: %w", err)
+	}
+	if bytes.Equal(file, c.base) {
+		return ReloadResult{}, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ours, err := c.marshalFile()
+	if err != nil {
+		return ReloadResult{}, fmt.Errorf("marshaling config: %w", err)
+	}
+	merged, res, err := mergeConfig(c.base, ours, file)
+	if err != nil {
+		c.bad = data
+		return ReloadResult{}, err
+	}
+	c.base, c.bad = file, nil
+	if len(res.Changed) == 0 {
+		return res, nil // reformatted, or changed back
+	}
+
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(merged, &raw); err != nil {
+		return ReloadResult{}, fmt.Errorf("parsing config: %w", err)
+	}
+	var next Config
+	if err := json.Unmarshal(merged, &next); err != nil {
+		return ReloadResult{}, fmt.Errorf("parsing config: %w", err)
+	}
+	next.applyDefaults(raw)
+	next.migrate()
+	c.assignLocked(&next)
+	if err := c.applyEnv(raw); err != nil {
+		return res, err
+	}
+
+	if !sameJSON(merged, file) {
+		c.SaveSoon()
+	}
+	return res, nil
+}
+
+// restartKeys are the keys of the Config fields tagged
+// reload:"restart".
+var restartKeys = func() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Tag.Get("reload") == "restart" {
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			keys[name] = true
+		}
+	}
+	return keys
+}()
+
+// assignLocked copies the serialized fields of n into c. Caller holds
+// c.mu.
+func (c *Config) assignLocked(n *Config) {
+	dst := reflect.ValueOf(c).Elem()
+	src := reflect.ValueOf(n).Elem()
+	for i := 0; i < dst.NumField(); i++ {
+		if dst.Type().Field(i).IsExported() {
+			dst.Field(i).Set(src.Field(i))
+		}
+	}
+}
+
+// mergeConfig merges the config file theirs into ours (the in-memory
+// config), both descended from base. See Reload.
+func mergeConfig(base, ours, theirs []byte) ([]byte, ReloadResult, error) {
+	var res ReloadResult
+	var b, o, t map[string]json.RawMessage
+	if len(base) > 0 {
+		if err := json.Unmarshal(base, &b); err != nil {
+			b = nil // unreadable last version: everything counts as changed
+		}
+	}
+	if err := json.Unmarshal(ours, &o); err != nil {
+		return nil, res, fmt.Errorf("parsing config: %w", err)
+	}
+	if err := json.Unmarshal(theirs, &t); err != nil {
+		return nil, res, fmt.Errorf("parsing config: %w", err)
+	}
+
+	merged := make(map[string]json.RawMessage, len(t))
+	for _, key := range unionKeys(b, o, t) {
+		bv, bok := b[key]
+		ov, ook := o[key]
+		tv, tok := t[key]
+		theirsChanged := bok != tok || (tok && !sameJSON(bv, tv))
+		oursChanged := bok != ook || (ook && !sameJSON(bv, ov))
+		if theirsChanged {
+			res.Changed = append(res.Changed, key)
+			if restartKeys[key] {
+				res.Restart = append(res.Restart, key)
+			}
+		}
+
+		switch {
+		case !theirsChanged:
+			if ook {
+				merged[key] = ov
+			}
+		case key == "channel_configs" && oursChanged:
+			v, conflicts, err := mergeChannels(bv, ov, tv)
+			if err != nil {
+				return nil, res, err
+			}
+			merged[key] = v
+			res.Conflicts = append(res.Conflicts, conflicts...)
+		default:
+			if oursChanged && !(ook == tok && sameJSON(ov, tv)) {
+				res.Conflicts = append(res.Conflicts, key)
+			}
+			if tok {
+				merged[key] = tv
+			}
+		}
+	}
+	out, err := json.Marshal(merged)
+	return out, res, err
+}
+
+// mergeChannels merges channel_configs per channel (by login): the
+// same three-way rule as mergeConfig, in the file's order with channels
+// only added at runtime appended.
+func mergeChannels(base, ours, theirs json.RawMessage) (json.RawMessage, []string, error) {
+	b, _, err := channelsByLogin(base)
+	if err != nil {
+		b = nil
+	}
+	o, oOrder, err := channelsByLogin(ours)
+	if err != nil {
+		return nil, nil, err
+	}
+	t, tOrder, err := channelsByLogin(theirs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var conflicts []string
+	var merged []json.RawMessage
+	add := func(login string) {
+		bv, bok := b[login]
+		ov, ook := o[login]
+		tv, tok := t[login]
+		theirsChanged := bok != tok || (tok && !sameJSON(bv, tv))
+		oursChanged := bok != ook || (ook && !sameJSON(bv, ov))
+		switch {
+		case !theirsChanged:
+			if ook {
+				merged = append(merged, ov)
+			}
+		default:
+			if oursChanged && !(ook == tok && sameJSON(ov, tv)) {
+				conflicts = append(conflicts, "channel_configs["+login+"]")
+			}
+			if tok {
+				merged = append(merged, tv)
+			}
+		}
+	}
+	seen := make(map[string]bool)
+	for _, login := range tOrder {
+		seen[login] = true
+		add(login)
+	}
+	for _, login := range oOrder {
+		if !seen[login] {
+			seen[login] = true
+			add(login)
+		}
+	}
+	for login := range b {
+		if !seen[login] {
+			add(login) // gone on both sides
+		}
+	}
+	if merged == nil {
+		merged = []json.RawMessage{}
+	}
+	out, err := json.Marshal(merged)
+	return out, conflicts, err
+}
+
+// channelsByLogin indexes a channel_configs array by lowercased login.
+func channelsByLogin(raw json.RawMessage) (map[string]json.RawMessage, []string, error) {
+	if len(raw) == 0 {
+		return nil, nil, nil
+	}
+	var entries []json.RawMessage
+	if err := json.Unmarshal(raw, &entries); err != nil {
+		return nil, nil, fmt.Errorf("parsing channel_configs: %w", err)
+	}
+	byLogin := make(map[string]json.RawMessage, len(entries))
+	order := make([]string, 0, len(entries))
+	for _, e := range entries {
+		var entry struct {
+			Login string `json:"login"`
+		}
+		if err := json.Unmarshal(e, &entry); err != nil {
+			return nil, nil, fmt.Errorf("parsing channel_configs: %w", err)
+		}
+		login := strings.ToLower(entry.Login)
+		if _, dup := byLogin[login]; !dup {
+			order = append(order, login)
+		}
+		byLogin[login] = e
+	}
+	return byLogin, order, nil
+}
+
+// sameJSON reports whether a and b encode the same value, whatever the
+// formatting and key order.
+func sameJSON(a, b []byte) bool {
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return bytes.Equal(a, b)
+	}
+	return reflect.DeepEqual(av, bv)
+}
+
+func unionKeys(maps ...map[string]json.RawMessage) []string {
+	seen := make(map[string]bool)
+	var keys []string
+	for _, m := range maps {
+		for k := range m {
+			if !seen[k] {
+				seen[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// editFile rewrites the config file the way an editor would: load the
+// JSON on disk, change it, write it back.
+func editFile(t *testing.T, path string, edit func(c *Config)) {
+	t.Helper()
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edit(c)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newReloadConfig(t *testing.T) (*Config, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.json")
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c.AddChannel("alpha")
+	c.AddChannel("beta")
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return c, path
+}
+
+func TestReload_OwnSaveIsNoChange(t *testing.T) {
+	c, _ := newReloadConfig(t)
+	c.SetPriority("alpha", 1)
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	res, err := c.Reload()
+	if err != nil || len(res.Changed) != 0 {
+		t.Errorf("Reload after own Save = %+v, %v; want no change", res, err)
+	}
+}
+
+func TestReload_TakesExternalEdits(t *testing.T) {
+	c, path := newReloadConfig(t)
+	editFile(t, path, func(e *Config) {
+		e.SetPriority("alpha", 1)
+		e.RemoveChannel("beta")
+		e.AddChannel("gamma")
+		e.MinViewers = 50
+	})
+
+	res, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"channel_configs", "min_viewers"}; !reflect.DeepEqual(res.Changed, want) {
+		t.Errorf("Changed = %v, want %v", res.Changed, want)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("Conflicts = %v, want none", res.Conflicts)
+	}
+	if got := c.GetChannelLogins(); !reflect.DeepEqual(got, []string{"alpha", "gamma"}) {
+		t.Errorf("channels = %v, want [alpha gamma]", got)
+	}
+	if c.GetPriority("alpha") != 1 || c.MinViewers != 50 {
+		t.Errorf("priority/min_viewers = %d/%d, want 1/50", c.GetPriority("alpha"), c.MinViewers)
+	}
+	if !c.GetWebEnabled() {
+		t.Error("reload lost the web_enabled default")
+	}
+}
+
+// TestReload_RestartKeys: fields tagged reload:"restart" are reported
+// as taking effect on the next start; others aren't.
+func TestReload_RestartKeys(t *testing.T) {
+	c, path := newReloadConfig(t)
+	editFile(t, path, func(e *Config) {
+		e.WebPort = 9090
+		e.MinViewers = 50
+		e.ProxyURL = "socks5://127.0.0.1:1080"
+	})
+
+	res, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"proxy_url", "web_port"}; !reflect.DeepEqual(res.Restart, want) {
+		t.Errorf("Restart = %v, want %v", res.Restart, want)
+	}
+	for key, want := range map[string]bool{"auth_token": true, "notifiers": true, "time_zone": false, "min_viewers": false} {
+		if restartKeys[key] != want {
+			t.Errorf("restartKeys[%s] = %v, want %v", key, restartKeys[key], want)
+		}
+	}
+}
+
+func TestReload_KeepsUnsavedRuntimeChanges(t *testing.T) {
+	c, path := newReloadConfig(t)
+	// Runtime: pause beta and add delta, not saved yet (SaveSoon pending).
+	c.SetChannelPaused("beta", true)
+	c.AddChannel("delta")
+	// Meanwhile the file gets a new priority for alpha.
+	editFile(t, path, func(e *Config) { e.SetPriority("alpha", 1) })
+
+	res, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Conflicts) != 0 {
+		t.Errorf("Conflicts = %v, want none", res.Conflicts)
+	}
+	if c.GetPriority("alpha") != 1 || !c.IsChannelPaused("beta") || !hasLogin(c, "delta") {
+		t.Errorf("merge lost a side: alpha P%d, beta paused %v, delta %v",
+			c.GetPriority("alpha"), c.IsChannelPaused("beta"), hasLogin(c, "delta"))
+	}
+
+	// The merged result is written back.
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	onDisk, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if onDisk.GetPriority("alpha") != 1 || !onDisk.IsChannelPaused("beta") || !hasLogin(onDisk, "delta") {
+		t.Error("merged config was not saved back")
+	}
+}
+
+func TestReload_ConflictFileWins(t *testing.T) {
+	c, path := newReloadConfig(t)
+	c.SetPriority("beta", 1)
+	editFile(t, path, func(e *Config) { e.SetChannelPaused("beta", true) })
+
+	res, err := c.Reload()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"channel_configs[beta]"}; !reflect.DeepEqual(res.Conflicts, want) {
+		t.Errorf("Conflicts = %v, want %v", res.Conflicts, want)
+	}
+	if c.GetPriority("beta") != 2 || !c.IsChannelPaused("beta") {
+		t.Errorf("beta = P%d paused %v, want the file's P2 paused", c.GetPriority("beta"), c.IsChannelPaused("beta"))
+	}
+}
+
+func TestReload_InvalidJSONKeepsConfig(t *testing.T) {
+	c, path := newReloadConfig(t)
+	if err := os.WriteFile(path, []byte(`{"channel_configs": [`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Reload(); err == nil {
+		t.Fatal("Reload of a half-written file returned no error")
+	}
+	if !hasLogin(c, "alpha") || !hasLogin(c, "beta") {
+		t.Error("failed reload changed the config")
+	}
+	// Not reported again until the file changes.
+	if _, err := c.Reload(); err != nil {
+		t.Errorf("second Reload of the same file: %v", err)
+	}
+}
+
+func hasLogin(c *Config, login string) bool {
+	for _, l := range c.GetChannelLogins() {
+		if l == login {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWindow(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.Local) }
+	tests := []struct {
+		window string
+		in     []time.Time
+		out    []time.Time
+	}{
+		{"02:00-08:00", []time.Time{at(2, 0), at(7, 59)}, []time.Time{at(1, 59), at(8, 0), at(23, 0)}},
+		{"23:00-07:30", []time.Time{at(23, 0), at(0, 0), at(7, 29)}, []time.Time{at(7, 30), at(22, 59), at(12, 0)}},
+	}
+	for _, tt := range tests {
+		w, err := ParseWindow(tt.window)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if w.String() != tt.window {
+			t.Errorf("String() = %q, want %q", w.String(), tt.window)
+		}
+		for _, tm := range tt.in {
+			if !w.Contains(tm) {
+				t.Errorf("%s should contain %s", tt.window, tm.Format("15:04"))
+			}
+		}
+		for _, tm := range tt.out {
+			if w.Contains(tm) {
+				t.Errorf("%s should not contain %s", tt.window, tm.Format("15:04"))
+			}
+		}
+	}
+	for _, bad := range []string{"", "08:00", "8-9", "25:00-01:00", "10:00-10:00"} {
+		if _, err := ParseWindow(bad); err == nil {
+			t.Errorf("ParseWindow(%q) accepted", bad)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2026, 3, 1, h, m, 0, 0, time.Local) }
+	s := Schedule{PauseWindows: []string{"02:00-08:00", "12:00-13:00"}}
+	if err := s.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if w, ok := s.PauseWindow(at(12, 30)); !ok || w.String() != "12:00-13:00" {
+		t.Errorf("PauseWindow(12:30) = %v, %v", w, ok)
+	}
+	if _, ok := s.PauseWindow(at(9, 0)); ok {
+		t.Error("09:00 is outside both windows")
+	}
+	for _, bad := range []Schedule{{PauseWindows: []string{"2-8"}}, {IdleMinutes: -1}, {IdleMinutes: MaxIdleMinutes + 1}} {
+		if bad.Validate() == nil {
+			t.Errorf("Validate(%+v) accepted", bad)
+		}
+	}
+
+	c := &Config{}
+	if got := c.GetSchedule(); len(got.PauseWindows) != 0 || got.IdleOnly || got.IdleMinutes != 0 {
+		t.Errorf("GetSchedule() of an empty config = %+v", got)
+	}
+	if got := (Schedule{}).IdleLimit(); got != DefaultIdleMinutes {
+		t.Errorf("IdleLimit() unset = %d, want %d", got, DefaultIdleMinutes)
+	}
+	if c.SetSchedule(Schedule{PauseWindows: []string{"nope"}}) || c.Schedule != nil {
+		t.Error("SetSchedule stored an invalid window")
+	}
+	if !c.SetSchedule(Schedule{IdleOnly: true}) || c.Schedule == nil {
+		t.Fatal("SetSchedule(idle_only) not stored")
+	}
+	if !c.SetSchedule(Schedule{}) || c.Schedule != nil {
+		t.Error("an empty schedule should be removed")
+	}
+}
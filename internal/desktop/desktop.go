@@ -0,0 +1,152 @@
+// Package desktop shows farmer notifications on the desktop — drop
+// claims, large points gains and a rejected auth token — as Windows
+// toasts, libnotify notifications on Linux or macOS Notification Center
+// banners.
+package desktop
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Farmer is the part of *farmer.Farmer desktop notifications use.
+type Farmer interface {
+	Subscribe() (<-chan farmer.PushEvent, func())
+	NotificationsEnabled() bool
+	GetChannels() []channels.Snapshot
+	Done() <-chan struct{}
+	Logf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+var _ Farmer = (*farmer.Farmer)(nil)
+
+// Notification is one desktop notification.
+type Notification struct {
+	Title  string
+	Body   string
+	Urgent bool // shown with raised urgency where the platform has one
+}
+
+// notifier turns farmer events into notifications.
+type notifier struct {
+	cfg   config.Desktop
+	quiet *config.Window // nil: no quiet hours
+	f     Farmer
+	show  func(Notification) error
+
+	mu      sync.Mutex
+	failing bool // the last show failed; logged once until one works
+}
+
+// Start shows notifications for farmer events until the farmer stops.
+// An invalid quiet_hours is logged and ignored.
+func Start(cfg config.Desktop, f Farmer) {
+	n := &notifier{cfg: cfg, f: f, show: show}
+	if cfg.QuietHours != "" {
+		w, err := config.ParseWindow(cfg.QuietHours)
+		if err != nil {
+			f.Warnf("[Desktop] Warning: ignoring quiet_hours: %v", err)
+		} else {
+			n.quiet = &w
+		}
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-f.Done()
+		cancel()
+	}()
+	go n.run(ctx)
+}
+
+// run shows the notifications for push events. Each is shown in its own
+// goroutine: helpers like PowerShell take a moment to start.
+func (n *notifier) run(ctx context.Context) {
+	events, cancel := n.f.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if !n.f.NotificationsEnabled() {
+				continue
+			}
+			if note, ok := n.notification(ev, time.Now()); ok {
+				go n.display(note)
+			}
+		}
+	}
+}
+
+// display shows note, logging the first of a run of failures.
+func (n *notifier) display(note Notification) {
+	err := n.show(note)
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if err != nil && !n.failing {
+		n.f.Warnf("[Desktop] Warning: could not show a notification: %v", err)
+	}
+	n.failing = err != nil
+}
+
+// notification renders a push event, or reports false when it isn't
+// one to show (that kind is off, or now is in quiet hours).
+func (n *notifier) notification(ev farmer.PushEvent, now time.Time) (Notification, bool) {
+	if n.quiet != nil && n.quiet.Contains(now) {
+		return Notification{}, false
+	}
+	switch ev.Kind {
+	case farmer.PushKindDropClaimed:
+		d, ok := ev.Data.(drops.DropClaimed)
+		if !ok || !n.cfg.Notifies(config.NotifyDrops) {
+			return Notification{}, false
+		}
+		body := d.Name
+		if d.Campaign != "" {
+			body += " (" + d.Campaign + ")"
+		}
+		return Notification{Title: "Drop claimed", Body: body}, true
+	case farmer.PushKindEvent:
+		e, ok := ev.Data.(farmer.PushFarmerEvent)
+		if !ok || e.Type != twitch.EventPointsEarned.String() || !n.cfg.Notifies(config.NotifyBonus) {
+			return Notification{}, false
+		}
+		p, ok := e.Data.(twitch.PointsData)
+		if !ok || p.PointsGained < n.cfg.MinBonus {
+			return Notification{}, false
+		}
+		return Notification{
+			Title: fmt.Sprintf("+%d points on %s", p.PointsGained, n.channelName(e.ChannelID)),
+			Body:  fmt.Sprintf("%s — balance %d", twitch.ReasonLabel(p.ReasonCode), p.TotalPoints),
+		}, true
+	case farmer.PushKindAuthFailed:
+		a, ok := ev.Data.(farmer.AuthFailed)
+		if !ok || !n.cfg.Notifies(config.NotifyAuth) {
+			return Notification{}, false
+		}
+		return Notification{Title: "TwitchPoint login needed", Body: a.Message, Urgent: true}, true
+	}
+	return Notification{}, false
+}
+
+// channelName returns the display name of a tracked channel, else id.
+func (n *notifier) channelName(id string) string {
+	for _, ch := range n.f.GetChannels() {
+		if ch.ChannelID == id {
+			return ch.DisplayName
+		}
+	}
+	return id
+}
@@ -0,0 +1,67 @@
+package desktop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+type fakeFarmer struct{}
+
+func (fakeFarmer) Subscribe() (<-chan farmer.PushEvent, func()) { return nil, func() {} }
+func (fakeFarmer) NotificationsEnabled() bool                   { return true }
+func (fakeFarmer) Done() <-chan struct{}                        { return nil }
+func (fakeFarmer) Logf(string, ...interface{})                  {}
+func (fakeFarmer) Warnf(string, ...interface{})                 {}
+func (fakeFarmer) GetChannels() []channels.Snapshot {
+	return []channels.Snapshot{{ChannelID: "1", DisplayName: "Alpha"}}
+}
+
+func points(gained int) farmer.PushEvent {
+	return farmer.PushEvent{Kind: farmer.PushKindEvent, Data: farmer.PushFarmerEvent{
+		Type:      twitch.EventPointsEarned.String(),
+		ChannelID: "1",
+		Data:      twitch.PointsData{PointsGained: gained, TotalPoints: 5000, ReasonCode: "RAID"},
+	}}
+}
+
+// TestNotification checks which events are shown: drop claims, gains
+// from min_bonus up, auth failures, nothing in quiet hours.
+func TestNotification(t *testing.T) {
+	n := &notifier{cfg: config.Desktop{Enabled: true, MinBonus: 1000}, f: fakeFarmer{}}
+	noon := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+
+	claim := farmer.PushEvent{Kind: farmer.PushKindDropClaimed, Data: drops.DropClaimed{Name: "Skin", Campaign: "Rust Week"}}
+	if got, ok := n.notification(claim, noon); !ok || got.Body != "Skin (Rust Week)" {
+		t.Errorf("drop claim = %+v, %v", got, ok)
+	}
+	if _, ok := n.notification(points(50), noon); ok {
+		t.Error("a 50-point gain was shown with min_bonus 1000")
+	}
+	if got, ok := n.notification(points(1200), noon); !ok || got.Title != "+1200 points on Alpha" {
+		t.Errorf("bonus = %+v, %v", got, ok)
+	}
+	auth := farmer.PushEvent{Kind: farmer.PushKindAuthFailed, Data: farmer.AuthFailed{Message: "log in again"}}
+	if got, ok := n.notification(auth, noon); !ok || !got.Urgent {
+		t.Errorf("auth = %+v, %v", got, ok)
+	}
+
+	n.cfg.Notify = []string{config.NotifyAuth}
+	if _, ok := n.notification(claim, noon); ok {
+		t.Error("drop claim shown with notify [auth]")
+	}
+
+	w, _ := config.ParseWindow("23:00-08:00")
+	n.quiet = &w
+	if _, ok := n.notification(auth, noon.Add(-10*time.Hour)); ok {
+		t.Error("shown at 02:00 in quiet hours 23:00-08:00")
+	}
+	if _, ok := n.notification(auth, noon); !ok {
+		t.Error("not shown at noon outside quiet hours")
+	}
+}
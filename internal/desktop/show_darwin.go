@@ -0,0 +1,15 @@
+//go:build darwin
+
+package desktop
+
+import "os/exec"
+
+// show posts a Notification Center banner through osascript. Title and
+// body are passed as arguments, not pasted into the script.
+func show(n Notification) error {
+	return exec.Command("osascript",
+		"-e", "on run argv",
+		"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+		"-e", "end run",
+		n.Title, n.Body).Run()
+}
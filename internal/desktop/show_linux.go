@@ -0,0 +1,23 @@
+//go:build linux
+
+package desktop
+
+import "github.com/godbus/dbus/v5"
+
+// show sends the notification to the desktop's notification daemon
+// (org.freedesktop.Notifications, what libnotify talks to).
+func show(n Notification) error {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	hints := map[string]dbus.Variant{}
+	if n.Urgent {
+		hints["urgency"] = dbus.MakeVariant(byte(2)) // critical
+	}
+	obj := conn.Object("org.freedesktop.Notifications", "/org/freedesktop/Notifications")
+	return obj.Call("org.freedesktop.Notifications.Notify", 0,
+		"TwitchPoint", uint32(0), "", n.Title, n.Body, []string{}, hints, int32(-1)).Err
+}
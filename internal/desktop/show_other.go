@@ -0,0 +1,10 @@
+//go:build !linux && !darwin && !windows
+
+package desktop
+
+import "errors"
+
+// show: no desktop notifications on this platform (BSDs and others).
+func show(Notification) error {
+	return errors.New("desktop notifications are not supported on this platform")
+}
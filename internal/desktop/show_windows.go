@@ -0,0 +1,32 @@
+//go:build windows
+
+package desktop
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+// toastScript shows a toast through the WinRT API from PowerShell, under
+// PowerShell's own app ID (an unregistered one shows nothing). Title and
+// body come from the environment, not pasted into the script.
+const toastScript = `$ErrorActionPreference = 'Stop'
+[Windows.UI.Notifications.ToastNotificationManager, Windows.UI.Notifications, ContentType = WindowsRuntime] | Out-Null
+$xml = [Windows.UI.Notifications.ToastNotificationManager]::GetTemplateContent([Windows.UI.Notifications.ToastTemplateType]::ToastText02)
+$text = $xml.GetElementsByTagName('text')
+$text.Item(0).AppendChild($xml.CreateTextNode($env:TWITCHPOINT_TOAST_TITLE)) | Out-Null
+$text.Item(1).AppendChild($xml.CreateTextNode($env:TWITCHPOINT_TOAST_BODY)) | Out-Null
+$app = '{1AC14E77-02E7-4E5D-B744-2EB1AE5198B7}\WindowsPowerShell\v1.0\powershell.exe'
+[Windows.UI.Notifications.ToastNotificationManager]::CreateToastNotifier($app).Show([Windows.UI.Notifications.ToastNotification]::new($xml))`
+
+// createNoWindow keeps PowerShell from flashing a console window.
+const createNoWindow = 0x08000000
+
+// show pops up a Windows toast.
+func show(n Notification) error {
+	cmd := exec.Command("powershell.exe", "-NoProfile", "-NonInteractive", "-Command", toastScript)
+	cmd.Env = append(os.Environ(), "TWITCHPOINT_TOAST_TITLE="+n.Title, "TWITCHPOINT_TOAST_BODY="+n.Body)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true, CreationFlags: createNoWindow}
+	return cmd.Run()
+}
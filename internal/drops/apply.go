@@ -2,9 +2,10 @@ package drops
 
 import (
 	"fmt"
-	"strings"
 	"time"
 
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
@@ -68,7 +69,7 @@ func (s *Service) ApplyPick(pick *PoolEntry, campaigns []twitch.DropCampaign) Ap
 			bidStr = info.BroadcastID
 			gidStr = info.GameID
 		}
-		s.log("[Drops/Watch] skip %s — refresh failed (live=%s broadcast=%q game_id=%q)",
+		s.warn("[Drops/Watch] skip %s — refresh failed (live=%s broadcast=%q game_id=%q)",
 			pick.ChannelLogin, liveStr, bidStr, gidStr)
 		return ApplyBail
 	}
@@ -79,13 +80,22 @@ func (s *Service) ApplyPick(pick *PoolEntry, campaigns []twitch.DropCampaign) Ap
 	//    sendSpadeEvents with a wrong game_id makes Twitch silently
 	//    drop credit. Cooldown is set, so the caller can re-select
 	//    immediately and the broken channel is excluded from the pool.
-	if !PickGameMatches(pick, info.GameName) {
+	if !PickGameMatches(s.cfg, pick, info.GameID, info.GameName) {
 		s.log("[Drops/Watch] skip %s — game changed to %q (expected one of %s)",
 			pick.ChannelLogin, info.GameName, PickCampaignGames(pick))
 		s.Stall.SetManual(pick.ChannelID, 15*time.Minute)
 		return ApplyRetry
 	}
 
+	// 2b. Rerun guard: with rerun_mode "skip" a rebroadcast is never
+	//     the pick — reruns often don't credit drop progress. Same
+	//     cooldown-and-retry as a game change.
+	if info.IsRerun() && s.cfg.GetRerunMode() == config.RerunModeSkip {
+		s.log("[Drops/Watch] skip %s — stream is a rerun (rerun_mode skip)", pick.ChannelLogin)
+		s.Stall.SetManual(pick.ChannelID, 30*time.Minute)
+		return ApplyRetry
+	}
+
 	// 3. Channel-ID consistency: pick.ChannelID came from the selector
 	//    pool (built from directory or allowed_channels). info.ID came
 	//    from a direct user(login:) lookup just now. They MUST match —
@@ -111,7 +121,7 @@ func (s *Service) ApplyPick(pick *PoolEntry, campaigns []twitch.DropCampaign) Ap
 			primaryCampID = pick.Campaigns[0].ID
 		}
 		if err := s.addTempChannelFromInfo(info, primaryCampID); err != nil {
-			s.log("[Drops/Pool] failed to add %s: %v", pick.ChannelLogin, err)
+			s.warn("[Drops/Pool] failed to add %s: %v", pick.ChannelLogin, err)
 			return ApplyBail
 		}
 		ch, exists = s.channels.Get(pick.ChannelID)
@@ -121,6 +131,7 @@ func (s *Service) ApplyPick(pick *PoolEntry, campaigns []twitch.DropCampaign) Ap
 	} else {
 		// Existing channel — refresh its state with the verified metadata.
 		ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+		ch.SetRerun(info.IsRerun())
 	}
 	snap := ch.Snapshot()
 
@@ -184,7 +195,7 @@ func (s *Service) ApplyPick(pick *PoolEntry, campaigns []twitch.DropCampaign) Ap
 			s.triggerRotation()
 		}
 		if !s.spade.StartWatching(snap.ChannelID, snap.Login, snap.BroadcastID, snap.GameName, snap.GameID) {
-			s.log("[Drops/Watch] WARNING: no free Spade slot for %s — drop minutes will not credit", snap.DisplayName)
+			s.warn("[Drops/Watch] WARNING: no free Spade slot for %s — drop minutes will not credit", snap.DisplayName)
 		}
 		s.log("[Drops/Watch] handing %s to drops Watcher (exclusive+spade)", snap.DisplayName)
 	}
@@ -240,6 +251,7 @@ func (s *Service) RefreshWatcherBroadcast(channelID, login string) {
 	}
 	if ch, ok := s.channels.Get(channelID); ok {
 		ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+		ch.SetRerun(info.IsRerun())
 	}
 	s.watcher.UpdateBroadcast(channelID, info.BroadcastID, info.GameName, info.GameID)
 	// Keep the pick's Spade heartbeats (step 8) on the fresh broadcast_id
@@ -274,10 +286,10 @@ func (s *Service) HandleGameChange(channelID string, data twitch.GameChangeData)
 	}
 
 	s.mu.RLock()
-	expectedGame := ""
+	expectedGame, expectedGameID := "", ""
 	pickedChannelLogin := ""
 	if c, ok := s.campaignCache[pickCampaign]; ok {
-		expectedGame = c.GameName
+		expectedGame, expectedGameID = c.GameName, c.GameID
 	}
 	if ch, ok := s.channels.Get(channelID); ok {
 		pickedChannelLogin = ch.Login
@@ -299,7 +311,8 @@ func (s *Service) HandleGameChange(channelID string, data twitch.GameChangeData)
 	}
 
 	// Optimistic early-out: payload already shows we're back on the right game.
-	if strings.EqualFold(data.NewGameName, expectedGame) {
+	// The PubSub payload only has names.
+	if sameGame(s.cfg, "", data.NewGameName, "", expectedGame) {
 		s.log("[Drops/WS] %s switched back to %q — keeping pick", channelID, expectedGame)
 		go s.RefreshWatcherBroadcast(channelID, pickedChannelLogin)
 		return
@@ -320,7 +333,7 @@ func (s *Service) HandleGameChange(channelID string, data twitch.GameChangeData)
 		}
 
 		info, err := s.gql.GetChannelInfo(pickedChannelLogin)
-		if err == nil && strings.EqualFold(info.GameName, expectedGame) {
+		if err == nil && sameGame(s.cfg, info.GameID, info.GameName, expectedGameID, expectedGame) {
 			s.log("[Drops/WS] %s flapped back to %q during 30s debounce — keeping pick",
 				channelID, expectedGame)
 			return
@@ -334,3 +347,43 @@ func (s *Service) HandleGameChange(channelID string, data twitch.GameChangeData)
 		s.ProcessDrops()
 	}()
 }
+
+// offlinePickCooldown keeps a just-offline channel out of the pool while
+// Twitch's game directory catches up — for a minute or two after
+// stream-down the directory can still list it as live, and the
+// immediate re-select would pick it right back.
+const offlinePickCooldown = 10 * time.Minute
+
+// HandleStreamDown reacts to the drop pick (or any temp drop channel)
+// going offline: stops the Watcher right away — sending sendSpadeEvents
+// for an offline broadcast looks suspicious to Twitch — puts the channel
+// in cooldown and re-runs the selector out of cycle, so a replacement
+// for the campaign is watching within seconds instead of at the next
+// 15-min inventory cycle. Returns false for non-drops channels, which
+// only need the points-side slot refill.
+func (s *Service) HandleStreamDown(snap channels.Snapshot) bool {
+	isPick := s.IsCurrentPick(snap.ChannelID)
+	if !isPick && !snap.IsTemporary {
+		return false
+	}
+	if isPick && s.watcher != nil {
+		s.watcher.Stop()
+	}
+	s.Stall.SetManual(snap.ChannelID, offlinePickCooldown)
+
+	campaignID := snap.CampaignID
+	if campaignID == "" && isPick {
+		campaignID = s.Stall.LastPickCampaignID()
+	}
+	s.mu.RLock()
+	campaignName := s.campaignCache[campaignID].Name
+	s.mu.RUnlock()
+	if campaignName == "" {
+		campaignName = "unknown campaign"
+	}
+
+	s.log("[Drops] %s went offline mid-drop (%s) — re-selecting now, %v cooldown",
+		snap.DisplayName, campaignName, offlinePickCooldown)
+	s.ProcessDrops()
+	return true
+}
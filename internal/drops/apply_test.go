@@ -0,0 +1,72 @@
+package drops
+
+import (
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func newStreamDownService() *Service {
+	s := NewService(ServiceDeps{
+		Channels: channels.New(),
+		Log:      func(string, ...interface{}) {},
+	})
+	s.campaignCache = map[string]twitch.DropCampaign{"camp-1": {ID: "camp-1", Name: "Camp One"}}
+	return s
+}
+
+func queuedKick(s *Service) bool {
+	select {
+	case <-s.processQueue:
+		return true
+	default:
+		return false
+	}
+}
+
+// TestHandleStreamDown_PickCoolsDownAndReselects: the pick going offline
+// must kick an out-of-cycle ProcessDrops and keep the channel out of the
+// next Select — even when HasActiveDrop was already cleared.
+func TestHandleStreamDown_PickCoolsDownAndReselects(t *testing.T) {
+	s := newStreamDownService()
+	s.currentPickID = "111"
+
+	if !s.HandleStreamDown(channels.Snapshot{ChannelID: "111", DisplayName: "Alice", CampaignID: "camp-1"}) {
+		t.Fatal("pick going offline should be handled by drops")
+	}
+	if !queuedKick(s) {
+		t.Fatal("expected an immediate ProcessDrops kick")
+	}
+	if !s.Stall.ActiveSkipSet()["111"] {
+		t.Fatal("offline pick should be in cooldown so the re-select can't re-pick it")
+	}
+}
+
+// TestHandleStreamDown_TempChannelReselects: a temp drop channel that
+// isn't (or is no longer) the pick still triggers the re-select.
+func TestHandleStreamDown_TempChannelReselects(t *testing.T) {
+	s := newStreamDownService()
+	if !s.HandleStreamDown(channels.Snapshot{ChannelID: "222", IsTemporary: true, CampaignID: "camp-1"}) {
+		t.Fatal("temp drop channel going offline should be handled by drops")
+	}
+	if !queuedKick(s) {
+		t.Fatal("expected an immediate ProcessDrops kick")
+	}
+}
+
+// TestHandleStreamDown_RegularChannelIgnored: plain points channels are
+// left to the points-side slot refill.
+func TestHandleStreamDown_RegularChannelIgnored(t *testing.T) {
+	s := newStreamDownService()
+	s.currentPickID = "111"
+	if s.HandleStreamDown(channels.Snapshot{ChannelID: "333"}) {
+		t.Fatal("non-drops channel should not be handled by drops")
+	}
+	if queuedKick(s) {
+		t.Fatal("non-drops channel must not kick ProcessDrops")
+	}
+	if s.Stall.ActiveSkipSet()["333"] {
+		t.Fatal("non-drops channel must not be cooled down")
+	}
+}
@@ -0,0 +1,354 @@
+package drops
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Archive outcomes (CampaignRecord.Outcome).
+const (
+	OutcomeCompleted = "completed" // every drop claimed (or marked completed)
+	OutcomeExpired   = "expired"   // the campaign ended with drops left
+	OutcomeRemoved   = "removed"   // left the inventory before its end, drops left
+)
+
+const (
+	// maxArchived caps the completed-campaigns archive; the oldest
+	// records go first.
+	maxArchived = 100
+	// timelineStep is the least time between two timeline points of
+	// the same drop, so a campaign farmed for days stays a few hundred
+	// points.
+	timelineStep = 10 * time.Minute
+	// maxTimeline caps the points kept per campaign.
+	maxTimeline = 500
+)
+
+// ProgressPoint is one timeline entry of a CampaignRecord.
+type ProgressPoint struct {
+	At       time.Time `json:"at"`
+	Drop     string    `json:"drop"`
+	Progress int       `json:"progress"` // minutes watched
+	Required int       `json:"required"`
+}
+
+// ArchivedDrop is a drop of a CampaignRecord as last seen.
+type ArchivedDrop struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Progress int    `json:"progress"`
+	Required int    `json:"required"`
+	Claimed  bool   `json:"claimed"`
+}
+
+// CampaignRecord is what the archive keeps of a campaign we farmed:
+// when, on which channels, for how many minutes and how progress went.
+type CampaignRecord struct {
+	CampaignID string          `json:"campaign_id"`
+	Name       string          `json:"name"`
+	Game       string          `json:"game"`
+	EndAt      time.Time       `json:"end_at"`     // campaign end
+	FirstSeen  time.Time       `json:"first_seen"` // first farmed
+	LastSeen   time.Time       `json:"last_seen"`  // last in the inventory
+	ArchivedAt time.Time       `json:"archived_at,omitempty"`
+	Outcome    string          `json:"outcome,omitempty"` // Outcome*; empty while still tracked
+	Minutes    int             `json:"minutes"`           // drop minutes credited while tracked
+	Channels   []string        `json:"channels"`          // logins watched for it, in order of first use
+	Drops      []ArchivedDrop  `json:"drops"`
+	Timeline   []ProgressPoint `json:"timeline"`
+}
+
+// archiveFile is the archive's on-disk layout.
+type archiveFile struct {
+	Tracking []*CampaignRecord `json:"tracking"`
+	Archived []*CampaignRecord `json:"archived"`
+}
+
+// Archive records the campaigns the farmer works on and, once one is
+// done (claimed, expired or gone from the inventory), moves its record
+// to the completed-campaigns archive. Both survive restarts in a JSON
+// file; an empty path keeps them in memory. A nil *Archive records
+// nothing.
+type Archive struct {
+	mu       sync.Mutex
+	path     string
+	tracking map[string]*CampaignRecord // campaignID -> record
+	archived []*CampaignRecord          // oldest first
+	log      func(string, ...interface{})
+}
+
+// NewArchive loads the archive at path (if it exists). log may be nil.
+func NewArchive(path string, log func(string, ...interface{})) *Archive {
+	a := &Archive{path: path, tracking: make(map[string]*CampaignRecord), log: log}
+	if path == "" {
+		return a
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return a
+	}
+	var f archiveFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		a.logf("[Drops/Archive] Ignoring unreadable %s: %v", path, err)
+		return a
+	}
+	for _, r := range f.Tracking {
+		a.tracking[r.CampaignID] = r
+	}
+	a.archived = f.Archived
+	return a
+}
+
+func (a *Archive) logf(format string, args ...interface{}) {
+	if a.log != nil {
+		a.log(format, args...)
+	}
+}
+
+// Observe takes an inventory cycle: it starts tracking the campaigns of
+// pick, updates every tracked campaign from campaigns and archives the
+// ones that are done. completed reports campaigns marked completed in
+// the config. Returns the records archived.
+func (a *Archive) Observe(campaigns []twitch.DropCampaign, pick *PoolEntry, completed func(campaignID string) bool, now time.Time) []CampaignRecord {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	byID := make(map[string]twitch.DropCampaign, len(campaigns))
+	for _, c := range campaigns {
+		byID[c.ID] = c
+	}
+	if pick != nil {
+		for _, ref := range pick.Campaigns {
+			c, ok := byID[ref.ID]
+			if !ok {
+				continue
+			}
+			r := a.tracking[c.ID]
+			if r == nil {
+				r = &CampaignRecord{CampaignID: c.ID, FirstSeen: now}
+				a.tracking[c.ID] = r
+			}
+			r.addChannel(pick.ChannelLogin)
+		}
+	}
+
+	var done []CampaignRecord
+	for id, r := range a.tracking {
+		c, ok := byID[id]
+		if ok {
+			r.update(c, now)
+		}
+		outcome := ""
+		switch {
+		case ok && (r.allClaimed() || completed(id)):
+			outcome = OutcomeCompleted
+		case !r.EndAt.IsZero() && now.After(r.EndAt):
+			outcome = OutcomeExpired
+		case !ok && r.allClaimed():
+			outcome = OutcomeCompleted
+		case !ok:
+			outcome = OutcomeRemoved
+		}
+		if outcome == "" {
+			continue
+		}
+		r.Outcome, r.ArchivedAt = outcome, now
+		delete(a.tracking, id)
+		a.archived = append(a.archived, r)
+		done = append(done, *r)
+	}
+	if len(a.archived) > maxArchived {
+		a.archived = a.archived[len(a.archived)-maxArchived:]
+	}
+	for _, r := range done {
+		a.logf("[Drops/Archive] Campaign %q %s after %d min on %d channel(s) — archived", r.Name, r.Outcome, r.Minutes, len(r.Channels))
+	}
+	a.saveLocked()
+	return done
+}
+
+// Progress applies a live progress update (PubSub or poll) to a tracked
+// campaign between inventory cycles. login is the channel watched, ""
+// if unknown.
+func (a *Archive) Progress(campaignID, dropID, dropName, login string, progress, required int, now time.Time) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	r := a.tracking[campaignID]
+	if r == nil {
+		return
+	}
+	r.addChannel(login)
+	for i := range r.Drops {
+		d := &r.Drops[i]
+		if d.ID != dropID && (dropID != "" || d.Name != dropName) {
+			continue
+		}
+		if progress > d.Progress {
+			r.Minutes += progress - d.Progress
+		}
+		d.Progress = progress
+		if required > 0 {
+			d.Required = required
+		}
+		if r.addPoint(ProgressPoint{At: now, Drop: d.Name, Progress: progress, Required: d.Required}) {
+			a.saveLocked()
+		}
+		return
+	}
+}
+
+// Archived returns the archived records, newest first.
+func (a *Archive) Archived() []CampaignRecord {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]CampaignRecord, 0, len(a.archived))
+	for i := len(a.archived) - 1; i >= 0; i-- {
+		out = append(out, a.archived[i].clone())
+	}
+	return out
+}
+
+// Tracking returns the records of the campaigns still being worked on,
+// most recently farmed first.
+func (a *Archive) Tracking() []CampaignRecord {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	out := make([]CampaignRecord, 0, len(a.tracking))
+	for _, r := range a.tracking {
+		out = append(out, r.clone())
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].LastSeen.After(out[j].LastSeen) })
+	return out
+}
+
+// saveLocked writes the archive file (temp file + rename). Caller
+// holds a.mu.
+func (a *Archive) saveLocked() {
+	if a.path == "" {
+		return
+	}
+	f := archiveFile{Tracking: make([]*CampaignRecord, 0, len(a.tracking)), Archived: a.archived}
+	for _, r := range a.tracking {
+		f.Tracking = append(f.Tracking, r)
+	}
+	sort.Slice(f.Tracking, func(i, j int) bool { return f.Tracking[i].FirstSeen.Before(f.Tracking[j].FirstSeen) })
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		a.logf("[Drops/Archive] Save failed: %v", err)
+		return
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		a.logf("[Drops/Archive] Save failed: %v", err)
+	}
+}
+
+// update refreshes r from an inventory entry of its campaign, counting
+// the minutes credited since the last look.
+func (r *CampaignRecord) update(c twitch.DropCampaign, now time.Time) {
+	r.Name, r.Game, r.EndAt, r.LastSeen = c.Name, c.GameName, c.EndAt, now
+	prev := make(map[string]ArchivedDrop, len(r.Drops))
+	for _, d := range r.Drops {
+		prev[d.ID] = d
+	}
+	drops := make([]ArchivedDrop, 0, len(c.Drops))
+	for _, d := range c.Drops {
+		if d.RequiredMinutesWatched <= 0 {
+			continue
+		}
+		name := d.BenefitName
+		if name == "" {
+			name = d.Name
+		}
+		ad := ArchivedDrop{
+			ID:       d.ID,
+			Name:     name,
+			Progress: d.CurrentMinutesWatched,
+			Required: d.RequiredMinutesWatched,
+			Claimed:  d.IsClaimed,
+		}
+		if p, ok := prev[d.ID]; ok && ad.Progress > p.Progress {
+			r.Minutes += ad.Progress - p.Progress
+		}
+		if p, ok := prev[d.ID]; !ok || p.Progress != ad.Progress || p.Claimed != ad.Claimed {
+			r.addPoint(ProgressPoint{At: now, Drop: name, Progress: ad.Progress, Required: ad.Required})
+		}
+		drops = append(drops, ad)
+	}
+	r.Drops = drops
+}
+
+// addPoint appends p to the timeline unless the drop's last point is
+// less than timelineStep old or shows the same progress. Reports
+// whether it was added.
+func (r *CampaignRecord) addPoint(p ProgressPoint) bool {
+	for i := len(r.Timeline) - 1; i >= 0; i-- {
+		last := r.Timeline[i]
+		if last.Drop != p.Drop {
+			continue
+		}
+		if last.Progress == p.Progress || (p.Progress < p.Required && p.At.Sub(last.At) < timelineStep) {
+			return false
+		}
+		break
+	}
+	r.Timeline = append(r.Timeline, p)
+	if len(r.Timeline) > maxTimeline {
+		r.Timeline = r.Timeline[len(r.Timeline)-maxTimeline:]
+	}
+	return true
+}
+
+func (r *CampaignRecord) addChannel(login string) {
+	if login == "" {
+		return
+	}
+	for _, l := range r.Channels {
+		if l == login {
+			return
+		}
+	}
+	r.Channels = append(r.Channels, login)
+}
+
+// allClaimed reports whether every drop of r is claimed. False before
+// the first update.
+func (r *CampaignRecord) allClaimed() bool {
+	if len(r.Drops) == 0 {
+		return false
+	}
+	for _, d := range r.Drops {
+		if !d.Claimed {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *CampaignRecord) clone() CampaignRecord {
+	c := *r
+	c.Channels = append([]string(nil), r.Channels...)
+	c.Drops = append([]ArchivedDrop(nil), r.Drops...)
+	c.Timeline = append([]ProgressPoint(nil), r.Timeline...)
+	return c
+}
@@ -0,0 +1,127 @@
+package drops
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func notCompleted(string) bool { return false }
+
+func TestArchive_ClaimedCampaignIsArchived(t *testing.T) {
+	a := NewArchive("", nil)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	pick := &PoolEntry{ChannelLogin: "streamer", Campaigns: []CampaignRef{{ID: "c1"}}}
+
+	c := makeCampaign("c1", "d1", 10, 60, false)
+	c.Name, c.GameName, c.EndAt = "Spring Drops", "Rust", now.Add(48*time.Hour)
+	if done := a.Observe([]twitch.DropCampaign{c}, pick, notCompleted, now); len(done) != 0 {
+		t.Fatalf("archived %d records on the first cycle, want 0", len(done))
+	}
+
+	// Live progress between cycles counts, on a second channel.
+	a.Progress("c1", "d1", "", "other", 40, 60, now.Add(30*time.Minute))
+
+	c.Drops[0].CurrentMinutesWatched, c.Drops[0].IsClaimed = 60, true
+	done := a.Observe([]twitch.DropCampaign{c}, pick, notCompleted, now.Add(time.Hour))
+	if len(done) != 1 {
+		t.Fatalf("archived %d records, want 1", len(done))
+	}
+	r := done[0]
+	if r.Outcome != OutcomeCompleted || r.Name != "Spring Drops" || r.Game != "Rust" {
+		t.Errorf("record = %+v, want completed Spring Drops (Rust)", r)
+	}
+	if r.Minutes != 50 {
+		t.Errorf("Minutes = %d, want 50 (10 → 40 → 60)", r.Minutes)
+	}
+	if len(r.Channels) != 2 || r.Channels[0] != "streamer" || r.Channels[1] != "other" {
+		t.Errorf("Channels = %v, want [streamer other]", r.Channels)
+	}
+	if len(r.Timeline) != 3 {
+		t.Errorf("Timeline has %d points, want 3: %+v", len(r.Timeline), r.Timeline)
+	}
+	if got := a.Tracking(); len(got) != 0 {
+		t.Errorf("still tracking %d campaigns after archiving", len(got))
+	}
+	if got := a.Archived(); len(got) != 1 || got[0].CampaignID != "c1" {
+		t.Errorf("Archived() = %+v, want c1", got)
+	}
+}
+
+func TestArchive_Outcomes(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	pick := &PoolEntry{ChannelLogin: "streamer", Campaigns: []CampaignRef{{ID: "c1"}}}
+	c := makeCampaign("c1", "d1", 10, 60, false)
+	c.EndAt = now.Add(time.Hour)
+
+	t.Run("expired", func(t *testing.T) {
+		a := NewArchive("", nil)
+		a.Observe([]twitch.DropCampaign{c}, pick, notCompleted, now)
+		done := a.Observe([]twitch.DropCampaign{c}, nil, notCompleted, now.Add(2*time.Hour))
+		if len(done) != 1 || done[0].Outcome != OutcomeExpired {
+			t.Errorf("done = %+v, want one expired record", done)
+		}
+	})
+	t.Run("removed", func(t *testing.T) {
+		a := NewArchive("", nil)
+		a.Observe([]twitch.DropCampaign{c}, pick, notCompleted, now)
+		done := a.Observe(nil, nil, notCompleted, now.Add(time.Minute))
+		if len(done) != 1 || done[0].Outcome != OutcomeRemoved {
+			t.Errorf("done = %+v, want one removed record", done)
+		}
+	})
+	t.Run("marked completed", func(t *testing.T) {
+		a := NewArchive("", nil)
+		a.Observe([]twitch.DropCampaign{c}, pick, notCompleted, now)
+		done := a.Observe([]twitch.DropCampaign{c}, pick, func(id string) bool { return id == "c1" }, now.Add(time.Minute))
+		if len(done) != 1 || done[0].Outcome != OutcomeCompleted {
+			t.Errorf("done = %+v, want one completed record", done)
+		}
+	})
+	t.Run("not picked", func(t *testing.T) {
+		a := NewArchive("", nil)
+		if a.Observe([]twitch.DropCampaign{c}, nil, notCompleted, now); len(a.Tracking()) != 0 {
+			t.Error("a campaign nobody farmed was tracked")
+		}
+	})
+}
+
+func TestArchive_TimelineThinned(t *testing.T) {
+	a := NewArchive("", nil)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	pick := &PoolEntry{Campaigns: []CampaignRef{{ID: "c1"}}}
+	a.Observe([]twitch.DropCampaign{makeCampaign("c1", "d1", 0, 120, false)}, pick, notCompleted, now)
+
+	// One update a minute for an hour: a point every timelineStep.
+	for m := 1; m <= 60; m++ {
+		a.Progress("c1", "d1", "", "", m, 120, now.Add(time.Duration(m)*time.Minute))
+	}
+	got := a.Tracking()[0]
+	if want := 1 + 60/int(timelineStep/time.Minute); len(got.Timeline) != want {
+		t.Errorf("Timeline has %d points, want %d", len(got.Timeline), want)
+	}
+	if got.Minutes != 60 {
+		t.Errorf("Minutes = %d, want 60", got.Minutes)
+	}
+}
+
+func TestArchive_Persists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "drops_archive.json")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	pick := &PoolEntry{ChannelLogin: "streamer", Campaigns: []CampaignRef{{ID: "c1"}, {ID: "c2"}}}
+	c1 := makeCampaign("c1", "d1", 60, 60, true)
+	c2 := makeCampaign("c2", "d2", 5, 60, false)
+
+	a := NewArchive(path, nil)
+	a.Observe([]twitch.DropCampaign{c1, c2}, pick, notCompleted, now)
+
+	b := NewArchive(path, nil)
+	if got := b.Archived(); len(got) != 1 || got[0].CampaignID != "c1" {
+		t.Errorf("reloaded Archived() = %+v, want c1", got)
+	}
+	if got := b.Tracking(); len(got) != 1 || got[0].CampaignID != "c2" || got[0].Channels[0] != "streamer" {
+		t.Errorf("reloaded Tracking() = %+v, want c2 on streamer", got)
+	}
+}
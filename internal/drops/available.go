@@ -0,0 +1,113 @@
+package drops
+
+import (
+	"sort"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// AvailableCampaign is a campaign the account is eligible for but has no
+// progress on yet (not in Inventory). Served by /api/campaigns/available
+// so the user can opt in to it from the campaign browser. JSON tags are
+// part of the public API contract.
+type AvailableCampaign struct {
+	CampaignID         string    `json:"campaign_id"`
+	CampaignName       string    `json:"campaign_name"`
+	GameName           string    `json:"game_name"`
+	Status             string    `json:"status"` // ACTIVE / UPCOMING
+	StartAt            time.Time `json:"start_at"`
+	EndAt              time.Time `json:"end_at"`
+	IsAccountConnected bool      `json:"is_account_connected"`
+	// IsEarnable is false when the campaign needs a linked publisher
+	// account that isn't connected — opting in won't farm anything
+	// until the user links the account.
+	IsEarnable      bool     `json:"is_earnable"`
+	Drops           int      `json:"drops"`
+	RequiredMinutes int      `json:"required_minutes"` // summed across all drops
+	AllowedChannels int      `json:"allowed_channels"` // 0 = any drops-enabled channel
+	Rewards         []string `json:"rewards"`
+	IsOptedIn       bool     `json:"is_opted_in"`
+	IsDisabled      bool     `json:"is_disabled"`
+}
+
+// AvailableConfig is the slice of config behavior BuildAvailable depends
+// on. *config.Config satisfies this in production.
+type AvailableConfig interface {
+	IsCampaignDisabled(campaignID string) bool
+	IsCampaignOptedIn(campaignID string) bool
+}
+
+// BuildAvailable filters the merged Dashboard/Inventory campaign list
+// down to campaigns without prior progress (InInventory=false) that are
+// still running or upcoming, sorted soonest-ending first.
+func BuildAvailable(cfg AvailableConfig, campaigns []twitch.DropCampaign, now time.Time) []AvailableCampaign {
+	out := make([]AvailableCampaign, 0)
+	for _, c := range campaigns {
+		if c.InInventory {
+			continue
+		}
+		if c.Status != "" && c.Status != "ACTIVE" && c.Status != "UPCOMING" {
+			continue
+		}
+		if !c.EndAt.IsZero() && !c.EndAt.After(now) {
+			continue
+		}
+
+		row := AvailableCampaign{
+			CampaignID:         c.ID,
+			CampaignName:       c.Name,
+			GameName:           c.GameName,
+			Status:             c.Status,
+			StartAt:            c.StartAt,
+			EndAt:              c.EndAt,
+			IsAccountConnected: c.IsAccountConnected,
+			IsEarnable:         c.IsAccountConnected || hasBadgeOrEmoteBenefit(c),
+			Drops:              len(c.Drops),
+			AllowedChannels:    len(c.Channels),
+			Rewards:            []string{},
+			IsOptedIn:          cfg.IsCampaignOptedIn(c.ID),
+			IsDisabled:         cfg.IsCampaignDisabled(c.ID),
+		}
+		seenReward := make(map[string]bool, len(c.Drops))
+		for _, d := range c.Drops {
+			row.RequiredMinutes += d.RequiredMinutesWatched
+			name := d.BenefitName
+			if name == "" {
+				name = d.Name
+			}
+			if name != "" && !seenReward[name] {
+				seenReward[name] = true
+				row.Rewards = append(row.Rewards, name)
+			}
+		}
+		out = append(out, row)
+	}
+
+	sort.SliceStable(out, func(i, j int) bool {
+		ei, ej := out[i].EndAt, out[j].EndAt
+		if ei.IsZero() != ej.IsZero() {
+			return !ei.IsZero() // campaigns without an end go last
+		}
+		return ei.Before(ej)
+	})
+	return out
+}
+
+// GetAvailableCampaigns returns the campaign-browser view of the current
+// cycle's campaign cache. The cache is the merged Dashboard + Details +
+// Inventory result from GetDropsInventory, so no extra GQL round-trip
+// is needed.
+func (s *Service) GetAvailableCampaigns() []AvailableCampaign {
+	s.mu.RLock()
+	campaigns := make([]twitch.DropCampaign, 0, len(s.campaignCache))
+	for _, c := range s.campaignCache {
+		campaigns = append(campaigns, c)
+	}
+	s.mu.RUnlock()
+
+	// Map iteration order is random; pre-sort by ID so equal-EndAt
+	// rows keep a stable order between polls.
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].ID < campaigns[j].ID })
+	return BuildAvailable(s.cfg, campaigns, time.Now())
+}
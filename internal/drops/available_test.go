@@ -0,0 +1,44 @@
+package drops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func TestBuildAvailable_OnlyCampaignsWithoutProgress(t *testing.T) {
+	cfg := &config.Config{OptInCampaigns: []string{"camp-b"}}
+	campaigns := []twitch.DropCampaign{
+		{ID: "camp-inv", Status: "ACTIVE", InInventory: true, EndAt: testNow.Add(time.Hour)},
+		{ID: "camp-expired", Status: "ACTIVE", EndAt: testNow.Add(-time.Hour)},
+		{ID: "camp-dead", Status: "EXPIRED", EndAt: testNow.Add(time.Hour)},
+		{ID: "camp-b", Status: "ACTIVE", IsAccountConnected: true, EndAt: testNow.Add(3 * time.Hour),
+			Drops: []twitch.TimeBasedDrop{
+				{ID: "d1", RequiredMinutesWatched: 60, BenefitName: "Skin"},
+				{ID: "d2", RequiredMinutesWatched: 120, BenefitName: "Skin"},
+			}},
+		{ID: "camp-a", Status: "UPCOMING", EndAt: testNow.Add(2 * time.Hour),
+			Drops: []twitch.TimeBasedDrop{{ID: "d3", RequiredMinutesWatched: 30, BenefitType: "BADGE", BenefitName: "Badge"}}},
+		{ID: "camp-noacct", Status: "ACTIVE", EndAt: testNow.Add(4 * time.Hour),
+			Drops: []twitch.TimeBasedDrop{{ID: "d4", RequiredMinutesWatched: 30, BenefitType: "DIRECT_ENTITLEMENT"}}},
+	}
+
+	got := BuildAvailable(cfg, campaigns, testNow)
+	if len(got) != 3 {
+		t.Fatalf("want 3 available campaigns, got %d: %+v", len(got), got)
+	}
+	if got[0].CampaignID != "camp-a" || got[1].CampaignID != "camp-b" || got[2].CampaignID != "camp-noacct" {
+		t.Fatalf("want soonest-ending first, got %s, %s, %s", got[0].CampaignID, got[1].CampaignID, got[2].CampaignID)
+	}
+	if !got[0].IsEarnable {
+		t.Error("badge campaign should be earnable without a linked account")
+	}
+	if got[2].IsEarnable {
+		t.Error("entitlement campaign without linked account should not be earnable")
+	}
+	if !got[1].IsOptedIn || got[1].RequiredMinutes != 180 || len(got[1].Rewards) != 1 {
+		t.Errorf("camp-b row wrong: %+v", got[1])
+	}
+}
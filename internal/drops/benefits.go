@@ -0,0 +1,114 @@
+package drops
+
+import (
+	"sort"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// openBenefits returns the benefit IDs of a campaign's unclaimed timed
+// drops. ok is false when one of them has no benefit ID — such a
+// campaign can't be proven to duplicate another one.
+func openBenefits(c twitch.DropCampaign) (ids []string, ok bool) {
+	for _, d := range c.Drops {
+		if d.RequiredMinutesWatched <= 0 || d.IsClaimed {
+			continue
+		}
+		if d.BenefitID == "" {
+			return nil, false
+		}
+		ids = append(ids, d.BenefitID)
+	}
+	return ids, len(ids) > 0
+}
+
+// redundantCampaigns groups overlapping campaigns by the benefits they
+// still have to grant. A campaign whose every open benefit is also
+// granted by a preferred campaign is redundant: farming it would earn
+// the same rewards twice. Preference goes to more existing progress,
+// then the earlier end (so the one that expires first isn't starved),
+// then campaign ID for stable output.
+//
+// Returns redundant campaign ID → ID of the campaign that covers it
+// (the first preferred one granting its first open benefit).
+func redundantCampaigns(campaigns []twitch.DropCampaign) map[string]string {
+	order := make([]twitch.DropCampaign, len(campaigns))
+	copy(order, campaigns)
+	sort.SliceStable(order, func(i, j int) bool {
+		a, b := order[i], order[j]
+		if pa, pb := a.ProgressPercent(), b.ProgressPercent(); pa != pb {
+			return pa > pb
+		}
+		if !a.EndAt.Equal(b.EndAt) {
+			if a.EndAt.IsZero() || b.EndAt.IsZero() {
+				return b.EndAt.IsZero()
+			}
+			return a.EndAt.Before(b.EndAt)
+		}
+		return a.ID < b.ID
+	})
+
+	coveredBy := make(map[string]string) // benefit ID → campaign farming it
+	redundant := make(map[string]string)
+	for _, c := range order {
+		ids, ok := openBenefits(c)
+		if !ok {
+			continue
+		}
+		covered := true
+		for _, id := range ids {
+			if _, seen := coveredBy[id]; !seen {
+				covered = false
+				break
+			}
+		}
+		if covered {
+			redundant[c.ID] = coveredBy[ids[0]]
+			continue
+		}
+		for _, id := range ids {
+			if _, seen := coveredBy[id]; !seen {
+				coveredBy[id] = c.ID
+			}
+		}
+	}
+	return redundant
+}
+
+// dropRedundant removes redundant campaigns from the pool entries'
+// campaign lists and drops entries left with none. Only campaigns that
+// made it into the pool are compared, so a duplicate with no live
+// channel never shadows one that can actually be watched.
+func dropRedundant(pool []*PoolEntry, eligible []twitch.DropCampaign) ([]*PoolEntry, map[string]string) {
+	inPool := make(map[string]bool)
+	for _, e := range pool {
+		for _, ref := range e.Campaigns {
+			inPool[ref.ID] = true
+		}
+	}
+	var live []twitch.DropCampaign
+	for _, c := range eligible {
+		if inPool[c.ID] {
+			live = append(live, c)
+		}
+	}
+	redundant := redundantCampaigns(live)
+	if len(redundant) == 0 {
+		return pool, redundant
+	}
+
+	out := pool[:0]
+	for _, e := range pool {
+		refs := e.Campaigns[:0]
+		for _, ref := range e.Campaigns {
+			if _, skip := redundant[ref.ID]; !skip {
+				refs = append(refs, ref)
+			}
+		}
+		e.Campaigns = refs
+		if len(refs) > 0 {
+			out = append(out, e)
+		}
+	}
+	return out, redundant
+}
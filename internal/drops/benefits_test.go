@@ -0,0 +1,65 @@
+package drops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func TestRedundantCampaigns(t *testing.T) {
+	drop := func(benefit string, current int) twitch.TimeBasedDrop {
+		return twitch.TimeBasedDrop{ID: benefit + "-drop", BenefitID: benefit, RequiredMinutesWatched: 60, CurrentMinutesWatched: current}
+	}
+	camp := func(id string, endIn time.Duration, drops ...twitch.TimeBasedDrop) twitch.DropCampaign {
+		return twitch.DropCampaign{ID: id, EndAt: testNow.Add(endIn), Drops: drops}
+	}
+	got := redundantCampaigns([]twitch.DropCampaign{
+		camp("fresh-dup", 48*time.Hour, drop("skin", 0)),
+		camp("started", 72*time.Hour, drop("skin", 20), drop("badge", 0)),
+		camp("same-progress-late", 96*time.Hour, drop("hat", 0)),
+		camp("same-progress-early", 24*time.Hour, drop("hat", 0)),
+		// A benefit only this campaign grants keeps it in.
+		camp("partial-overlap", 48*time.Hour, drop("skin", 0), drop("emote", 0)),
+		// Unknown benefit: can't prove the duplicate.
+		camp("no-benefit-id", 48*time.Hour, drop("", 0)),
+	})
+
+	want := map[string]string{
+		"fresh-dup":          "started",
+		"same-progress-late": "same-progress-early",
+	}
+	if len(got) != len(want) {
+		t.Fatalf("redundant = %v, want %v", got, want)
+	}
+	for id, by := range want {
+		if got[id] != by {
+			t.Errorf("%s covered by %q, want %q", id, got[id], by)
+		}
+	}
+}
+
+func TestDropRedundant_OnlyLiveCampaignsCount(t *testing.T) {
+	benefit := []twitch.TimeBasedDrop{{ID: "d", BenefitID: "skin", RequiredMinutesWatched: 60}}
+	started := []twitch.TimeBasedDrop{{ID: "d2", BenefitID: "skin", RequiredMinutesWatched: 60, CurrentMinutesWatched: 30}}
+	eligible := []twitch.DropCampaign{
+		{ID: "a", Drops: benefit},
+		{ID: "b", Drops: started},
+	}
+
+	// "b" has more progress but no live channel: "a" must stay.
+	pool := []*PoolEntry{{ChannelID: "1", Campaigns: []CampaignRef{{ID: "a"}}}}
+	out, redundant := dropRedundant(pool, eligible)
+	if len(out) != 1 || len(redundant) != 0 {
+		t.Fatalf("pool %d, redundant %v; want a kept", len(out), redundant)
+	}
+
+	pool = []*PoolEntry{
+		{ChannelID: "1", Campaigns: []CampaignRef{{ID: "a"}}},
+		{ChannelID: "2", Campaigns: []CampaignRef{{ID: "b"}}},
+	}
+	out, redundant = dropRedundant(pool, eligible)
+	if len(out) != 1 || out[0].ChannelID != "2" || redundant["a"] != "b" {
+		t.Fatalf("pool %+v, redundant %v; want only b's channel", out, redundant)
+	}
+}
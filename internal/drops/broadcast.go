@@ -11,7 +11,7 @@ func (s *Service) SubscribeBroadcastSettings(channelID string) {
 	}
 	topic := fmt.Sprintf("broadcast-settings-update.%s", channelID)
 	if err := s.pubsub.Listen([]string{topic}); err != nil {
-		s.log("[PubSub] subscribe %s failed: %v", topic, err)
+		s.warn("[PubSub] subscribe %s failed: %v", topic, err)
 	}
 }
 
@@ -23,7 +23,7 @@ func (s *Service) UnsubscribeBroadcastSettings(channelID string) {
 	}
 	topic := fmt.Sprintf("broadcast-settings-update.%s", channelID)
 	if err := s.pubsub.Unlisten([]string{topic}); err != nil {
-		s.log("[PubSub] unsubscribe %s failed: %v", topic, err)
+		s.warn("[PubSub] unsubscribe %s failed: %v", topic, err)
 	}
 }
 
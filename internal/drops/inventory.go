@@ -12,6 +12,22 @@ type dropClaimer interface {
 	ClaimDrop(dropInstanceID string) error
 }
 
+// DropClaimed describes a drop we just claimed, or failed to claim
+// (ServiceDeps.OnDropClaimed).
+type DropClaimed struct {
+	Name     string `json:"name"`               // benefit name, else drop name
+	Campaign string `json:"campaign,omitempty"` // empty when the campaign isn't cached
+	Game     string `json:"game,omitempty"`
+	Error    string `json:"error,omitempty"` // the claim failed; retried on the next inventory check
+}
+
+// claimed reports a claim attempt to the OnDropClaimed hook, if any.
+func (s *Service) claimed(d DropClaimed) {
+	if s.onDropClaimed != nil {
+		s.onDropClaimed(d)
+	}
+}
+
 // AutoClaimAndMarkCompleted walks an inventory campaigns list and claims
 // every complete-but-unclaimed drop instance synchronously, mutating
 // the local IsClaimed flag in-place on success. When every watchable
@@ -89,10 +105,12 @@ func (s *Service) autoClaimWith(campaigns []twitch.DropCampaign, claimer dropCla
 					name = d.Name
 				}
 				if err := claimer.ClaimDrop(d.DropInstanceID); err != nil {
-					s.log("[Drops] Failed to claim %s: %v", name, err)
+					s.warn("[Drops] Failed to claim %s: %v", name, err)
+					s.claimed(DropClaimed{Name: name, Campaign: c.Name, Game: c.GameName, Error: err.Error()})
 					allClaimed = false
 				} else {
 					s.log("[Drops] Claimed: %s (%s)", name, c.Name)
+					s.claimed(DropClaimed{Name: name, Campaign: c.Name, Game: c.GameName})
 					// Mutate the slice's drop in-place so downstream
 					// stages (Selector, SnapshotPick) see the fresh
 					// claim without another inventory round-trip.
@@ -107,7 +125,7 @@ func (s *Service) autoClaimWith(campaigns []twitch.DropCampaign, claimer dropCla
 
 		if hasWatchable && allClaimed {
 			s.cfg.MarkCampaignCompleted(c.ID)
-			_ = s.cfg.Save()
+			s.cfg.SaveSoon()
 			s.log("[Drops] Campaign %q fully claimed — marked as completed", c.Name)
 		}
 	}
@@ -138,7 +156,7 @@ func (s *Service) MarkCompletedIfFinishedExternally(campaignID string) {
 			return
 		}
 		s.cfg.MarkCampaignCompleted(c.ID)
-		_ = s.cfg.Save()
+		s.cfg.SaveSoon()
 		s.log("[Drops] Campaign %q finished externally (poll: complete + not in inventory) — marked completed", c.Name)
 		return
 	}
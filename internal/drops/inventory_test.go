@@ -24,8 +24,9 @@ func (s *stubClaimer) ClaimDrop(dropInstanceID string) error {
 
 func newServiceForClaimTest(cfg *config.Config) *Service {
 	return &Service{
-		cfg: cfg,
-		log: func(string, ...interface{}) {},
+		cfg:  cfg,
+		log:  func(string, ...interface{}) {},
+		warn: func(string, ...interface{}) {},
 	}
 }
 
@@ -207,6 +208,19 @@ func TestClaimViaPubSub_DisabledSkipsClaim(t *testing.T) {
 	}
 }
 
+// TestClaimViaPubSub_PausedSkipsClaim: Farmer.Pause holds drop claims
+// back even with AutoClaim on; the inventory check after Resume claims.
+func TestClaimViaPubSub_PausedSkipsClaim(t *testing.T) {
+	cfg := &config.Config{AutoClaim: true}
+	s := newServiceForClaimTest(cfg)
+	s.isPaused = func() bool { return true }
+	claimer := &stubClaimer{}
+
+	if s.claimViaPubSub(claimer, "instance-paused") || len(claimer.calls) != 0 {
+		t.Fatalf("paused farmer must skip PubSub ClaimDrop, got %d calls", len(claimer.calls))
+	}
+}
+
 // TestClaimViaPubSub_EnabledClaims is the positive case — AutoClaim=true
 // must let the PubSub claim through unchanged.
 func TestClaimViaPubSub_EnabledClaims(t *testing.T) {
@@ -1,14 +1,31 @@
 package drops
 
-import "strings"
+import (
+	"strings"
 
-// PickGameMatches returns true if the freshly-fetched game equals (case-
-// insensitive) any of the pick's campaign games. Used as a guard before
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// sameGame reports whether two game references are the same game: by
+// Twitch game ID when both carry one (names drift — trademark signs,
+// editions, directory vs display name), else by cfg.GameKey.
+func sameGame(cfg *config.Config, aID, aName, bID, bName string) bool {
+	if aID != "" && bID != "" {
+		return aID == bID
+	}
+	if aName == "" || bName == "" {
+		return false
+	}
+	return cfg.GameKey(aName) == cfg.GameKey(bName)
+}
+
+// PickGameMatches returns true if the freshly-fetched game is the game
+// of any of the pick's campaigns (see sameGame). Used as a guard before
 // committing the watcher to a channel — streamer may have switched games
 // between selector run and now.
-func PickGameMatches(pick *PoolEntry, currentGame string) bool {
+func PickGameMatches(cfg *config.Config, pick *PoolEntry, gameID, gameName string) bool {
 	for _, c := range pick.Campaigns {
-		if strings.EqualFold(c.GameName, currentGame) {
+		if sameGame(cfg, c.GameID, c.GameName, gameID, gameName) {
 			return true
 		}
 	}
@@ -21,7 +38,7 @@ func PickCampaignGames(pick *PoolEntry) string {
 	seen := make(map[string]bool, len(pick.Campaigns))
 	out := make([]string, 0, len(pick.Campaigns))
 	for _, c := range pick.Campaigns {
-		key := strings.ToLower(c.GameName)
+		key := config.NormalizeGameName(c.GameName)
 		if seen[key] {
 			continue
 		}
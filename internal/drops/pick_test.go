@@ -1,6 +1,10 @@
 package drops
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/config"
+)
 
 // Pure-function unit tests for the applySelectorPick guards. Full integration
 // coverage of the Farmer state machine is deferred (would require a fake GQL
@@ -12,6 +16,7 @@ func TestPickGameMatches(t *testing.T) {
 	tests := []struct {
 		name        string
 		campaigns   []CampaignRef
+		currentID   string
 		currentGame string
 		want        bool
 	}{
@@ -48,6 +53,32 @@ func TestPickGameMatches(t *testing.T) {
 			currentGame: "",
 			want:        false,
 		},
+		{
+			name:        "trademark sign and punctuation ignored",
+			campaigns:   []CampaignRef{{GameName: "Tom Clancy's Rainbow Six Siege"}},
+			currentGame: "Tom Clancy's Rainbow Six® Siege",
+			want:        true,
+		},
+		{
+			name:        "alias maps an edition onto the campaign game",
+			campaigns:   []CampaignRef{{GameName: "Call of Duty: Warzone"}},
+			currentGame: "Call of Duty®: Warzone™ 2.0",
+			want:        true,
+		},
+		{
+			name:        "game ID wins over differing names",
+			campaigns:   []CampaignRef{{GameName: "Pokémon Legends", GameID: "123"}},
+			currentID:   "123",
+			currentGame: "Pokemon Legends",
+			want:        true,
+		},
+		{
+			name:        "different game IDs never match by name",
+			campaigns:   []CampaignRef{{GameName: "Rust", GameID: "263490"}},
+			currentID:   "999",
+			currentGame: "Rust",
+			want:        false,
+		},
 		{
 			name:        "no campaigns — no match",
 			campaigns:   nil,
@@ -55,10 +86,11 @@ func TestPickGameMatches(t *testing.T) {
 			want:        false,
 		},
 	}
+	cfg := &config.Config{GameAliases: map[string]string{"Call of Duty: Warzone 2.0": "Call of Duty: Warzone"}}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			pick := &PoolEntry{Campaigns: tc.campaigns}
-			if got := PickGameMatches(pick, tc.currentGame); got != tc.want {
+			if got := PickGameMatches(cfg, pick, tc.currentID, tc.currentGame); got != tc.want {
 				t.Fatalf("PickGameMatches(%q) = %v, want %v", tc.currentGame, got, tc.want)
 			}
 		})
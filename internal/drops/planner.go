@@ -0,0 +1,219 @@
+package drops
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// planAtRiskSlack flags a campaign that isn't being watched once less
+// than this much spare time is left between its remaining watch time
+// and its end — it has to be picked soon or it won't finish.
+const planAtRiskSlack = 2 * time.Hour
+
+// Plan verdicts.
+const (
+	PlanOnTrack    = "on_track"   // watched and finishes before EndAt, or plenty of slack
+	PlanAtRisk     = "at_risk"    // not watched and slack below planAtRiskSlack
+	PlanImpossible = "impossible" // remaining watch time exceeds the time left
+	PlanDone       = "done"       // nothing left to watch
+	// PlanNeedsPriority: the campaign fits before EndAt on its own but
+	// not behind the campaigns queued ahead of it — it has to be pinned
+	// or have its game moved up in wanted_games to finish.
+	PlanNeedsPriority = "needs_priority"
+)
+
+// PlanEntry is one campaign in the watch planner (/api/drops/plan).
+type PlanEntry struct {
+	CampaignID          string     `json:"campaign_id"`
+	CampaignName        string     `json:"campaign_name"`
+	GameName            string     `json:"game_name"`
+	Status              string     `json:"status"` // ActiveDrop.Status
+	ChannelLogin        string     `json:"channel_login,omitempty"`
+	IsWatching          bool       `json:"is_watching"`
+	RemainingMinutes    int        `json:"remaining_minutes"` // CampaignEtaMinutes
+	EndAt               time.Time  `json:"end_at"`
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"` // whole campaign; only while watching
+	// SlackMinutes is the time left until EndAt minus RemainingMinutes.
+	// Negative means the campaign can't be finished any more.
+	SlackMinutes int `json:"slack_minutes"`
+	// QueuePosition, ProjectedStart and ProjectedFinish come from the
+	// queue forecast (see forecastQueue); only set for ACTIVE and QUEUED
+	// campaigns. BlockedBy names the campaigns forecast to hold the
+	// watch slot before this one.
+	QueuePosition   int        `json:"queue_position,omitempty"`
+	ProjectedStart  *time.Time `json:"projected_start,omitempty"`
+	ProjectedFinish *time.Time `json:"projected_finish,omitempty"`
+	BlockedBy       []string   `json:"blocked_by,omitempty"`
+	Verdict         string     `json:"verdict"`
+	Warning         string     `json:"warning,omitempty"`
+}
+
+// queueForecast is one campaign's slot in the simulated watch queue.
+type queueForecast struct {
+	start, finish time.Time
+	blockedBy     []string
+}
+
+// forecastQueue simulates the drop queue from now: the Watcher holds a
+// single channel at a time, so the ACTIVE campaign runs first and each
+// QUEUED one starts when the one before it finishes or ends, in
+// QueueIndex order. IDLE campaigns have no live channel and aren't
+// scheduled. The result is keyed by index into rows.
+//
+// Two campaigns of the same game can accrue on one channel in parallel;
+// the forecast ignores that and errs on the late side.
+func forecastQueue(rows []ActiveDrop, now time.Time) map[int]queueForecast {
+	var order []int
+	for i, d := range rows {
+		if (d.Status == "ACTIVE" || d.Status == "QUEUED") && d.QueueIndex > 0 && d.CampaignEtaMinutes > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rows[order[a]].QueueIndex < rows[order[b]].QueueIndex })
+
+	out := make(map[int]queueForecast, len(order))
+	t := now
+	var ahead []string
+	for _, i := range order {
+		d := rows[i]
+		f := queueForecast{
+			start:     t,
+			finish:    t.Add(time.Duration(d.CampaignEtaMinutes) * time.Minute),
+			blockedBy: ahead,
+		}
+		out[i] = f
+		// The selector drops a campaign once it ends, so a late one only
+		// holds the slot until EndAt.
+		busyUntil := f.finish
+		if !d.EndAt.IsZero() && d.EndAt.Before(busyUntil) {
+			busyUntil = d.EndAt
+		}
+		if busyUntil.After(t) {
+			t = busyUntil
+			ahead = append(ahead[:len(ahead):len(ahead)], d.CampaignName)
+		}
+	}
+	return out
+}
+
+// queueVerdict downgrades a standalone verdict when the queue forecast
+// finishes the campaign after EndAt.
+func queueVerdict(d ActiveDrop, verdict string, f queueForecast, ok bool) string {
+	if !ok || d.EndAt.IsZero() || !f.finish.After(d.EndAt) {
+		return verdict
+	}
+	if verdict == PlanOnTrack || verdict == PlanAtRisk {
+		return PlanNeedsPriority
+	}
+	return verdict
+}
+
+// planVerdict computes a row's slack and verdict at now. Progress runs
+// at one minute per minute while watched and not at all otherwise.
+func planVerdict(d ActiveDrop, watching bool, now time.Time) (slack int, verdict string) {
+	if d.CampaignEtaMinutes <= 0 {
+		return 0, PlanDone
+	}
+	if d.EndAt.IsZero() {
+		return 0, PlanOnTrack
+	}
+	slack = int(d.EndAt.Sub(now)/time.Minute) - d.CampaignEtaMinutes
+	switch {
+	case slack < 0:
+		return slack, PlanImpossible
+	case !watching && time.Duration(slack)*time.Minute < planAtRiskSlack:
+		return slack, PlanAtRisk
+	}
+	return slack, PlanOnTrack
+}
+
+// annotatePlan stamps the clock-dependent planner fields onto rows.
+func annotatePlan(rows []ActiveDrop, now time.Time) {
+	forecast := forecastQueue(rows, now)
+	for i := range rows {
+		d := &rows[i]
+		d.IsWatching = d.Status == "ACTIVE" && d.ChannelLogin != ""
+		d.EstimatedCompletion = nil
+		if d.IsWatching && d.EtaMinutes > 0 {
+			t := now.Add(time.Duration(d.EtaMinutes) * time.Minute)
+			d.EstimatedCompletion = &t
+		}
+		if d.Status == "BELOW_MIN" || d.Status == "REDUNDANT" {
+			d.AtRisk, d.NeedsPriority = false, false
+			continue
+		}
+		_, verdict := planVerdict(*d, d.IsWatching, now)
+		f, ok := forecast[i]
+		verdict = queueVerdict(*d, verdict, f, ok)
+		d.AtRisk = verdict == PlanAtRisk || verdict == PlanImpossible || verdict == PlanNeedsPriority
+		d.NeedsPriority = verdict == PlanNeedsPriority
+	}
+}
+
+// Plan builds the watch planner from the drops rows: every farmable
+// campaign (disabled, completed, below-threshold and redundant ones are
+// left out) with its remaining watch time, deadline slack and a warning
+// when it can't be finished before EndAt at the current pace, or can't
+// be finished behind the campaigns queued ahead of it
+// (PlanNeedsPriority).
+func Plan(rows []ActiveDrop, now time.Time) []PlanEntry {
+	forecast := forecastQueue(rows, now)
+	out := make([]PlanEntry, 0, len(rows))
+	for i, d := range rows {
+		if d.Status == "DISABLED" || d.Status == "COMPLETED" || d.Status == "BELOW_MIN" || d.Status == "REDUNDANT" {
+			continue
+		}
+		watching := d.Status == "ACTIVE" && d.ChannelLogin != ""
+		slack, verdict := planVerdict(d, watching, now)
+		f, queued := forecast[i]
+		verdict = queueVerdict(d, verdict, f, queued)
+		e := PlanEntry{
+			CampaignID:       d.CampaignID,
+			CampaignName:     d.CampaignName,
+			GameName:         d.GameName,
+			Status:           d.Status,
+			ChannelLogin:     d.ChannelLogin,
+			IsWatching:       watching,
+			RemainingMinutes: d.CampaignEtaMinutes,
+			EndAt:            d.EndAt,
+			SlackMinutes:     slack,
+			Verdict:          verdict,
+		}
+		if watching && d.CampaignEtaMinutes > 0 {
+			t := now.Add(time.Duration(d.CampaignEtaMinutes) * time.Minute)
+			e.EstimatedCompletion = &t
+		}
+		if queued {
+			start, finish := f.start, f.finish
+			e.QueuePosition = d.QueueIndex
+			e.ProjectedStart = &start
+			e.ProjectedFinish = &finish
+			e.BlockedBy = f.blockedBy
+		}
+		switch verdict {
+		case PlanImpossible:
+			e.Warning = fmt.Sprintf("needs %s more watch time but ends in %s",
+				planDuration(d.CampaignEtaMinutes), planDuration(d.CampaignEtaMinutes+slack))
+		case PlanAtRisk:
+			e.Warning = fmt.Sprintf("not being watched — must start within %s to finish", planDuration(slack))
+		case PlanNeedsPriority:
+			e.Warning = fmt.Sprintf("misses its end by %s behind %s — pin it or move %s up in wanted_games",
+				planDuration(int(f.finish.Sub(d.EndAt)/time.Minute)), strings.Join(f.blockedBy, ", "), d.GameName)
+		}
+		out = append(out, e)
+	}
+	return out
+}
+
+// planDuration renders minutes as "45m" / "3h05m".
+func planDuration(minutes int) string {
+	if minutes < 0 {
+		minutes = 0
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
+	}
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
@@ -0,0 +1,122 @@
+package drops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func TestCampaignToRow_CampaignEtaIsLongestDrop(t *testing.T) {
+	c := twitch.DropCampaign{
+		ID: "c1",
+		Drops: []twitch.TimeBasedDrop{
+			{Name: "claimed", RequiredMinutesWatched: 30, CurrentMinutesWatched: 30, IsClaimed: true},
+			{Name: "first", RequiredMinutesWatched: 60, CurrentMinutesWatched: 20},
+			{Name: "long", RequiredMinutesWatched: 240, CurrentMinutesWatched: 20},
+		},
+	}
+	row := campaignToRow(c, "")
+	if row.EtaMinutes != 40 || row.CampaignEtaMinutes != 220 {
+		t.Fatalf("eta=%d campaign=%d, want 40 / 220", row.EtaMinutes, row.CampaignEtaMinutes)
+	}
+
+	// A progress event moves both together.
+	row.Progress = 50
+	row.recomputeDerived()
+	if row.EtaMinutes != 10 || row.CampaignEtaMinutes != 190 {
+		t.Fatalf("after progress: eta=%d campaign=%d, want 10 / 190", row.EtaMinutes, row.CampaignEtaMinutes)
+	}
+}
+
+func TestPlan_Verdicts(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := func(id, status, channel string, remaining int, endIn time.Duration) ActiveDrop {
+		d := ActiveDrop{CampaignID: id, Status: status, ChannelLogin: channel, Required: remaining}
+		d.recomputeDerived()
+		d.EndAt = now.Add(endIn)
+		return d
+	}
+	rows := []ActiveDrop{
+		row("watched", "ACTIVE", "chan", 120, 3*time.Hour),
+		row("late", "ACTIVE", "chan", 240, 3*time.Hour),
+		row("queued-tight", "QUEUED", "", 120, 3*time.Hour),
+		row("queued-easy", "QUEUED", "", 60, 24*time.Hour),
+		row("disabled", "DISABLED", "", 999, time.Hour),
+	}
+
+	got := map[string]PlanEntry{}
+	for _, e := range Plan(rows, now) {
+		got[e.CampaignID] = e
+	}
+	if _, ok := got["disabled"]; ok {
+		t.Error("disabled campaigns should be left out of the plan")
+	}
+
+	checks := map[string]string{
+		"watched":      PlanOnTrack,
+		"late":         PlanImpossible,
+		"queued-tight": PlanAtRisk,
+		"queued-easy":  PlanOnTrack,
+	}
+	for id, want := range checks {
+		if got[id].Verdict != want {
+			t.Errorf("%s: verdict %q (slack %d), want %q", id, got[id].Verdict, got[id].SlackMinutes, want)
+		}
+	}
+	if e := got["watched"]; e.EstimatedCompletion == nil || !e.EstimatedCompletion.Equal(now.Add(2*time.Hour)) {
+		t.Errorf("watched: completion %v, want now+2h", e.EstimatedCompletion)
+	}
+	if got["late"].Warning == "" || got["queued-tight"].Warning == "" {
+		t.Error("impossible and at-risk campaigns should carry a warning")
+	}
+
+	annotatePlan(rows, now)
+	if !rows[0].IsWatching || rows[0].AtRisk || rows[0].EstimatedCompletion == nil {
+		t.Errorf("watched row: %+v", rows[0])
+	}
+	if !rows[1].AtRisk || !rows[2].AtRisk || rows[3].AtRisk {
+		t.Error("AtRisk should follow the plan verdict")
+	}
+}
+
+func TestPlan_QueueForecast(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	row := func(id, status string, queue, remaining int, endIn time.Duration) ActiveDrop {
+		d := ActiveDrop{CampaignID: id, CampaignName: id, GameName: "Rust", Status: status, QueueIndex: queue, Required: remaining}
+		if status == "ACTIVE" {
+			d.ChannelLogin = "chan"
+		}
+		d.recomputeDerived()
+		d.EndAt = now.Add(endIn)
+		return d
+	}
+	rows := []ActiveDrop{
+		row("active", "ACTIVE", 1, 180, 24*time.Hour),
+		row("second", "QUEUED", 2, 120, 24*time.Hour),
+		// Fits on its own (4h left, 3h needed) but not behind 5h of queue.
+		row("squeezed", "QUEUED", 3, 180, 4*time.Hour),
+		row("idle", "IDLE", 4, 60, 2*time.Hour),
+	}
+
+	got := map[string]PlanEntry{}
+	for _, e := range Plan(rows, now) {
+		got[e.CampaignID] = e
+	}
+	if e := got["second"]; e.ProjectedStart == nil || !e.ProjectedStart.Equal(now.Add(3*time.Hour)) ||
+		!e.ProjectedFinish.Equal(now.Add(5*time.Hour)) || e.Verdict != PlanOnTrack {
+		t.Errorf("second: %+v", e)
+	}
+	e := got["squeezed"]
+	if e.Verdict != PlanNeedsPriority || e.Warning == "" || len(e.BlockedBy) != 2 {
+		t.Errorf("squeezed: verdict %q blocked by %v, want %q behind active, second", e.Verdict, e.BlockedBy, PlanNeedsPriority)
+	}
+	if got["idle"].ProjectedStart != nil {
+		t.Error("IDLE campaigns have no live channel and shouldn't be scheduled")
+	}
+
+	annotatePlan(rows, now)
+	if !rows[2].NeedsPriority || !rows[2].AtRisk || rows[1].NeedsPriority {
+		t.Errorf("annotatePlan: squeezed %+v", rows[2])
+	}
+}
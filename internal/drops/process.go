@@ -6,36 +6,150 @@ import (
 	"strings"
 	"time"
 
+	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
+const (
+	// checkInterval is the default CheckLoop cadence
+	// (drop_check_minutes overrides it). v1.8.0 reduced it from 5 to 15
+	// min because user-drop-events PubSub now delivers progress in
+	// real-time.
+	checkInterval = 15 * time.Minute
+	// minCheckInterval is the shortest adaptive interval, used while
+	// the watched drop is about to complete.
+	minCheckInterval = 2 * time.Minute
+	// idleCheckFactor stretches the interval while no campaign is being
+	// farmed, up to maxIdleCheckInterval.
+	idleCheckFactor      = 4
+	maxIdleCheckInterval = time.Hour
+	// firstCheckDelay gives channels time to initialize before the
+	// first check after startup.
+	firstCheckDelay = 30 * time.Second
+)
+
 // CheckLoop polls the drops inventory periodically as a safety net for
-// missed WebSocket events. v1.8.0 reduced the cadence from 5 to 15 min
-// because user-drop-events PubSub now delivers progress in real-time.
+// missed WebSocket events. The interval adapts after every cycle (see
+// nextCheckInterval). CheckNow runs the check early and restarts the
+// interval.
 //
 // Pass the farmer's stop channel so the loop exits at shutdown.
 func (s *Service) CheckLoop(stopCh <-chan struct{}) {
-	// Initial check shortly after startup (give channels time to initialize).
-	timer := time.NewTimer(30 * time.Second)
-	select {
-	case <-timer.C:
-		s.ProcessDrops()
-	case <-stopCh:
-		timer.Stop()
-		return
-	}
-
-	ticker := time.NewTicker(15 * time.Minute) // v1.8.0: WebSocket carries the real-time load
-	defer ticker.Stop()
+	timer := time.NewTimer(firstCheckDelay)
+	defer timer.Stop()
+	s.setNextCheck(time.Now().Add(firstCheckDelay))
+	defer s.setNextCheck(time.Time{})
 
+	last := time.Now()
 	for {
 		select {
-		case <-ticker.C:
-			s.ProcessDrops()
+		case <-timer.C:
+		case <-s.checkNow:
+		case <-s.replan:
+			// A cycle finished (ours or another trigger's): re-time
+			// the next check from when the last one ran.
+			next := last.Add(s.nextCheckInterval())
+			if wait := time.Until(next); wait > 0 {
+				timer.Reset(wait)
+				s.setNextCheck(next)
+				continue
+			}
+			// Overdue under the new interval: check now.
 		case <-stopCh:
 			return
 		}
+		last = time.Now()
+		iv := s.nextCheckInterval()
+		timer.Reset(iv)
+		s.setNextCheck(last.Add(iv))
+		s.ProcessDrops()
+	}
+}
+
+// CheckInterval returns the base inventory check interval the adaptive
+// one starts from: drop_check_minutes, or checkInterval when unset.
+func (s *Service) CheckInterval() time.Duration {
+	if iv := s.cfg.GetDropCheckInterval(); iv > 0 {
+		return iv
+	}
+	return checkInterval
+}
+
+// nextCheckInterval returns the wait before the next inventory check,
+// from the configured base interval and the last cycle's rows:
+//   - while a watched drop completes within the base interval, the
+//     check lands a minute after it would complete (at least
+//     minCheckInterval), so it is claimed promptly even if PubSub
+//     misses the event;
+//   - while no campaign is being farmed, the base interval times
+//     idleCheckFactor (at most maxIdleCheckInterval, never below the
+//     base), since the check only looks for new campaigns then;
+//   - otherwise, and before the first cycle, the base interval.
+func (s *Service) nextCheckInterval() time.Duration {
+	base := s.CheckInterval()
+
+	s.mu.RLock()
+	cycled := s.campaignCache != nil
+	soonest, farming := 0, false
+	for _, d := range s.activeDrops {
+		if d.Status != "ACTIVE" || d.ChannelLogin == "" {
+			continue
+		}
+		farming = true
+		if d.EtaMinutes > 0 && (soonest == 0 || d.EtaMinutes < soonest) {
+			soonest = d.EtaMinutes
+		}
+	}
+	s.mu.RUnlock()
+
+	switch {
+	case !cycled:
+		return base
+	case !farming:
+		idle := base * idleCheckFactor
+		if idle > maxIdleCheckInterval {
+			idle = max(maxIdleCheckInterval, base)
+		}
+		return idle
+	case soonest > 0:
+		if eta := time.Duration(soonest+1) * time.Minute; eta < base {
+			return max(eta, minCheckInterval)
+		}
 	}
+	return base
+}
+
+// CheckNow makes CheckLoop run the drops check right away instead of
+// at the next tick. Unlike a bare ProcessDrops it also restarts the
+// 15-minute countdown.
+func (s *Service) CheckNow() {
+	select {
+	case s.checkNow <- struct{}{}:
+	default:
+	}
+}
+
+// Replan makes CheckLoop re-time its next check, e.g. after
+// drop_check_minutes changed.
+func (s *Service) Replan() {
+	select {
+	case s.replan <- struct{}{}:
+	default:
+	}
+}
+
+// NextCheck returns when CheckLoop runs the next drops check (zero when
+// the loop isn't running, e.g. drops disabled).
+func (s *Service) NextCheck() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nextCheck
+}
+
+func (s *Service) setNextCheck(t time.Time) {
+	s.mu.Lock()
+	s.nextCheck = t
+	s.mu.Unlock()
 }
 
 // ProcessDrops kicks an inventory→selector→apply→commit cycle.
@@ -45,7 +159,7 @@ func (s *Service) CheckLoop(stopCh <-chan struct{}) {
 // run all want "another pass with fresh data" — exactly one is
 // enough).
 //
-// Trigger sources: 15-min CheckLoop ticker, 60s PollProgressOnce
+// Trigger sources: 15-min CheckLoop timer (or CheckNow), 60s PollProgressOnce
 // silent-pick path, HandleDropClaim, HandleGameChange (after the
 // 30s debounce), EventStreamDown when the picked drop channel
 // goes offline, and SetCampaignEnabled from TUI/Web toggles.
@@ -85,9 +199,14 @@ func (s *Service) ProcessLoop(stopCh <-chan struct{}) {
 // currentPickID without an outer process-lock (the per-field
 // s.mu still serializes UI reads vs commit writes).
 func (s *Service) processOnce() {
+	// Paused: no pick, no claims. Resume kicks a fresh cycle.
+	if s.farmingPaused() {
+		return
+	}
+
 	campaigns, err := s.gql.GetDropsInventory()
 	if err != nil {
-		s.log("[Drops] Failed to fetch inventory: %v", err)
+		s.warn("[Drops] Failed to fetch inventory: %v", err)
 		return
 	}
 
@@ -99,9 +218,10 @@ func (s *Service) processOnce() {
 	s.AutoClaimAndMarkCompleted(campaigns)
 
 	// 2a. Compare the previous pick's drop progress against this cycle's
-	//     inventory. If Twitch did not credit any new minutes, put the
-	//     channel into stall cooldown so the selector skips it next time.
-	s.Stall.Apply(campaigns)
+	//     inventory. If Twitch credited no new minutes for StallCycles
+	//     cycles, the channel goes into stall cooldown and the selection
+	//     below fails over to another one.
+	stalled := s.Stall.Apply(campaigns)
 
 	// 2b–2d. Select + apply with bounded retry. ApplyPick can reject the
 	//        pick for recoverable reasons (game-mismatch, id-mismatch)
@@ -131,7 +251,7 @@ func (s *Service) processOnce() {
 				attempt+1, maxApplyRetries)
 			continue
 		case ApplyBail:
-			s.log("[Drops/Pool] skipping commit — pick refresh failed, previous state preserved")
+			s.warn("[Drops/Pool] skipping commit — pick refresh failed, previous state preserved")
 			return
 		}
 		break
@@ -144,7 +264,7 @@ func (s *Service) processOnce() {
 	}
 
 	// 3. Build per-campaign UI rows from the FINAL committed pick.
-	active, queued, idle := BuildRows(s.cfg, campaigns, pick, pool)
+	active, queued, idle := BuildRows(s.cfg, campaigns, pick, pool, s.Selector.Redundant())
 
 	// 4. Rebuild campaign cache (for web UI endAt lookups).
 	newCache := make(map[string]twitch.DropCampaign, len(campaigns))
@@ -168,12 +288,23 @@ func (s *Service) processOnce() {
 	// 8. Drop existing temp channels that are no longer the pick.
 	s.CleanupNonPickedTemps(pick)
 
+	// 8b. Record this cycle's progress; finished campaigns move to the
+	//     completed-campaigns archive.
+	s.archive.Observe(campaigns, pick, s.cfg.IsCampaignCompleted, time.Now())
+
 	// 9. Trigger the points-side Spade rotation so slot 1 reflects the
 	//    new pick (rotation lives in farmer; Service can't reach in).
 	if s.triggerRotation != nil {
 		s.triggerRotation()
 	}
 
+	switch {
+	case stalled == "":
+	case pick == nil:
+		s.log("[Drops] No other channel to switch to after the stall — drops idle until one goes live")
+	case pick.ChannelID != stalled:
+		s.log("[Drops] Switched the drop channel to %s after the stall", pick.DisplayName)
+	}
 	if pick != nil {
 		campaignNames := make([]string, len(pick.Campaigns))
 		for i, c := range pick.Campaigns {
@@ -183,14 +314,17 @@ func (s *Service) processOnce() {
 	} else {
 		fs := s.Selector.LastFilterStats()
 		s.log("[Drops/Pool] empty pool — drops idle, slots free for points "+
-			"(filter: total=%d status=%d expired=%d not_in_wanted=%d not_connected=%d disabled=%d completed=%d no_earnable=%d eligible=%d | poolSize=%d)",
-			fs.Total, fs.StatusRejected, fs.Expired, fs.NotInWanted, fs.NotConnected, fs.Disabled, fs.Completed, fs.NoEarnableDrops, fs.Eligible,
+			"(filter: total=%d status=%d expired=%d not_in_wanted=%d game_filtered=%d not_connected=%d disabled=%d completed=%d no_earnable=%d below_min=%d eligible=%d | poolSize=%d)",
+			fs.Total, fs.StatusRejected, fs.Expired, fs.NotInWanted, fs.GameFiltered, fs.NotConnected, fs.Disabled, fs.Completed, fs.NoEarnableDrops, fs.BelowMinProgress, fs.Eligible,
 			s.Selector.LastPoolSize())
 	}
 
 	// 10. Snapshot the picked channel's current drop progress so the
 	//     next cycle can detect whether Twitch credited any minutes.
 	s.Stall.SnapshotPick(pick, campaigns)
+
+	// 11. Let CheckLoop re-time the next check from the new rows.
+	s.Replan()
 }
 
 // GetActiveDrops returns a single concatenated slice of UI rows in
@@ -208,6 +342,7 @@ func (s *Service) GetActiveDrops() []ActiveDrop {
 	out = append(out, s.activeDrops...)
 	out = append(out, s.queuedDrops...)
 	out = append(out, s.idleDrops...)
+	annotatePlan(out, time.Now())
 	return out
 }
 
@@ -226,7 +361,7 @@ func (s *Service) GetEligibleGames() []string {
 		if c.GameName == "" {
 			continue
 		}
-		key := strings.ToLower(c.GameName)
+		key := config.NormalizeGameName(c.GameName)
 		if seen[key] {
 			continue
 		}
@@ -0,0 +1,44 @@
+package drops
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func TestNextCheckInterval(t *testing.T) {
+	watched := func(eta int) ActiveDrop {
+		return ActiveDrop{Status: "ACTIVE", ChannelLogin: "streamer", EtaMinutes: eta}
+	}
+	cases := []struct {
+		name   string
+		base   int // drop_check_minutes
+		cycled bool
+		active []ActiveDrop
+		want   time.Duration
+	}{
+		{"before the first cycle", 0, false, nil, checkInterval},
+		{"nothing farmed", 0, true, []ActiveDrop{{Status: "DISABLED"}}, time.Hour},
+		{"nothing farmed, long base", 90, true, nil, 90 * time.Minute},
+		{"drop far off", 0, true, []ActiveDrop{watched(60)}, checkInterval},
+		{"drop near completion", 0, true, []ActiveDrop{watched(60), watched(7)}, 8 * time.Minute},
+		{"drop about to complete", 0, true, []ActiveDrop{watched(0), watched(1)}, minCheckInterval},
+		{"configured base", 5, true, []ActiveDrop{watched(30)}, 5 * time.Minute},
+		{"active without a channel", 0, true, []ActiveDrop{{Status: "ACTIVE", EtaMinutes: 3}}, time.Hour},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.SetDropCheckMinutes(tc.base)
+			s := &Service{cfg: cfg, activeDrops: tc.active}
+			if tc.cycled {
+				s.campaignCache = map[string]twitch.DropCampaign{}
+			}
+			if got := s.nextCheckInterval(); got != tc.want {
+				t.Errorf("nextCheckInterval() = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
@@ -129,19 +129,49 @@ func (s *Service) PollProgressOnce() {
 }
 
 // claimViaPubSub performs the actual ClaimDrop call for a PubSub-driven
-// claim event, gated by the AutoClaim config flag. Extracted so unit
-// tests can swap the claimer for a stub and verify the gate without
-// having to drive the full HandleDropClaim flow (sleeps + polling).
-func (s *Service) claimViaPubSub(claimer dropClaimer, instanceID string) {
+// claim event, gated by Farmer.Pause and the AutoClaim config flag, and
+// reports whether the drop was claimed. Extracted so unit tests can swap
+// the claimer for a stub and verify the gate without having to drive the
+// full HandleDropClaim flow (sleeps + polling).
+func (s *Service) claimViaPubSub(claimer dropClaimer, instanceID string) bool {
+	if s.farmingPaused() {
+		s.log("[Drops/WS] Farming paused — leaving instance %s for the inventory check after resume", instanceID)
+		return false
+	}
 	if !s.cfg.GetAutoClaim() {
 		s.log("[Drops/WS] AutoClaim disabled — skipping claim for instance %s (claim manually via Twitch)", instanceID)
-		return
+		return false
 	}
 	if err := claimer.ClaimDrop(instanceID); err != nil {
-		s.log("[Drops/WS] Failed to claim drop: %v", err)
-		return
+		s.warn("[Drops/WS] Failed to claim drop: %v", err)
+		d := s.describeDrop(instanceID)
+		d.Error = err.Error()
+		s.claimed(d)
+		return false
 	}
 	s.log("[Drops/WS] Claimed drop instance %s", instanceID)
+	return true
+}
+
+// describeDrop names a drop, by drop or instance ID, from the cached
+// inventory for DropClaimed. Unknown drops (cache not built yet) fall
+// back to the ID.
+func (s *Service) describeDrop(dropID string) DropClaimed {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, c := range s.campaignCache {
+		for _, d := range c.Drops {
+			if d.ID != dropID && (d.DropInstanceID == "" || d.DropInstanceID != dropID) {
+				continue
+			}
+			name := d.BenefitName
+			if name == "" {
+				name = d.Name
+			}
+			return DropClaimed{Name: name, Campaign: c.Name, Game: c.GameName}
+		}
+	}
+	return DropClaimed{Name: dropID}
 }
 
 // HandleDropClaim is the sequential, TDM-aligned drop-claim flow. It:
@@ -160,8 +190,8 @@ func (s *Service) claimViaPubSub(claimer dropClaimer, instanceID string) {
 // session progression is still observed correctly. The claimable drop
 // sits in inventory until the user claims it manually via Twitch.tv.
 func (s *Service) HandleDropClaim(data twitch.DropClaimData) {
-	if data.DropInstanceID != "" {
-		s.claimViaPubSub(s.gql, data.DropInstanceID)
+	if data.DropInstanceID != "" && s.claimViaPubSub(s.gql, data.DropInstanceID) {
+		s.claimed(s.describeDrop(data.DropID))
 	}
 
 	// Wait for Twitch to advance the session.
@@ -284,9 +314,11 @@ func (s *Service) ApplyProgressUpdate(data twitch.DropProgressData) {
 		s.mu.RLock()
 		pickedCh := s.currentPickID
 		s.mu.RUnlock()
+		login := ""
 		if pickedCh != "" {
 			if ch, ok := s.channels.Get(pickedCh); ok {
 				snap := ch.Snapshot()
+				login = snap.Login
 				if snap.HasActiveDrop {
 					if nextName == "" {
 						nextName = snap.DropName // fall back if cache miss
@@ -298,6 +330,7 @@ func (s *Service) ApplyProgressUpdate(data twitch.DropProgressData) {
 				}
 			}
 		}
+		s.archive.Progress(data.CampaignID, data.DropID, nextName, login, data.CurrentMinutesWatched, nextRequired, time.Now())
 	}
 }
 
@@ -1,7 +1,6 @@
 package drops
 
 import (
-	"strings"
 	"time"
 
 	"github.com/miwi/twitchpoint/internal/twitch"
@@ -31,10 +30,33 @@ type ActiveDrop struct {
 	// is non-empty (with an empty list, ALL eligible campaigns are
 	// auto-discovered and the marker would be noise).
 	IsAutoDiscovered bool `json:"is_auto_discovered"`
-	Status             string    `json:"status"`               // ACTIVE / QUEUED / IDLE / DISABLED / COMPLETED
+	// IsOptedIn marks campaigns the user enabled from the campaign
+	// browser (config opt_in_campaigns).
+	IsOptedIn bool `json:"is_opted_in"`
+	// AutoSelect is the campaign's auto-select override ("off",
+	// "allowed", "directory"); empty when it follows drop_auto_select.
+	AutoSelect string `json:"auto_select,omitempty"`
+	Status             string    `json:"status"`               // ACTIVE / QUEUED / IDLE / BELOW_MIN / REDUNDANT / DISABLED / COMPLETED
 	IsPinned           bool      `json:"is_pinned"`
+	// RedundantWith names the campaign farmed instead of this one when
+	// Status is REDUNDANT (it grants the same benefits).
+	RedundantWith string `json:"redundant_with,omitempty"`
 	QueueIndex         int       `json:"queue_index"`          // 1-based for ACTIVE/QUEUED/IDLE; 0 otherwise
 	EtaMinutes         int       `json:"eta_minutes"`          // RequiredMinutesWatched - CurrentMinutesWatched of next-to-claim drop
+	// CampaignEtaMinutes is the watch time left until every unclaimed
+	// drop of the campaign is done. Drops accrue in parallel, so this is
+	// the longest remaining one (a lower bound for chained drops).
+	CampaignEtaMinutes int `json:"campaign_eta_minutes"`
+	// IsWatching, EstimatedCompletion, AtRisk and NeedsPriority are stamped at read
+	// time by annotatePlan — they depend on the clock.
+	IsWatching          bool       `json:"is_watching"`                    // ACTIVE with a channel: progress accrues 1 min/min
+	EstimatedCompletion *time.Time `json:"estimated_completion,omitempty"` // next drop done; only while watching
+	AtRisk              bool       `json:"at_risk"`                        // see Plan: can't (or may not) finish before EndAt
+	NeedsPriority       bool       `json:"needs_priority"`                 // finishes alone, not behind the queue ahead of it
+
+	// extraMinutes is CampaignEtaMinutes beyond the current drop's ETA,
+	// captured at build time so progress events can keep both in step.
+	extraMinutes int
 }
 
 // RowsConfig is the slice of config behavior BuildRows depends on.
@@ -43,13 +65,22 @@ type RowsConfig interface {
 	GetPinnedCampaign() string
 	IsCampaignDisabled(campaignID string) bool
 	IsCampaignCompleted(campaignID string) bool
+	IsCampaignOptedIn(campaignID string) bool
+	GetCampaignAutoSelectOverride(campaignID string) string
 	GetGamesToWatch() []string
+	GameKey(name string) string
+	GetDropMinProgressPercent() int
+	IsDropGameDenied(gameName string) bool
+	IsDropGameAllowed(gameName string) bool
 }
 
 // BuildRows produces the per-campaign UI rows for the web API. It
 // classifies each campaign as ACTIVE (matches the current pick), QUEUED
 // (in the selector pool but not picked), IDLE (no live channels right
-// now), DISABLED (user-disabled), or COMPLETED (config flag set).
+// now), BELOW_MIN (under drop_min_progress_percent, so the selector
+// skips it), REDUNDANT (another pooled campaign grants the same
+// benefits; redundant maps it to that campaign's ID), DISABLED
+// (user-disabled), or COMPLETED (config flag set).
 //
 // Sub-only-deduped campaigns (no watchable drops) are silently skipped
 // unless the user explicitly disabled or completed them — keeping them
@@ -59,17 +90,19 @@ func BuildRows(
 	campaigns []twitch.DropCampaign,
 	pick *PoolEntry,
 	pool []*PoolEntry,
+	redundant map[string]string,
 ) (active, queued, idle []ActiveDrop) {
 	pinnedID := cfg.GetPinnedCampaign()
+	minPct := cfg.GetDropMinProgressPercent()
 
-	// Build a lower-cased lookup of wanted-games. Auto-discovered marker
+	// Build a GameKey lookup of wanted-games. Auto-discovered marker
 	// is meaningful ONLY when this list is non-empty — when it's empty,
 	// EVERY eligible campaign is auto-discovered and tagging them all
 	// would be noise.
 	wanted := cfg.GetGamesToWatch()
 	wantedSet := make(map[string]bool, len(wanted))
 	for _, g := range wanted {
-		wantedSet[strings.ToLower(strings.TrimSpace(g))] = true
+		wantedSet[cfg.GameKey(g)] = true
 	}
 	useAutoMarker := len(wantedSet) > 0
 
@@ -89,6 +122,11 @@ func BuildRows(
 		}
 	}
 
+	campaignNames := make(map[string]string, len(campaigns))
+	for _, c := range campaigns {
+		campaignNames[c.ID] = c.Name
+	}
+
 	queueIdx := 1
 	seenWatchableNames := make(map[string]bool) // dedup sub-only-deduped campaign noise (e.g. 9× "S5 Support ABI Partners")
 	for _, c := range campaigns {
@@ -102,7 +140,12 @@ func BuildRows(
 		// When the user has explicit priority games set, the UI shouldn't
 		// surface campaigns from other games (they're not farmable anyway
 		// per the strict filter, so listing them is noise).
-		if useAutoMarker && !wantedSet[strings.ToLower(strings.TrimSpace(c.GameName))] {
+		optedIn := cfg.IsCampaignOptedIn(c.ID)
+		if useAutoMarker && !wantedSet[cfg.GameKey(c.GameName)] && !optedIn {
+			continue
+		}
+		// drops_games_allow / drops_games_deny — same gate as the Selector.
+		if cfg.IsDropGameDenied(c.GameName) || (!cfg.IsDropGameAllowed(c.GameName) && !optedIn) {
 			continue
 		}
 
@@ -138,7 +181,9 @@ func BuildRows(
 		seenWatchableNames[c.Name] = true
 
 		row := campaignToRow(c, pinnedID)
-		if useAutoMarker && !wantedSet[strings.ToLower(strings.TrimSpace(c.GameName))] {
+		row.IsOptedIn = optedIn
+		row.AutoSelect = cfg.GetCampaignAutoSelectOverride(c.ID)
+		if useAutoMarker && !wantedSet[cfg.GameKey(c.GameName)] && !optedIn {
 			row.IsAutoDiscovered = true
 		}
 
@@ -162,6 +207,13 @@ func BuildRows(
 			row.QueueIndex = queueIdx
 			queueIdx++
 			queued = append(queued, row)
+		case redundant[c.ID] != "":
+			row.Status = "REDUNDANT"
+			row.RedundantWith = campaignNames[redundant[c.ID]]
+			idle = append(idle, row)
+		case minPct > 0 && c.ProgressPercent() < minPct && !optedIn:
+			row.Status = "BELOW_MIN"
+			idle = append(idle, row)
 		default:
 			row.Status = "IDLE"
 			idle = append(idle, row)
@@ -176,18 +228,25 @@ func BuildRows(
 // it decides the row's bucket.
 func campaignToRow(c twitch.DropCampaign, pinnedID string) ActiveDrop {
 	var dropName string
-	var progress, required int
+	var progress, required, longest int
+	found := false
 	for _, d := range c.Drops {
 		if d.RequiredMinutesWatched <= 0 || d.IsClaimed {
 			continue
 		}
+		if left := d.RequiredMinutesWatched - d.CurrentMinutesWatched; left > longest {
+			longest = left
+		}
+		if found {
+			continue
+		}
+		found = true
 		dropName = d.BenefitName
 		if dropName == "" {
 			dropName = d.Name
 		}
 		progress = d.CurrentMinutesWatched
 		required = d.RequiredMinutesWatched
-		break
 	}
 
 	row := ActiveDrop{
@@ -202,22 +261,27 @@ func campaignToRow(c twitch.DropCampaign, pinnedID string) ActiveDrop {
 		IsAccountConnected: c.IsAccountConnected,
 		IsPinned:           c.ID == pinnedID && pinnedID != "",
 	}
+	if eta := required - progress; longest > eta && eta >= 0 {
+		row.extraMinutes = longest - eta
+	}
 	row.recomputeDerived()
 	return row
 }
 
-// recomputeDerived recalculates Percent and EtaMinutes from Progress and
-// Required. Both are pure functions of those two fields, so storing them
-// independently invites divergence: a progress event that advances
-// Progress without recomputing Percent/EtaMinutes (e.g. when Required was
-// momentarily 0) leaves the row showing a stale "248/300min (88%)" where
-// the percentage no longer matches the minutes. Every writer of Progress
-// or Required MUST call this before the row is published so the three
-// fields can never disagree on screen.
+// recomputeDerived recalculates Percent, EtaMinutes and
+// CampaignEtaMinutes from Progress and Required (plus the campaign's
+// remaining minutes after this drop). They are pure functions of those
+// fields, so storing them independently invites divergence: a progress
+// event that advances Progress without recomputing Percent/EtaMinutes
+// (e.g. when Required was momentarily 0) leaves the row showing a stale
+// "248/300min (88%)" where the percentage no longer matches the
+// minutes. Every writer of Progress or Required MUST call this before
+// the row is published so the fields can never disagree on screen.
 func (d *ActiveDrop) recomputeDerived() {
 	if d.Required <= 0 {
 		d.Percent = 0
 		d.EtaMinutes = 0
+		d.CampaignEtaMinutes = d.extraMinutes
 		return
 	}
 	pct := (d.Progress * 100) / d.Required
@@ -230,4 +294,5 @@ func (d *ActiveDrop) recomputeDerived() {
 		eta = 0
 	}
 	d.EtaMinutes = eta
+	d.CampaignEtaMinutes = eta + d.extraMinutes
 }
@@ -60,6 +60,7 @@ func TestApplyProgressUpdate_KeepsPercentConsistent(t *testing.T) {
 	s := &Service{
 		cfg:           &config.Config{},
 		log:           func(string, ...interface{}) {},
+		warn:          func(string, ...interface{}) {},
 		writeLogFile:  func(string) {},
 		campaignCache: map[string]twitch.DropCampaign{},
 		activeDrops: []ActiveDrop{{
@@ -95,6 +96,7 @@ func TestApplyProgressUpdate_RequiredResolvedFromPayload(t *testing.T) {
 	s := &Service{
 		cfg:           &config.Config{},
 		log:           func(string, ...interface{}) {},
+		warn:          func(string, ...interface{}) {},
 		writeLogFile:  func(string) {},
 		campaignCache: map[string]twitch.DropCampaign{},
 		activeDrops: []ActiveDrop{{
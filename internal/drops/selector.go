@@ -16,6 +16,9 @@ type streamSource interface {
 	// Used for ACL campaigns: query the campaign's allowed_channels directly
 	// instead of relying on the (often too small) game-directory top 100.
 	GetChannelInfos(logins []string) []*twitch.ChannelInfo
+	// GetChannelDropCampaigns returns the campaign IDs each channel is
+	// running; channels whose lookup failed are absent.
+	GetChannelDropCampaigns(channelIDs []string) map[string][]string
 }
 
 // CampaignRef is a lightweight reference to a campaign that a pool entry serves.
@@ -23,6 +26,7 @@ type CampaignRef struct {
 	ID            string
 	Name          string
 	GameName      string
+	GameID        string
 	EndAt         time.Time
 	RemainingTime time.Duration
 	IsPinned      bool
@@ -47,15 +51,17 @@ type PoolEntry struct {
 // these numbers it's impossible to tell whether 0 candidates means
 // "no eligible campaigns" or "eligible but no live streamers".
 type FilterStats struct {
-	Total           int
-	StatusRejected  int // non-ACTIVE status
-	Expired         int // EndAt in the past
-	NotInWanted     int // wanted_games is non-empty AND campaign's game not in it
-	NotConnected    int // isAccountConnected=false AND no badge/emote benefit
-	Disabled        int // user-disabled
-	Completed       int // user-marked completed
-	NoEarnableDrops int // no IsEarnable drop right now (claimed / out-of-window / precondition gated)
-	Eligible        int
+	Total            int
+	StatusRejected   int // non-ACTIVE status
+	Expired          int // EndAt in the past
+	NotInWanted      int // wanted_games is non-empty AND campaign's game not in it (and not opted in)
+	GameFiltered     int // game in drops_games_deny, or not in a non-empty drops_games_allow (and not opted in)
+	NotConnected     int // isAccountConnected=false AND no badge/emote benefit
+	Disabled         int // user-disabled
+	Completed        int // user-marked completed
+	NoEarnableDrops  int // no IsEarnable drop right now (claimed / out-of-window / precondition gated)
+	BelowMinProgress int // existing progress under drop_min_progress_percent (and not opted in)
+	Eligible         int
 }
 
 // Selector is a pure pick-a-channel function with no side effects on Farmer state.
@@ -63,9 +69,10 @@ type FilterStats struct {
 type Selector struct {
 	cfg          *config.Config
 	streams      streamSource
-	now          func() time.Time // injectable for deterministic tests
-	lastFilter   FilterStats      // populated by every Select(); LastFilterStats() reads it
-	lastPoolSize int              // candidates after buildPool, before skip-set
+	now          func() time.Time  // injectable for deterministic tests
+	lastFilter   FilterStats       // populated by every Select(); LastFilterStats() reads it
+	lastPoolSize int               // candidates after buildPool, before skip-set
+	redundant    map[string]string // campaign ID → campaign covering its benefits; see dropRedundant
 	diagFn       func(format string, args ...interface{})
 }
 
@@ -101,9 +108,10 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 	wanted := s.cfg.GetGamesToWatch()
 	wantedSet := make(map[string]bool, len(wanted))
 	for _, g := range wanted {
-		wantedSet[strings.ToLower(strings.TrimSpace(g))] = true
+		wantedSet[s.cfg.GameKey(g)] = true
 	}
 	hasWantedFilter := len(wantedSet) > 0
+	minPct := s.cfg.GetDropMinProgressPercent()
 
 	// One-shot diagnostic dump: for every campaign whose game IS in the wanted
 	// list, log status/connection/benefit-type so we can see why a "should
@@ -113,7 +121,7 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 		if !hasWantedFilter {
 			return
 		}
-		if !wantedSet[strings.ToLower(strings.TrimSpace(c.GameName))] {
+		if !wantedSet[s.cfg.GameKey(c.GameName)] {
 			return
 		}
 		benefitTypes := make([]string, 0, len(c.Drops))
@@ -134,7 +142,7 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 	if hasWantedFilter && s.diagFn != nil {
 		count := 0
 		for _, c := range campaigns {
-			if !wantedSet[strings.ToLower(strings.TrimSpace(c.GameName))] {
+			if !wantedSet[s.cfg.GameKey(c.GameName)] {
 				continue
 			}
 			count++
@@ -155,7 +163,16 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 			stats.Expired++
 			continue
 		}
-		if hasWantedFilter && !wantedSet[strings.ToLower(strings.TrimSpace(c.GameName))] {
+		// drops_games_deny always applies; drops_games_allow, like the
+		// wanted_games whitelist, yields to a campaign opt-in.
+		if s.cfg.IsDropGameDenied(c.GameName) || (!s.cfg.IsDropGameAllowed(c.GameName) && !s.cfg.IsCampaignOptedIn(c.ID)) {
+			logWantedReject(c, "game_filtered")
+			stats.GameFiltered++
+			continue
+		}
+		// Campaigns opted in from the campaign browser bypass the
+		// whitelist — the user asked for this specific campaign.
+		if hasWantedFilter && !wantedSet[s.cfg.GameKey(c.GameName)] && !s.cfg.IsCampaignOptedIn(c.ID) {
 			stats.NotInWanted++
 			continue
 		}
@@ -178,6 +195,14 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 			stats.Completed++
 			continue
 		}
+		// drop_min_progress_percent: only finish campaigns that already
+		// have enough progress. Opted-in campaigns are exempt — the user
+		// picked them explicitly.
+		if minPct > 0 && c.ProgressPercent() < minPct && !s.cfg.IsCampaignOptedIn(c.ID) {
+			logWantedReject(c, "below_min_progress")
+			stats.BelowMinProgress++
+			continue
+		}
 		// Need at least one drop that's actually earnable RIGHT NOW.
 		// IsEarnable mirrors TDM's TimedDrop._base_can_earn — checks
 		// not just unclaimed-with-required-mins but also the per-drop
@@ -209,7 +234,15 @@ func (s *Selector) filterEligibleCampaigns(campaigns []twitch.DropCampaign) []tw
 // buildPool turns an eligible-campaign list into a deduped pool of candidate
 // channels. For campaigns with an allow list, it queries the drops-enabled
 // game directory and intersects with that list. For unrestricted campaigns,
-// the top drops-enabled streams for the game become candidates directly.
+// the top drops-enabled streams for the game become candidates directly,
+// minus those Twitch says are running other campaigns only.
+//
+// The campaign's auto-select mode narrows this: "off" only considers the
+// user's configured channels (intersected with the allow list, if any);
+// "allowed" keeps allow lists but replaces the directory scan for
+// unrestricted campaigns with the configured channels, so the bot never
+// joins a random streamer's chat. Allow-list and directory channels on
+// the blacklist (by login or the campaign's game) are skipped.
 //
 // Channels appearing in multiple campaigns are deduped — a single PoolEntry
 // carries all the campaigns it serves.
@@ -240,13 +273,76 @@ func (s *Selector) buildPool(eligible []twitch.DropCampaign) []*PoolEntry {
 		return streams
 	}
 
+	// Channel ID → campaigns it runs, for the directory streams. The
+	// DROPS_ENABLED filter only says a streamer has drops on; a stream
+	// whose campaigns are known and don't include ours would waste the
+	// watch time, so it is skipped. Unknown (lookup failed) keeps the
+	// stream on the directory filter's word.
+	runsCache := make(map[string][]string)
+	runsCampaign := func(streams []twitch.GameStream, campaignID string) func(channelID string) bool {
+		var missing []string
+		for _, st := range streams {
+			if _, ok := runsCache[st.BroadcasterID]; !ok {
+				missing = append(missing, st.BroadcasterID)
+			}
+		}
+		if len(missing) > 0 {
+			found := s.streams.GetChannelDropCampaigns(missing)
+			for _, id := range missing {
+				runsCache[id] = found[id] // nil = unknown
+			}
+		}
+		return func(channelID string) bool {
+			ids := runsCache[channelID]
+			if ids == nil {
+				return true
+			}
+			for _, id := range ids {
+				if id == campaignID {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	// Configured channels' live info, fetched at most once per cycle for
+	// campaigns whose auto-select mode keeps the pick on them.
+	var ownLogins []string
+	var ownInfos []*twitch.ChannelInfo
+	ownFetched := false
+	getOwn := func() ([]string, []*twitch.ChannelInfo) {
+		if !ownFetched {
+			ownFetched = true
+			ownLogins = s.cfg.GetChannelLogins()
+			if len(ownLogins) > 0 {
+				ownInfos = s.streams.GetChannelInfos(ownLogins)
+			}
+		}
+		return ownLogins, ownInfos
+	}
+
 	byChannel := make(map[string]*PoolEntry) // channelID → entry
+	addLive := func(login string, info *twitch.ChannelInfo, display string, ref CampaignRef) {
+		entry, exists := byChannel[info.ID]
+		if !exists {
+			entry = &PoolEntry{
+				ChannelID:    info.ID,
+				ChannelLogin: login,
+				DisplayName:  display,
+				ViewerCount:  info.ViewerCount,
+			}
+			byChannel[info.ID] = entry
+		}
+		entry.Campaigns = append(entry.Campaigns, ref)
+	}
 
 	for _, c := range eligible {
 		ref := CampaignRef{
 			ID:            c.ID,
 			Name:          c.Name,
 			GameName:      c.GameName,
+			GameID:        c.GameID,
 			EndAt:         c.EndAt,
 			RemainingTime: time.Until(c.EndAt),
 			IsPinned:      c.ID == pinnedID,
@@ -256,6 +352,29 @@ func (s *Selector) buildPool(eligible []twitch.DropCampaign) []*PoolEntry {
 		}
 
 		hasAllow := len(c.Channels) > 0
+		mode := s.cfg.GetCampaignAutoSelect(c.ID)
+
+		if mode == config.AutoSelectOff || (mode == config.AutoSelectAllowed && !hasAllow) {
+			allowed := make(map[string]bool, len(c.Channels))
+			for _, ch := range c.Channels {
+				allowed[strings.ToLower(ch.Name)] = true
+			}
+			logins, infos := getOwn()
+			for i, info := range infos {
+				if info == nil || !info.IsLive || !sameGame(s.cfg, info.GameID, info.GameName, c.GameID, c.GameName) {
+					continue
+				}
+				if hasAllow && !allowed[logins[i]] {
+					continue
+				}
+				display := info.DisplayName
+				if display == "" {
+					display = logins[i]
+				}
+				addLive(logins[i], info, display, ref)
+			}
+			continue
+		}
 
 		if hasAllow {
 			// ACL/Partner-Only campaigns (e.g., ABI Partner-Only Drops):
@@ -280,37 +399,34 @@ func (s *Selector) buildPool(eligible []twitch.DropCampaign) []*PoolEntry {
 					continue
 				}
 				// Strict: must actually be streaming the campaign's game
-				if !strings.EqualFold(info.GameName, c.GameName) {
+				if !sameGame(s.cfg, info.GameID, info.GameName, c.GameID, c.GameName) {
 					continue
 				}
 				login := logins[i]
-				entry, exists := byChannel[info.ID]
-				if !exists {
-					display := info.DisplayName
-					if display == "" {
-						if a, ok := loginToAllowed[login]; ok && a.DisplayName != "" {
-							display = a.DisplayName
-						} else {
-							display = login
-						}
-					}
-					entry = &PoolEntry{
-						ChannelID:    info.ID,
-						ChannelLogin: login,
-						DisplayName:  display,
-						ViewerCount:  info.ViewerCount,
+				if s.blocked(login, c.GameName) {
+					continue
+				}
+				display := info.DisplayName
+				if display == "" {
+					if a, ok := loginToAllowed[login]; ok && a.DisplayName != "" {
+						display = a.DisplayName
+					} else {
+						display = login
 					}
-					byChannel[info.ID] = entry
 				}
-				entry.Campaigns = append(entry.Campaigns, ref)
+				addLive(login, info, display, ref)
 			}
 			continue
 		}
 
 		// No allow list — fall back to game-directory drops-enabled streams.
 		streams := getDir(c.GameSlug, c.GameName)
+		runs := runsCampaign(streams, c.ID)
 		for _, st := range streams {
 			login := strings.ToLower(st.BroadcasterLogin)
+			if s.blocked(login, c.GameName) || !runs(st.BroadcasterID) {
+				continue
+			}
 			entry, exists := byChannel[st.BroadcasterID]
 			if !exists {
 				entry = &PoolEntry{
@@ -325,14 +441,30 @@ func (s *Selector) buildPool(eligible []twitch.DropCampaign) []*PoolEntry {
 		}
 	}
 
-	// Convert to slice
+	// Convert to slice. Configured points-only channels never serve a
+	// campaign, however they got in (own channels, allow list, directory),
+	// and neither do ones with disable_spade: drop minutes only credit
+	// through Spade heartbeats.
 	pool := make([]*PoolEntry, 0, len(byChannel))
 	for _, e := range byChannel {
+		if s.cfg.GetChannelMode(e.ChannelLogin) == config.ChannelModePoints {
+			continue
+		}
+		if !s.cfg.IsChannelFeatureEnabled(e.ChannelLogin, config.ChannelFeatureSpade) {
+			continue
+		}
 		pool = append(pool, e)
 	}
 	return pool
 }
 
+// blocked reports whether the blacklist keeps auto-selection from adding
+// a channel for a campaign of gameName. Configured channels are exempt:
+// they were added by hand.
+func (s *Selector) blocked(login, gameName string) bool {
+	return !s.cfg.HasChannel(login) && s.cfg.IsBlacklisted(login, gameName)
+}
+
 // sortPool sorts entries in priority order:
 //   1. wanted_games rank (lower index = higher priority; channels not in wanted go to end)
 //   2. earliest endAt across the channel's campaigns
@@ -344,7 +476,7 @@ func (s *Selector) sortPool(pool []*PoolEntry) {
 	wanted := s.cfg.GetGamesToWatch()
 	gameRanks := make(map[string]int, len(wanted))
 	for i, g := range wanted {
-		gameRanks[strings.ToLower(strings.TrimSpace(g))] = i
+		gameRanks[s.cfg.GameKey(g)] = i
 	}
 	useGameSort := len(wanted) > 0
 	notWantedRank := len(wanted)
@@ -360,7 +492,7 @@ func (s *Selector) sortPool(pool []*PoolEntry) {
 		first := true
 		for _, ref := range e.Campaigns {
 			if useGameSort {
-				if r, ok := gameRanks[strings.ToLower(ref.GameName)]; ok && r < c.gameRank {
+				if r, ok := gameRanks[s.cfg.GameKey(ref.GameName)]; ok && r < c.gameRank {
 					c.gameRank = r
 				}
 			}
@@ -399,11 +531,9 @@ func (s *Selector) sortPool(pool []*PoolEntry) {
 // The returned pool is sorted; callers can use pool[1:] as the queue for UI.
 func (s *Selector) Select(campaigns []twitch.DropCampaign, skipChannels map[string]bool) (*PoolEntry, []*PoolEntry) {
 	eligible := s.filterEligibleCampaigns(campaigns)
-	if len(eligible) == 0 {
-		return nil, nil
-	}
 	pool := s.buildPool(eligible)
 	s.lastPoolSize = len(pool)
+	pool, s.redundant = dropRedundant(pool, eligible)
 	if len(pool) == 0 {
 		return nil, nil
 	}
@@ -440,6 +570,11 @@ func hasBadgeOrEmoteBenefit(c twitch.DropCampaign) bool {
 	return false
 }
 
+// Redundant returns the campaigns the most recent Select left out
+// because another campaign in the pool grants the same benefits
+// (campaign ID → ID of the campaign farmed instead).
+func (s *Selector) Redundant() map[string]string { return s.redundant }
+
 // LastPoolSize returns how many channel candidates the pool stage produced
 // from the most recent Select. 0 with Eligible>0 means the filter passed
 // campaigns but no live drops-enabled streamer was found for any of them.
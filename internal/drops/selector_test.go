@@ -1,6 +1,7 @@
 package drops
 
 import (
+	"fmt"
 	"testing"
 	"time"
 
@@ -128,17 +129,108 @@ func TestFilterEligibleCampaigns(t *testing.T) {
 	}
 }
 
+func TestFilterEligibleCampaigns_OptInBypassesWantedGames(t *testing.T) {
+	cfg := &config.Config{GamesToWatch: []string{"Marvel Rivals"}}
+	camp := twitch.DropCampaign{
+		ID: "camp-optin", Status: "ACTIVE", IsAccountConnected: true, GameName: "Rust",
+		EndAt: testNow.Add(2 * time.Hour),
+		Drops: []twitch.TimeBasedDrop{makeWatchableDrop()},
+	}
+
+	sel := newTestSelector(cfg)
+	if out := sel.filterEligibleCampaigns([]twitch.DropCampaign{camp}); len(out) != 0 {
+		t.Fatalf("campaign outside wanted_games should be filtered, got %d", len(out))
+	}
+
+	cfg.SetCampaignOptIn("camp-optin", true)
+	if out := sel.filterEligibleCampaigns([]twitch.DropCampaign{camp}); len(out) != 1 {
+		t.Fatalf("opted-in campaign should bypass wanted_games, got %d", len(out))
+	}
+}
+
+func TestFilterEligibleCampaigns_GameAllowDeny(t *testing.T) {
+	cfg := &config.Config{
+		DropsGamesAllow: []string{"Rust", "Path of Exile"},
+		DropsGamesDeny:  []string{"path of exile"},
+	}
+	camp := func(id, game string) twitch.DropCampaign {
+		return twitch.DropCampaign{ID: id, Status: "ACTIVE", IsAccountConnected: true, GameName: game,
+			EndAt: testNow.Add(2 * time.Hour), Drops: []twitch.TimeBasedDrop{makeWatchableDrop()}}
+	}
+	camps := []twitch.DropCampaign{camp("rust", "Rust"), camp("poe", "Path of Exile"), camp("other", "Marvel Rivals")}
+
+	sel := newTestSelector(cfg)
+	out := sel.filterEligibleCampaigns(camps)
+	if len(out) != 1 || out[0].ID != "rust" {
+		t.Fatalf("eligible = %v, want only rust", out)
+	}
+	if fs := sel.LastFilterStats(); fs.GameFiltered != 2 {
+		t.Fatalf("GameFiltered = %d, want 2", fs.GameFiltered)
+	}
+
+	// An opt-in bypasses the allow list but not the deny list.
+	cfg.SetCampaignOptIn("other", true)
+	cfg.SetCampaignOptIn("poe", true)
+	out = sel.filterEligibleCampaigns(camps)
+	if len(out) != 2 || out[0].ID != "rust" || out[1].ID != "other" {
+		t.Fatalf("eligible = %v, want rust + other", out)
+	}
+}
+
+func TestFilterEligibleCampaigns_MinProgress(t *testing.T) {
+	cfg := &config.Config{DropMinProgressPercent: 50}
+	drop := func(id string, required, current int, claimed bool) twitch.TimeBasedDrop {
+		return twitch.TimeBasedDrop{ID: id, RequiredMinutesWatched: required, CurrentMinutesWatched: current, IsClaimed: claimed}
+	}
+	camp := func(id string, drops ...twitch.TimeBasedDrop) twitch.DropCampaign {
+		return twitch.DropCampaign{ID: id, Status: "ACTIVE", IsAccountConnected: true, GameName: "Rust",
+			EndAt: testNow.Add(2 * time.Hour), Drops: drops}
+	}
+	camps := []twitch.DropCampaign{
+		camp("fresh", drop("a", 60, 0, false)),
+		// 60 claimed + 30 of 120 = 90/180 = 50%.
+		camp("halfway", drop("b", 60, 60, true), drop("c", 120, 30, false)),
+		camp("optin", drop("d", 60, 10, false)),
+	}
+	cfg.SetCampaignOptIn("optin", true)
+
+	sel := newTestSelector(cfg)
+	out := sel.filterEligibleCampaigns(camps)
+	if len(out) != 2 || out[0].ID != "halfway" || out[1].ID != "optin" {
+		t.Fatalf("eligible = %v, want halfway + optin", out)
+	}
+	if fs := sel.LastFilterStats(); fs.BelowMinProgress != 1 {
+		t.Fatalf("BelowMinProgress = %d, want 1", fs.BelowMinProgress)
+	}
+
+	cfg.SetDropMinProgressPercent(0)
+	if out := sel.filterEligibleCampaigns(camps); len(out) != 3 {
+		t.Fatalf("threshold off: %d eligible, want 3", len(out))
+	}
+}
+
 // fakeStreamSource is a deterministic in-memory stream source for tests.
 type fakeStreamSource struct {
 	byGame  map[string][]twitch.GameStream
 	calls   map[string]int                     // game name → how often queried
 	byLogin map[string]*twitch.ChannelInfo     // login → ChannelInfo for ACL lookups
+	runs    map[string][]string                // channel ID → campaigns it runs; absent = lookup failed
 }
 
-func (f *fakeStreamSource) GetGameStreamsDropsEnabled(gameName string, limit int) ([]twitch.GameStream, error) {
+// GetGameStreamsDropsEnabled receives the directory slug (buildPool derives
+// it via SlugFromGameName), while tests key byGame/calls by display name —
+// match the two through the same derivation.
+func (f *fakeStreamSource) GetGameStreamsDropsEnabled(slug string, limit int) ([]twitch.GameStream, error) {
 	if f.calls == nil {
 		f.calls = make(map[string]int)
 	}
+	gameName := slug
+	for name := range f.byGame {
+		if name == slug || twitch.SlugFromGameName(name) == slug {
+			gameName = name
+			break
+		}
+	}
 	f.calls[gameName]++
 	streams := f.byGame[gameName]
 	if len(streams) > limit {
@@ -155,6 +247,16 @@ func (f *fakeStreamSource) GetChannelInfos(logins []string) []*twitch.ChannelInf
 	return out
 }
 
+func (f *fakeStreamSource) GetChannelDropCampaigns(channelIDs []string) map[string][]string {
+	out := make(map[string][]string)
+	for _, id := range channelIDs {
+		if ids, ok := f.runs[id]; ok {
+			out[id] = ids
+		}
+	}
+	return out
+}
+
 func newSelectorWithStreams(cfg *config.Config, src *fakeStreamSource) *Selector {
 	return &Selector{
 		cfg:     cfg,
@@ -211,6 +313,135 @@ func TestBuildPool_AllowListIntersection(t *testing.T) {
 	}
 }
 
+// autoSelectFixture: one ACL campaign allowing "mine" and "stranger",
+// one unrestricted campaign whose directory lists "mine" and "other".
+// Only "mine" is a configured channel.
+func autoSelectFixture() (*fakeStreamSource, []twitch.DropCampaign) {
+	src := &fakeStreamSource{
+		byGame: map[string][]twitch.GameStream{
+			"Marvel Rivals": {
+				{BroadcasterID: "1", BroadcasterLogin: "mine", DisplayName: "Mine", ViewerCount: 10},
+				{BroadcasterID: "3", BroadcasterLogin: "other", DisplayName: "Other", ViewerCount: 900},
+			},
+		},
+		byLogin: map[string]*twitch.ChannelInfo{
+			"mine":     {ID: "1", Login: "mine", DisplayName: "Mine", IsLive: true, GameName: "Marvel Rivals", ViewerCount: 10},
+			"stranger": {ID: "2", Login: "stranger", DisplayName: "Stranger", IsLive: true, GameName: "Marvel Rivals", ViewerCount: 500},
+		},
+	}
+	campaigns := []twitch.DropCampaign{
+		{
+			ID: "acl", Status: "ACTIVE", IsAccountConnected: true, GameName: "Marvel Rivals",
+			EndAt: testNow.Add(2 * time.Hour),
+			Drops: []twitch.TimeBasedDrop{makeWatchableDrop()},
+			Channels: []twitch.DropChannel{{ID: "1", Name: "mine"}, {ID: "2", Name: "stranger"}},
+		},
+		{
+			ID: "open", Status: "ACTIVE", IsAccountConnected: true, GameName: "Marvel Rivals",
+			EndAt: testNow.Add(3 * time.Hour),
+			Drops: []twitch.TimeBasedDrop{makeWatchableDrop()},
+		},
+	}
+	return src, campaigns
+}
+
+// poolCampaigns maps each pool login to the campaign IDs it serves.
+func poolCampaigns(pool []*PoolEntry) map[string][]string {
+	out := make(map[string][]string)
+	for _, e := range pool {
+		for _, ref := range e.Campaigns {
+			out[e.ChannelLogin] = append(out[e.ChannelLogin], ref.ID)
+		}
+	}
+	return out
+}
+
+func TestBuildPool_AutoSelectModes(t *testing.T) {
+	tests := []struct {
+		mode string
+		want string // fmt of poolCampaigns
+	}{
+		{config.AutoSelectDirectory, "map[mine:[acl open] other:[open] stranger:[acl]]"},
+		{config.AutoSelectAllowed, "map[mine:[acl open] stranger:[acl]]"},
+		{config.AutoSelectOff, "map[mine:[acl open]]"},
+	}
+	for _, tc := range tests {
+		src, campaigns := autoSelectFixture()
+		cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{{Login: "mine", Priority: 2}}}
+		cfg.SetDropAutoSelect(tc.mode)
+		sel := newSelectorWithStreams(cfg, src)
+
+		got := fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+		if got != tc.want {
+			t.Errorf("mode %s: pool %s, want %s", tc.mode, got, tc.want)
+		}
+	}
+}
+
+// TestBuildPool_CampaignAutoSelectOverride: a per-campaign override wins
+// over the global mode in both directions.
+func TestBuildPool_CampaignAutoSelectOverride(t *testing.T) {
+	src, campaigns := autoSelectFixture()
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{{Login: "mine", Priority: 2}}}
+	cfg.SetDropAutoSelect(config.AutoSelectOff)
+	cfg.SetCampaignAutoSelect("open", config.AutoSelectDirectory)
+	sel := newSelectorWithStreams(cfg, src)
+
+	got := fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+	if want := "map[mine:[acl open] other:[open]]"; got != want {
+		t.Fatalf("pool %s, want %s", got, want)
+	}
+}
+
+// TestBuildPool_PointsOnlyChannelExcluded: a points-only channel never
+// serves a campaign, whether it came from the directory or the allow list.
+func TestBuildPool_PointsOnlyChannelExcluded(t *testing.T) {
+	src, campaigns := autoSelectFixture()
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{{Login: "mine", Priority: 2, Mode: config.ChannelModePoints}}}
+	sel := newSelectorWithStreams(cfg, src)
+
+	got := fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+	if want := "map[other:[open] stranger:[acl]]"; got != want {
+		t.Fatalf("pool %s, want %s", got, want)
+	}
+}
+
+// TestBuildPool_SpadeDisabledChannelExcluded: without Spade heartbeats a
+// channel can't credit drop minutes, so it never serves a campaign.
+func TestBuildPool_SpadeDisabledChannelExcluded(t *testing.T) {
+	src, campaigns := autoSelectFixture()
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{{Login: "mine", Priority: 2, DisableSpade: true}}}
+	sel := newSelectorWithStreams(cfg, src)
+
+	got := fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+	if want := "map[other:[open] stranger:[acl]]"; got != want {
+		t.Fatalf("pool %s, want %s", got, want)
+	}
+}
+
+// TestBuildPool_BlacklistSkipsAutoAddedChannels: blacklisted logins and
+// games keep allow-list and directory channels out; configured channels
+// still serve.
+func TestBuildPool_BlacklistSkipsAutoAddedChannels(t *testing.T) {
+	src, campaigns := autoSelectFixture()
+	cfg := &config.Config{Blacklist: []string{"Stranger"}}
+	sel := newSelectorWithStreams(cfg, src)
+	got := fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+	if want := "map[mine:[acl open] other:[open]]"; got != want {
+		t.Fatalf("login blacklist: pool %s, want %s", got, want)
+	}
+
+	cfg = &config.Config{
+		Blacklist:      []string{"marvel rivals"},
+		ChannelConfigs: []config.ChannelEntry{{Login: "mine", Priority: 2}},
+	}
+	sel = newSelectorWithStreams(cfg, src)
+	got = fmt.Sprint(poolCampaigns(sel.buildPool(campaigns)))
+	if want := "map[mine:[acl open]]"; got != want {
+		t.Fatalf("game blacklist: pool %s, want %s", got, want)
+	}
+}
+
 func TestBuildPool_UnrestrictedCampaign(t *testing.T) {
 	cfg := &config.Config{}
 	src := &fakeStreamSource{byGame: map[string][]twitch.GameStream{
@@ -234,6 +465,42 @@ func TestBuildPool_UnrestrictedCampaign(t *testing.T) {
 	}
 }
 
+func TestBuildPool_DirectorySkipsStreamsRunningOtherCampaigns(t *testing.T) {
+	cfg := &config.Config{}
+	src := &fakeStreamSource{
+		byGame: map[string][]twitch.GameStream{
+			"Marvel Rivals": {
+				{BroadcasterID: "10", BroadcasterLogin: "streamer_a", ViewerCount: 5000},
+				{BroadcasterID: "11", BroadcasterLogin: "streamer_b", ViewerCount: 3000},
+				{BroadcasterID: "12", BroadcasterLogin: "streamer_c", ViewerCount: 1000},
+				{BroadcasterID: "13", BroadcasterLogin: "streamer_d", ViewerCount: 500},
+			},
+		},
+		runs: map[string][]string{
+			"10": {"rivals-s6"},              // drops on, but for another campaign
+			"11": {"rivals-s6", "rivals-s7"}, // runs ours
+			"12": {},                         // no campaign at all
+			// "13" lookup failed: kept on the directory filter's word
+		},
+	}
+	sel := newSelectorWithStreams(cfg, src)
+
+	camp := twitch.DropCampaign{
+		ID: "rivals-s7", Status: "ACTIVE", IsAccountConnected: true, GameName: "Marvel Rivals",
+		EndAt: testNow.Add(5 * time.Hour),
+		Drops: []twitch.TimeBasedDrop{makeWatchableDrop()},
+	}
+
+	pool := sel.buildPool([]twitch.DropCampaign{camp})
+	got := map[string]bool{}
+	for _, e := range pool {
+		got[e.ChannelID] = true
+	}
+	if len(got) != 2 || !got["11"] || !got["13"] {
+		t.Errorf("pool = %v, want channels 11 and 13", got)
+	}
+}
+
 func TestBuildPool_DedupesAcrossCampaigns(t *testing.T) {
 	cfg := &config.Config{}
 	src := &fakeStreamSource{
@@ -29,14 +29,18 @@ type Service struct {
 	channels               *channels.Registry
 	watcher                *Watcher
 	log                    func(string, ...interface{}) // visible UI + file
+	warn                   func(string, ...interface{}) // like log, at warn level
 	writeLogFile           func(string)                 // file-only noise log
 	removeTempChannel      func(channelID string)
 	addTempChannelFromInfo func(info *twitch.ChannelInfo, campaignID string) error
 	triggerRotation        func()
+	onDropClaimed          func(DropClaimed)
+	isPaused               func() bool
 
 	// Subordinate services (built by NewService).
 	Selector *Selector
 	Stall    *StallTracker
+	archive  *Archive
 
 	// State (protected by mu).
 	mu                 sync.RWMutex
@@ -46,6 +50,7 @@ type Service struct {
 	campaignCache      map[string]twitch.DropCampaign // campaignID -> campaign, rebuilt each cycle
 	currentPickID      string                         // ChannelID currently assigned the drop slot, "" if none
 	lastProgressUpdate time.Time                      // when applyDropProgressUpdate last fired (WS or poll)
+	nextCheck          time.Time                      // when CheckLoop fires next; zero when it isn't running
 
 	// processQueue is a 1-slot buffered channel that serializes
 	// processOnce(). Every trigger source (CheckLoop, claim handler,
@@ -63,6 +68,14 @@ type Service struct {
 	// channel send/receive are themselves the synchronization
 	// primitive.
 	processQueue chan struct{}
+
+	// checkNow wakes CheckLoop early (CheckNow). 1-slot buffer, same
+	// coalescing as processQueue.
+	checkNow chan struct{}
+	// replan tells CheckLoop a cycle finished, so it re-times the next
+	// check from the new state (nextCheckInterval). Same 1-slot
+	// coalescing.
+	replan chan struct{}
 }
 
 // ServiceDeps bundles the external dependencies NewService needs. The
@@ -77,6 +90,7 @@ type ServiceDeps struct {
 	Channels *channels.Registry
 	Watcher  *Watcher
 	Log      func(string, ...interface{}) // visible UI + file
+	Warn     func(string, ...interface{}) // failures; Log is used if nil
 	// WriteLogFile writes file-only debug entries (used for noisy events
 	// that shouldn't flood the UI feed — non-pick game-change PubSub
 	// notifications, every WS drop-progress event, etc.).
@@ -95,6 +109,16 @@ type ServiceDeps struct {
 	// slot 1 reflects the freshly-applied drop pick. Rotation lives in
 	// farmer (it's part of the channel-points domain, not drops).
 	TriggerRotation func()
+	// OnDropClaimed is told about every drop we claim (inventory sweep
+	// and PubSub claim alike), for notifications, and every claim that
+	// failed (DropClaimed.Error set). May be nil.
+	OnDropClaimed func(DropClaimed)
+	// Paused reports whether the whole farmer is paused (Farmer.Pause):
+	// inventory cycles and claims are skipped until it ends. May be nil.
+	Paused func() bool
+	// ArchivePath is the completed-campaigns archive file; empty keeps
+	// the archive in memory.
+	ArchivePath string
 }
 
 // NewService constructs a Service with its subordinate Selector and
@@ -103,6 +127,10 @@ type ServiceDeps struct {
 // spawned by farmer.Start — kicks queue up and the worker picks
 // them up once it starts.
 func NewService(deps ServiceDeps) *Service {
+	warn := deps.Warn
+	if warn == nil {
+		warn = deps.Log
+	}
 	return &Service{
 		cfg:                    deps.Cfg,
 		gql:                    deps.GQL,
@@ -112,13 +140,19 @@ func NewService(deps ServiceDeps) *Service {
 		channels:               deps.Channels,
 		watcher:                deps.Watcher,
 		log:                    deps.Log,
+		warn:                   warn,
 		writeLogFile:           deps.WriteLogFile,
 		removeTempChannel:      deps.RemoveTempChannel,
 		addTempChannelFromInfo: deps.AddTempChannelFromInfo,
 		triggerRotation:        deps.TriggerRotation,
+		onDropClaimed:          deps.OnDropClaimed,
+		isPaused:               deps.Paused,
 		Selector:               NewSelector(deps.Cfg, deps.GQL),
-		Stall:                  NewStallTracker(deps.Log),
+		Stall:                  NewStallTracker(warn),
+		archive:                NewArchive(deps.ArchivePath, deps.Log),
 		processQueue:           make(chan struct{}, 1),
+		checkNow:               make(chan struct{}, 1),
+		replan:                 make(chan struct{}, 1),
 	}
 }
 
@@ -150,3 +184,42 @@ func (s *Service) ActiveDropsCount() int {
 	defer s.mu.RUnlock()
 	return len(s.activeDrops)
 }
+
+// farmingPaused reports whether the whole farmer is paused.
+func (s *Service) farmingPaused() bool {
+	return s.isPaused != nil && s.isPaused()
+}
+
+// Suspend releases the current pick for Farmer.Pause: the Watcher
+// session and the pick's Spade slot stop, and the stall snapshot is
+// dropped so the minutes not watched while paused aren't taken for a
+// stall. The pick itself stays recorded; the first inventory cycle
+// after the pause hands it (or a better one) to the Watcher again.
+func (s *Service) Suspend() {
+	s.mu.RLock()
+	pickID := s.currentPickID
+	s.mu.RUnlock()
+
+	if s.watcher != nil {
+		s.watcher.Stop()
+	}
+	if pickID != "" {
+		s.spade.StopWatching(pickID)
+		if ch, ok := s.channels.Get(pickID); ok {
+			ch.SetWatching(false)
+		}
+	}
+	s.Stall.SnapshotPick(nil, nil)
+}
+
+// ArchivedCampaigns returns the completed-campaigns archive, newest
+// first.
+func (s *Service) ArchivedCampaigns() []CampaignRecord {
+	return s.archive.Archived()
+}
+
+// TrackedCampaigns returns the records of the campaigns farmed and not
+// archived yet.
+func (s *Service) TrackedCampaigns() []CampaignRecord {
+	return s.archive.Tracking()
+}
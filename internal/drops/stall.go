@@ -8,11 +8,23 @@ import (
 )
 
 // StallCooldownDuration is how long a channel is excluded from the pool
-// after Twitch failed to credit drop progress for that channel for one
-// cycle. 30 min ≈ 6 cycles — long enough that we exhaust other candidates
-// before retrying, short enough to recover from temporary Twitch hiccups.
+// after Twitch failed to credit drop progress for that channel for
+// StallCycles cycles. 30 min ≈ 6 cycles — long enough that we exhaust
+// other candidates before retrying, short enough to recover from
+// temporary Twitch hiccups.
 const StallCooldownDuration = 30 * time.Minute
 
+const (
+	// StallCycles is how many inventory cycles in a row must show no new
+	// minutes on the picked drop before the pick is failed over.
+	StallCycles = 2
+	// stallMinInterval is the least time a cycle must come after the
+	// previous one to count towards StallCycles. Claims, game changes
+	// and the UI kick extra cycles seconds apart; Twitch can't have
+	// credited a minute in between, so those prove nothing.
+	stallMinInterval = 5 * time.Minute
+)
+
 // CooldownReason explains why a channel is currently in cooldown. It
 // matters because the stall-recovery path clears cooldowns when a channel
 // credits new minutes — but that recovery must NOT clear cooldowns set
@@ -38,10 +50,10 @@ type cooldownEntry struct {
 // StallTracker tracks Twitch's drop-credit reliability per channel.
 // It snapshots the picked channel/campaign/progress at the end of each
 // inventory cycle, then compares the next cycle's progress to that
-// baseline. If progress did not advance, the channel goes into stall
-// cooldown so the selector skips it. It also accepts manual cooldowns
-// from game-change and id-mismatch paths that must NOT be cleared by
-// credit recovery.
+// baseline. If progress did not advance for StallCycles cycles, the
+// channel goes into stall cooldown so the selector fails over to another
+// channel. It also accepts manual cooldowns from game-change and
+// id-mismatch paths that must NOT be cleared by credit recovery.
 //
 // All methods are safe for concurrent use; the tracker owns its own
 // mutex and never reaches into Farmer state.
@@ -49,31 +61,41 @@ type StallTracker struct {
 	mu       sync.Mutex
 	cooldown map[string]cooldownEntry
 	log      func(string, ...interface{})
+	now      func() time.Time // injectable for tests
 
 	// Baseline for the next Apply() comparison.
 	lastPickChannelID  string
+	lastPickLogin      string
 	lastPickCampaignID string
 	lastPickProgress   int
+	lastCheckAt        time.Time // baseline taken, or last stalled cycle counted
+	stalledCycles      int       // cycles in a row without new minutes
 }
 
 // NewStallTracker constructs a StallTracker. log may be nil; if non-nil
-// it receives the "no credit on X" line whenever Apply records a stall.
+// it receives the failover line whenever Apply records a stall.
 func NewStallTracker(log func(string, ...interface{})) *StallTracker {
 	return &StallTracker{
 		cooldown: make(map[string]cooldownEntry),
 		log:      log,
+		now:      time.Now,
 	}
 }
 
 // SnapshotPick records the picked channel's primary-campaign progress
 // so the next Apply() can compare. Pass nil pick to clear the baseline.
+// Re-snapshotting the same pick at the same progress keeps the running
+// stall count and its timing.
 func (s *StallTracker) SnapshotPick(pick *PoolEntry, campaigns []twitch.DropCampaign) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	if pick == nil || len(pick.Campaigns) == 0 {
 		s.lastPickChannelID = ""
+		s.lastPickLogin = ""
 		s.lastPickCampaignID = ""
 		s.lastPickProgress = 0
+		s.lastCheckAt = time.Time{}
+		s.stalledCycles = 0
 		return
 	}
 	primaryCampID := pick.Campaigns[0].ID
@@ -91,16 +113,25 @@ func (s *StallTracker) SnapshotPick(pick *PoolEntry, campaigns []twitch.DropCamp
 		}
 		break
 	}
+	same := pick.ChannelID == s.lastPickChannelID && primaryCampID == s.lastPickCampaignID &&
+		progress == s.lastPickProgress
 	s.lastPickChannelID = pick.ChannelID
+	s.lastPickLogin = pick.ChannelLogin
 	s.lastPickCampaignID = primaryCampID
 	s.lastPickProgress = progress
+	if !same {
+		s.lastCheckAt = s.now()
+		s.stalledCycles = 0
+	}
 }
 
 // Apply compares the snapshot against the new inventory. If the
-// previously snapshotted pick's progress did not advance, the channel
-// gets a stall-reason cooldown. If progress advanced, ONLY the
+// previously snapshotted pick's progress did not advance for StallCycles
+// cycles (each at least stallMinInterval apart), the channel gets a
+// stall-reason cooldown and its ID is returned: the selection that
+// follows fails over to another channel. If progress advanced, ONLY the
 // stall-reason cooldown is cleared — manual cooldowns are preserved.
-func (s *StallTracker) Apply(campaigns []twitch.DropCampaign) {
+func (s *StallTracker) Apply(campaigns []twitch.DropCampaign) (stalledChannelID string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -108,7 +139,7 @@ func (s *StallTracker) Apply(campaigns []twitch.DropCampaign) {
 	prevCampID := s.lastPickCampaignID
 	prevProgress := s.lastPickProgress
 	if prevChID == "" || prevCampID == "" {
-		return // no previous pick to evaluate
+		return "" // no previous pick to evaluate
 	}
 
 	// Find the previous pick's drop progress in the new inventory.
@@ -133,7 +164,7 @@ func (s *StallTracker) Apply(campaigns []twitch.DropCampaign) {
 	if currentProgress < 0 {
 		// Campaign disappeared from inventory or fully claimed. Either
 		// way, no stall to record.
-		return
+		return ""
 	}
 
 	if currentProgress > prevProgress {
@@ -145,18 +176,38 @@ func (s *StallTracker) Apply(campaigns []twitch.DropCampaign) {
 		if cd, ok := s.cooldown[prevChID]; ok && cd.reason == CooldownStall {
 			delete(s.cooldown, prevChID)
 		}
-		return
+		s.stalledCycles = 0
+		return ""
 	}
 
-	// No credit since last cycle — record a stall-reason cooldown.
+	// No credit since last cycle. Too soon after the previous one to
+	// tell anything — wait for a later cycle.
+	now := s.now()
+	if now.Sub(s.lastCheckAt) < stallMinInterval {
+		return ""
+	}
+	s.lastCheckAt = now
+	s.stalledCycles++
+	if s.stalledCycles < StallCycles {
+		return ""
+	}
+
+	// Stuck for StallCycles cycles — record a stall-reason cooldown so
+	// the selector fails over.
 	s.cooldown[prevChID] = cooldownEntry{
-		expires: time.Now().Add(StallCooldownDuration),
+		expires: now.Add(StallCooldownDuration),
 		reason:  CooldownStall,
 	}
+	s.stalledCycles = 0
 	if s.log != nil {
-		s.log("[Drops/Pool] no credit on %s (progress stuck at %d/%d) — %v cooldown",
-			prevChID, currentProgress, prevProgress, StallCooldownDuration)
+		name := s.lastPickLogin
+		if name == "" {
+			name = prevChID
+		}
+		s.log("[Drops] Failing over from %s: no drop progress in %d inventory cycles (stuck at %d min) — skipping it for %v",
+			name, StallCycles, currentProgress, StallCooldownDuration)
 	}
+	return prevChID
 }
 
 // SetManual records a manual-reason cooldown that won't be auto-cleared
@@ -166,7 +217,7 @@ func (s *StallTracker) SetManual(channelID string, dur time.Duration) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.cooldown[channelID] = cooldownEntry{
-		expires: time.Now().Add(dur),
+		expires: s.now().Add(dur),
 		reason:  CooldownManual,
 	}
 }
@@ -177,7 +228,7 @@ func (s *StallTracker) ActiveSkipSet() map[string]bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	skip := make(map[string]bool, len(s.cooldown))
-	now := time.Now()
+	now := s.now()
 	for chID, cd := range s.cooldown {
 		if now.Before(cd.expires) {
 			skip[chID] = true
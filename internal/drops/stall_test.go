@@ -19,6 +19,23 @@ func makeCampaign(id string, dropID string, current, required int, claimed bool)
 	}
 }
 
+// newTestStallTracker returns a tracker on a fake clock and a function
+// moving that clock forward.
+func newTestStallTracker() (*StallTracker, func(time.Duration)) {
+	s := NewStallTracker(nil)
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	s.now = func() time.Time { return now }
+	return s, func(d time.Duration) { now = now.Add(d) }
+}
+
+// stallCycle runs one inventory cycle for pick: Apply, then SnapshotPick,
+// as processOnce does.
+func stallCycle(s *StallTracker, pick *PoolEntry, camps []twitch.DropCampaign) string {
+	stalled := s.Apply(camps)
+	s.SnapshotPick(pick, camps)
+	return stalled
+}
+
 func TestStallTracker_NoBaseline_NoCooldown(t *testing.T) {
 	s := NewStallTracker(nil)
 	s.Apply([]twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
@@ -39,30 +56,82 @@ func TestStallTracker_ProgressAdvanced_NoCooldown(t *testing.T) {
 }
 
 func TestStallTracker_ProgressStalled_AddsCooldown(t *testing.T) {
-	s := NewStallTracker(nil)
+	s, advance := newTestStallTracker()
 	pick := &PoolEntry{ChannelID: "ch1", Campaigns: []CampaignRef{{ID: "c1"}}}
-	s.SnapshotPick(pick, []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
-	// Next cycle: still 5
-	s.Apply([]twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
+	stuck := []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)}
+	s.SnapshotPick(pick, stuck)
+
+	// First cycle without progress: not yet.
+	advance(15 * time.Minute)
+	if got := stallCycle(s, pick, stuck); got != "" {
+		t.Fatalf("stalled after one cycle: %q", got)
+	}
+	if _, in := s.ActiveSkipSet()["ch1"]; in {
+		t.Fatal("ch1 put in cooldown after a single no-progress cycle")
+	}
+	// Second one fails over.
+	advance(15 * time.Minute)
+	if got := stallCycle(s, pick, stuck); got != "ch1" {
+		t.Fatalf("Apply after %d stalled cycles = %q, want ch1", StallCycles, got)
+	}
 	if _, in := s.ActiveSkipSet()["ch1"]; !in {
-		t.Error("ch1 not put in cooldown after no-progress cycle")
+		t.Error("ch1 not put in cooldown after no-progress cycles")
+	}
+}
+
+func TestStallTracker_QuickCyclesDontCount(t *testing.T) {
+	s, advance := newTestStallTracker()
+	pick := &PoolEntry{ChannelID: "ch1", Campaigns: []CampaignRef{{ID: "c1"}}}
+	stuck := []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)}
+	s.SnapshotPick(pick, stuck)
+
+	// Extra cycles kicked seconds apart (claims, UI) prove nothing.
+	for i := 0; i < 5; i++ {
+		advance(30 * time.Second)
+		if got := stallCycle(s, pick, stuck); got != "" {
+			t.Fatalf("quick cycle %d stalled %q", i, got)
+		}
+	}
+	if len(s.ActiveSkipSet()) != 0 {
+		t.Fatal("quick cycles put the channel in cooldown")
 	}
 }
 
 func TestStallTracker_StallClearedOnLaterProgress(t *testing.T) {
-	s := NewStallTracker(nil)
+	s, advance := newTestStallTracker()
 	pick := &PoolEntry{ChannelID: "ch1", Campaigns: []CampaignRef{{ID: "c1"}}}
-	// Cycle 1: stalls
-	s.SnapshotPick(pick, []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
-	s.Apply([]twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
-	// Cycle 2: same pick now credits a minute
-	s.SnapshotPick(pick, []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
+	stuck := []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)}
+	// Stalls for StallCycles cycles
+	s.SnapshotPick(pick, stuck)
+	for i := 0; i < StallCycles; i++ {
+		advance(15 * time.Minute)
+		stallCycle(s, pick, stuck)
+	}
+	if _, in := s.ActiveSkipSet()["ch1"]; !in {
+		t.Fatal("ch1 not in cooldown after stalling")
+	}
+	// Next cycle: same pick now credits a minute
+	advance(15 * time.Minute)
 	s.Apply([]twitch.DropCampaign{makeCampaign("c1", "d1", 6, 60, false)})
 	if _, in := s.ActiveSkipSet()["ch1"]; in {
 		t.Error("stall cooldown not cleared after credit recovery")
 	}
 }
 
+func TestStallTracker_ProgressResetsCount(t *testing.T) {
+	s, advance := newTestStallTracker()
+	pick := &PoolEntry{ChannelID: "ch1", Campaigns: []CampaignRef{{ID: "c1"}}}
+	s.SnapshotPick(pick, []twitch.DropCampaign{makeCampaign("c1", "d1", 5, 60, false)})
+
+	// stuck, credited, stuck: never two in a row
+	for _, minutes := range []int{5, 9, 9} {
+		advance(15 * time.Minute)
+		if got := stallCycle(s, pick, []twitch.DropCampaign{makeCampaign("c1", "d1", minutes, 60, false)}); got != "" {
+			t.Fatalf("stalled at %d min", minutes)
+		}
+	}
+}
+
 func TestStallTracker_ManualSurvivesProgressRecovery(t *testing.T) {
 	s := NewStallTracker(nil)
 	// Set a manual cooldown directly (game change scenario).
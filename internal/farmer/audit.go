@@ -0,0 +1,48 @@
+package farmer
+
+import (
+	"errors"
+
+	"github.com/miwi/twitchpoint/internal/audit"
+	"github.com/miwi/twitchpoint/internal/points"
+)
+
+// auditFileName sits next to config.json and holds every claim, raid
+// join and redemption while audit_log is on.
+const auditFileName = "audit.jsonl"
+
+// ErrAuditDisabled is returned by Audit before Start.
+var ErrAuditDisabled = errors.New("audit log is not available")
+
+// onActivity is points' OnActivity hook: the entry is pushed to live
+// clients and, with audit_log on, appended to the audit log.
+func (f *Farmer) onActivity(a points.Activity) {
+	f.publish(PushKindActivity, a)
+	if f.audit == nil || !f.cfg.GetAuditLog() {
+		return
+	}
+	e := audit.Entry{
+		Time:      a.At,
+		Kind:      a.Kind,
+		ChannelID: a.ChannelID,
+		Channel:   a.Channel,
+		Detail:    a.Detail,
+		Points:    a.Points,
+		Result:    audit.ResultOK,
+		Error:     a.Error,
+	}
+	if a.Error != "" {
+		e.Result = audit.ResultError
+	}
+	if err := f.audit.Append(e); err != nil {
+		f.logWarn("[Audit] Warning: could not write %s: %v", f.audit.Path(), err)
+	}
+}
+
+// Audit returns the audit log entries matching q, newest first.
+func (f *Farmer) Audit(q audit.Query) ([]audit.Entry, error) {
+	if f.audit == nil {
+		return nil, ErrAuditDisabled
+	}
+	return f.audit.Read(q)
+}
@@ -0,0 +1,104 @@
+package farmer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+// Breakdown ranges (the range parameter of /api/stats/breakdown).
+const (
+	RangeSession = "session"
+	RangeToday   = "today"
+	RangeWeek    = "week" // the last 7 days
+	RangeAll     = "all"
+)
+
+// reasonSummaryTTL is how long GetReasonSummary reuses its queries: the
+// TUI renders (and /api/tui polls) far more often than it changes.
+const reasonSummaryTTL = 30 * time.Second
+
+// ReasonSummary is the points earned by reason code — WATCH, CLAIM,
+// WATCH_STREAK, RAID, ... — overall and per channel, this session,
+// today, over the last 7 days and across all of history.
+type ReasonSummary struct {
+	Session history.Breakdown
+	Today   history.Breakdown
+	Week    history.Breakdown
+	All     history.Breakdown
+	Error   string // why there is no breakdown (history.db not open)
+}
+
+// reasonCache holds the last ReasonSummary.
+type reasonCache struct {
+	mu  sync.Mutex
+	at  time.Time
+	sum ReasonSummary
+}
+
+// Breakdown totals the points earned in q's range by reason code,
+// overall and per channel.
+func (f *Farmer) Breakdown(q history.Query) (history.Breakdown, error) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if f.history == nil {
+		return history.Breakdown{}, ErrHistoryDisabled
+	}
+	return f.history.Breakdown(q)
+}
+
+// RangeStart returns where range rng starts (zero for RangeAll).
+func (f *Farmer) RangeStart(rng string, now time.Time) (time.Time, error) {
+	switch rng {
+	case RangeSession:
+		return f.startTime, nil
+	case RangeToday:
+		return startOfLocalDay(now), nil
+	case RangeWeek:
+		return now.AddDate(0, 0, -7), nil
+	case RangeAll:
+		return time.Time{}, nil
+	}
+	return time.Time{}, fmt.Errorf("unknown range %q (want session, today, week or all)", rng)
+}
+
+// GetReasonSummary returns the reason breakdowns the Stats tab shows,
+// queried at most every reasonSummaryTTL.
+func (f *Farmer) GetReasonSummary() ReasonSummary {
+	c := &f.reasons
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := time.Now()
+	if !c.at.IsZero() && now.Sub(c.at) < reasonSummaryTTL {
+		return c.sum
+	}
+
+	var sum ReasonSummary
+	for _, r := range []struct {
+		b   *history.Breakdown
+		rng string
+	}{
+		{&sum.Session, RangeSession},
+		{&sum.Today, RangeToday},
+		{&sum.Week, RangeWeek},
+		{&sum.All, RangeAll},
+	} {
+		from, _ := f.RangeStart(r.rng, now)
+		b, err := f.Breakdown(history.Query{From: from})
+		if err != nil {
+			sum = ReasonSummary{Error: err.Error()}
+			break
+		}
+		*r.b = b
+	}
+	c.at, c.sum = now, sum
+	return sum
+}
+
+// startOfLocalDay returns local midnight of t's day.
+func startOfLocalDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.Local)
+}
@@ -0,0 +1,100 @@
+package farmer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/points"
+)
+
+// Practical channel-count ceilings. None of these are enforced — adding
+// more channels still works — but past them something starts silently
+// degrading, so the UI warns before the user crosses one.
+const (
+	// pubsubTopicLimit is Twitch's per-connection LISTEN cap. The
+	// PubSub client shards topics across connections, so the real
+	// ceiling is pubsubMaxConnections of these.
+	pubsubTopicLimit = 50
+	// pubsubMaxConnections is Twitch's recommended per-IP connection
+	// limit. Past it new connections get rate-limited and the channels
+	// on them miss stream-up/down and raid events.
+	pubsubMaxConnections = 10
+	// pubsubUserTopics: community-points-user, user-drop-events and
+	// community-momento-user (subscribed once at Start).
+	pubsubUserTopics = 3
+	// pubsubTopicsPerChannel: video-playback-by-id + raid +
+	// hype-train-events-v1. The Hype Train topic alone cuts the PubSub
+	// ceiling by a third (two topics per channel without it).
+	pubsubTopicsPerChannel = 3
+	// tempChannelReserve keeps room for the drops selector's temporary
+	// channel so a full config doesn't starve the drop pick.
+	tempChannelReserve = 1
+
+	// gqlRequestCost approximates one claim-sweep step per channel
+	// (500ms inter-channel sleep + a points-context request). Once a
+	// full walk takes longer than the sweep interval the sweep can no
+	// longer keep up. The balance refresh is batched and costs a few
+	// requests for the whole list.
+	gqlRequestCost         = 750 * time.Millisecond
+	capacityWarnPercentage = 80
+)
+
+// Capacity describes how many channels the farmer can track before one
+// of Twitch's limits starts dropping events.
+type Capacity struct {
+	Channels       int    // currently tracked (configured + temporary)
+	Max            int    // practical ceiling across all subsystems
+	LimitingFactor string // "pubsub" or "gql" — whichever caps Max
+	Reason         string // human-readable explanation of LimitingFactor
+	NearLimit      bool   // Channels >= capacityWarnPercentage% of Max
+	OverLimit      bool   // Channels > Max
+}
+
+// computeCapacity returns the capacity for the given tracked-channel
+// count. IRC doesn't cap it: the client queues JOINs within Twitch's
+// rate limit.
+func computeCapacity(tracked int) Capacity {
+	c := Capacity{
+		Channels:       tracked,
+		Max:            (pubsubMaxConnections*pubsubTopicLimit-pubsubUserTopics)/pubsubTopicsPerChannel - tempChannelReserve,
+		LimitingFactor: "pubsub",
+		Reason: fmt.Sprintf("PubSub allows %d connections of %d topics (%d user topics + %d per channel, 1 slot reserved for drops)",
+			pubsubMaxConnections, pubsubTopicLimit, pubsubUserTopics, pubsubTopicsPerChannel),
+	}
+	if gqlMax := int(points.ClaimSweepInterval / gqlRequestCost); gqlMax < c.Max {
+		c.Max = gqlMax
+		c.LimitingFactor = "gql"
+		c.Reason = fmt.Sprintf("claim sweep can't walk more than %d channels per %v without hitting GQL rate limits", gqlMax, points.ClaimSweepInterval)
+	}
+	c.NearLimit = c.Max > 0 && tracked*100 >= c.Max*capacityWarnPercentage
+	c.OverLimit = tracked > c.Max
+	return c
+}
+
+// GetCapacity reports the current channel count against the practical
+// channel limit.
+func (f *Farmer) GetCapacity() Capacity {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.capacity()
+}
+
+func (f *Farmer) capacity() Capacity {
+	return computeCapacity(len(f.channels.Snapshots()))
+}
+
+// CapacityWarning returns a user-facing warning when the tracked channel
+// count exceeds the practical limit, or "" when within it.
+func (f *Farmer) CapacityWarning() string {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.capacityWarning()
+}
+
+func (f *Farmer) capacityWarning() string {
+	c := f.capacity()
+	if !c.OverLimit {
+		return ""
+	}
+	return fmt.Sprintf("%d channels exceeds the practical limit of %d — %s", c.Channels, c.Max, c.Reason)
+}
@@ -0,0 +1,102 @@
+package farmer
+
+import (
+	"math"
+	"time"
+)
+
+// PeriodStats is one side of a Comparison. The per-hour rates are over
+// the time actually farmed, so a partial day compares fairly with a
+// full one.
+type PeriodStats struct {
+	Date               string  `json:"date,omitempty"` // YYYY-MM-DD for a single day
+	Hours              float64 `json:"hours"`          // time spent farming
+	Points             int     `json:"points"`
+	Claims             int     `json:"claims"`
+	DropMinutes        int     `json:"drop_minutes"`
+	PointsPerHour      float64 `json:"points_per_hour"`
+	ClaimsPerHour      float64 `json:"claims_per_hour"`
+	DropMinutesPerHour float64 `json:"drop_minutes_per_hour"`
+}
+
+// Comparison sets today against yesterday and this session against the
+// average of the past week, to make a drop in earnings (a Twitch-side
+// change breaking something) stand out.
+type Comparison struct {
+	Today     PeriodStats `json:"today"`
+	Yesterday PeriodStats `json:"yesterday"` // zero when the farmer didn't run yesterday
+	Session   PeriodStats `json:"session"`
+	// WeekAverage is the per-day average over the past WeekDays days
+	// (up to 7, today excluded) the farmer ran on.
+	WeekAverage PeriodStats `json:"week_average"`
+	WeekDays    int         `json:"week_days"`
+}
+
+// GetComparison returns the current Comparison, as of the last daily
+// sample (at most dailySampleInterval old).
+func (f *Farmer) GetComparison() Comparison {
+	d := &f.daily
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	today := d.today
+	if date := localDate(now); today.Date != date {
+		today = DailySummary{Date: date}
+	}
+	cmp := Comparison{
+		Today:   periodStats(today),
+		Session: periodStats(d.session),
+	}
+	cmp.Session.Date = ""
+
+	yesterday := localDate(now.AddDate(0, 0, -1))
+	weekStart := localDate(now.AddDate(0, 0, -dailyKeepDays))
+	var week, timed DailySummary // timed: the days with farming time
+	for _, day := range d.past {
+		if day.Date == yesterday {
+			cmp.Yesterday = periodStats(day)
+		}
+		if day.Date >= weekStart && day.Date < today.Date {
+			week.add(day)
+			cmp.WeekDays++
+			if day.Seconds > 0 {
+				timed.add(day)
+			}
+		}
+	}
+	if cmp.WeekDays > 0 {
+		avg := periodStats(timed)
+		n := float64(cmp.WeekDays)
+		avg.Hours = round1(avg.Hours / n)
+		avg.Points = int(math.Round(float64(week.Points) / n))
+		avg.Claims = int(math.Round(float64(week.Claims) / n))
+		avg.DropMinutes = int(math.Round(float64(week.DropMinutes) / n))
+		cmp.WeekAverage = avg
+	}
+	return cmp
+}
+
+// periodStats derives the rates of s. Days from before the history
+// recorded farming time have no hours and so no rates; GetComparison
+// leaves them out of the weekly rates.
+func periodStats(s DailySummary) PeriodStats {
+	p := PeriodStats{
+		Date:        s.Date,
+		Hours:       round1(float64(s.Seconds) / 3600),
+		Points:      s.Points,
+		Claims:      s.Claims,
+		DropMinutes: s.DropMinutes,
+	}
+	if s.Seconds > 0 {
+		hours := float64(s.Seconds) / 3600
+		p.PointsPerHour = round1(float64(s.Points) / hours)
+		p.ClaimsPerHour = round1(float64(s.Claims) / hours)
+		p.DropMinutesPerHour = round1(float64(s.DropMinutes) / hours)
+	}
+	return p
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
@@ -0,0 +1,36 @@
+package farmer
+
+import "time"
+
+// ChannelConnections is the presence plumbing active for one channel
+// right now.
+type ChannelConnections struct {
+	PubSub        bool      // every PubSub topic of the channel confirmed by Twitch
+	EventSub      bool      // stream status comes from EventSub instead of PubSub
+	IRC           bool      // chat joined, JOIN echoed back by the server
+	Spade         bool      // minute-watched heartbeats are being sent
+	LastHeartbeat time.Time // last heartbeat sent, accepted or not; zero if none
+}
+
+// ChannelConnections reports which of PubSub, EventSub, IRC and Spade
+// are active for a tracked channel. All false before Start.
+func (f *Farmer) ChannelConnections(channelID, login string) ChannelConnections {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	var c ChannelConnections
+	if f.pubsub != nil {
+		c.PubSub = f.pubsub.OwnerSubscribed(channelID)
+	}
+	f.transport.mu.Lock()
+	c.EventSub = f.transport.onEventSub[channelID]
+	f.transport.mu.Unlock()
+	if irc := f.irc.Load(); irc != nil {
+		c.IRC = irc.Joined(login)
+	}
+	if f.spade != nil {
+		if h, ok := f.spade.Health(channelID); ok {
+			c.Spade, c.LastHeartbeat = true, h.LastSent
+		}
+	}
+	return c
+}
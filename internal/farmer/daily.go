@@ -0,0 +1,219 @@
+package farmer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+const (
+	// dailySampleInterval is how often the session counters are folded
+	// into today's tally.
+	dailySampleInterval = 30 * time.Second
+	// dailyRecordInterval is how often the farming time and drop
+	// minutes sampled since the last write go to the history, as one
+	// event each. Points and claims are recorded there as they happen.
+	dailyRecordInterval = 5 * time.Minute
+	// dailyKeepDays is how many finished days the comparison view
+	// looks back.
+	dailyKeepDays = 7
+)
+
+// DailySummary is the running tally for one local calendar day.
+type DailySummary struct {
+	Date        string `json:"date"` // YYYY-MM-DD, local time
+	Points      int    `json:"points"`
+	Claims      int    `json:"claims"`
+	DropMinutes int    `json:"drop_minutes"`
+	Seconds     int    `json:"seconds,omitempty"` // time spent farming (running, not paused)
+}
+
+// dailyState folds the session counters (which start at zero on every
+// launch) into a per-day tally that resets at local midnight. The days
+// live in the earnings history; this keeps today and the past week in
+// memory for the header and the comparison view.
+type dailyState struct {
+	mu         sync.Mutex
+	today      DailySummary
+	past       []DailySummary // finished days, oldest first, at most dailyKeepDays
+	session    DailySummary   // this launch's tally (Date unused)
+	unrecorded DailySummary   // farming time and drop minutes not yet in the history
+	lastRecord time.Time
+	lastSample time.Time
+	lastPoints int            // points.TotalPointsEarned at the previous sample
+	lastClaims int            // points.TotalClaimsMade at the previous sample
+	lastDrop   map[string]int // campaignID/dropName -> Progress at the previous sample
+}
+
+func localDate(t time.Time) string {
+	return t.Format("2006-01-02")
+}
+
+// initDaily loads today's tally so far and the past week from the
+// history. Without one (history.db couldn't be opened) every start
+// begins the day at zero.
+func (f *Farmer) initDaily() {
+	d := &f.daily
+	now := time.Now()
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.lastDrop = make(map[string]int)
+	d.lastSample, d.lastRecord = now, now
+	d.unrecorded = DailySummary{}
+	d.today = DailySummary{Date: localDate(now)}
+	d.past = nil
+
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	days, err := f.history.Days(midnight.AddDate(0, 0, -dailyKeepDays), midnight.AddDate(0, 0, 1))
+	if err != nil {
+		f.debugLog("[Daily] Could not read the past days from the history: %v", err)
+	}
+	for _, day := range days {
+		sum := DailySummary{
+			Date:        localDate(day.Day),
+			Points:      day.Points,
+			Claims:      day.Claims,
+			DropMinutes: day.DropMinutes,
+			Seconds:     day.Seconds,
+		}
+		if sum.Date == d.today.Date {
+			d.today = sum
+		} else {
+			d.finishDayLocked(sum)
+		}
+	}
+}
+
+// finishDayLocked adds a day that has ended to the past days, dropping
+// the oldest beyond dailyKeepDays. Caller holds d.mu.
+func (d *dailyState) finishDayLocked(day DailySummary) {
+	if day.Date == "" || (day.Points == 0 && day.Claims == 0 && day.DropMinutes == 0 && day.Seconds == 0) {
+		return
+	}
+	d.past = append(d.past, day)
+	if len(d.past) > dailyKeepDays {
+		d.past = d.past[len(d.past)-dailyKeepDays:]
+	}
+}
+
+// dailyLoop samples the session counters every dailySampleInterval.
+// Stop takes the final sample itself so it lands before the process
+// exits.
+func (f *Farmer) dailyLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(dailySampleInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.sampleDaily(false)
+		case <-stop:
+			return
+		}
+	}
+}
+
+// sampleDaily adds everything earned since the previous sample to
+// today's tally, and writes the farming time and drop minutes to the
+// history every dailyRecordInterval — or now, with flush.
+func (f *Farmer) sampleDaily(flush bool) {
+	if f.daily.lastDrop == nil {
+		return // Start never ran
+	}
+	earned := f.points.TotalPointsEarned()
+	claims := f.points.TotalClaimsMade()
+	rows := f.drops.GetActiveDrops()
+	paused := f.IsPaused()
+	now := time.Now()
+
+	d := &f.daily
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if date := localDate(now); d.today.Date != date {
+		// What was sampled before midnight belongs to the day that ended.
+		f.recordDailyLocked(d.lastSample)
+		d.finishDayLocked(d.today)
+		d.today = DailySummary{Date: date}
+	}
+	delta := DailySummary{
+		Points:      earned - d.lastPoints,
+		Claims:      claims - d.lastClaims,
+		DropMinutes: d.dropMinutesLocked(rows),
+	}
+	// Time farmed: the gap since the previous sample unless paused. A
+	// gap much longer than the interval means the machine slept.
+	if !d.lastSample.IsZero() && !paused {
+		gap := now.Sub(d.lastSample)
+		if gap > 2*dailySampleInterval {
+			gap = dailySampleInterval
+		}
+		delta.Seconds = int(gap.Round(time.Second).Seconds())
+	}
+	d.today.add(delta)
+	d.session.add(delta)
+	d.unrecorded.add(DailySummary{DropMinutes: delta.DropMinutes, Seconds: delta.Seconds})
+	d.lastPoints, d.lastClaims, d.lastSample = earned, claims, now
+	if flush || now.Sub(d.lastRecord) >= dailyRecordInterval {
+		f.recordDailyLocked(now)
+	}
+}
+
+// recordDailyLocked writes the farming time and drop minutes not yet
+// recorded to the history, as of at. Caller holds f.daily.mu.
+func (f *Farmer) recordDailyLocked(at time.Time) {
+	d := &f.daily
+	if d.unrecorded.Seconds > 0 {
+		f.recordHistory(history.Event{Time: at, Kind: history.KindFarmed, Points: d.unrecorded.Seconds})
+	}
+	if d.unrecorded.DropMinutes > 0 {
+		f.recordHistory(history.Event{Time: at, Kind: history.KindDropMinutes, Points: d.unrecorded.DropMinutes})
+	}
+	d.unrecorded = DailySummary{}
+	d.lastRecord = at
+}
+
+func (s *DailySummary) add(o DailySummary) {
+	s.Points += o.Points
+	s.Claims += o.Claims
+	s.DropMinutes += o.DropMinutes
+	s.Seconds += o.Seconds
+}
+
+// dropMinutesLocked returns the drop minutes watched since the previous
+// sample across the actively farmed campaigns. A drop seen for the
+// first time only sets the baseline; progress going backwards (next
+// drop of the campaign) resets it. Caller holds d.mu.
+func (d *dailyState) dropMinutesLocked(rows []drops.ActiveDrop) int {
+	minutes := 0
+	seen := make(map[string]bool, len(rows))
+	for _, r := range rows {
+		if r.Status != "ACTIVE" {
+			continue
+		}
+		key := r.CampaignID + "/" + r.DropName
+		seen[key] = true
+		if prev, ok := d.lastDrop[key]; ok && r.Progress > prev {
+			minutes += r.Progress - prev
+		}
+		d.lastDrop[key] = r.Progress
+	}
+	for key := range d.lastDrop {
+		if !seen[key] {
+			delete(d.lastDrop, key)
+		}
+	}
+	return minutes
+}
+
+// GetDailySummary returns today's tally. Past local midnight it reports
+// an empty day until the next sample rolls the day over.
+func (f *Farmer) GetDailySummary() DailySummary {
+	f.daily.mu.Lock()
+	defer f.daily.mu.Unlock()
+	if date := localDate(time.Now()); f.daily.today.Date != date {
+		return DailySummary{Date: date}
+	}
+	return f.daily.today
+}
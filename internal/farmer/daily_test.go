@@ -0,0 +1,76 @@
+package farmer
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+// newDailyTestFarmer returns a farmer whose earnings history holds
+// events, as Start would have opened it.
+func newDailyTestFarmer(t *testing.T, events ...history.Event) *Farmer {
+	t.Helper()
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	for _, e := range events {
+		if err := store.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	return &Farmer{history: store}
+}
+
+// TestComparison_FromHistory: today's tally and the past days come from
+// the earnings history, so they survive a restart.
+func TestComparison_FromHistory(t *testing.T) {
+	now := time.Now()
+	midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	yesterday := midnight.AddDate(0, 0, -1).Add(12 * time.Hour)
+	older := midnight.AddDate(0, 0, -3).Add(12 * time.Hour)
+	f := newDailyTestFarmer(t,
+		history.Event{Time: yesterday, Login: "alpha", Kind: history.KindEarned, Points: 200},
+		history.Event{Time: yesterday, Login: "alpha", Kind: history.KindClaim},
+		history.Event{Time: yesterday, Kind: history.KindFarmed, Points: 7200},
+		history.Event{Time: yesterday, Kind: history.KindDropMinutes, Points: 60},
+		history.Event{Time: older, Login: "alpha", Kind: history.KindEarned, Points: 100}, // before farming time was recorded
+		history.Event{Time: midnight.AddDate(0, 0, -30), Login: "alpha", Kind: history.KindEarned, Points: 9999},
+		history.Event{Time: now, Login: "alpha", Kind: history.KindEarned, Points: 50},
+	)
+	f.initDaily()
+
+	if got := f.GetDailySummary(); got.Points != 50 || got.Date != localDate(now) {
+		t.Errorf("today = %+v, want 50 points", got)
+	}
+	cmp := f.GetComparison()
+	y := cmp.Yesterday
+	if y.Points != 200 || y.Claims != 1 || y.DropMinutes != 60 || y.Hours != 2 || y.PointsPerHour != 100 {
+		t.Errorf("yesterday = %+v", y)
+	}
+	if cmp.WeekDays != 2 || cmp.WeekAverage.Points != 150 || cmp.WeekAverage.PointsPerHour != 100 {
+		t.Errorf("week average over %d days = %+v", cmp.WeekDays, cmp.WeekAverage)
+	}
+}
+
+// TestRecordDaily: farming time and drop minutes reach the history and
+// count again after a restart; nothing is written when there is none.
+func TestRecordDaily(t *testing.T) {
+	f := newDailyTestFarmer(t)
+	f.initDaily()
+
+	now := time.Now()
+	f.daily.mu.Lock()
+	f.daily.unrecorded = DailySummary{Seconds: 90, DropMinutes: 2}
+	f.recordDailyLocked(now)
+	f.recordDailyLocked(now)
+	f.daily.mu.Unlock()
+
+	f.initDaily() // restart
+	if got := f.GetDailySummary(); got.Seconds != 90 || got.DropMinutes != 2 {
+		t.Errorf("today after restart = %+v, want 90s and 2 drop minutes", got)
+	}
+}
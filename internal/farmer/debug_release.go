@@ -2,14 +2,12 @@
 
 package farmer
 
-import "fmt"
+import "log/slog"
 
-// debugLog writes per-cycle/heartbeat noise (prober ticks, drops watch
-// updates, raw WS payloads) to the daily debug log file only — silent in
-// the live UI feed.
+// defaultLogLevel is the UI feed's level when log_level is unset:
+// per-cycle/heartbeat noise (debugLog — prober ticks, drops watch
+// updates, raw WS payloads) goes to the daily debug log file only.
 //
 // Build with `-tags=debug` to surface these in the UI instead — useful
 // for diagnosing pick/credit issues, otherwise too chatty.
-func (f *Farmer) debugLog(format string, args ...any) {
-	f.writeLogFile(fmt.Sprintf(format, args...))
-}
+const defaultLogLevel = slog.LevelInfo
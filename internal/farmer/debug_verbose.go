@@ -2,9 +2,9 @@
 
 package farmer
 
-// debugLog (debug build) — same noise as the release path but routed
-// through addLog so every probe/heartbeat shows up in the live UI feed.
+import "log/slog"
+
+// defaultLogLevel (debug build) — every probe/heartbeat shows up in the
+// live UI feed unless log_level says otherwise.
 // Build with `go build -tags=debug` to enable.
-func (f *Farmer) debugLog(format string, args ...any) {
-	f.addLog(format, args...)
-}
+const defaultLogLevel = slog.LevelDebug
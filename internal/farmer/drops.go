@@ -2,17 +2,24 @@ package farmer
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/drops"
 )
 
+// dropArchiveFileName sits next to config.json and holds the records of
+// the campaigns being farmed and the completed-campaigns archive.
+const dropArchiveFileName = "drops_archive.json"
+
 // SetCampaignEnabled enables or disables a drop campaign and triggers an
 // immediate inventory re-evaluation so the selector picks up the change.
 func (f *Farmer) SetCampaignEnabled(campaignID string, enabled bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	f.cfg.SetCampaignEnabled(campaignID, enabled)
-	if err := f.cfg.Save(); err != nil {
-		return fmt.Errorf("save config: %w", err)
-	}
+	f.cfg.SaveSoon()
 
 	if enabled {
 		f.addLog("[Drops] Enabled campaign %s", campaignID)
@@ -24,16 +31,176 @@ func (f *Farmer) SetCampaignEnabled(campaignID string, enabled bool) error {
 	return nil
 }
 
+// SetCampaignOptIn opts a campaign in (or out) from the campaign browser.
+// Opted-in campaigns bypass the wanted-games whitelist, so the selector
+// can pick a live channel for them even without prior progress. Kicks
+// an immediate inventory re-evaluation like SetCampaignEnabled.
+func (f *Farmer) SetCampaignOptIn(campaignID string, optIn bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	f.cfg.SetCampaignOptIn(campaignID, optIn)
+	f.cfg.SaveSoon()
+
+	if optIn {
+		f.addLog("[Drops] Opted in to campaign %s", campaignID)
+	} else {
+		f.addLog("[Drops] Opted out of campaign %s", campaignID)
+	}
+
+	go f.drops.ProcessDrops()
+	return nil
+}
+
+// SetDropAutoSelect changes the global drop auto-select mode and
+// re-runs selection so a now-disallowed pick is dropped right away.
+func (f *Farmer) SetDropAutoSelect(mode string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.SetDropAutoSelect(mode) {
+		return config.CheckDropAutoSelect(mode)
+	}
+	f.cfg.SaveSoon()
+	f.addLog("[Drops] Auto-select mode: %s", f.cfg.GetDropAutoSelect())
+
+	go f.drops.ProcessDrops()
+	return nil
+}
+
+// SetDropMinProgressPercent sets drop_min_progress_percent, saves the
+// config and re-runs drop selection so the pool reflects it right away.
+func (f *Farmer) SetDropMinProgressPercent(pct int) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.SetDropMinProgressPercent(pct) {
+		return config.CheckDropMinProgressPercent(pct)
+	}
+	f.cfg.SaveSoon()
+	if pct > 0 {
+		f.addLog("[Drops] Only campaigns with at least %d%% progress are farmed", pct)
+	} else {
+		f.addLog("[Drops] Minimum campaign progress: off")
+	}
+
+	go f.drops.ProcessDrops()
+	return nil
+}
+
+// SetDropCheckMinutes sets the base interval of the drops inventory
+// check (0 = default), saves the config and re-times the next check.
+func (f *Farmer) SetDropCheckMinutes(m int) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.SetDropCheckMinutes(m) {
+		return config.CheckDropCheckMinutes(m)
+	}
+	f.cfg.SaveSoon()
+	if m > 0 {
+		f.addLog("[Drops] Inventory check every %d min (adapted to drop progress)", m)
+	} else {
+		f.addLog("[Drops] Inventory check interval: default")
+	}
+	if f.drops != nil {
+		f.drops.Replan()
+	}
+	return nil
+}
+
+// SetIrcSkipTempChannels toggles IRC presence for temporary drop
+// channels and re-syncs the IRC join list right away.
+func (f *Farmer) SetIrcSkipTempChannels(skip bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	f.cfg.SetIrcSkipTempChannels(skip)
+	f.cfg.SaveSoon()
+	if skip {
+		f.addLog("[Drops] Temporary drop channels: no IRC presence")
+	} else {
+		f.addLog("[Drops] Temporary drop channels: IRC presence on")
+	}
+	f.points.SyncIRC()
+	return nil
+}
+
+// SetBlacklist replaces the auto-selection blacklist (logins and game
+// names), saves the config and re-runs drop selection so a blacklisted
+// temporary channel is dropped right away.
+func (f *Farmer) SetBlacklist(entries []string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	f.cfg.SetBlacklist(entries)
+	f.cfg.SaveSoon()
+	if list := f.cfg.GetBlacklist(); len(list) > 0 {
+		f.addLog("[Drops] Blacklist: %s", strings.Join(list, ", "))
+	} else {
+		f.addLog("[Drops] Blacklist cleared")
+	}
+
+	if f.cfg.GetDropsEnabled() {
+		go f.drops.ProcessDrops()
+	}
+	return nil
+}
+
+// SetCampaignAutoSelect sets or clears ("") a campaign's auto-select
+// override.
+func (f *Farmer) SetCampaignAutoSelect(campaignID, mode string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.SetCampaignAutoSelect(campaignID, mode) {
+		return fmt.Errorf("unknown auto-select mode %q (want off, allowed, directory or empty)", mode)
+	}
+	f.cfg.SaveSoon()
+	if override := f.cfg.GetCampaignAutoSelectOverride(campaignID); override != "" {
+		f.addLog("[Drops] Auto-select for campaign %s: %s", campaignID, override)
+	} else {
+		f.addLog("[Drops] Auto-select for campaign %s follows the global mode", campaignID)
+	}
+
+	go f.drops.ProcessDrops()
+	return nil
+}
+
+// GetAvailableCampaigns returns campaigns the account is eligible for
+// but has no progress on yet — the web campaign browser's data source.
+func (f *Farmer) GetAvailableCampaigns() []drops.AvailableCampaign {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.drops.GetAvailableCampaigns()
+}
+
 // GetActiveDrops returns drop UI rows in display order — public API
 // surface used by the web /api/drops endpoint and the TUI.
 func (f *Farmer) GetActiveDrops() []drops.ActiveDrop {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	return f.drops.GetActiveDrops()
 }
 
+// GetDropHistory returns the completed-campaigns archive, newest first;
+// with tracking, the records of the campaigns still being farmed
+// instead.
+func (f *Farmer) GetDropHistory(tracking bool) []drops.CampaignRecord {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if tracking {
+		return f.drops.TrackedCampaigns()
+	}
+	return f.drops.ArchivedCampaigns()
+}
+
+// GetDropPlan returns the watch planner for the current drops rows.
+func (f *Farmer) GetDropPlan() []drops.PlanEntry {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return drops.Plan(f.drops.GetActiveDrops(), time.Now())
+}
+
 // GetEligibleGames returns the unique sorted list of game names from
 // the current cycle's inventory cache. Used as the default
 // autocomplete pool for the wanted-games UI.
 func (f *Farmer) GetEligibleGames() []string {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	return f.drops.GetEligibleGames()
 }
 
@@ -42,6 +209,8 @@ func (f *Farmer) GetEligibleGames() []string {
 // user's current inventory. Returns up to `limit` matching game name
 // strings.
 func (f *Farmer) SearchGameCategories(query string, limit int) ([]string, error) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	if limit <= 0 || limit > 25 {
 		limit = 10
 	}
@@ -0,0 +1,106 @@
+package farmer
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// eventSampleWindow is how many recent events the latency percentiles
+// cover.
+const eventSampleWindow = 1000
+
+// EventLoopMetrics describes how quickly the event loop keeps up: how
+// long recent events waited on the queue and how long handleEvent took
+// for them. A rising handle_p95_ms after adding a feature is the
+// regression this is meant to catch.
+type EventLoopMetrics struct {
+	Handled       uint64  `json:"handled"`        // events since start
+	QueueDepth    int     `json:"queue_depth"`    // events waiting right now
+	QueueCapacity int     `json:"queue_capacity"` // senders block (then drop) past this
+	Samples       int     `json:"samples"`        // recent events the percentiles cover
+	WaitP50Ms     float64 `json:"wait_p50_ms"`
+	WaitP95Ms     float64 `json:"wait_p95_ms"`
+	HandleP50Ms   float64 `json:"handle_p50_ms"`
+	HandleP95Ms   float64 `json:"handle_p95_ms"`
+	HandleMaxMs   float64 `json:"handle_max_ms"`
+	SlowestType   string  `json:"slowest_type,omitempty"` // event type of handle_max_ms
+}
+
+// eventSample is one handled event.
+type eventSample struct {
+	typ    string
+	wait   time.Duration
+	handle time.Duration
+}
+
+// eventStats keeps the last eventSampleWindow samples in a ring.
+type eventStats struct {
+	mu      sync.Mutex
+	handled uint64
+	samples []eventSample
+	next    int
+}
+
+// observe records an event that handleEvent picked up at start and
+// spent handle on.
+func (s *eventStats) observe(evt twitch.FarmerEvent, start time.Time, handle time.Duration) {
+	sm := eventSample{typ: evt.Type.String(), handle: handle}
+	if !evt.Queued.IsZero() && start.After(evt.Queued) {
+		sm.wait = start.Sub(evt.Queued)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handled++
+	if len(s.samples) < eventSampleWindow {
+		s.samples = append(s.samples, sm)
+		return
+	}
+	s.samples[s.next] = sm
+	s.next = (s.next + 1) % eventSampleWindow
+}
+
+// EventLoopMetrics returns the event loop's queue depth and the latency
+// percentiles of recent events.
+func (f *Farmer) EventLoopMetrics() EventLoopMetrics {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	s := &f.eventStats
+	s.mu.Lock()
+	m := EventLoopMetrics{Handled: s.handled, Samples: len(s.samples)}
+	waits := make([]time.Duration, len(s.samples))
+	handles := make([]time.Duration, len(s.samples))
+	for i, sm := range s.samples {
+		waits[i], handles[i] = sm.wait, sm.handle
+		if ms := millis(sm.handle); ms > m.HandleMaxMs || m.SlowestType == "" {
+			m.HandleMaxMs, m.SlowestType = ms, sm.typ
+		}
+	}
+	s.mu.Unlock()
+
+	m.QueueDepth, m.QueueCapacity = len(f.events), cap(f.events)
+	m.WaitP50Ms, m.WaitP95Ms = percentile(waits, 50), percentile(waits, 95)
+	m.HandleP50Ms, m.HandleP95Ms = percentile(handles, 50), percentile(handles, 95)
+	return m
+}
+
+// percentile returns the p-th percentile of d in milliseconds (nearest
+// rank), sorting d in place. Empty input gives 0.
+func percentile(d []time.Duration, p int) float64 {
+	if len(d) == 0 {
+		return 0
+	}
+	sort.Slice(d, func(i, j int) bool { return d[i] < d[j] })
+	i := (len(d)*p + 99) / 100
+	if i > 0 {
+		i--
+	}
+	return millis(d[i])
+}
+
+func millis(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
@@ -0,0 +1,124 @@
+package farmer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+const (
+	// failureLogName is the JSON-lines file in logDir that collects every
+	// failed claim, one twitch.ClaimFailure per line.
+	failureLogName = "claim-failures.jsonl"
+	// maxFailureLogSize is the size past which the failures log is cut
+	// back to its newest keepFailures records.
+	maxFailureLogSize = 4 << 20
+	keepFailures      = 100
+	// maxFailureLine bounds one record when reading the log back
+	// (responses are capped at 16 KB, but JSON escaping grows them).
+	maxFailureLine = 256 << 10
+)
+
+// recordClaimFailure is the GQL client's OnClaimFailure hook: it appends
+// the record to the failures log and notes its ID in the debug log.
+func (f *Farmer) recordClaimFailure(fl twitch.ClaimFailure) {
+	line, err := json.Marshal(fl)
+	if err != nil {
+		f.writeLogFile(fmt.Sprintf("[Failures] Could not encode %s failure: %v", fl.Operation, err))
+		return
+	}
+
+	f.failMu.Lock()
+	defer f.failMu.Unlock()
+	path := filepath.Join(logDir, failureLogName)
+	if err := appendLine(path, line); err != nil {
+		f.writeLogFile(fmt.Sprintf("[Failures] Could not write %s: %v", path, err))
+		return
+	}
+	if info, err := os.Stat(path); err == nil && info.Size() > maxFailureLogSize {
+		if err := trimFailureLog(path); err != nil {
+			f.writeLogFile(fmt.Sprintf("[Failures] Could not trim %s: %v", path, err))
+		}
+	}
+	f.writeLogFile(fmt.Sprintf("[Failures] %s failed (ref %s): %s", fl.Operation, fl.ID, fl.Error))
+}
+
+// RecentClaimFailures returns up to n of the newest failed claims from
+// the failures log, newest first. A missing log means none.
+func (f *Farmer) RecentClaimFailures(n int) ([]twitch.ClaimFailure, error) {
+	f.failMu.Lock()
+	defer f.failMu.Unlock()
+	all, err := readFailureLog(filepath.Join(logDir, failureLogName))
+	if err != nil {
+		return nil, err
+	}
+	out := make([]twitch.ClaimFailure, 0, min(n, len(all)))
+	for i := len(all) - 1; i >= 0 && len(out) < n; i-- {
+		out = append(out, all[i])
+	}
+	return out, nil
+}
+
+func appendLine(path string, line []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// readFailureLog parses the failures log, oldest first. Lines that
+// don't parse (a torn write) are skipped.
+func readFailureLog(path string) ([]twitch.ClaimFailure, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var out []twitch.ClaimFailure
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	sc.Buffer(make([]byte, 64<<10), maxFailureLine)
+	for sc.Scan() {
+		var fl twitch.ClaimFailure
+		if json.Unmarshal(sc.Bytes(), &fl) == nil {
+			out = append(out, fl)
+		}
+	}
+	return out, sc.Err()
+}
+
+// trimFailureLog rewrites the failures log with only its newest
+// keepFailures records.
+func trimFailureLog(path string) error {
+	all, err := readFailureLog(path)
+	if err != nil {
+		return err
+	}
+	if len(all) > keepFailures {
+		all = all[len(all)-keepFailures:]
+	}
+	var buf bytes.Buffer
+	for _, fl := range all {
+		line, err := json.Marshal(fl)
+		if err != nil {
+			return err
+		}
+		buf.Write(line)
+		buf.WriteByte('\n')
+	}
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
@@ -1,63 +1,97 @@
 package farmer
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"path/filepath"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/miwi/twitchpoint/internal/audit"
 	"github.com/miwi/twitchpoint/internal/channels"
 	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/history"
 	"github.com/miwi/twitchpoint/internal/points"
 	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
-// LogEntry represents a single log line in the event log.
-type LogEntry struct {
-	Time    time.Time
-	Message string
-}
-
 // Farmer is the main orchestrator that ties GQL, PubSub, Spade, and IRC together.
 type Farmer struct {
 	cfg     *config.Config
 	version string
+
+	// runMu guards the per-run fields Start and Restart replace (the
+	// clients and services, channels, history, user, stopCh, events,
+	// startTime). Start and Restart hold it for writing; the exported
+	// methods that read them hold it for reading and call no other
+	// method that takes it. The loops of a run go without: teardown
+	// stops them before anything is replaced.
+	runMu sync.RWMutex
+
 	gql        *twitch.GQLClient
 	pubsub     *twitch.PubSubClient
+	eventsub   *twitch.EventSubClient // nil with transport "pubsub"
 	spade      *twitch.SpadeTracker
 	prober     *twitch.StreamProber
 	dropWatch  *drops.Watcher
 	dropProgC  chan drops.ProgressUpdate
-	irc        *twitch.IRCClient
+	irc        atomic.Pointer[twitch.IRCClient] // nil while IRC is off (SetSubsystemEnabled)
 	events     chan twitch.FarmerEvent
 
 	user *twitch.UserInfo
 
 	channels *channels.Registry
 
-	logMu      sync.RWMutex // protects logEntries (UI buffer)
+	logMu      sync.RWMutex // protects logEntries (UI buffer) and logOut writes
 	logEntries []LogEntry
+	logLevel   slog.Level // least severe entry kept in logEntries (log_level)
+	logJSON    bool       // log_format "json" for the debug log file and logOut
+	logOut     io.Writer  // gets the entries logEntries does (headless stdout); see SetLogOutput
 
 	// fileLogMu serializes ALL writes/rotations/closes of logFile.
 	// Many goroutines log concurrently; without this lock writeLogFile
 	// could race against itself (rotation interleaving with a write)
-	// or write to a logFile that Stop() has already closed. The
-	// stopped guard inside writeLogFile uses f.stopped.Load() to
-	// drop late writes silently after shutdown.
+	// or write to a logFile that Shutdown() has already closed. The
+	// logClosed guard inside writeLogFile drops late writes silently
+	// once the file is closed for good.
 	fileLogMu sync.Mutex
-	logFile   *os.File
-	logDate   string // current log file date (YYYY-MM-DD) for rotation
+	logClosed atomic.Bool
+	logFile   logWriter
+	logDate   string        // current log file date (YYYY-MM-DD) for rotation
+	logHealth logFileHealth // write failures / memory-only fallback
+	// openLog opens the debug log for a date; nil means
+	// logs/debug-<date>.log (tests replace it).
+	openLog func(date string) (logWriter, error)
+
+	failMu     sync.Mutex // serializes appends to the claim failures log
+	recordFile *os.File   // pubsub_record_file capture, nil when off
+	tokenCheck tokenCheck // cached /oauth2/validate result for the health checks
 
 	startTime time.Time
-	stopCh    chan struct{}
+	// stopCh is closed when the current run is torn down (Stop or
+	// Restart); loops get it as an argument so a restarted run's new
+	// channel doesn't keep the old loops alive.
+	stopCh chan struct{}
 	// stopped is atomic so Stop() doesn't need a mutex — Farmer no longer
 	// owns any other shared mutable state since Phase 4 moved everything
 	// across to channels.Registry / drops.Service / points.Service.
 	stopped atomic.Bool
+	// started is set once Start has brought every subsystem up; until
+	// then the web server only answers the login endpoints.
+	started atomic.Bool
+
+	// paused is the global Pause/Resume switch, read by the points and
+	// drops services through their Paused deps. Runtime only.
+	paused atomic.Bool
+	// schedule tracks the pauses made by the farming schedule.
+	schedule scheduleState
 
 	// Drops
 	drops *drops.Service
@@ -68,36 +102,113 @@ type Farmer struct {
 	// until it gets moved across batch by batch.
 	points *points.Service
 
+	// Stream-status transport routing (PubSub / EventSub)
+	transport transportState
+
+	// Stream-down events waiting out the network profile's grace
+	streamDown streamDownState
+
+	// Broadcasts already announced (channel_live, live_hook)
+	liveHook liveHookState
+
 	// Update checker
 	update updateState
+
+	// Today's points/claims/drop-minutes tally and the past week (history.db)
+	daily dailyState
+
+	// Points-earned / claim event log (history.db); nil when it
+	// couldn't be opened
+	history *history.Store
+
+	// Config entries that failed to resolve (reloadConfig skips them)
+	unresolved unresolvedChannels
+
+	// Last reason-code breakdown of history (GetReasonSummary)
+	reasons reasonCache
+
+	// Claims and other account actions (audit.jsonl), written while
+	// audit_log is on
+	audit *audit.Log
+
+	// Live-update bus for the web /api/events stream.
+	push pushBus
+
+	// Device-code login started from the web UI
+	login loginState
+	// tokenMu serializes SetAuthToken so two swaps can't interleave
+	// across the clients.
+	tokenMu sync.Mutex
+	// validateIRC checks irc_auth_token; nil means twitch.ValidateToken
+	// (tests replace it).
+	validateIRC func(token string) (*twitch.TokenInfo, error)
+	// ircRetryPending is set while a retry of an irc_auth_token that
+	// couldn't be checked is scheduled (retryIRCLogin).
+	ircRetryPending atomic.Bool
+	// startRun is what Restart starts the new run with; nil means start
+	// (tests replace it).
+	startRun func() error
+	// readIdle reads the idle time for schedule.idle_only; nil means
+	// idle.Time (tests replace it).
+	readIdle func() (time.Duration, error)
+	// validateToken checks the auth token for the health checks
+	// (refreshToken); nil means the GQL client's ValidateToken (tests
+	// replace it).
+	validateToken func(gql *twitch.GQLClient) (*twitch.TokenInfo, error)
+
+	// Queue wait / handling time of recent events (/api/metrics)
+	eventStats eventStats
+
+	// Unknown community-points event types already logged
+	unknownPoints unknownPointsState
+
+	// Stop channels of the subsystems switchable at runtime
+	subsys subsystemState
+
+	// Running / restarting / stopped, for Restart, Health and Done
+	life lifecycle
 }
 
 // New creates a new Farmer from config.
 func New(cfg *config.Config, version string) *Farmer {
-	return &Farmer{
+	f := &Farmer{
 		cfg:      cfg,
 		version:  version,
 		events:   make(chan twitch.FarmerEvent, 100),
 		channels: channels.New(),
 		stopCh:   make(chan struct{}),
+		life:     newLifecycle(),
+		logLevel: logLevelOf(cfg),
+		logJSON:  cfg.GetLogFormat() == config.LogFormatJSON,
 	}
+	cfg.SetSaveErrorHook(func(err error) {
+		f.logWarn("Warning: could not save config: %v", err)
+	})
+	return f
 }
 
-// Start initializes all subsystems and begins farming.
+// Start logs in, initializes all subsystems and begins farming. With
+// startup_delay_seconds the connecting happens later, in the background
+// (see connect); Start itself returns once the login is checked.
 func (f *Farmer) Start() error {
+	f.runMu.Lock()
+	defer f.runMu.Unlock()
+	return f.start()
+}
+
+func (f *Farmer) start() error {
 	f.startTime = time.Now()
 
 	// Open daily debug log file (append mode)
-	if err := os.MkdirAll("logs", 0755); err != nil {
+	if err := os.MkdirAll(logDir, 0755); err != nil {
 		return fmt.Errorf("create logs dir: %w", err)
 	}
-	logPath := fmt.Sprintf("logs/debug-%s.log", time.Now().Format("2006-01-02"))
-	logFile, err := os.OpenFile(logPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	f.fileLogMu.Lock()
+	err := f.openLogFileLocked(time.Now())
+	f.fileLogMu.Unlock()
 	if err != nil {
-		return fmt.Errorf("open %s: %w", logPath, err)
+		return fmt.Errorf("open debug log: %w", err)
 	}
-	f.logFile = logFile
-	f.logDate = time.Now().Format("2006-01-02")
 	f.writeLogFile("=== TwitchPoint Farmer started ===")
 
 	// Initialize GQL client. Reads through accessors — even though
@@ -105,6 +216,7 @@ func (f *Farmer) Start() error {
 	// going through the lock-aware getters keeps the codebase
 	// consistent (no direct field reads outside of Config itself).
 	authToken := f.cfg.GetAuthToken()
+	f.initNetwork()
 	f.gql = twitch.NewGQLClient(authToken)
 	// Route GQL diagnostics through the file logger so they're visible on
 	// Windows (log.Printf is io.Discard'd there). Wrap addLog so the diag
@@ -113,11 +225,20 @@ func (f *Farmer) Start() error {
 		f.writeLogFile(fmt.Sprintf(format, args...))
 	}
 	f.gql.DiagLog = diagSink
+	f.gql.OnClaimFailure = f.recordClaimFailure
 	twitch.SetParseDiagSink(diagSink)
 
 	// Validate auth token by getting user info
 	user, err := f.gql.GetUserInfo()
 	if err != nil {
+		// Close the log so Start can run again once a new token is in
+		// (headless mode waits for a web login and retries).
+		f.fileLogMu.Lock()
+		if f.logFile != nil {
+			f.logFile.Close()
+			f.logFile = nil
+		}
+		f.fileLogMu.Unlock()
 		return fmt.Errorf("auth validation failed: %w", err)
 	}
 	f.user = user
@@ -126,8 +247,13 @@ func (f *Farmer) Start() error {
 
 	// Initialize Spade tracker
 	f.spade = twitch.NewSpadeTracker(user.ID, authToken, f.gql.DeviceID(), f.gql, f.addLog)
+	f.spade.OnHeartbeat = f.onHeartbeat
+	f.spade.Warn = f.logWarn
+	if jitter, ok := f.cfg.GetHeartbeatJitter(); ok {
+		f.spade.SetJitter(jitter)
+	}
 	if err := f.spade.Start(); err != nil {
-		f.addLog("Spade initialization warning: %v", err)
+		f.logWarn("Spade initialization warning: %v", err)
 	}
 
 	// Initialize stream prober — fetches m3u8+chunk for picked channels so
@@ -139,10 +265,13 @@ func (f *Farmer) Start() error {
 	// must skip whatever channel ID Watcher reports as current.
 	f.dropProgC = make(chan drops.ProgressUpdate, 16)
 	f.dropWatch = drops.NewWatcher(f.gql, user.ID, f.dropProgC, f.debugLog)
-	go f.dropProgressLoop()
+	go f.dropProgressLoop(f.dropProgC, f.stopCh)
 
-	// Initialize PubSub
+	// Initialize PubSub, plus EventSub when the transport config uses it
 	f.pubsub = twitch.NewPubSubClient(authToken, f.events)
+	f.pubsub.SetTopicHook(f.onPubSubTopic)
+	f.initPubSubRecording()
+	f.initTransport()
 
 	// Initialize drops Service now that all of its deps exist (gql, spade,
 	// prober, pubsub, watcher, channels registry already populated, log).
@@ -155,28 +284,34 @@ func (f *Farmer) Start() error {
 		Channels:               f.channels,
 		Watcher:                f.dropWatch,
 		Log:                    f.addLog,
+		Warn:                   f.logWarn,
 		WriteLogFile:           f.writeLogFile,
 		RemoveTempChannel:      f.removeTemporaryChannel,
 		AddTempChannelFromInfo: f.addTemporaryChannelFromInfo,
 		// Closure binds late — f.points is constructed AFTER drops, so we
 		// can't pass f.points.Rotate directly here (it would capture nil).
 		TriggerRotation: func() { f.points.Rotate() },
+		OnDropClaimed:   f.onDropClaimed,
+		Paused:          f.paused.Load,
+		ArchivePath:     filepath.Join(filepath.Dir(f.cfg.Path()), dropArchiveFileName),
 	})
 	// Route Selector's reject-diag through the same file-logger sink so we
 	// can see why a wanted-game campaign got filtered out on Windows too.
 	f.drops.Selector.SetDiagSink(diagSink)
 
-	// Subscribe to user-level PubSub topics: community points + v1.8.0 drop events
+	// Subscribe to user-level PubSub topics: community points + v1.8.0 drop
+	// events + Moments callouts
 	if err := f.pubsub.Listen([]string{
 		fmt.Sprintf("community-points-user-v1.%s", user.ID),
 		fmt.Sprintf("user-drop-events.%s", user.ID),
+		fmt.Sprintf("community-momento-user-v1.%s", user.ID),
 	}); err != nil {
-		f.addLog("PubSub user topic error: %v", err)
+		f.logWarn("PubSub user topic error: %v", err)
 	}
 
 	// Initialize IRC for viewer presence
 	if f.cfg.GetIrcEnabled() {
-		f.irc = twitch.NewIRCClient(authToken, user.Login, f.addLog)
+		f.irc.Store(f.newIRCClient(authToken))
 	}
 
 	// Initialize points Service AFTER IRC so we can hand it the (possibly
@@ -185,19 +320,54 @@ func (f *Farmer) Start() error {
 	// sort, IsCurrentPick for offline handling). Phase 4 will move methods
 	// one batch at a time; until then this Service holds state and
 	// dependencies but the logic still runs from Farmer methods.
+	f.initHistory()
+	f.audit = audit.New(filepath.Join(filepath.Dir(f.cfg.Path()), auditFileName))
 	f.points = points.NewService(points.ServiceDeps{
-		Cfg:       f.cfg,
-		GQL:       f.gql,
-		Spade:     f.spade,
-		Prober:    f.prober,
-		IRC:       f.irc,
-		Channels:  f.channels,
-		Drops:     f.drops,
-		DropWatch: f.dropWatch,
-		Log:       f.addLog,
-		DebugLog:  f.debugLog,
+		Cfg:        f.cfg,
+		GQL:        f.gql,
+		Spade:      f.spade,
+		Prober:     f.prober,
+		IRC:        f.irc.Load(),
+		Channels:   f.channels,
+		Drops:      f.drops,
+		DropWatch:  f.dropWatch,
+		History:    f.history,
+		Log:        f.addLog,
+		Warn:       f.logWarn,
+		DebugLog:   f.debugLog,
+		Paused:     f.paused.Load,
+		LoginGone:  f.checkRename,
+		OnActivity: f.onActivity,
 	})
 
+	// A Restart reconnects right away. A fresh start waits out
+	// startup_delay_seconds first, in the background so the UI is up
+	// meanwhile; the farmer stays StateStarting until it connects.
+	if f.life.get() == StateRestarting || f.cfg.GetStartupDelay() <= 0 {
+		f.connect()
+		return nil
+	}
+	stop := f.stopCh
+	go func() {
+		if !f.waitStartupDelay(stop) {
+			return
+		}
+		f.life.run.Lock()
+		defer f.life.run.Unlock()
+		select {
+		case <-stop:
+			return // Shutdown got the lock first
+		default:
+		}
+		f.connect()
+	}()
+	return nil
+}
+
+// connect is the second half of Start: it resolves the configured
+// channels, opens the connections and starts the background loops,
+// then marks the farmer running.
+func (f *Farmer) connect() {
 	// Initialize channels first (stores all PubSub topics before connecting).
 	// resolveChannelsParallel does the GQL lookups concurrently with bounded
 	// fan-out, then we apply any rename/ID-migration to config in one pass
@@ -205,87 +375,106 @@ func (f *Farmer) Start() error {
 	// the startup log stays readable.
 	f.bootstrapChannels(f.cfg.GetChannelEntries())
 
+	// IRC rejoins from the registry after every reconnect rather than its
+	// own join map, so adds/removes made while disconnected are honored.
+	if irc := f.irc.Load(); irc != nil {
+		irc.SetReadyHook(f.points.SyncIRC)
+	}
+	if w := f.capacityWarning(); w != "" {
+		f.logWarn("[Capacity] Warning: %s", w)
+	}
+
 	// Start event loop before PubSub connect so events are processed immediately
-	go f.eventLoop()
+	go f.eventLoop(f.events, f.stopCh)
 
-	// Connect PubSub AFTER all channels are added — subscribes to all topics at once
+	// Connect PubSub AFTER all channels are added — subscribes to all topics at once.
+	// With connect_stagger_seconds set, IRC and drop mining follow one
+	// gap apart instead of all hitting Twitch in the same second.
 	go f.pubsub.Connect()
+	f.connectTransport()
 
 	// Connect IRC for viewer presence
-	if f.irc != nil {
-		go f.irc.Connect()
+	if irc := f.irc.Load(); irc != nil {
+		f.staggered(1, func() { irc.Connect() })
 	}
 
 	// Start periodic balance refresh
 	go f.points.BalanceRefreshLoop(f.stopCh)
 
+	// Every 15 min, sweep for bonus chests PubSub never told us about.
+	// Chests that spawned while offline were already picked up per
+	// channel in addChannelWithInfo
+	go f.points.ClaimSweepLoop(f.stopCh)
+
 	// Start channel rotation (Twitch only credits points for 2 channels at a time)
 	go f.points.RotationLoop(f.stopCh)
 
-	// Start drop mining if enabled. ProcessLoop is the single worker
-	// goroutine that drains drops.processQueue — every trigger source
-	// (CheckLoop ticker, claim handler, game-change debounce,
-	// stream-down, UI/Web toggle) calls drops.ProcessDrops which is
-	// now a non-blocking enqueue. Without ProcessLoop running, kicks
-	// would queue indefinitely.
+	// Start drop mining if enabled (see startDropLoops).
 	if f.cfg.GetDropsEnabled() {
-		f.addLog("Drop mining enabled — checking inventory every 15 min + DropCurrentSession poll every 60s")
-		go f.drops.ProcessLoop(f.stopCh)
-		go f.drops.CheckLoop(f.stopCh)
-		go f.drops.ProgressPollLoop(f.stopCh)
+		every := int(f.drops.CheckInterval().Minutes())
+		f.addLog("Drop mining enabled — checking inventory every %d min (adaptive) + DropCurrentSession poll every 60s", every)
+		f.staggered(2, f.startDropLoops)
 	}
 
 	// Start background update checker
-	go f.updateCheckLoop()
+	if f.cfg.GetUpdateCheckEnabled() {
+		f.startUpdateCheck()
+	}
 
-	return nil
+	// Fold session counters into today's persisted tally
+	f.initDaily()
+	go f.dailyLoop(f.stopCh)
+
+	// Pick up edits to config.json made while running
+	go f.configReloadLoop(f.stopCh)
+
+	// Notify when Twitch stops accepting the token
+	go f.authCheckLoop(f.stopCh)
+
+	// Pause and resume farming on the configured schedule
+	go f.scheduleLoop(f.stopCh)
+
+	// Publish channel snapshot diffs to /api/events subscribers
+	go f.snapshotDiffLoop(f.stopCh)
+
+	f.started.Store(true)
+	f.life.set(StateRunning, "")
 }
 
-// Stop shuts down the farmer. Idempotent — calling twice is a no-op.
-//
-// Shutdown ordering matters: stopped flag is set FIRST so writeLogFile
-// drops late goroutine writes; subsystems get their Close/Stop next so
-// no new events get queued; THEN we hold fileLogMu while writing the
-// final "stopped" line and closing the log file. Holding the mutex
-// during close serializes with any in-flight writeLogFile call that
-// observed stopped=false before our CAS — they finish their write,
-// release the mutex, and we close cleanly without racing.
-func (f *Farmer) Stop() {
-	if !f.stopped.CompareAndSwap(false, true) {
-		return
-	}
-	close(f.stopCh)
+// shutdownTimeout bounds how long Stop waits for claims and other
+// requests in flight before cutting them off.
+const shutdownTimeout = 10 * time.Second
 
-	if f.pubsub != nil {
-		f.pubsub.Close()
-	}
-	if f.irc != nil {
-		f.irc.Close()
-	}
-	if f.spade != nil {
-		f.spade.Stop()
-	}
-	if f.prober != nil {
-		f.prober.StopAll()
-	}
-	if f.dropWatch != nil {
-		f.dropWatch.StopAll()
-	}
+// Stop shuts down the farmer, waiting up to shutdownTimeout for work in
+// flight. Idempotent — calling twice is a no-op.
+func (f *Farmer) Stop() {
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	_ = f.Shutdown(ctx)
+}
 
-	// Drain any in-flight log write, emit the final marker, close.
-	f.fileLogMu.Lock()
-	if f.logFile != nil {
-		line := fmt.Sprintf("[%s] === TwitchPoint Farmer stopped ===\n", time.Now().Format("2006-01-02 15:04:05"))
-		_, _ = f.logFile.WriteString(line)
-		_ = f.logFile.Close()
-		f.logFile = nil
+// Shutdown stops the farmer for good (see teardown): claims and Spade
+// heartbeats already sent get until ctx is done to finish and be
+// recorded, whatever is still running then is cancelled and ctx's
+// error returned. Waits for a Restart in progress first. Idempotent —
+// a second call returns nil at once.
+func (f *Farmer) Shutdown(ctx context.Context) error {
+	if !f.stopped.CompareAndSwap(false, true) {
+		return nil
 	}
-	f.fileLogMu.Unlock()
+	l := &f.life
+	l.run.Lock()
+	defer l.run.Unlock()
+	err := f.teardown(ctx, "stopped")
+	l.set(StateStopped, "")
+	close(l.done)
+	return err
 }
 
-// Done returns a channel that is closed when the farmer stops.
+// Done returns a channel that is closed when the farmer stops for good.
+// A Restart doesn't close it.
 func (f *Farmer) Done() <-chan struct{} {
-	return f.stopCh
+	return f.life.done
 }
 
 // channelResolveResult captures the outcome of a single channel-resolve
@@ -322,8 +511,7 @@ func (f *Farmer) bootstrapChannels(entries []config.ChannelEntry) {
 			continue
 		}
 		if r.renamedTo != "" {
-			f.addLog("Channel renamed: %s → %s (ID: %s)", r.entry.Login, r.renamedTo, r.entry.ID)
-			f.cfg.UpdateChannelLogin(r.entry.ID, r.renamedTo)
+			f.migrateRename(r.entry.ID, r.entry.Login, r.renamedTo)
 			configDirty = true
 		}
 		if r.persistID && r.info != nil {
@@ -332,9 +520,7 @@ func (f *Farmer) bootstrapChannels(entries []config.ChannelEntry) {
 		}
 	}
 	if configDirty {
-		if err := f.cfg.Save(); err != nil {
-			f.addLog("Warning: could not save config after channel resolve: %v", err)
-		}
+		f.cfg.SaveSoon()
 	}
 
 	// Phase 3: register each channel into the Farmer state. Sequential
@@ -342,17 +528,18 @@ func (f *Farmer) bootstrapChannels(entries []config.ChannelEntry) {
 	// network work was the GQL lookup (already done in parallel) so
 	// this loop is fast.
 	for _, r := range results {
+		f.unresolved.done(r.entry.Login, r.err)
 		if r.err != nil {
 			if r.entry.ID == "" {
-				f.addLog("Failed to add channel %s: channel not found on Twitch and no ID stored to recover from a rename — remove via `--remove-channel %s`: %v",
+				f.logError("Failed to add channel %s: channel not found on Twitch and no ID stored to recover from a rename — remove via `twitchpoint channels remove %s`: %v",
 					r.entry.Login, r.entry.Login, r.err)
 			} else {
-				f.addLog("Failed to add channel %s: get channel info: %v", r.entry.Login, r.err)
+				f.logError("Failed to add channel %s: get channel info: %v", r.entry.Login, r.err)
 			}
 			continue
 		}
 		if err := f.addChannelWithInfo(r.info); err != nil {
-			f.addLog("Failed to register channel %s: %v", r.entry.Login, err)
+			f.logError("Failed to register channel %s: %v", r.entry.Login, err)
 		}
 	}
 }
@@ -420,16 +607,17 @@ func (f *Farmer) resolveOneChannel(entry config.ChannelEntry) channelResolveResu
 func (f *Farmer) addChannelWithInfo(info *twitch.ChannelInfo) error {
 	state := channels.NewState(info.Login, info.DisplayName, info.ID)
 	state.Priority = f.cfg.GetPriority(info.Login)
+	state.Paused = f.cfg.IsChannelPaused(info.Login)
 
 	f.channels.Add(state)
 
-	// Subscribe to PubSub topics for this channel
-	topics := []string{
-		fmt.Sprintf("video-playback-by-id.%s", info.ID),
-		fmt.Sprintf("raid.%s", info.ID),
-	}
-	if err := f.pubsub.Listen(topics); err != nil {
-		f.addLog("PubSub subscribe error for %s: %v", info.Login, err)
+	// Subscribe to PubSub topics for this channel. With disable_pubsub
+	// it misses stream up/down, raids and Hype Trains; its live status
+	// stays whatever this lookup saw.
+	if f.cfg.IsChannelFeatureEnabled(info.Login, config.ChannelFeaturePubSub) {
+		if err := f.listenChannelTopics(info.ID); err != nil {
+			f.logWarn("PubSub subscribe error for %s: %v", info.Login, err)
+		}
 	}
 
 	f.points.NotifyChannelAdded(info.Login)
@@ -438,25 +626,41 @@ func (f *Farmer) addChannelWithInfo(info *twitch.ChannelInfo) error {
 	if state.Priority == 1 {
 		priLabel = "PRIORITY"
 	}
+	var off []string
+	for _, feature := range []string{config.ChannelFeatureIRC, config.ChannelFeaturePubSub, config.ChannelFeatureSpade} {
+		if !f.cfg.IsChannelFeatureEnabled(info.Login, feature) {
+			off = append(off, feature)
+		}
+	}
+	if len(off) > 0 {
+		priLabel += ", no " + strings.Join(off, "/")
+	}
 	f.addLog("Added channel: %s (ID: %s) [%s]", info.DisplayName, info.ID, priLabel)
 
 	// Check if live and start watching
 	if info.IsLive {
 		state.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+		state.SetRerun(info.IsRerun())
 		f.addLog("%s is LIVE - %s (%d viewers)", info.DisplayName, info.GameName, info.ViewerCount)
 		f.points.TryStartWatching(state)
 	} else {
 		f.addLog("%s is offline", info.DisplayName)
 	}
 
-	// Fetch initial balance
-	channelLogin := info.Login
+	// Fetch initial balance and claim any bonus chest that spawned
+	// while we weren't running
 	go func() {
-		balance, err := f.gql.GetChannelPointsBalance(channelLogin)
-		if err == nil && balance > 0 {
-			state.SetBalance(balance)
+		claimed, err := f.points.SweepChannel(state)
+		if err != nil {
+			f.debugLog("Initial points context for %s: %v", info.Login, err)
+			return
+		}
+		if balance := state.Snapshot().PointsBalance; balance > 0 {
 			f.addLog("%s balance: %d points", info.DisplayName, balance)
 		}
+		if claimed {
+			f.addLog("%s: claiming bonus chest that appeared while offline", info.DisplayName)
+		}
 	}()
 
 	return nil
@@ -494,6 +698,8 @@ func (f *Farmer) addTemporaryChannel(login, campaignID string) error {
 // mutation. Avoids duplicate GQL calls and ensures the temp channel is
 // only registered when the metadata is provably valid.
 func (f *Farmer) addTemporaryChannelFromInfo(info *twitch.ChannelInfo, campaignID string) error {
+	f.makeTempChannelRoom()
+
 	state := channels.NewState(info.Login, info.DisplayName, info.ID)
 	state.Priority = 2 // temp channels use P2 (drops will promote to P0)
 	state.IsTemporary = true
@@ -502,17 +708,14 @@ func (f *Farmer) addTemporaryChannelFromInfo(info *twitch.ChannelInfo, campaignI
 	f.channels.Add(state)
 
 	// Subscribe to PubSub topics
-	topics := []string{
-		fmt.Sprintf("video-playback-by-id.%s", info.ID),
-		fmt.Sprintf("raid.%s", info.ID),
-	}
-	if err := f.pubsub.Listen(topics); err != nil {
-		f.addLog("[Drops] PubSub subscribe error for temp channel %s: %v", info.Login, err)
+	if err := f.listenChannelTopics(info.ID); err != nil {
+		f.logWarn("[Drops] PubSub subscribe error for temp channel %s: %v", info.Login, err)
 	}
 
 	f.points.NotifyChannelAdded(info.Login)
 
 	state.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+	state.SetRerun(info.IsRerun())
 	f.addLog("[Drops] Auto-added temporary channel: %s (campaign: %s)", info.DisplayName, campaignID)
 	// FIX #3: do NOT start Spade for temp drop channels — applySelectorPick
 	// (the caller of addTemporaryChannel) hands the channel directly to the
@@ -523,6 +726,29 @@ func (f *Farmer) addTemporaryChannelFromInfo(info *twitch.ChannelInfo, campaignI
 	return nil
 }
 
+// makeTempChannelRoom releases temporary channels until one more fits
+// under max_temp_channels — those not holding the drop pick first. The
+// channel being added is the new pick, so it wins over older ones.
+func (f *Farmer) makeTempChannelRoom() {
+	var temps []channels.Snapshot
+	for _, s := range f.channels.Snapshots() {
+		if s.IsTemporary {
+			temps = append(temps, s)
+		}
+	}
+	limit := f.cfg.GetMaxTempChannels()
+	if len(temps) < limit {
+		return
+	}
+	sort.SliceStable(temps, func(i, j int) bool {
+		return !temps[i].HasActiveDrop && temps[j].HasActiveDrop
+	})
+	for _, s := range temps[:len(temps)-limit+1] {
+		f.addLog("[Drops] Temporary channel limit (%d) reached — releasing %s", limit, s.DisplayName)
+		f.removeTemporaryChannel(s.ChannelID)
+	}
+}
+
 // removeTemporaryChannel cleans up a temporary channel without touching config.
 func (f *Farmer) removeTemporaryChannel(channelID string) {
 	ch, ok := f.channels.Remove(channelID)
@@ -535,53 +761,98 @@ func (f *Farmer) removeTemporaryChannel(channelID string) {
 	f.spade.StopWatching(channelID)
 	f.prober.Stop(login)
 
-	f.pubsub.Unlisten([]string{
-		fmt.Sprintf("video-playback-by-id.%s", channelID),
-		fmt.Sprintf("raid.%s", channelID),
-	})
+	f.releaseChannelTopics(channelID)
 
 	f.points.NotifyChannelRemoved(login)
 
 	f.addLog("[Drops] Removed temporary channel: %s", displayName)
 }
 
-// AddChannelLive adds a channel at runtime.
-func (f *Farmer) AddChannelLive(login string) error {
-	login = strings.ToLower(login)
+// AddChannelLive adds a channel at runtime. ref is a login, a twitch.tv
+// URL or a channel ID (see twitch.ParseChannelRef).
+func (f *Farmer) AddChannelLive(ref string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.addChannelLive(ref)
+}
+
+func (f *Farmer) addChannelLive(ref string) error {
+	login, id, err := twitch.ParseChannelRef(ref)
+	if err != nil {
+		return err
+	}
+
+	// An ID has to be resolved before the already-added check.
+	var info *twitch.ChannelInfo
+	if id != "" {
+		if info, err = f.gql.ResolveChannel(id); err != nil {
+			return fmt.Errorf("get channel info: %w", err)
+		}
+		login = info.Login
+	}
 
 	if ch, ok := f.channels.GetByLogin(login); ok {
 		// If channel exists as temporary, promote to permanent
 		if ch.Snapshot().IsTemporary {
-			ch.SetIsTemporary(false)
-			f.cfg.AddChannel(login)
-			f.cfg.SetChannelID(login, ch.ChannelID)
-			if err := f.cfg.Save(); err != nil {
-				f.addLog("Warning: could not save config: %v", err)
-			}
-			f.addLog("Promoted temporary channel %s to permanent", ch.DisplayName)
+			f.promoteTemporaryChannel(ch)
 			return nil
 		}
 		return fmt.Errorf("channel %s already added", login)
 	}
 
 	// Resolve channel info first so we have the ID
-	info, err := f.gql.GetChannelInfo(login)
-	if err != nil {
-		return fmt.Errorf("get channel info: %w", err)
+	if info == nil {
+		if info, err = f.gql.GetChannelInfo(login); err != nil {
+			return fmt.Errorf("get channel info: %w", err)
+		}
 	}
 
 	// Save to config with ID
 	f.cfg.AddChannel(info.Login)
 	f.cfg.SetChannelID(info.Login, info.ID)
-	if err := f.cfg.Save(); err != nil {
-		f.addLog("Warning: could not save config: %v", err)
+	f.cfg.SaveSoon()
+
+	if err := f.addChannelWithInfo(info); err != nil {
+		return err
+	}
+	if w := f.capacityWarning(); w != "" {
+		f.logWarn("[Capacity] Warning: %s", w)
 	}
+	return nil
+}
 
-	return f.addChannelWithInfo(info)
+// PromoteChannel turns a temporary drop channel into a configured one,
+// so it stays tracked once the drops selector moves on.
+func (f *Farmer) PromoteChannel(login string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	ch, ok := f.channels.GetByLogin(login)
+	if !ok {
+		return fmt.Errorf("channel %s not found", login)
+	}
+	if !ch.Snapshot().IsTemporary {
+		return fmt.Errorf("channel %s is not temporary", login)
+	}
+	f.promoteTemporaryChannel(ch)
+	return nil
+}
+
+// promoteTemporaryChannel saves a temporary channel to the config and
+// clears its temporary flag.
+func (f *Farmer) promoteTemporaryChannel(ch *channels.State) {
+	ch.SetIsTemporary(false)
+	f.points.NotifyChannelAdded(ch.Login) // joins IRC if it was skipped as a temp
+	f.cfg.AddChannel(ch.Login)
+	f.cfg.SetChannelID(ch.Login, ch.ChannelID)
+	f.cfg.SaveSoon()
+	f.addLog("Promoted temporary channel %s to permanent", ch.DisplayName)
 }
 
 // RemoveChannelLive removes a channel at runtime.
 func (f *Farmer) RemoveChannelLive(login string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	login = strings.ToLower(login)
 
 	ch, ok := f.channels.GetByLogin(login)
@@ -596,33 +867,36 @@ func (f *Farmer) RemoveChannelLive(login string) error {
 		return nil
 	}
 
-	f.channels.Remove(channelID)
-
-	// Stop watching
-	f.spade.StopWatching(channelID)
-	f.prober.Stop(login)
-
-	// Unsubscribe PubSub
-	f.pubsub.Unlisten([]string{
-		fmt.Sprintf("video-playback-by-id.%s", channelID),
-		fmt.Sprintf("raid.%s", channelID),
-	})
-
-	f.points.NotifyChannelRemoved(login)
-
+	f.untrackChannel(ch)
 	f.addLog("Removed channel: %s", ch.DisplayName)
 
 	// Save config
 	f.cfg.RemoveChannel(login)
-	if err := f.cfg.Save(); err != nil {
-		f.addLog("Warning: could not save config: %v", err)
-	}
+	f.cfg.SaveSoon()
 
 	return nil
 }
 
+// untrackChannel drops a permanent channel from the registry, stops
+// watching it and releases its PubSub topics and IRC join. The config
+// is left alone.
+func (f *Farmer) untrackChannel(ch *channels.State) {
+	f.channels.Remove(ch.ChannelID)
+
+	// Stop watching
+	f.spade.StopWatching(ch.ChannelID)
+	f.prober.Stop(ch.Login)
+
+	// Unsubscribe PubSub / EventSub
+	f.releaseChannelTopics(ch.ChannelID)
+
+	f.points.NotifyChannelRemoved(ch.Login)
+}
+
 // SetPriorityLive changes a channel's priority at runtime.
 func (f *Farmer) SetPriorityLive(login string, priority int) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	login = strings.ToLower(login)
 	ch, ok := f.channels.GetByLogin(login)
 	if !ok {
@@ -639,9 +913,7 @@ func (f *Farmer) SetPriorityLive(login string, priority int) error {
 
 	// Save to config
 	f.cfg.SetPriority(login, priority)
-	if err := f.cfg.Save(); err != nil {
-		f.addLog("Warning: could not save config: %v", err)
-	}
+	f.cfg.SaveSoon()
 
 	// Trigger immediate rotation to apply new priority
 	go f.points.Rotate()
@@ -649,15 +921,231 @@ func (f *Farmer) SetPriorityLive(login string, priority int) error {
 	return nil
 }
 
+// SetMomentsEnabledLive toggles Moments auto-claim for a configured
+// channel. Takes effect on the next Moment — no restart needed.
+func (f *Farmer) SetMomentsEnabledLive(login string, enabled bool) error {
+	login = strings.ToLower(login)
+	if !f.cfg.SetMomentsEnabled(login, enabled) {
+		return fmt.Errorf("channel %s not in config", login)
+	}
+	f.cfg.SaveSoon()
+
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	f.addLog("Moments auto-claim for %s: %s", login, state)
+	return nil
+}
+
+// SetPointsGoalLive sets a configured channel's target balance (0
+// clears it) and re-evaluates it against the current balance right away.
+func (f *Farmer) SetPointsGoalLive(login string, goal int) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	if goal < 0 {
+		return fmt.Errorf("goal must not be negative")
+	}
+	if !f.cfg.SetPointsGoal(login, goal) {
+		return fmt.Errorf("channel %s not in config", login)
+	}
+	f.cfg.SaveSoon()
+
+	if goal == 0 {
+		f.addLog("Cleared the points goal for %s", login)
+	} else {
+		f.addLog("Points goal for %s: %d", login, goal)
+	}
+	if ch, ok := f.channels.GetByLogin(login); ok {
+		f.points.CheckGoal(ch)
+	}
+	return nil
+}
+
+// SetMaxPointsLive sets a configured channel's balance cap (0 clears
+// it) and re-checks it, so a channel already over it leaves the rotation
+// right away.
+func (f *Farmer) SetMaxPointsLive(login string, limit int) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	if limit < 0 {
+		return fmt.Errorf("max_points must not be negative")
+	}
+	if !f.cfg.SetMaxPoints(login, limit) {
+		return fmt.Errorf("channel %s not in config", login)
+	}
+	f.cfg.SaveSoon()
+
+	if limit == 0 {
+		f.addLog("Cleared max_points for %s", login)
+	} else {
+		f.addLog("max_points for %s: %d", login, limit)
+	}
+	if ch, ok := f.channels.GetByLogin(login); ok {
+		f.points.CheckGoal(ch)
+	}
+	return nil
+}
+
+// SetChannelModeLive restricts a configured channel to points or drops
+// farming (config.ChannelMode*; "both" clears it) and re-runs rotation
+// and drop selection so the change takes effect right away.
+func (f *Farmer) SetChannelModeLive(login, mode string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	if !f.cfg.HasChannel(login) {
+		return fmt.Errorf("channel %s not in config", login)
+	}
+	if !f.cfg.SetChannelMode(login, mode) {
+		return fmt.Errorf("mode must be both, points or drops")
+	}
+	f.cfg.SaveSoon()
+
+	switch f.cfg.GetChannelMode(login) {
+	case config.ChannelModePoints:
+		f.addLog("%s: points only (never used for drops)", login)
+	case config.ChannelModeDrops:
+		f.addLog("%s: drops only (watched only while it serves a campaign)", login)
+	default:
+		f.addLog("%s: points and drops", login)
+	}
+	go f.points.Rotate()
+	go f.drops.ProcessDrops()
+	return nil
+}
+
+// SetPausedLive pauses or resumes a channel at runtime. A paused channel
+// stays tracked (balance, claims, raids) but is dropped from the watch
+// rotation; pausing frees its Spade slot immediately.
+func (f *Farmer) SetPausedLive(login string, paused bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	ch, ok := f.channels.GetByLogin(login)
+	if !ok {
+		return fmt.Errorf("channel %s not found", login)
+	}
+
+	ch.SetPaused(paused)
+	if paused {
+		f.points.StopWatching(ch)
+		f.addLog("Paused %s", ch.DisplayName)
+	} else {
+		f.addLog("Resumed %s", ch.DisplayName)
+	}
+
+	// Temp drop channels aren't in config — the pause lasts until the
+	// channel is rotated out.
+	if f.cfg.SetChannelPaused(login, paused) {
+		f.cfg.SaveSoon()
+	}
+
+	go f.points.Rotate()
+	return nil
+}
+
+// ForceWatchLive moves a channel into the watch set right now, ahead of
+// the regular rotation order, for one rotation interval.
+func (f *Farmer) ForceWatchLive(login string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	ch, ok := f.channels.GetByLogin(login)
+	if !ok {
+		return fmt.Errorf("channel %s not found", login)
+	}
+	return f.points.ForceWatch(ch)
+}
+
+// Pause stops farming without touching the config — for when the user
+// is watching Twitch themselves. Every Spade heartbeat and the drops
+// Watcher stop, and bonus, Moment and drop claims and raid joins are
+// skipped until Resume (missed chests and drops are picked up by the
+// sweeps afterwards). Balances and go-live tracking keep running. Not
+// persisted: a restart farms again.
+func (f *Farmer) Pause() {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if f.paused.Swap(true) {
+		return
+	}
+	f.drops.Suspend()
+	for _, ch := range f.channels.States() {
+		f.points.StopWatching(ch)
+	}
+	f.addLog("Farming paused")
+}
+
+// Resume ends a Pause: the rotation and the drops check run right away.
+func (f *Farmer) Resume() {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.paused.Swap(false) {
+		return
+	}
+	f.addLog("Farming resumed")
+	go f.points.Rotate()
+	if f.cfg.GetDropsEnabled() {
+		go f.drops.ProcessDrops()
+	}
+}
+
+// IsPaused reports whether farming is paused (Pause).
+func (f *Farmer) IsPaused() bool {
+	return f.paused.Load()
+}
+
+// RotateNow runs the points rotation immediately and restarts its
+// 5-minute countdown.
+func (f *Farmer) RotateNow() {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	f.addLog("Rotation triggered manually")
+	f.points.RotateNow()
+}
+
+// CheckDropsNow runs the drops inventory check immediately and restarts
+// its 15-minute countdown.
+func (f *Farmer) CheckDropsNow() error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.GetDropsEnabled() {
+		return fmt.Errorf("drops are disabled")
+	}
+	f.addLog("Drop check triggered manually")
+	f.drops.CheckNow()
+	return nil
+}
+
+// RefreshChannelLive re-fetches a channel's balance and stream info in
+// the background instead of waiting for the 5-min refresh.
+func (f *Farmer) RefreshChannelLive(login string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	ch, ok := f.channels.GetByLogin(login)
+	if !ok {
+		return fmt.Errorf("channel %s not found", login)
+	}
+	go func() {
+		f.points.RefreshChannel(ch)
+		f.addLog("Refreshed %s: %d points", ch.DisplayName, ch.Snapshot().PointsBalance)
+	}()
+	return nil
+}
+
 // dropProgressLoop drains drops.Watcher progress events and forwards
 // them to drops.Service.ApplyProgressUpdate (which knows how to resolve
 // the drop_id back to a campaign and update the channel state). This
 // loop stays in farmer because it owns the drops.Watcher progress
 // channel — service is the consumer.
-func (f *Farmer) dropProgressLoop() {
+func (f *Farmer) dropProgressLoop(progress <-chan drops.ProgressUpdate, stop <-chan struct{}) {
 	for {
 		select {
-		case ev := <-f.dropProgC:
+		case ev := <-progress:
 			// ApplyProgressUpdate wants (campaign_id, drop_id) — resolve via
 			// the cached inventory.
 			campID := f.drops.LookupCampaignByDropID(ev.DropID)
@@ -670,18 +1158,21 @@ func (f *Farmer) dropProgressLoop() {
 				CurrentMinutesWatched:  ev.CurrentMin,
 				RequiredMinutesWatched: ev.RequiredMin,
 			})
-		case <-f.stopCh:
+		case <-stop:
 			return
 		}
 	}
 }
 
-func (f *Farmer) eventLoop() {
+func (f *Farmer) eventLoop(events <-chan twitch.FarmerEvent, stop <-chan struct{}) {
 	for {
 		select {
-		case evt := <-f.events:
+		case evt := <-events:
+			start := time.Now()
 			f.handleEvent(evt)
-		case <-f.stopCh:
+			f.eventStats.observe(evt, start, time.Since(start))
+			f.publishFarmerEvent(evt)
+		case <-stop:
 			return
 		}
 	}
@@ -693,6 +1184,9 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 	switch evt.Type {
 	case twitch.EventClaimAvailable:
 		data := evt.Data.(twitch.ClaimData)
+		if f.paused.Load() {
+			return // left for the claim sweep after Resume
+		}
 
 		// Dedup — only attempt each claim once.
 		if f.points.SeenClaim(data.ClaimID) {
@@ -708,13 +1202,52 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 
 		f.points.AttemptClaim(evt.ChannelID, data.ClaimID, channelName, ch)
 
+	case twitch.EventMomentAvailable:
+		data := evt.Data.(twitch.MomentData)
+		if f.paused.Load() {
+			return
+		}
+		if f.points.SeenMoment(data.MomentID) {
+			return
+		}
+		// Moments arrive on the user-level topic for every channel, so
+		// untracked channels claim by default (no config entry to opt out).
+		login := ""
+		channelName := evt.ChannelID
+		if ok {
+			login = ch.Login
+			channelName = ch.DisplayName
+		} else if evt.ChannelID != "" {
+			channelName = f.points.ResolveChannelName(evt.ChannelID)
+		}
+		f.points.AttemptMomentClaim(data.MomentID, login, channelName)
+
+	case twitch.EventHypeTrain:
+		if ok {
+			f.points.HandleHypeTrain(ch, evt.Data.(twitch.HypeTrainData))
+		}
+
+	case twitch.EventPointsUnknown:
+		f.handleUnknownPoints(evt)
+
 	case twitch.EventPointsEarned:
 		data := evt.Data.(twitch.PointsData)
 		f.points.RecordPoints(data.PointsGained)
+		hist := history.Event{
+			ChannelID: evt.ChannelID,
+			Kind:      history.KindEarned,
+			Reason:    data.ReasonCode,
+			Points:    data.PointsGained,
+			Balance:   data.TotalPoints,
+		}
 		if ok {
-			ch.AddPointsEarned(data.PointsGained, data.TotalPoints)
+			hist.Login = ch.Login
+			spent := ch.AddPointsEarned(data.PointsGained, data.TotalPoints)
 			f.addLog("+%d points on %s (%s) - Balance: %d",
-				data.PointsGained, ch.DisplayName, data.ReasonCode, data.TotalPoints)
+				data.PointsGained, ch.DisplayName, twitch.ReasonLabel(data.ReasonCode), data.TotalPoints)
+			f.points.RecordSpent(ch, spent)
+			f.points.CheckGoal(ch)
+			go f.points.CheckRedeem(ch)
 
 			// WATCH_STREAK bonus arrived — mark the channel as claimed and
 			// immediately free its Streak-Hunt slot so the next candidate
@@ -726,11 +1259,21 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 		} else {
 			channelName := f.points.ResolveChannelName(evt.ChannelID)
 			f.addLog("+%d points on %s (%s) - Balance: %d",
-				data.PointsGained, channelName, data.ReasonCode, data.TotalPoints)
+				data.PointsGained, channelName, twitch.ReasonLabel(data.ReasonCode), data.TotalPoints)
+		}
+		f.recordHistory(hist)
+
+	case twitch.EventPointsSpent:
+		data := evt.Data.(twitch.PointsData)
+		if ok {
+			f.points.RecordSpent(ch, ch.SetLiveBalance(data.TotalPoints))
 		}
 
 	case twitch.EventStreamUp:
 		if ok {
+			if f.cancelStreamDown(ch.ChannelID) {
+				f.addLog("%s is back up within the stream-down grace, keeping it online", ch.DisplayName)
+			}
 			// Fetch fresh stream info with retry for broadcast ID and game
 			go func() {
 				var broadcastID, gameName string
@@ -740,10 +1283,11 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 					}
 					info, err := f.gql.GetChannelInfo(ch.Login)
 					if err != nil {
-						f.addLog("Error fetching stream info for %s (attempt %d): %v", ch.Login, attempt+1, err)
+						f.logWarn("Error fetching stream info for %s (attempt %d): %v", ch.Login, attempt+1, err)
 						continue
 					}
 					ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+					ch.SetRerun(info.IsRerun())
 					broadcastID = info.BroadcastID
 					gameName = info.GameName
 					if broadcastID != "" && gameName != "" {
@@ -754,43 +1298,15 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 					f.addLog("%s went LIVE but broadcast ID is empty — heartbeats won't work!", ch.DisplayName)
 				} else {
 					f.addLog("%s went LIVE! %s (broadcast=%s)", ch.DisplayName, gameName, broadcastID)
+					f.announceLive(ch)
 				}
-				f.points.TryStartWatching(ch)
+				f.points.HandleStreamUp(ch)
 			}()
 		}
 
 	case twitch.EventStreamDown:
 		if ok {
-			snap := ch.Snapshot()
-			hasDropBefore := snap.HasActiveDrop
-
-			ch.SetOffline()
-			f.spade.StopWatching(ch.ChannelID)
-			f.prober.Stop(ch.Login)
-			f.addLog("%s went OFFLINE", ch.DisplayName)
-
-			// v1.8.0 (per spec section 2): if the picked drop channel just went
-			// offline, trigger an out-of-cycle processDrops so the selector
-			// picks a new drops-enabled channel within seconds instead of
-			// waiting up to 15 minutes for the next inventory cycle.
-			// Non-pick channels go through the normal slot-fill path only.
-			if hasDropBefore {
-				isCurrentPick := f.drops.IsCurrentPick(ch.ChannelID)
-				// FIX: stop the drops Watcher RIGHT NOW for the pick — don't wait
-				// for processDrops to finish (which may hang on a slow Inventory
-				// fetch). Otherwise the Watcher keeps sending sendSpadeEvents
-				// for an offline broadcast for 5-30s, which Twitch interprets
-				// as suspicious activity.
-				if isCurrentPick && f.dropWatch != nil {
-					f.dropWatch.Stop()
-				}
-				if isCurrentPick {
-					go f.drops.ProcessDrops()
-				}
-			}
-
-			// Try to fill freed Spade slot
-			f.points.FillSpadeSlots()
+			f.handleStreamDown(ch)
 		}
 
 	case twitch.EventRaid:
@@ -807,6 +1323,9 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 		}
 
 		f.addLog("Raid detected: %s -> %s", sourceName, data.TargetDisplayName)
+		if f.paused.Load() {
+			return // no raid joins while paused
+		}
 
 		// v1.7.0: raid handling no longer triggers immediate failover — the next
 		// selector cycle (≤5 min) will repick if the source channel has gone
@@ -815,22 +1334,28 @@ func (f *Farmer) handleEvent(evt twitch.FarmerEvent) {
 		_ = ok
 
 		go func() {
+			raid := points.Activity{Kind: points.ActivityRaid, ChannelID: evt.ChannelID, Channel: sourceName, Detail: data.TargetDisplayName}
 			if err := f.gql.JoinRaid(data.RaidID); err != nil {
-				f.addLog("Failed to join raid to %s: %v", data.TargetDisplayName, err)
+				f.logWarn("Failed to join raid to %s: %v", data.TargetDisplayName, err)
+				raid.Error = err.Error()
 			} else {
 				f.addLog("Joined raid to %s!", data.TargetDisplayName)
 			}
+			f.points.RecordActivity(raid)
 		}()
 
 	case twitch.EventViewCount:
 		data := evt.Data.(twitch.ViewCountData)
 		if ok {
 			ch.SetViewerCount(data.Viewers)
+			f.checkInactive(ch)
 		}
 
 	case twitch.EventError:
-		if err, ok := evt.Data.(error); ok {
-			f.addLog("[PubSub] %v", err)
+		if status, ok := evt.Data.(twitch.ConnStatus); ok {
+			f.addLog("[PubSub] %v", status)
+		} else if err, ok := evt.Data.(error); ok {
+			f.logWarn("[PubSub] %v", err)
 		}
 
 	case twitch.EventDropProgress:
@@ -858,65 +1383,39 @@ func (f *Farmer) Config() *config.Config {
 	return f.cfg
 }
 
-func (f *Farmer) addLog(format string, args ...interface{}) {
-	msg := fmt.Sprintf(format, args...)
-	now := time.Now()
-
-	entry := LogEntry{
-		Time:    now,
-		Message: msg,
-	}
-
-	f.logMu.Lock()
-	f.logEntries = append(f.logEntries, entry)
-	// Keep last 500 entries for TUI
-	if len(f.logEntries) > 500 {
-		f.logEntries = f.logEntries[len(f.logEntries)-500:]
-	}
-	f.logMu.Unlock()
-
-	// Write full untruncated line to debug.log
-	f.writeLogFile(msg)
+// NotificationsEnabled reports whether integrations should send
+// notifications (the "notifications" subsystem).
+func (f *Farmer) NotificationsEnabled() bool {
+	return f.cfg.GetNotificationsEnabled()
 }
 
-func (f *Farmer) writeLogFile(msg string) {
-	// Drop late writes after Stop() so we don't WriteString to a
-	// closed *os.File (panics on POSIX, NPE on Windows). Reads atomic
-	// — no need to hold fileLogMu for the check.
-	if f.stopped.Load() {
-		return
-	}
-
-	f.fileLogMu.Lock()
-	defer f.fileLogMu.Unlock()
-
-	if f.logFile == nil {
-		return
-	}
+// Logf adds a line to the farmer log. Used by integrations (Telegram)
+// so their activity shows up in the TUI/web log like everything else.
+func (f *Farmer) Logf(format string, args ...interface{}) {
+	f.addLog(format, args...)
+}
 
-	// Daily rotation: check if we've crossed midnight.
-	today := time.Now().Format("2006-01-02")
-	if today != f.logDate {
-		newPath := fmt.Sprintf("logs/debug-%s.log", today)
-		newFile, err := os.OpenFile(newPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
-		if err == nil {
-			f.logFile.Close()
-			f.logFile = newFile
-			f.logDate = today
-		}
-	}
+// Warnf is Logf at warn level.
+func (f *Farmer) Warnf(format string, args ...interface{}) {
+	f.logWarn(format, args...)
+}
 
-	line := fmt.Sprintf("[%s] %s\n", time.Now().Format("2006-01-02 15:04:05"), msg)
-	f.logFile.WriteString(line)
+// Errorf is Logf at error level.
+func (f *Farmer) Errorf(format string, args ...interface{}) {
+	f.logError(format, args...)
 }
 
 // GetUser returns the authenticated user info.
 func (f *Farmer) GetUser() *twitch.UserInfo {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	return f.user
 }
 
 // GetChannels returns snapshots of all channel states.
 func (f *Farmer) GetChannels() []channels.Snapshot {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	snapshots := f.channels.Snapshots()
 
 	// Sort: watching first, then online, then offline — each group alphabetically
@@ -952,22 +1451,54 @@ func (f *Farmer) GetLogs() []LogEntry {
 	return logs
 }
 
+// GetActivity returns the activity feed (claims, raid joins, drop
+// claims, redemptions), oldest first.
+func (f *Farmer) GetActivity() []points.Activity {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.points.Activity()
+}
+
+// GetRedemptions returns the auto-redeem log, oldest first.
+func (f *Farmer) GetRedemptions() []points.Redemption {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.points.Redemptions()
+}
+
 // GetStats returns aggregate stats.
 type Stats struct {
 	TotalPointsEarned int
+	TotalPointsSpent  int
+	PointsPerHour     int // all channels, over the last channels.RateWindowSpan
 	TotalClaimsMade   int
+	TotalMoments      int
 	Uptime            time.Duration
 	ChannelsOnline    int
 	ChannelsWatching  int
 	ChannelsTotal     int
 	ActiveDrops       int
+	Capacity          Capacity
+	NextRotation      time.Time // zero if not scheduled
+	NextDropCheck     time.Time // zero if drops are disabled
+	LogFileError      string    // non-empty while the debug log is memory-only
+	Paused            bool      // farming paused (Pause)
 }
 
 func (f *Farmer) GetStats() Stats {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
 	stats := Stats{
 		TotalPointsEarned: f.points.TotalPointsEarned(),
+		TotalPointsSpent:  f.points.TotalPointsSpent(),
+		PointsPerHour:     f.points.PointsPerHour(),
 		TotalClaimsMade:   f.points.TotalClaimsMade(),
+		TotalMoments:      f.points.TotalMomentsClaimed(),
 		Uptime:            time.Since(f.startTime),
+		NextRotation:      f.points.NextRotation(),
+		NextDropCheck:     f.drops.NextCheck(),
+		LogFileError:      f.LogFileError(),
+		Paused:            f.paused.Load(),
 	}
 
 	snapshots := f.channels.Snapshots()
@@ -982,6 +1513,7 @@ func (f *Farmer) GetStats() Stats {
 	}
 
 	stats.ActiveDrops = f.drops.ActiveDropsCount()
+	stats.Capacity = computeCapacity(stats.ChannelsTotal)
 
 	return stats
 }
@@ -0,0 +1,68 @@
+package farmer
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// addTemp tracks a temporary drop channel, holding the drop pick with
+// drop.
+func addTemp(f *Farmer, login, id string, drop bool) {
+	st := channels.NewState(login, login, id)
+	st.SetIsTemporary(true)
+	if drop {
+		st.SetDropInfo("Drop", 10, 60)
+	}
+	f.channels.Add(st)
+}
+
+func trackedLogins(f *Farmer) []string {
+	var logins []string
+	for _, s := range f.channels.Snapshots() {
+		logins = append(logins, s.Login)
+	}
+	sort.Strings(logins)
+	return logins
+}
+
+// TestMakeTempChannelRoom: at max_temp_channels, temporary channels are
+// released until the new one fits, those without the drop pick first;
+// configured channels are never touched.
+func TestMakeTempChannelRoom(t *testing.T) {
+	f := newRunningFarmer(t)
+	f.prober = twitch.NewStreamProber(f.gql, "tok", f.user.ID, "device", func(string, ...interface{}) {})
+
+	// Under the default limit (2) nothing goes.
+	addTemp(f, "picked", "10", true)
+	f.makeTempChannelRoom()
+	if got := trackedLogins(f); !reflect.DeepEqual(got, []string{"alpha", "picked"}) {
+		t.Fatalf("under the limit: tracked %v", got)
+	}
+
+	// At it, the channels without the pick go first.
+	addTemp(f, "old", "11", false)
+	f.makeTempChannelRoom()
+	if got := trackedLogins(f); !reflect.DeepEqual(got, []string{"alpha", "picked"}) {
+		t.Errorf("at the default limit: tracked %v, want old released", got)
+	}
+
+	// max_temp_channels 1 releases the pick too, to make room.
+	f.cfg.MaxTempChannels = 1
+	f.makeTempChannelRoom()
+	if got := trackedLogins(f); !reflect.DeepEqual(got, []string{"alpha"}) {
+		t.Errorf("max_temp_channels 1: tracked %v, want only the configured channel", got)
+	}
+
+	// A higher limit keeps them all.
+	f.cfg.MaxTempChannels = 3
+	addTemp(f, "one", "12", false)
+	addTemp(f, "two", "13", true)
+	f.makeTempChannelRoom()
+	if got := trackedLogins(f); !reflect.DeepEqual(got, []string{"alpha", "one", "two"}) {
+		t.Errorf("max_temp_channels 3: tracked %v", got)
+	}
+}
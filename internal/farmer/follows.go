@@ -0,0 +1,58 @@
+package farmer
+
+import (
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// ImportFollows adds the channels the user follows to the config with
+// the default priority (rotate), filtered as by twitch.FilterFollows.
+// Channels already configured are skipped; a temporary drop channel is
+// promoted. Returns the logins added.
+func (f *Farmer) ImportFollows(liveOnly bool, minAge time.Duration) ([]string, error) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	follows, err := f.gql.GetFollowedChannels()
+	if err != nil {
+		return nil, err
+	}
+	matched := twitch.FilterFollows(follows, liveOnly, minAge, time.Now())
+
+	var added []string
+	for _, fc := range matched {
+		login := strings.ToLower(fc.Login)
+		if f.cfg.HasChannel(login) {
+			continue
+		}
+		if _, ok := f.channels.GetByLogin(login); ok {
+			// Temp drop channel — addChannelLive promotes it.
+			if err := f.addChannelLive(login); err != nil {
+				f.logWarn("[Follows] Could not add %s: %v", login, err)
+				continue
+			}
+			added = append(added, login)
+			continue
+		}
+		info := fc.ChannelInfo
+		info.Login = login
+		f.cfg.AddChannel(login)
+		f.cfg.SetChannelID(login, info.ID)
+		if err := f.addChannelWithInfo(&info); err != nil {
+			f.logWarn("[Follows] Could not add %s: %v", login, err)
+			continue
+		}
+		added = append(added, login)
+	}
+	if len(added) > 0 {
+		f.cfg.SaveSoon()
+	}
+
+	f.addLog("[Follows] Imported %d channels (%d followed, %d matched the filter)",
+		len(added), len(follows), len(matched))
+	if w := f.capacityWarning(); w != "" && len(added) > 0 {
+		f.logWarn("[Capacity] Warning: %s", w)
+	}
+	return added, nil
+}
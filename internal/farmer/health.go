@@ -0,0 +1,326 @@
+package farmer
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/history"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Account health verdicts (AccountHealth.Status, HealthFinding.Severity).
+const (
+	HealthOK      = "ok"
+	HealthWarning = "warning"
+	HealthProblem = "problem"
+)
+
+const (
+	// tokenCheckInterval is how long a /oauth2/validate answer is reused.
+	tokenCheckInterval = 5 * time.Minute
+	// authCheckInterval is how often authCheckLoop validates the token.
+	authCheckInterval = 15 * time.Minute
+	// healthWindow is the span the "recent" signals look back over.
+	healthWindow = time.Hour
+	// minWatchWindow is how long channels must have been watched before
+	// missing WATCH points count as a problem (Twitch credits every 5 min).
+	minWatchWindow = 20 * time.Minute
+	// minClaimSample is the attempts needed before a claim rejection rate
+	// is judged.
+	minClaimSample = 4
+)
+
+// AccountHealth gathers the signals that tell whether the account is
+// being farmed normally or quietly limited by Twitch.
+type AccountHealth struct {
+	Checked  time.Time       `json:"checked"`
+	Status   string          `json:"status"` // worst finding: ok, warning or problem
+	Findings []HealthFinding `json:"findings"`
+	Token    TokenHealth     `json:"token"`
+	GQL      GQLHealthInfo   `json:"gql"`
+	Claims   []ClaimHealth   `json:"claims"`
+	Watch    WatchHealth     `json:"watch"`
+}
+
+// HealthFinding is one thing worth the user's attention.
+type HealthFinding struct {
+	Severity string `json:"severity"` // warning or problem
+	Message  string `json:"message"`
+}
+
+// TokenHealth is the auth token as Twitch's /oauth2/validate sees it.
+type TokenHealth struct {
+	Valid     bool       `json:"valid"`
+	Login     string     `json:"login,omitempty"`
+	ClientID  string     `json:"client_id,omitempty"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at,omitempty"` // nil = doesn't expire
+	Error     string     `json:"error,omitempty"`      // validation couldn't be done
+}
+
+// GQLHealthInfo summarizes GQL traffic: totals since start and the
+// error classes of the last hour (twitch.GQLError*).
+type GQLHealthInfo struct {
+	Requests      int                     `json:"requests"`
+	Errors        int                     `json:"errors"`
+	RecentClasses map[string]int          `json:"recent_classes"`
+	RecentErrors  []twitch.GQLErrorRecord `json:"recent_errors"` // last 10, newest first
+}
+
+// ClaimHealth is the rejection rate of one claim mutation since start.
+type ClaimHealth struct {
+	Operation     string  `json:"operation"`
+	Attempts      int     `json:"attempts"`
+	Failures      int     `json:"failures"`
+	RejectionRate float64 `json:"rejection_rate"` // 0..1
+}
+
+// WatchHealth tells whether watch time is turning into points: the
+// WATCH points credited over the last hour (or since start) while
+// channels were being watched.
+type WatchHealth struct {
+	Watching      int    `json:"watching"`       // channels watched right now
+	WindowMinutes int    `json:"window_minutes"` // span the points cover
+	WatchPoints   int    `json:"watch_points"`   // WATCH reason points in the window
+	Converting    string `json:"converting"`     // yes, no or unknown
+}
+
+// tokenCheck caches the last token validation.
+type tokenCheck struct {
+	refresh sync.Mutex // held across the /oauth2/validate request
+
+	mu   sync.Mutex // guards the fields below
+	at   time.Time
+	info *twitch.TokenInfo
+	err  error
+	gen  int // bumped by invalidate; a refresh started before is dropped
+}
+
+// invalidate marks the cached validation stale, e.g. after the token
+// changed.
+func (c *tokenCheck) invalidate() {
+	c.mu.Lock()
+	c.at = time.Time{}
+	c.gen++
+	c.mu.Unlock()
+}
+
+// AccountHealth collects the account health signals. The token check
+// hits Twitch at most every tokenCheckInterval.
+func (f *Farmer) AccountHealth() AccountHealth {
+	now := time.Now()
+	f.refreshToken(now)
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	h := AccountHealth{Checked: now}
+	add := func(severity, format string, args ...interface{}) {
+		h.Findings = append(h.Findings, HealthFinding{Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	// Token
+	info, err := f.tokenCheck.cached()
+	h.Token.Scopes = []string{}
+	switch {
+	case errors.Is(err, twitch.ErrTokenInvalid):
+		add(HealthProblem, "Twitch rejects the auth token — log in again (twitchpoint login)")
+	case err != nil:
+		h.Token.Error = err.Error()
+		add(HealthWarning, "Could not validate the auth token: %v", err)
+	case info == nil:
+		h.Token.Error = "not validated yet"
+	default:
+		h.Token.Valid = true
+		h.Token.Login = info.Login
+		h.Token.ClientID = info.ClientID
+		if info.Scopes != nil {
+			h.Token.Scopes = info.Scopes
+		}
+		if info.ExpiresIn > 0 {
+			at := now.Add(time.Duration(info.ExpiresIn) * time.Second)
+			h.Token.ExpiresAt = &at
+			if info.ExpiresIn < 24*60*60 {
+				add(HealthWarning, "The auth token expires in %s", time.Duration(info.ExpiresIn)*time.Second)
+			}
+		}
+		if f.user != nil && info.UserID != "" && info.UserID != f.user.ID {
+			add(HealthProblem, "The auth token belongs to %s, not the logged-in %s", info.Login, f.user.Login)
+		}
+		if info.ClientID != "" && info.ClientID != twitch.TVClientID {
+			add(HealthWarning, "The auth token was issued to another client ID (%s) — some GQL calls may fail integrity checks", info.ClientID)
+		}
+	}
+
+	// GQL errors
+	gh := f.gql.Health()
+	h.GQL = GQLHealthInfo{Requests: gh.Requests, Errors: gh.Errors, RecentClasses: map[string]int{}}
+	for _, e := range gh.Recent {
+		if now.Sub(e.Time) > healthWindow {
+			break
+		}
+		h.GQL.RecentClasses[e.Class]++
+		if len(h.GQL.RecentErrors) < 10 {
+			h.GQL.RecentErrors = append(h.GQL.RecentErrors, e)
+		}
+	}
+	if n := h.GQL.RecentClasses[twitch.GQLErrorIntegrity]; n > 0 {
+		add(HealthProblem, "%d GQL calls failed an integrity check in the last hour", n)
+	}
+	if n := h.GQL.RecentClasses[twitch.GQLErrorAuth]; n > 0 {
+		add(HealthProblem, "%d GQL calls were refused as unauthorized in the last hour", n)
+	}
+	if n := h.GQL.RecentClasses[twitch.GQLErrorRateLimited]; n > 0 {
+		add(HealthWarning, "%d GQL calls were rate limited in the last hour", n)
+	}
+
+	// Claims
+	for op, st := range gh.Claims {
+		c := ClaimHealth{Operation: op, Attempts: st.Attempts, Failures: st.Failures}
+		if st.Attempts > 0 {
+			c.RejectionRate = float64(st.Failures) / float64(st.Attempts)
+		}
+		h.Claims = append(h.Claims, c)
+		if st.Attempts >= minClaimSample && c.RejectionRate >= 0.5 {
+			add(HealthWarning, "%d of %d %s claims failed since start", st.Failures, st.Attempts, op)
+		}
+	}
+	sort.Slice(h.Claims, func(i, j int) bool { return h.Claims[i].Operation < h.Claims[j].Operation })
+
+	// Watch time -> points
+	h.Watch = f.watchHealth(now)
+	if h.Watch.Converting == "no" {
+		add(HealthProblem, "%d channels watched for %d minutes but no WATCH points were credited", h.Watch.Watching, h.Watch.WindowMinutes)
+	}
+
+	h.Status = HealthOK
+	for _, fd := range h.Findings {
+		if fd.Severity == HealthProblem {
+			h.Status = HealthProblem
+			break
+		}
+		h.Status = HealthWarning
+	}
+	if h.Findings == nil {
+		h.Findings = []HealthFinding{}
+	}
+	return h
+}
+
+// AuthFailed is the PushKindAuthFailed payload.
+type AuthFailed struct {
+	Message string `json:"message"`
+}
+
+// authCheckLoop validates the auth token every authCheckInterval until
+// Stop and publishes PushKindAuthFailed once when Twitch starts
+// rejecting it, so notifications can ask for a new login. Network
+// errors don't count.
+func (f *Farmer) authCheckLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(authCheckInterval)
+	defer ticker.Stop()
+
+	rejected := false
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+		f.refreshToken(time.Now())
+		_, err := f.tokenCheck.cached()
+		switch {
+		case errors.Is(err, twitch.ErrTokenInvalid):
+			if !rejected {
+				rejected = true
+				msg := "Twitch rejects the auth token — log in again (twitchpoint login)"
+				f.logError("[Auth] Error: %s", msg)
+				f.publish(PushKindAuthFailed, AuthFailed{Message: msg})
+			}
+		case err == nil && rejected:
+			rejected = false
+			f.addLog("[Auth] The auth token is accepted again")
+		}
+	}
+}
+
+// refreshToken validates the token with Twitch once the cached answer
+// is older than tokenCheckInterval. Callers run it before taking runMu:
+// the request can be slow, and a Restart waiting for runMu would hold
+// up every getter until Twitch answers. Concurrent callers wait for the
+// one request in flight.
+func (f *Farmer) refreshToken(now time.Time) {
+	c := &f.tokenCheck
+	c.refresh.Lock()
+	defer c.refresh.Unlock()
+
+	c.mu.Lock()
+	fresh := !c.at.IsZero() && now.Sub(c.at) <= tokenCheckInterval
+	gen := c.gen
+	c.mu.Unlock()
+	if fresh {
+		return
+	}
+	f.runMu.RLock()
+	gql := f.gql
+	f.runMu.RUnlock()
+	if gql == nil {
+		return // not started
+	}
+
+	validate := (*twitch.GQLClient).ValidateToken
+	if f.validateToken != nil {
+		validate = f.validateToken
+	}
+	info, err := validate(gql)
+	c.mu.Lock()
+	if c.gen == gen {
+		c.info, c.err, c.at = info, err, now
+	}
+	c.mu.Unlock()
+}
+
+// cached returns the last token validation; a nil info and error mean
+// none finished yet.
+func (c *tokenCheck) cached() (*twitch.TokenInfo, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.info, c.err
+}
+
+// watchHealth compares the WATCH points of the last hour (or since
+// start) with whether anything was watched. Paused farming, too short a
+// window or no history make the answer "unknown".
+func (f *Farmer) watchHealth(now time.Time) WatchHealth {
+	var w WatchHealth
+	for _, snap := range f.channels.Snapshots() {
+		if snap.IsWatching {
+			w.Watching++
+		}
+	}
+	from := now.Add(-healthWindow)
+	if f.startTime.After(from) {
+		from = f.startTime
+	}
+	w.WindowMinutes = int(now.Sub(from) / time.Minute)
+	w.Converting = "unknown"
+
+	if f.history == nil {
+		return w
+	}
+	series, err := f.history.Series(history.Query{From: from, To: now, Bucket: history.BucketHour})
+	if err != nil {
+		return w
+	}
+	for _, p := range series {
+		w.WatchPoints += p.Reasons["WATCH"]
+	}
+	switch {
+	case w.WatchPoints > 0:
+		w.Converting = "yes"
+	case w.Watching > 0 && !f.paused.Load() && now.Sub(from) >= minWatchWindow:
+		w.Converting = "no"
+	}
+	return w
+}
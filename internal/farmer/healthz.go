@@ -0,0 +1,90 @@
+package farmer
+
+import (
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Healthz is the liveness verdict for /healthz and --healthcheck: OK
+// when the farmer runs and every check passes.
+type Healthz struct {
+	OK     bool           `json:"ok"`
+	State  string         `json:"state"`
+	Checks []HealthzCheck `json:"checks,omitempty"` // while running
+}
+
+// HealthzCheck is one Healthz check: pubsub, spade, gql or token.
+type HealthzCheck struct {
+	Name   string `json:"name"`
+	OK     bool   `json:"ok"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// healthzChecks are the Health subsystems Healthz checks, in order:
+// what must work for points to be farmed.
+var healthzChecks = []string{"pubsub", "spade", "gql", "token"}
+
+// Healthz checks the subsystems in healthzChecks: each passes unless
+// Health has it down.
+func (f *Farmer) Healthz() Healthz {
+	now := time.Now()
+	f.refreshToken(now)
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return healthzOf(f.health(now))
+}
+
+func healthzOf(health Health) Healthz {
+	h := Healthz{State: health.State}
+	for _, name := range healthzChecks {
+		for _, sub := range health.Subsystems {
+			if sub.Name == name {
+				h.Checks = append(h.Checks, HealthzCheck{Name: name, OK: sub.Status != StatusDown, Detail: sub.Detail})
+			}
+		}
+	}
+	h.OK = len(h.Checks) > 0
+	for _, c := range h.Checks {
+		h.OK = h.OK && c.OK
+	}
+	return h
+}
+
+// heartbeatCounts returns how many channels get Spade heartbeats, on how
+// many they fail, and whether Twitch accepted any yet.
+func (f *Farmer) heartbeatCounts() (watching, failing int, accepted bool) {
+	for _, ch := range f.channels.States() {
+		hb, ok := f.spade.Health(ch.ChannelID)
+		if !ok {
+			continue
+		}
+		watching++
+		if hb.Failures >= twitch.HeartbeatFailLimit {
+			failing++
+		}
+		if !hb.LastSuccess.IsZero() {
+			accepted = true
+		}
+	}
+	return watching, failing, accepted
+}
+
+// Alive is the watchdog's narrower verdict: the farmer runs, PubSub
+// connections aren't failing and heartbeats aren't failing on every
+// watched channel — the failures a restart can fix. The reason says
+// what isn't working when it isn't.
+func (f *Farmer) Alive() (bool, string) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if st := f.life.get(); st != StateRunning || !f.started.Load() {
+		return false, "farmer is " + st
+	}
+	if !f.pubsub.Healthy() {
+		return false, "PubSub connections failing"
+	}
+	if watching, failing, _ := f.heartbeatCounts(); watching > 0 && failing == watching {
+		return false, "Spade heartbeats failing on every channel"
+	}
+	return true, ""
+}
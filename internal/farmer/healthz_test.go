@@ -0,0 +1,67 @@
+package farmer
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// TestHealthzOf: Healthz takes its checks from Health, in
+// healthzChecks order; only a subsystem that is down fails.
+func TestHealthzOf(t *testing.T) {
+	h := healthzOf(Health{State: StateRunning, Subsystems: []SubsystemHealth{
+		{Name: "gql", Status: StatusDegraded, Detail: "2 errors in the last 5 min"},
+		{Name: "pubsub", Status: StatusUp, Detail: "3 connections"},
+		{Name: "irc", Status: StatusDown},
+		{Name: "spade", Status: StatusUp},
+		{Name: "token", Status: StatusDown, Detail: "Twitch rejects the auth token"},
+	}})
+	want := []HealthzCheck{
+		{Name: "pubsub", OK: true, Detail: "3 connections"},
+		{Name: "spade", OK: true},
+		{Name: "gql", OK: true, Detail: "2 errors in the last 5 min"},
+		{Name: "token", OK: false, Detail: "Twitch rejects the auth token"},
+	}
+	if !reflect.DeepEqual(h.Checks, want) {
+		t.Errorf("checks = %+v, want %+v", h.Checks, want)
+	}
+	if h.OK {
+		t.Error("OK with the token down")
+	}
+
+	if h := healthzOf(Health{State: StateStarting}); h.OK || len(h.Checks) != 0 {
+		t.Errorf("starting: %+v, want not OK and no checks", h)
+	}
+}
+
+// TestHealthz_MatchesHealth: /healthz and /api/health agree on the
+// subsystems they share.
+func TestHealthz_MatchesHealth(t *testing.T) {
+	f := newRunningFarmer(t)
+	f.tokenCheck.info = &twitch.TokenInfo{UserID: "200"}
+
+	health := f.health(time.Now())
+	status := make(map[string]SubsystemHealth)
+	for _, s := range health.Subsystems {
+		status[s.Name] = s
+	}
+	if s := status["gql"]; s.Status != StatusDown || s.Detail != "no call succeeded yet" {
+		t.Errorf("gql = %+v, want down with no call yet", s)
+	}
+	if s := status["token"]; s.Status != StatusDown || s.Detail != "token belongs to another account" {
+		t.Errorf("token = %+v, want down for another account", s)
+	}
+
+	h := f.Healthz()
+	if h.OK || h.State != StateRunning || len(h.Checks) != len(healthzChecks) {
+		t.Fatalf("Healthz = %+v", h)
+	}
+	for _, c := range h.Checks {
+		s := status[c.Name]
+		if c.OK != (s.Status != StatusDown) || c.Detail != s.Detail {
+			t.Errorf("check %+v disagrees with Health's %+v", c, s)
+		}
+	}
+}
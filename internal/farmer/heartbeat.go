@@ -0,0 +1,49 @@
+package farmer
+
+import (
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// heartbeatBench is how long a channel whose heartbeats kept failing is
+// kept out of the points rotation.
+const heartbeatBench = 30 * time.Minute
+
+// onHeartbeat is the Spade tracker's OnHeartbeat hook: it mirrors the
+// heartbeat health into the channel state and, once a rotation channel
+// has failed twitch.HeartbeatFailLimit heartbeats in a row, benches it
+// so the slot goes to another channel. The drop pick is left to the
+// drops stall detection, which fails it over on missing progress.
+func (f *Farmer) onHeartbeat(channelID string, h twitch.HeartbeatHealth) {
+	ch, ok := f.channels.Get(channelID)
+	if !ok {
+		return
+	}
+	ch.SetHeartbeat(h.LastSuccess, h.Failures)
+	if h.Failures != twitch.HeartbeatFailLimit {
+		return
+	}
+	if f.dropWatch != nil && f.dropWatch.CurrentChannelID() == channelID {
+		f.logWarn("[Spade] Warning: %d heartbeats in a row failed for the drop channel %s", h.Failures, ch.DisplayName)
+		return
+	}
+	ch.BenchHeartbeats(time.Now().Add(heartbeatBench))
+	f.logWarn("[Spade] Warning: %d heartbeats in a row failed for %s — rotating it out for %v",
+		h.Failures, ch.DisplayName, heartbeatBench)
+	f.points.RotateNow()
+}
+
+// checkInactive rotates a watched channel out as soon as its stream
+// looks like a ghost (min_viewers, stale_viewers_minutes) instead of
+// waiting for the next rotation tick. The drop pick is left alone.
+func (f *Farmer) checkInactive(ch *channels.State) {
+	snap := ch.Snapshot()
+	if !snap.IsWatching || (f.dropWatch != nil && f.dropWatch.CurrentChannelID() == snap.ChannelID) {
+		return
+	}
+	if snap.InactiveReason(time.Now(), f.cfg.GetMinViewers(), f.cfg.GetStaleViewersAfter()) != "" {
+		f.points.RotateNow()
+	}
+}
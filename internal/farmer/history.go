@@ -0,0 +1,57 @@
+package farmer
+
+import (
+	"errors"
+	"path/filepath"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+// historyFileName sits next to config.json and holds every points-earned,
+// spent and claim event across sessions.
+const historyFileName = "history.db"
+
+// ErrHistoryDisabled is returned by History when history.db couldn't be
+// opened at startup.
+var ErrHistoryDisabled = errors.New("earnings history is not available")
+
+// initHistory opens the earnings history. Failure only costs the
+// history: the farmer keeps running and nothing is recorded.
+func (f *Farmer) initHistory() {
+	path := filepath.Join(filepath.Dir(f.cfg.Path()), historyFileName)
+	store, err := history.Open(path)
+	if err != nil {
+		f.logWarn("[History] Earnings history disabled: %v", err)
+		return
+	}
+	f.history = store
+}
+
+// recordHistory appends an event to the earnings history.
+func (f *Farmer) recordHistory(e history.Event) {
+	if err := f.history.Record(e); err != nil {
+		f.debugLog("[History] Failed to record %s on %s: %v", e.Kind, e.ChannelID, err)
+	}
+}
+
+// History returns the aggregated earnings series for q.
+func (f *Farmer) History(q history.Query) ([]history.Point, error) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if f.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	return f.history.Series(q)
+}
+
+// ChannelChart returns login's daily earned and spent points and
+// end-of-day balance from from to to.
+func (f *Farmer) ChannelChart(login string, from, to time.Time) ([]history.ChartDay, error) {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if f.history == nil {
+		return nil, ErrHistoryDisabled
+	}
+	return f.history.Chart(login, from, to)
+}
@@ -0,0 +1,75 @@
+package farmer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// TestIRCClientFor_TokenCheck: only a token Twitch rejects falls back
+// to a guest for good; one that couldn't be checked joins as a guest
+// with a retry scheduled, and a valid one logs in as its account.
+func TestIRCClientFor_TokenCheck(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		err       error
+		anonymous bool
+		retry     bool
+	}{
+		{"valid", nil, false, false},
+		{"rejected", twitch.ErrTokenInvalid, true, false},
+		{"wrapped rejection", errors.Join(errors.New("validate"), twitch.ErrTokenInvalid), true, false},
+		{"network error", errors.New("validate token: dial tcp: i/o timeout"), true, true},
+		{"server error", errors.New("validate token: status 503"), true, true},
+	} {
+		f, cfg := newSettingsTestFarmer(t)
+		cfg.IrcAuthToken = "second"
+		f.stopped.Store(true) // the scheduled retry, if any, does nothing
+		var checked string
+		f.validateIRC = func(token string) (*twitch.TokenInfo, error) {
+			checked = token
+			if tc.err != nil {
+				return nil, tc.err
+			}
+			return &twitch.TokenInfo{Login: "Second"}, nil
+		}
+
+		c := f.ircClientFor("main")
+		if checked != "second" {
+			t.Errorf("%s: checked %q, want irc_auth_token", tc.name, checked)
+		}
+		if c.Anonymous() != tc.anonymous {
+			t.Errorf("%s: anonymous = %v, want %v", tc.name, c.Anonymous(), tc.anonymous)
+		}
+		if f.ircRetryPending.Load() != tc.retry {
+			t.Errorf("%s: retry pending = %v, want %v", tc.name, f.ircRetryPending.Load(), tc.retry)
+		}
+	}
+}
+
+// TestRetryIRCLogin_KeepsRetrying: a retry that still can't check the
+// token schedules the next one, and a rejection ends the retries.
+func TestRetryIRCLogin_KeepsRetrying(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+	cfg.IrcAuthToken = "second"
+	f.started.Store(true)
+	f.irc.Store(twitch.NewAnonymousIRCClient(f.addLog))
+
+	f.validateIRC = func(string) (*twitch.TokenInfo, error) { return nil, errors.New("status 502") }
+	f.retryIRCLogin()
+	if !f.ircRetryPending.Load() {
+		t.Fatal("transient failure: no retry scheduled")
+	}
+
+	f.ircRetryPending.Store(false)
+	f.validateIRC = func(string) (*twitch.TokenInfo, error) { return nil, twitch.ErrTokenInvalid }
+	f.retryIRCLogin()
+	if f.ircRetryPending.Load() {
+		t.Error("rejected token: retry scheduled")
+	}
+	if c := f.irc.Load(); c == nil || !c.Anonymous() {
+		t.Error("rejected token: guest client replaced")
+	}
+	f.stopped.Store(true) // the retry scheduled above does nothing
+}
@@ -0,0 +1,381 @@
+package farmer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Lifecycle states (Health.State).
+const (
+	StateStarting   = "starting"   // Start hasn't finished (startup delay, waiting for a login)
+	StateRunning    = "running"    // every subsystem is up
+	StateRestarting = "restarting" // Restart is tearing down or starting again
+	StateFailed     = "failed"     // a Restart couldn't start again; see Health.Error
+	StateStopped    = "stopped"    // Stop or Shutdown; final
+)
+
+// Subsystem health (SubsystemHealth.Status).
+const (
+	StatusUp       = "up"
+	StatusDegraded = "degraded" // working, with failures
+	StatusDown     = "down"
+	StatusOff      = "off" // switched off in the config
+)
+
+// ErrStopped is returned by Restart once the farmer has stopped.
+var ErrStopped = errors.New("farmer is stopped")
+
+// lifecycle tracks the farmer's state across Start, Restart and Stop.
+type lifecycle struct {
+	run  sync.Mutex    // held through Restart and Shutdown
+	done chan struct{} // closed by Shutdown
+
+	mu       sync.Mutex
+	state    string
+	since    time.Time
+	restarts int
+	err      string // why the last Restart failed
+}
+
+func newLifecycle() lifecycle {
+	return lifecycle{done: make(chan struct{}), state: StateStarting, since: time.Now()}
+}
+
+func (l *lifecycle) get() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.state
+}
+
+func (l *lifecycle) set(state, err string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.state != state {
+		l.state, l.since = state, time.Now()
+	}
+	l.err = err
+}
+
+// Health is the farmer's lifecycle state and how each subsystem is doing.
+type Health struct {
+	State      string            `json:"state"` // State*
+	Since      time.Time         `json:"since"` // when State was entered
+	Restarts   int               `json:"restarts"`
+	Error      string            `json:"error,omitempty"`      // why the last Restart failed (StateFailed)
+	Subsystems []SubsystemHealth `json:"subsystems,omitempty"` // while running
+}
+
+// SubsystemHealth is the state of one subsystem.
+type SubsystemHealth struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // Status*
+	Detail string `json:"detail,omitempty"`
+}
+
+// gqlErrorWindow is how far back Health looks for GQL errors.
+const gqlErrorWindow = 5 * time.Minute
+
+// gqlStaleWindow is how recent the last successful GQL call must be for
+// the gql subsystem to be up. The farmer polls channels and inventory
+// well within it.
+const gqlStaleWindow = 10 * time.Minute
+
+// Health reports the lifecycle state and, while running, the state of
+// the GQL client, PubSub, EventSub (unless the transport is pubsub),
+// IRC, the Spade heartbeats, the drops loops, the update check,
+// notifications, the history database and the auth token.
+func (f *Farmer) Health() Health {
+	now := time.Now()
+	f.refreshToken(now)
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	return f.health(now)
+}
+
+func (f *Farmer) health(now time.Time) Health {
+	l := &f.life
+	l.mu.Lock()
+	h := Health{State: l.state, Since: l.since, Restarts: l.restarts, Error: l.err}
+	l.mu.Unlock()
+	if h.State != StateRunning || !f.started.Load() {
+		return h
+	}
+
+	gql := f.gql.Health()
+	sub := SubsystemHealth{Name: "gql", Status: StatusUp}
+	recent := 0
+	for _, e := range gql.Recent {
+		if now.Sub(e.Time) <= gqlErrorWindow {
+			recent++
+		}
+	}
+	switch ago := now.Sub(gql.LastOK).Round(time.Second); {
+	case gql.LastOK.IsZero():
+		sub.Status, sub.Detail = StatusDown, "no call succeeded yet"
+	case ago > gqlStaleWindow && recent > 0:
+		sub.Status, sub.Detail = StatusDown, fmt.Sprintf("last successful call %s ago; last error: %s", ago, gql.Recent[0].Message)
+	case ago > gqlStaleWindow:
+		sub.Status, sub.Detail = StatusDown, fmt.Sprintf("last successful call %s ago", ago)
+	case recent > 0:
+		sub.Status, sub.Detail = StatusDegraded, fmt.Sprintf("%d errors in the last %d min", recent, int(gqlErrorWindow/time.Minute))
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+
+	sub = SubsystemHealth{Name: "pubsub", Status: StatusUp, Detail: fmt.Sprintf("%d connections", f.pubsub.Connections())}
+	if !f.pubsub.Healthy() {
+		sub.Status, sub.Detail = StatusDown, "connections failing"
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+
+	f.transport.mu.Lock()
+	mode, esDown, onES := f.transport.mode, f.transport.eventsubDown, len(f.transport.onEventSub)
+	f.transport.mu.Unlock()
+	if mode != config.TransportPubSub {
+		sub = SubsystemHealth{Name: "eventsub", Status: StatusUp, Detail: fmt.Sprintf("%d channels", onES)}
+		if esDown {
+			sub.Status, sub.Detail = StatusDown, "unreachable — channels on PubSub"
+		}
+		h.Subsystems = append(h.Subsystems, sub)
+	}
+
+	sub = SubsystemHealth{Name: SubsystemIRC, Status: StatusUp}
+	switch irc := f.irc.Load(); {
+	case !f.cfg.GetIrcEnabled():
+		sub.Status = StatusOff
+	case irc == nil:
+		sub.Status, sub.Detail = StatusDown, "not running"
+	case !irc.Ready():
+		sub.Status, sub.Detail = StatusDown, "connecting"
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+
+	url, urlErr := f.spade.URL()
+	watching, failing, accepted := f.heartbeatCounts()
+	sub = SubsystemHealth{Name: "spade", Status: StatusUp, Detail: fmt.Sprintf("watching %d channels via %s", watching, url)}
+	switch {
+	case urlErr != nil && !accepted:
+		sub.Status, sub.Detail = StatusDown, fmt.Sprintf("URL not found on twitch.tv (%v); fallback %s not confirmed yet", urlErr, url)
+	case watching > 0 && failing == watching:
+		sub.Status, sub.Detail = StatusDown, fmt.Sprintf("heartbeats to %s failing on every channel", url)
+	case failing > 0:
+		sub.Status, sub.Detail = StatusDegraded, fmt.Sprintf("heartbeats failing on %d of %d channels", failing, watching)
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+
+	for _, s := range f.Subsystems() {
+		if s.Name == SubsystemIRC {
+			continue
+		}
+		sub = SubsystemHealth{Name: s.Name, Status: StatusUp}
+		switch {
+		case !s.Enabled:
+			sub.Status = StatusOff
+		case !s.Running && s.Name != SubsystemNotifications: // notifications need no running part
+			sub.Status = StatusDown
+		}
+		h.Subsystems = append(h.Subsystems, sub)
+	}
+
+	sub = SubsystemHealth{Name: "history", Status: StatusUp}
+	if f.history == nil {
+		sub.Status, sub.Detail = StatusDown, "history.db could not be opened"
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+
+	// A check that can't reach Twitch doesn't fail it — gql covers the
+	// network.
+	sub = SubsystemHealth{Name: "token", Status: StatusUp, Detail: "valid"}
+	switch info, err := f.tokenCheck.cached(); {
+	case errors.Is(err, twitch.ErrTokenInvalid):
+		sub.Status, sub.Detail = StatusDown, "Twitch rejects the auth token"
+	case err != nil:
+		sub.Status, sub.Detail = StatusDegraded, fmt.Sprintf("could not validate: %v", err)
+	case info == nil:
+		sub.Status, sub.Detail = StatusDegraded, "not validated yet"
+	case f.user != nil && info.UserID != "" && info.UserID != f.user.ID:
+		sub.Status, sub.Detail = StatusDown, "token belongs to another account"
+	}
+	h.Subsystems = append(h.Subsystems, sub)
+	return h
+}
+
+// Restart tears every subsystem down and starts them again from the
+// current config, as a fresh Start would: new Twitch clients and
+// connections, the user looked up again (another account after a token
+// change), channels resolved again and session counters back to zero.
+// Claims in flight get until ctx is done to finish, as with Shutdown.
+// Push subscribers, Done, the pause switch and the web server stay as
+// they are. A failed Start leaves the farmer in StateFailed until the
+// next Restart. Callers of the exported getters and setters wait (on
+// runMu) while the new run starts, so they never see half of it.
+func (f *Farmer) Restart(ctx context.Context) error {
+	l := &f.life
+	l.run.Lock()
+	defer l.run.Unlock()
+	if f.stopped.Load() {
+		return ErrStopped
+	}
+	prev := l.get()
+	if prev != StateRunning && prev != StateFailed {
+		return fmt.Errorf("farmer is %s", prev)
+	}
+
+	f.addLog("Restarting all subsystems")
+	l.set(StateRestarting, "")
+	if err := f.teardown(ctx, "restarting"); err != nil {
+		f.logWarn("Warning: requests still in flight were cancelled for the restart")
+	}
+	start := f.start
+	if f.startRun != nil {
+		start = f.startRun
+	}
+	f.runMu.Lock()
+	f.resetRun()
+	err := start()
+	f.runMu.Unlock()
+	if err != nil {
+		l.set(StateFailed, err.Error())
+		f.logError("Error: restart failed: %v", err)
+		return err
+	}
+	l.mu.Lock()
+	l.restarts++
+	l.mu.Unlock()
+	return nil
+}
+
+// RestartInBackground runs Restart without waiting for it, for callers
+// that can't block on the teardown (the web API; SetAuthToken, which
+// holds tokenMu). Restart logs its own failure; Health reports it.
+func (f *Farmer) RestartInBackground() {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = f.Restart(ctx)
+	}()
+}
+
+// teardown stops the current run: every subsystem, then the work in
+// flight (waited for until ctx is done, then cancelled, returning ctx's
+// error), then the history database, daily tally, config and log file
+// are written out and closed. marker ends up in the log's last line.
+//
+// The ordering matters: subsystems get their Close/Stop first so no new
+// events get queued; in-flight work is waited for (and may still log);
+// THEN we hold fileLogMu while setting logClosed, writing the final
+// line and closing the log file. Holding the mutex during close
+// serializes with any in-flight writeLogFile call — it finishes its
+// write, releases the mutex, and we close cleanly without racing;
+// later writes see logClosed and are dropped.
+func (f *Farmer) teardown(ctx context.Context, marker string) error {
+	f.started.Store(false)
+	close(f.stopCh)
+
+	if f.pubsub != nil {
+		f.pubsub.Close()
+		f.pubsub.RecordTo(nil)
+	}
+	if f.recordFile != nil {
+		_ = f.recordFile.Close()
+	}
+	if f.eventsub != nil {
+		f.eventsub.Close()
+	}
+	f.subsys.stopAll()
+	if irc := f.irc.Load(); irc != nil {
+		irc.Close()
+	}
+	if f.prober != nil {
+		f.prober.StopAll()
+	}
+	if f.dropWatch != nil {
+		f.dropWatch.StopAll()
+	}
+	if f.points != nil {
+		f.points.StopTimers()
+	}
+	f.streamDown.mu.Lock()
+	for id, t := range f.streamDown.pending {
+		t.Stop()
+		delete(f.streamDown.pending, id)
+	}
+	f.streamDown.mu.Unlock()
+
+	// Let claims and heartbeats in flight finish, then cut off the rest.
+	var err error
+	if f.spade != nil {
+		err = f.spade.Shutdown(ctx)
+	}
+	if f.points != nil {
+		if e := f.points.WaitClaims(ctx); err == nil {
+			err = e
+		}
+	}
+	if f.gql != nil {
+		if e := f.gql.Shutdown(ctx); err == nil {
+			err = e
+		}
+	}
+	if err != nil {
+		f.logWarn("Warning: shutdown timed out — requests still in flight were cancelled")
+	}
+
+	f.sampleDaily(true)
+	_ = f.history.Close()
+
+	// Write out changes still waiting in the config's save debounce.
+	if err := f.cfg.Flush(); err != nil {
+		f.logWarn("Warning: could not save config: %v", err)
+	}
+
+	// Drain any in-flight log write, emit the final marker, close.
+	f.fileLogMu.Lock()
+	f.logClosed.Store(true)
+	if f.logFile != nil {
+		line := f.formatLogLine(newLogEntry(slog.LevelInfo, "=== TwitchPoint Farmer "+marker+" ==="))
+		_, _ = f.logFile.WriteString(line)
+		_ = f.logFile.Close()
+		f.logFile = nil
+	}
+	f.fileLogMu.Unlock()
+	return err
+}
+
+// resetRun clears what teardown left behind so Start can run again.
+// Caller holds runMu.
+// The clients Start replaces (GQL, PubSub, Spade, the services) are
+// left in place, shut down, for callers that still hold the old run.
+func (f *Farmer) resetRun() {
+	f.stopCh = make(chan struct{})
+	f.events = make(chan twitch.FarmerEvent, 100)
+	f.channels = channels.New()
+	f.eventsub, f.recordFile = nil, nil
+	f.irc.Store(nil)
+	f.history = nil
+
+	f.transport.mu.Lock()
+	f.transport.pubsubDown, f.transport.eventsubDown = false, false
+	f.transport.mu.Unlock()
+
+	f.tokenCheck.invalidate()
+
+	f.daily.mu.Lock()
+	f.daily.lastPoints, f.daily.lastClaims = 0, 0
+	f.daily.mu.Unlock()
+
+	f.reasons.mu.Lock()
+	f.reasons.at = time.Time{}
+	f.reasons.mu.Unlock()
+
+	f.unresolved.reset()
+
+	f.logClosed.Store(false)
+}
@@ -0,0 +1,134 @@
+package farmer
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// fakeStart brings up a run the way start does, without Twitch: the
+// clients are built but never connect.
+func fakeStart(f *Farmer) func() error {
+	return func() error {
+		noLog := func(string, ...interface{}) {}
+		f.startTime = time.Now()
+		f.user = &twitch.UserInfo{ID: "100", Login: "me", DisplayName: "Me"}
+		f.gql = &twitch.GQLClient{}
+		f.spade = twitch.NewSpadeTracker(f.user.ID, "tok", "device", f.gql, noLog)
+		f.pubsub = twitch.NewPubSubClient("tok", f.events)
+		f.channels.Add(channels.NewState("alpha", "Alpha", "1"))
+		f.drops = drops.NewService(drops.ServiceDeps{Cfg: f.cfg, Channels: f.channels, Log: noLog})
+		f.points = points.NewService(points.ServiceDeps{Cfg: f.cfg, Channels: f.channels, Drops: f.drops, Log: noLog})
+		f.tokenCheck.mu.Lock()
+		f.tokenCheck.at, f.tokenCheck.info, f.tokenCheck.err = time.Now(), &twitch.TokenInfo{UserID: f.user.ID}, nil
+		f.tokenCheck.mu.Unlock()
+		f.started.Store(true)
+		f.life.set(StateRunning, "")
+		return nil
+	}
+}
+
+// newRunningFarmer returns a farmer running on fakeStart.
+func newRunningFarmer(t *testing.T) *Farmer {
+	t.Helper()
+	f, _ := newSettingsTestFarmer(t)
+	f.life = newLifecycle()
+	f.stopCh = make(chan struct{})
+	f.events = make(chan twitch.FarmerEvent, 100)
+	f.channels = channels.New()
+	f.startRun = fakeStart(f)
+	if err := f.startRun(); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// TestRestart_ConcurrentReaders: readers running through a Restart see
+// either the old run or the new one, never a half-replaced one. Run
+// with -race.
+func TestRestart_ConcurrentReaders(t *testing.T) {
+	f := newRunningFarmer(t)
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for _, read := range []func(){
+		func() { _ = f.GetStats() },
+		func() { _ = f.GetChannels() },
+		func() { _ = f.GetUser() },
+		func() { _ = f.GetCapacity() },
+		func() { _ = f.Health() },
+		func() { _ = f.Healthz() },
+	} {
+		wg.Add(1)
+		go func(read func()) {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					read()
+				}
+			}
+		}(read)
+	}
+
+	for i := 0; i < 5; i++ {
+		if err := f.Restart(context.Background()); err != nil {
+			t.Fatalf("Restart %d: %v", i, err)
+		}
+	}
+	close(stop)
+	wg.Wait()
+
+	h := f.Health()
+	if h.State != StateRunning || h.Restarts != 5 {
+		t.Errorf("after 5 restarts: state %s, restarts %d", h.State, h.Restarts)
+	}
+	if got := f.GetChannels(); len(got) != 1 {
+		t.Errorf("channels after restart = %d, want the new run's 1", len(got))
+	}
+}
+
+// TestHealth_TokenCheckOutsideRunMu: a slow token validation doesn't
+// hold runMu, so a Restart (and the getters behind it) goes ahead while
+// Twitch hasn't answered.
+func TestHealth_TokenCheckOutsideRunMu(t *testing.T) {
+	f := newRunningFarmer(t)
+	f.tokenCheck.invalidate()
+	called, release := make(chan struct{}), make(chan struct{})
+	f.validateToken = func(*twitch.GQLClient) (*twitch.TokenInfo, error) {
+		close(called)
+		<-release
+		return &twitch.TokenInfo{UserID: "100"}, nil
+	}
+
+	done := make(chan Health)
+	go func() { done <- f.Health() }()
+	<-called
+
+	restarted := make(chan error)
+	go func() { restarted <- f.Restart(context.Background()) }()
+	select {
+	case err := <-restarted:
+		if err != nil {
+			t.Fatalf("Restart: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Restart waited for the token validation")
+	}
+	if got := f.GetChannels(); len(got) != 1 {
+		t.Errorf("channels after restart = %d, want 1", len(got))
+	}
+
+	close(release)
+	if h := <-done; h.State != StateRunning {
+		t.Errorf("Health state %s, want running", h.State)
+	}
+}
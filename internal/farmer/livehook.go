@@ -0,0 +1,140 @@
+package farmer
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+)
+
+// PushKindStreamLive is published when a channel covered by live_hook
+// goes live; PushKindChannelLive for every configured channel that goes
+// live (notifications). Data: StreamLive, Quality only set on the former.
+const (
+	PushKindStreamLive  = "stream_live"
+	PushKindChannelLive = "channel_live"
+)
+
+// StreamLive describes a channel that just went live, with everything a
+// recorder like Streamlink needs.
+type StreamLive struct {
+	ChannelID   string    `json:"channel_id"`
+	Login       string    `json:"login"`
+	DisplayName string    `json:"display_name"`
+	Priority    int       `json:"priority"`
+	URL         string    `json:"url"`
+	Quality     string    `json:"quality"`
+	GameName    string    `json:"game_name,omitempty"`
+	BroadcastID string    `json:"broadcast_id"`
+	OnlineSince time.Time `json:"online_since"`
+}
+
+// liveHookState remembers the broadcast each channel was last announced
+// for, so a repeated stream-up (transport failover, a stream-up inside
+// the stream-down grace) doesn't start a second recording.
+type liveHookState struct {
+	mu        sync.Mutex
+	announced map[string]string // channel ID -> broadcast ID
+}
+
+// announceLive publishes a channel_live event for a channel that went
+// live and, if live_hook covers it, a stream_live event plus the hook
+// command. Called once the stream-up handler has the broadcast ID.
+func (f *Farmer) announceLive(ch *channels.State) {
+	snap := ch.Snapshot()
+	if snap.IsTemporary || snap.BroadcastID == "" {
+		return
+	}
+
+	lh := &f.liveHook
+	lh.mu.Lock()
+	if lh.announced == nil {
+		lh.announced = make(map[string]string)
+	}
+	if lh.announced[snap.ChannelID] == snap.BroadcastID {
+		lh.mu.Unlock()
+		return
+	}
+	lh.announced[snap.ChannelID] = snap.BroadcastID
+	lh.mu.Unlock()
+
+	ev := StreamLive{
+		ChannelID:   snap.ChannelID,
+		Login:       snap.Login,
+		DisplayName: snap.DisplayName,
+		Priority:    snap.Priority,
+		URL:         "https://www.twitch.tv/" + snap.Login,
+		GameName:    snap.GameName,
+		BroadcastID: snap.BroadcastID,
+		OnlineSince: snap.OnlineSince,
+	}
+	f.publish(PushKindChannelLive, ev)
+
+	hook := f.cfg.GetLiveHook()
+	if !hook.Covers(snap.Login, snap.Priority) {
+		return
+	}
+	ev.Quality = hook.Quality
+	f.publish(PushKindStreamLive, ev)
+
+	if len(hook.Command) > 0 {
+		go f.runLiveHook(hook.Command, ev)
+	}
+}
+
+// runLiveHook starts the live_hook command with placeholders filled in
+// and the event also passed as TWITCHPOINT_* environment variables
+// (liveHookEnv).
+// Output is discarded; a recorder that should keep its output has to
+// redirect it itself. The process is not tied to the farmer's lifetime,
+// so a recording keeps going across a quit.
+func (f *Farmer) runLiveHook(argv []string, ev StreamLive) {
+	r := strings.NewReplacer(
+		"{login}", ev.Login,
+		"{url}", ev.URL,
+		"{quality}", ev.Quality,
+		"{channel_id}", ev.ChannelID,
+		"{game}", ev.GameName,
+		"{broadcast_id}", ev.BroadcastID,
+	)
+	args := make([]string, len(argv))
+	for i, a := range argv {
+		args[i] = r.Replace(a)
+	}
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Env = liveHookEnv(os.Environ(), ev)
+	if err := cmd.Start(); err != nil {
+		f.logWarn("[LiveHook] Could not start %s for %s: %v", args[0], ev.DisplayName, err)
+		return
+	}
+	f.addLog("[LiveHook] Started %s for %s (pid %d)", args[0], ev.DisplayName, cmd.Process.Pid)
+	if err := cmd.Wait(); err != nil {
+		f.logWarn("[LiveHook] %s for %s exited: %v", args[0], ev.DisplayName, err)
+		return
+	}
+	f.debugLog("[LiveHook] %s for %s finished", args[0], ev.DisplayName)
+}
+
+// liveHookEnv is the live_hook command's environment: environ without
+// its TWITCHPOINT_* variables, which are config overrides and may hold
+// tokens, plus the event's own.
+func liveHookEnv(environ []string, ev StreamLive) []string {
+	env := make([]string, 0, len(environ)+6)
+	for _, kv := range environ {
+		if !strings.HasPrefix(strings.ToUpper(kv), "TWITCHPOINT_") {
+			env = append(env, kv)
+		}
+	}
+	return append(env,
+		"TWITCHPOINT_LOGIN="+ev.Login,
+		"TWITCHPOINT_URL="+ev.URL,
+		"TWITCHPOINT_QUALITY="+ev.Quality,
+		"TWITCHPOINT_CHANNEL_ID="+ev.ChannelID,
+		"TWITCHPOINT_GAME="+ev.GameName,
+		"TWITCHPOINT_BROADCAST_ID="+ev.BroadcastID,
+	)
+}
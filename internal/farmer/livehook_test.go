@@ -0,0 +1,37 @@
+package farmer
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// TestRunLiveHook_Env: the hook gets the event's TWITCHPOINT_* variables
+// but none of the config overrides twitchpoint itself was started with.
+func TestRunLiveHook_Env(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("needs sh")
+	}
+	t.Setenv("TWITCHPOINT_AUTH_TOKEN", "secret")
+	t.Setenv("TWITCHPOINT_WEB_TOKEN", "secret")
+	f, _ := newSettingsTestFarmer(t)
+
+	out := filepath.Join(t.TempDir(), "env")
+	f.runLiveHook([]string{"sh", "-c", "env > " + out}, StreamLive{Login: "alpha", URL: "https://www.twitch.tv/alpha"})
+
+	data, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatalf("hook wrote no environment: %v", err)
+	}
+	env := string(data)
+	if strings.Contains(env, "secret") {
+		t.Errorf("hook inherited a token:\n%s", env)
+	}
+	for _, want := range []string{"TWITCHPOINT_LOGIN=alpha\n", "TWITCHPOINT_URL=https://www.twitch.tv/alpha\n", "PATH="} {
+		if !strings.Contains(env, want) {
+			t.Errorf("hook environment lacks %q:\n%s", want, env)
+		}
+	}
+}
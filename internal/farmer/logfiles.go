@@ -0,0 +1,218 @@
+package farmer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"time"
+)
+
+// logDir holds the daily debug-YYYY-MM-DD.log files (relative to CWD).
+const logDir = "logs"
+
+// logFileName matches the files writeLogFile produces. Anything else in
+// logDir (or any path trick) is refused by OpenLogFile.
+var logFileName = regexp.MustCompile(`^debug-\d{4}-\d{2}-\d{2}\.log$`)
+
+// LogFile describes one debug log on disk.
+type LogFile struct {
+	Name     string
+	Size     int64
+	Modified time.Time
+	Current  bool // the file writeLogFile is appending to right now
+}
+
+// ListLogFiles returns the debug logs on disk, newest first.
+func (f *Farmer) ListLogFiles() ([]LogFile, error) {
+	entries, err := os.ReadDir(logDir)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", logDir, err)
+	}
+
+	f.fileLogMu.Lock()
+	current := fmt.Sprintf("debug-%s.log", f.logDate)
+	f.fileLogMu.Unlock()
+
+	var out []LogFile
+	for _, e := range entries {
+		if e.IsDir() || !logFileName.MatchString(e.Name()) {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		out = append(out, LogFile{
+			Name:     e.Name(),
+			Size:     info.Size(),
+			Modified: info.ModTime(),
+			Current:  e.Name() == current,
+		})
+	}
+	// File names embed the date, so reverse lexical order is newest first.
+	sort.Slice(out, func(i, j int) bool { return out[i].Name > out[j].Name })
+	return out, nil
+}
+
+// OpenLogFile opens a debug log for reading. An empty name means the
+// current day's file. Only names matching debug-YYYY-MM-DD.log are
+// accepted, so callers can pass user input straight through.
+func (f *Farmer) OpenLogFile(name string) (*os.File, error) {
+	if name == "" {
+		f.fileLogMu.Lock()
+		name = fmt.Sprintf("debug-%s.log", f.logDate)
+		f.fileLogMu.Unlock()
+	}
+	if !logFileName.MatchString(name) {
+		return nil, fmt.Errorf("invalid log file name %q", name)
+	}
+	return os.Open(filepath.Join(logDir, name))
+}
+
+const (
+	// logWriteFailLimit is how many writes in a row may fail (disk full,
+	// file removed or made read-only) before the debug log switches to
+	// memory-only.
+	logWriteFailLimit = 3
+	// logRetryInterval is how often memory-only mode tries the file again.
+	logRetryInterval = 5 * time.Minute
+	// memLogLimit bounds the lines held while the file is unavailable;
+	// the oldest go first.
+	memLogLimit = 2000
+)
+
+// logFileHealth tracks debug-log write failures. Guarded by
+// Farmer.fileLogMu.
+type logFileHealth struct {
+	fails   int       // consecutive failed writes
+	err     error     // set while memory-only
+	retryAt time.Time // next reopen attempt while memory-only
+	pending []string  // lines not yet on disk, flushed once the file works again
+}
+
+// LogFileError returns why the debug log is memory-only, or "" while
+// it's being written normally.
+func (f *Farmer) LogFileError() string {
+	f.fileLogMu.Lock()
+	defer f.fileLogMu.Unlock()
+	if f.logHealth.err == nil {
+		return ""
+	}
+	return fmt.Sprintf("%v (%d lines held in memory)", f.logHealth.err, len(f.logHealth.pending))
+}
+
+// writeLogFileLocked appends a formatted line (see formatLogLine) to
+// the day's debug log, or holds it in memory while the file is failing.
+// Returns a notice for the UI when the log switches to memory-only or
+// back. Caller holds fileLogMu.
+func (f *Farmer) writeLogFileLocked(line string) string {
+	now := time.Now()
+	h := &f.logHealth
+
+	if h.err != nil {
+		h.hold(line)
+		if now.Before(h.retryAt) {
+			return ""
+		}
+		if err := f.openLogFileLocked(now); err != nil {
+			h.retryAt = now.Add(logRetryInterval)
+			return ""
+		}
+		if err := f.flushPendingLocked(); err != nil {
+			f.logFile.Close()
+			f.logFile = nil
+			h.retryAt = now.Add(logRetryInterval)
+			return ""
+		}
+		h.err = nil
+		h.fails = 0
+		return "[Log] Debug log is writable again; lines held in memory were written out"
+	}
+
+	if f.logFile == nil {
+		return ""
+	}
+
+	// Daily rotation: check if we've crossed midnight.
+	if now.Format("2006-01-02") != f.logDate {
+		old := f.logFile
+		if f.openLogFileLocked(now) == nil {
+			old.Close()
+		}
+	}
+
+	// Earlier lines that failed go first so the file stays in order.
+	err := f.flushPendingLocked()
+	if err == nil {
+		_, err = f.logFile.WriteString(line)
+	}
+	if err == nil {
+		h.fails = 0
+		return ""
+	}
+
+	h.hold(line)
+	h.fails++
+	if h.fails < logWriteFailLimit {
+		return ""
+	}
+	f.logFile.Close()
+	f.logFile = nil
+	h.err = err
+	h.retryAt = now.Add(logRetryInterval)
+	return fmt.Sprintf("[Log] Writing the debug log failed %d times in a row (%v) — keeping it in memory only, retrying every %v",
+		h.fails, err, logRetryInterval)
+}
+
+// logWriter is the open debug log: an *os.File outside tests.
+type logWriter interface {
+	WriteString(s string) (int, error)
+	Close() error
+}
+
+// openLogFileLocked opens (creating if needed) the debug log for now's
+// date and makes it the current file. The previous file is left open
+// for the caller to close.
+func (f *Farmer) openLogFileLocked(now time.Time) error {
+	date := now.Format("2006-01-02")
+	open := f.openLog
+	if open == nil {
+		open = openDailyLog
+	}
+	file, err := open(date)
+	if err != nil {
+		return err
+	}
+	f.logFile = file
+	f.logDate = date
+	return nil
+}
+
+// openDailyLog opens logs/debug-<date>.log for appending.
+func openDailyLog(date string) (logWriter, error) {
+	return os.OpenFile(filepath.Join(logDir, fmt.Sprintf("debug-%s.log", date)), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+}
+
+// flushPendingLocked writes held lines to the current file.
+func (f *Farmer) flushPendingLocked() error {
+	h := &f.logHealth
+	for len(h.pending) > 0 {
+		if _, err := f.logFile.WriteString(h.pending[0]); err != nil {
+			return err
+		}
+		h.pending = h.pending[1:]
+	}
+	h.pending = nil
+	return nil
+}
+
+// hold keeps a line that couldn't be written, dropping the oldest past
+// memLogLimit.
+func (h *logFileHealth) hold(line string) {
+	h.pending = append(h.pending, line)
+	if len(h.pending) > memLogLimit {
+		h.pending = h.pending[len(h.pending)-memLogLimit:]
+	}
+}
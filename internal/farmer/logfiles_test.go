@@ -0,0 +1,126 @@
+package farmer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeLog is a debug log whose writes fail while fail is set.
+type fakeLog struct {
+	lines  []string
+	fail   bool
+	closed bool
+}
+
+func (l *fakeLog) WriteString(s string) (int, error) {
+	if l.fail {
+		return 0, errors.New("disk full")
+	}
+	l.lines = append(l.lines, s)
+	return len(s), nil
+}
+
+func (l *fakeLog) Close() error {
+	l.closed = true
+	return nil
+}
+
+// newLogTestFarmer returns a farmer whose debug log is file, opened
+// while openErr is nil.
+func newLogTestFarmer(t *testing.T, file *fakeLog, openErr *error) *Farmer {
+	t.Helper()
+	f := &Farmer{}
+	f.openLog = func(string) (logWriter, error) {
+		if *openErr != nil {
+			return nil, *openErr
+		}
+		return file, nil
+	}
+	if err := f.openLogFileLocked(time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	return f
+}
+
+// TestWriteLogFile_MemoryOnlyAndBack walks the fallback: failed writes
+// are held, the third in a row switches to memory-only, reopen attempts
+// wait for logRetryInterval, and a working file gets the held lines
+// first, in order.
+func TestWriteLogFile_MemoryOnlyAndBack(t *testing.T) {
+	file := &fakeLog{}
+	var openErr error
+	f := newLogTestFarmer(t, file, &openErr)
+
+	if notice := f.writeLogFileLocked("a\n"); notice != "" || len(file.lines) != 1 {
+		t.Fatalf("healthy write: notice %q, lines %q", notice, file.lines)
+	}
+
+	file.fail = true
+	for i, line := range []string{"b\n", "c\n"} {
+		if notice := f.writeLogFileLocked(line); notice != "" || f.logHealth.fails != i+1 {
+			t.Fatalf("failure %d: notice %q, fails %d", i+1, notice, f.logHealth.fails)
+		}
+	}
+	notice := f.writeLogFileLocked("d\n")
+	if !strings.Contains(notice, "memory only") || f.logFile != nil || !file.closed || f.LogFileError() == "" {
+		t.Fatalf("third failure: notice %q, file open %v, closed %v", notice, f.logFile != nil, file.closed)
+	}
+
+	// Before the retry time nothing is reopened.
+	file.fail = false
+	if notice := f.writeLogFileLocked("e\n"); notice != "" || f.logFile != nil {
+		t.Fatalf("memory-only write reopened the file early (notice %q)", notice)
+	}
+
+	// A failed reopen pushes the next attempt out.
+	openErr = errors.New("read-only file system")
+	f.logHealth.retryAt = time.Now().Add(-time.Second)
+	f.writeLogFileLocked("f\n")
+	if f.logFile != nil || !f.logHealth.retryAt.After(time.Now()) {
+		t.Fatal("failed reopen should stay memory-only and schedule another retry")
+	}
+
+	openErr = nil
+	f.logHealth.retryAt = time.Now().Add(-time.Second)
+	notice = f.writeLogFileLocked("g\n")
+	if !strings.Contains(notice, "writable again") || f.logFile == nil || f.LogFileError() != "" {
+		t.Fatalf("recovery: notice %q, file open %v", notice, f.logFile != nil)
+	}
+	if got := strings.Join(file.lines, ""); got != "a\nb\nc\nd\ne\nf\ng\n" {
+		t.Fatalf("file = %q, want every line once, in order", got)
+	}
+	if len(f.logHealth.pending) != 0 || f.logHealth.fails != 0 {
+		t.Fatalf("state after recovery: %+v", f.logHealth)
+	}
+}
+
+// TestWriteLogFile_RecoversBeforeLimit: a write that works again before
+// logWriteFailLimit flushes the held lines without a notice.
+func TestWriteLogFile_RecoversBeforeLimit(t *testing.T) {
+	file := &fakeLog{}
+	var openErr error
+	f := newLogTestFarmer(t, file, &openErr)
+
+	file.fail = true
+	f.writeLogFileLocked("a\n")
+	file.fail = false
+	if notice := f.writeLogFileLocked("b\n"); notice != "" {
+		t.Fatalf("notice %q for a single failed write", notice)
+	}
+	if got := strings.Join(file.lines, ""); got != "a\nb\n" || f.logHealth.fails != 0 {
+		t.Fatalf("file = %q, fails = %d", got, f.logHealth.fails)
+	}
+}
+
+func TestLogFileHealthHold_DropsOldest(t *testing.T) {
+	var h logFileHealth
+	for i := 0; i < memLogLimit+5; i++ {
+		h.hold("x\n")
+	}
+	h.hold("last\n")
+	if len(h.pending) != memLogLimit || h.pending[memLogLimit-1] != "last\n" {
+		t.Fatalf("held %d lines, last %q", len(h.pending), h.pending[len(h.pending)-1])
+	}
+}
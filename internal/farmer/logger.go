@@ -0,0 +1,158 @@
+package farmer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// LogEntry represents a single log line in the event log.
+type LogEntry struct {
+	Time      time.Time
+	Level     slog.Level
+	Subsystem string // the leading "[Name]" of Message, if any ("Drops/Watch")
+	Message   string
+}
+
+// logPrefix matches the "[Drops] " prefix that names a line's subsystem.
+var logPrefix = regexp.MustCompile(`^\[([^\]]+)\]`)
+
+func newLogEntry(level slog.Level, msg string) LogEntry {
+	e := LogEntry{Time: time.Now(), Level: level, Message: msg}
+	if m := logPrefix.FindStringSubmatch(msg); m != nil {
+		e.Subsystem = m[1]
+	}
+	return e
+}
+
+// logLevelOf returns the UI feed level configured by log_level, or the
+// build's defaultLogLevel.
+func logLevelOf(cfg *config.Config) slog.Level {
+	var l slog.Level
+	if s := cfg.GetLogLevel(); s == "" || l.UnmarshalText([]byte(s)) != nil {
+		return defaultLogLevel
+	}
+	return l
+}
+
+// addLog logs a line at info level.
+func (f *Farmer) addLog(format string, args ...interface{}) {
+	f.logAt(slog.LevelInfo, format, args...)
+}
+
+// logWarn logs a line at warn level: something failed but farming
+// carries on (a retry, a fallback, a skipped channel).
+func (f *Farmer) logWarn(format string, args ...interface{}) {
+	f.logAt(slog.LevelWarn, format, args...)
+}
+
+// logError logs a line at error level: something the user has to fix or
+// that stopped a subsystem.
+func (f *Farmer) logError(format string, args ...interface{}) {
+	f.logAt(slog.LevelError, format, args...)
+}
+
+func (f *Farmer) logAt(level slog.Level, format string, args ...interface{}) {
+	f.log(newLogEntry(level, fmt.Sprintf(format, args...)))
+}
+
+// debugLog logs per-cycle/heartbeat noise (prober ticks, drops watch
+// updates, raw WS payloads) at debug level: debug log file only, unless
+// log_level is "debug".
+func (f *Farmer) debugLog(format string, args ...any) {
+	f.log(newLogEntry(slog.LevelDebug, fmt.Sprintf(format, args...)))
+}
+
+// log keeps e in the UI feed (and pushes it to subscribers) if it is at
+// or above the configured level, and writes it to the debug log file
+// regardless.
+func (f *Farmer) log(e LogEntry) {
+	if e.Level >= f.logLevel {
+		f.logMu.Lock()
+		f.logEntries = append(f.logEntries, e)
+		// Keep last 500 entries for TUI
+		if len(f.logEntries) > 500 {
+			f.logEntries = f.logEntries[len(f.logEntries)-500:]
+		}
+		if f.logOut != nil {
+			_, _ = io.WriteString(f.logOut, f.formatLogLine(e))
+		}
+		f.logMu.Unlock()
+
+		f.publish(PushKindLog, e)
+	}
+
+	// Write full untruncated line to debug.log
+	f.writeLogEntry(e)
+}
+
+// SetLogOutput makes the event log also go to w — the entries at
+// log_level and up, formatted like the debug log file (log_format) — so
+// headless mode can log to stdout for Docker and journald. Call it
+// before Start.
+func (f *Farmer) SetLogOutput(w io.Writer) {
+	f.logMu.Lock()
+	f.logOut = w
+	f.logMu.Unlock()
+}
+
+// writeLogFile writes msg to the debug log file only, at debug level.
+func (f *Farmer) writeLogFile(msg string) {
+	f.writeLogEntry(newLogEntry(slog.LevelDebug, msg))
+}
+
+func (f *Farmer) writeLogEntry(e LogEntry) {
+	// Drop late writes once Shutdown() has closed the file so we don't
+	// WriteString to a closed *os.File (panics on POSIX, NPE on
+	// Windows) or reopen it. Checked again under fileLogMu, which
+	// Shutdown holds while closing.
+	if f.logClosed.Load() {
+		return
+	}
+
+	f.fileLogMu.Lock()
+	if f.logClosed.Load() {
+		f.fileLogMu.Unlock()
+		return
+	}
+	notice := f.writeLogFileLocked(f.formatLogLine(e))
+	failing := f.logHealth.err != nil
+	f.fileLogMu.Unlock()
+
+	// Outside the lock: logging comes back through writeLogEntry.
+	switch {
+	case notice == "":
+	case failing:
+		f.logWarn("%s", notice)
+	default:
+		f.addLog("%s", notice)
+	}
+}
+
+// formatLogLine renders e as a debug log file line: "[time] LEVEL msg",
+// or a JSON object with log_format "json".
+func (f *Farmer) formatLogLine(e LogEntry) string {
+	if !f.logJSON {
+		return fmt.Sprintf("[%s] %-5s %s\n", e.Time.Format("2006-01-02 15:04:05"), e.Level, e.Message)
+	}
+	msg := e.Message
+	if e.Subsystem != "" {
+		msg = strings.TrimSpace(strings.TrimPrefix(msg, "["+e.Subsystem+"]"))
+	}
+	b, err := json.Marshal(struct {
+		Time      time.Time `json:"time"`
+		Level     string    `json:"level"`
+		Subsystem string    `json:"subsystem,omitempty"`
+		Message   string    `json:"msg"`
+	}{e.Time, strings.ToLower(e.Level.String()), e.Subsystem, msg})
+	if err != nil {
+		return fmt.Sprintf("[%s] %-5s %s\n", e.Time.Format("2006-01-02 15:04:05"), e.Level, e.Message)
+	}
+	return string(b) + "\n"
+}
@@ -0,0 +1,65 @@
+package farmer
+
+import (
+	"log/slog"
+	"strings"
+	"testing"
+)
+
+// TestLogLevels: each helper logs at its own level, the UI feed keeps
+// only lines at log_level and up, and the debug log file gets them all.
+func TestLogLevels(t *testing.T) {
+	file := &fakeLog{}
+	var openErr error
+	f := newLogTestFarmer(t, file, &openErr)
+	f.logLevel = slog.LevelWarn
+
+	f.addLog("[Test] info line")
+	f.logWarn("[Test] warn line")
+	f.logError("[Test] error line")
+
+	logs := f.GetLogs()
+	if len(logs) != 2 {
+		t.Fatalf("feed has %d entries, want 2: %+v", len(logs), logs)
+	}
+	if logs[0].Level != slog.LevelWarn || logs[0].Message != "[Test] warn line" {
+		t.Errorf("first entry = %v %q, want WARN warn line", logs[0].Level, logs[0].Message)
+	}
+	if logs[1].Level != slog.LevelError || logs[1].Subsystem != "Test" {
+		t.Errorf("second entry = %v [%s], want ERROR [Test]", logs[1].Level, logs[1].Subsystem)
+	}
+
+	if len(file.lines) != 3 {
+		t.Fatalf("log file has %d lines, want 3: %q", len(file.lines), file.lines)
+	}
+	for i, level := range []string{"INFO", "WARN", "ERROR"} {
+		if !strings.Contains(file.lines[i], level) {
+			t.Errorf("file line %d = %q, want level %s", i, file.lines[i], level)
+		}
+	}
+}
+
+// TestLogFileNotice_Level: the notice that the debug log fell back to
+// memory only is a warning, not an info line.
+func TestLogFileNotice_Level(t *testing.T) {
+	file := &fakeLog{}
+	var openErr error
+	f := newLogTestFarmer(t, file, &openErr)
+
+	file.fail = true
+	for i := 0; i < 3; i++ {
+		f.addLog("line %d", i)
+	}
+	var notice *LogEntry
+	for _, e := range f.GetLogs() {
+		if strings.Contains(e.Message, "memory only") {
+			notice = &e
+		}
+	}
+	if notice == nil {
+		t.Fatalf("no memory-only notice in %+v", f.GetLogs())
+	}
+	if notice.Level != slog.LevelWarn {
+		t.Errorf("memory-only notice at %v, want WARN", notice.Level)
+	}
+}
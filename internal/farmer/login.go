@@ -0,0 +1,194 @@
+package farmer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Device-code login states (DeviceLogin.State).
+const (
+	LoginIdle    = "idle"
+	LoginPending = "pending" // code issued, waiting for the user to authorize
+	LoginDone    = "done"
+	LoginFailed  = "failed"
+)
+
+// DeviceLogin is the state of a device-code login run from the web UI.
+type DeviceLogin struct {
+	State           string     `json:"state"`
+	UserCode        string     `json:"user_code,omitempty"`
+	VerificationURI string     `json:"verification_uri,omitempty"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	Waiting         bool       `json:"waiting"` // the farmer waits for this login to start
+	Running         bool       `json:"running"` // the farmer has started
+}
+
+// loginState tracks the web login. The zero value is idle.
+type loginState struct {
+	mu       sync.Mutex
+	current  DeviceLogin
+	loggedIn chan struct{} // set by AwaitLogin, closed when a token is saved
+}
+
+// Started reports whether Start has finished bringing the farmer up.
+func (f *Farmer) Started() bool {
+	return f.started.Load()
+}
+
+// DeviceLoginStatus returns the state of the web login.
+func (f *Farmer) DeviceLoginStatus() DeviceLogin {
+	l := &f.login
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return f.deviceLoginLocked()
+}
+
+func (f *Farmer) deviceLoginLocked() DeviceLogin {
+	st := f.login.current
+	if st.State == "" {
+		st.State = LoginIdle
+	}
+	st.Waiting = f.login.loggedIn != nil
+	st.Running = f.started.Load()
+	return st
+}
+
+// StartDeviceLogin requests a device code and polls for the user's
+// authorization in the background. The new token is saved to the config
+// and wakes AwaitLogin; a farmer that is already running keeps its old
+// token until restarted. While a code is still pending it is returned
+// again instead of requesting another.
+func (f *Farmer) StartDeviceLogin() (DeviceLogin, error) {
+	l := &f.login
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current.State == LoginPending && time.Now().Before(*l.current.ExpiresAt) {
+		return f.deviceLoginLocked(), nil
+	}
+
+	dcr, err := twitch.RequestDeviceCode(twitch.TVClientID)
+	if err != nil {
+		return DeviceLogin{}, fmt.Errorf("request device code: %w", err)
+	}
+	expires := time.Now().Add(time.Duration(dcr.ExpiresIn) * time.Second)
+	l.current = DeviceLogin{
+		State:           LoginPending,
+		UserCode:        dcr.UserCode,
+		VerificationURI: dcr.VerificationURI,
+		ExpiresAt:       &expires,
+	}
+	f.addLog("[Login] Enter code %s at %s to log in", dcr.UserCode, dcr.VerificationURI)
+	go f.pollDeviceLogin(dcr)
+	return f.deviceLoginLocked(), nil
+}
+
+// pollDeviceLogin waits for dcr to be authorized and saves the token.
+func (f *Farmer) pollDeviceLogin(dcr *twitch.DeviceCodeResponse) {
+	token, err := twitch.PollDeviceCode(twitch.TVClientID, dcr)
+
+	l := &f.login
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.current.UserCode != dcr.UserCode {
+		return // superseded by a newer code
+	}
+	if err == nil {
+		// A running farmer switches over in place; a waiting one picks
+		// the saved token up when it starts.
+		err = f.SetAuthToken(token)
+	}
+	if err != nil {
+		l.current = DeviceLogin{State: LoginFailed, Error: err.Error()}
+		f.logError("[Login] Device-code login failed: %v", err)
+		return
+	}
+
+	l.current = DeviceLogin{State: LoginDone}
+	if l.loggedIn != nil {
+		close(l.loggedIn)
+		l.loggedIn = nil
+		f.addLog("[Login] Logged in — token saved to %s", f.cfg.Path())
+	}
+}
+
+// SetAuthToken switches to a new auth token without a restart. A running
+// farmer first checks the token belongs to the logged-in account, then
+// saves it and hands it to every client: GQL, PubSub (which reconnects
+// and LISTENs again), EventSub, IRC (which reconnects, unless it logs
+// in with irc_auth_token), Spade and the stream prober. A token for a
+// different account is saved and the farmer restarts to switch over, as
+// does one that failed a previous restart. Before Start the token is only
+// saved, for Start to use.
+func (f *Farmer) SetAuthToken(token string) error {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return fmt.Errorf("empty auth token")
+	}
+	f.tokenMu.Lock()
+	defer f.tokenMu.Unlock()
+
+	if !f.started.Load() {
+		f.cfg.SetAuthToken(token)
+		if err := f.cfg.Save(); err != nil {
+			return fmt.Errorf("save config: %w", err)
+		}
+		if f.life.get() == StateFailed {
+			f.addLog("[Login] Token saved — retrying the failed restart")
+			f.RestartInBackground()
+		}
+		return nil
+	}
+
+	info, err := twitch.ValidateToken(token)
+	if err != nil {
+		return fmt.Errorf("validate token: %w", err)
+	}
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if info.UserID != f.user.ID {
+		f.cfg.SetAuthToken(token)
+		if err := f.cfg.Save(); err != nil {
+			return fmt.Errorf("save config: %w", err)
+		}
+		f.addLog("[Login] The new token belongs to %s — restarting to switch from %s", info.Login, f.user.Login)
+		f.RestartInBackground()
+		return nil
+	}
+	f.cfg.SetAuthToken(token)
+	if err := f.cfg.Save(); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+
+	f.gql.SetAuthToken(token)
+	f.pubsub.SetAuthToken(token)
+	if f.eventsub != nil {
+		f.eventsub.SetAuthToken(token)
+	}
+	if irc := f.irc.Load(); irc != nil && f.cfg.GetIrcAuthToken() == "" {
+		irc.SetAuthToken(token)
+	}
+	f.spade.SetAuthToken(token)
+	f.prober.SetAuthToken(token)
+
+	// The cached validation was of the old token.
+	f.tokenCheck.invalidate()
+
+	f.addLog("[Login] Switched to the new auth token — PubSub and IRC reconnect with it")
+	return nil
+}
+
+// AwaitLogin marks the farmer as waiting for a web login and returns a
+// channel that is closed once one has saved a token. Start can then
+// pick the token up from the config.
+func (f *Farmer) AwaitLogin() <-chan struct{} {
+	l := &f.login
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.loggedIn = make(chan struct{})
+	return l.loggedIn
+}
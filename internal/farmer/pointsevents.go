@@ -0,0 +1,104 @@
+package farmer
+
+import (
+	"encoding/json"
+	"strings"
+	"sync"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// maxLoggedPayload caps the payload shown in the UI log line for an
+// unknown event, in runes; the debug log file gets it whole.
+const maxLoggedPayload = 500
+
+// unknownPointsState remembers the unknown community-points event types
+// already announced this session.
+type unknownPointsState struct {
+	mu   sync.Mutex
+	seen map[string]bool
+}
+
+// handleUnknownPoints deals with a community-points message the PubSub
+// client has no handler for. Types listed in points_claim_events are
+// claimed like bonus chests; anything else is announced once per type
+// with its payload, so new claimables can be spotted and configured.
+func (f *Farmer) handleUnknownPoints(evt twitch.FarmerEvent) {
+	data := evt.Data.(twitch.UnknownPointsData)
+	f.debugLog("[Points] %s event: %s", data.Type, data.Payload)
+
+	rule, ok := f.cfg.GetPointsClaimEvent(data.Type)
+	if !ok {
+		if f.firstUnknownPoints(data.Type) {
+			payload := []rune(string(data.Payload))
+			if len(payload) > maxLoggedPayload {
+				payload = append(payload[:maxLoggedPayload], '…')
+			}
+			f.addLog("[Points] Unhandled community-points event %q (claim it via points_claim_events if it is claimable): %s", data.Type, string(payload))
+		}
+		return
+	}
+
+	claimID := jsonPathString(data.Payload, rule.IDPath)
+	channelID := jsonPathString(data.Payload, rule.ChannelPath)
+	if channelID == "" && rule.ChannelPath == config.DefaultPointsClaimChannelPath {
+		channelID = jsonPathString(data.Payload, "claim.channel_id")
+	}
+	if channelID == "" {
+		channelID = evt.ChannelID
+	}
+	if claimID == "" || channelID == "" {
+		f.logWarn("[Points] Could not claim %s event: no claim ID at %q or channel at %q", data.Type, rule.IDPath, rule.ChannelPath)
+		return
+	}
+	f.debugLog("[Points] Claiming %s event %s via points_claim_events", data.Type, claimID)
+
+	// From here on it's a bonus chest: pause, dedup and naming apply.
+	f.handleEvent(twitch.FarmerEvent{
+		Type:      twitch.EventClaimAvailable,
+		ChannelID: channelID,
+		Data:      twitch.ClaimData{ClaimID: claimID},
+	})
+}
+
+// firstUnknownPoints reports whether typ is seen for the first time.
+func (f *Farmer) firstUnknownPoints(typ string) bool {
+	u := &f.unknownPoints
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.seen[typ] {
+		return false
+	}
+	if u.seen == nil {
+		u.seen = make(map[string]bool)
+	}
+	u.seen[typ] = true
+	return true
+}
+
+// jsonPathString returns the string (or number, as written) at a dotted
+// path such as "claim.id" in a JSON object, or "" if there is none.
+func jsonPathString(raw json.RawMessage, path string) string {
+	cur := raw
+	for _, key := range strings.Split(path, ".") {
+		var obj map[string]json.RawMessage
+		if err := json.Unmarshal(cur, &obj); err != nil {
+			return ""
+		}
+		next, ok := obj[key]
+		if !ok {
+			return ""
+		}
+		cur = next
+	}
+	var s string
+	if err := json.Unmarshal(cur, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(cur, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
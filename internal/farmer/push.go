@@ -0,0 +1,157 @@
+package farmer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Push kinds delivered to Subscribe callers.
+const (
+	PushKindEvent          = "event"           // Data: PushFarmerEvent
+	PushKindLog            = "log"             // Data: LogEntry
+	PushKindChannel        = "channel"         // Data: channels.Snapshot (added or changed)
+	PushKindChannelRemoved = "channel_removed" // Data: string channel ID
+	PushKindDropClaimed    = "drop_claimed"    // Data: drops.DropClaimed
+	PushKindActivity       = "activity"        // Data: points.Activity
+	PushKindAuthFailed     = "auth_failed"     // Data: AuthFailed
+)
+
+// pushBufferSize bounds each subscriber's queue. A subscriber that falls
+// this far behind (stalled browser tab) starts losing messages instead
+// of blocking the farmer — clients re-sync via the regular /api/* GETs.
+const pushBufferSize = 64
+
+// snapshotDiffInterval is how often channel snapshots are diffed while
+// at least one subscriber is connected.
+const snapshotDiffInterval = 1 * time.Second
+
+// PushEvent is one message on the live-update bus.
+type PushEvent struct {
+	Kind string
+	Data interface{}
+}
+
+// PushFarmerEvent is the client-facing projection of a twitch.FarmerEvent.
+// Error payloads are flattened to their message so they survive JSON
+// encoding.
+type PushFarmerEvent struct {
+	Type      string      `json:"type"`
+	ChannelID string      `json:"channel_id,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// pushBus fans PushEvents out to every subscriber.
+type pushBus struct {
+	mu   sync.Mutex
+	subs map[chan PushEvent]struct{}
+}
+
+// Subscribe registers a live-update listener. The returned cancel func
+// must be called when the listener goes away; it closes the channel.
+func (f *Farmer) Subscribe() (<-chan PushEvent, func()) {
+	ch := make(chan PushEvent, pushBufferSize)
+	f.push.mu.Lock()
+	if f.push.subs == nil {
+		f.push.subs = make(map[chan PushEvent]struct{})
+	}
+	f.push.subs[ch] = struct{}{}
+	f.push.mu.Unlock()
+
+	var once sync.Once
+	cancel := func() {
+		once.Do(func() {
+			f.push.mu.Lock()
+			delete(f.push.subs, ch)
+			f.push.mu.Unlock()
+			close(ch)
+		})
+	}
+	return ch, cancel
+}
+
+// publish delivers ev to every subscriber without blocking.
+func (f *Farmer) publish(kind string, data interface{}) {
+	f.push.mu.Lock()
+	defer f.push.mu.Unlock()
+	for ch := range f.push.subs {
+		select {
+		case ch <- PushEvent{Kind: kind, Data: data}:
+		default:
+			// Subscriber is behind — drop rather than stall the farmer.
+		}
+	}
+}
+
+// hasSubscribers reports whether anyone is listening, so the diff loop
+// can skip snapshot work when no dashboard is open.
+func (f *Farmer) hasSubscribers() bool {
+	f.push.mu.Lock()
+	defer f.push.mu.Unlock()
+	return len(f.push.subs) > 0
+}
+
+// onDropClaimed is drops' OnDropClaimed hook: the claim is pushed and
+// goes into the activity feed. A failed claim only goes into the feed.
+func (f *Farmer) onDropClaimed(d drops.DropClaimed) {
+	if d.Error == "" {
+		f.publish(PushKindDropClaimed, d)
+	}
+	detail := d.Name
+	if d.Campaign != "" {
+		detail += " (" + d.Campaign + ")"
+	}
+	f.points.RecordActivity(points.Activity{Kind: points.ActivityDropClaim, Detail: detail, Error: d.Error})
+}
+
+// publishFarmerEvent projects a FarmerEvent onto the bus.
+func (f *Farmer) publishFarmerEvent(evt twitch.FarmerEvent) {
+	data := evt.Data
+	if err, ok := data.(error); ok {
+		data = err.Error()
+	}
+	f.publish(PushKindEvent, PushFarmerEvent{
+		Type:      evt.Type.String(),
+		ChannelID: evt.ChannelID,
+		Data:      data,
+	})
+}
+
+// snapshotDiffLoop publishes per-channel snapshot changes. Snapshots are
+// plain values, so == detects any field change.
+func (f *Farmer) snapshotDiffLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(snapshotDiffInterval)
+	defer ticker.Stop()
+
+	prev := make(map[string]channels.Snapshot)
+	for {
+		select {
+		case <-ticker.C:
+			if !f.hasSubscribers() {
+				// Forget the baseline so the next subscriber gets a full
+				// picture on its first tick instead of stale diffs.
+				prev = make(map[string]channels.Snapshot)
+				continue
+			}
+			cur := make(map[string]channels.Snapshot)
+			for _, snap := range f.channels.Snapshots() {
+				cur[snap.ChannelID] = snap
+				if old, ok := prev[snap.ChannelID]; !ok || old != snap {
+					f.publish(PushKindChannel, snap)
+				}
+			}
+			for id := range prev {
+				if _, ok := cur[id]; !ok {
+					f.publish(PushKindChannelRemoved, id)
+				}
+			}
+			prev = cur
+		case <-stop:
+			return
+		}
+	}
+}
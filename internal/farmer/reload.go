@@ -0,0 +1,224 @@
+package farmer
+
+import (
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// configReloadInterval is how often config.json is checked for edits.
+const configReloadInterval = 3 * time.Second
+
+// unresolvedChannels are the config entries that aren't tracked
+// because resolving them failed, at startup or on a reload, or that a
+// reload is resolving right now. Reloads leave them alone, instead of
+// repeating the lookup (and its error) every configReloadInterval,
+// until the entry is removed from the file or added back.
+type unresolvedChannels struct {
+	mu     sync.Mutex
+	logins map[string]bool // login -> still resolving (false: failed)
+}
+
+// begin reports whether login should be resolved now and, if so, marks
+// it as resolving. A failed login is tried again only with retry.
+func (u *unresolvedChannels) begin(login string, retry bool) bool {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	resolving, failed := u.logins[login]
+	if resolving || (failed && !retry) {
+		return false
+	}
+	if u.logins == nil {
+		u.logins = make(map[string]bool)
+	}
+	u.logins[login] = true
+	return true
+}
+
+// done records how resolving login went.
+func (u *unresolvedChannels) done(login string, err error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if err == nil {
+		delete(u.logins, login)
+		return
+	}
+	if u.logins == nil {
+		u.logins = make(map[string]bool)
+	}
+	u.logins[login] = false
+}
+
+// forget drops login, removed from the config.
+func (u *unresolvedChannels) forget(login string) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	delete(u.logins, login)
+}
+
+// reset forgets every login, for a restart that resolves them anew.
+func (u *unresolvedChannels) reset() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.logins = nil
+}
+
+// configReloadLoop applies edits made to config.json while running
+// (see reloadConfig) until Stop.
+func (f *Farmer) configReloadLoop(stop <-chan struct{}) {
+	ticker := time.NewTicker(configReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			f.reloadConfig()
+		case <-stop:
+			return
+		}
+	}
+}
+
+// reloadConfig merges an edited config.json into the running config
+// (config.Reload) and applies what changed: channels added to or
+// removed from the list are added or dropped, per-channel settings
+// (priority, pause, goal, max_points, mode, presence switches) take
+// effect and the IRC, drops and update-check switches start or stop
+// their subsystem.
+// Other settings are read where they are used and need nothing more.
+func (f *Farmer) reloadConfig() {
+	before := f.cfg.GetChannelEntries()
+	irc, ircMode := f.cfg.GetIrcEnabled(), f.cfg.GetIrcMode()
+	dropsOn, updates := f.cfg.GetDropsEnabled(), f.cfg.GetUpdateCheckEnabled()
+
+	res, err := f.cfg.Reload()
+	if err != nil {
+		f.logWarn("[Config] Warning: could not reload %s: %v", f.cfg.Path(), err)
+		return
+	}
+	if len(res.Changed) == 0 {
+		return
+	}
+	f.addLog("[Config] Reloaded %s (changed: %s)", f.cfg.Path(), strings.Join(res.Changed, ", "))
+	for _, key := range res.Conflicts {
+		f.logWarn("[Config] Warning: %s was changed in the file and at runtime — kept the file's version", key)
+	}
+	if len(res.Restart) > 0 {
+		f.logWarn("[Config] Warning: %s take(s) effect after a restart", strings.Join(res.Restart, ", "))
+	}
+	if slices.Contains(res.Changed, "time_zone") {
+		if err := f.cfg.ApplyTimeZone(); err != nil {
+			f.logWarn("[Config] Warning: time_zone not applied, keeping %s: %v", time.Local, err)
+		} else {
+			f.addLog("[Config] Time zone: %s", time.Local)
+		}
+	}
+
+	f.applyChannelEntries(before, f.cfg.GetChannelEntries())
+
+	if f.cfg.GetIrcEnabled() != irc || f.cfg.GetIrcMode() != ircMode {
+		if f.cfg.GetIrcEnabled() && f.cfg.GetIrcMode() != config.IrcModeOff {
+			f.startIRC()
+			f.points.SyncIRC()
+		} else {
+			f.stopIRC()
+		}
+	}
+	if on := f.cfg.GetDropsEnabled(); on != dropsOn {
+		if on {
+			f.startDropLoops()
+			f.drops.CheckNow()
+		} else {
+			f.stopDropLoops()
+		}
+	}
+	if on := f.cfg.GetUpdateCheckEnabled(); on != updates {
+		if on {
+			f.startUpdateCheck()
+		} else {
+			f.stopUpdateCheck()
+		}
+	}
+
+	go f.points.Rotate()
+	if f.cfg.GetDropsEnabled() {
+		go f.drops.ProcessDrops()
+	}
+}
+
+// applyChannelEntries brings the channel registry from the channel list
+// before a reload to the one after it. Channels are matched by login. A
+// listed channel that isn't tracked is resolved again only when it is
+// new in the file, not on every reload (see unresolvedChannels).
+func (f *Farmer) applyChannelEntries(before, after []config.ChannelEntry) {
+	old := make(map[string]config.ChannelEntry, len(before))
+	for _, e := range before {
+		old[e.Login] = e
+	}
+	seen := make(map[string]bool, len(after))
+	for _, e := range after {
+		seen[e.Login] = true
+		prev, existed := old[e.Login]
+		ch, tracked := f.channels.GetByLogin(e.Login)
+		if !existed || !tracked {
+			// New in the file (or a temp drop channel now listed): resolve
+			// and add it like the TUI's add does.
+			if !f.unresolved.begin(e.Login, !existed) {
+				continue
+			}
+			go func(login string) {
+				err := f.AddChannelLive(login)
+				f.unresolved.done(login, err)
+				if err != nil {
+					f.logError("[Config] Error: could not add %s: %v", login, err)
+				}
+			}(e.Login)
+			continue
+		}
+		if e.Priority != prev.Priority {
+			ch.SetPriority(e.Priority)
+		}
+		if e.Paused != prev.Paused {
+			ch.SetPaused(e.Paused)
+			if e.Paused {
+				f.points.StopWatching(ch)
+			}
+		}
+		if e.Goal != prev.Goal || e.MaxPoints != prev.MaxPoints {
+			f.points.CheckGoal(ch)
+		}
+		for _, ft := range []struct {
+			name     string
+			was, now bool
+		}{
+			{config.ChannelFeatureIRC, prev.DisableIRC, e.DisableIRC},
+			{config.ChannelFeaturePubSub, prev.DisablePubSub, e.DisablePubSub},
+			{config.ChannelFeatureSpade, prev.DisableSpade, e.DisableSpade},
+		} {
+			if ft.now != ft.was {
+				f.applyChannelFeature(ch, ft.name, !ft.now)
+			}
+		}
+	}
+
+	var removed []string
+	for login := range old {
+		if !seen[login] {
+			removed = append(removed, login)
+			f.unresolved.forget(login)
+		}
+	}
+	sort.Strings(removed)
+	for _, login := range removed {
+		ch, ok := f.channels.GetByLogin(login)
+		if !ok || ch.Snapshot().IsTemporary {
+			continue
+		}
+		f.untrackChannel(ch)
+		f.addLog("Removed channel: %s", ch.DisplayName)
+	}
+}
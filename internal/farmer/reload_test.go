@@ -0,0 +1,48 @@
+package farmer
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+func TestUnresolvedChannels(t *testing.T) {
+	var u unresolvedChannels
+	if !u.begin("ghost", false) {
+		t.Fatal("first begin refused")
+	}
+	if u.begin("ghost", true) {
+		t.Error("begin while resolving accepted")
+	}
+	u.done("ghost", errors.New("not found"))
+	if u.begin("ghost", false) {
+		t.Error("failed login resolved again without retry")
+	}
+	if !u.begin("ghost", true) {
+		t.Error("retry of a failed login refused")
+	}
+	u.done("ghost", nil)
+	if !u.begin("ghost", false) {
+		t.Error("resolved login still refused")
+	}
+}
+
+// TestApplyChannelEntries_SkipsUnresolved: an entry that failed to
+// resolve isn't looked up again on every reload (the farmer has no GQL
+// client, so a lookup would panic), and is forgotten once removed.
+func TestApplyChannelEntries_SkipsUnresolved(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+	f.unresolved.done("ghost", errors.New("not found"))
+	entries := append(cfg.GetChannelEntries(), config.ChannelEntry{Login: "ghost", Priority: 2})
+
+	f.applyChannelEntries(entries, entries)
+	if resolving, failed := f.unresolved.logins["ghost"]; resolving || !failed {
+		t.Fatalf("ghost resolving=%v failed=%v after a reload, want still failed", resolving, failed)
+	}
+
+	f.applyChannelEntries(entries, cfg.GetChannelEntries())
+	if _, ok := f.unresolved.logins["ghost"]; ok {
+		t.Error("removed entry still marked unresolved")
+	}
+}
@@ -0,0 +1,65 @@
+package farmer
+
+import (
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// checkRename is the points Service's LoginGone hook: a tracked
+// channel's login no longer resolves, so it is looked up by its ID. If
+// the streamer changed the login, the channel is migrated to the new
+// one; if the ID is gone too, the channel was deleted or banned and is
+// left for the user to remove. Temporary drop channels are skipped —
+// the drops selector replaces them on its own.
+func (f *Farmer) checkRename(ch *channels.State) {
+	snap := ch.Snapshot()
+	if snap.IsTemporary {
+		return
+	}
+	info, err := f.gql.GetChannelInfoByID(ch.ChannelID)
+	if err != nil {
+		f.logWarn("Warning: channel %s no longer resolves and could not be looked up by ID %s: %v", ch.Login, ch.ChannelID, err)
+		return
+	}
+	if info.Login == "" || info.Login == ch.Login {
+		return // a lookup hiccup, not a rename
+	}
+	f.renameChannel(ch, info)
+}
+
+// renameChannel moves a tracked channel to the new login in info: the
+// config entry and the earnings history are migrated, and the channel
+// is registered again under the new login (its session counters start
+// over).
+func (f *Farmer) renameChannel(ch *channels.State, info *twitch.ChannelInfo) {
+	oldLogin := ch.Login
+	if !f.migrateRename(ch.ChannelID, oldLogin, info.Login) {
+		return // another lookup applied this rename first
+	}
+	f.cfg.SaveSoon()
+
+	f.channels.Remove(ch.ChannelID)
+	f.spade.StopWatching(ch.ChannelID)
+	f.prober.Stop(oldLogin)
+	f.releaseChannelTopics(ch.ChannelID)
+	f.points.NotifyChannelRemoved(oldLogin)
+
+	if err := f.addChannelWithInfo(info); err != nil {
+		f.logError("Failed to register renamed channel %s: %v", info.Login, err)
+	}
+}
+
+// migrateRename points the config entry and the earnings history at
+// the new login and logs the rename. It reports false, doing nothing,
+// when the config entry no longer has oldLogin (someone else renamed it
+// already). The caller saves the config.
+func (f *Farmer) migrateRename(channelID, oldLogin, newLogin string) bool {
+	if !f.cfg.RenameChannel(channelID, oldLogin, newLogin) {
+		return false
+	}
+	f.addLog("Channel renamed: %s → %s (ID: %s)", oldLogin, newLogin, channelID)
+	if _, err := f.history.RenameLogin(channelID, newLogin); err != nil {
+		f.logWarn("[History] Warning: could not move %s's history to %s: %v", oldLogin, newLogin, err)
+	}
+	return true
+}
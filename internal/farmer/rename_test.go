@@ -0,0 +1,37 @@
+package farmer
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestMigrateRename_Once: lookups racing to apply the same rename
+// migrate the config entry once; the others back off.
+func TestMigrateRename_Once(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+
+	var wg sync.WaitGroup
+	var applied atomic.Int32
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if f.migrateRename("1", "alpha", "alpha_new") {
+				applied.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := applied.Load(); n != 1 {
+		t.Fatalf("rename applied %d times, want 1", n)
+	}
+	if logins := cfg.GetChannelLogins(); len(logins) != 1 || logins[0] != "alpha_new" {
+		t.Fatalf("config logins = %v, want [alpha_new]", logins)
+	}
+	if n := strings.Count(strings.Join(settingLogs(f), "\n"), "Channel renamed"); n != 1 {
+		t.Fatalf("rename logged %d times, want 1", n)
+	}
+}
@@ -0,0 +1,123 @@
+package farmer
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// initPubSubRecording starts appending raw PubSub messages to
+// pubsub_record_file, if set. A file that can't be opened only costs
+// the capture.
+func (f *Farmer) initPubSubRecording() {
+	path := f.cfg.GetPubSubRecordFile()
+	if path == "" {
+		return
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(f.cfg.Path()), path)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		f.logWarn("[Replay] Could not open PubSub capture %s: %v", path, err)
+		return
+	}
+	f.recordFile = file
+	f.pubsub.RecordTo(file)
+	f.addLog("[Replay] Recording raw PubSub messages to %s", path)
+}
+
+// ReplayAction is what the farmer would do about one replayed event.
+type ReplayAction struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel,omitempty"`
+	Action  string `json:"action"`
+}
+
+// ReplayPubSub runs a capture file (pubsub_record_file) through the
+// PubSub parsers as a dry run: nothing is claimed, joined or changed,
+// and no GQL request is made. It returns, per parsed event, what the
+// live event handling would do with it.
+func (f *Farmer) ReplayPubSub(r io.Reader) ([]ReplayAction, error) {
+	actions := []ReplayAction{}
+	n, err := twitch.ReplayPubSub(r, func(ev twitch.FarmerEvent) {
+		actions = append(actions, f.replayAction(ev))
+	})
+	if err != nil {
+		f.logWarn("[Replay] Dry run stopped after %d messages: %v", n, err)
+		return actions, fmt.Errorf("replay: %w", err)
+	}
+	f.addLog("[Replay] Dry run of %d PubSub messages: %d events", n, len(actions))
+	return actions, nil
+}
+
+// replayAction describes what handleEvent would do with evt, reading
+// only local state.
+func (f *Farmer) replayAction(evt twitch.FarmerEvent) ReplayAction {
+	a := ReplayAction{Event: evt.Type.String(), Channel: evt.ChannelID}
+	ch, tracked := f.channels.Get(evt.ChannelID)
+	if tracked {
+		a.Channel = ch.DisplayName
+	}
+	paused := f.paused.Load()
+
+	switch data := evt.Data.(type) {
+	case twitch.ClaimData:
+		a.Action = "claim bonus " + data.ClaimID
+		if paused {
+			a.Action = "skip: paused, left for the claim sweep"
+		}
+	case twitch.MomentData:
+		a.Action = "claim moment " + data.MomentID
+		if paused {
+			a.Action = "skip: paused"
+		}
+	case twitch.RaidData:
+		a.Action = "join raid to " + data.TargetDisplayName
+		if paused {
+			a.Action = "skip: paused"
+		}
+	case twitch.PointsData:
+		if evt.Type == twitch.EventPointsSpent {
+			a.Action = fmt.Sprintf("set balance to %d", data.TotalPoints)
+		} else {
+			a.Action = fmt.Sprintf("record +%d points (%s), balance %d", data.PointsGained, twitch.ReasonLabel(data.ReasonCode), data.TotalPoints)
+		}
+	case twitch.ViewCountData:
+		a.Action = fmt.Sprintf("set viewers to %d", data.Viewers)
+	case twitch.HypeTrainData:
+		a.Action = fmt.Sprintf("hype train level %d", data.Level)
+		if !data.Active {
+			a.Action = "hype train ended"
+		}
+	case twitch.UnknownPointsData:
+		a.Action = "log unhandled points message " + data.Type
+	case twitch.DropProgressData:
+		a.Action = fmt.Sprintf("update drop %s to %d/%d min", data.DropID, data.CurrentMinutesWatched, data.RequiredMinutesWatched)
+	case twitch.DropClaimData:
+		a.Action = "claim drop " + data.DropID
+	case twitch.GameChangeData:
+		a.Action = fmt.Sprintf("re-check drops: game %q -> %q", data.OldGameName, data.NewGameName)
+	case error:
+		a.Action = "parse error: " + data.Error()
+	default:
+		switch evt.Type {
+		case twitch.EventStreamUp:
+			a.Action = "mark live and fetch stream info"
+		case twitch.EventStreamDown:
+			a.Action = "mark offline"
+		}
+	}
+
+	switch evt.Type {
+	case twitch.EventHypeTrain, twitch.EventPointsSpent, twitch.EventStreamUp,
+		twitch.EventStreamDown, twitch.EventViewCount:
+		if !tracked {
+			a.Action = "ignore: channel not tracked"
+		}
+	}
+	return a
+}
@@ -0,0 +1,52 @@
+package farmer
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// TestReplayPubSub_DryRun: a replay only describes what would happen.
+// The farmer has no GQL client, so any real claim or raid join panics.
+func TestReplayPubSub_DryRun(t *testing.T) {
+	reg := channels.New()
+	reg.Add(channels.NewState("alpha", "Alpha", "1"))
+	f := &Farmer{channels: reg}
+
+	var capture bytes.Buffer
+	for _, rec := range []twitch.PubSubRecord{
+		{Topic: "community-points-user-v1.9", Message: `{"type":"claim-available","data":{"claim":{"id":"c1","channel_id":"1"}}}`},
+		{Topic: "raid.1", Message: `{"type":"raid_update_v2","raid":{"id":"r1","target_display_name":"Beta"}}`},
+		{Topic: "video-playback-by-id.2", Message: `{"type":"stream-up"}`},
+	} {
+		line, _ := json.Marshal(rec)
+		capture.Write(append(line, '\n'))
+	}
+
+	actions, err := f.ReplayPubSub(bytes.NewReader(capture.Bytes()))
+	if err != nil {
+		t.Fatalf("ReplayPubSub: %v", err)
+	}
+	want := []ReplayAction{
+		{Event: "claim_available", Channel: "Alpha", Action: "claim bonus c1"},
+		{Event: "raid", Channel: "Alpha", Action: "join raid to Beta"},
+		{Event: "stream_up", Channel: "2", Action: "ignore: channel not tracked"},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("actions = %+v, want %+v", actions, want)
+	}
+	for i := range want {
+		if actions[i] != want[i] {
+			t.Errorf("action %d = %+v, want %+v", i, actions[i], want[i])
+		}
+	}
+
+	f.paused.Store(true)
+	actions, _ = f.ReplayPubSub(bytes.NewReader(capture.Bytes()))
+	if len(actions) == 0 || actions[0].Action != "skip: paused, left for the claim sweep" {
+		t.Fatalf("paused claim = %+v", actions)
+	}
+}
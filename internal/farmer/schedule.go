@@ -0,0 +1,129 @@
+package farmer
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/idle"
+)
+
+// scheduleInterval is how often scheduleLoop checks the schedule and
+// the idle time.
+const scheduleInterval = 30 * time.Second
+
+// scheduleState is what the schedule has done to the global pause. It
+// only acts when its verdict changes, so a manual Resume inside a pause
+// window sticks, and a manual Pause is never lifted by the schedule.
+type scheduleState struct {
+	mu      sync.Mutex
+	want    bool   // the last verdict was "pause"
+	reason  string // why, while want is set
+	paused  bool   // the schedule made the current pause
+	idleErr bool   // an idle detection failure has been logged
+}
+
+// scheduleLoop applies the farming schedule (config.Schedule) until
+// the farmer stops.
+func (f *Farmer) scheduleLoop(stop <-chan struct{}) {
+	if err := f.cfg.GetSchedule().Validate(); err != nil {
+		f.logWarn("[Schedule] Warning: %v", err)
+	}
+	ticker := time.NewTicker(scheduleInterval)
+	defer ticker.Stop()
+	for {
+		f.applySchedule(time.Now())
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+	}
+}
+
+// applySchedule pauses or resumes farming when the schedule's verdict
+// for now differs from the last one.
+func (f *Farmer) applySchedule(now time.Time) {
+	st := &f.schedule
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	pause, reason := f.scheduleVerdict(now)
+	switch {
+	case pause && !st.want:
+		st.want, st.reason = true, reason
+		if !f.paused.Load() {
+			f.addLog("[Schedule] Pausing farming: %s", reason)
+			f.Pause()
+			st.paused = true
+		}
+	case !pause && st.want:
+		st.want, st.reason = false, ""
+		if st.paused {
+			st.paused = false
+			if f.paused.Load() {
+				f.addLog("[Schedule] Resuming farming")
+				f.Resume()
+			}
+		}
+	case pause:
+		st.reason = reason
+	}
+}
+
+// scheduleVerdict reports whether the schedule wants farming paused at
+// now, and why. An idle time that can't be read doesn't pause: the
+// failure is logged once and idle_only is ignored until it works.
+func (f *Farmer) scheduleVerdict(now time.Time) (bool, string) {
+	s := f.cfg.GetSchedule()
+	if w, ok := s.PauseWindow(now); ok {
+		return true, "pause window " + w.String()
+	}
+	if !s.IdleOnly {
+		return false, ""
+	}
+	d, err := f.idleTime()
+	if err != nil {
+		if !f.schedule.idleErr {
+			f.schedule.idleErr = true
+			f.logWarn("[Schedule] Warning: could not read the idle time (%v) — idle_only has no effect", err)
+		}
+		return false, ""
+	}
+	f.schedule.idleErr = false
+	if limit := s.IdleLimit(); d < time.Duration(limit)*time.Minute {
+		return true, fmt.Sprintf("computer in use (idle for less than %d min)", limit)
+	}
+	return false, ""
+}
+
+// idleTime returns how long the computer has had no user input.
+func (f *Farmer) idleTime() (time.Duration, error) {
+	if f.readIdle != nil {
+		return f.readIdle()
+	}
+	return idle.Time()
+}
+
+// SchedulePause returns why the farming schedule wants farming paused
+// right now, or "" when it doesn't.
+func (f *Farmer) SchedulePause() string {
+	f.schedule.mu.Lock()
+	defer f.schedule.mu.Unlock()
+	return f.schedule.reason
+}
+
+// SetSchedule validates, saves and applies a new farming schedule.
+func (f *Farmer) SetSchedule(s config.Schedule) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if !f.cfg.SetSchedule(s) {
+		return fmt.Errorf("invalid schedule")
+	}
+	f.cfg.SaveSoon()
+	f.addLog("[Schedule] Schedule updated")
+	f.applySchedule(time.Now())
+	return nil
+}
@@ -0,0 +1,78 @@
+package farmer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+)
+
+// TestApplySchedule_IdleOnly: idle_only pauses farming while the
+// computer is in use and resumes it once idle_minutes (default 10) have
+// passed without input; an idle time that can't be read never pauses.
+func TestApplySchedule_IdleOnly(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+	f.drops = drops.NewService(drops.ServiceDeps{Cfg: cfg, Channels: f.channels, Log: func(string, ...interface{}) {}})
+	cfg.DropsEnabled = false
+	if err := f.SetSchedule(config.Schedule{IdleOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	if got := cfg.GetSchedule(); got.IdleMinutes != 0 {
+		t.Errorf("stored idle_minutes = %d, want it left unset", got.IdleMinutes)
+	}
+
+	idle, idleErr := 2*time.Minute, error(nil)
+	f.readIdle = func() (time.Duration, error) { return idle, idleErr }
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.Local)
+
+	f.applySchedule(now)
+	if !f.IsPaused() || f.SchedulePause() != "computer in use (idle for less than 10 min)" {
+		t.Fatalf("in use: paused=%v reason=%q", f.IsPaused(), f.SchedulePause())
+	}
+	idle = 11 * time.Minute
+	f.applySchedule(now)
+	if f.IsPaused() || f.SchedulePause() != "" {
+		t.Fatalf("idle: paused=%v reason=%q", f.IsPaused(), f.SchedulePause())
+	}
+	idle, idleErr = 0, errors.New("no idle source")
+	f.applySchedule(now)
+	if f.IsPaused() {
+		t.Error("an unreadable idle time paused farming")
+	}
+}
+
+// TestApplySchedule_ManualOverride: the schedule only acts when its
+// verdict changes. A manual resume inside a pause window sticks, and a
+// manual pause outside one is not lifted.
+func TestApplySchedule_ManualOverride(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+	f.drops = drops.NewService(drops.ServiceDeps{Cfg: cfg, Channels: f.channels, Log: func(string, ...interface{}) {}})
+	cfg.DropsEnabled = false
+	f.readIdle = func() (time.Duration, error) { return 0, errors.New("not used") }
+	if err := f.SetSchedule(config.Schedule{PauseWindows: []string{"02:00-08:00"}}); err != nil {
+		t.Fatal(err)
+	}
+	at := func(h int) time.Time { return time.Date(2026, 3, 1, h, 0, 0, 0, time.Local) }
+
+	f.applySchedule(at(3))
+	if !f.IsPaused() {
+		t.Fatal("not paused inside the window")
+	}
+	f.Resume()
+	f.applySchedule(at(4))
+	if f.IsPaused() {
+		t.Error("a manual resume inside the window was undone")
+	}
+	f.applySchedule(at(9))
+	f.Pause()
+	f.applySchedule(at(10))
+	if !f.IsPaused() {
+		t.Error("a manual pause outside the window was lifted")
+	}
+
+	if err := f.SetSchedule(config.Schedule{PauseWindows: []string{"2-8"}}); err == nil {
+		t.Error("SetSchedule accepted an invalid window")
+	}
+}
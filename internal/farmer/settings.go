@@ -0,0 +1,195 @@
+package farmer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// ChannelSettings are the per-channel settings of a configured channel
+// that can be edited at runtime (the TUI settings form and
+// /api/channels/{login}/settings). The presence switches are positive
+// here, unlike the inverted disable_* keys in config.json. Redeem rules
+// are not part of it.
+type ChannelSettings struct {
+	Priority  int    `json:"priority"` // 1 = always watch, 2 = rotate
+	Paused    bool   `json:"paused"`
+	Moments   bool   `json:"moments"`
+	Goal      int    `json:"goal"`       // 0 = no goal
+	MaxPoints int    `json:"max_points"` // 0 = no cap
+	Mode      string `json:"mode"`       // "both", "points" or "drops"
+	IRC       bool   `json:"irc"`
+	PubSub    bool   `json:"pubsub"`
+	Spade     bool   `json:"spade"`
+}
+
+// DefaultChannelSettings are the settings of a freshly added channel.
+func DefaultChannelSettings() ChannelSettings {
+	return ChannelSettings{Priority: 2, Moments: true, Mode: "both", IRC: true, PubSub: true, Spade: true}
+}
+
+// GetChannelSettings returns a configured channel's settings.
+func (f *Farmer) GetChannelSettings(login string) (ChannelSettings, error) {
+	login = strings.ToLower(login)
+	if !f.cfg.HasChannel(login) {
+		return ChannelSettings{}, fmt.Errorf("channel %s not in config", login)
+	}
+	mode := f.cfg.GetChannelMode(login)
+	if mode == config.ChannelModeBoth {
+		mode = "both"
+	}
+	return ChannelSettings{
+		Priority:  f.cfg.GetPriority(login),
+		Paused:    f.cfg.IsChannelPaused(login),
+		Moments:   f.cfg.IsMomentsEnabled(login),
+		Goal:      f.cfg.GetPointsGoal(login),
+		MaxPoints: f.cfg.GetMaxPoints(login),
+		Mode:      mode,
+		IRC:       f.cfg.IsChannelFeatureEnabled(login, config.ChannelFeatureIRC),
+		PubSub:    f.cfg.IsChannelFeatureEnabled(login, config.ChannelFeaturePubSub),
+		Spade:     f.cfg.IsChannelFeatureEnabled(login, config.ChannelFeatureSpade),
+	}, nil
+}
+
+// SetChannelSettingsLive replaces a configured channel's settings. s is
+// validated as a whole first; then every field that changed goes
+// through its own Set*Live method, so each change takes effect and is
+// logged as if it had been made on its own.
+func (f *Farmer) SetChannelSettingsLive(login string, s ChannelSettings) error {
+	login = strings.ToLower(login)
+	cur, err := f.GetChannelSettings(login)
+	if err != nil {
+		return err
+	}
+	if s.Priority != 1 && s.Priority != 2 {
+		return fmt.Errorf("priority must be 1 or 2")
+	}
+	if s.Goal < 0 {
+		return fmt.Errorf("goal must not be negative")
+	}
+	if s.MaxPoints < 0 {
+		return fmt.Errorf("max_points must not be negative")
+	}
+	switch s.Mode = strings.ToLower(strings.TrimSpace(s.Mode)); s.Mode {
+	case config.ChannelModeBoth:
+		s.Mode = "both"
+	case "both", config.ChannelModePoints, config.ChannelModeDrops:
+	default:
+		return fmt.Errorf("mode must be both, points or drops")
+	}
+
+	if s.Priority != cur.Priority {
+		if err := f.SetPriorityLive(login, s.Priority); err != nil {
+			return err
+		}
+	}
+	if s.Paused != cur.Paused {
+		if err := f.SetPausedLive(login, s.Paused); err != nil {
+			return err
+		}
+	}
+	if s.Moments != cur.Moments {
+		if err := f.SetMomentsEnabledLive(login, s.Moments); err != nil {
+			return err
+		}
+	}
+	if s.Goal != cur.Goal {
+		if err := f.SetPointsGoalLive(login, s.Goal); err != nil {
+			return err
+		}
+	}
+	if s.MaxPoints != cur.MaxPoints {
+		if err := f.SetMaxPointsLive(login, s.MaxPoints); err != nil {
+			return err
+		}
+	}
+	if s.Mode != cur.Mode {
+		if err := f.SetChannelModeLive(login, s.Mode); err != nil {
+			return err
+		}
+	}
+	features := []struct {
+		name     string
+		cur, new bool
+	}{
+		{config.ChannelFeatureIRC, cur.IRC, s.IRC},
+		{config.ChannelFeaturePubSub, cur.PubSub, s.PubSub},
+		{config.ChannelFeatureSpade, cur.Spade, s.Spade},
+	}
+	for _, ft := range features {
+		if ft.new != ft.cur {
+			if err := f.SetChannelFeatureLive(login, ft.name, ft.new); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// SetChannelFeatureLive turns a presence mechanism
+// (config.ChannelFeature*) on or off for a configured channel and
+// applies it right away: IRC is joined or parted, the channel's PubSub
+// topics are subscribed or released, and the rotation re-runs so a
+// Spade slot is freed or filled. A Spade switch also re-runs the drop
+// selection.
+func (f *Farmer) SetChannelFeatureLive(login, feature string, enabled bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	login = strings.ToLower(login)
+	if !f.cfg.HasChannel(login) {
+		return fmt.Errorf("channel %s not in config", login)
+	}
+	if f.cfg.IsChannelFeatureEnabled(login, feature) == enabled {
+		return nil
+	}
+	if !f.cfg.SetChannelFeatureEnabled(login, feature, enabled) {
+		return fmt.Errorf("unknown feature %q (want irc, pubsub or spade)", feature)
+	}
+	f.cfg.SaveSoon()
+
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	f.addLog("%s for %s: %s", strings.ToUpper(feature), login, state)
+
+	// Not resolved yet: addChannelWithInfo reads the new value.
+	if ch, ok := f.channels.GetByLogin(login); ok {
+		f.applyChannelFeature(ch, feature, enabled)
+	}
+	return nil
+}
+
+// applyChannelFeature applies a feature switch already in the config
+// to a tracked channel.
+func (f *Farmer) applyChannelFeature(ch *channels.State, feature string, enabled bool) {
+	login := ch.Login
+	switch feature {
+	case config.ChannelFeatureIRC:
+		if enabled {
+			f.points.NotifyChannelAdded(login)
+		} else {
+			f.points.NotifyChannelRemoved(login)
+		}
+	case config.ChannelFeaturePubSub:
+		if enabled {
+			if err := f.listenChannelTopics(ch.ChannelID); err != nil {
+				f.logWarn("PubSub subscribe error for %s: %v", login, err)
+			}
+		} else {
+			f.releaseChannelTopics(ch.ChannelID)
+		}
+	case config.ChannelFeatureSpade:
+		if !enabled {
+			f.points.StopWatching(ch)
+		}
+		// The drop selection skips channels without Spade; re-run it so
+		// the pick moves off (or back onto) this one.
+		if f.drops != nil {
+			go f.drops.ProcessDrops()
+		}
+	}
+	go f.points.Rotate()
+}
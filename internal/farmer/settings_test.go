@@ -0,0 +1,121 @@
+package farmer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/points"
+)
+
+// newSettingsTestFarmer returns a farmer tracking one configured,
+// offline channel "alpha" whose config lives in a temp dir.
+func newSettingsTestFarmer(t *testing.T) (*Farmer, *config.Config) {
+	t.Helper()
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	t.Cleanup(func() { _ = cfg.Flush() })
+	if !cfg.AddChannel("alpha") || !cfg.SetMaxPoints("alpha", 1000) {
+		t.Fatal("setting up alpha failed")
+	}
+
+	reg := channels.New()
+	st := channels.NewState("alpha", "Alpha", "1")
+	st.SetPriority(cfg.GetPriority("alpha"))
+	reg.Add(st)
+	f := &Farmer{cfg: cfg, channels: reg}
+	f.points = points.NewService(points.ServiceDeps{
+		Cfg:      cfg,
+		Channels: reg,
+		Drops:    &drops.Service{},
+		Log:      func(string, ...interface{}) {},
+	})
+	return f, cfg
+}
+
+// settingLogs returns the farmer's log messages.
+func settingLogs(f *Farmer) []string {
+	var msgs []string
+	for _, e := range f.GetLogs() {
+		msgs = append(msgs, e.Message)
+	}
+	return msgs
+}
+
+// TestSetChannelSettingsLive_AppliesOnlyChanges: changed fields reach
+// the config through their own setters; the others aren't touched.
+func TestSetChannelSettingsLive_AppliesOnlyChanges(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+
+	s, err := f.GetChannelSettings("alpha")
+	if err != nil {
+		t.Fatalf("GetChannelSettings: %v", err)
+	}
+	s.Priority = 1
+	s.Goal = 500
+	s.Paused = true
+	if err := f.SetChannelSettingsLive("Alpha", s); err != nil {
+		t.Fatalf("SetChannelSettingsLive: %v", err)
+	}
+
+	if got := cfg.GetPriority("alpha"); got != 1 {
+		t.Errorf("priority = %d, want 1", got)
+	}
+	if got := cfg.GetPointsGoal("alpha"); got != 500 {
+		t.Errorf("goal = %d, want 500", got)
+	}
+	if !cfg.IsChannelPaused("alpha") {
+		t.Error("channel not paused in config")
+	}
+	if got := cfg.GetMaxPoints("alpha"); got != 1000 {
+		t.Errorf("max_points = %d, want 1000 (unchanged)", got)
+	}
+	if !cfg.IsMomentsEnabled("alpha") || cfg.GetChannelMode("alpha") != config.ChannelModeBoth {
+		t.Error("unchanged moments or mode was rewritten")
+	}
+
+	logs := strings.Join(settingLogs(f), "\n")
+	for _, want := range []string{"PRIORITY", "Points goal for alpha: 500", "Paused Alpha"} {
+		if !strings.Contains(logs, want) {
+			t.Errorf("log lacks %q:\n%s", want, logs)
+		}
+	}
+	for _, unwanted := range []string{"max_points", "Moments", "points and drops", "IRC", "SPADE"} {
+		if strings.Contains(logs, unwanted) {
+			t.Errorf("no-op field logged %q:\n%s", unwanted, logs)
+		}
+	}
+}
+
+// TestSetChannelSettingsLive_RejectsBeforeApplying: one bad field
+// fails the call with nothing applied, and an unchanged body is a no-op.
+func TestSetChannelSettingsLive_RejectsBeforeApplying(t *testing.T) {
+	f, cfg := newSettingsTestFarmer(t)
+	s, _ := f.GetChannelSettings("alpha")
+
+	bad := s
+	bad.Goal = 500
+	bad.Mode = "sometimes"
+	if err := f.SetChannelSettingsLive("alpha", bad); err == nil {
+		t.Fatal("unknown mode accepted")
+	}
+	if got := cfg.GetPointsGoal("alpha"); got != 0 {
+		t.Fatalf("goal = %d after a rejected call, want 0", got)
+	}
+
+	if err := f.SetChannelSettingsLive("alpha", s); err != nil {
+		t.Fatalf("unchanged settings: %v", err)
+	}
+	if logs := settingLogs(f); len(logs) != 0 {
+		t.Fatalf("unchanged settings logged %q", logs)
+	}
+
+	if err := f.SetChannelSettingsLive("nobody", s); err == nil {
+		t.Fatal("unknown channel accepted")
+	}
+}
@@ -0,0 +1,45 @@
+package farmer
+
+import (
+	"math/rand/v2"
+	"time"
+)
+
+// waitStartupDelay sleeps a random time up to startup_delay_seconds so
+// that many instances restarting together (after a power cut) don't all
+// connect at the same second. Returns false if stop closes meanwhile.
+func (f *Farmer) waitStartupDelay(stop <-chan struct{}) bool {
+	max := f.cfg.GetStartupDelay()
+	if max <= 0 {
+		return true
+	}
+	d := rand.N(max)
+	f.addLog("Startup delay: connecting in %s", d.Round(time.Second))
+	select {
+	case <-time.After(d):
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+// staggered runs fn in its own goroutine, step connect_stagger_seconds
+// after the call plus up to half a gap of jitter, so the farmer's
+// connections don't all open at once. With no stagger configured fn
+// starts right away. fn is skipped if the farmer stops first.
+func (f *Farmer) staggered(step int, fn func()) {
+	gap := f.cfg.GetConnectStagger()
+	if gap <= 0 || step <= 0 {
+		go fn()
+		return
+	}
+	d := time.Duration(step)*gap + rand.N(gap/2+1)
+	stop := f.stopCh
+	go func() {
+		select {
+		case <-time.After(d):
+			fn()
+		case <-stop:
+		}
+	}()
+}
@@ -0,0 +1,102 @@
+package farmer
+
+import (
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// streamDownState holds stream-down events waiting out the network
+// profile's StreamDownGrace.
+type streamDownState struct {
+	mu      sync.Mutex
+	grace   time.Duration          // fixed for the session
+	pending map[string]*time.Timer // channel ID -> deferred offline handling
+}
+
+// initNetwork installs the configured network profile for all Twitch
+// clients. Called before any client is created.
+func (f *Farmer) initNetwork() {
+	profile := twitch.DefaultNetwork
+	if f.cfg.GetNetworkProfile() == config.NetworkProfileFlaky {
+		profile = twitch.FlakyNetwork
+		f.addLog("[Network] Using the flaky-connection profile (stream-down grace %v)", profile.StreamDownGrace)
+	}
+	twitch.SetNetworkProfile(profile)
+	if p := twitch.ActiveProxy(); p != "" {
+		f.addLog("[Network] Routing Twitch traffic through proxy %s", p)
+	}
+	f.streamDown.grace = profile.StreamDownGrace
+	f.streamDown.pending = make(map[string]*time.Timer)
+}
+
+// handleStreamDown acts on a stream-down event, after the grace period
+// when one is configured. A repeated stream-down keeps the first timer.
+func (f *Farmer) handleStreamDown(ch *channels.State) {
+	sd := &f.streamDown
+	if sd.grace <= 0 {
+		f.streamWentOffline(ch)
+		return
+	}
+
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	if _, ok := sd.pending[ch.ChannelID]; ok {
+		return
+	}
+	f.debugLog("[Network] %s reported offline, waiting %v before acting on it", ch.Login, sd.grace)
+	sd.pending[ch.ChannelID] = time.AfterFunc(sd.grace, func() {
+		sd.mu.Lock()
+		delete(sd.pending, ch.ChannelID)
+		sd.mu.Unlock()
+
+		if f.stopped.Load() {
+			return
+		}
+		// Removed (or re-added as a new State) while we waited.
+		if cur, ok := f.channels.Get(ch.ChannelID); !ok || cur != ch {
+			return
+		}
+		f.streamWentOffline(ch)
+	})
+}
+
+// cancelStreamDown drops a pending stream-down for the channel and
+// reports whether there was one.
+func (f *Farmer) cancelStreamDown(channelID string) bool {
+	sd := &f.streamDown
+	sd.mu.Lock()
+	defer sd.mu.Unlock()
+	t, ok := sd.pending[channelID]
+	if !ok {
+		return false
+	}
+	t.Stop()
+	delete(sd.pending, channelID)
+	return true
+}
+
+// streamWentOffline marks the channel offline and frees its watch slot.
+func (f *Farmer) streamWentOffline(ch *channels.State) {
+	snap := ch.Snapshot()
+
+	ch.SetOffline()
+	f.spade.StopWatching(ch.ChannelID)
+	f.prober.Stop(ch.Login)
+	f.addLog("%s went OFFLINE", ch.DisplayName)
+
+	// If the drop pick (or a temp drop channel) just went offline,
+	// drops stops the Watcher and re-selects out of cycle so the
+	// campaign gets a replacement channel within seconds instead
+	// of waiting up to 15 minutes for the next inventory cycle.
+	// Keyed on pick/temp status rather than HasActiveDrop, which
+	// is already clear when the pick sits between drops.
+	// Non-drops channels go through the normal slot-fill path only.
+	f.drops.HandleStreamDown(snap)
+
+	// Try to fill freed Spade slot
+	f.points.FillSpadeSlots()
+}
@@ -0,0 +1,347 @@
+package farmer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Subsystems that can be switched on and off at runtime
+// (SetSubsystemEnabled, /api/subsystems).
+const (
+	SubsystemIRC           = "irc"           // IRC viewer presence (irc_enabled)
+	SubsystemDrops         = "drops"         // drops inventory polling and mining (drops_enabled)
+	SubsystemUpdates       = "updates"       // GitHub release check (disable_update_check)
+	SubsystemNotifications = "notifications" // Telegram notifications (disable_notifications)
+)
+
+// subsystemNames lists the subsystems in the order Subsystems reports them.
+var subsystemNames = []string{SubsystemIRC, SubsystemDrops, SubsystemUpdates, SubsystemNotifications}
+
+// Subsystem is the state of one runtime-switchable subsystem. Enabled is
+// the configured switch; Running whether it is active in this process
+// (false for all of them until Start, and for notifications without a
+// Telegram bot).
+type Subsystem struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	Running bool   `json:"running"`
+}
+
+// subsystemState holds the stop channels of the loops started by
+// startDropLoops and startUpdateCheck; nil = not running. mu also
+// serializes SetSubsystemEnabled.
+type subsystemState struct {
+	mu          sync.Mutex
+	dropsStop   chan struct{}
+	updatesStop chan struct{}
+}
+
+// stopAll stops the switchable loops (Farmer.Stop).
+func (s *subsystemState) stopAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, ch := range []*chan struct{}{&s.dropsStop, &s.updatesStop} {
+		if *ch != nil {
+			close(*ch)
+			*ch = nil
+		}
+	}
+}
+
+// Subsystems returns the state of every runtime-switchable subsystem.
+func (f *Farmer) Subsystems() []Subsystem {
+	s := &f.subsys
+	s.mu.Lock()
+	dropsRunning, updatesRunning := s.dropsStop != nil, s.updatesStop != nil
+	s.mu.Unlock()
+	_, telegram := f.cfg.GetTelegram()
+
+	out := make([]Subsystem, 0, len(subsystemNames))
+	for _, name := range subsystemNames {
+		sub := Subsystem{Name: name}
+		switch name {
+		case SubsystemIRC:
+			sub.Enabled, sub.Running = f.cfg.GetIrcEnabled(), f.irc.Load() != nil
+		case SubsystemDrops:
+			sub.Enabled, sub.Running = f.cfg.GetDropsEnabled(), dropsRunning
+		case SubsystemUpdates:
+			sub.Enabled, sub.Running = f.cfg.GetUpdateCheckEnabled(), updatesRunning
+		case SubsystemNotifications:
+			sub.Enabled = f.cfg.GetNotificationsEnabled()
+			sub.Running = sub.Enabled && telegram && f.started.Load()
+		}
+		out = append(out, sub)
+	}
+	return out
+}
+
+// SetSubsystemEnabled switches a subsystem (Subsystem*) on or off, saves
+// the config and, once the farmer has started, applies it right away:
+// IRC connects or disconnects, the drops loops start or stop (releasing
+// the drop channel), the update check starts or stops. Notifications
+// are checked on every send, so the saved switch is all they need.
+func (f *Farmer) SetSubsystemEnabled(name string, enabled bool) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	name = strings.ToLower(strings.TrimSpace(name))
+	s := &f.subsys
+	s.mu.Lock()
+	switch name {
+	case SubsystemIRC:
+		f.cfg.SetIrcEnabled(enabled)
+	case SubsystemDrops:
+		f.cfg.SetDropsEnabled(enabled)
+	case SubsystemUpdates:
+		f.cfg.SetUpdateCheckEnabled(enabled)
+	case SubsystemNotifications:
+		f.cfg.SetNotificationsEnabled(enabled)
+	default:
+		s.mu.Unlock()
+		return fmt.Errorf("unknown subsystem %q (want %s)", name, strings.Join(subsystemNames, ", "))
+	}
+	s.mu.Unlock()
+	f.cfg.SaveSoon()
+
+	state := "off"
+	if enabled {
+		state = "on"
+	}
+	f.addLog("Subsystem %s switched %s", name, state)
+
+	if !f.started.Load() || f.stopped.Load() {
+		return nil // Start reads the saved switches
+	}
+	switch name {
+	case SubsystemIRC:
+		if enabled {
+			f.startIRC()
+		} else {
+			f.stopIRC()
+		}
+	case SubsystemDrops:
+		if enabled {
+			f.startDropLoops()
+			f.drops.CheckNow()
+		} else {
+			f.stopDropLoops()
+		}
+	case SubsystemUpdates:
+		if enabled {
+			f.startUpdateCheck()
+		} else {
+			f.stopUpdateCheck()
+		}
+	}
+	return nil
+}
+
+// SetIrcMode sets which channels IRC joins (config.IrcMode*), saves the
+// config and applies it: "off" disconnects IRC, the others connect it if
+// needed and re-sync the join list.
+func (f *Farmer) SetIrcMode(mode string) error {
+	f.runMu.RLock()
+	defer f.runMu.RUnlock()
+	if !f.cfg.SetIrcMode(mode) {
+		return config.CheckIrcMode(mode)
+	}
+	f.cfg.SaveSoon()
+	mode = f.cfg.GetIrcMode()
+	f.addLog("[IRC] Presence mode: %s", mode)
+
+	if !f.started.Load() || f.stopped.Load() {
+		return nil
+	}
+	if mode == config.IrcModeOff {
+		f.stopIRC()
+		return nil
+	}
+	f.startIRC()
+	f.points.SyncIRC()
+	return nil
+}
+
+// startIRC connects a new IRC client for viewer presence, if IRC is
+// enabled and none is running. The client gets the current token under
+// tokenMu so a concurrent SetAuthToken can't leave it on the old one.
+func (f *Farmer) startIRC() {
+	s := &f.subsys
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !f.cfg.GetIrcEnabled() || f.irc.Load() != nil {
+		return
+	}
+	f.tokenMu.Lock()
+	irc := f.newIRCClient(f.cfg.GetAuthToken())
+	irc.SetReadyHook(f.points.SyncIRC)
+	f.irc.Store(irc)
+	f.points.SetIRC(irc)
+	f.tokenMu.Unlock()
+	go irc.Connect()
+}
+
+// ircTokenRetry is how long IRC stays a guest when irc_auth_token
+// couldn't be checked (network error, 5xx) before trying it again.
+const ircTokenRetry = time.Minute
+
+// newIRCClient creates an IRC client logging in with token, as the
+// irc_auth_token account, or as an anonymous guest with irc_anonymous.
+// A rejected irc_auth_token falls back to a guest, never to the main
+// token — keeping the main account out of chat is the point of it. One
+// that couldn't be checked joins as a guest until retryIRCLogin gets
+// an answer.
+func (f *Farmer) newIRCClient(token string) *twitch.IRCClient {
+	c := f.ircClientFor(token)
+	c.Warn = f.logWarn
+	return c
+}
+
+// ircClientFor picks the IRC login for newIRCClient.
+func (f *Farmer) ircClientFor(token string) *twitch.IRCClient {
+	if f.cfg.GetIrcAnonymous() {
+		return twitch.NewAnonymousIRCClient(f.addLog)
+	}
+	if ircToken := f.cfg.GetIrcAuthToken(); ircToken != "" {
+		info, err := f.checkIRCToken(ircToken)
+		if errors.Is(err, twitch.ErrTokenInvalid) {
+			f.logWarn("[IRC] Warning: Twitch rejected irc_auth_token — joining as an anonymous guest")
+			return twitch.NewAnonymousIRCClient(f.addLog)
+		}
+		if err != nil {
+			f.logWarn("[IRC] Warning: irc_auth_token could not be checked (%v) — joining as a guest, retrying in %s", err, ircTokenRetry)
+			f.scheduleIRCRetry()
+			return twitch.NewAnonymousIRCClient(f.addLog)
+		}
+		f.addLog("[IRC] Chat presence as %s (irc_auth_token)", info.Login)
+		return twitch.NewIRCClient(ircToken, info.Login, f.addLog)
+	}
+	return twitch.NewIRCClient(token, f.user.Login, f.addLog)
+}
+
+// checkIRCToken validates an irc_auth_token.
+func (f *Farmer) checkIRCToken(token string) (*twitch.TokenInfo, error) {
+	if f.validateIRC != nil {
+		return f.validateIRC(token)
+	}
+	return twitch.ValidateToken(token)
+}
+
+// scheduleIRCRetry runs retryIRCLogin after ircTokenRetry, unless a
+// retry is already scheduled.
+func (f *Farmer) scheduleIRCRetry() {
+	if f.ircRetryPending.CompareAndSwap(false, true) {
+		time.AfterFunc(ircTokenRetry, f.retryIRCLogin)
+	}
+}
+
+// retryIRCLogin checks irc_auth_token again for an IRC client that
+// joined as a guest because it couldn't be checked, and reconnects as
+// the account once Twitch accepts it. Another failure to check it
+// schedules the next try; a rejection leaves the guest in place.
+func (f *Farmer) retryIRCLogin() {
+	f.ircRetryPending.Store(false)
+	if f.stopped.Load() {
+		return
+	}
+	if !f.started.Load() { // (re)starting: try again once it's up
+		f.scheduleIRCRetry()
+		return
+	}
+	irc := f.irc.Load()
+	token := f.cfg.GetIrcAuthToken()
+	if irc == nil || !irc.Anonymous() || f.cfg.GetIrcAnonymous() || token == "" {
+		return
+	}
+	_, err := f.checkIRCToken(token)
+	if errors.Is(err, twitch.ErrTokenInvalid) {
+		f.logWarn("[IRC] Warning: Twitch rejected irc_auth_token — staying an anonymous guest")
+		return
+	}
+	if err != nil {
+		f.scheduleIRCRetry()
+		return
+	}
+	f.stopIRC()
+	f.startIRC()
+	f.points.SyncIRC()
+}
+
+// stopIRC disconnects IRC; channels leave viewer presence with it.
+func (f *Farmer) stopIRC() {
+	s := &f.subsys
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	irc := f.irc.Swap(nil)
+	if irc == nil {
+		return
+	}
+	f.points.SetIRC(nil)
+	irc.Close()
+}
+
+// startDropLoops starts the drops worker, the 15-minute inventory check
+// and the progress poll, if drops are enabled and they aren't running.
+// ProcessLoop is the single worker goroutine that drains
+// drops.processQueue — every trigger source (CheckLoop ticker, claim
+// handler, game-change debounce, stream-down, UI/Web toggle) calls
+// drops.ProcessDrops, a non-blocking enqueue, so without ProcessLoop
+// kicks would queue indefinitely.
+func (f *Farmer) startDropLoops() {
+	s := &f.subsys
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !f.cfg.GetDropsEnabled() || s.dropsStop != nil || f.stopped.Load() {
+		return
+	}
+	stop := make(chan struct{})
+	s.dropsStop = stop
+	go f.drops.ProcessLoop(stop)
+	go f.drops.CheckLoop(stop)
+	go f.drops.ProgressPollLoop(stop)
+}
+
+// stopDropLoops stops the drops loops and gives up the drop channel:
+// the Watcher session and its Spade slot stop, and temporary channels
+// added for drops are removed.
+func (f *Farmer) stopDropLoops() {
+	s := &f.subsys
+	s.mu.Lock()
+	stop := s.dropsStop
+	s.dropsStop = nil
+	s.mu.Unlock()
+	if stop == nil {
+		return
+	}
+	close(stop)
+	f.drops.Suspend()
+	f.drops.CleanupNonPickedTemps(nil)
+	go f.points.Rotate()
+}
+
+// startUpdateCheck starts the update check loop unless it is running.
+func (f *Farmer) startUpdateCheck() {
+	s := &f.subsys
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updatesStop != nil || f.stopped.Load() {
+		return
+	}
+	s.updatesStop = make(chan struct{})
+	go f.updateCheckLoop(s.updatesStop)
+}
+
+// stopUpdateCheck stops the update check loop.
+func (f *Farmer) stopUpdateCheck() {
+	s := &f.subsys
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.updatesStop != nil {
+		close(s.updatesStop)
+		s.updatesStop = nil
+	}
+}
@@ -0,0 +1,275 @@
+package farmer
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// transportState routes each channel's stream up/down events through
+// either PubSub (video-playback-by-id) or EventSub (stream.online /
+// stream.offline). Every channel is on exactly one of them at a time so
+// the event loop never sees the same transition twice.
+type transportState struct {
+	mu           sync.Mutex
+	mode         string          // config.Transport* — fixed for the session
+	onEventSub   map[string]bool // channel IDs whose status comes from EventSub
+	pubsubDown   bool            // auto: PubSub failed, new channels go to EventSub
+	eventsubDown bool            // eventsub: EventSub failed, PubSub for the rest of the session
+}
+
+func videoPlaybackTopic(channelID string) string {
+	return fmt.Sprintf("video-playback-by-id.%s", channelID)
+}
+
+// initTransport reads the configured transport and, unless it's plain
+// PubSub, wires up the EventSub client. Called after f.pubsub exists and
+// before channels are registered.
+func (f *Farmer) initTransport() {
+	t := &f.transport
+	t.mode = f.cfg.GetTransport()
+	t.onEventSub = make(map[string]bool)
+	if t.mode == config.TransportPubSub {
+		return
+	}
+
+	f.eventsub = twitch.NewEventSubClient(f.cfg.GetAuthToken(), f.events, f.addLog)
+	f.eventsub.Warn = f.logWarn
+	f.eventsub.SetSubscribeErrorHook(f.onEventSubSubscribeError)
+	f.eventsub.SetHealthHook(f.onEventSubHealth)
+	if t.mode == config.TransportAuto {
+		f.pubsub.SetHealthHook(f.onPubSubHealth)
+	}
+	f.addLog("[Transport] Stream status via %s", t.mode)
+}
+
+// useEventSubLocked reports whether newly added channels should get
+// their stream status from EventSub. Caller holds f.transport.mu.
+func (f *Farmer) useEventSubLocked() bool {
+	t := &f.transport
+	switch t.mode {
+	case config.TransportEventSub:
+		return !t.eventsubDown
+	case config.TransportAuto:
+		return t.pubsubDown && !t.eventsubDown
+	}
+	return false
+}
+
+// channelTopics returns the PubSub topics to LISTEN for a newly tracked
+// channel. When EventSub carries its stream status, the video-playback
+// topic is left out and the channel is subscribed there instead.
+func (f *Farmer) channelTopics(channelID string) []string {
+	topics := []string{
+		fmt.Sprintf("raid.%s", channelID),
+		fmt.Sprintf("hype-train-events-v1.%s", channelID),
+	}
+
+	f.transport.mu.Lock()
+	useEventSub := f.useEventSubLocked()
+	if useEventSub {
+		f.transport.onEventSub[channelID] = true
+	}
+	f.transport.mu.Unlock()
+
+	if useEventSub {
+		f.eventsub.Subscribe(channelID)
+		return topics
+	}
+	return append([]string{videoPlaybackTopic(channelID)}, topics...)
+}
+
+// listenChannelTopics subscribes a newly tracked channel's topics
+// (channelTopics), owned by its channel ID, then checks the pool.
+func (f *Farmer) listenChannelTopics(channelID string) error {
+	err := f.pubsub.ListenOwned(channelID, f.channelTopics(channelID))
+	f.verifyTopics()
+	return err
+}
+
+// releaseChannelTopics undoes channelTopics for a removed channel.
+func (f *Farmer) releaseChannelTopics(channelID string) {
+	f.transport.mu.Lock()
+	onEventSub := f.transport.onEventSub[channelID]
+	delete(f.transport.onEventSub, channelID)
+	f.transport.mu.Unlock()
+
+	if onEventSub {
+		f.eventsub.Unsubscribe(channelID)
+	}
+	f.pubsub.UnlistenOwner(channelID)
+	f.verifyTopics()
+}
+
+// verifyTopics checks the PubSub subscriptions against the channel
+// registry after a channel's topics changed. The client repairs its own
+// bookkeeping (a topic on two shards, or lost by its shard) and drops
+// topics of channels no longer tracked; a tracked channel that should
+// have topics but holds none is subscribed again.
+func (f *Farmer) verifyTopics() {
+	check, err := f.pubsub.Verify(func(channelID string) bool {
+		_, ok := f.channels.Get(channelID)
+		return ok
+	})
+	if err != nil {
+		f.debugLog("[PubSub] Topic check: %v", err)
+	}
+	if !check.Empty() {
+		f.logWarn("[PubSub] Warning: topic check repaired %d duplicate, %d lost and %d orphaned topic(s)",
+			len(check.Strays), len(check.Missing), len(check.Orphaned))
+		f.debugLog("[PubSub] Topic check: strays %v, lost %v, orphaned %v", check.Strays, check.Missing, check.Orphaned)
+	}
+
+	for _, ch := range f.channels.States() {
+		if !ch.Snapshot().IsTemporary && !f.cfg.IsChannelFeatureEnabled(ch.Login, config.ChannelFeaturePubSub) {
+			continue
+		}
+		if len(f.pubsub.OwnedTopics(ch.ChannelID)) > 0 {
+			continue
+		}
+		f.logWarn("[PubSub] Warning: %s had no topics, subscribing again", ch.DisplayName)
+		if err := f.pubsub.ListenOwned(ch.ChannelID, f.channelTopics(ch.ChannelID)); err != nil {
+			f.logWarn("PubSub subscribe error for %s: %v", ch.Login, err)
+		}
+	}
+}
+
+// connectTransport starts the EventSub loop when the mode uses it from
+// the start. Auto mode connects lazily on the first failover.
+func (f *Farmer) connectTransport() {
+	if f.transport.mode == config.TransportEventSub {
+		f.eventsub.Connect()
+	}
+}
+
+// moveToPubSub hands a channel's stream status back to PubSub.
+func (f *Farmer) moveToPubSub(channelID string) {
+	f.transport.mu.Lock()
+	if !f.transport.onEventSub[channelID] {
+		f.transport.mu.Unlock()
+		return
+	}
+	delete(f.transport.onEventSub, channelID)
+	f.transport.mu.Unlock()
+
+	f.eventsub.Unsubscribe(channelID)
+	if _, ok := f.channels.Get(channelID); !ok {
+		return // removed meanwhile
+	}
+	if err := f.pubsub.ListenOwned(channelID, []string{videoPlaybackTopic(channelID)}); err != nil {
+		f.logWarn("[Transport] PubSub subscribe error for %s: %v", channelID, err)
+	}
+}
+
+// moveToEventSub hands a channel's stream status to EventSub.
+func (f *Farmer) moveToEventSub(channelID string) {
+	f.transport.mu.Lock()
+	if f.transport.onEventSub[channelID] {
+		f.transport.mu.Unlock()
+		return
+	}
+	f.transport.onEventSub[channelID] = true
+	f.transport.mu.Unlock()
+
+	f.pubsub.Unlisten([]string{videoPlaybackTopic(channelID)})
+	f.eventsub.Subscribe(channelID)
+}
+
+// onPubSubTopic is the PubSub client's topic hook: a topic whose LISTEN
+// keeps failing (err set) or no longer does (err nil). A
+// channel topic's failure is shown on the channel until it recovers;
+// the retries carry on inside the client either way.
+func (f *Farmer) onPubSubTopic(topic string, err error) {
+	id := topic[strings.LastIndex(topic, ".")+1:]
+	ch, ok := f.channels.Get(id)
+	if err == nil {
+		f.addLog("[PubSub] %s is no longer failing", topic)
+		if ok {
+			ch.SetPubSubWarning("")
+		}
+		return
+	}
+	f.logWarn("[PubSub] Warning: could not subscribe to %s (%v), still retrying", topic, err)
+	if ok {
+		ch.SetPubSubWarning(fmt.Sprintf("%s: %v", topic, err))
+	}
+}
+
+// onEventSubSubscribeError falls a channel back to PubSub when EventSub
+// refused its subscriptions (budget exhausted, token rejected by Helix).
+func (f *Farmer) onEventSubSubscribeError(channelID string, err error) {
+	name := channelID
+	if ch, ok := f.channels.Get(channelID); ok {
+		name = ch.DisplayName
+	}
+	if errors.Is(err, twitch.ErrEventSubBudget) {
+		f.addLog("[Transport] EventSub budget full — %s stays on PubSub", name)
+	} else {
+		f.logWarn("[Transport] EventSub subscribe failed for %s (%v) — using PubSub", name, err)
+	}
+	f.moveToPubSub(channelID)
+}
+
+// onEventSubHealth moves every EventSub channel back to PubSub once the
+// EventSub connection keeps failing. In eventsub mode this is final for
+// the session; in auto mode PubSub is the primary anyway.
+func (f *Farmer) onEventSubHealth(healthy bool) {
+	if healthy {
+		return
+	}
+	f.transport.mu.Lock()
+	if f.transport.eventsubDown {
+		f.transport.mu.Unlock()
+		return
+	}
+	f.transport.eventsubDown = true
+	ids := make([]string, 0, len(f.transport.onEventSub))
+	for id := range f.transport.onEventSub {
+		ids = append(ids, id)
+	}
+	f.transport.mu.Unlock()
+
+	f.addLog("[Transport] EventSub unreachable — moving %d channels to PubSub", len(ids))
+	for _, id := range ids {
+		f.moveToPubSub(id)
+	}
+}
+
+// onPubSubHealth (auto mode) fails stream status over to EventSub while
+// PubSub is down and back once it has recovered.
+func (f *Farmer) onPubSubHealth(healthy bool) {
+	f.transport.mu.Lock()
+	if f.transport.pubsubDown == !healthy {
+		f.transport.mu.Unlock()
+		return
+	}
+	f.transport.pubsubDown = !healthy
+	// Give EventSub another chance on every PubSub outage.
+	f.transport.eventsubDown = false
+	f.transport.mu.Unlock()
+
+	if healthy {
+		f.transport.mu.Lock()
+		ids := make([]string, 0, len(f.transport.onEventSub))
+		for id := range f.transport.onEventSub {
+			ids = append(ids, id)
+		}
+		f.transport.mu.Unlock()
+		f.addLog("[Transport] PubSub recovered — moving %d channels back from EventSub", len(ids))
+		for _, id := range ids {
+			f.moveToPubSub(id)
+		}
+		return
+	}
+
+	snaps := f.channels.Snapshots()
+	f.logWarn("[Transport] PubSub unreachable — failing over stream status for %d channels to EventSub", len(snaps))
+	f.eventsub.Connect()
+	for _, snap := range snaps {
+		f.moveToEventSub(snap.ChannelID)
+	}
+}
@@ -176,14 +176,14 @@ func intCmp(a, b int) int {
 	return 0
 }
 
-// updateCheckLoop runs the background update check goroutine.
-func (f *Farmer) updateCheckLoop() {
+// updateCheckLoop runs the background update check until stop is closed.
+func (f *Farmer) updateCheckLoop(stop <-chan struct{}) {
 	// Initial check after 10 seconds
 	timer := time.NewTimer(10 * time.Second)
 	select {
 	case <-timer.C:
 		f.checkForUpdates()
-	case <-f.stopCh:
+	case <-stop:
 		timer.Stop()
 		return
 	}
@@ -196,7 +196,7 @@ func (f *Farmer) updateCheckLoop() {
 		select {
 		case <-ticker.C:
 			f.checkForUpdates()
-		case <-f.stopCh:
+		case <-stop:
 			return
 		}
 	}
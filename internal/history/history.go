@@ -0,0 +1,498 @@
+// Package history keeps a persistent log of channel-points earnings in a
+// SQLite database (history.db next to config.json) and aggregates it into
+// time series for the web UI.
+package history
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // pure-Go driver, keeps cross-compiles CGO-free
+)
+
+// Event kinds.
+const (
+	KindEarned = "earned" // points credited (PubSub points-earned)
+	KindClaim  = "claim"  // bonus chest claimed by us
+	KindSpent  = "spent"  // balance drop: redemption, prediction, manual spend
+	// KindFarmed and KindDropMinutes belong to no channel: Points holds
+	// the seconds spent farming, or the drop minutes watched, since the
+	// previous event of the kind.
+	KindFarmed      = "farmed"
+	KindDropMinutes = "drop_minutes"
+)
+
+// Bucket sizes accepted by Series.
+const (
+	BucketHour = "hour"
+	BucketDay  = "day"
+	BucketWeek = "week"
+)
+
+const schema = `
+CREATE TABLE IF NOT EXISTS events (
+	ts         INTEGER NOT NULL, -- unix seconds
+	channel_id TEXT    NOT NULL,
+	login      TEXT    NOT NULL,
+	kind       TEXT    NOT NULL,
+	reason     TEXT    NOT NULL DEFAULT '',
+	points     INTEGER NOT NULL DEFAULT 0,
+	balance    INTEGER NOT NULL DEFAULT 0
+);
+CREATE INDEX IF NOT EXISTS events_ts ON events (ts);
+CREATE INDEX IF NOT EXISTS events_login_ts ON events (login, ts);
+`
+
+// Event is one recorded points-earned, spent or claim event.
+type Event struct {
+	Time      time.Time
+	ChannelID string
+	Login     string
+	Kind      string // KindEarned, KindSpent, KindClaim, KindFarmed or KindDropMinutes
+	Reason    string // Twitch reason code (WATCH, CLAIM, WATCH_STREAK, RAID, ...)
+	Points    int    // points gained or spent; 0 for claims (the CLAIM earn event carries them)
+	Balance   int    // balance after the event, 0 when unknown
+}
+
+// Query selects the events Series aggregates. An empty Login covers all
+// channels; a zero From/To leaves that end open.
+type Query struct {
+	Login  string
+	From   time.Time
+	To     time.Time
+	Bucket string // BucketHour, BucketDay or BucketWeek
+}
+
+// Point is one bucket of a series. Buckets without events are omitted.
+type Point struct {
+	Start   time.Time      `json:"start"`
+	Points  int            `json:"points"`
+	Events  int            `json:"events"` // points-earned events
+	Claims  int            `json:"claims"`
+	Reasons map[string]int `json:"reasons"` // reason code -> points
+}
+
+// Store is the history database. A nil *Store is valid and records
+// nothing, so callers don't need to care whether it could be opened.
+type Store struct {
+	db *sql.DB
+}
+
+// Open opens (creating if needed) the history database at path.
+func Open(path string) (*Store, error) {
+	db, err := sql.Open("sqlite", "file:"+path+"?_pragma=journal_mode(WAL)&_pragma=synchronous(NORMAL)&_pragma=busy_timeout(5000)")
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	// One connection: SQLite serializes writers anyway, and a single
+	// connection avoids SQLITE_BUSY between our own goroutines.
+	db.SetMaxOpenConns(1)
+	if _, err := db.Exec(schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("init %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database. Safe on a nil Store.
+func (s *Store) Close() error {
+	if s == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+// Record appends an event. Safe on a nil Store.
+func (s *Store) Record(e Event) error {
+	if s == nil {
+		return nil
+	}
+	if e.Time.IsZero() {
+		e.Time = time.Now()
+	}
+	_, err := s.db.Exec(
+		`INSERT INTO events (ts, channel_id, login, kind, reason, points, balance) VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		e.Time.Unix(), e.ChannelID, strings.ToLower(e.Login), e.Kind, e.Reason, e.Points, e.Balance)
+	return err
+}
+
+// RenameLogin moves every event of channelID to login, after the
+// streamer changed it, so series and charts stay continuous. Returns the
+// number of events moved. Safe on a nil Store.
+func (s *Store) RenameLogin(channelID, login string) (int64, error) {
+	if s == nil {
+		return 0, nil
+	}
+	res, err := s.db.Exec(`UPDATE events SET login = ? WHERE channel_id = ?`, strings.ToLower(login), channelID)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// Series aggregates the events matching q into buckets, oldest first.
+// Day and week buckets follow local midnight (weeks start on Monday).
+func (s *Store) Series(q Query) ([]Point, error) {
+	if s == nil {
+		return nil, nil
+	}
+	truncate, err := bucketFunc(q.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite groups by hour and reason; folding hours into local days
+	// and weeks happens here so DST shifts land on the right day.
+	where, args := q.where()
+	rows, err := s.db.Query(`SELECT ts / 3600, kind, reason, SUM(points), COUNT(*) FROM events WHERE `+
+		where+` GROUP BY 1, 2, 3`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byStart := make(map[int64]*Point)
+	for rows.Next() {
+		var hour int64
+		var kind, reason string
+		var points, count int
+		if err := rows.Scan(&hour, &kind, &reason, &points, &count); err != nil {
+			return nil, err
+		}
+		start := truncate(time.Unix(hour*3600, 0))
+		p := byStart[start.Unix()]
+		if p == nil {
+			p = &Point{Start: start, Reasons: make(map[string]int)}
+			byStart[start.Unix()] = p
+		}
+		switch kind {
+		case KindEarned:
+			p.Points += points
+			p.Events += count
+			if reason != "" {
+				p.Reasons[reason] += points
+			}
+		case KindClaim:
+			p.Claims += count
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]Point, 0, len(byStart))
+	for _, p := range byStart {
+		out = append(out, *p)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Start.Before(out[j].Start) })
+	return out, nil
+}
+
+// where returns the WHERE clause (and its arguments) selecting q's
+// channel and time range.
+func (q Query) where() (string, []any) {
+	where := []string{"1=1"}
+	var args []any
+	if q.Login != "" {
+		where = append(where, "login = ?")
+		args = append(args, strings.ToLower(q.Login))
+	}
+	if !q.From.IsZero() {
+		where = append(where, "ts >= ?")
+		args = append(args, q.From.Unix())
+	}
+	if !q.To.IsZero() {
+		where = append(where, "ts < ?")
+		args = append(args, q.To.Unix())
+	}
+	return strings.Join(where, " AND "), args
+}
+
+// ReasonOther stands in for points-earned events recorded without a
+// reason code.
+const ReasonOther = "OTHER"
+
+// ReasonTotal is what one reason code earned.
+type ReasonTotal struct {
+	Reason string `json:"reason"`
+	Points int    `json:"points"`
+	Events int    `json:"events"`
+}
+
+// ChannelReasons is one channel's earnings by reason code.
+type ChannelReasons struct {
+	Login   string        `json:"login"`
+	Points  int           `json:"points"`
+	Reasons []ReasonTotal `json:"reasons"` // most points first
+}
+
+// Breakdown is the points earned in a range by reason code, overall and
+// per channel.
+type Breakdown struct {
+	Points   int              `json:"points"`
+	Reasons  []ReasonTotal    `json:"reasons"`  // most points first
+	Channels []ChannelReasons `json:"channels"` // most points first
+}
+
+// Breakdown totals the points earned in q's range (and channel, if set)
+// by reason code. q.Bucket is ignored. Safe on a nil Store.
+func (s *Store) Breakdown(q Query) (Breakdown, error) {
+	b := Breakdown{Reasons: []ReasonTotal{}, Channels: []ChannelReasons{}}
+	if s == nil {
+		return b, nil
+	}
+	where, args := q.where()
+	rows, err := s.db.Query(`SELECT login, reason, SUM(points), COUNT(*) FROM events WHERE kind = ? AND `+
+		where+` GROUP BY 1, 2`, append([]any{KindEarned}, args...)...)
+	if err != nil {
+		return b, err
+	}
+	defer rows.Close()
+
+	overall := make(map[string]*ReasonTotal)
+	channels := make(map[string]*ChannelReasons)
+	for rows.Next() {
+		var login, reason string
+		var points, count int
+		if err := rows.Scan(&login, &reason, &points, &count); err != nil {
+			return b, err
+		}
+		if reason == "" {
+			reason = ReasonOther
+		}
+		t := overall[reason]
+		if t == nil {
+			t = &ReasonTotal{Reason: reason}
+			overall[reason] = t
+		}
+		t.Points += points
+		t.Events += count
+		b.Points += points
+
+		ch := channels[login]
+		if ch == nil {
+			ch = &ChannelReasons{Login: login}
+			channels[login] = ch
+		}
+		ch.Points += points
+		ch.Reasons = append(ch.Reasons, ReasonTotal{Reason: reason, Points: points, Events: count})
+	}
+	if err := rows.Err(); err != nil {
+		return b, err
+	}
+
+	for _, t := range overall {
+		b.Reasons = append(b.Reasons, *t)
+	}
+	sortReasons(b.Reasons)
+	for _, ch := range channels {
+		sortReasons(ch.Reasons)
+		b.Channels = append(b.Channels, *ch)
+	}
+	sort.Slice(b.Channels, func(i, j int) bool {
+		if b.Channels[i].Points != b.Channels[j].Points {
+			return b.Channels[i].Points > b.Channels[j].Points
+		}
+		return b.Channels[i].Login < b.Channels[j].Login
+	})
+	return b, nil
+}
+
+// sortReasons orders reasons by points, most first, then by name.
+func sortReasons(r []ReasonTotal) {
+	sort.Slice(r, func(i, j int) bool {
+		if r[i].Points != r[j].Points {
+			return r[i].Points > r[j].Points
+		}
+		return r[i].Reason < r[j].Reason
+	})
+}
+
+// bucketFunc maps a bucket name to the function that truncates a time
+// to the start of its bucket.
+func bucketFunc(bucket string) (func(time.Time) time.Time, error) {
+	switch bucket {
+	case BucketHour, "":
+		return startOfHour, nil
+	case BucketDay:
+		return startOfDay, nil
+	case BucketWeek:
+		return func(t time.Time) time.Time {
+			d := startOfDay(t)
+			return d.AddDate(0, 0, -((int(d.Weekday()) + 6) % 7))
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown bucket %q (want hour, day or week)", bucket)
+}
+
+// startOfHour truncates in the local zone; Truncate(time.Hour) works on
+// UTC and is off by the half hour in zones like Asia/Kolkata.
+func startOfHour(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, time.Local)
+}
+
+func startOfDay(t time.Time) time.Time {
+	t = t.Local()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.Local)
+}
+
+// DayTotal is what one local day recorded across all channels.
+type DayTotal struct {
+	Day         time.Time
+	Points      int // points earned
+	Claims      int // bonus chests claimed
+	DropMinutes int
+	Seconds     int // time spent farming
+}
+
+// Days totals the events from from to to by local day, oldest first.
+// Days without events are omitted. Safe on a nil Store.
+func (s *Store) Days(from, to time.Time) ([]DayTotal, error) {
+	if s == nil {
+		return nil, nil
+	}
+	// By hour in SQLite, into local days here (see Series).
+	where, args := Query{From: from, To: to}.where()
+	rows, err := s.db.Query(`SELECT ts / 3600, kind, SUM(points), COUNT(*) FROM events WHERE `+
+		where+` GROUP BY 1, 2`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	byDay := make(map[int64]*DayTotal)
+	for rows.Next() {
+		var hour int64
+		var kind string
+		var points, count int
+		if err := rows.Scan(&hour, &kind, &points, &count); err != nil {
+			return nil, err
+		}
+		day := startOfDay(time.Unix(hour*3600, 0))
+		d := byDay[day.Unix()]
+		if d == nil {
+			d = &DayTotal{Day: day}
+			byDay[day.Unix()] = d
+		}
+		switch kind {
+		case KindEarned:
+			d.Points += points
+		case KindClaim:
+			d.Claims += count
+		case KindDropMinutes:
+			d.DropMinutes += points
+		case KindFarmed:
+			d.Seconds += points
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	out := make([]DayTotal, 0, len(byDay))
+	for _, d := range byDay {
+		out = append(out, *d)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Day.Before(out[j].Day) })
+	return out, nil
+}
+
+// ChartDay is one local day of a channel's chart.
+type ChartDay struct {
+	Day     time.Time `json:"day"`
+	Earned  int       `json:"earned"`
+	Spent   int       `json:"spent"`
+	Balance int       `json:"balance"` // at the end of the day
+	// Estimated is set when Balance wasn't reported by Twitch but worked
+	// out from the earned and spent points around it.
+	Estimated bool `json:"estimated"`
+}
+
+// Chart returns one ChartDay per local day from from to to (both
+// required) for login, oldest first, days without events included.
+//
+// The balance runs forward from the last one recorded before from; if
+// there is none it is worked back from the first balance in the range.
+// A channel with no recorded balance at all is counted from 0.
+func (s *Store) Chart(login string, from, to time.Time) ([]ChartDay, error) {
+	if s == nil {
+		return nil, nil
+	}
+	login = strings.ToLower(login)
+	rows, err := s.db.Query(`SELECT ts, kind, points, balance FROM events
+		WHERE login = ? AND ts >= ? AND ts < ? AND kind IN (?, ?) ORDER BY ts`,
+		login, from.Unix(), to.Unix(), KindEarned, KindSpent)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type sample struct {
+		t       time.Time
+		delta   int // signed change of the balance
+		balance int // 0 when unknown
+	}
+	var samples []sample
+	for rows.Next() {
+		var ts int64
+		var kind string
+		var points, balance int
+		if err := rows.Scan(&ts, &kind, &points, &balance); err != nil {
+			return nil, err
+		}
+		if kind == KindSpent {
+			points = -points
+		}
+		samples = append(samples, sample{t: time.Unix(ts, 0), delta: points, balance: balance})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Opening balance: the last one before the range, or the first one in
+	// it minus the deltas leading up to it.
+	opening := 0
+	err = s.db.QueryRow(`SELECT balance FROM events WHERE login = ? AND ts < ? AND balance > 0
+		ORDER BY ts DESC LIMIT 1`, login, from.Unix()).Scan(&opening)
+	if errors.Is(err, sql.ErrNoRows) {
+		net := 0
+		for _, smp := range samples {
+			net += smp.delta
+			if smp.balance > 0 {
+				opening = smp.balance - net
+				break
+			}
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	var out []ChartDay
+	running, i := opening, 0
+	for day := startOfDay(from); day.Before(to); day = day.AddDate(0, 0, 1) {
+		next := day.AddDate(0, 0, 1)
+		d := ChartDay{Day: day, Estimated: true}
+		for ; i < len(samples) && samples[i].t.Before(next); i++ {
+			smp := samples[i]
+			if smp.delta > 0 {
+				d.Earned += smp.delta
+			} else {
+				d.Spent -= smp.delta
+			}
+			if smp.balance > 0 {
+				running = smp.balance
+			} else {
+				running += smp.delta
+			}
+			d.Estimated = smp.balance <= 0
+		}
+		d.Balance = running
+		out = append(out, d)
+	}
+	return out, nil
+}
@@ -0,0 +1,273 @@
+package history
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSeriesBuckets(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local) // a Wednesday
+	for _, e := range []Event{
+		{Time: day.Add(9*time.Hour + 5*time.Minute), Login: "Alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: day.Add(9*time.Hour + 40*time.Minute), Login: "alpha", Kind: KindEarned, Reason: "CLAIM", Points: 50},
+		{Time: day.Add(9*time.Hour + 40*time.Minute), Login: "alpha", Kind: KindClaim, Reason: "CLAIM"},
+		{Time: day.Add(10 * time.Hour), Login: "beta", Kind: KindEarned, Reason: "WATCH", Points: 12},
+		{Time: day.AddDate(0, 0, 1).Add(time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	hours, err := s.Series(Query{Login: "ALPHA", From: day, To: day.AddDate(0, 0, 1), Bucket: BucketHour})
+	if err != nil {
+		t.Fatalf("Series(hour): %v", err)
+	}
+	if len(hours) != 1 || hours[0].Points != 60 || hours[0].Events != 2 || hours[0].Claims != 1 || hours[0].Reasons["CLAIM"] != 50 {
+		t.Fatalf("hour series = %+v", hours)
+	}
+
+	days, err := s.Series(Query{Bucket: BucketDay})
+	if err != nil {
+		t.Fatalf("Series(day): %v", err)
+	}
+	if len(days) != 2 || !days[0].Start.Equal(day) || days[0].Points != 72 || days[1].Points != 10 {
+		t.Fatalf("day series = %+v", days)
+	}
+
+	weeks, err := s.Series(Query{Bucket: BucketWeek})
+	if err != nil {
+		t.Fatalf("Series(week): %v", err)
+	}
+	if monday := day.AddDate(0, 0, -2); len(weeks) != 1 || !weeks[0].Start.Equal(monday) || weeks[0].Points != 82 {
+		t.Fatalf("week series = %+v", weeks)
+	}
+
+	if _, err := s.Series(Query{Bucket: "month"}); err == nil {
+		t.Fatal("unknown bucket accepted")
+	}
+
+	var nilStore *Store
+	if err := nilStore.Record(Event{}); err != nil {
+		t.Fatalf("nil Store Record: %v", err)
+	}
+}
+
+func TestHourBucketFollowsLocalZone(t *testing.T) {
+	defer func(loc *time.Location) { time.Local = loc }(time.Local)
+	time.Local = time.FixedZone("IST", 5*3600+30*60)
+
+	start, err := bucketFunc(BucketHour)
+	if err != nil {
+		t.Fatalf("bucketFunc: %v", err)
+	}
+	at := time.Date(2026, 3, 4, 9, 45, 0, 0, time.Local)
+	if got, want := start(at), time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local); !got.Equal(want) {
+		t.Fatalf("hour bucket of %v = %v, want %v", at, got, want)
+	}
+	if got := start(at.UTC()); !got.Equal(time.Date(2026, 3, 4, 9, 0, 0, 0, time.Local)) {
+		t.Fatalf("hour bucket of the UTC instant = %v", got)
+	}
+}
+
+func TestChart(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	for _, e := range []Event{
+		{Time: day.Add(9 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: day.Add(10 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10, Balance: 1020},
+		{Time: day.Add(11 * time.Hour), Login: "alpha", Kind: KindClaim, Reason: "CLAIM"},
+		{Time: day.Add(11 * time.Hour), Login: "beta", Kind: KindEarned, Reason: "WATCH", Points: 99, Balance: 5000},
+		{Time: day.AddDate(0, 0, 2).Add(8 * time.Hour), Login: "alpha", Kind: KindSpent, Points: 500, Balance: 520},
+		{Time: day.AddDate(0, 0, 2).Add(9 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	days, err := s.Chart("Alpha", day.AddDate(0, 0, -1), day.AddDate(0, 0, 3))
+	if err != nil {
+		t.Fatalf("Chart: %v", err)
+	}
+	want := []ChartDay{
+		{Day: day.AddDate(0, 0, -1), Balance: 1000, Estimated: true},
+		{Day: day, Earned: 20, Balance: 1020},
+		{Day: day.AddDate(0, 0, 1), Balance: 1020, Estimated: true},
+		{Day: day.AddDate(0, 0, 2), Earned: 10, Spent: 500, Balance: 530, Estimated: true},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("Chart = %+v, want %d days", days, len(want))
+	}
+	for i := range want {
+		if !days[i].Day.Equal(want[i].Day) || days[i].Earned != want[i].Earned || days[i].Spent != want[i].Spent ||
+			days[i].Balance != want[i].Balance || days[i].Estimated != want[i].Estimated {
+			t.Errorf("day %d = %+v, want %+v", i, days[i], want[i])
+		}
+	}
+
+	// A later range opens with the last balance before it.
+	days, err = s.Chart("alpha", day.AddDate(0, 0, 1), day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("Chart: %v", err)
+	}
+	if len(days) != 1 || days[0].Balance != 1020 {
+		t.Fatalf("later Chart = %+v", days)
+	}
+}
+
+func TestRenameLogin(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	now := time.Now()
+	for _, e := range []Event{
+		{Time: now, ChannelID: "1", Login: "old", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: now, ChannelID: "1", Login: "old", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: now, ChannelID: "2", Login: "other", Kind: KindEarned, Reason: "WATCH", Points: 10},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+	if n, err := s.RenameLogin("1", "New"); err != nil || n != 2 {
+		t.Fatalf("RenameLogin = %d, %v; want 2 events moved", n, err)
+	}
+	q := Query{From: now.Add(-time.Hour), To: now.Add(time.Hour), Bucket: BucketDay}
+	for login, want := range map[string]int{"old": 0, "new": 20, "other": 10} {
+		q.Login = login
+		points, err := s.Series(q)
+		if err != nil {
+			t.Fatalf("Series: %v", err)
+		}
+		got := 0
+		for _, p := range points {
+			got += p.Points
+		}
+		if got != want {
+			t.Errorf("%s earned %d, want %d", login, got, want)
+		}
+	}
+}
+
+func TestBreakdown(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	for _, e := range []Event{
+		{Time: day.Add(time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: day.Add(2 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: day.Add(2 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "CLAIM", Points: 50},
+		{Time: day.Add(2 * time.Hour), Login: "alpha", Kind: KindClaim, Reason: "CLAIM"},
+		{Time: day.Add(3 * time.Hour), Login: "beta", Kind: KindEarned, Reason: "RAID", Points: 250},
+		{Time: day.Add(3 * time.Hour), Login: "beta", Kind: KindSpent, Points: 1000},
+		{Time: day.Add(4 * time.Hour), Login: "beta", Kind: KindEarned, Points: 5},
+		{Time: day.AddDate(0, 0, 1), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	b, err := s.Breakdown(Query{From: day, To: day.AddDate(0, 0, 1)})
+	if err != nil {
+		t.Fatalf("Breakdown: %v", err)
+	}
+	if b.Points != 325 {
+		t.Errorf("points = %d, want 325 (spent and claim events left out)", b.Points)
+	}
+	want := []ReasonTotal{{"RAID", 250, 1}, {"CLAIM", 50, 1}, {"WATCH", 20, 2}, {ReasonOther, 5, 1}}
+	if !reflect.DeepEqual(b.Reasons, want) {
+		t.Errorf("reasons = %+v, want %+v", b.Reasons, want)
+	}
+	if len(b.Channels) != 2 || b.Channels[0].Login != "beta" || b.Channels[0].Points != 255 ||
+		b.Channels[1].Login != "alpha" || b.Channels[1].Reasons[0].Reason != "CLAIM" {
+		t.Errorf("channels = %+v", b.Channels)
+	}
+
+	b, err = s.Breakdown(Query{Login: "Alpha"})
+	if err != nil {
+		t.Fatalf("Breakdown(alpha): %v", err)
+	}
+	if b.Points != 80 || len(b.Channels) != 1 {
+		t.Errorf("alpha = %+v", b)
+	}
+
+	var nilStore *Store
+	if b, err := nilStore.Breakdown(Query{}); err != nil || b.Reasons == nil {
+		t.Errorf("nil Store Breakdown = %+v, %v", b, err)
+	}
+}
+
+func TestDays(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "history.db"))
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer s.Close()
+
+	day := time.Date(2026, 3, 4, 0, 0, 0, 0, time.Local)
+	for _, e := range []Event{
+		{Time: day.Add(9 * time.Hour), Login: "alpha", Kind: KindEarned, Reason: "WATCH", Points: 10},
+		{Time: day.Add(9 * time.Hour), Login: "alpha", Kind: KindClaim, Reason: "CLAIM"},
+		{Time: day.Add(9 * time.Hour), Login: "alpha", Kind: KindSpent, Points: 500},
+		{Time: day.Add(10 * time.Hour), Kind: KindFarmed, Points: 300},
+		{Time: day.Add(23 * time.Hour), Kind: KindFarmed, Points: 300},
+		{Time: day.Add(23 * time.Hour), Kind: KindDropMinutes, Points: 5},
+		{Time: day.AddDate(0, 0, 1).Add(time.Hour), Login: "beta", Kind: KindEarned, Points: 20},
+		{Time: day.AddDate(0, 0, 2), Login: "beta", Kind: KindEarned, Points: 99}, // past to
+	} {
+		if err := s.Record(e); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+	}
+
+	days, err := s.Days(day, day.AddDate(0, 0, 2))
+	if err != nil {
+		t.Fatalf("Days: %v", err)
+	}
+	want := []DayTotal{
+		{Day: day, Points: 10, Claims: 1, DropMinutes: 5, Seconds: 600},
+		{Day: day.AddDate(0, 0, 1), Points: 20},
+	}
+	if len(days) != len(want) {
+		t.Fatalf("Days = %+v, want %+v", days, want)
+	}
+	for i := range want {
+		if !days[i].Day.Equal(want[i].Day) || days[i].Points != want[i].Points || days[i].Claims != want[i].Claims ||
+			days[i].DropMinutes != want[i].DropMinutes || days[i].Seconds != want[i].Seconds {
+			t.Errorf("day %d = %+v, want %+v", i, days[i], want[i])
+		}
+	}
+
+	// Farming time and drop minutes stay out of the points series.
+	series, err := s.Series(Query{From: day, To: day.AddDate(0, 0, 1), Bucket: BucketDay})
+	if err != nil || len(series) != 1 || series[0].Points != 10 || series[0].Events != 1 {
+		t.Errorf("Series = %+v, %v", series, err)
+	}
+
+	var nilStore *Store
+	if days, err := nilStore.Days(day, day.AddDate(0, 0, 1)); err != nil || days != nil {
+		t.Errorf("nil Store Days = %+v, %v", days, err)
+	}
+}
@@ -0,0 +1,13 @@
+// Package idle reports how long the computer has gone without keyboard
+// or mouse input, for farming only while the user is away.
+package idle
+
+import "time"
+
+// Time returns the time since the last user input in the desktop
+// session. It fails where the platform offers no way to tell: a
+// Windows service (session 0), Linux without a GNOME or KDE session
+// bus, other systems.
+func Time() (time.Duration, error) {
+	return idleTime()
+}
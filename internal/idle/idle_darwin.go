@@ -0,0 +1,30 @@
+//go:build darwin
+
+package idle
+
+import (
+	"errors"
+	"os/exec"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// hidIdle matches IOHIDSystem's HIDIdleTime (nanoseconds) in ioreg output.
+var hidIdle = regexp.MustCompile(`"HIDIdleTime" = (\d+)`)
+
+func idleTime() (time.Duration, error) {
+	out, err := exec.Command("ioreg", "-c", "IOHIDSystem", "-d", "4").Output()
+	if err != nil {
+		return 0, err
+	}
+	m := hidIdle.FindSubmatch(out)
+	if m == nil {
+		return 0, errors.New("no HIDIdleTime in ioreg output")
+	}
+	ns, err := strconv.ParseInt(string(m[1]), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return time.Duration(ns), nil
+}
@@ -0,0 +1,34 @@
+//go:build linux
+
+package idle
+
+import (
+	"errors"
+	"time"
+
+	"github.com/godbus/dbus/v5"
+)
+
+func idleTime() (time.Duration, error) {
+	conn, err := dbus.ConnectSessionBus()
+	if err != nil {
+		return 0, err
+	}
+	defer conn.Close()
+
+	// GNOME (X11 and Wayland).
+	var ms uint64
+	err = conn.Object("org.gnome.Mutter.IdleMonitor", "/org/gnome/Mutter/IdleMonitor/Core").
+		Call("org.gnome.Mutter.IdleMonitor.GetIdletime", 0).Store(&ms)
+	if err == nil {
+		return time.Duration(ms) * time.Millisecond, nil
+	}
+	// KDE answers in milliseconds, whatever the freedesktop draft says.
+	var kde uint32
+	err = conn.Object("org.freedesktop.ScreenSaver", "/org/freedesktop/ScreenSaver").
+		Call("org.freedesktop.ScreenSaver.GetSessionIdleTime", 0).Store(&kde)
+	if err == nil {
+		return time.Duration(kde) * time.Millisecond, nil
+	}
+	return 0, errors.New("no idle time from GNOME (Mutter) or KDE (ScreenSaver) on the session bus")
+}
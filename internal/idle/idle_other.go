@@ -0,0 +1,12 @@
+//go:build !linux && !darwin && !windows
+
+package idle
+
+import (
+	"errors"
+	"time"
+)
+
+func idleTime() (time.Duration, error) {
+	return 0, errors.New("idle detection is not supported on this system")
+}
@@ -0,0 +1,34 @@
+//go:build windows
+
+package idle
+
+import (
+	"errors"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+var (
+	user32           = syscall.NewLazyDLL("user32.dll")
+	kernel32         = syscall.NewLazyDLL("kernel32.dll")
+	getLastInputInfo = user32.NewProc("GetLastInputInfo")
+	getTickCount     = kernel32.NewProc("GetTickCount")
+)
+
+// lastInputInfo is the LASTINPUTINFO struct.
+type lastInputInfo struct {
+	cbSize uint32
+	dwTime uint32
+}
+
+func idleTime() (time.Duration, error) {
+	info := lastInputInfo{cbSize: uint32(unsafe.Sizeof(lastInputInfo{}))}
+	if r, _, err := getLastInputInfo.Call(uintptr(unsafe.Pointer(&info))); r == 0 {
+		return 0, errors.New("GetLastInputInfo: " + err.Error())
+	}
+	now, _, _ := getTickCount.Call()
+	// Both are 32-bit millisecond tick counts; the subtraction survives
+	// the wrap every 49.7 days.
+	return time.Duration(uint32(now)-info.dwTime) * time.Millisecond, nil
+}
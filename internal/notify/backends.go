@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// pushoverURL is Pushover's message endpoint.
+const pushoverURL = "https://api.pushover.net/1/messages.json"
+
+// ntfy publishes to a topic URL (https://ntfy.sh/<topic> or a
+// self-hosted server): the text is the body, the rest goes in headers.
+type ntfy struct {
+	url   string
+	token string // optional, for protected topics
+	http  *http.Client
+}
+
+func (n *ntfy) send(ctx context.Context, m Message) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.url, strings.NewReader(m.Text))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Title", m.Title)
+	req.Header.Set("Tags", m.Kind)
+	if m.URL != "" {
+		req.Header.Set("Click", m.URL)
+	}
+	if m.High {
+		req.Header.Set("Priority", "high")
+	}
+	if n.token != "" {
+		req.Header.Set("Authorization", "Bearer "+n.token)
+	}
+	return do(n.http, req)
+}
+
+// gotify posts to a Gotify server's /message endpoint with an
+// application token.
+type gotify struct {
+	url   string
+	token string
+	http  *http.Client
+}
+
+func (g *gotify) send(ctx context.Context, m Message) error {
+	priority := 5
+	if m.High {
+		priority = 8
+	}
+	body := map[string]interface{}{
+		"title":    m.Title,
+		"message":  m.Text,
+		"priority": priority,
+	}
+	if m.URL != "" {
+		body["extras"] = map[string]interface{}{
+			"client::notification": map[string]interface{}{
+				"click": map[string]string{"url": m.URL},
+			},
+		}
+	}
+	buf, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(g.url, "/")+"/message", bytes.NewReader(buf))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gotify-Key", g.token)
+	return do(g.http, req)
+}
+
+// pushover sends through the Pushover API with an application token
+// and a user (or group) key.
+type pushover struct {
+	url   string // pushoverURL; replaced in tests
+	token string
+	user  string
+	http  *http.Client
+}
+
+func (p *pushover) send(ctx context.Context, m Message) error {
+	form := url.Values{
+		"token":   {p.token},
+		"user":    {p.user},
+		"title":   {m.Title},
+		"message": {m.Text},
+	}
+	if m.URL != "" {
+		form.Set("url", m.URL)
+	}
+	if m.High {
+		form.Set("priority", "1")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return do(p.http, req)
+}
+
+// do sends req and checks the answer.
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return stripURL(err)
+	}
+	defer resp.Body.Close()
+	return checkResponse(resp)
+}
+
+// checkResponse turns a non-2xx answer into an error.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode/100 == 2 {
+		return nil
+	}
+	return fmt.Errorf("HTTP %d", resp.StatusCode)
+}
+
+// stripURL drops the request URL from a transport error: Gotify and
+// ntfy URLs may carry credentials.
+func stripURL(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return ue.Err
+	}
+	return err
+}
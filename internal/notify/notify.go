@@ -0,0 +1,169 @@
+// Package notify sends farmer notifications — drop claims, channels
+// going live, a rejected auth token — to push services: ntfy, Gotify
+// and Pushover. Each configured backend (config.Notifier) gets the
+// notifications its notify list selects.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// requestTimeout bounds one push request.
+const requestTimeout = 15 * time.Second
+
+// Farmer is the part of *farmer.Farmer the notifiers use.
+type Farmer interface {
+	Subscribe() (<-chan farmer.PushEvent, func())
+	NotificationsEnabled() bool
+	Done() <-chan struct{}
+	Logf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+var _ Farmer = (*farmer.Farmer)(nil)
+
+// Message is one notification.
+type Message struct {
+	Kind  string // config.Notify*
+	Title string
+	Text  string
+	URL   string // opened when the notification is tapped; may be empty
+	High  bool   // needs attention: sent with raised priority
+}
+
+// sender delivers messages to one push service.
+type sender interface {
+	send(ctx context.Context, m Message) error
+}
+
+// Notifier forwards farmer events to one backend.
+type Notifier struct {
+	cfg    config.Notifier
+	sender sender
+}
+
+// New checks cfg and returns its notifier.
+func New(cfg config.Notifier) (*Notifier, error) {
+	client := &http.Client{Timeout: requestTimeout}
+	var s sender
+	switch cfg.Type {
+	case config.NotifierNtfy:
+		if cfg.URL == "" {
+			return nil, errors.New("ntfy needs url (the topic URL, e.g. https://ntfy.sh/<topic>)")
+		}
+		s = &ntfy{url: cfg.URL, token: cfg.Token, http: client}
+	case config.NotifierGotify:
+		if cfg.URL == "" || cfg.Token == "" {
+			return nil, errors.New("gotify needs url (the server) and token (an application token)")
+		}
+		s = &gotify{url: cfg.URL, token: cfg.Token, http: client}
+	case config.NotifierPushover:
+		if cfg.Token == "" || cfg.User == "" {
+			return nil, errors.New("pushover needs token (the application's API token) and user (your user key)")
+		}
+		s = &pushover{url: pushoverURL, token: cfg.Token, user: cfg.User, http: client}
+	default:
+		return nil, fmt.Errorf("unknown type %q (want %s, %s or %s)", cfg.Type,
+			config.NotifierNtfy, config.NotifierGotify, config.NotifierPushover)
+	}
+	return &Notifier{cfg: cfg, sender: s}, nil
+}
+
+// Start sets up every configured backend and forwards farmer events to
+// them until the farmer stops. Backends with a config error are logged
+// and skipped.
+func Start(cfgs []config.Notifier, f Farmer) {
+	var ns []*Notifier
+	for i, cfg := range cfgs {
+		n, err := New(cfg)
+		if err != nil {
+			f.Errorf("[Notify] Error: notifiers[%d]: %v", i, err)
+			continue
+		}
+		ns = append(ns, n)
+	}
+	if len(ns) == 0 {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-f.Done()
+		cancel()
+	}()
+	go run(ctx, ns, f)
+	f.Logf("[Notify] Sending notifications to %d push backend(s)", len(ns))
+}
+
+// run forwards push events that map to a message. Sends run in their
+// own goroutine so a slow service never backs up the subscription.
+func run(ctx context.Context, ns []*Notifier, f Farmer) {
+	events, cancel := f.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if !f.NotificationsEnabled() {
+				continue
+			}
+			for _, n := range ns {
+				m, ok := n.message(ev)
+				if !ok {
+					continue
+				}
+				go func(n *Notifier) {
+					if err := n.sender.send(ctx, m); err != nil && ctx.Err() == nil {
+						f.Warnf("[Notify] %s: sending failed: %v", n.cfg.Type, err)
+					}
+				}(n)
+			}
+		}
+	}
+}
+
+// message renders a push event for this backend, or reports false when
+// the event isn't one it notifies about.
+func (n *Notifier) message(ev farmer.PushEvent) (Message, bool) {
+	switch ev.Kind {
+	case farmer.PushKindDropClaimed:
+		d, ok := ev.Data.(drops.DropClaimed)
+		if !ok || !n.cfg.Notifies(config.NotifyDrops) {
+			return Message{}, false
+		}
+		text := d.Name
+		if d.Campaign != "" {
+			text += " (" + d.Campaign + ")"
+		}
+		return Message{Kind: config.NotifyDrops, Title: "Drop claimed", Text: text}, true
+	case farmer.PushKindChannelLive:
+		s, ok := ev.Data.(farmer.StreamLive)
+		if !ok || !n.cfg.Notifies(config.NotifyLive) || !n.cfg.CoversLive(s.Login, s.Priority) {
+			return Message{}, false
+		}
+		text := s.GameName
+		if text == "" {
+			text = s.URL
+		}
+		return Message{Kind: config.NotifyLive, Title: s.DisplayName + " is live", Text: text, URL: s.URL}, true
+	case farmer.PushKindAuthFailed:
+		a, ok := ev.Data.(farmer.AuthFailed)
+		if !ok || !n.cfg.Notifies(config.NotifyAuth) {
+			return Message{}, false
+		}
+		return Message{Kind: config.NotifyAuth, Title: "TwitchPoint login needed", Text: a.Message, High: true}, true
+	}
+	return Message{}, false
+}
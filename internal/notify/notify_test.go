@@ -0,0 +1,118 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+func TestNewChecksConfig(t *testing.T) {
+	bad := []config.Notifier{
+		{Type: "email"},
+		{Type: config.NotifierNtfy},
+		{Type: config.NotifierGotify, URL: "https://gotify.example"},
+		{Type: config.NotifierPushover, Token: "app"},
+	}
+	for _, cfg := range bad {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("New(%+v) accepted an incomplete config", cfg)
+		}
+	}
+	if _, err := New(config.Notifier{Type: config.NotifierNtfy, URL: "https://ntfy.sh/x"}); err != nil {
+		t.Errorf("ntfy with a topic URL: %v", err)
+	}
+}
+
+// TestMessage checks which push events become notifications for a
+// backend: its notify list and live_channels decide.
+func TestMessage(t *testing.T) {
+	n, err := New(config.Notifier{Type: config.NotifierNtfy, URL: "https://ntfy.sh/x", LiveChannels: []string{"alpha"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claim := farmer.PushEvent{Kind: farmer.PushKindDropClaimed, Data: drops.DropClaimed{Name: "Skin", Campaign: "Rust Week"}}
+	if m, ok := n.message(claim); !ok || m.Text != "Skin (Rust Week)" {
+		t.Errorf("drop claim = %+v, %v", m, ok)
+	}
+	live := farmer.PushEvent{Kind: farmer.PushKindChannelLive, Data: farmer.StreamLive{Login: "alpha", DisplayName: "Alpha", GameName: "Rust", URL: "https://www.twitch.tv/alpha"}}
+	if m, ok := n.message(live); !ok || m.Title != "Alpha is live" || m.URL == "" {
+		t.Errorf("live = %+v, %v", m, ok)
+	}
+	other := farmer.PushEvent{Kind: farmer.PushKindChannelLive, Data: farmer.StreamLive{Login: "beta", Priority: 1}}
+	if _, ok := n.message(other); ok {
+		t.Error("beta is not in live_channels")
+	}
+	auth := farmer.PushEvent{Kind: farmer.PushKindAuthFailed, Data: farmer.AuthFailed{Message: "log in again"}}
+	if m, ok := n.message(auth); !ok || !m.High {
+		t.Errorf("auth = %+v, %v; want a high-priority message", m, ok)
+	}
+	if _, ok := n.message(farmer.PushEvent{Kind: farmer.PushKindLog}); ok {
+		t.Error("log lines are not notifications")
+	}
+
+	n.cfg.Notify = []string{config.NotifyAuth}
+	if _, ok := n.message(claim); ok {
+		t.Error("drops notified with notify [auth]")
+	}
+}
+
+// TestSenders checks the request each backend makes.
+func TestSenders(t *testing.T) {
+	var got *http.Request
+	var body []byte
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+	m := Message{Kind: config.NotifyAuth, Title: "Login needed", Text: "log in again", URL: "https://x", High: true}
+	ctx := context.Background()
+
+	if err := (&ntfy{url: srv.URL + "/topic", token: "tk", http: srv.Client()}).send(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/topic" || string(body) != "log in again" || got.Header.Get("Title") != "Login needed" ||
+		got.Header.Get("Priority") != "high" || got.Header.Get("Click") != "https://x" || got.Header.Get("Authorization") != "Bearer tk" {
+		t.Errorf("ntfy request: %s %v %q", got.URL.Path, got.Header, body)
+	}
+
+	if err := (&gotify{url: srv.URL + "/", token: "app", http: srv.Client()}).send(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Title    string `json:"title"`
+		Message  string `json:"message"`
+		Priority int    `json:"priority"`
+	}
+	if err := json.Unmarshal(body, &g); err != nil {
+		t.Fatal(err)
+	}
+	if got.URL.Path != "/message" || got.Header.Get("X-Gotify-Key") != "app" || g.Message != "log in again" || g.Priority != 8 {
+		t.Errorf("gotify request: %s %v %+v", got.URL.Path, got.Header, g)
+	}
+
+	if err := (&pushover{url: srv.URL, token: "app", user: "me", http: srv.Client()}).send(ctx, m); err != nil {
+		t.Fatal(err)
+	}
+	form, _ := url.ParseQuery(string(body))
+	if form.Get("token") != "app" || form.Get("user") != "me" || form.Get("message") != "log in again" || form.Get("priority") != "1" {
+		t.Errorf("pushover form: %v", form)
+	}
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+	if err := (&ntfy{url: failing.URL, http: failing.Client()}).send(ctx, m); err == nil {
+		t.Error("HTTP 401 reported as sent")
+	}
+}
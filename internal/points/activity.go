@@ -0,0 +1,54 @@
+package points
+
+import "time"
+
+// Activity kinds (Activity.Kind).
+const (
+	ActivityBonus      = "bonus_claim"
+	ActivityMoment     = "moment_claim"
+	ActivityRaid       = "raid_join"  // recorded by the farmer
+	ActivityDropClaim  = "drop_claim" // recorded by the farmer for drops.OnDropClaimed
+	ActivityRedemption = "redemption"
+)
+
+// maxActivityLog bounds the in-memory activity feed.
+const maxActivityLog = 200
+
+// Activity is one entry of the activity feed: a bonus or Moment claim, a
+// raid join, a drop claim or an auto-redemption. Unlike the log it is
+// structured, for clients that render a timeline.
+type Activity struct {
+	At        time.Time
+	Kind      string // Activity* constant
+	ChannelID string // "" when not known (drop claims)
+	Channel   string // display name
+	Detail    string // raid target, drop and campaign, reward title
+	Points    int    // redemption cost
+	Error     string // "" on success
+}
+
+// RecordActivity appends a to the feed and passes it to the OnActivity
+// hook. A zero At is set to now.
+func (s *Service) RecordActivity(a Activity) {
+	if a.At.IsZero() {
+		a.At = time.Now()
+	}
+	s.mu.Lock()
+	s.activity = append(s.activity, a)
+	if len(s.activity) > maxActivityLog {
+		s.activity = s.activity[len(s.activity)-maxActivityLog:]
+	}
+	s.mu.Unlock()
+	if s.onActivity != nil {
+		s.onActivity(a)
+	}
+}
+
+// Activity returns the activity feed, oldest first.
+func (s *Service) Activity() []Activity {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Activity, len(s.activity))
+	copy(out, s.activity)
+	return out
+}
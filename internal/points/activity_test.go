@@ -0,0 +1,21 @@
+package points
+
+import "testing"
+
+func TestRecordActivity_BoundedAndHooked(t *testing.T) {
+	var hooked int
+	s := &Service{onActivity: func(Activity) { hooked++ }}
+	for i := 0; i < maxActivityLog+5; i++ {
+		s.RecordActivity(Activity{Kind: ActivityBonus, Points: i})
+	}
+	feed := s.Activity()
+	if len(feed) != maxActivityLog {
+		t.Fatalf("len = %d, want %d", len(feed), maxActivityLog)
+	}
+	if feed[0].Points != 5 || feed[0].At.IsZero() {
+		t.Fatalf("oldest entry = %+v, want Points 5 with a time", feed[0])
+	}
+	if hooked != maxActivityLog+5 {
+		t.Fatalf("hook called %d times", hooked)
+	}
+}
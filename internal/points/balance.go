@@ -1,6 +1,13 @@
 package points
 
-import "time"
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
 
 // balanceRefreshInterval is how often we re-fetch each channel's
 // points balance + (for online channels) stream metadata. 5 min keeps
@@ -26,29 +33,139 @@ func (s *Service) BalanceRefreshLoop(stopCh <-chan struct{}) {
 	}
 }
 
-// RefreshBalances iterates every tracked channel: fetches the channel-
-// points balance, and for online channels also re-fetches stream
-// metadata so the rotation has fresh broadcast IDs/game IDs to work
-// with on the next tick.
-//
-// 500 ms inter-channel sleep keeps us under any informal rate-limit
-// the GQL endpoint enforces — we'd otherwise burst N requests in
-// roughly the same millisecond and risk a 429.
+// RefreshBalances refreshes every tracked channel: the channel-points
+// balance, and for online channels also the stream metadata so the
+// rotation has fresh broadcast IDs/game IDs to work with on the next
+// tick. The lookups go out as batched GQL requests (see
+// GetChannelRefreshes), a handful of requests for the whole list
+// rather than one or two per channel.
 func (s *Service) RefreshBalances() {
-	for _, ch := range s.channels.States() {
-		balance, err := s.gql.GetChannelPointsBalance(ch.Login)
-		if err == nil && balance > 0 {
-			ch.SetBalance(balance)
-		}
-
+	states := s.channels.States()
+	logins := make([]string, 0, len(states))
+	online := make(map[string]bool)
+	for _, ch := range states {
 		snap := ch.Snapshot()
+		login := strings.ToLower(snap.Login)
+		logins = append(logins, login)
 		if snap.IsOnline {
-			info, err := s.gql.GetChannelInfo(ch.Login)
-			if err == nil && info.IsLive {
-				ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
-			}
+			online[login] = true
+		}
+	}
+	results, err := s.gql.GetChannelRefreshes(logins, online)
+	if err != nil {
+		s.debugLog("Balance refresh: %v", err)
+	}
+	for _, ch := range states {
+		if r, ok := results[strings.ToLower(ch.Login)]; ok {
+			s.applyRefresh(ch, r)
+		}
+	}
+}
+
+// RefreshChannel is RefreshBalances for a single channel, for on-demand
+// refreshes from the UI.
+func (s *Service) RefreshChannel(ch *channels.State) {
+	r := &twitch.ChannelRefresh{}
+	r.Points, r.PointsErr = s.gql.GetChannelPointsContext(ch.Login)
+	if !errors.Is(r.PointsErr, twitch.ErrChannelNotFound) && ch.Snapshot().IsOnline {
+		if info, err := s.gql.GetChannelInfo(ch.Login); err == nil {
+			r.Info = info
+		}
+	}
+	s.applyRefresh(ch, r)
+}
+
+// applyRefresh applies one channel's refresh results. A login Twitch no
+// longer knows goes to the LoginGone hook, which checks for a rename.
+func (s *Service) applyRefresh(ch *channels.State, r *twitch.ChannelRefresh) {
+	if errors.Is(r.PointsErr, twitch.ErrChannelNotFound) {
+		if s.loginGone != nil {
+			s.loginGone(ch)
+		}
+		return
+	}
+	if r.PointsErr == nil {
+		ch.SetMultiplier(r.Points.Multiplier, r.Points.SubTier)
+	}
+	if r.PointsErr == nil && r.Points.Balance > 0 {
+		s.RecordSpent(ch, ch.SetBalance(r.Points.Balance))
+		s.CheckGoal(ch)
+		s.CheckRedeem(ch)
+	}
+
+	if info := r.Info; info != nil && info.IsLive && ch.Snapshot().IsOnline {
+		ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+		ch.SetRerun(info.IsRerun())
+	}
+}
+
+// ClaimSweepInterval is how often SweepClaims re-checks every channel
+// for a pending bonus chest. PubSub delivers claim-available in real
+// time, so the sweep only has to catch what PubSub missed: chests that
+// spawned while we were offline or during a reconnect gap.
+const ClaimSweepInterval = 15 * time.Minute
+
+// ClaimSweepLoop runs SweepClaims every ClaimSweepInterval. Started by
+// Farmer.Start as a goroutine. The startup pass is covered per channel
+// by SweepChannel as each one is registered.
+func (s *Service) ClaimSweepLoop(stopCh <-chan struct{}) {
+	ticker := time.NewTicker(ClaimSweepInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.SweepClaims()
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// SweepClaims queries ChannelPointsContext for every tracked channel and
+// claims any pending bonus chest. Claims go through the same SeenClaim
+// dedup as the PubSub path, so a chest PubSub already handled isn't
+// claimed twice. Same 500 ms inter-channel spacing as RefreshBalances.
+func (s *Service) SweepClaims() {
+	found := 0
+	for _, ch := range s.channels.States() {
+		claimed, err := s.SweepChannel(ch)
+		if err != nil {
+			s.debugLog("Claim sweep: %s: %v", ch.Login, err)
+		}
+		if claimed {
+			found++
 		}
 
 		time.Sleep(500 * time.Millisecond)
 	}
+	if found > 0 {
+		s.log("Claim sweep: found %d missed bonus chest(s)", found)
+	}
+}
+
+// SweepChannel is one SweepClaims step: it refreshes the channel's
+// balance from ChannelPointsContext and claims a pending bonus chest if
+// there is one. Called on its own when a channel is registered, so
+// chests that spawned while the bot was offline are claimed right at
+// startup instead of on the first sweep. Reports whether a claim was
+// started.
+func (s *Service) SweepChannel(ch *channels.State) (bool, error) {
+	ctx, err := s.gql.GetChannelPointsContext(ch.Login)
+	if err != nil {
+		return false, err
+	}
+	ch.SetMultiplier(ctx.Multiplier, ctx.SubTier)
+	if ctx.Balance > 0 {
+		s.RecordSpent(ch, ch.SetBalance(ctx.Balance))
+		s.CheckGoal(ch)
+		s.CheckRedeem(ch)
+	}
+	// While paused the chest is left for the first sweep after Resume.
+	if ctx.AvailableClaimID == "" || s.farmingPaused() || s.SeenClaim(ctx.AvailableClaimID) {
+		return false, nil
+	}
+	snap := ch.Snapshot()
+	s.AttemptClaim(snap.ChannelID, ctx.AvailableClaimID, snap.DisplayName, ch)
+	return true, nil
 }
@@ -1,10 +1,12 @@
 package points
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/history"
 	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
@@ -53,6 +55,24 @@ func (s *Service) SeenRaid(raidID string) bool {
 	return false
 }
 
+// SeenMoment returns true if the Moment was already attempted. Same
+// dedup contract as SeenClaim — a reconnect can replay the "active"
+// message for a Moment we've already claimed.
+func (s *Service) SeenMoment(momentID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, seen := s.seenMoments[momentID]; seen {
+		return true
+	}
+	s.seenMoments[momentID] = time.Now()
+	for id, t := range s.seenMoments {
+		if time.Since(t) > dedupTTL {
+			delete(s.seenMoments, id)
+		}
+	}
+	return false
+}
+
 // RecordPoints adds to the running totalPointsEarned counter. Called by
 // the EventPointsEarned handler for both tracked and untracked channels
 // (untracked channels still credit globally; per-channel session totals
@@ -61,6 +81,31 @@ func (s *Service) RecordPoints(gained int) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.totalPointsEarned += gained
+	s.earnRate.Add(gained, time.Now())
+}
+
+// RecordSpent books a detected balance drop against the running total,
+// logs it and records it in the earnings history. No-op for spent <= 0
+// so callers can pass the SetBalance return value straight through.
+func (s *Service) RecordSpent(ch *channels.State, spent int) {
+	if spent <= 0 {
+		return
+	}
+	s.mu.Lock()
+	s.totalPointsSpent += spent
+	s.mu.Unlock()
+	snap := ch.Snapshot()
+	s.log("-%d points spent on %s - Balance: %d", spent, snap.DisplayName, snap.PointsBalance)
+	err := s.history.Record(history.Event{
+		ChannelID: snap.ChannelID,
+		Login:     snap.Login,
+		Kind:      history.KindSpent,
+		Points:    spent,
+		Balance:   snap.PointsBalance,
+	})
+	if err != nil {
+		s.debugLog("[History] Failed to record spend on %s: %v", snap.DisplayName, err)
+	}
 }
 
 // AttemptClaim runs the channel-points bonus claim flow asynchronously
@@ -77,7 +122,7 @@ func (s *Service) RecordPoints(gained int) {
 // Spawns a goroutine internally — handleEvent must NOT block on
 // network calls or it'll back up the PubSub event channel.
 func (s *Service) AttemptClaim(channelID, claimID, channelName string, ch *channels.State) {
-	go func() {
+	s.goClaim(func() {
 		var lastErr error
 		for attempt := 0; attempt < 3; attempt++ {
 			if attempt > 0 {
@@ -85,13 +130,19 @@ func (s *Service) AttemptClaim(channelID, claimID, channelName string, ch *chann
 			}
 			lastErr = s.gql.ClaimCommunityPoints(channelID, claimID)
 			if lastErr == nil {
+				claim := history.Event{ChannelID: channelID, Kind: history.KindClaim, Reason: "CLAIM"}
 				if ch != nil {
 					ch.RecordClaim()
+					claim.Login = ch.Login
 				}
 				s.mu.Lock()
 				s.totalClaimsMade++
 				s.mu.Unlock()
 				s.log("Claimed bonus on %s!", channelName)
+				s.RecordActivity(Activity{Kind: ActivityBonus, ChannelID: channelID, Channel: channelName})
+				if err := s.history.Record(claim); err != nil {
+					s.debugLog("[History] Failed to record claim on %s: %v", channelName, err)
+				}
 				return
 			}
 			if errors.Is(lastErr, twitch.ErrClaimNotFound) {
@@ -99,7 +150,81 @@ func (s *Service) AttemptClaim(channelID, claimID, channelName string, ch *chann
 				s.log("Claim on %s skipped — already consumed (NOT_FOUND)", channelName)
 				return
 			}
+			if errors.Is(lastErr, twitch.ErrShutdown) {
+				s.log("Claim on %s dropped — shutting down", channelName)
+				return
+			}
 		}
-		s.log("Claim failed on %s after 3 attempts: %v", channelName, lastErr)
+		s.warn("Claim failed on %s after 3 attempts: %v", channelName, lastErr)
+		s.RecordActivity(Activity{Kind: ActivityBonus, ChannelID: channelID, Channel: channelName, Error: lastErr.Error()})
+	})
+}
+
+// AttemptMomentClaim claims a Moment asynchronously with the same
+// 3-attempt / 2s-spaced retry as AttemptClaim. Channels opted out via
+// config (disable_moments) are skipped before any network call.
+func (s *Service) AttemptMomentClaim(momentID, channelLogin, channelName string) {
+	if channelLogin != "" && !s.cfg.IsMomentsEnabled(channelLogin) {
+		s.debugLog("Moment on %s skipped — disabled for this channel", channelName)
+		return
+	}
+	s.goClaim(func() {
+		var lastErr error
+		for attempt := 0; attempt < 3; attempt++ {
+			if attempt > 0 {
+				time.Sleep(2 * time.Second)
+			}
+			lastErr = s.gql.ClaimMoment(momentID)
+			if lastErr == nil {
+				s.mu.Lock()
+				s.totalMoments++
+				s.mu.Unlock()
+				s.log("Claimed Moment on %s!", channelName)
+				s.RecordActivity(Activity{Kind: ActivityMoment, Channel: channelName})
+				return
+			}
+			if errors.Is(lastErr, twitch.ErrShutdown) {
+				s.log("Moment claim on %s dropped — shutting down", channelName)
+				return
+			}
+		}
+		s.warn("Moment claim failed on %s after 3 attempts: %v", channelName, lastErr)
+		s.RecordActivity(Activity{Kind: ActivityMoment, Channel: channelName, Error: lastErr.Error()})
+	})
+}
+
+// goClaim runs a claim in its own goroutine, tracked for WaitClaims.
+// Claims arriving after WaitClaims has begun are dropped.
+func (s *Service) goClaim(claim func()) {
+	s.claimsMu.Lock()
+	defer s.claimsMu.Unlock()
+	if s.claimsClosed {
+		return
+	}
+	s.claims.Add(1)
+	go func() {
+		defer s.claims.Done()
+		claim()
 	}()
 }
+
+// WaitClaims stops accepting claims and waits for those in flight, or
+// until ctx is done (returning its error). For shutdown: a claim that
+// has reached Twitch gets its answer, record and log line.
+func (s *Service) WaitClaims(ctx context.Context) error {
+	s.claimsMu.Lock()
+	s.claimsClosed = true
+	s.claimsMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.claims.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
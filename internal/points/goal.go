@@ -0,0 +1,69 @@
+package points
+
+import (
+	"github.com/miwi/twitchpoint/internal/channels"
+)
+
+// goalReached reports whether a channel's balance is at or over its
+// configured goal.
+func (s *Service) goalReached(snap channels.Snapshot) bool {
+	goal := s.cfg.GetPointsGoal(snap.Login)
+	return goal > 0 && snap.PointsBalance >= goal
+}
+
+// capReached reports whether a channel's balance is at or over its
+// max_points cap. Such a channel gets no points slot unless it serves
+// an active drop campaign.
+func (s *Service) capReached(snap channels.Snapshot) bool {
+	limit := s.cfg.GetMaxPoints(snap.Login)
+	return limit > 0 && snap.PointsBalance >= limit && !snap.HasActiveDrop
+}
+
+// CheckGoal announces a channel reaching (or, after spending, falling
+// back below) its points goal or max_points cap and re-rotates so the
+// watch slot goes to a channel that still needs points. Called after
+// every balance update; cheap when nothing changed.
+func (s *Service) CheckGoal(ch *channels.State) {
+	snap := ch.Snapshot()
+	reached, capped := s.goalReached(snap), s.capReached(snap)
+
+	s.mu.Lock()
+	goalChanged := setReached(s.goalsReached, snap.ChannelID, reached)
+	capChanged := setReached(s.capsReached, snap.ChannelID, capped)
+	s.mu.Unlock()
+	if !goalChanged && !capChanged {
+		return
+	}
+
+	if goal := s.cfg.GetPointsGoal(snap.Login); goalChanged {
+		if reached {
+			s.log("[Goal] %s reached its %d point goal (balance %d), moving it to the back of the rotation",
+				snap.DisplayName, goal, snap.PointsBalance)
+		} else if goal > 0 {
+			s.log("[Goal] %s is below its %d point goal again (balance %d), back in normal rotation",
+				snap.DisplayName, goal, snap.PointsBalance)
+		}
+	}
+	if limit := s.cfg.GetMaxPoints(snap.Login); capChanged {
+		if capped {
+			s.log("[Goal] %s reached its max_points cap of %d (balance %d), taking it out of the rotation",
+				snap.DisplayName, limit, snap.PointsBalance)
+		} else if limit > 0 && snap.PointsBalance < limit {
+			s.log("[Goal] %s is below its max_points cap of %d again (balance %d), back in the rotation",
+				snap.DisplayName, limit, snap.PointsBalance)
+		}
+	}
+	s.RotateNow()
+}
+
+// setReached records whether channelID is over a threshold in m and
+// reports whether that changed.
+func setReached(m map[string]bool, channelID string, reached bool) bool {
+	changed := m[channelID] != reached
+	if reached {
+		m[channelID] = true
+	} else {
+		delete(m, channelID)
+	}
+	return changed
+}
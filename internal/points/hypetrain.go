@@ -0,0 +1,72 @@
+package points
+
+import (
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// HandleHypeTrain records a hype-train update on the channel and
+// re-rotates when a train starts or ends, so the channel takes (or gives
+// back) a watch slot right away. Rotate ranks a running train just
+// below a "watch now" pick: the points multiplier only lasts minutes.
+//
+// hype-train-end can go missing over a reconnect, so a rotation is also
+// scheduled for the expiry Twitch announced. There is one such timer per
+// channel: a level-up pushes the expiry out and reschedules it, the end
+// of the train cancels it.
+func (s *Service) HandleHypeTrain(ch *channels.State, data twitch.HypeTrainData) {
+	now := time.Now()
+	before := ch.Snapshot()
+	running := before.InHypeTrain(now)
+
+	if !data.Active {
+		s.setHypeTimer(ch.ChannelID, time.Time{})
+		ch.ClearHypeTrain()
+		if running {
+			s.log("Hype Train on %s ended, back to normal priority", before.DisplayName)
+			go s.Rotate()
+		}
+		return
+	}
+
+	ch.SetHypeTrain(data.Level, data.ExpiresAt)
+	s.setHypeTimer(ch.ChannelID, data.ExpiresAt)
+	switch {
+	case !running:
+		s.log("Hype Train on %s (level %d), boosting to top watch priority", before.DisplayName, data.Level)
+		go s.Rotate()
+	case data.Level > before.HypeTrainLevel:
+		s.log("Hype Train on %s reached level %d", before.DisplayName, data.Level)
+	}
+}
+
+// setHypeTimer replaces the channel's expiry rotation with one at
+// expires, or just cancels it when expires is zero.
+func (s *Service) setHypeTimer(channelID string, expires time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t := s.hypeTimers[channelID]; t != nil {
+		t.Stop()
+		delete(s.hypeTimers, channelID)
+	}
+	if expires.IsZero() {
+		return
+	}
+	if s.hypeTimers == nil {
+		s.hypeTimers = make(map[string]*time.Timer)
+	}
+	s.hypeTimers[channelID] = time.AfterFunc(time.Until(expires)+time.Second, s.RotateNow)
+}
+
+// StopTimers cancels the pending Hype Train expiry rotations. Called on
+// shutdown.
+func (s *Service) StopTimers() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, t := range s.hypeTimers {
+		t.Stop()
+		delete(s.hypeTimers, id)
+	}
+}
@@ -0,0 +1,49 @@
+package points
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// TestHandleHypeTrain_OneTimerPerChannel: level-ups replace the expiry
+// rotation instead of stacking timers; the end of the train and
+// StopTimers cancel it.
+func TestHandleHypeTrain_OneTimerPerChannel(t *testing.T) {
+	s := &Service{
+		log:       func(string, ...interface{}) {},
+		warn:      func(string, ...interface{}) {},
+		isPaused:  func() bool { return true }, // keeps Rotate a no-op
+		rotateNow: make(chan struct{}, 1),
+	}
+	a := channels.NewState("a", "A", "1")
+	b := channels.NewState("b", "B", "2")
+	expires := time.Now().Add(time.Hour)
+
+	for level := 1; level <= 3; level++ {
+		s.HandleHypeTrain(a, twitch.HypeTrainData{Active: true, Level: level, ExpiresAt: expires})
+	}
+	s.HandleHypeTrain(b, twitch.HypeTrainData{Active: true, Level: 1, ExpiresAt: expires})
+	if n := len(s.hypeTimers); n != 2 {
+		t.Fatalf("%d timers after updates on two channels, want 2", n)
+	}
+	first := s.hypeTimers["1"]
+
+	s.HandleHypeTrain(a, twitch.HypeTrainData{Active: true, Level: 4, ExpiresAt: expires.Add(time.Minute)})
+	if first.Stop() {
+		t.Error("level-up left the previous timer running")
+	}
+
+	s.HandleHypeTrain(a, twitch.HypeTrainData{})
+	if _, ok := s.hypeTimers["1"]; ok || len(s.hypeTimers) != 1 {
+		t.Fatalf("timers after the train ended: %v", s.hypeTimers)
+	}
+
+	pending := s.hypeTimers["2"]
+	s.StopTimers()
+	if len(s.hypeTimers) != 0 || pending.Stop() {
+		t.Fatal("StopTimers left a timer running")
+	}
+}
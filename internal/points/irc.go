@@ -1,25 +1,80 @@
 package points
 
+import (
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
 // NotifyChannelAdded is called when a channel (permanent or temp) joins
 // the registry. It hands the login to the IRC client for viewer
 // presence — Twitch tracks viewer-presence-by-IRC-join independently
 // of the channel-points-WATCH heartbeats, so the join is what makes
 // the user count toward the streamer's viewer count.
 //
-// No-op when IRC is disabled in config (s.irc == nil).
+// No-op when IRC is disabled (no client set), for temp drop channels
+// when irc_skip_temp_channels is set, and for channels with
+// disable_irc. With irc_mode "watching" the join waits for the channel
+// to get a Spade slot (syncWatchingIRC).
 func (s *Service) NotifyChannelAdded(login string) {
-	if s.irc == nil {
+	irc := s.irc.Load()
+	if irc == nil || s.cfg.GetIrcMode() == config.IrcModeWatching {
+		return
+	}
+	if ch, ok := s.channels.GetByLogin(login); ok && s.skipIRC(ch) {
 		return
 	}
-	s.irc.Join(login)
+	irc.Join(login)
+}
+
+// skipIRC reports whether a channel is kept out of IRC presence: a
+// temp drop channel under irc_skip_temp_channels, or a configured one
+// with disable_irc.
+func (s *Service) skipIRC(ch *channels.State) bool {
+	snap := ch.Snapshot()
+	if snap.IsTemporary && s.cfg.GetIrcSkipTempChannels() {
+		return true
+	}
+	return !s.cfg.IsChannelFeatureEnabled(snap.Login, config.ChannelFeatureIRC)
 }
 
 // NotifyChannelRemoved is the inverse — drops IRC presence when a
 // channel is removed (RemoveChannelLive) or torn down as a temp
 // (removeTemporaryChannel).
 func (s *Service) NotifyChannelRemoved(login string) {
-	if s.irc == nil {
+	irc := s.irc.Load()
+	if irc == nil {
+		return
+	}
+	irc.Part(login)
+}
+
+// SyncIRC pushes the registry's current channel set (permanent + temp,
+// minus skipped temps; only the watched ones with irc_mode "watching")
+// to the IRC client. Wired as the IRC ready hook so every reconnect
+// rejoins exactly the live channel list — channels removed while IRC
+// was down are not resurrected, channels added in that window are
+// joined.
+func (s *Service) SyncIRC() {
+	irc := s.irc.Load()
+	if irc == nil {
 		return
 	}
-	s.irc.Part(login)
+	watchingOnly := s.cfg.GetIrcMode() == config.IrcModeWatching
+	states := s.channels.States()
+	logins := make([]string, 0, len(states))
+	for _, ch := range states {
+		if s.skipIRC(ch) || (watchingOnly && !ch.Snapshot().IsWatching) {
+			continue
+		}
+		logins = append(logins, ch.Login)
+	}
+	irc.SyncChannels(logins)
+}
+
+// syncWatchingIRC mirrors the Spade slots into IRC after they changed,
+// when irc_mode is "watching".
+func (s *Service) syncWatchingIRC() {
+	if s.cfg.GetIrcMode() == config.IrcModeWatching {
+		s.SyncIRC()
+	}
 }
@@ -0,0 +1,43 @@
+package points
+
+import (
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+func TestSkipIRC_OnlyTempChannelsWhenEnabled(t *testing.T) {
+	cfg := &config.Config{}
+	s := &Service{cfg: cfg}
+
+	perm := channels.NewState("mine", "Mine", "1")
+	temp := channels.NewState("random", "Random", "2")
+	temp.SetIsTemporary(true)
+
+	if s.skipIRC(perm) || s.skipIRC(temp) {
+		t.Fatal("nothing should be skipped by default")
+	}
+	cfg.SetIrcSkipTempChannels(true)
+	if s.skipIRC(perm) {
+		t.Error("configured channel must keep IRC presence")
+	}
+	if !s.skipIRC(temp) {
+		t.Error("temp drop channel should be skipped")
+	}
+}
+
+func TestSkipIRC_DisabledPerChannel(t *testing.T) {
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{
+		{Login: "quiet", Priority: 2, DisableIRC: true},
+		{Login: "chatty", Priority: 2},
+	}}
+	s := &Service{cfg: cfg}
+
+	if !s.skipIRC(channels.NewState("quiet", "Quiet", "1")) {
+		t.Error("channel with disable_irc should be skipped")
+	}
+	if s.skipIRC(channels.NewState("chatty", "Chatty", "2")) {
+		t.Error("other channels keep IRC presence")
+	}
+}
@@ -0,0 +1,50 @@
+package points
+
+import (
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+)
+
+// benchedDropsOnly reports whether a channel is set to drops-only but
+// serves no active campaign right now. Such a channel gets no points
+// slot until the drops selector picks it.
+func (s *Service) benchedDropsOnly(snap channels.Snapshot) bool {
+	return !snap.HasActiveDrop && s.cfg.GetChannelMode(snap.Login) == config.ChannelModeDrops
+}
+
+// spadeDisabled reports whether the channel has Spade heartbeats
+// switched off (disable_spade), which keeps it out of the points
+// rotation entirely.
+func (s *Service) spadeDisabled(snap channels.Snapshot) bool {
+	return !s.cfg.IsChannelFeatureEnabled(snap.Login, config.ChannelFeatureSpade)
+}
+
+// farmingPaused reports whether the whole farmer is paused
+// (Farmer.Pause): nothing gets a Spade slot and no bonus is claimed.
+func (s *Service) farmingPaused() bool {
+	return s.isPaused != nil && s.isPaused()
+}
+
+// InactiveStream tells why a live channel looks like a ghost stream
+// (min_viewers, stale_viewers_minutes) or is a rerun skipped by
+// rerun_mode, or returns "" if neither. Such a channel gets no points
+// slot; /api/channels reports the reason as "inactive".
+func InactiveStream(cfg *config.Config, snap channels.Snapshot, now time.Time) string {
+	if snap.IsOnline && snap.IsRerun && cfg.GetRerunMode() == config.RerunModeSkip {
+		return "rerun"
+	}
+	return snap.InactiveReason(now, cfg.GetMinViewers(), cfg.GetStaleViewersAfter())
+}
+
+func (s *Service) inactiveStream(snap channels.Snapshot, now time.Time) string {
+	return InactiveStream(s.cfg, snap, now)
+}
+
+// deprioritizedRerun reports whether the channel streams a rerun and
+// rerun_mode is "deprioritize": like a reached points goal, it only
+// gets a slot no other channel wants.
+func (s *Service) deprioritizedRerun(snap channels.Snapshot) bool {
+	return snap.IsRerun && s.cfg.GetRerunMode() == config.RerunModeDeprioritize
+}
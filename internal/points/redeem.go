@@ -0,0 +1,150 @@
+package points
+
+import (
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+const (
+	// redeemSuccessPause spaces consecutive redemptions on one channel so
+	// the balance has caught up (via PubSub) before the next check.
+	redeemSuccessPause = 1 * time.Minute
+	// redeemRetryBackoff is how long a channel is left alone after a
+	// check found nothing redeemable or Twitch refused the redemption.
+	// Stops a misconfigured rule from re-querying rewards on every
+	// points-earned event.
+	redeemRetryBackoff = 15 * time.Minute
+	// maxRedemptionLog bounds the in-memory redemption log.
+	maxRedemptionLog = 100
+)
+
+// Redemption is one auto-redeem attempt.
+type Redemption struct {
+	At      time.Time
+	Channel string // display name
+	Reward  string
+	Cost    int
+	Error   string // "" on success
+}
+
+// Redemptions returns the redemption log, oldest first.
+func (s *Service) Redemptions() []Redemption {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	out := make([]Redemption, len(s.redemptions))
+	copy(out, s.redemptions)
+	return out
+}
+
+// CheckRedeem redeems the first matching configured reward on a channel
+// once its balance reaches the rule's threshold. Cheap when nothing
+// applies (no GQL request unless some rule's threshold is met), so it
+// is called after every balance update. Blocks on GQL — event handlers
+// call it in a goroutine.
+func (s *Service) CheckRedeem(ch *channels.State) {
+	snap := ch.Snapshot()
+	rules := s.cfg.GetRedeemRules(snap.Login)
+	if len(rules) == 0 || !anyRuleMet(rules, snap.PointsBalance) {
+		return
+	}
+
+	now := time.Now()
+	s.mu.Lock()
+	if s.redeemBusy[snap.ChannelID] || now.Before(s.redeemNext[snap.ChannelID]) {
+		s.mu.Unlock()
+		return
+	}
+	s.redeemBusy[snap.ChannelID] = true
+	s.mu.Unlock()
+
+	pause := s.redeemOnce(snap, rules, now)
+
+	s.mu.Lock()
+	delete(s.redeemBusy, snap.ChannelID)
+	s.redeemNext[snap.ChannelID] = time.Now().Add(pause)
+	s.mu.Unlock()
+}
+
+// redeemOnce fetches the channel's rewards, redeems the first match and
+// returns how long to wait before the next check on this channel.
+func (s *Service) redeemOnce(snap channels.Snapshot, rules []config.RedeemRule, now time.Time) time.Duration {
+	channelID, rewards, err := s.gql.GetCustomRewards(snap.Login)
+	if err != nil {
+		s.debugLog("[Redeem] %s: %v", snap.Login, err)
+		return redeemRetryBackoff
+	}
+	if channelID == "" {
+		channelID = snap.ChannelID
+	}
+
+	rule, reward, ok := chooseRedemption(rules, rewards, snap.PointsBalance, now)
+	if !ok {
+		s.debugLog("[Redeem] %s: no configured reward redeemable at balance %d", snap.Login, snap.PointsBalance)
+		return redeemRetryBackoff
+	}
+
+	_, err = s.gql.RedeemCustomReward(channelID, reward, rule.Input)
+	entry := Redemption{At: time.Now(), Channel: snap.DisplayName, Reward: reward.Title, Cost: reward.Cost}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	s.RecordActivity(Activity{
+		At: entry.At, Kind: ActivityRedemption, ChannelID: snap.ChannelID, Channel: snap.DisplayName,
+		Detail: reward.Title, Points: reward.Cost, Error: entry.Error,
+	})
+	if err != nil {
+		s.appendRedemption(entry)
+		s.warn("[Redeem] Failed to redeem %q on %s: %v", reward.Title, snap.DisplayName, err)
+		return redeemRetryBackoff
+	}
+	s.appendRedemption(entry)
+	s.log("[Redeem] Redeemed %q on %s for %d points", reward.Title, snap.DisplayName, reward.Cost)
+	return redeemSuccessPause
+}
+
+func (s *Service) appendRedemption(r Redemption) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.redemptions = append(s.redemptions, r)
+	if len(s.redemptions) > maxRedemptionLog {
+		s.redemptions = s.redemptions[len(s.redemptions)-maxRedemptionLog:]
+	}
+}
+
+func anyRuleMet(rules []config.RedeemRule, balance int) bool {
+	for _, r := range rules {
+		if balance >= r.MinBalance {
+			return true
+		}
+	}
+	return false
+}
+
+// chooseRedemption returns the first rule, in config order, whose
+// threshold is met and whose reward exists, is redeemable right now and
+// is affordable. Rewards that need viewer input only match rules that
+// provide it.
+func chooseRedemption(rules []config.RedeemRule, rewards []twitch.CustomReward, balance int, now time.Time) (config.RedeemRule, twitch.CustomReward, bool) {
+	for _, rule := range rules {
+		if balance < rule.MinBalance {
+			continue
+		}
+		for _, reward := range rewards {
+			if !strings.EqualFold(strings.TrimSpace(reward.Title), strings.TrimSpace(rule.Reward)) {
+				continue
+			}
+			if ok, _ := reward.Redeemable(now); !ok {
+				break
+			}
+			if reward.Cost > balance || (reward.IsUserInputRequired && rule.Input == "") {
+				break
+			}
+			return rule, reward, true
+		}
+	}
+	return config.RedeemRule{}, twitch.CustomReward{}, false
+}
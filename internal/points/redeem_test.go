@@ -0,0 +1,60 @@
+package points
+
+import (
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+func available(title string, cost int) twitch.CustomReward {
+	return twitch.CustomReward{ID: title, Title: title, Cost: cost, IsEnabled: true, IsInStock: true}
+}
+
+func TestChooseRedemption_FirstMetRuleWins(t *testing.T) {
+	rules := []config.RedeemRule{
+		{Reward: "Big One", MinBalance: 50000},
+		{Reward: "hydrate", MinBalance: 1000},
+	}
+	rewards := []twitch.CustomReward{available("Big One", 40000), available("Hydrate", 500)}
+
+	rule, reward, ok := chooseRedemption(rules, rewards, 2000, time.Now())
+	if !ok || reward.Title != "Hydrate" || rule.Reward != "hydrate" {
+		t.Fatalf("balance 2000: got %+v %+v %v, want Hydrate via case-insensitive match", rule, reward, ok)
+	}
+
+	_, reward, ok = chooseRedemption(rules, rewards, 60000, time.Now())
+	if !ok || reward.Title != "Big One" {
+		t.Fatalf("balance 60000: got %+v %v, want Big One (config order)", reward, ok)
+	}
+}
+
+func TestChooseRedemption_SkipsUnredeemable(t *testing.T) {
+	now := time.Now()
+	paused := available("Paused", 100)
+	paused.IsPaused = true
+	cooling := available("Cooling", 100)
+	cooling.CooldownExpiresAt = now.Add(time.Minute)
+	soldOut := available("Sold Out", 100)
+	soldOut.IsInStock = false
+	pricey := available("Pricey", 5000)
+	needsText := available("Say Hi", 100)
+	needsText.IsUserInputRequired = true
+
+	rewards := []twitch.CustomReward{paused, cooling, soldOut, pricey, needsText}
+	for _, title := range []string{"Paused", "Cooling", "Sold Out", "Pricey", "Say Hi", "Missing"} {
+		rules := []config.RedeemRule{{Reward: title, MinBalance: 1000}}
+		if _, r, ok := chooseRedemption(rules, rewards, 1000, now); ok {
+			t.Errorf("%s: chose %+v, want skipped", title, r)
+		}
+	}
+
+	rules := []config.RedeemRule{{Reward: "Say Hi", MinBalance: 1000, Input: "hello"}}
+	if _, _, ok := chooseRedemption(rules, rewards, 1000, now); !ok {
+		t.Error("input-required reward should match a rule that provides input")
+	}
+	if _, _, ok := chooseRedemption(rules, rewards, 999, now); ok {
+		t.Error("rule below its min_balance should not match")
+	}
+}
@@ -1,6 +1,8 @@
 package points
 
 import (
+	"fmt"
+	"slices"
 	"sort"
 	"time"
 
@@ -20,18 +22,37 @@ const rotationInterval = 5 * time.Minute
 // not the legacy POST-spade pipeline that the Spade tracker uses.
 const maxSpadeSlots = 2
 
-// streakHuntWindow is the cutoff past which a channel is no longer
-// considered for the Streak-Hunt slot. Twitch's WATCH_STREAK bonus
-// fires after ~5min watch-time once a streak >=5 is established;
-// past 30min from stream-start the window is almost always closed,
-// so further blocking the slot for this channel wastes bandwidth.
+// streakHuntWindow is the default cutoff past which a channel is no
+// longer considered for the Streak-Hunt slot (streak_window_minutes
+// overrides it). Twitch's WATCH_STREAK bonus fires after ~5min
+// watch-time once a streak >=5 is established; past 30min from
+// stream-start the window is almost always closed, so further blocking
+// the slot for this channel wastes bandwidth.
 const streakHuntWindow = 30 * time.Minute
 
+// interval returns the configured rotation interval, falling back to
+// rotationInterval.
+func (s *Service) interval() time.Duration {
+	if iv := s.cfg.GetRotationInterval(); iv > 0 {
+		return iv
+	}
+	return rotationInterval
+}
+
+// streakWindow returns the configured Streak-Hunt window.
+func (s *Service) streakWindow() time.Duration {
+	if w := s.cfg.GetStreakWindow(); w > 0 {
+		return w
+	}
+	return streakHuntWindow
+}
+
 // isStreakCandidate reports whether a channel is eligible for the
 // Streak-Hunt bucket: online, not owned by the drops watcher, hasn't
 // claimed THIS stream's WATCH_STREAK yet, and is within the hunt
-// window. Pure function — caller passes "now" for testability.
-func isStreakCandidate(snap channels.Snapshot, now time.Time, dropChanID string) bool {
+// window. Pure function — caller passes "now" and the window for
+// testability.
+func isStreakCandidate(snap channels.Snapshot, now time.Time, dropChanID string, window time.Duration) bool {
 	if !snap.IsOnline {
 		return false
 	}
@@ -42,14 +63,14 @@ func isStreakCandidate(snap channels.Snapshot, now time.Time, dropChanID string)
 		// Already claimed (or claimed exactly when online — treat as claimed).
 		return false
 	}
-	if now.Sub(snap.OnlineSince) >= streakHuntWindow {
+	if now.Sub(snap.OnlineSince) >= window {
 		return false
 	}
 	return true
 }
 
 // sortStreakCandidates orders by OnlineSince ASC — oldest stream first,
-// since its 30min window is closest to expiring. Stable tie-break by
+// since its hunt window is closest to expiring. Stable tie-break by
 // ChannelID for deterministic ordering when two candidates share a
 // timestamp (rare but possible in test setups).
 func sortStreakCandidates(list []*channels.State) {
@@ -63,22 +84,54 @@ func sortStreakCandidates(list []*channels.State) {
 	})
 }
 
-// RotationLoop runs Rotate every rotationInterval until stopCh fires.
-// Started as a goroutine from Farmer.Start.
+// RotationLoop runs Rotate every rotation interval until stopCh fires.
+// RotateNow runs it early and restarts the interval. The interval is
+// re-read from the config on every pass, so a changed
+// rotation_interval_minutes applies from the next rotation on. Started
+// as a goroutine from Farmer.Start.
 func (s *Service) RotationLoop(stopCh <-chan struct{}) {
-	ticker := time.NewTicker(rotationInterval)
+	iv := s.interval()
+	ticker := time.NewTicker(iv)
 	defer ticker.Stop()
+	s.setNextRotation(time.Now().Add(iv))
 
 	for {
 		select {
 		case <-ticker.C:
-			s.Rotate()
+		case <-s.rotateNow:
 		case <-stopCh:
 			return
 		}
+		iv = s.interval()
+		ticker.Reset(iv)
+		s.setNextRotation(time.Now().Add(iv))
+		s.Rotate()
 	}
 }
 
+// RotateNow makes RotationLoop rotate right away instead of at the next
+// tick, so priority or pause changes take effect immediately.
+func (s *Service) RotateNow() {
+	select {
+	case s.rotateNow <- struct{}{}:
+	default:
+	}
+}
+
+// NextRotation returns when RotationLoop rotates next (zero before the
+// loop has started).
+func (s *Service) NextRotation() time.Time {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.nextRotation
+}
+
+func (s *Service) setNextRotation(t time.Time) {
+	s.mu.Lock()
+	s.nextRotation = t
+	s.mu.Unlock()
+}
+
 // Rotate computes the desired 2-channel watch set and diffs it against
 // what Spade is currently watching: stops anything that fell out, keeps
 // anything that stays (refreshing the broadcast ID), starts anything
@@ -91,17 +144,93 @@ func (s *Service) RotationLoop(stopCh <-chan struct{}) {
 // it via the Spade POST endpoint would create cross-talk and may flag
 // the user as suspicious.
 func (s *Service) Rotate() {
+	if s.farmingPaused() {
+		return // Farmer.Pause already released every slot
+	}
+	plan := s.planRotation(time.Now())
+
+	// Diff vs what's currently watching: stop anything that fell out,
+	// keep anything that stays (and refresh its broadcast ID in case the
+	// streamer restarted mid-cycle).
+	currentlyWatching := make(map[string]bool)
+	for _, ch := range plan.ranked {
+		if !ch.Snapshot().IsWatching {
+			continue
+		}
+		currentlyWatching[ch.ChannelID] = true
+		if _, keep := plan.desired[ch.ChannelID]; !keep {
+			s.spade.StopWatching(ch.ChannelID)
+			s.prober.Stop(ch.Login)
+			ch.SetWatching(false)
+		} else {
+			snap := ch.Snapshot()
+			s.spade.UpdateBroadcastID(snap.ChannelID, snap.BroadcastID, snap.GameName, snap.GameID)
+		}
+	}
+
+	for _, ch := range plan.release {
+		s.StopWatching(ch)
+	}
+
+	// Start newly desired channels.
+	for chID, ch := range plan.desired {
+		if currentlyWatching[chID] {
+			continue
+		}
+		snap := ch.Snapshot()
+		broadcastID := snap.BroadcastID
+		if broadcastID == "" {
+			go s.fetchAndStartWatching(ch)
+			continue
+		}
+		if s.spade.StartWatching(snap.ChannelID, snap.Login, broadcastID, snap.GameName, snap.GameID) {
+			ch.SetWatching(true)
+			s.prober.Start(snap.Login)
+			s.log("Started watching %s (broadcast=%s, via rotation)", snap.DisplayName, broadcastID)
+		} else {
+			s.log("[Spade] StartWatching for %s returned false (capacity full)", snap.DisplayName)
+		}
+	}
+	s.syncWatchingIRC()
+}
+
+// rotationPlan is what Rotate applies: the desired watch set, every
+// channel that competed for a slot (in rank order) and the watched
+// channels that must give theirs up.
+type rotationPlan struct {
+	desired map[string]*channels.State
+	ranked  []*channels.State
+	release []*channels.State
+}
+
+// planRotation ranks the online channels into the priority buckets and
+// picks the desired watch set. It moves the P2 cursor but doesn't touch
+// Spade, so tests can check the allocation directly.
+func (s *Service) planRotation(now time.Time) rotationPlan {
 	dropChanID := ""
 	if s.dropWatch != nil {
 		dropChanID = s.dropWatch.CurrentChannelID()
 	}
 
-	now := time.Now()
+	window := s.streakWindow()
+	preserveStreaks := s.cfg.GetStreakPreservation()
+	weightSubs := s.cfg.GetWeightSubMultipliers()
+
+	s.mu.RLock()
+	forcedID := s.forcedChannelID
+	if now.After(s.forcedUntil) {
+		forcedID = ""
+	}
+	s.mu.RUnlock()
 
+	var forced []*channels.State         // user's "watch now" pick, outranks P0
+	var priorityHype []*channels.State   // running Hype Train, outranks P0
 	var priority0 []*channels.State      // P0: active drop (auto-promoted)
 	var priorityStreak []*channels.State // PS: fresh-online, unclaimed streak (NEW)
 	var priority1 []*channels.State
 	var priority2 []*channels.State
+	var priorityGoal []*channels.State // points goal reached or deprioritized rerun: leftover slots only
+	var release []*channels.State      // watched, but benched, inactive or over max_points
 	for _, ch := range s.channels.States() {
 		snap := ch.Snapshot()
 		if !snap.IsOnline {
@@ -110,6 +239,34 @@ func (s *Service) Rotate() {
 		if snap.ChannelID == dropChanID {
 			continue // drops Watcher owns this — don't add to Spade rotation
 		}
+		if snap.Paused || s.benchedDropsOnly(snap) || s.spadeDisabled(snap) {
+			continue
+		}
+		// Over max_points: no slot until spending brings it back
+		// under (CheckGoal logs both transitions).
+		if s.capReached(snap) {
+			if snap.IsWatching {
+				release = append(release, ch)
+			}
+			continue
+		}
+		if reason := s.inactiveStream(snap, now); reason != "" || snap.HeartbeatBenched(now) {
+			if snap.IsWatching {
+				if reason != "" {
+					s.log("[Spade] Releasing %s's slot — stream looks inactive (%s)", snap.DisplayName, reason)
+				}
+				release = append(release, ch)
+			}
+			continue
+		}
+		if snap.ChannelID == forcedID {
+			forced = append(forced, ch)
+			continue
+		}
+		if snap.InHypeTrain(now) && !s.goalReached(snap) {
+			priorityHype = append(priorityHype, ch)
+			continue
+		}
 		// Drops auto-promote to P0; keeps existing precedence rule
 		// (a channel with both an active drop AND an unclaimed streak
 		// goes to P0 — drops are typically worth more than 450 points).
@@ -117,8 +274,17 @@ func (s *Service) Rotate() {
 			priority0 = append(priority0, ch)
 			continue
 		}
+		// A channel that has saved up its goal doesn't need points
+		// (streak bonus included) — it only gets a slot nobody else wants.
+		// Neither does a rerun with rerun_mode "deprioritize": reruns
+		// rarely grant streaks.
+		if s.goalReached(snap) || s.deprioritizedRerun(snap) {
+			priorityGoal = append(priorityGoal, ch)
+			continue
+		}
 		// Streak-Hunt sits between P0 and P1 — fresh-online, unclaimed.
-		if isStreakCandidate(snap, now, dropChanID) {
+		// With streak_preservation it moves ahead of P0 instead.
+		if isStreakCandidate(snap, now, dropChanID, window) {
 			priorityStreak = append(priorityStreak, ch)
 			continue
 		}
@@ -129,6 +295,8 @@ func (s *Service) Rotate() {
 		}
 	}
 
+	sortHypeTrains(priorityHype)
+
 	// Sort P0 by campaign end time (soonest expiring first gets the Spade slot).
 	sort.Slice(priority0, func(i, j int) bool {
 		ei := s.drops.CampaignEndAt(priority0[i].Snapshot().CampaignID)
@@ -150,8 +318,17 @@ func (s *Service) Rotate() {
 	sort.Slice(priority2, func(i, j int) bool {
 		return priority2[i].ChannelID < priority2[j].ChannelID
 	})
+	sort.Slice(priorityGoal, func(i, j int) bool {
+		return priorityGoal[i].ChannelID < priorityGoal[j].ChannelID
+	})
+	if weightSubs {
+		sortByMultiplier(priority1)
+	}
 
-	// Build the desired watch set: P0 → PS → P1 → P2 (rotated cursor).
+	// Build the desired watch set: forced → hype → P0 → PS → P1 → P2
+	// (rotated cursor) → goal reached, or forced → hype → PS → P0 → ...
+	// with streak_preservation. With weight_sub_multipliers the P2
+	// slots are shared out by points multiplier instead of the cursor.
 	desired := make(map[string]*channels.State)
 
 	// Since 2026-07-10 the drop pick needs a Spade heartbeat slot of its
@@ -164,25 +341,37 @@ func (s *Service) Rotate() {
 	}
 
 	slotsUsed := 0
-	for _, ch := range priority0 {
+	for _, ch := range forced {
 		if slotsUsed >= slotLimit {
 			break
 		}
 		desired[ch.ChannelID] = ch
 		slotsUsed++
 	}
-
-	// Streak-Hunt: FIFO by OnlineSince ASC. Never starves P1/P2 long-term
-	// because each candidate either claims (within ~5-15min) or times out
-	// (30min hard cap), then drops back to P2 next tick.
-	sortStreakCandidates(priorityStreak)
-	for _, ch := range priorityStreak {
+	for _, ch := range priorityHype {
 		if slotsUsed >= slotLimit {
 			break
 		}
 		desired[ch.ChannelID] = ch
 		slotsUsed++
 	}
+	// Streak-Hunt: FIFO by OnlineSince ASC. Never starves P1/P2 long-term
+	// because each candidate either claims (within ~5-15min) or times out
+	// (hunt window, 30min by default), then drops back to P2 next tick.
+	sortStreakCandidates(priorityStreak)
+	buckets := [][]*channels.State{priority0, priorityStreak}
+	if preserveStreaks {
+		buckets = [][]*channels.State{priorityStreak, priority0}
+	}
+	for _, list := range buckets {
+		for _, ch := range list {
+			if slotsUsed >= slotLimit {
+				break
+			}
+			desired[ch.ChannelID] = ch
+			slotsUsed++
+		}
+	}
 
 	for _, ch := range priority1 {
 		if slotsUsed >= slotLimit {
@@ -193,7 +382,12 @@ func (s *Service) Rotate() {
 	}
 
 	remainingSlots := slotLimit - slotsUsed
-	if remainingSlots > 0 && len(priority2) > 0 {
+	if remainingSlots > 0 && len(priority2) > 0 && weightSubs {
+		for _, ch := range s.weightedPicks(priority2, remainingSlots) {
+			desired[ch.ChannelID] = ch
+			slotsUsed++
+		}
+	} else if remainingSlots > 0 && len(priority2) > 0 {
 		s.mu.Lock()
 		idx := s.rotationIndex % len(priority2)
 		s.rotationIndex = (s.rotationIndex + remainingSlots) % len(priority2)
@@ -202,49 +396,22 @@ func (s *Service) Rotate() {
 		for i := 0; i < remainingSlots && i < len(priority2); i++ {
 			ch := priority2[(idx+i)%len(priority2)]
 			desired[ch.ChannelID] = ch
+			slotsUsed++
 		}
 	}
-
-	// Diff vs what's currently watching: stop anything that fell out,
-	// keep anything that stays (and refresh its broadcast ID in case the
-	// streamer restarted mid-cycle).
-	currentlyWatching := make(map[string]bool)
-	for _, list := range [][]*channels.State{priority0, priorityStreak, priority1, priority2} {
-		for _, ch := range list {
-			if !ch.Snapshot().IsWatching {
-				continue
-			}
-			currentlyWatching[ch.ChannelID] = true
-			if _, keep := desired[ch.ChannelID]; !keep {
-				s.spade.StopWatching(ch.ChannelID)
-				s.prober.Stop(ch.Login)
-				ch.SetWatching(false)
-			} else {
-				snap := ch.Snapshot()
-				s.spade.UpdateBroadcastID(snap.ChannelID, snap.BroadcastID, snap.GameName, snap.GameID)
-			}
+	for _, ch := range priorityGoal {
+		if slotsUsed >= slotLimit {
+			break
 		}
+		desired[ch.ChannelID] = ch
+		slotsUsed++
 	}
 
-	// Start newly desired channels.
-	for chID, ch := range desired {
-		if currentlyWatching[chID] {
-			continue
-		}
-		snap := ch.Snapshot()
-		broadcastID := snap.BroadcastID
-		if broadcastID == "" {
-			go s.fetchAndStartWatching(ch)
-			continue
-		}
-		if s.spade.StartWatching(snap.ChannelID, snap.Login, broadcastID, snap.GameName, snap.GameID) {
-			ch.SetWatching(true)
-			s.prober.Start(snap.Login)
-			s.log("Started watching %s (broadcast=%s, via rotation)", snap.DisplayName, broadcastID)
-		} else {
-			s.log("[Spade] StartWatching for %s returned false (capacity full)", snap.DisplayName)
-		}
+	var ranked []*channels.State
+	for _, list := range [][]*channels.State{forced, priorityHype, priority0, priorityStreak, priority1, priority2, priorityGoal} {
+		ranked = append(ranked, list...)
 	}
+	return rotationPlan{desired: desired, ranked: ranked, release: release}
 }
 
 // fetchAndStartWatching fills in a missing broadcast ID via GQL before
@@ -255,7 +422,7 @@ func (s *Service) Rotate() {
 func (s *Service) fetchAndStartWatching(ch *channels.State) {
 	info, err := s.gql.GetChannelInfo(ch.Login)
 	if err != nil {
-		s.log("[Spade] failed to fetch broadcast ID for %s: %v", ch.DisplayName, err)
+		s.warn("[Spade] failed to fetch broadcast ID for %s: %v", ch.DisplayName, err)
 		return
 	}
 	if info.BroadcastID == "" {
@@ -263,20 +430,28 @@ func (s *Service) fetchAndStartWatching(ch *channels.State) {
 		return
 	}
 	ch.SetOnlineWithGameID(info.BroadcastID, info.GameName, info.GameID, info.ViewerCount, info.StreamCreatedAt)
+	ch.SetRerun(info.IsRerun())
+	if s.farmingPaused() {
+		return // paused while the lookup ran
+	}
 	if s.spade.StartWatching(ch.ChannelID, ch.Login, info.BroadcastID, info.GameName, info.GameID) {
 		ch.SetWatching(true)
 		s.prober.Start(ch.Login)
 		s.log("Started watching %s (broadcast=%s)", ch.DisplayName, info.BroadcastID)
+		s.syncWatchingIRC()
 	}
 }
 
 // TryStartWatching is the points-side single-channel start path: used by
-// Farmer when a channel is added (addChannelWithInfo) or comes online
-// (EventStreamUp). It refuses to double-track the drops Watcher's
+// Farmer when a channel is added (addChannelWithInfo) and by
+// HandleStreamUp when one comes online. It refuses to double-track the drops Watcher's
 // current pick — drops has exclusive ownership of that channel.
 func (s *Service) TryStartWatching(state *channels.State) {
 	snap := state.Snapshot()
-	if !snap.IsOnline || snap.IsWatching {
+	if !snap.IsOnline || snap.IsWatching || snap.Paused || s.farmingPaused() || s.benchedDropsOnly(snap) || s.spadeDisabled(snap) || s.capReached(snap) {
+		return
+	}
+	if s.inactiveStream(snap, time.Now()) != "" {
 		return
 	}
 
@@ -293,28 +468,178 @@ func (s *Service) TryStartWatching(state *channels.State) {
 		state.SetWatching(true)
 		s.prober.Start(snap.Login)
 		s.log("Started watching %s (Spade active, broadcast=%s)", snap.DisplayName, snap.BroadcastID)
+		s.syncWatchingIRC()
+	}
+}
+
+// HandleStreamUp starts watching a channel that just came online. With
+// streak_preservation a channel that can't get a free slot but still has
+// its watch streak to claim is rotated in right away — displacing a
+// lower-ranked channel — instead of waiting for the next tick, and a
+// rotation is scheduled for the end of its hunt window so the slot goes
+// back to normal rotation on time.
+func (s *Service) HandleStreamUp(ch *channels.State) {
+	s.TryStartWatching(ch)
+	if !s.cfg.GetStreakPreservation() {
+		return
+	}
+	dropChanID := ""
+	if s.dropWatch != nil {
+		dropChanID = s.dropWatch.CurrentChannelID()
+	}
+	snap := ch.Snapshot()
+	window := s.streakWindow()
+	if snap.Paused || s.farmingPaused() || s.benchedDropsOnly(snap) || s.spadeDisabled(snap) || !isStreakCandidate(snap, time.Now(), dropChanID, window) {
+		return
+	}
+	if !snap.IsWatching {
+		s.log("Streak-Hunt: rotating %s in for its watch streak (up to %v)", snap.DisplayName, window)
+		s.Rotate()
+	}
+	time.AfterFunc(time.Until(snap.OnlineSince.Add(window))+time.Second, s.RotateNow)
+}
+
+// StopWatching releases a channel's Spade slot immediately instead of
+// waiting for the next Rotate. Used when the user pauses a channel.
+func (s *Service) StopWatching(ch *channels.State) {
+	if !ch.Snapshot().IsWatching {
+		return
+	}
+	s.spade.StopWatching(ch.ChannelID)
+	s.prober.Stop(ch.Login)
+	ch.SetWatching(false)
+	s.syncWatchingIRC()
+}
+
+// ForceWatch puts a channel at the front of the watch set for one
+// rotation interval, ahead of active drops, then rotates immediately.
+// After one interval the channel falls back to its normal bucket.
+func (s *Service) ForceWatch(ch *channels.State) error {
+	snap := ch.Snapshot()
+	switch {
+	case s.farmingPaused():
+		return fmt.Errorf("farming is paused")
+	case !snap.IsOnline:
+		return fmt.Errorf("%s is offline", snap.DisplayName)
+	case snap.Paused:
+		return fmt.Errorf("%s is paused", snap.DisplayName)
+	case s.benchedDropsOnly(snap):
+		return fmt.Errorf("%s is drops-only and serves no active campaign", snap.DisplayName)
+	case s.spadeDisabled(snap):
+		return fmt.Errorf("%s has Spade heartbeats disabled", snap.DisplayName)
+	case s.dropWatch != nil && s.dropWatch.CurrentChannelID() == snap.ChannelID:
+		return fmt.Errorf("%s is already being watched for drops", snap.DisplayName)
+	}
+
+	iv := s.interval()
+	s.mu.Lock()
+	s.forcedChannelID = snap.ChannelID
+	s.forcedUntil = time.Now().Add(iv)
+	s.mu.Unlock()
+
+	s.log("Force-watching %s for the next %v", snap.DisplayName, iv)
+	s.Rotate()
+	return nil
+}
+
+// sortHypeTrains orders running Hype Trains by level DESC (higher level,
+// bigger multiplier), then channel ID for a stable pick.
+func sortHypeTrains(list []*channels.State) {
+	sort.Slice(list, func(i, j int) bool {
+		li, lj := list[i].Snapshot().HypeTrainLevel, list[j].Snapshot().HypeTrainLevel
+		if li != lj {
+			return li > lj
+		}
+		return list[i].ChannelID < list[j].ChannelID
+	})
+}
+
+// multiplier returns the channel's points multiplier, 1 while unknown.
+func multiplier(snap channels.Snapshot) float64 {
+	if snap.PointsMultiplier < 1 {
+		return 1
 	}
+	return snap.PointsMultiplier
+}
+
+// sortByMultiplier orders by points multiplier DESC (a tier 3 sub earns
+// twice as much per slot-hour as no sub), keeping the existing order
+// among equal multipliers.
+func sortByMultiplier(list []*channels.State) {
+	mult := make(map[*channels.State]float64, len(list))
+	for _, ch := range list {
+		mult[ch] = multiplier(ch.Snapshot())
+	}
+	sort.SliceStable(list, func(i, j int) bool {
+		return mult[list[i]] > mult[list[j]]
+	})
+}
+
+// weightedPicks picks n of the P2 channels (sorted by channel ID) for
+// this rotation with weight_sub_multipliers. Every rotation each channel
+// earns its points multiplier as credit, and the n with the most credit
+// get a slot and pay the average price for it. A channel's share of the
+// P2 slots thus follows its multiplier, and one without a sub still gets
+// its turns.
+func (s *Service) weightedPicks(list []*channels.State, n int) []*channels.State {
+	if n >= len(list) {
+		return list
+	}
+	weight := make(map[string]float64, len(list))
+	total := 0.0
+	for _, ch := range list {
+		weight[ch.ChannelID] = multiplier(ch.Snapshot())
+		total += weight[ch.ChannelID]
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	// Rebuilt each time, so a channel that leaves P2 starts over.
+	credit := make(map[string]float64, len(list))
+	for _, ch := range list {
+		credit[ch.ChannelID] = s.p2Credit[ch.ChannelID] + weight[ch.ChannelID]
+	}
+	picks := slices.Clone(list)
+	sort.SliceStable(picks, func(i, j int) bool {
+		return credit[picks[i].ChannelID] > credit[picks[j].ChannelID]
+	})
+	picks = picks[:n]
+	for _, ch := range picks {
+		credit[ch.ChannelID] -= total / float64(n)
+	}
+	s.p2Credit = credit
+	return picks
 }
 
 // orderFillCandidates returns the input list sorted by:
-//  1. Streak-Hunt candidates first (FIFO by OnlineSince ASC)
-//  2. Everything else by ViewerCount DESC (existing behavior)
+//  1. Running Hype Trains (level DESC)
+//  2. Streak-Hunt candidates (FIFO by OnlineSince ASC)
+//  3. Everything else by ViewerCount DESC (existing behavior), or by
+//     points multiplier DESC first with weightSubs
 //
 // Pure function for testability — caller passes "now" and dropChanID.
-func orderFillCandidates(in []*channels.State, now time.Time, dropChanID string) []*channels.State {
-	var streak, rest []*channels.State
+func orderFillCandidates(in []*channels.State, now time.Time, dropChanID string, window time.Duration, weightSubs bool) []*channels.State {
+	var hype, streak, rest []*channels.State
 	for _, ch := range in {
-		if isStreakCandidate(ch.Snapshot(), now, dropChanID) {
+		snap := ch.Snapshot()
+		switch {
+		case snap.InHypeTrain(now):
+			hype = append(hype, ch)
+		case isStreakCandidate(snap, now, dropChanID, window):
 			streak = append(streak, ch)
-		} else {
+		default:
 			rest = append(rest, ch)
 		}
 	}
+	sortHypeTrains(hype)
 	sortStreakCandidates(streak)
 	sort.Slice(rest, func(i, j int) bool {
 		return rest[i].Snapshot().ViewerCount > rest[j].Snapshot().ViewerCount
 	})
-	return append(streak, rest...)
+	if weightSubs {
+		sortByMultiplier(rest)
+	}
+	return append(append(hype, streak...), rest...)
 }
 
 // FillSpadeSlots scans for online-but-not-watching channels and tops up
@@ -322,24 +647,36 @@ func orderFillCandidates(in []*channels.State, now time.Time, dropChanID string)
 // EventStreamDown frees a slot AND by the WATCH_STREAK event handler
 // to immediately rotate in the next streak candidate.
 //
-// Selection order: Streak-Hunt candidates first (FIFO by OnlineSince),
-// then remaining channels by ViewerCount desc.
+// Selection order: running Hype Trains, then Streak-Hunt candidates
+// (FIFO by OnlineSince), then remaining channels by ViewerCount desc
+// (points multiplier first with weight_sub_multipliers), with channels that reached their points goal or stream a deprioritized
+// rerun last.
 func (s *Service) FillSpadeSlots() {
+	if s.farmingPaused() {
+		return
+	}
 	dropChanID := ""
 	if s.dropWatch != nil {
 		dropChanID = s.dropWatch.CurrentChannelID()
 	}
 	now := time.Now()
 
-	var candidates []*channels.State
+	var candidates, reached []*channels.State
 	for _, ch := range s.channels.States() {
 		snap := ch.Snapshot()
-		if snap.IsOnline && !snap.IsWatching {
+		if !snap.IsOnline || snap.IsWatching || snap.Paused || s.benchedDropsOnly(snap) || s.spadeDisabled(snap) ||
+			snap.HeartbeatBenched(now) || s.inactiveStream(snap, now) != "" || s.capReached(snap) {
+			continue
+		}
+		if s.goalReached(snap) || s.deprioritizedRerun(snap) {
+			reached = append(reached, ch)
+		} else {
 			candidates = append(candidates, ch)
 		}
 	}
 
-	for _, ch := range orderFillCandidates(candidates, now, dropChanID) {
+	ordered := orderFillCandidates(candidates, now, dropChanID, s.streakWindow(), s.cfg.GetWeightSubMultipliers())
+	for _, ch := range append(ordered, reached...) {
 		if s.spade.ActiveSlots() <= 0 {
 			break
 		}
@@ -1,10 +1,16 @@
 package points
 
 import (
+	"sort"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
 func TestClassifyStreakBucket_FreshUnclaimedOnline_IsCandidate(t *testing.T) {
@@ -12,7 +18,7 @@ func TestClassifyStreakBucket_FreshUnclaimedOnline_IsCandidate(t *testing.T) {
 	ch.SetPriority(2)
 	ch.SetOnline("b1", "G", 5)
 	// Note: OnlineSince was just set to "now"; StreakClaimedAt is zero.
-	if !isStreakCandidate(ch.Snapshot(), time.Now(), "" /*dropChanID*/) {
+	if !isStreakCandidate(ch.Snapshot(), time.Now(), "" /*dropChanID*/, streakHuntWindow) {
 		t.Error("fresh online + unclaimed should be streak candidate")
 	}
 }
@@ -22,7 +28,7 @@ func TestClassifyStreakBucket_ClaimedThisStream_NotCandidate(t *testing.T) {
 	ch.SetPriority(2)
 	ch.SetOnline("b1", "G", 5)
 	ch.MarkStreakClaimed()
-	if isStreakCandidate(ch.Snapshot(), time.Now(), "") {
+	if isStreakCandidate(ch.Snapshot(), time.Now(), "", streakHuntWindow) {
 		t.Error("already-claimed channel should not be candidate")
 	}
 }
@@ -33,7 +39,7 @@ func TestClassifyStreakBucket_OldStream_NotCandidate(t *testing.T) {
 	ch.SetOnline("b1", "G", 5)
 	// Simulate a stream that's been online > 30min by passing a future "now".
 	now := time.Now().Add(31 * time.Minute)
-	if isStreakCandidate(ch.Snapshot(), now, "") {
+	if isStreakCandidate(ch.Snapshot(), now, "", streakHuntWindow) {
 		t.Error("stream online >30min should not be streak candidate")
 	}
 }
@@ -42,7 +48,7 @@ func TestClassifyStreakBucket_Offline_NotCandidate(t *testing.T) {
 	ch := channels.NewState("alice", "Alice", "111")
 	ch.SetPriority(2)
 	// never set online
-	if isStreakCandidate(ch.Snapshot(), time.Now(), "") {
+	if isStreakCandidate(ch.Snapshot(), time.Now(), "", streakHuntWindow) {
 		t.Error("offline channel should not be streak candidate")
 	}
 }
@@ -52,7 +58,7 @@ func TestClassifyStreakBucket_DropOwnedByDropsWatcher_NotCandidate(t *testing.T)
 	ch.SetPriority(2)
 	ch.SetOnline("b1", "G", 5)
 	// drops watcher owns this channel — must be skipped
-	if isStreakCandidate(ch.Snapshot(), time.Now(), "111") {
+	if isStreakCandidate(ch.Snapshot(), time.Now(), "111", streakHuntWindow) {
 		t.Error("drops-owned channel should not be streak candidate")
 	}
 }
@@ -70,7 +76,7 @@ func TestClassifyStreakBucket_StaleClaimFromPriorStream_IsCandidate(t *testing.T
 	ch.SetOffline()
 	time.Sleep(2 * time.Millisecond)
 	ch.SetOnline("b2", "G", 5)
-	if !isStreakCandidate(ch.Snapshot(), time.Now(), "") {
+	if !isStreakCandidate(ch.Snapshot(), time.Now(), "", streakHuntWindow) {
 		t.Error("after restart, prior-stream claim should not block new candidacy")
 	}
 }
@@ -115,7 +121,7 @@ func TestSelectFillCandidates_StreakBeforeViewerCount(t *testing.T) {
 	// StreakClaimedAt left zero → unclaimed → streak candidate
 
 	candidates := []*channels.State{bigViewer, freshLive}
-	ordered := orderFillCandidates(candidates, time.Now(), "")
+	ordered := orderFillCandidates(candidates, time.Now(), "", streakHuntWindow, false)
 
 	if len(ordered) != 2 {
 		t.Fatalf("got %d candidates, want 2", len(ordered))
@@ -142,7 +148,7 @@ func TestSelectFillCandidates_NoStreakCandidates_FallsBackToViewerCount(t *testi
 	chBig.MarkStreakClaimed()
 
 	ordered := orderFillCandidates(
-		[]*channels.State{chSmall, chBig}, time.Now(), "",
+		[]*channels.State{chSmall, chBig}, time.Now(), "", streakHuntWindow, false,
 	)
 
 	if ordered[0].ChannelID != "2" {
@@ -150,3 +156,297 @@ func TestSelectFillCandidates_NoStreakCandidates_FallsBackToViewerCount(t *testi
 			ordered[0].ChannelID)
 	}
 }
+
+func TestSelectFillCandidates_HypeTrainFirst(t *testing.T) {
+	now := time.Now()
+
+	fresh := channels.NewState("fresh", "Fresh", "1")
+	fresh.SetOnline("b1", "G", 10) // unclaimed streak candidate
+
+	lowHype := channels.NewState("low", "Low", "2")
+	lowHype.SetOnline("b2", "G", 10)
+	lowHype.MarkStreakClaimed()
+	lowHype.SetHypeTrain(1, now.Add(3*time.Minute))
+
+	highHype := channels.NewState("high", "High", "3")
+	highHype.SetOnline("b3", "G", 10)
+	highHype.MarkStreakClaimed()
+	highHype.SetHypeTrain(4, now.Add(3*time.Minute))
+
+	expired := channels.NewState("expired", "Expired", "4")
+	expired.SetOnline("b4", "G", 1000)
+	expired.MarkStreakClaimed()
+	expired.SetHypeTrain(5, now.Add(-time.Minute))
+
+	ordered := orderFillCandidates(
+		[]*channels.State{expired, fresh, lowHype, highHype}, now, "", streakHuntWindow, false,
+	)
+
+	var got []string
+	for _, ch := range ordered {
+		got = append(got, ch.ChannelID)
+	}
+	want := []string{"3", "2", "1", "4"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("order = %v, want %v", got, want)
+		}
+	}
+}
+
+// TestSelectFillCandidates_WeightSubs: with weightSubs a subscribed
+// channel outranks a bigger one without a sub; viewer count still breaks
+// ties between equal multipliers.
+func TestSelectFillCandidates_WeightSubs(t *testing.T) {
+	var in []*channels.State
+	for _, c := range []struct {
+		id      string
+		viewers int
+		mult    float64
+	}{{"1", 1000, 0}, {"2", 10, 1.2}, {"3", 50, 2}, {"4", 500, 1.2}} {
+		ch := channels.NewState("c"+c.id, "C"+c.id, c.id)
+		ch.SetOnline("b"+c.id, "G", c.viewers)
+		ch.MarkStreakClaimed()
+		ch.SetMultiplier(c.mult, 0)
+		in = append(in, ch)
+	}
+
+	for _, tc := range []struct {
+		weight bool
+		want   []string
+	}{
+		{false, []string{"1", "4", "3", "2"}},
+		{true, []string{"3", "4", "2", "1"}},
+	} {
+		var got []string
+		for _, ch := range orderFillCandidates(in, time.Now(), "", streakHuntWindow, tc.weight) {
+			got = append(got, ch.ChannelID)
+		}
+		if strings.Join(got, ",") != strings.Join(tc.want, ",") {
+			t.Errorf("weightSubs=%v: order = %v, want %v", tc.weight, got, tc.want)
+		}
+	}
+}
+
+func TestBenchedDropsOnly(t *testing.T) {
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{
+		{Login: "dropper", Priority: 2, Mode: config.ChannelModeDrops},
+		{Login: "pointer", Priority: 2, Mode: config.ChannelModePoints},
+	}}
+	s := &Service{cfg: cfg}
+
+	if !s.benchedDropsOnly(channels.Snapshot{Login: "dropper"}) {
+		t.Error("drops-only channel without an active drop should be benched")
+	}
+	if s.benchedDropsOnly(channels.Snapshot{Login: "dropper", HasActiveDrop: true}) {
+		t.Error("drops-only channel serving a campaign should get a slot")
+	}
+	if s.benchedDropsOnly(channels.Snapshot{Login: "pointer"}) || s.benchedDropsOnly(channels.Snapshot{Login: "temp"}) {
+		t.Error("only drops-only channels are benched")
+	}
+}
+
+func TestCapReached(t *testing.T) {
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{
+		{Login: "capped", Priority: 2, MaxPoints: 1000},
+		{Login: "free", Priority: 2},
+	}}
+	s := &Service{cfg: cfg}
+
+	if s.capReached(channels.Snapshot{Login: "capped", PointsBalance: 999}) {
+		t.Error("balance under max_points should keep the slot")
+	}
+	if !s.capReached(channels.Snapshot{Login: "capped", PointsBalance: 1000}) {
+		t.Error("balance at max_points should lose the slot")
+	}
+	if s.capReached(channels.Snapshot{Login: "capped", PointsBalance: 5000, HasActiveDrop: true}) {
+		t.Error("a channel serving a drop campaign is exempt")
+	}
+	if s.capReached(channels.Snapshot{Login: "free", PointsBalance: 1 << 20}) {
+		t.Error("no max_points means no cap")
+	}
+}
+
+func TestRerunMode(t *testing.T) {
+	cfg := &config.Config{}
+	s := &Service{cfg: cfg}
+	rerun := channels.Snapshot{Login: "alpha", IsOnline: true, IsRerun: true}
+	live := channels.Snapshot{Login: "beta", IsOnline: true}
+	now := time.Now()
+
+	if s.inactiveStream(rerun, now) != "" || s.deprioritizedRerun(rerun) {
+		t.Error("rerun_mode watch should treat a rerun like a live stream")
+	}
+	cfg.RerunMode = config.RerunModeDeprioritize
+	if s.inactiveStream(rerun, now) != "" || !s.deprioritizedRerun(rerun) || s.deprioritizedRerun(live) {
+		t.Error("rerun_mode deprioritize should move only the rerun back")
+	}
+	cfg.RerunMode = config.RerunModeSkip
+	if s.inactiveStream(rerun, now) != "rerun" || s.inactiveStream(live, now) != "" || s.deprioritizedRerun(rerun) {
+		t.Error("rerun_mode skip should keep only the rerun out of rotation")
+	}
+}
+
+func TestFarmingPaused(t *testing.T) {
+	s := &Service{cfg: &config.Config{}}
+	if s.farmingPaused() {
+		t.Fatal("no Paused dep means never paused")
+	}
+	paused := true
+	s.isPaused = func() bool { return paused }
+	ch := channels.NewState("alpha", "Alpha", "1")
+	if err := s.ForceWatch(ch); err == nil || err.Error() != "farming is paused" {
+		t.Fatalf("ForceWatch while paused = %v", err)
+	}
+	// Rotate and FillSpadeSlots must bail before touching the (nil)
+	// registry and trackers.
+	s.Rotate()
+	s.FillSpadeSlots()
+}
+
+// newRotationService returns a Service over a registry holding states,
+// enough for planRotation.
+func newRotationService(cfg *config.Config, states ...*channels.State) *Service {
+	reg := channels.New()
+	for _, st := range states {
+		reg.Add(st)
+	}
+	return &Service{cfg: cfg, channels: reg, drops: &drops.Service{}, log: func(string, ...interface{}) {}, warn: func(string, ...interface{}) {}}
+}
+
+// TestRotate_WeightSubs: with weight_sub_multipliers the P2 slots go
+// out in proportion to the points multiplier — the tier 3 sub gets
+// twice the turns of each channel without a sub, which still get theirs.
+// Without it the cursor gives everyone the same share.
+func TestRotate_WeightSubs(t *testing.T) {
+	for _, tc := range []struct {
+		weight bool
+		want   string
+	}{
+		{false, "1:5 2:5 3:5 4:5"},
+		{true, "1:8 2:4 3:4 4:4"},
+	} {
+		var states []*channels.State
+		for i, mult := range []float64{2, 0, 0, 0} {
+			id := string(rune('1' + i))
+			st := channels.NewState("c"+id, "C"+id, id)
+			st.SetPriority(2)
+			st.SetOnline("b"+id, "G", 10)
+			st.MarkStreakClaimed()
+			st.SetMultiplier(mult, 0)
+			states = append(states, st)
+		}
+		s := newRotationService(&config.Config{WeightSubMultipliers: tc.weight}, states...)
+		noLog := func(string, ...interface{}) {}
+		// A non-numeric user ID makes every heartbeat bail before the
+		// network, and a stopped prober never starts.
+		s.spade = twitch.NewSpadeTracker("test", "", "", nil, noLog)
+		defer s.spade.Stop()
+		s.prober = twitch.NewStreamProber(nil, "", "", "", noLog)
+		s.prober.StopAll()
+
+		turns := map[string]int{}
+		for tick := 0; tick < 10; tick++ {
+			s.Rotate()
+			if n := s.spade.WatchedCount(); n != maxSpadeSlots {
+				t.Fatalf("weightSubs=%v tick %d: %d channels watched, want %d", tc.weight, tick, n, maxSpadeSlots)
+			}
+			for _, st := range states {
+				if st.Snapshot().IsWatching {
+					turns[st.ChannelID]++
+				}
+			}
+		}
+		var got []string
+		for _, st := range states {
+			got = append(got, st.ChannelID+":"+strconv.Itoa(turns[st.ChannelID]))
+		}
+		if strings.Join(got, " ") != tc.want {
+			t.Errorf("weightSubs=%v: turns %s, want %s", tc.weight, strings.Join(got, " "), tc.want)
+		}
+	}
+}
+
+// plannedIDs returns the channel IDs of the planned watch set, sorted.
+func plannedIDs(plan rotationPlan) string {
+	var ids []string
+	for id := range plan.desired {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return strings.Join(ids, ",")
+}
+
+// TestPlanRotation_StreakPreservation: a fresh streak candidate ranks
+// below P0 by default and above it with streak_preservation, and above
+// P1 either way. The forced pick leaves one slot to fight over.
+func TestPlanRotation_StreakPreservation(t *testing.T) {
+	for _, tc := range []struct {
+		preserve bool
+		want     string
+	}{
+		{false, "1,9"},
+		{true, "3,9"},
+	} {
+		forced := channels.NewState("forced", "Forced", "9")
+		forced.SetOnline("b9", "G", 10)
+		forced.MarkStreakClaimed()
+		drop := channels.NewState("drop", "Drop", "1")
+		drop.SetOnline("b1", "G", 10)
+		drop.MarkStreakClaimed()
+		drop.SetDropInfo("Drop", 10, 60)
+		p1 := channels.NewState("always", "Always", "2")
+		p1.SetPriority(1)
+		p1.SetOnline("b2", "G", 10)
+		p1.MarkStreakClaimed()
+		fresh := channels.NewState("fresh", "Fresh", "3")
+		fresh.SetPriority(2)
+		fresh.SetOnline("b3", "G", 10) // unclaimed streak, just went live
+
+		s := newRotationService(&config.Config{StreakPreservation: tc.preserve}, forced, drop, p1, fresh)
+		s.forcedChannelID, s.forcedUntil = "9", time.Now().Add(time.Minute)
+		if got := plannedIDs(s.planRotation(time.Now())); got != tc.want {
+			t.Errorf("streak_preservation=%v: watch set %s, want %s", tc.preserve, got, tc.want)
+		}
+
+		// Without the forced pick the candidate outranks P1 either way.
+		s.forcedChannelID = ""
+		drop.ClearDropInfo()
+		drop.SetPriority(2)
+		if got := plannedIDs(s.planRotation(time.Now())); got != "2,3" {
+			t.Errorf("streak_preservation=%v: watch set %s, want 2,3", tc.preserve, got)
+		}
+	}
+}
+
+// TestPlanRotation_GoalBucketGetsLeftoverSlotsOnly: channels past their
+// points goal only fill what P2 leaves free — the P2 cursor's picks
+// count against the slot limit.
+func TestPlanRotation_GoalBucketGetsLeftoverSlotsOnly(t *testing.T) {
+	cfg := &config.Config{ChannelConfigs: []config.ChannelEntry{
+		{Login: "saver1", Priority: 2, Goal: 100},
+		{Login: "saver2", Priority: 2, Goal: 100},
+	}}
+	var states []*channels.State
+	for i, login := range []string{"normal", "saver1", "saver2"} {
+		st := channels.NewState(login, login, string(rune('1'+i)))
+		st.SetPriority(2)
+		st.SetOnline("b", "G", 10)
+		st.MarkStreakClaimed()
+		st.SetBalance(500)
+		states = append(states, st)
+	}
+	s := newRotationService(cfg, states...)
+
+	plan := s.planRotation(time.Now())
+	if len(plan.desired) != maxSpadeSlots || plan.desired["1"] == nil {
+		t.Fatalf("watch set %s, want the P2 channel plus one goal channel", plannedIDs(plan))
+	}
+
+	// With every channel at its goal, the goal bucket fills both slots
+	// in channel ID order.
+	cfg.ChannelConfigs = append(cfg.ChannelConfigs, config.ChannelEntry{Login: "normal", Priority: 2, Goal: 100})
+	if got := plannedIDs(s.planRotation(time.Now())); got != "1,2" {
+		t.Fatalf("watch set %s, want 1,2", got)
+	}
+}
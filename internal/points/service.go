@@ -2,11 +2,13 @@ package points
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/miwi/twitchpoint/internal/channels"
 	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/history"
 	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
@@ -26,25 +28,58 @@ import (
 // still lives in farmer.go. Subsequent batches move them in.
 type Service struct {
 	// Dependencies (set at construction).
-	cfg       *config.Config
-	gql       *twitch.GQLClient
-	spade     *twitch.SpadeTracker
-	prober    *twitch.StreamProber
-	irc       *twitch.IRCClient
-	channels  *channels.Registry
-	drops     *drops.Service
-	dropWatch *drops.Watcher
-	log       func(string, ...interface{}) // visible UI + file
-	debugLog  func(string, ...interface{}) // file-only by default (-tags=debug surfaces in UI)
+	cfg        *config.Config
+	gql        *twitch.GQLClient
+	spade      *twitch.SpadeTracker
+	prober     *twitch.StreamProber
+	irc        atomic.Pointer[twitch.IRCClient] // nil while IRC is off; swapped by SetIRC
+	channels   *channels.Registry
+	drops      *drops.Service
+	dropWatch  *drops.Watcher
+	history    *history.Store               // may be nil
+	log        func(string, ...interface{}) // visible UI + file
+	warn       func(string, ...interface{}) // like log, at warn level
+	debugLog   func(string, ...interface{}) // file-only by default (-tags=debug surfaces in UI)
+	isPaused   func() bool                  // Farmer.Pause in effect; may be nil
+	loginGone  func(*channels.State)        // ServiceDeps.LoginGone; may be nil
+	onActivity func(Activity)               // ServiceDeps.OnActivity; may be nil
 
 	// State (protected by mu).
 	mu                sync.RWMutex
 	seenClaims        map[string]time.Time // claimID -> when we attempted (dedup)
 	seenRaids         map[string]time.Time // raidID -> when we attempted (dedup)
+	seenMoments       map[string]time.Time // momentID -> when we attempted (dedup)
 	totalPointsEarned int
+	totalPointsSpent  int
+	earnRate          channels.RateWindow // all channels, tracked or not
 	totalClaimsMade   int
-	nameCache         map[string]string // channelID -> displayName, for untracked channels
-	rotationIndex     int               // priority-2 channel cursor for the 5-min rotation
+	totalMoments      int
+	nameCache         map[string]string  // channelID -> displayName, for untracked channels
+	rotationIndex     int                // priority-2 channel cursor for the 5-min rotation
+	p2Credit          map[string]float64 // channelID -> P2 slot credit with weight_sub_multipliers (weightedPicks)
+	forcedChannelID   string             // ForceWatch target, outranks P0 until forcedUntil
+	forcedUntil       time.Time
+	redeemBusy        map[string]bool      // channelID -> redemption check in flight
+	redeemNext        map[string]time.Time // channelID -> earliest next redemption check
+	redemptions       []Redemption         // bounded by maxRedemptionLog
+	activity          []Activity           // bounded by maxActivityLog
+	nextRotation      time.Time            // when RotationLoop fires next; zero before it starts
+	goalsReached      map[string]bool      // channelID -> balance at/over its goal (announced)
+	capsReached       map[string]bool      // channelID -> balance at/over max_points (announced)
+
+	// hypeTimers holds each running Hype Train's expiry rotation by
+	// channel ID (setHypeTimer), guarded by mu.
+	hypeTimers map[string]*time.Timer
+
+	// rotateNow wakes RotationLoop early (RotateNow). 1-slot buffer:
+	// extra requests while one is queued coalesce.
+	rotateNow chan struct{}
+
+	// Claim goroutines in flight, for WaitClaims. claimsClosed stops
+	// new ones once WaitClaims has begun.
+	claimsMu     sync.Mutex
+	claimsClosed bool
+	claims       sync.WaitGroup
 }
 
 // ServiceDeps bundles the external dependencies NewService needs. Mirrors
@@ -59,27 +94,58 @@ type ServiceDeps struct {
 	Channels  *channels.Registry
 	Drops     *drops.Service
 	DropWatch *drops.Watcher
+	History   *history.Store               // may be nil if history.db couldn't be opened
 	Log       func(string, ...interface{}) // visible UI + file
+	Warn      func(string, ...interface{}) // failures; Log is used if nil
 	DebugLog  func(string, ...interface{}) // file-only by default
+	Paused    func() bool                  // whole farmer paused (no heartbeats, no claims); may be nil
+	LoginGone func(*channels.State)        // a tracked channel's login stopped resolving; may be nil
+	// OnActivity is told about every activity feed entry; may be nil.
+	OnActivity func(Activity)
 }
 
 // NewService constructs a Service with empty dedup/stat maps.
 func NewService(deps ServiceDeps) *Service {
-	return &Service{
-		cfg:        deps.Cfg,
-		gql:        deps.GQL,
-		spade:      deps.Spade,
-		prober:     deps.Prober,
-		irc:        deps.IRC,
-		channels:   deps.Channels,
-		drops:      deps.Drops,
-		dropWatch:  deps.DropWatch,
-		log:        deps.Log,
-		debugLog:   deps.DebugLog,
-		seenClaims: make(map[string]time.Time),
-		seenRaids:  make(map[string]time.Time),
-		nameCache:  make(map[string]string),
+	warn := deps.Warn
+	if warn == nil {
+		warn = deps.Log
 	}
+	s := &Service{
+		cfg:          deps.Cfg,
+		gql:          deps.GQL,
+		spade:        deps.Spade,
+		prober:       deps.Prober,
+		channels:     deps.Channels,
+		drops:        deps.Drops,
+		dropWatch:    deps.DropWatch,
+		history:      deps.History,
+		log:          deps.Log,
+		warn:         warn,
+		debugLog:     deps.DebugLog,
+		isPaused:     deps.Paused,
+		loginGone:    deps.LoginGone,
+		onActivity:   deps.OnActivity,
+		seenClaims:   make(map[string]time.Time),
+		seenRaids:    make(map[string]time.Time),
+		seenMoments:  make(map[string]time.Time),
+		nameCache:    make(map[string]string),
+		earnRate:     channels.NewRateWindow(time.Now()),
+		redeemBusy:   make(map[string]bool),
+		redeemNext:   make(map[string]time.Time),
+		goalsReached: make(map[string]bool),
+		capsReached:  make(map[string]bool),
+		hypeTimers:   make(map[string]*time.Timer),
+		rotateNow:    make(chan struct{}, 1),
+	}
+	s.irc.Store(deps.IRC)
+	return s
+}
+
+// SetIRC swaps the IRC client used for viewer presence (nil turns
+// presence off) when IRC is switched at runtime. The caller connects
+// the new client; its ready hook should be SyncIRC.
+func (s *Service) SetIRC(c *twitch.IRCClient) {
+	s.irc.Store(c)
 }
 
 // TotalPointsEarned returns the running sum of points credited via
@@ -90,6 +156,22 @@ func (s *Service) TotalPointsEarned() int {
 	return s.totalPointsEarned
 }
 
+// PointsPerHour returns the global earn rate over the last
+// channels.RateWindowSpan.
+func (s *Service) PointsPerHour() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.earnRate.PerHour(time.Now())
+}
+
+// TotalPointsSpent returns the running sum of balance drops the bot
+// didn't cause (manual redemptions, predictions) since farmer start.
+func (s *Service) TotalPointsSpent() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.totalPointsSpent
+}
+
 // TotalClaimsMade returns the running count of bonus-claims successfully
 // completed via ClaimCommunityPoints.
 func (s *Service) TotalClaimsMade() int {
@@ -97,3 +179,11 @@ func (s *Service) TotalClaimsMade() int {
 	defer s.mu.RUnlock()
 	return s.totalClaimsMade
 }
+
+// TotalMomentsClaimed returns the running count of Moments successfully
+// claimed via ClaimMoment.
+func (s *Service) TotalMomentsClaimed() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.totalMoments
+}
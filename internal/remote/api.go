@@ -0,0 +1,84 @@
+package remote
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// API sends single requests to an instance's web API. Client builds on
+// it for the attached TUI; the CLI's one-shot commands ("twitchpoint
+// status", "channels list", ...) use it on its own.
+type API struct {
+	base  string // scheme://host:port, no trailing slash
+	token string // web_token of the remote instance, sent as a Bearer header
+	http  *http.Client
+}
+
+// NewAPI returns an API for the instance at baseURL ("nas:8080" or
+// "http://nas:8080"). Nothing is sent yet.
+func NewAPI(baseURL, token string) (*API, error) {
+	base := strings.TrimRight(strings.TrimSpace(baseURL), "/")
+	if !strings.Contains(base, "://") {
+		base = "http://" + base
+	}
+	if _, err := url.Parse(base); err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", baseURL, err)
+	}
+	return &API{
+		base:  base,
+		token: token,
+		http:  &http.Client{Timeout: requestTimeout},
+	}, nil
+}
+
+// Base returns the instance's URL (scheme://host:port).
+func (a *API) Base() string {
+	return a.base
+}
+
+// Do sends a JSON request and decodes the JSON reply into out (if
+// non-nil). Non-2xx replies become the server's error message.
+func (a *API) Do(method, path string, body, out interface{}) error {
+	var rd io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, a.base+path, rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if a.token != "" {
+		req.Header.Set("Authorization", "Bearer "+a.token)
+	}
+	resp, err := a.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		if json.NewDecoder(resp.Body).Decode(&e) == nil && e.Error != "" {
+			return fmt.Errorf("%s", e.Error)
+		}
+		return fmt.Errorf("%s %s: %s", method, path, resp.Status)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
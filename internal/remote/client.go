@@ -0,0 +1,432 @@
+// Package remote is the thin client behind "twitchpoint attach <url>":
+// it drives the terminal UI from the web API of a headless instance
+// running elsewhere (a NAS, a server) instead of an in-process farmer.
+package remote
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
+	"github.com/miwi/twitchpoint/internal/ui"
+	"github.com/miwi/twitchpoint/internal/web"
+)
+
+var _ ui.Backend = (*Client)(nil)
+
+const (
+	// pollInterval matches the TUI's one-second redraw tick.
+	pollInterval = time.Second
+	// requestTimeout bounds every API call, polls and actions alike.
+	requestTimeout = 10 * time.Second
+	// maxLogEntries mirrors the farmer's in-memory log buffer.
+	maxLogEntries = 500
+	// reasonsWindow is how long after the last GetReasonSummary the
+	// polls keep asking for the reason breakdown: the TUI calls it on
+	// every redraw while the Stats tab shows it.
+	reasonsWindow = 3 * pollInterval
+)
+
+// Client implements ui.Backend over the web API. A background loop
+// polls /api/tui every second; the getters answer from that snapshot
+// and actions are sent straight to the matching endpoint.
+type Client struct {
+	*API
+
+	mu      sync.RWMutex
+	state   web.TUIState
+	logs    []farmer.LogEntry // accumulated across polls
+	since   time.Time         // newest remote log line held (remote clock)
+	atSince int               // lines held that were logged at exactly since
+	down    bool              // last poll failed; reported once in the log
+	// reasonsAt is the last GetReasonSummary call (see reasonsWindow).
+	reasonsAt time.Time
+
+	kick     chan struct{} // poll right away (after an action)
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// Dial connects to the instance at baseURL ("nas:8080" or
+// "http://nas:8080"), fetches the first snapshot and starts polling.
+func Dial(baseURL, token string) (*Client, error) {
+	api, err := NewAPI(baseURL, token)
+	if err != nil {
+		return nil, err
+	}
+	c := &Client{
+		API:  api,
+		kick: make(chan struct{}, 1),
+		stop: make(chan struct{}),
+	}
+	if err := c.poll(); err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", c.base, err)
+	}
+	c.addLog("[Remote] Attached to %s", c.base)
+	go c.pollLoop()
+	return c, nil
+}
+
+// Stop stops polling. The remote farmer keeps running.
+func (c *Client) Stop() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *Client) pollLoop() {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		case <-c.kick:
+		}
+		err := c.poll()
+
+		c.mu.Lock()
+		wasDown := c.down
+		c.down = err != nil
+		c.mu.Unlock()
+		switch {
+		case err != nil && !wasDown:
+			c.addLog("[Remote] Lost connection to %s: %v", c.base, err)
+		case err == nil && wasDown:
+			c.addLog("[Remote] Reconnected to %s", c.base)
+		}
+	}
+}
+
+// poll fetches a snapshot, asking only for log lines from the newest
+// one already held on, and for the reason breakdown only while the TUI
+// shows it.
+func (c *Client) poll() error {
+	q := url.Values{}
+	c.mu.RLock()
+	if !c.since.IsZero() {
+		q.Set("logs_after", c.since.Format(time.RFC3339Nano))
+	}
+	if time.Since(c.reasonsAt) < reasonsWindow {
+		q.Set("reasons", "1")
+	}
+	c.mu.RUnlock()
+	path := "/api/tui"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+
+	var st web.TUIState
+	if err := c.Do(http.MethodGet, path, nil, &st); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// logs_after is inclusive, so the reply starts with the lines at
+	// since that are already held.
+	fresh := st.Logs
+	for skip := c.atSince; skip > 0 && len(fresh) > 0 && fresh[0].Time.Equal(c.since); skip-- {
+		fresh = fresh[1:]
+	}
+	for _, e := range fresh {
+		if e.Time.Equal(c.since) {
+			c.atSince++
+		} else {
+			c.since, c.atSince = e.Time, 1
+		}
+	}
+	c.logs = append(c.logs, fresh...)
+	if len(c.logs) > maxLogEntries {
+		c.logs = c.logs[len(c.logs)-maxLogEntries:]
+	}
+	st.Logs = nil
+	if st.Reasons == nil {
+		st.Reasons = c.state.Reasons // the last one, for when the tab opens again
+	}
+	c.state = st
+	return nil
+}
+
+// refresh schedules an immediate poll so an action shows up without
+// waiting for the next tick.
+func (c *Client) refresh() {
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+// addLog appends a client-side line to the event log.
+func (c *Client) addLog(format string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.logs = append(c.logs, farmer.LogEntry{Time: time.Now(), Message: fmt.Sprintf(format, args...)})
+}
+
+// action sends a state-changing request and refreshes the snapshot.
+func (c *Client) action(method, path string, body interface{}) error {
+	err := c.Do(method, path, body, nil)
+	c.refresh()
+	return err
+}
+
+func channelPath(login, suffix string) string {
+	return "/api/channels/" + url.PathEscape(login) + suffix
+}
+
+// GetUser implements ui.Backend.
+func (c *Client) GetUser() *twitch.UserInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.User
+}
+
+// GetStats implements ui.Backend.
+func (c *Client) GetStats() farmer.Stats {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Stats
+}
+
+// GetChannels implements ui.Backend.
+func (c *Client) GetChannels() []channels.Snapshot {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Channels
+}
+
+// GetActiveDrops implements ui.Backend.
+func (c *Client) GetActiveDrops() []drops.ActiveDrop {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Drops
+}
+
+// GetLogs implements ui.Backend.
+func (c *Client) GetLogs() []farmer.LogEntry {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	logs := make([]farmer.LogEntry, len(c.logs))
+	copy(logs, c.logs)
+	return logs
+}
+
+// GetUpdateInfo implements ui.Backend.
+func (c *Client) GetUpdateInfo() farmer.UpdateInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Update
+}
+
+// GetRedemptions implements ui.Backend.
+func (c *Client) GetRedemptions() []points.Redemption {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Redemptions
+}
+
+// GetDailySummary implements ui.Backend.
+func (c *Client) GetDailySummary() farmer.DailySummary {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Daily
+}
+
+// GetComparison implements ui.Backend.
+func (c *Client) GetComparison() farmer.Comparison {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.Comparison
+}
+
+// GetReasonSummary implements ui.Backend.
+// The first call after a while polls right away for a fresh one.
+func (c *Client) GetReasonSummary() farmer.ReasonSummary {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if time.Since(c.reasonsAt) >= reasonsWindow {
+		c.refresh()
+	}
+	c.reasonsAt = time.Now()
+	if c.state.Reasons == nil {
+		return farmer.ReasonSummary{}
+	}
+	return *c.state.Reasons
+}
+
+// CapacityWarning implements ui.Backend.
+func (c *Client) CapacityWarning() string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.state.CapacityWarning
+}
+
+// GetGamesToWatch implements ui.Backend.
+func (c *Client) GetGamesToWatch() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.state.Games...)
+}
+
+// GetBlacklist implements ui.Backend.
+func (c *Client) GetBlacklist() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.state.Blacklist...)
+}
+
+// GetBoolSetting implements ui.Backend.
+func (c *Client) GetBoolSetting(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	s := c.state.Settings
+	switch key {
+	case "drops_enabled":
+		return s.DropsEnabled
+	case "auto_claim":
+		return s.AutoClaim
+	case "irc_enabled":
+		return s.IrcEnabled
+	case "web_enabled":
+		return s.WebEnabled
+	}
+	return false
+}
+
+// SearchGameCategories implements ui.Backend.
+func (c *Client) SearchGameCategories(query string, limit int) ([]string, error) {
+	var resp struct {
+		Games []string `json:"games"`
+	}
+	path := "/api/games/search?q=" + url.QueryEscape(query) + "&limit=" + strconv.Itoa(limit)
+	if err := c.Do(http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Games, nil
+}
+
+// AddChannelLive implements ui.Backend.
+func (c *Client) AddChannelLive(login string) error {
+	return c.action(http.MethodPost, "/api/channels", map[string]string{"login": login})
+}
+
+// RemoveChannelLive implements ui.Backend.
+func (c *Client) RemoveChannelLive(login string) error {
+	return c.action(http.MethodDelete, channelPath(login, ""), nil)
+}
+
+// PromoteChannel implements ui.Backend.
+func (c *Client) PromoteChannel(login string) error {
+	return c.action(http.MethodPost, channelPath(login, "/promote"), nil)
+}
+
+// SetPriorityLive implements ui.Backend.
+func (c *Client) SetPriorityLive(login string, priority int) error {
+	return c.action(http.MethodPut, channelPath(login, "/priority"), map[string]int{"priority": priority})
+}
+
+// SetPausedLive implements ui.Backend.
+func (c *Client) SetPausedLive(login string, paused bool) error {
+	return c.action(http.MethodPut, channelPath(login, "/paused"), map[string]bool{"paused": paused})
+}
+
+// ForceWatchLive implements ui.Backend.
+func (c *Client) ForceWatchLive(login string) error {
+	return c.action(http.MethodPost, channelPath(login, "/watch"), nil)
+}
+
+// RefreshChannelLive implements ui.Backend.
+func (c *Client) RefreshChannelLive(login string) error {
+	return c.action(http.MethodPost, channelPath(login, "/refresh"), nil)
+}
+
+// GetChannelSettings implements ui.Backend.
+func (c *Client) GetChannelSettings(login string) (farmer.ChannelSettings, error) {
+	var s farmer.ChannelSettings
+	err := c.Do(http.MethodGet, channelPath(login, "/settings"), nil, &s)
+	return s, err
+}
+
+// SetChannelSettingsLive implements ui.Backend.
+func (c *Client) SetChannelSettingsLive(login string, s farmer.ChannelSettings) error {
+	return c.action(http.MethodPut, channelPath(login, "/settings"), s)
+}
+
+// SetCampaignEnabled implements ui.Backend.
+func (c *Client) SetCampaignEnabled(campaignID string, enabled bool) error {
+	return c.action(http.MethodPut, "/api/drops/"+url.PathEscape(campaignID)+"/toggle", map[string]bool{"enabled": enabled})
+}
+
+// RotateNow implements ui.Backend.
+func (c *Client) RotateNow() {
+	if err := c.action(http.MethodPost, "/api/rotate", nil); err != nil {
+		c.addLog("[Remote] Rotate failed: %v", err)
+	}
+}
+
+// Pause implements ui.Backend.
+func (c *Client) Pause() {
+	if err := c.action(http.MethodPost, "/api/pause", nil); err != nil {
+		c.addLog("[Remote] Pause failed: %v", err)
+	}
+}
+
+// Resume implements ui.Backend.
+func (c *Client) Resume() {
+	if err := c.action(http.MethodPost, "/api/resume", nil); err != nil {
+		c.addLog("[Remote] Resume failed: %v", err)
+	}
+}
+
+// CheckDropsNow implements ui.Backend.
+func (c *Client) CheckDropsNow() error {
+	return c.action(http.MethodPost, "/api/drops/check", nil)
+}
+
+// SetGamesToWatch implements ui.Backend.
+func (c *Client) SetGamesToWatch(games []string) error {
+	var resp struct {
+		Games []string `json:"games"`
+	}
+	if err := c.Do(http.MethodPut, "/api/wanted_games", map[string][]string{"games": games}, &resp); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.state.Games = resp.Games
+	c.mu.Unlock()
+	return nil
+}
+
+// SetBlacklist implements ui.Backend.
+func (c *Client) SetBlacklist(entries []string) error {
+	var resp struct {
+		Blacklist []string `json:"blacklist"`
+	}
+	if err := c.Do(http.MethodPut, "/api/blacklist", map[string][]string{"blacklist": entries}, &resp); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.state.Blacklist = resp.Blacklist
+	c.mu.Unlock()
+	return nil
+}
+
+// SetBoolSetting implements ui.Backend.
+func (c *Client) SetBoolSetting(key string, v bool) error {
+	var resp web.SettingsResponse
+	if err := c.Do(http.MethodPut, "/api/settings", map[string]bool{key: v}, &resp); err != nil {
+		return err
+	}
+	c.mu.Lock()
+	c.state.Settings = resp
+	c.mu.Unlock()
+	return nil
+}
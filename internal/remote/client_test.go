@@ -0,0 +1,128 @@
+package remote
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/web"
+)
+
+// TestClientPollsIncrementalLogs checks that only log lines from the
+// last remote one on are requested, that a line logged in the same
+// instant as it still arrives exactly once, and that API errors come
+// back as the server's message.
+func TestClientPollsIncrementalLogs(t *testing.T) {
+	t0 := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	all := []farmer.LogEntry{
+		{Time: t0, Message: "one"},
+		{Time: t0.Add(time.Second), Message: "two"},
+	}
+	var afters []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/api/tui":
+			after := r.URL.Query().Get("logs_after")
+			afters = append(afters, after)
+			var st web.TUIState
+			since, _ := time.Parse(time.RFC3339Nano, after)
+			for _, e := range all {
+				if !e.Time.Before(since) {
+					st.Logs = append(st.Logs, e)
+				}
+			}
+			st.Games = []string{"Rust"}
+			json.NewEncoder(w).Encode(st)
+		case "/api/rotate":
+			w.WriteHeader(http.StatusConflict)
+			json.NewEncoder(w).Encode(map[string]string{"error": "busy"})
+		}
+	}))
+	defer srv.Close()
+
+	c, err := Dial(srv.URL+"/", "secret")
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	c.Stop()
+
+	all = append(all,
+		farmer.LogEntry{Time: t0.Add(time.Second), Message: "two too"},
+		farmer.LogEntry{Time: t0.Add(2 * time.Second), Message: "three"})
+	if err := c.poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	if err := c.poll(); err != nil {
+		t.Fatalf("poll: %v", err)
+	}
+	// one, two, the client's "Attached" line, two too, three.
+	var got []string
+	for _, e := range c.GetLogs() {
+		got = append(got, e.Message)
+	}
+	if len(got) != 5 || got[0] != "one" || got[1] != "two" || got[3] != "two too" || got[4] != "three" {
+		t.Fatalf("logs = %q", got)
+	}
+	if len(afters) != 3 || afters[0] != "" || afters[1] != all[1].Time.Format(time.RFC3339Nano) || afters[2] != all[3].Time.Format(time.RFC3339Nano) {
+		t.Fatalf("logs_after params = %q", afters)
+	}
+	if g := c.GetGamesToWatch(); len(g) != 1 || g[0] != "Rust" {
+		t.Fatalf("games = %v", g)
+	}
+
+	if err := c.Do(http.MethodPost, "/api/rotate", nil, nil); err == nil || err.Error() != "busy" {
+		t.Fatalf("error reply = %v, want busy", err)
+	}
+}
+
+// TestClientAsksForReasonsWhileShown: the reason breakdown is only
+// polled for while the TUI keeps asking for it.
+func TestClientAsksForReasonsWhileShown(t *testing.T) {
+	var asked []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var st web.TUIState
+		asked = append(asked, r.URL.Query().Get("reasons"))
+		if r.URL.Query().Get("reasons") == "1" {
+			st.Reasons = &farmer.ReasonSummary{Error: "sentinel"}
+		}
+		json.NewEncoder(w).Encode(st)
+	}))
+	defer srv.Close()
+
+	// No pollLoop: the kick GetReasonSummary sends must not add a poll.
+	api, err := NewAPI(srv.URL, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Client{API: api, kick: make(chan struct{}, 1), stop: make(chan struct{})}
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.GetReasonSummary(); got.Error != "" {
+		t.Fatalf("reasons before any poll asked = %+v", got)
+	}
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.GetReasonSummary(); got.Error != "sentinel" {
+		t.Fatalf("reasons = %+v, want the polled ones", got)
+	}
+
+	c.mu.Lock()
+	c.reasonsAt = time.Now().Add(-reasonsWindow)
+	c.mu.Unlock()
+	if err := c.poll(); err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"", "1", ""}; len(asked) != 3 || asked[0] != want[0] || asked[1] != want[1] || asked[2] != want[2] {
+		t.Errorf("reasons params = %q, want %q", asked, want)
+	}
+}
@@ -0,0 +1,153 @@
+// Package systemd talks to systemd when the farmer runs as a Type=notify
+// service: READY=1 once the farmer has started, STATUS= lines, and
+// WATCHDOG=1 keepalives only while PubSub and the Spade heartbeats work,
+// so systemd restarts a farmer that has quietly stopped earning.
+// Outside systemd ($NOTIFY_SOCKET unset) it does nothing.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Farmer is the part of *farmer.Farmer the watchdog uses.
+type Farmer interface {
+	Alive() (bool, string)
+	Done() <-chan struct{}
+	Logf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+// Notify sends state ("READY=1", "STATUS=...", newline-separated) to
+// the socket in $NOTIFY_SOCKET. It reports false without an error when
+// the variable isn't set, i.e. not running under systemd.
+func Notify(state string) (bool, error) {
+	name := os.Getenv("NOTIFY_SOCKET")
+	if name == "" {
+		return false, nil
+	}
+	if name[0] == '@' { // abstract namespace
+		name = "\x00" + name[1:]
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: name, Net: "unixgram"})
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// WatchdogInterval returns the WatchdogSec= systemd set for this process
+// ($WATCHDOG_USEC, when $WATCHDOG_PID is unset or ours), or 0 when the
+// watchdog is off.
+func WatchdogInterval() time.Duration {
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0
+	}
+	usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64)
+	if err != nil || usec <= 0 {
+		return 0
+	}
+	return time.Duration(usec) * time.Microsecond
+}
+
+// Start tells systemd the farmer is ready and, when the unit has
+// WatchdogSec=, sends keepalives at half that interval while f is alive
+// until it stops. A farmer that isn't alive gets no keepalives, its
+// STATUS= says why, and systemd restarts it once the interval runs out.
+func Start(f Farmer) {
+	ok, err := Notify("READY=1\nSTATUS=Farming")
+	if err != nil {
+		f.Warnf("[systemd] Warning: could not notify systemd: %v", err)
+		return
+	}
+	if !ok {
+		return
+	}
+	interval := WatchdogInterval()
+	if interval <= 0 {
+		return
+	}
+	go watchdog(f, interval/2)
+}
+
+// watchdog sends WATCHDOG=1 every tick while f is alive, until Done.
+func watchdog(f Farmer, tick time.Duration) {
+	t := time.NewTicker(tick)
+	defer t.Stop()
+	failing := ""
+	for {
+		select {
+		case <-f.Done():
+			_, _ = Notify("STOPPING=1")
+			return
+		case <-t.C:
+		}
+		alive, reason := f.Alive()
+		if !alive {
+			if reason != failing {
+				f.Warnf("[systemd] Warning: withholding watchdog keepalive: %s", reason)
+				_, _ = Notify("STATUS=" + reason)
+				failing = reason
+			}
+			continue
+		}
+		state := "WATCHDOG=1"
+		if failing != "" {
+			state += "\nSTATUS=Farming"
+			failing = ""
+		}
+		if _, err := Notify(state); err != nil {
+			f.Warnf("[systemd] Warning: could not send watchdog keepalive: %v", err)
+		}
+	}
+}
+
+// Unit returns a user service file that runs exe headless with config
+// in dir, as Type=notify with a watchdog.
+func Unit(exe, config, dir string) string {
+	return fmt.Sprintf(`[Unit]
+Description=TwitchPoint Farmer
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=notify
+NotifyAccess=main
+WorkingDirectory=%s
+ExecStart=%s --headless --config %s
+Restart=on-failure
+RestartSec=30
+# Keepalives stop while PubSub or the Spade heartbeats are failing.
+WatchdogSec=5min
+# The first start waits for a login through the web UI.
+TimeoutStartSec=infinity
+# Claims in flight get 10s to finish on shutdown.
+TimeoutStopSec=30
+
+[Install]
+WantedBy=default.target
+`, escape(dir), quote(exe), quote(config))
+}
+
+// quote escapes a path for an ExecStart= command line: % doubled (it
+// starts a specifier), C-style quotes around one with blanks or quotes.
+func quote(s string) string {
+	s = escape(s)
+	if strings.ContainsAny(s, " \t\"\\") {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// escape doubles the % in a unit file setting.
+func escape(s string) string {
+	return strings.ReplaceAll(s, "%", "%%")
+}
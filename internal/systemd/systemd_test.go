@@ -0,0 +1,121 @@
+package systemd
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type fakeFarmer struct {
+	alive atomic.Bool
+	done  chan struct{}
+}
+
+func (f *fakeFarmer) Alive() (bool, string) {
+	if f.alive.Load() {
+		return true, ""
+	}
+	return false, "PubSub connections failing"
+}
+func (f *fakeFarmer) Done() <-chan struct{}        { return f.done }
+func (f *fakeFarmer) Logf(string, ...interface{})  {}
+func (f *fakeFarmer) Warnf(string, ...interface{}) {}
+
+// listen points $NOTIFY_SOCKET at a new datagram socket and returns it.
+func listen(t *testing.T) *net.UnixConn {
+	path := filepath.Join(t.TempDir(), "notify")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Skipf("unixgram sockets unavailable: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	t.Setenv("NOTIFY_SOCKET", path)
+	return conn
+}
+
+func read(t *testing.T, conn *net.UnixConn) string {
+	t.Helper()
+	buf := make([]byte, 256)
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return string(buf[:n])
+}
+
+func TestNotify(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	if ok, err := Notify("READY=1"); ok || err != nil {
+		t.Errorf("without NOTIFY_SOCKET: %v, %v", ok, err)
+	}
+
+	conn := listen(t)
+	if ok, err := Notify("READY=1"); !ok || err != nil {
+		t.Fatalf("Notify = %v, %v", ok, err)
+	}
+	if got := read(t, conn); got != "READY=1" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestWatchdogInterval(t *testing.T) {
+	t.Setenv("WATCHDOG_USEC", "300000000")
+	t.Setenv("WATCHDOG_PID", "")
+	if got := WatchdogInterval(); got != 5*time.Minute {
+		t.Errorf("interval = %v", got)
+	}
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(os.Getpid()+1))
+	if got := WatchdogInterval(); got != 0 {
+		t.Errorf("another process's watchdog: %v", got)
+	}
+	t.Setenv("WATCHDOG_PID", "")
+	t.Setenv("WATCHDOG_USEC", "")
+	if got := WatchdogInterval(); got != 0 {
+		t.Errorf("no watchdog: %v", got)
+	}
+}
+
+// TestWatchdog checks keepalives stop while the farmer isn't alive and
+// resume with a STATUS reset once it is.
+func TestWatchdog(t *testing.T) {
+	conn := listen(t)
+	f := &fakeFarmer{done: make(chan struct{})}
+	go watchdog(f, 10*time.Millisecond)
+
+	if got := read(t, conn); got != "STATUS=PubSub connections failing" {
+		t.Errorf("unhealthy: got %q", got)
+	}
+	f.alive.Store(true)
+	if got := read(t, conn); got != "WATCHDOG=1\nSTATUS=Farming" {
+		t.Errorf("recovered: got %q", got)
+	}
+	if got := read(t, conn); got != "WATCHDOG=1" {
+		t.Errorf("keepalive: got %q", got)
+	}
+	close(f.done)
+	for got := read(t, conn); got != "STOPPING=1"; got = read(t, conn) {
+		if got != "WATCHDOG=1" {
+			t.Fatalf("stopping: got %q", got)
+		}
+	}
+}
+
+func TestUnit(t *testing.T) {
+	unit := Unit("/opt/twitch point/twitchpoint", "/home/me/config.json", "/home/me/100%")
+	for _, want := range []string{
+		"Type=notify\n",
+		"WorkingDirectory=/home/me/100%%\n",
+		`ExecStart="/opt/twitch point/twitchpoint" --headless --config /home/me/config.json` + "\n",
+		"WatchdogSec=",
+	} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("unit lacks %q:\n%s", want, unit)
+		}
+	}
+}
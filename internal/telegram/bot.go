@@ -0,0 +1,385 @@
+// Package telegram connects a running farmer to a Telegram bot: it
+// sends drop-claimed, go-live and auth notifications to one chat and
+// answers commands (/stats, /channels, /add, /pause, /resume) from that
+// chat.
+package telegram
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+const (
+	apiBase = "https://api.telegram.org"
+	// pollTimeout is the getUpdates long-poll window; requests get a
+	// little longer before the HTTP client gives up on them.
+	pollTimeout    = 30 * time.Second
+	requestTimeout = pollTimeout + 10*time.Second
+	// retryDelay is the pause after a failed getUpdates.
+	retryDelay = 10 * time.Second
+	// maxMessageLen is Telegram's limit on a message's text.
+	maxMessageLen = 4096
+)
+
+// Farmer is the part of *farmer.Farmer the bot uses.
+type Farmer interface {
+	GetStats() farmer.Stats
+	GetDailySummary() farmer.DailySummary
+	GetChannels() []channels.Snapshot
+	AddChannelLive(login string) error
+	SetPausedLive(login string, paused bool) error
+	Pause()
+	Resume()
+	Subscribe() (<-chan farmer.PushEvent, func())
+	NotificationsEnabled() bool
+	Done() <-chan struct{}
+	Logf(format string, args ...interface{})
+	Warnf(format string, args ...interface{})
+}
+
+var _ Farmer = (*farmer.Farmer)(nil)
+
+// Bot relays farmer events to a chat and farmer commands from it.
+type Bot struct {
+	cfg  config.Telegram
+	f    Farmer
+	base string // apiBase + "/bot" + token; the token never appears in logs
+	http *http.Client
+
+	offset int64 // next getUpdates offset (last update ID + 1)
+}
+
+// New creates a bot for cfg (see config.GetTelegram). Call Start to run it.
+func New(cfg config.Telegram, f Farmer) *Bot {
+	return &Bot{
+		cfg:  cfg,
+		f:    f,
+		base: apiBase + "/bot" + cfg.BotToken,
+		http: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+// Start runs the notification and command loops until the farmer stops.
+func (b *Bot) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-b.f.Done()
+		cancel()
+	}()
+	go b.pollLoop(ctx)
+	if b.cfg.ChatID == 0 {
+		b.f.Logf("[Telegram] No chat_id set — send the bot a message and copy the chat ID logged here")
+		return
+	}
+	go b.notifyLoop(ctx)
+	b.f.Logf("[Telegram] Bot started, reporting to chat %d", b.cfg.ChatID)
+}
+
+// notifyLoop forwards push events that map to a notification. Sends run
+// in their own goroutine so a slow API never backs up the subscription.
+func (b *Bot) notifyLoop(ctx context.Context) {
+	events, cancel := b.f.Subscribe()
+	defer cancel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			if text := b.notification(ev); text != "" {
+				go b.send(ctx, text)
+			}
+		}
+	}
+}
+
+// notification renders a push event as a message, or "" when the event
+// isn't one we notify about (or that kind, or all notifications, are
+// turned off).
+func (b *Bot) notification(ev farmer.PushEvent) string {
+	if !b.f.NotificationsEnabled() {
+		return ""
+	}
+	switch ev.Kind {
+	case farmer.PushKindDropClaimed:
+		d, ok := ev.Data.(drops.DropClaimed)
+		if !ok || !b.cfg.Notifies(config.TelegramNotifyDrops) {
+			return ""
+		}
+		if d.Campaign != "" {
+			return fmt.Sprintf("Drop claimed: %s (%s)", d.Name, d.Campaign)
+		}
+		return "Drop claimed: " + d.Name
+	case farmer.PushKindChannelLive:
+		s, ok := ev.Data.(farmer.StreamLive)
+		if !ok || !b.cfg.Notifies(config.TelegramNotifyLive) || !b.cfg.CoversLive(s.Login, s.Priority) {
+			return ""
+		}
+		text := s.DisplayName + " is live"
+		if s.GameName != "" {
+			text += ": " + s.GameName
+		}
+		return text + "\n" + s.URL
+	case farmer.PushKindAuthFailed:
+		a, ok := ev.Data.(farmer.AuthFailed)
+		if !ok || !b.cfg.Notifies(config.TelegramNotifyAuth) {
+			return ""
+		}
+		return a.Message
+	}
+	return ""
+}
+
+// pollLoop long-polls getUpdates and answers commands from the
+// configured chat. Messages from any other chat are ignored (and
+// logged, which is how users find their chat ID).
+func (b *Bot) pollLoop(ctx context.Context) {
+	down := false
+	for {
+		updates, err := b.getUpdates(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			if !down {
+				b.f.Warnf("[Telegram] getUpdates failed: %v", err)
+				down = true
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(retryDelay):
+			}
+			continue
+		}
+		if down {
+			b.f.Logf("[Telegram] Reconnected")
+			down = false
+		}
+		for _, u := range updates {
+			b.offset = u.UpdateID + 1
+			msg := u.Message
+			if msg == nil || msg.Text == "" {
+				continue
+			}
+			if b.cfg.ChatID == 0 || msg.Chat.ID != b.cfg.ChatID {
+				b.f.Logf("[Telegram] Ignored message from chat %d (not chat_id)", msg.Chat.ID)
+				continue
+			}
+			if reply := b.command(msg.Text); reply != "" {
+				b.send(ctx, reply)
+			}
+		}
+	}
+}
+
+// command runs one chat command and returns the reply. Plain text that
+// isn't a command gets no reply.
+func (b *Bot) command(text string) string {
+	fields := strings.Fields(text)
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return ""
+	}
+	// "/stats@MyBot" in group chats.
+	cmd, _, _ := strings.Cut(strings.ToLower(fields[0]), "@")
+	arg := ""
+	if len(fields) > 1 {
+		arg = strings.ToLower(strings.TrimPrefix(fields[1], "@"))
+	}
+
+	switch cmd {
+	case "/stats":
+		return b.stats()
+	case "/channels":
+		return b.channels()
+	case "/add":
+		if arg == "" {
+			return "Usage: /add <login, twitch.tv URL or channel ID>"
+		}
+		if err := b.f.AddChannelLive(arg); err != nil {
+			return "Could not add " + arg + ": " + err.Error()
+		}
+		return "Added " + arg
+	case "/pause", "/resume":
+		paused := cmd == "/pause"
+		if arg == "" {
+			// No login: the whole farmer.
+			if paused {
+				b.f.Pause()
+				return "Farming paused"
+			}
+			b.f.Resume()
+			return "Farming resumed"
+		}
+		if err := b.f.SetPausedLive(arg, paused); err != nil {
+			return err.Error()
+		}
+		if paused {
+			return "Paused " + arg
+		}
+		return "Resumed " + arg
+	case "/start", "/help":
+		return "Commands:\n" +
+			"/stats - session and today's totals\n" +
+			"/channels - channel list\n" +
+			"/add <login> - add a channel (or its URL or ID)\n" +
+			"/pause <login> - stop watching a channel\n" +
+			"/resume <login> - watch it again\n" +
+			"/pause, /resume - all farming"
+	}
+	return "Unknown command " + cmd + " (try /help)"
+}
+
+func (b *Bot) stats() string {
+	s := b.f.GetStats()
+	today := b.f.GetDailySummary()
+	return fmt.Sprintf("Points: +%d (%d/h), %d bonus claims\n"+
+		"Channels: %d watching, %d online, %d total\n"+
+		"Active drops: %d\n"+
+		"Today: +%d pts, %d claims, %d drop min\n"+
+		"Uptime: %s",
+		s.TotalPointsEarned, s.PointsPerHour, s.TotalClaimsMade,
+		s.ChannelsWatching, s.ChannelsOnline, s.ChannelsTotal,
+		s.ActiveDrops,
+		today.Points, today.Claims, today.DropMinutes,
+		s.Uptime.Truncate(time.Minute))
+}
+
+func (b *Bot) channels() string {
+	var sb strings.Builder
+	for _, ch := range b.f.GetChannels() {
+		status := "offline"
+		switch {
+		case ch.Paused:
+			status = "paused"
+		case ch.IsWatching:
+			status = "watching"
+		case ch.IsOnline:
+			status = "online"
+		}
+		line := fmt.Sprintf("%s - %s, %d pts", ch.DisplayName, status, ch.PointsBalance)
+		if ch.IsOnline && ch.GameName != "" {
+			line += ", " + ch.GameName
+		}
+		if ch.IsTemporary {
+			line += " (drops)"
+		}
+		if sb.Len()+len(line)+1 > maxMessageLen {
+			break
+		}
+		sb.WriteString(line + "\n")
+	}
+	if sb.Len() == 0 {
+		return "No channels"
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+type update struct {
+	UpdateID int64 `json:"update_id"`
+	Message  *struct {
+		Text string `json:"text"`
+		Chat struct {
+			ID int64 `json:"id"`
+		} `json:"chat"`
+	} `json:"message"`
+}
+
+func (b *Bot) getUpdates(ctx context.Context) ([]update, error) {
+	q := url.Values{
+		"offset":          {strconv.FormatInt(b.offset, 10)},
+		"timeout":         {strconv.Itoa(int(pollTimeout.Seconds()))},
+		"allowed_updates": {`["message"]`},
+	}
+	var updates []update
+	err := b.call(ctx, http.MethodGet, "getUpdates?"+q.Encode(), nil, &updates)
+	return updates, err
+}
+
+// truncate cuts text to at most n bytes without splitting a character.
+// Telegram counts UTF-16 code units, never more than there are bytes.
+func truncate(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
+
+// send posts text to the configured chat, logging failures.
+func (b *Bot) send(ctx context.Context, text string) {
+	text = truncate(text, maxMessageLen)
+	body := map[string]interface{}{
+		"chat_id":                  b.cfg.ChatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	}
+	if err := b.call(ctx, http.MethodPost, "sendMessage", body, nil); err != nil && ctx.Err() == nil {
+		b.f.Warnf("[Telegram] sendMessage failed: %v", err)
+	}
+}
+
+// call invokes a Bot API method and decodes its result into out (may
+// be nil). API refusals come back as their description.
+func (b *Bot) call(ctx context.Context, method, path string, body, out interface{}) error {
+	var rd io.Reader
+	if body != nil {
+		buf, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		rd = bytes.NewReader(buf)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, b.base+"/"+path, rd)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	resp, err := b.http.Do(req)
+	if err != nil {
+		// *url.Error carries the request URL, which holds the bot token.
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			return ue.Err
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	var reply struct {
+		OK          bool            `json:"ok"`
+		Description string          `json:"description"`
+		Result      json.RawMessage `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		return fmt.Errorf("HTTP %d: %w", resp.StatusCode, err)
+	}
+	if !reply.OK {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, reply.Description)
+	}
+	if out == nil {
+		return nil
+	}
+	return json.Unmarshal(reply.Result, out)
+}
@@ -0,0 +1,184 @@
+package telegram
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+type fakeFarmer struct {
+	all    bool // Pause / Resume
+	paused map[string]bool
+	added  []string
+	logs   []string
+	muted  bool // NotificationsEnabled = false
+}
+
+func (f *fakeFarmer) GetStats() farmer.Stats               { return farmer.Stats{TotalPointsEarned: 1200} }
+func (f *fakeFarmer) GetDailySummary() farmer.DailySummary { return farmer.DailySummary{Points: 300} }
+func (f *fakeFarmer) GetChannels() []channels.Snapshot {
+	return []channels.Snapshot{{DisplayName: "Alpha", IsOnline: true, IsWatching: true, GameName: "Rust", PointsBalance: 50}}
+}
+func (f *fakeFarmer) AddChannelLive(login string) error {
+	f.added = append(f.added, login)
+	return nil
+}
+func (f *fakeFarmer) SetPausedLive(login string, paused bool) error {
+	if login != "alpha" {
+		return fmt.Errorf("channel %s not found", login)
+	}
+	f.paused[login] = paused
+	return nil
+}
+func (f *fakeFarmer) Pause()                                       { f.all = true }
+func (f *fakeFarmer) Resume()                                      { f.all = false }
+func (f *fakeFarmer) Subscribe() (<-chan farmer.PushEvent, func()) { return nil, func() {} }
+func (f *fakeFarmer) NotificationsEnabled() bool                   { return !f.muted }
+func (f *fakeFarmer) Done() <-chan struct{}                        { return nil }
+func (f *fakeFarmer) Logf(format string, args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+func (f *fakeFarmer) Warnf(format string, args ...interface{}) {
+	f.logs = append(f.logs, fmt.Sprintf(format, args...))
+}
+
+// TestNotification checks which push events become messages: drop
+// claims, and go-live only for covered channels and enabled kinds, all
+// of it only while notifications aren't muted.
+func TestNotification(t *testing.T) {
+	f := &fakeFarmer{}
+	b := New(config.Telegram{ChatID: 1, LiveChannels: []string{"alpha"}}, f)
+
+	claim := farmer.PushEvent{Kind: farmer.PushKindDropClaimed, Data: drops.DropClaimed{Name: "Skin", Campaign: "Rust Week"}}
+	if got := b.notification(claim); got != "Drop claimed: Skin (Rust Week)" {
+		t.Fatalf("drop claim = %q", got)
+	}
+	live := farmer.PushEvent{Kind: farmer.PushKindChannelLive, Data: farmer.StreamLive{
+		Login: "alpha", DisplayName: "Alpha", GameName: "Rust", URL: "https://www.twitch.tv/alpha",
+	}}
+	if got := b.notification(live); got != "Alpha is live: Rust\nhttps://www.twitch.tv/alpha" {
+		t.Fatalf("live = %q", got)
+	}
+	other := farmer.PushEvent{Kind: farmer.PushKindChannelLive, Data: farmer.StreamLive{Login: "beta", Priority: 1}}
+	if got := b.notification(other); got != "" {
+		t.Fatalf("uncovered channel notified: %q", got)
+	}
+	if got := b.notification(farmer.PushEvent{Kind: farmer.PushKindLog}); got != "" {
+		t.Fatalf("log event notified: %q", got)
+	}
+
+	b.cfg.Notify = []string{config.TelegramNotifyLive}
+	if got := b.notification(claim); got != "" {
+		t.Fatalf("drop claim sent with notify=[live]: %q", got)
+	}
+	f.muted = true
+	if got := b.notification(live); got != "" {
+		t.Fatalf("live sent with notifications off: %q", got)
+	}
+}
+
+func TestCommand(t *testing.T) {
+	f := &fakeFarmer{paused: map[string]bool{}}
+	b := New(config.Telegram{ChatID: 1}, f)
+
+	if got := b.command("/pause@MyBot Alpha"); got != "Paused alpha" || !f.paused["alpha"] {
+		t.Fatalf("/pause = %q, paused = %v", got, f.paused)
+	}
+	if got := b.command("/resume nobody"); got != "channel nobody not found" {
+		t.Fatalf("/resume unknown = %q", got)
+	}
+	if got := b.command("/pause"); got != "Farming paused" || !f.all {
+		t.Fatalf("/pause without login = %q, all = %v", got, f.all)
+	}
+	if got := b.command("/add @Beta"); got != "Added beta" || len(f.added) != 1 || f.added[0] != "beta" {
+		t.Fatalf("/add = %q, added = %v", got, f.added)
+	}
+	if got := b.command("/add"); !strings.HasPrefix(got, "Usage") {
+		t.Fatalf("/add without login = %q", got)
+	}
+	if got := b.command("/channels"); got != "Alpha - watching, 50 pts, Rust" {
+		t.Fatalf("/channels = %q", got)
+	}
+	if got := b.command("/stats"); !strings.Contains(got, "Points: +1200") || !strings.Contains(got, "Today: +300 pts") {
+		t.Fatalf("/stats = %q", got)
+	}
+	if got := b.command("hello"); got != "" {
+		t.Fatalf("plain text answered: %q", got)
+	}
+}
+
+// TestCall checks the request shape and that API refusals come back as
+// Telegram's description, without the token-bearing URL.
+func TestCall(t *testing.T) {
+	var sent map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/botsecret/sendMessage":
+			json.NewDecoder(r.Body).Decode(&sent)
+			w.Write([]byte(`{"ok":true,"result":{}}`))
+		case "/botsecret/getUpdates":
+			if r.URL.Query().Get("offset") != "7" {
+				t.Errorf("offset = %q", r.URL.Query().Get("offset"))
+			}
+			w.Write([]byte(`{"ok":true,"result":[{"update_id":7,"message":{"text":"/stats","chat":{"id":1}}}]}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"ok":false,"description":"Unauthorized"}`))
+		}
+	}))
+	defer srv.Close()
+
+	f := &fakeFarmer{}
+	b := New(config.Telegram{BotToken: "secret", ChatID: 1}, f)
+	b.base = srv.URL + "/botsecret"
+	ctx := context.Background()
+
+	b.send(ctx, "hi")
+	if sent["text"] != "hi" || sent["chat_id"] != float64(1) {
+		t.Fatalf("sendMessage body = %v", sent)
+	}
+
+	b.offset = 7
+	updates, err := b.getUpdates(ctx)
+	if err != nil || len(updates) != 1 || updates[0].Message.Text != "/stats" {
+		t.Fatalf("getUpdates = %+v, %v", updates, err)
+	}
+
+	b.base = srv.URL + "/botwrong"
+	if err := b.call(ctx, http.MethodPost, "sendMessage", nil, nil); err == nil || err.Error() != "HTTP 401: Unauthorized" {
+		t.Fatalf("refusal = %v", err)
+	}
+	b.base = "http://127.0.0.1:1/botsecret"
+	if err := b.call(ctx, http.MethodGet, "getMe", nil, nil); err == nil || strings.Contains(err.Error(), "secret") {
+		t.Fatalf("transport error = %v, want one without the token", err)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	for _, tc := range []struct {
+		in   string
+		n    int
+		want string
+	}{
+		{"hello", 10, "hello"},
+		{"hello", 3, "hel"},
+		{"héllo", 2, "h"}, // é is two bytes; don't keep half of it
+		{"héllo", 3, "hé"},
+		{"🎉🎉", 5, "🎉"},
+	} {
+		got := truncate(tc.in, tc.n)
+		if got != tc.want || !utf8.ValidString(got) {
+			t.Errorf("truncate(%q, %d) = %q, want %q", tc.in, tc.n, got, tc.want)
+		}
+	}
+}
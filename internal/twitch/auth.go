@@ -18,6 +18,10 @@ const (
 	oauthScopes = "channel:read:redemptions user:read:email chat:read chat:edit user:write:chat"
 )
 
+// authHTTPClient carries the device-code login. Its transport looks the
+// proxy up per request, so SetProxy after package init still applies.
+var authHTTPClient = NewHTTPClient(30 * time.Second)
+
 // DeviceCodeResponse is the response from the device code request.
 type DeviceCodeResponse struct {
 	DeviceCode      string `json:"device_code"`
@@ -46,7 +50,7 @@ type tokenErrorResponse struct {
 // It requests a device code, prints instructions for the user, and polls
 // until the user authorizes or the code expires.
 func DeviceCodeLogin(clientID string) (string, error) {
-	dcr, err := requestDeviceCode(clientID)
+	dcr, err := RequestDeviceCode(clientID)
 	if err != nil {
 		return "", fmt.Errorf("request device code: %w", err)
 	}
@@ -68,14 +72,14 @@ func DeviceCodeLogin(clientID string) (string, error) {
 	return token, nil
 }
 
-// requestDeviceCode sends a POST to Twitch's device code endpoint.
-func requestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
+// RequestDeviceCode sends a POST to Twitch's device code endpoint.
+func RequestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
 	form := url.Values{
 		"client_id": {clientID},
 		"scopes":    {oauthScopes},
 	}
 
-	resp, err := http.Post(deviceCodeURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+	resp, err := authHTTPClient.Post(deviceCodeURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
 	if err != nil {
 		return nil, fmt.Errorf("POST %s: %w", deviceCodeURL, err)
 	}
@@ -102,6 +106,12 @@ func requestDeviceCode(clientID string) (*DeviceCodeResponse, error) {
 	return &dcr, nil
 }
 
+// PollDeviceCode waits until the user authorizes dcr and returns the
+// access token. It blocks until then, or until the code expires.
+func PollDeviceCode(clientID string, dcr *DeviceCodeResponse) (string, error) {
+	return pollForToken(clientID, dcr.DeviceCode, dcr.Interval, dcr.ExpiresIn)
+}
+
 // pollForToken polls Twitch's token endpoint until the user authorizes,
 // the code expires, or authorization is denied.
 func pollForToken(clientID, deviceCode string, interval, expiresIn int) (string, error) {
@@ -126,7 +136,7 @@ func pollForToken(clientID, deviceCode string, interval, expiresIn int) (string,
 			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
 		}
 
-		resp, err := http.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		resp, err := authHTTPClient.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
 		if err != nil {
 			continue // transient network error, retry
 		}
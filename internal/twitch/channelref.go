@@ -0,0 +1,85 @@
+package twitch
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	loginPattern     = regexp.MustCompile(`^[a-z0-9_]{1,25}$`)
+	channelIDPattern = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ParseChannelRef reduces what a user may paste to name a channel — a
+// login, "@login", a twitch.tv URL or a numeric channel ID — to a
+// lowercase login or an ID. Exactly one of the two is set.
+func ParseChannelRef(ref string) (login, id string, err error) {
+	s := strings.ToLower(strings.TrimSpace(ref))
+	if strings.Contains(s, "twitch.tv") || strings.Contains(s, "://") {
+		// A URL path is always a login, even an all-digit one.
+		if login, err = loginFromURL(s); err != nil {
+			return "", "", err
+		}
+		return login, "", nil
+	}
+	switch {
+	case s == "":
+		return "", "", fmt.Errorf("channel is required")
+	case channelIDPattern.MatchString(s):
+		return "", s, nil
+	}
+	s = strings.TrimPrefix(s, "@")
+	if loginPattern.MatchString(s) {
+		return s, "", nil
+	}
+	return "", "", fmt.Errorf("%q is not a channel login, twitch.tv URL or channel ID", ref)
+}
+
+// loginFromURL returns the channel login of a twitch.tv URL such as
+// https://www.twitch.tv/login/videos or twitch.tv/popout/login/chat.
+func loginFromURL(s string) (string, error) {
+	if !strings.Contains(s, "://") {
+		s = "https://" + s
+	}
+	u, err := url.Parse(s)
+	if err != nil {
+		return "", fmt.Errorf("invalid channel URL: %w", err)
+	}
+	host := u.Hostname()
+	if host != "twitch.tv" && !strings.HasSuffix(host, ".twitch.tv") {
+		return "", fmt.Errorf("%s is not a twitch.tv URL", host)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if len(parts) >= 2 && (parts[0] == "popout" || parts[0] == "moderator" || parts[0] == "embed") {
+		parts = parts[1:]
+	}
+	if parts[0] == "" || !loginPattern.MatchString(parts[0]) {
+		return "", fmt.Errorf("no channel in URL %s", s)
+	}
+	return parts[0], nil
+}
+
+// ResolveChannel looks up a channel named by anything ParseChannelRef
+// accepts. A numeric ref is tried as a channel ID first, then as a login:
+// all-digit logins exist.
+func (g *GQLClient) ResolveChannel(ref string) (*ChannelInfo, error) {
+	login, id, err := ParseChannelRef(ref)
+	if err != nil {
+		return nil, err
+	}
+	if id == "" {
+		return g.GetChannelInfo(login)
+	}
+	info, err := g.GetChannelInfoByID(id)
+	if err == nil {
+		return info, nil
+	}
+	if loginPattern.MatchString(id) {
+		if byLogin, lerr := g.GetChannelInfo(id); lerr == nil {
+			return byLogin, nil
+		}
+	}
+	return nil, err
+}
@@ -0,0 +1,37 @@
+package twitch
+
+import "testing"
+
+func TestParseChannelRef(t *testing.T) {
+	cases := []struct {
+		ref       string
+		login, id string
+		wantErr   bool
+	}{
+		{ref: "SomeStreamer", login: "somestreamer"},
+		{ref: "  @some_streamer ", login: "some_streamer"},
+		{ref: "123456789", id: "123456789"},
+		{ref: "@123456789", login: "123456789"},
+		{ref: "https://www.twitch.tv/SomeStreamer", login: "somestreamer"},
+		{ref: "twitch.tv/somestreamer/videos?filter=all", login: "somestreamer"},
+		{ref: "https://m.twitch.tv/somestreamer/", login: "somestreamer"},
+		{ref: "https://www.twitch.tv/popout/somestreamer/chat?popout=", login: "somestreamer"},
+		{ref: "https://twitch.tv/123456789", login: "123456789"},
+		{ref: "", wantErr: true},
+		{ref: "https://www.twitch.tv/", wantErr: true},
+		{ref: "https://example.com/somestreamer", wantErr: true},
+		{ref: "not a login", wantErr: true},
+	}
+	for _, c := range cases {
+		login, id, err := ParseChannelRef(c.ref)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("ParseChannelRef(%q) = %q, %q, want error", c.ref, login, id)
+			}
+			continue
+		}
+		if err != nil || login != c.login || id != c.id {
+			t.Errorf("ParseChannelRef(%q) = %q, %q, %v, want %q, %q", c.ref, login, id, err, c.login, c.id)
+		}
+	}
+}
@@ -171,6 +171,29 @@ func applyClaimedBenefitFallback(c *DropCampaign, claimedBenefits map[string]tim
 	}
 }
 
+// ProgressPercent returns the campaign's overall progress (0-100): watched
+// minutes over required minutes summed across its timed drops, with
+// claimed drops counting as fully watched.
+func (c *DropCampaign) ProgressPercent() int {
+	var watched, required int
+	for _, d := range c.Drops {
+		if d.RequiredMinutesWatched <= 0 {
+			continue
+		}
+		required += d.RequiredMinutesWatched
+		switch {
+		case d.IsClaimed || d.CurrentMinutesWatched >= d.RequiredMinutesWatched:
+			watched += d.RequiredMinutesWatched
+		case d.CurrentMinutesWatched > 0:
+			watched += d.CurrentMinutesWatched
+		}
+	}
+	if required == 0 {
+		return 0
+	}
+	return watched * 100 / required
+}
+
 // ProgressPercent returns the drop progress as a percentage (0-100).
 func (d *TimeBasedDrop) ProgressPercent() int {
 	if d.RequiredMinutesWatched <= 0 {
@@ -206,7 +229,10 @@ func (g *GQLClient) GetDropsInventory() ([]DropCampaign, error) {
 	}
 
 	// Fetch inventory for progress data + gameEventDrops
-	inventoryCampaigns, claimedBenefits, _ := g.getDropsFromInventory()
+	inventoryCampaigns, claimedBenefits, invErr := g.getDropsFromInventory()
+	if invErr != nil {
+		g.diag("[Drops/Diag] Inventory query failed (%v) — continuing without progress data", invErr)
+	}
 
 	// One-shot diag: dump every inventory campaign so we can spot
 	// "should-be-active campaign missing or mislabeled" cases.
@@ -341,6 +367,10 @@ const (
 	// (timeBasedDrops, allow.channels, self.isAccountConnected, etc.) under
 	// data.user.dropCampaign. Used to enrich the Dashboard summary list.
 	persistedHashCampaignDetails = "039277bf98f3130929262cc7c6efd9c141ca3749cb6dca442fc8ead9a53f77c1"
+	// persistedHashAvailableDrops (DropsHighlightService_AvailableDrops)
+	// lists the campaigns a channel is running under
+	// data.channel.viewerDropCampaigns.
+	persistedHashAvailableDrops = "9a62a09bce5b53e26e64a671e530bc599cb6aab1e5ba3cbd5d85966d3940716f"
 
 	// campaignDetailsBatchSize: Twitch caps batched GQL requests around 35;
 	// 20 keeps us well under that ceiling while still cutting the round-trip
@@ -398,27 +428,92 @@ func (g *GQLClient) getCampaignDetails(campaignIDs []string) (map[string]DropCam
 
 		for i, resp := range resps {
 			id := chunk[i]
-			userRaw, ok := resp.Data["user"]
-			if !ok || userRaw == nil {
+			var data struct {
+				User *struct {
+					DropCampaign *gqlDropCampaign `json:"dropCampaign"`
+				} `json:"user"`
+			}
+			if err := resp.decode(&data); err != nil {
+				g.diag("[Drops/Diag] CampaignDetails %s: %v", id, err)
 				continue
 			}
-			userMap, ok := userRaw.(map[string]interface{})
-			if !ok {
+			if data.User == nil || data.User.DropCampaign == nil {
 				continue
 			}
-			campaignRaw, ok := userMap["dropCampaign"]
-			if !ok || campaignRaw == nil {
+			out[id] = parseCampaignList([]gqlDropCampaign{*data.User.DropCampaign})[0]
+		}
+	}
+
+	return out, nil
+}
+
+// GetChannelDropCampaigns returns the IDs of the drop campaigns each
+// channel is running right now, keyed by channel ID, in batched requests.
+// The game directory's DROPS_ENABLED filter only tells that a streamer has
+// drops switched on, not for which campaign — this is the per-campaign
+// answer. Channels whose lookup failed are absent from the map; a channel
+// running none maps to an empty slice.
+func (g *GQLClient) GetChannelDropCampaigns(channelIDs []string) map[string][]string {
+	out := make(map[string][]string, len(channelIDs))
+	for start := 0; start < len(channelIDs); start += campaignDetailsBatchSize {
+		end := start + campaignDetailsBatchSize
+		if end > len(channelIDs) {
+			end = len(channelIDs)
+		}
+		chunk := channelIDs[start:end]
+
+		reqs := make([]GQLRequest, len(chunk))
+		for i, id := range chunk {
+			reqs[i] = GQLRequest{
+				OperationName: "DropsHighlightService_AvailableDrops",
+				Variables: map[string]interface{}{
+					"channelID": id,
+				},
+				Extensions: &GQLExtensions{
+					PersistedQuery: &PersistedQuery{
+						Version:    1,
+						SHA256Hash: persistedHashAvailableDrops,
+					},
+				},
+			}
+		}
+
+		resps, err := g.doBatch(reqs)
+		if err != nil {
+			g.diag("[Drops/Diag] AvailableDrops batch %d-%d: %v", start, end, err)
+			continue
+		}
+
+		for i, resp := range resps {
+			if i >= len(chunk) {
+				break
+			}
+			if len(resp.Errors) > 0 {
 				continue
 			}
-			parsed := parseCampaignList([]interface{}{campaignRaw})
-			if len(parsed) == 0 {
+			var data struct {
+				Channel *struct {
+					ViewerDropCampaigns []struct {
+						ID string `json:"id"`
+					} `json:"viewerDropCampaigns"`
+				} `json:"channel"`
+			}
+			if err := resp.decode(&data); err != nil {
+				g.diag("[Drops/Diag] AvailableDrops %s: %v", chunk[i], err)
 				continue
 			}
-			out[id] = parsed[0]
+			ids := []string{}
+			if data.Channel != nil {
+				for _, c := range data.Channel.ViewerDropCampaigns {
+					if c.ID != "" {
+						ids = append(ids, c.ID)
+					}
+				}
+			}
+			out[chunk[i]] = ids
 		}
 	}
-
-	return out, nil
+	return out
 }
 
 // getDropsDashboard fetches all campaigns via ViewerDropsDashboard using the
@@ -441,63 +536,47 @@ func (g *GQLClient) getDropsDashboard() ([]DropCampaign, error) {
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
-		return nil, fmt.Errorf("get drops dashboard: %w", err)
-	}
-
-	currentUser, ok := resp.Data["currentUser"]
-	if !ok || currentUser == nil {
-		g.diag("[Drops/Diag] Dashboard: currentUser missing/null in response (auth token may lack scope, or Client-ID rejected). Raw data keys: %v", mapKeys(resp.Data))
-		return nil, nil
+	var data struct {
+		CurrentUser *struct {
+			DropCampaigns []gqlDropCampaign `json:"dropCampaigns"`
+		} `json:"currentUser"`
 	}
-	userMap, ok := currentUser.(map[string]interface{})
-	if !ok {
-		g.diag("[Drops/Diag] Dashboard: currentUser is not an object (type=%T)", currentUser)
-		return nil, nil
+	if err := g.query(req, &data); err != nil {
+		return nil, fmt.Errorf("get drops dashboard: %w", err)
 	}
 
-	campaignsRaw, ok := userMap["dropCampaigns"]
-	if !ok || campaignsRaw == nil {
-		g.diag("[Drops/Diag] Dashboard: dropCampaigns missing/null (Twitch returned the user but no campaigns — A/B test, region, or account-state issue). currentUser keys: %v", mapKeys(userMap))
+	if data.CurrentUser == nil {
+		g.diag("[Drops/Diag] Dashboard: currentUser missing/null in response (auth token may lack scope, or Client-ID rejected)")
 		return nil, nil
 	}
-	campaignList, ok := campaignsRaw.([]interface{})
-	if !ok {
-		g.diag("[Drops/Diag] Dashboard: dropCampaigns is not a list (type=%T)", campaignsRaw)
+	if data.CurrentUser.DropCampaigns == nil {
+		g.diag("[Drops/Diag] Dashboard: dropCampaigns missing/null (Twitch returned the user but no campaigns — A/B test, region, or account-state issue)")
 		return nil, nil
 	}
 
-	parsed := parseCampaignList(campaignList)
-	g.diag("[Drops/Diag] Dashboard OK: %d campaigns parsed from %d raw entries", len(parsed), len(campaignList))
+	parsed := parseCampaignList(data.CurrentUser.DropCampaigns)
+	g.diag("[Drops/Diag] Dashboard OK: %d campaigns parsed", len(parsed))
 	return parsed, nil
 }
 
 // parseDiagOnce dumps the field shape of the first campaign each call. It
 // routes through the package-level diagSink so the output ends up in the
 // user-visible file log instead of the discarded log.Printf stream.
-func parseDiagOnce(cMap map[string]interface{}) {
+func parseDiagOnce(c gqlDropCampaign) {
 	diag := getParseDiag()
 	if diag == nil {
 		return
 	}
-	drops := -1
-	if td, ok := cMap["timeBasedDrops"].([]interface{}); ok {
-		drops = len(td)
-	} else if _, present := cMap["timeBasedDrops"]; !present {
-		drops = -2 // key missing entirely
+	drops := len(c.TimeBasedDrops)
+	if c.TimeBasedDrops == nil {
+		drops = -2 // key missing or null
 	}
-	channels := -1
-	if allow, ok := cMap["allow"].(map[string]interface{}); ok {
-		if ch, ok := allow["channels"].([]interface{}); ok {
-			channels = len(ch)
-		} else if _, present := allow["channels"]; !present {
-			channels = -2
-		}
+	channels := -2
+	if c.Allow != nil && c.Allow.Channels != nil {
+		channels = len(c.Allow.Channels)
 	}
-	diag("[Drops/Diag] First campaign raw keys=%v | timeBasedDrops count=%d (-1 wrong type, -2 missing) | allow.channels count=%d | name=%q endAt=%q status=%q",
-		mapKeys(cMap), drops, channels,
-		getString(cMap, "name"), getString(cMap, "endAt"), getString(cMap, "status"))
+	diag("[Drops/Diag] First campaign timeBasedDrops count=%d (-2 missing) | allow.channels count=%d | name=%q endAt=%q status=%q",
+		drops, channels, c.Name, c.EndAt.Format(time.RFC3339), c.Status)
 }
 
 // parseDiagSink is the package-level sink for parseCampaignList diagnostics.
@@ -512,18 +591,6 @@ func SetParseDiagSink(sink func(format string, args ...interface{})) {
 	parseDiagSink = sink
 }
 
-// mapKeys returns the keys of a map[string]interface{} for diagnostic logging.
-func mapKeys(m map[string]interface{}) []string {
-	if m == nil {
-		return nil
-	}
-	keys := make([]string, 0, len(m))
-	for k := range m {
-		keys = append(keys, k)
-	}
-	return keys
-}
-
 // getDropsFromInventory fetches campaigns via the Inventory query (fallback).
 // Also returns gameEventDrops: a map of benefitID → lastAwardedAt for ALL ever-claimed benefits.
 func (g *GQLClient) getDropsFromInventory() ([]DropCampaign, map[string]time.Time, error) {
@@ -540,235 +607,119 @@ func (g *GQLClient) getDropsFromInventory() ([]DropCampaign, map[string]time.Tim
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
-		return nil, nil, fmt.Errorf("get drops inventory: %w", err)
-	}
-
-	currentUser, ok := resp.Data["currentUser"]
-	if !ok || currentUser == nil {
-		return nil, nil, nil
-	}
-	userMap, ok := currentUser.(map[string]interface{})
-	if !ok {
-		return nil, nil, nil
+	var data struct {
+		CurrentUser *struct {
+			Inventory *gqlInventory `json:"inventory"`
+		} `json:"currentUser"`
 	}
-
-	inventory, ok := userMap["inventory"]
-	if !ok || inventory == nil {
-		return nil, nil, nil
+	if err := g.query(req, &data); err != nil {
+		return nil, nil, fmt.Errorf("get drops inventory: %w", err)
 	}
-	invMap, ok := inventory.(map[string]interface{})
-	if !ok {
+	if data.CurrentUser == nil || data.CurrentUser.Inventory == nil {
 		return nil, nil, nil
 	}
+	inv := data.CurrentUser.Inventory
 
 	// Parse gameEventDrops — permanent history of all claimed benefit IDs
-	claimedBenefits := parseGameEventDrops(invMap)
-
-	campaignsRaw, ok := invMap["dropCampaignsInProgress"]
-	if !ok || campaignsRaw == nil {
-		return nil, claimedBenefits, nil
-	}
-	campaignList, ok := campaignsRaw.([]interface{})
-	if !ok {
+	claimedBenefits := parseGameEventDrops(inv.GameEventDrops)
+	if len(inv.DropCampaignsInProgress) == 0 {
 		return nil, claimedBenefits, nil
 	}
-
-	return parseCampaignList(campaignList), claimedBenefits, nil
+	return parseCampaignList(inv.DropCampaignsInProgress), claimedBenefits, nil
 }
 
 // parseGameEventDrops extracts the benefit ID → lastAwardedAt map from the inventory response.
 // gameEventDrops contains ALL benefits ever claimed by the user, even from completed/expired campaigns.
-func parseGameEventDrops(invMap map[string]interface{}) map[string]time.Time {
+// A benefit without a timestamp is still claimed and maps to the zero time.
+func parseGameEventDrops(items []gqlGameEventDrop) map[string]time.Time {
 	result := make(map[string]time.Time)
-
-	geDropsRaw, ok := invMap["gameEventDrops"]
-	if !ok || geDropsRaw == nil {
-		return result
-	}
-	geDropsList, ok := geDropsRaw.([]interface{})
-	if !ok {
-		return result
-	}
-
-	for _, item := range geDropsList {
-		itemMap, ok := item.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		id := getString(itemMap, "id")
-		if id == "" {
+	for _, item := range items {
+		if item.ID == "" {
 			continue
 		}
-		lastAwarded := getString(itemMap, "lastAwardedAt")
-		if lastAwarded != "" {
-			if t, err := time.Parse(time.RFC3339, lastAwarded); err == nil {
-				result[id] = t
-			}
-		} else {
-			// Benefit exists but no timestamp — still claimed
-			result[id] = time.Time{}
-		}
+		result[item.ID] = item.LastAwardedAt.Time
 	}
-
 	return result
 }
 
-// parseCampaignList parses a list of campaign objects from GQL response.
-func parseCampaignList(campaignList []interface{}) []DropCampaign {
+// parseCampaignList converts decoded campaign objects to DropCampaigns.
+func parseCampaignList(campaignList []gqlDropCampaign) []DropCampaign {
 	var campaigns []DropCampaign
-	for i, cRaw := range campaignList {
-		cMap, ok := cRaw.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		// One-time diagnostic dump of the first campaign's raw shape to
+	for i, c := range campaignList {
+		// One-time diagnostic dump of the first campaign's shape to
 		// verify which fields the persisted-hash response actually carries.
-		// Remove once the field-shape question is resolved.
 		if i == 0 {
-			parseDiagOnce(cMap)
+			parseDiagOnce(c)
 		}
 
 		campaign := DropCampaign{
-			ID:                 getString(cMap, "id"),
-			Name:               getString(cMap, "name"),
-			Status:             getString(cMap, "status"),
+			ID:                 c.ID,
+			Name:               c.Name,
+			Status:             c.Status,
+			StartAt:            c.StartAt.Time,
+			EndAt:              c.EndAt.Time,
 			IsAccountConnected: true, // default true — only false when API explicitly says so
 		}
 
-		// Parse game with `displayName || name` fallback. The persisted-hash
-		// Inventory and CampaignDetails responses return only `name` (Android
-		// schema), while the raw-query Dashboard returned `displayName`.
-		// Without the fallback every inventory campaign ends up with an empty
-		// GameName and gets silently rejected by the wanted_games whitelist.
-		if game, ok := cMap["game"]; ok && game != nil {
-			if gMap, ok := game.(map[string]interface{}); ok {
-				campaign.GameID = getString(gMap, "id")
-				if dn := getString(gMap, "displayName"); dn != "" {
-					campaign.GameName = dn
-				} else {
-					campaign.GameName = getString(gMap, "name")
-				}
-				campaign.GameSlug = getString(gMap, "slug")
+		// Game name takes `displayName || name`. The persisted-hash
+		// Inventory and CampaignDetails responses return only `name`
+		// (Android schema), while the raw-query Dashboard returned
+		// `displayName`. Without the fallback every inventory campaign
+		// ends up with an empty GameName and gets silently rejected by the
+		// wanted_games whitelist.
+		if c.Game != nil {
+			campaign.GameID = c.Game.ID
+			campaign.GameName = c.Game.title()
+			campaign.GameSlug = c.Game.Slug
+		}
+
+		// Account connection status (only override if explicitly set)
+		if c.Self != nil && c.Self.IsAccountConnected != nil {
+			campaign.IsAccountConnected = *c.Self.IsAccountConnected
+		}
+
+		for _, d := range c.TimeBasedDrops {
+			// Per-drop time window (TDM parity — a drop's startAt/endAt
+			// may differ from the campaign's window for multi-drop chains).
+			drop := TimeBasedDrop{
+				ID:                     d.ID,
+				Name:                   d.Name,
+				RequiredMinutesWatched: d.RequiredMinutesWatched,
+				StartAt:                d.StartAt.Time,
+				EndAt:                  d.EndAt.Time,
 			}
-		}
 
-		// Parse account connection status (only override if explicitly false)
-		if self, ok := cMap["self"]; ok && self != nil {
-			if selfMap, ok := self.(map[string]interface{}); ok {
-				if connected, ok := selfMap["isAccountConnected"]; ok && connected != nil {
-					if b, ok := connected.(bool); ok {
-						campaign.IsAccountConnected = b
-					}
+			// Drops that must be claimed before this one becomes earnable.
+			for _, pre := range d.PreconditionDrops {
+				if pre.ID != "" {
+					drop.PreconditionDrops = append(drop.PreconditionDrops, pre.ID)
 				}
 			}
-		}
 
-		// Parse timestamps
-		if startAt := getString(cMap, "startAt"); startAt != "" {
-			if t, err := time.Parse(time.RFC3339, startAt); err == nil {
-				campaign.StartAt = t
-			}
-		}
-		if endAt := getString(cMap, "endAt"); endAt != "" {
-			if t, err := time.Parse(time.RFC3339, endAt); err == nil {
-				campaign.EndAt = t
+			if len(d.BenefitEdges) > 0 && d.BenefitEdges[0].Benefit != nil {
+				b := d.BenefitEdges[0].Benefit
+				drop.BenefitID = b.ID
+				drop.BenefitName = b.Name
+				drop.BenefitType = b.DistributionType
 			}
-		}
-
-		// Parse time-based drops
-		if dropsRaw, ok := cMap["timeBasedDrops"]; ok && dropsRaw != nil {
-			if dropList, ok := dropsRaw.([]interface{}); ok {
-				for _, dRaw := range dropList {
-					dMap, ok := dRaw.(map[string]interface{})
-					if !ok {
-						continue
-					}
-
-					drop := TimeBasedDrop{
-						ID:                    getString(dMap, "id"),
-						Name:                  getString(dMap, "name"),
-						RequiredMinutesWatched: getInt(dMap, "requiredMinutesWatched"),
-					}
-
-					// Parse per-drop time window (TDM parity — a drop's
-					// startAt/endAt may differ from the campaign's window
-					// for multi-drop chains).
-					if startAt := getString(dMap, "startAt"); startAt != "" {
-						if t, err := time.Parse(time.RFC3339, startAt); err == nil {
-							drop.StartAt = t
-						}
-					}
-					if endAt := getString(dMap, "endAt"); endAt != "" {
-						if t, err := time.Parse(time.RFC3339, endAt); err == nil {
-							drop.EndAt = t
-						}
-					}
-
-					// Parse preconditionDrops list (drops that must be
-					// claimed before this one becomes earnable).
-					if pre, ok := dMap["preconditionDrops"]; ok && pre != nil {
-						if preList, ok := pre.([]interface{}); ok {
-							for _, pItem := range preList {
-								if pMap, ok := pItem.(map[string]interface{}); ok {
-									if pid := getString(pMap, "id"); pid != "" {
-										drop.PreconditionDrops = append(drop.PreconditionDrops, pid)
-									}
-								}
-							}
-						}
-					}
-
-					// Parse benefit name
-					if benefitEdges, ok := dMap["benefitEdges"]; ok && benefitEdges != nil {
-						if edges, ok := benefitEdges.([]interface{}); ok && len(edges) > 0 {
-							if edge, ok := edges[0].(map[string]interface{}); ok {
-								if benefit, ok := edge["benefit"]; ok && benefit != nil {
-									if bMap, ok := benefit.(map[string]interface{}); ok {
-										drop.BenefitID = getString(bMap, "id")
-									drop.BenefitName = getString(bMap, "name")
-									drop.BenefitType = getString(bMap, "distributionType")
-									}
-								}
-							}
-						}
-					}
-
-					// Parse self (progress)
-					if self, ok := dMap["self"]; ok && self != nil {
-						if sMap, ok := self.(map[string]interface{}); ok {
-							drop.CurrentMinutesWatched = getInt(sMap, "currentMinutesWatched")
-							drop.DropInstanceID = getString(sMap, "dropInstanceID")
-							if claimed, ok := sMap["isClaimed"]; ok && claimed != nil {
-								if b, ok := claimed.(bool); ok {
-									drop.IsClaimed = b
-								}
-							}
-						}
-					}
 
-					campaign.Drops = append(campaign.Drops, drop)
-				}
+			// Progress
+			if d.Self != nil {
+				drop.CurrentMinutesWatched = d.Self.CurrentMinutesWatched
+				drop.DropInstanceID = d.Self.DropInstanceID
+				drop.IsClaimed = d.Self.IsClaimed
 			}
+
+			campaign.Drops = append(campaign.Drops, drop)
 		}
 
-		// Parse allowed channels
-		if allow, ok := cMap["allow"]; ok && allow != nil {
-			if allowMap, ok := allow.(map[string]interface{}); ok {
-				if chRaw, ok := allowMap["channels"]; ok && chRaw != nil {
-					if chList, ok := chRaw.([]interface{}); ok {
-						for _, chItem := range chList {
-							if chMap, ok := chItem.(map[string]interface{}); ok {
-								campaign.Channels = append(campaign.Channels, DropChannel{
-									ID:          getString(chMap, "id"),
-									Name:        getString(chMap, "name"),
-									DisplayName: getString(chMap, "displayName"),
-								})
-							}
-						}
-					}
-				}
+		if c.Allow != nil {
+			for _, ch := range c.Allow.Channels {
+				campaign.Channels = append(campaign.Channels, DropChannel{
+					ID:          ch.ID,
+					Name:        ch.Name,
+					DisplayName: ch.DisplayName,
+				})
 			}
 		}
 
@@ -790,19 +741,19 @@ func (g *GQLClient) ClaimDrop(dropInstanceID string) error {
 		},
 	}
 
-	resp, err := g.do(req)
+	var data struct {
+		ClaimDropRewards *struct {
+			Status string `json:"status"`
+		} `json:"claimDropRewards"`
+	}
+	x, err := g.claimQuery(req, &data)
 	if err != nil {
-		return fmt.Errorf("claim drop: %w", err)
+		return g.claimFailed(x, fmt.Errorf("claim drop: %w", err))
 	}
 
-	if resp != nil {
-		if claimData, ok := resp.Data["claimDropRewards"]; ok && claimData != nil {
-			if cdMap, ok := claimData.(map[string]interface{}); ok {
-				status := getString(cdMap, "status")
-				if status != "" && status != "ELIGIBLE_FOR_ALL" && status != "CLAIMED" {
-					return fmt.Errorf("claim drop returned status: %s", status)
-				}
-			}
+	if cd := data.ClaimDropRewards; cd != nil {
+		if cd.Status != "" && cd.Status != "ELIGIBLE_FOR_ALL" && cd.Status != "CLAIMED" {
+			return g.claimFailed(x, fmt.Errorf("claim drop returned status: %s", cd.Status))
 		}
 	}
 
@@ -1,6 +1,8 @@
 package twitch
 
 import (
+	"encoding/json"
+	"strings"
 	"testing"
 	"time"
 )
@@ -124,3 +126,78 @@ func TestApplyClaimedBenefitFallback_AwardOutsideWindow(t *testing.T) {
 		t.Fatal("award before the campaign window must not mark the drop claimed")
 	}
 }
+
+// TestParseCampaignList_Decoded decodes an Inventory-shaped campaign
+// (Android schema: game.name only, null and empty timestamps) into DropCampaign.
+func TestParseCampaignList_Decoded(t *testing.T) {
+	resp := GQLResponse{Data: json.RawMessage(`{"currentUser":{"inventory":{
+		"dropCampaignsInProgress":[{
+			"id":"c1","name":"Season Drops","status":"ACTIVE",
+			"game":{"id":"g1","name":"Rust","slug":"rust"},
+			"self":{"isAccountConnected":false},
+			"startAt":"2026-07-09T00:00:00Z","endAt":"2026-07-16T00:00:00Z",
+			"timeBasedDrops":[{
+				"id":"d1","name":"Hat","requiredMinutesWatched":120,
+				"startAt":"","endAt":null,
+				"preconditionDrops":[{"id":"d0"}],
+				"benefitEdges":[{"benefit":{"id":"b1","name":"Hat","distributionType":"DIRECT_ENTITLEMENT"}}],
+				"self":{"currentMinutesWatched":30,"dropInstanceID":"","isClaimed":false}
+			}],
+			"allow":{"channels":[{"id":"1","name":"streamer","displayName":"Streamer"}]}
+		}],
+		"gameEventDrops":[{"id":"b0","lastAwardedAt":"2026-07-10T00:00:00Z"},{"id":"b9","lastAwardedAt":null}]
+	}}}`)}
+	var data struct {
+		CurrentUser *struct {
+			Inventory *gqlInventory `json:"inventory"`
+		} `json:"currentUser"`
+	}
+	if err := resp.decode(&data); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	inv := data.CurrentUser.Inventory
+
+	got := parseCampaignList(inv.DropCampaignsInProgress)
+	if len(got) != 1 {
+		t.Fatalf("campaigns = %d, want 1", len(got))
+	}
+	c := got[0]
+	if c.GameName != "Rust" || c.GameSlug != "rust" || c.IsAccountConnected || !c.EndAt.Equal(campEnd) {
+		t.Errorf("campaign = %+v", c)
+	}
+	if len(c.Channels) != 1 || c.Channels[0].Name != "streamer" {
+		t.Errorf("channels = %+v", c.Channels)
+	}
+	d := c.Drops[0]
+	if d.CurrentMinutesWatched != 30 || d.BenefitID != "b1" || len(d.PreconditionDrops) != 1 || !d.StartAt.IsZero() {
+		t.Errorf("drop = %+v", d)
+	}
+
+	claimed := parseGameEventDrops(inv.GameEventDrops)
+	if _, ok := claimed["b9"]; !ok || len(claimed) != 2 {
+		t.Errorf("gameEventDrops = %v", claimed)
+	}
+}
+
+// TestGQLDecode_SchemaChange: a retyped field fails with its path
+// instead of reading as zero.
+func TestGQLDecode_SchemaChange(t *testing.T) {
+	resp := GQLResponse{Data: json.RawMessage(`{"user":{"dropCampaign":{"id":"c1","timeBasedDrops":[{"requiredMinutesWatched":"120"}]}}}`)}
+	var data struct {
+		User *struct {
+			DropCampaign *gqlDropCampaign `json:"dropCampaign"`
+		} `json:"user"`
+	}
+	err := resp.decode(&data)
+	if err == nil || !strings.Contains(err.Error(), "requiredMinutesWatched") {
+		t.Fatalf("err = %v, want a decode error naming requiredMinutesWatched", err)
+	}
+
+	var cp gqlCommunityPoints
+	if err := json.Unmarshal([]byte(`{"balance":{"availablePoints":1500}}`), &cp); err != nil || cp.Balance != 1500 {
+		t.Errorf("object balance = %d, %v", cp.Balance, err)
+	}
+	if err := json.Unmarshal([]byte(`{"balance":900}`), &cp); err != nil || cp.Balance != 900 {
+		t.Errorf("number balance = %d, %v", cp.Balance, err)
+	}
+}
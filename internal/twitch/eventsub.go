@@ -0,0 +1,631 @@
+package twitch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	eventsubURL           = "wss://eventsub.wss.twitch.tv/ws"
+	helixSubscriptionsURL = "https://api.twitch.tv/helix/eventsub/subscriptions"
+	// eventsubWelcomeTimeout bounds the wait for session_welcome after
+	// dialing; Twitch sends it immediately.
+	eventsubWelcomeTimeout = 15 * time.Second
+	// eventsubKeepaliveGrace is added to the session's keepalive timeout
+	// before a silent connection is considered dead.
+	eventsubKeepaliveGrace = 10 * time.Second
+	// eventsubFailThreshold is how many connection attempts in a row may
+	// fail before the health hook reports the transport as down.
+	eventsubFailThreshold = 3
+)
+
+// ErrEventSubBudget is returned when Twitch refuses a subscription
+// because the session's cost budget is used up. WebSocket sessions only
+// get a handful of unauthorized (stream.online/offline) subscriptions,
+// so channels past the budget have to stay on PubSub.
+var ErrEventSubBudget = errors.New("eventsub subscription budget exhausted")
+
+// eventsubStreamTypes are the subscription types created per channel.
+// Viewer-side channel points, bonus claims and drops have no EventSub
+// equivalent (the channel-points subscriptions need broadcaster auth),
+// so those stay on PubSub regardless of transport.
+var eventsubStreamTypes = []string{"stream.online", "stream.offline"}
+
+// EventSubClient delivers stream up/down events over an EventSub
+// WebSocket session as an alternative to PubSub's video-playback-by-id
+// topic. Subscriptions are created through Helix against the session ID
+// and recreated on every new session; a session_reconnect migrates them
+// to the new URL without resubscribing.
+type EventSubClient struct {
+	authToken  tokenBox
+	events     chan FarmerEvent
+	logFunc    func(format string, args ...interface{})
+	httpClient *http.Client
+
+	// Warn, if set, gets connection failures in place of the log func.
+	// Set it before Connect.
+	Warn func(format string, args ...interface{})
+
+	mu        sync.Mutex
+	conn      *websocket.Conn
+	sessionID string
+	channels  map[string]bool     // broadcaster ID -> wanted (survives reconnects)
+	subs      map[string][]string // broadcaster ID -> subscription IDs on the current session
+	healthy   bool
+	started   bool
+	stopped   bool
+	kickCh    chan struct{} // wakes the idle loop when the first channel is added
+	stopCh    chan struct{}
+
+	onHealth       func(healthy bool)
+	onSubscribeErr func(channelID string, err error)
+}
+
+// NewEventSubClient creates an EventSub client. Events are delivered on
+// the shared farmer events channel.
+func NewEventSubClient(authToken string, events chan FarmerEvent, logFunc func(format string, args ...interface{})) *EventSubClient {
+	return &EventSubClient{
+		authToken:  tokenBox{token: authToken},
+		events:     events,
+		logFunc:    logFunc,
+		httpClient: NewHTTPClient(15 * time.Second),
+		channels:   make(map[string]bool),
+		subs:       make(map[string][]string),
+		healthy:    true,
+		kickCh:     make(chan struct{}, 1),
+		stopCh:     make(chan struct{}),
+	}
+}
+
+// SetHealthHook registers a callback for transport health transitions:
+// false after eventsubFailThreshold failed connection attempts in a
+// row, true once a session is established again.
+func (c *EventSubClient) SetHealthHook(fn func(healthy bool)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onHealth = fn
+}
+
+// SetSubscribeErrorHook registers a callback for channels whose
+// subscriptions could not be created. The channel is dropped from the
+// wanted set before the hook runs, so the caller can route it elsewhere.
+func (c *EventSubClient) SetSubscribeErrorHook(fn func(channelID string, err error)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onSubscribeErr = fn
+}
+
+// Connect starts the session loop. It only dials while at least one
+// channel is wanted — Twitch closes sessions that don't subscribe to
+// anything shortly after the welcome. Safe to call more than once.
+func (c *EventSubClient) Connect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped || c.started {
+		return nil
+	}
+	c.started = true
+	go c.connectLoop()
+	return nil
+}
+
+// Subscribe adds a channel's stream.online/offline subscriptions. With
+// a live session they are created right away; otherwise on the next
+// welcome. Failures are reported through the subscribe error hook.
+func (c *EventSubClient) Subscribe(channelID string) {
+	c.mu.Lock()
+	if c.channels[channelID] {
+		c.mu.Unlock()
+		return
+	}
+	c.channels[channelID] = true
+	sessionID := c.sessionID
+	c.mu.Unlock()
+
+	select {
+	case c.kickCh <- struct{}{}:
+	default:
+	}
+	if sessionID != "" {
+		go c.subscribeChannel(sessionID, channelID)
+	}
+}
+
+// Unsubscribe removes a channel and deletes its subscriptions. When the
+// last channel goes, the session is closed and the loop idles.
+func (c *EventSubClient) Unsubscribe(channelID string) {
+	c.mu.Lock()
+	delete(c.channels, channelID)
+	ids := c.subs[channelID]
+	delete(c.subs, channelID)
+	var idle *websocket.Conn
+	if len(c.channels) == 0 {
+		idle = c.conn
+	}
+	c.mu.Unlock()
+
+	for _, id := range ids {
+		if err := c.deleteSubscription(id); err != nil {
+			c.logf("delete subscription %s: %v", id, err)
+		}
+	}
+	if idle != nil {
+		idle.Close()
+	}
+}
+
+// Channels returns the number of channels routed through EventSub.
+func (c *EventSubClient) Channels() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.channels)
+}
+
+// Close shuts down the client.
+func (c *EventSubClient) Close() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.stopped {
+		return
+	}
+	c.stopped = true
+	close(c.stopCh)
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+func (c *EventSubClient) logf(format string, args ...interface{}) {
+	if c.logFunc != nil {
+		c.logFunc("[EventSub] "+format, args...)
+	}
+}
+
+func (c *EventSubClient) warnf(format string, args ...interface{}) {
+	if c.Warn != nil {
+		c.Warn("[EventSub] "+format, args...)
+		return
+	}
+	c.logf(format, args...)
+}
+
+func (c *EventSubClient) wanted() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.channels) > 0
+}
+
+func (c *EventSubClient) connectLoop() {
+	net := ActiveNetwork()
+	backoff := net.ReconnectBase
+	failures := 0
+	url := eventsubURL
+
+	for {
+		if !c.wanted() {
+			// Idle until a channel is added; start the next session
+			// with a clean health record.
+			failures = 0
+			c.mu.Lock()
+			c.healthy = true
+			c.mu.Unlock()
+			for !c.wanted() {
+				select {
+				case <-c.kickCh:
+				case <-c.stopCh:
+					return
+				}
+			}
+		}
+		select {
+		case <-c.stopCh:
+			return
+		default:
+		}
+
+		connectedAt := time.Now()
+		migrated := url != eventsubURL
+		conn, session, err := c.dial(url)
+		url = eventsubURL
+		if err == nil {
+			failures = 0
+			c.setHealthy(true)
+			reconnectURL, reason := c.runSession(conn, session, !migrated)
+			if reconnectURL != "" {
+				// Server-initiated migration: subscriptions move with
+				// the session, reconnect immediately.
+				url = reconnectURL
+				continue
+			}
+			select {
+			case <-c.stopCh:
+				return
+			default:
+			}
+			if time.Since(connectedAt) > net.StableAfter {
+				backoff = net.ReconnectBase
+			}
+			if !c.wanted() {
+				continue
+			}
+			c.logf("disconnected (%s), reconnecting in %v", reason, backoff)
+		} else {
+			failures++
+			if failures >= eventsubFailThreshold {
+				c.setHealthy(false)
+			}
+			c.warnf("connection failed: %v, retrying in %v", err, backoff)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-c.stopCh:
+			return
+		}
+		backoff *= 2
+		if backoff > net.ReconnectMax {
+			backoff = net.ReconnectMax
+		}
+	}
+}
+
+// dial connects to url and waits for session_welcome.
+func (c *EventSubClient) dial(url string) (*websocket.Conn, *eventsubSession, error) {
+	conn, _, err := wsDialer().Dial(url, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dial eventsub: %w", err)
+	}
+	conn.SetReadDeadline(time.Now().Add(eventsubWelcomeTimeout))
+	_, message, err := conn.ReadMessage()
+	if err != nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("waiting for welcome: %w", err)
+	}
+	msg, err := parseEventSubMessage(message)
+	if err != nil {
+		conn.Close()
+		return nil, nil, err
+	}
+	if msg.Metadata.MessageType != "session_welcome" || msg.Payload.Session == nil {
+		conn.Close()
+		return nil, nil, fmt.Errorf("expected session_welcome, got %q", msg.Metadata.MessageType)
+	}
+	return conn, msg.Payload.Session, nil
+}
+
+// runSession installs conn as the live connection, subscribes every
+// wanted channel when fresh is set, and reads until the connection
+// drops. Returns the reconnect URL if the server asked to migrate; the
+// old connection then stays open until the new session replaces it.
+func (c *EventSubClient) runSession(conn *websocket.Conn, session *eventsubSession, fresh bool) (reconnectURL, reason string) {
+	c.mu.Lock()
+	if c.stopped {
+		c.mu.Unlock()
+		conn.Close()
+		return "", "stopped"
+	}
+	if c.conn != nil && c.conn != conn {
+		c.conn.Close()
+	}
+	c.conn = conn
+	c.sessionID = session.ID
+	var pending []string
+	if fresh {
+		// New session: the old subscriptions died with it.
+		c.subs = make(map[string][]string)
+		for id := range c.channels {
+			pending = append(pending, id)
+		}
+	}
+	c.mu.Unlock()
+
+	if len(pending) > 0 {
+		c.logf("session %s established, subscribing %d channels", session.ID, len(pending))
+		go func() {
+			for _, id := range pending {
+				c.subscribeChannel(session.ID, id)
+			}
+		}()
+	} else {
+		c.logf("session %s established", session.ID)
+	}
+
+	keepalive := time.Duration(session.KeepaliveTimeoutSeconds) * time.Second
+	if keepalive <= 0 {
+		keepalive = 10 * time.Second
+	}
+	defer func() {
+		if reconnectURL != "" {
+			return
+		}
+		c.mu.Lock()
+		if c.conn == conn {
+			c.conn = nil
+			c.sessionID = ""
+		}
+		c.mu.Unlock()
+	}()
+
+	for {
+		conn.SetReadDeadline(time.Now().Add(keepalive + eventsubKeepaliveGrace))
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return "", err.Error()
+		}
+		msg, err := parseEventSubMessage(message)
+		if err != nil {
+			continue
+		}
+		switch msg.Metadata.MessageType {
+		case "session_keepalive":
+			// Deadline is refreshed at the top of the loop.
+		case "notification":
+			if ev, ok := eventsubNotificationEvent(msg); ok {
+				c.emitEvent(ev)
+			}
+		case "session_reconnect":
+			if msg.Payload.Session != nil && msg.Payload.Session.ReconnectURL != "" {
+				c.logf("server requested reconnect")
+				return msg.Payload.Session.ReconnectURL, "server requested reconnect"
+			}
+		case "revocation":
+			if sub := msg.Payload.Subscription; sub != nil {
+				c.logf("subscription %s for %s revoked (%s)", sub.Type, sub.Condition.BroadcasterUserID, sub.Status)
+			}
+		}
+	}
+}
+
+func (c *EventSubClient) setHealthy(healthy bool) {
+	c.mu.Lock()
+	changed := c.healthy != healthy
+	c.healthy = healthy
+	hook := c.onHealth
+	c.mu.Unlock()
+	if changed && hook != nil {
+		hook(healthy)
+	}
+}
+
+// subscribeChannel creates the stream subscriptions for one channel on
+// sessionID. On failure the channel is dropped from the wanted set and
+// handed to the subscribe error hook.
+func (c *EventSubClient) subscribeChannel(sessionID, channelID string) {
+	var ids []string
+	var subErr error
+	for _, typ := range eventsubStreamTypes {
+		id, err := c.createSubscription(sessionID, typ, channelID)
+		if err != nil {
+			subErr = fmt.Errorf("%s: %w", typ, err)
+			break
+		}
+		ids = append(ids, id)
+	}
+
+	c.mu.Lock()
+	current := c.sessionID == sessionID && c.channels[channelID]
+	if subErr == nil && current {
+		c.subs[channelID] = ids
+		c.mu.Unlock()
+		return
+	}
+	if subErr != nil {
+		delete(c.channels, channelID)
+	}
+	hook := c.onSubscribeErr
+	c.mu.Unlock()
+
+	// Either creation failed or the channel/session went away meanwhile;
+	// don't leave orphaned subscriptions counting against the budget.
+	for _, id := range ids {
+		_ = c.deleteSubscription(id)
+	}
+	if subErr != nil && hook != nil {
+		hook(channelID, subErr)
+	}
+}
+
+func (c *EventSubClient) createSubscription(sessionID, typ, channelID string) (string, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"type":      typ,
+		"version":   "1",
+		"condition": map[string]string{"broadcaster_user_id": channelID},
+		"transport": map[string]string{"method": "websocket", "session_id": sessionID},
+	})
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequest("POST", helixSubscriptionsURL, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	c.setHeaders(req)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		return "", ErrEventSubBudget
+	case resp.StatusCode == http.StatusConflict:
+		// Already subscribed on this session: reuse that subscription's
+		// ID so Unsubscribe can still delete it.
+		return c.findSubscription(sessionID, typ, channelID)
+	case resp.StatusCode != http.StatusAccepted:
+		return "", fmt.Errorf("helix status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+	}
+
+	var out struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return "", fmt.Errorf("decode subscription: %w", err)
+	}
+	if len(out.Data) == 0 {
+		return "", fmt.Errorf("empty subscription response")
+	}
+	return out.Data[0].ID, nil
+}
+
+// findSubscription looks up the ID of an existing subscription of typ
+// for channelID on sessionID, paging through the account's
+// subscriptions of that type.
+func (c *EventSubClient) findSubscription(sessionID, typ, channelID string) (string, error) {
+	cursor := ""
+	for {
+		q := url.Values{"type": {typ}}
+		if cursor != "" {
+			q.Set("after", cursor)
+		}
+		req, err := http.NewRequest("GET", helixSubscriptionsURL+"?"+q.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		c.setHeaders(req)
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return "", err
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("list subscriptions: helix status %d: %s", resp.StatusCode, bytes.TrimSpace(data))
+		}
+
+		var out struct {
+			Data []struct {
+				ID        string `json:"id"`
+				Condition struct {
+					BroadcasterUserID string `json:"broadcaster_user_id"`
+				} `json:"condition"`
+				Transport struct {
+					SessionID string `json:"session_id"`
+				} `json:"transport"`
+			} `json:"data"`
+			Pagination struct {
+				Cursor string `json:"cursor"`
+			} `json:"pagination"`
+		}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return "", fmt.Errorf("decode subscriptions: %w", err)
+		}
+		for _, sub := range out.Data {
+			if sub.Condition.BroadcasterUserID == channelID && sub.Transport.SessionID == sessionID {
+				return sub.ID, nil
+			}
+		}
+		if out.Pagination.Cursor == "" || out.Pagination.Cursor == cursor {
+			return "", fmt.Errorf("helix reported a conflicting %s subscription that could not be found", typ)
+		}
+		cursor = out.Pagination.Cursor
+	}
+}
+
+func (c *EventSubClient) deleteSubscription(id string) error {
+	if id == "" {
+		return nil
+	}
+	req, err := http.NewRequest("DELETE", helixSubscriptionsURL+"?id="+id, nil)
+	if err != nil {
+		return err
+	}
+	c.setHeaders(req)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("helix status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// setHeaders authenticates Helix requests. The user token was issued to
+// TVClientID, and Helix requires the Client-Id to match the token.
+func (c *EventSubClient) setHeaders(req *http.Request) {
+	req.Header.Set("Authorization", "Bearer "+c.authToken.get())
+	req.Header.Set("Client-Id", TVClientID)
+}
+
+func (c *EventSubClient) emitEvent(ev FarmerEvent) {
+	timer := time.NewTimer(eventSendTimeout)
+	defer timer.Stop()
+
+	ev.Queued = time.Now()
+	select {
+	case c.events <- ev:
+	case <-c.stopCh:
+	case <-timer.C:
+		c.logf("dropping event %d after blocked queue", ev.Type)
+	}
+}
+
+// eventsubMessage is the envelope of every EventSub WebSocket frame.
+type eventsubMessage struct {
+	Metadata struct {
+		MessageType      string `json:"message_type"`
+		SubscriptionType string `json:"subscription_type"`
+	} `json:"metadata"`
+	Payload struct {
+		Session      *eventsubSession `json:"session"`
+		Subscription *struct {
+			ID        string `json:"id"`
+			Type      string `json:"type"`
+			Status    string `json:"status"`
+			Condition struct {
+				BroadcasterUserID string `json:"broadcaster_user_id"`
+			} `json:"condition"`
+		} `json:"subscription"`
+		Event json.RawMessage `json:"event"`
+	} `json:"payload"`
+}
+
+type eventsubSession struct {
+	ID                      string `json:"id"`
+	Status                  string `json:"status"`
+	KeepaliveTimeoutSeconds int    `json:"keepalive_timeout_seconds"`
+	ReconnectURL            string `json:"reconnect_url"`
+}
+
+func parseEventSubMessage(data []byte) (*eventsubMessage, error) {
+	var msg eventsubMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, fmt.Errorf("decode eventsub message: %w", err)
+	}
+	return &msg, nil
+}
+
+// eventsubNotificationEvent maps a notification to the FarmerEvent the
+// PubSub video-playback handler would have emitted for it.
+func eventsubNotificationEvent(msg *eventsubMessage) (FarmerEvent, bool) {
+	var evt struct {
+		BroadcasterUserID string `json:"broadcaster_user_id"`
+	}
+	if len(msg.Payload.Event) == 0 || json.Unmarshal(msg.Payload.Event, &evt) != nil || evt.BroadcasterUserID == "" {
+		return FarmerEvent{}, false
+	}
+	switch msg.Metadata.SubscriptionType {
+	case "stream.online":
+		return FarmerEvent{Type: EventStreamUp, ChannelID: evt.BroadcasterUserID}, true
+	case "stream.offline":
+		return FarmerEvent{Type: EventStreamDown, ChannelID: evt.BroadcasterUserID}, true
+	}
+	return FarmerEvent{}, false
+}
@@ -0,0 +1,91 @@
+package twitch
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// TestEventSubNotification_MapsStreamEvents: stream.online/offline must
+// surface as the same FarmerEvents the PubSub video-playback handler
+// emits, so the event loop doesn't care which transport delivered them.
+func TestEventSubNotification_MapsStreamEvents(t *testing.T) {
+	cases := []struct {
+		subType string
+		want    FarmerEventType
+	}{
+		{"stream.online", EventStreamUp},
+		{"stream.offline", EventStreamDown},
+	}
+	for _, tc := range cases {
+		raw := `{"metadata":{"message_type":"notification","subscription_type":"` + tc.subType + `"},` +
+			`"payload":{"event":{"broadcaster_user_id":"1234","broadcaster_user_login":"alpha"}}}`
+		msg, err := parseEventSubMessage([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ev, ok := eventsubNotificationEvent(msg)
+		if !ok || ev.Type != tc.want || ev.ChannelID != "1234" {
+			t.Fatalf("%s: got %+v ok=%v", tc.subType, ev, ok)
+		}
+	}
+}
+
+// TestEventSubNotification_IgnoresOtherTypes: unknown subscription types
+// and events without a broadcaster ID produce nothing.
+func TestEventSubNotification_IgnoresOtherTypes(t *testing.T) {
+	for _, raw := range []string{
+		`{"metadata":{"message_type":"notification","subscription_type":"channel.follow"},"payload":{"event":{"broadcaster_user_id":"1"}}}`,
+		`{"metadata":{"message_type":"notification","subscription_type":"stream.online"},"payload":{"event":{}}}`,
+	} {
+		msg, err := parseEventSubMessage([]byte(raw))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ev, ok := eventsubNotificationEvent(msg); ok {
+			t.Fatalf("unexpected event %+v from %s", ev, raw)
+		}
+	}
+}
+
+// TestEventSubSession_ParsesWelcomeAndReconnect: the session block carries
+// the keepalive used for the read deadline and the migration URL.
+func TestEventSubSession_ParsesWelcomeAndReconnect(t *testing.T) {
+	msg, err := parseEventSubMessage([]byte(`{"metadata":{"message_type":"session_reconnect"},` +
+		`"payload":{"session":{"id":"abc","status":"reconnecting","keepalive_timeout_seconds":10,"reconnect_url":"wss://example/ws?id=abc"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := msg.Payload.Session
+	if s == nil || s.ID != "abc" || s.KeepaliveTimeoutSeconds != 10 || s.ReconnectURL != "wss://example/ws?id=abc" {
+		t.Fatalf("session = %+v", s)
+	}
+}
+
+// TestCreateSubscription_ConflictReusesExisting: a 409 means the
+// subscription already exists on the session; its ID is looked up so
+// Unsubscribe can delete it later, instead of recording "".
+func TestCreateSubscription_ConflictReusesExisting(t *testing.T) {
+	c := NewEventSubClient("tok", nil, nil)
+	c.httpClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		status, body := http.StatusConflict, `{"error":"Conflict"}`
+		if r.Method == http.MethodGet {
+			status = http.StatusOK
+			if r.URL.Query().Get("after") == "" {
+				body = `{"data":[{"id":"other","condition":{"broadcaster_user_id":"1"},"transport":{"session_id":"old"}}],"pagination":{"cursor":"p2"}}`
+			} else {
+				body = `{"data":[{"id":"sub-1","condition":{"broadcaster_user_id":"1"},"transport":{"session_id":"sess"}}],"pagination":{}}`
+			}
+		}
+		return &http.Response{StatusCode: status, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	id, err := c.createSubscription("sess", "stream.online", "1")
+	if err != nil || id != "sub-1" {
+		t.Fatalf("createSubscription = %q, %v; want sub-1", id, err)
+	}
+	if _, err := c.createSubscription("sess", "stream.online", "2"); err == nil {
+		t.Fatal("conflict without a matching subscription should fail")
+	}
+}
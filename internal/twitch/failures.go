@@ -0,0 +1,170 @@
+package twitch
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// maxFailureBody caps the response body kept in a ClaimFailure.
+const maxFailureBody = 16 * 1024
+
+// redacted replaces secrets in a ClaimFailure.
+const redacted = "[redacted]"
+
+// ClaimFailure is the forensic record of a failed claim mutation, meant
+// to be attached to bug reports. Secrets are stripped: the auth header
+// and any token-like field in the request or response are redacted.
+type ClaimFailure struct {
+	// ID correlates the record with the log line and the error the
+	// caller got back (which ends in "[ref ID]").
+	ID        string            `json:"id"`
+	Time      time.Time         `json:"time"`
+	Operation string            `json:"operation"`
+	Session   string            `json:"session"` // Client-Session-Id of the GQL client
+	Error     string            `json:"error"`
+	Headers   map[string]string `json:"headers"`
+	Request   json.RawMessage   `json:"request,omitempty"`
+	Status    int               `json:"status,omitempty"` // 0 = no response
+	Response  string            `json:"response,omitempty"`
+	Truncated bool              `json:"truncated,omitempty"` // sanitized response cut at maxFailureBody
+}
+
+// gqlExchange is the raw request and response of one GQL call, kept
+// for failure capture.
+type gqlExchange struct {
+	operation string
+	request   []byte
+	status    int
+	response  []byte
+}
+
+// claimQuery is query for claim mutations: it also returns the exchange,
+// to be handed to claimFailed if the claim fails.
+func (g *GQLClient) claimQuery(req *GQLRequest, out interface{}) (*gqlExchange, error) {
+	g.health.claim(req.OperationName, false)
+	resp, x, err := g.doExchange(req)
+	if err != nil {
+		return x, err
+	}
+	return x, resp.decode(out)
+}
+
+// claimFailed reports a failed claim to OnClaimFailure and returns err
+// tagged with the record's ID. Without a hook err is returned as is.
+func (g *GQLClient) claimFailed(x *gqlExchange, err error) error {
+	g.health.claim(x.operation, true)
+	if g.OnClaimFailure == nil {
+		return err
+	}
+	fl := g.newClaimFailure(x, err, time.Now())
+	g.OnClaimFailure(fl)
+	return fmt.Errorf("%w [ref %s]", err, fl.ID)
+}
+
+// newClaimFailure builds the sanitized record of a failed exchange.
+func (g *GQLClient) newClaimFailure(x *gqlExchange, err error, now time.Time) ClaimFailure {
+	fl := ClaimFailure{
+		ID:        newFailureID(),
+		Time:      now,
+		Operation: x.operation,
+		Session:   g.clientSessionID,
+		Error:     g.scrub(err.Error()),
+		Headers:   g.sanitizedHeaders(),
+		Status:    x.status,
+	}
+	if len(x.request) > 0 {
+		fl.Request = json.RawMessage(g.scrub(string(sanitizeJSON(x.request))))
+	}
+	// Redact first: cut JSON no longer parses, so sanitizeJSON would
+	// pass a secret in the kept part straight through.
+	body := g.scrub(string(sanitizeJSON(x.response)))
+	if len(body) > maxFailureBody {
+		body, fl.Truncated = body[:maxFailureBody], true
+	}
+	fl.Response = body
+	return fl
+}
+
+// sanitizedHeaders returns the headers GQL requests are sent with, the
+// auth header redacted.
+func (g *GQLClient) sanitizedHeaders() map[string]string {
+	req, err := http.NewRequest("POST", gqlURL, nil)
+	if err != nil {
+		return nil
+	}
+	g.setHeaders(req)
+	out := make(map[string]string, len(req.Header))
+	for k := range req.Header {
+		if isSecretKey(k) {
+			out[k] = redacted
+			continue
+		}
+		out[k] = req.Header.Get(k)
+	}
+	return out
+}
+
+// scrub replaces any occurrence of the auth token in s, in case Twitch
+// echoes it back in an error.
+func (g *GQLClient) scrub(s string) string {
+	token := g.authToken.get()
+	if token == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, token, redacted)
+}
+
+// sanitizeJSON redacts the values of token-like keys anywhere in a JSON
+// document. Anything that isn't valid JSON is returned unchanged.
+func sanitizeJSON(b []byte) []byte {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return b
+	}
+	out, err := json.Marshal(redactSecrets(v))
+	if err != nil {
+		return b
+	}
+	return out
+}
+
+func redactSecrets(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, val := range t {
+			if isSecretKey(k) {
+				t[k] = redacted
+			} else {
+				t[k] = redactSecrets(val)
+			}
+		}
+	case []interface{}:
+		for i, val := range t {
+			t[i] = redactSecrets(val)
+		}
+	}
+	return v
+}
+
+// isSecretKey reports whether a header or JSON key names a credential.
+func isSecretKey(k string) bool {
+	k = strings.ToLower(k)
+	for _, s := range []string{"auth", "token", "password", "secret", "integrity", "signature"} {
+		if strings.Contains(k, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// newFailureID returns 8 random hex characters.
+func newFailureID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,62 @@
+package twitch
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestClaimFailureSanitized checks that a failure record keeps the
+// exchange but none of the credentials.
+func TestClaimFailureSanitized(t *testing.T) {
+	g := &GQLClient{authToken: tokenBox{token: "tok123"}, clientSessionID: "sess"}
+	x := &gqlExchange{
+		operation: "ClaimCommunityPoints",
+		request:   []byte(`{"operationName":"ClaimCommunityPoints","variables":{"input":{"claimID":"c1","integrityToken":"abc"}}}`),
+		status:    200,
+		response:  []byte(`{"errors":[{"message":"bad token tok123"}],"data":{"authorization":{"value":"x"}}}`),
+	}
+	fl := g.newClaimFailure(x, errors.New("gql error: bad token tok123"), time.Unix(0, 0))
+
+	if len(fl.ID) != 8 || fl.Operation != "ClaimCommunityPoints" || fl.Session != "sess" || fl.Status != 200 {
+		t.Fatalf("record = %+v", fl)
+	}
+	if fl.Headers["Authorization"] != redacted || fl.Headers["Client-Id"] != TVClientID {
+		t.Fatalf("headers = %v", fl.Headers)
+	}
+	all := fl.Error + string(fl.Request) + fl.Response
+	for _, leak := range []string{"tok123", "abc", `"x"`} {
+		if strings.Contains(all, leak) {
+			t.Fatalf("record leaks %q: %s", leak, all)
+		}
+	}
+	if !strings.Contains(string(fl.Request), `"claimID":"c1"`) {
+		t.Fatalf("request lost its variables: %s", fl.Request)
+	}
+
+	g.OnClaimFailure = func(ClaimFailure) {}
+	err := g.claimFailed(x, ErrClaimNotFound)
+	if !errors.Is(err, ErrClaimNotFound) || !strings.Contains(err.Error(), "[ref ") {
+		t.Fatalf("claimFailed = %v", err)
+	}
+}
+
+// TestClaimFailureLargeResponseSanitized: a response over maxFailureBody
+// is redacted before it's cut, so secrets near the start don't survive.
+func TestClaimFailureLargeResponseSanitized(t *testing.T) {
+	g := &GQLClient{authToken: tokenBox{token: "tok123"}}
+	pad := strings.Repeat("a", maxFailureBody)
+	x := &gqlExchange{
+		operation: "ClaimCommunityPoints",
+		response:  []byte(`{"data":{"authorization":{"value":"secret-x"}},"pad":"` + pad + `"}`),
+	}
+	fl := g.newClaimFailure(x, errors.New("gql error"), time.Unix(0, 0))
+
+	if !fl.Truncated || len(fl.Response) != maxFailureBody {
+		t.Fatalf("truncated = %v, len = %d", fl.Truncated, len(fl.Response))
+	}
+	if strings.Contains(fl.Response, "secret-x") || !strings.Contains(fl.Response, redacted) {
+		t.Fatalf("response not redacted: %.200s", fl.Response)
+	}
+}
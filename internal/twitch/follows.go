@@ -0,0 +1,106 @@
+package twitch
+
+import (
+	"fmt"
+	"time"
+)
+
+const (
+	queryFollowedChannels = `query FollowedChannels($first: Int!, $after: Cursor) {
+		currentUser {
+			follows(first: $first, after: $after) {
+				edges {
+					cursor followedAt
+					node {
+						id login displayName
+						stream { id type createdAt viewersCount game { id displayName } freeformTags { name } }
+					}
+				}
+				pageInfo { hasNextPage }
+			}
+		}
+	}`
+
+	// followsPageSize is the largest page Twitch serves for follows.
+	followsPageSize = 100
+	// maxFollowsPages caps the walk at 5000 follows.
+	maxFollowsPages = 50
+)
+
+// FollowedChannel is a channel the logged-in user follows, with its
+// live status as of the query.
+type FollowedChannel struct {
+	ChannelInfo
+	FollowedAt time.Time
+}
+
+type gqlFollows struct {
+	Edges []struct {
+		Cursor     string   `json:"cursor"`
+		FollowedAt gqlTime  `json:"followedAt"`
+		Node       *gqlUser `json:"node"`
+	} `json:"edges"`
+	PageInfo struct {
+		HasNextPage bool `json:"hasNextPage"`
+	} `json:"pageInfo"`
+}
+
+// GetFollowedChannels returns every channel the logged-in user follows,
+// newest follow first (Twitch's order).
+func (g *GQLClient) GetFollowedChannels() ([]FollowedChannel, error) {
+	var out []FollowedChannel
+	var after interface{}
+	for page := 0; page < maxFollowsPages; page++ {
+		req := &GQLRequest{
+			Query: queryFollowedChannels,
+			Variables: map[string]interface{}{
+				"first": followsPageSize,
+				"after": after,
+			},
+		}
+		var data struct {
+			CurrentUser *struct {
+				Follows *gqlFollows `json:"follows"`
+			} `json:"currentUser"`
+		}
+		if err := g.query(req, &data); err != nil {
+			return nil, fmt.Errorf("get followed channels: %w", err)
+		}
+		if data.CurrentUser == nil {
+			return nil, fmt.Errorf("get followed channels: invalid auth token or user not found")
+		}
+		follows := data.CurrentUser.Follows
+		if follows == nil {
+			break
+		}
+		for _, e := range follows.Edges {
+			if e.Node == nil || e.Node.Login == "" {
+				continue
+			}
+			out = append(out, FollowedChannel{ChannelInfo: *e.Node.channelInfo(), FollowedAt: e.FollowedAt.Time})
+		}
+		n := len(follows.Edges)
+		if !follows.PageInfo.HasNextPage || n == 0 || follows.Edges[n-1].Cursor == "" {
+			break
+		}
+		after = follows.Edges[n-1].Cursor
+	}
+	return out, nil
+}
+
+// FilterFollows returns the follows worth importing: only live ones when
+// liveOnly is set, and only those followed at least minAge before now
+// (0 = any age). A follow without a timestamp passes the age check.
+func FilterFollows(follows []FollowedChannel, liveOnly bool, minAge time.Duration, now time.Time) []FollowedChannel {
+	var out []FollowedChannel
+	for _, f := range follows {
+		if liveOnly && !f.IsLive {
+			continue
+		}
+		if minAge > 0 && !f.FollowedAt.IsZero() && now.Sub(f.FollowedAt) < minAge {
+			continue
+		}
+		out = append(out, f)
+	}
+	return out
+}
@@ -0,0 +1,52 @@
+package twitch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFilterFollows(t *testing.T) {
+	now := time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)
+	follow := func(login string, live bool, daysAgo int) FollowedChannel {
+		f := FollowedChannel{ChannelInfo: ChannelInfo{Login: login, IsLive: live}}
+		if daysAgo >= 0 {
+			f.FollowedAt = now.AddDate(0, 0, -daysAgo)
+		}
+		return f
+	}
+	all := []FollowedChannel{
+		follow("old_live", true, 400),
+		follow("old_offline", false, 400),
+		follow("new_live", true, 2),
+		follow("no_date", false, -1),
+	}
+
+	cases := []struct {
+		name     string
+		liveOnly bool
+		minAge   time.Duration
+		want     []string
+	}{
+		{"all", false, 0, []string{"old_live", "old_offline", "new_live", "no_date"}},
+		{"live only", true, 0, []string{"old_live", "new_live"}},
+		{"min age", false, 30 * 24 * time.Hour, []string{"old_live", "old_offline", "no_date"}},
+		{"both", true, 30 * 24 * time.Hour, []string{"old_live"}},
+	}
+	for _, c := range cases {
+		got := FilterFollows(all, c.liveOnly, c.minAge, now)
+		var logins []string
+		for _, f := range got {
+			logins = append(logins, f.Login)
+		}
+		if len(logins) != len(c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, logins, c.want)
+			continue
+		}
+		for i := range logins {
+			if logins[i] != c.want[i] {
+				t.Errorf("%s: got %v, want %v", c.name, logins, c.want)
+				break
+			}
+		}
+	}
+}
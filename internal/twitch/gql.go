@@ -3,6 +3,7 @@ package twitch
 import (
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/rand"
 	"encoding/base64"
 	"encoding/hex"
@@ -30,7 +31,7 @@ const (
 	queryGetChannelInfo = `query GetChannelInfo($login: String!) {
 		user(login: $login) {
 			id login displayName
-			stream { id createdAt viewersCount game { id displayName } }
+			stream { id type createdAt viewersCount game { id displayName } freeformTags { name } }
 		}
 	}`
 
@@ -41,14 +42,14 @@ const (
 	queryGetChannelInfoByID = `query GetChannelInfoByID($id: ID!) {
 		user(id: $id) {
 			id login displayName
-			stream { id createdAt viewersCount game { id displayName } }
+			stream { id type createdAt viewersCount game { id displayName } freeformTags { name } }
 		}
 	}`
 
-	queryChannelPointsBalance = `query ChannelPointsContext($channelLogin: String!) {
+	queryChannelPointsContext = `query ChannelPointsContext($channelLogin: String!) {
 		community(name: $channelLogin) {
 			channel {
-				self { communityPoints { balance { availablePoints } } }
+				self { communityPoints { balance availableClaim { id } activeMultipliers { factor reasonCode } } }
 			}
 		}
 	}`
@@ -70,6 +71,9 @@ const (
 		}
 	}`
 
+	// Persisted query hash for CommunityMomentCallout_Claim (Moments).
+	claimMomentHash = "e2d67415aead910f7f9ceb45a77b750a1e1d9622c936d832328a0689e054db62"
+
 	// Persisted query hash for JoinRaid (used as fallback if raw mutation fails)
 	joinRaidHash = "c6a332a86d1087fbbb1a8623aa01bd1313d2386e7c63be60fdb2d1901f01a4ae"
 
@@ -156,16 +160,18 @@ func (g *GQLClient) SendMinuteWatched(channelID, channelLogin, broadcastID, game
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		SendSpadeEvents *struct {
+			StatusCode int `json:"statusCode"`
+		} `json:"sendSpadeEvents"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return fmt.Errorf("send minute watched: %w", err)
 	}
-	sse, ok := resp.Data["sendSpadeEvents"].(map[string]interface{})
-	if !ok || sse == nil {
+	if data.SendSpadeEvents == nil {
 		return fmt.Errorf("no sendSpadeEvents in response")
 	}
-	status := getInt(sse, "statusCode")
-	if status != 204 {
+	if status := data.SendSpadeEvents.StatusCode; status != 204 {
 		return fmt.Errorf("sendSpadeEvents returned statusCode %d (need 204)", status)
 	}
 	return nil
@@ -198,16 +204,20 @@ func (g *GQLClient) GetPlaybackAccessToken(login string) (value, signature strin
 			"playerType": "site",
 		},
 	}
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		StreamPlaybackAccessToken *struct {
+			Value     string `json:"value"`
+			Signature string `json:"signature"`
+		} `json:"streamPlaybackAccessToken"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return "", "", fmt.Errorf("playback access token: %w", err)
 	}
-	spat, ok := resp.Data["streamPlaybackAccessToken"].(map[string]interface{})
-	if !ok || spat == nil {
+	spat := data.StreamPlaybackAccessToken
+	if spat == nil {
 		return "", "", fmt.Errorf("no streamPlaybackAccessToken in response")
 	}
-	value = getString(spat, "value")
-	signature = getString(spat, "signature")
+	value, signature = spat.Value, spat.Signature
 	if value == "" || signature == "" {
 		return "", "", fmt.Errorf("empty token or signature")
 	}
@@ -216,7 +226,7 @@ func (g *GQLClient) GetPlaybackAccessToken(login string) (value, signature strin
 
 // GQLClient handles all Twitch GQL API calls.
 type GQLClient struct {
-	authToken       string
+	authToken       tokenBox
 	httpClient      *http.Client
 	deviceID        string // X-Device-Id header (32 alphanumeric, persisted per session)
 	clientSessionID string // Client-Session-Id header (16 hex bytes, per session)
@@ -226,6 +236,13 @@ type GQLClient struct {
 	// On Windows log.Printf goes to io.Discard, so we can't use it for
 	// anything the user needs to see.
 	DiagLog func(format string, args ...interface{})
+	// OnClaimFailure, if set, receives a sanitized record of every failed
+	// claim mutation (channel points, moments, drops). Set by callers
+	// AFTER construction.
+	OnClaimFailure func(ClaimFailure)
+
+	health gqlHealth // request / error / claim counters for Health
+	gate   requestGate // requests in flight, for Shutdown
 }
 
 // SetUserID stores the logged-in user's Twitch ID. Required before any
@@ -251,14 +268,15 @@ func NewGQLClient(authToken string) *GQLClient {
 		deviceID = generateDeviceID()
 	}
 	return &GQLClient{
-		authToken: authToken,
-		// 30s timeout on every Twitch request. Without this, a hung
+		authToken: tokenBox{token: authToken},
+		// Timeout on every Twitch request (30s, longer on the flaky
+		// network profile). Without this, a hung
 		// connection (Twitch backend issues, DNS hiccup, mid-flight
 		// reset) blocks the calling goroutine indefinitely. ProcessDrops,
 		// Startup channel-resolve, Claim, CurrentDropSession poll all run
 		// through this client — a stuck request would wedge the whole
 		// drops loop until the process is killed.
-		httpClient:      &http.Client{Timeout: 30 * time.Second},
+		httpClient:      NewHTTPClient(ActiveNetwork().GQLTimeout),
 		deviceID:        deviceID,
 		clientSessionID: generateSessionID(),
 	}
@@ -268,7 +286,7 @@ func NewGQLClient(authToken string) *GQLClient {
 // `unique_id` cookie that Twitch sets in the response. Drop anti-cheat trusts
 // IDs that Twitch itself issued — locally-generated random ones get flagged.
 func fetchTwitchUniqueID() string {
-	client := &http.Client{Timeout: 10 * time.Second}
+	client := NewHTTPClient(10 * time.Second)
 	req, err := http.NewRequest("GET", "https://www.twitch.tv", nil)
 	if err != nil {
 		return ""
@@ -296,60 +314,110 @@ func (g *GQLClient) DeviceID() string {
 	return g.deviceID
 }
 
+// post sends a GQL body, resending it up to NetworkProfile.GQLRetries
+// times when no HTTP response came back at all (dial error, reset,
+// timeout). Any response, whatever its status, is returned as is. The
+// mutations we send are idempotent or carry a transaction ID, so a
+// resend after a lost response is harmless.
+func (g *GQLClient) post(ctx context.Context, body []byte) (*http.Response, error) {
+	net := ActiveNetwork()
+	for attempt := 0; ; attempt++ {
+		httpReq, err := http.NewRequestWithContext(ctx, "POST", gqlURL, bytes.NewReader(body))
+		if err != nil {
+			return nil, fmt.Errorf("create http request: %w", err)
+		}
+		g.setHeaders(httpReq)
+
+		resp, err := g.httpClient.Do(httpReq)
+		if err == nil || attempt >= net.GQLRetries {
+			return resp, err
+		}
+		if sleep(ctx, net.GQLRetryDelay) != nil {
+			return nil, err
+		}
+	}
+}
+
+// Shutdown stops the client: new requests fail with ErrShutdown, those
+// in flight may finish until ctx is done, and the rest are cancelled.
+// Returns ctx's error if any had to be cut off.
+func (g *GQLClient) Shutdown(ctx context.Context) error {
+	return g.gate.shutdown(ctx)
+}
+
 func (g *GQLClient) do(req *GQLRequest) (*GQLResponse, error) {
-	body, err := json.Marshal(req)
+	resp, _, err := g.doExchange(req)
+	return resp, err
+}
+
+// doExchange is do, also returning the raw request and response as far
+// as they got. The exchange is never nil.
+func (g *GQLClient) doExchange(req *GQLRequest) (*GQLResponse, *gqlExchange, error) {
+	ctx, err := g.gate.enter()
 	if err != nil {
-		return nil, fmt.Errorf("marshal gql request: %w", err)
+		return nil, &gqlExchange{operation: req.OperationName}, err
 	}
+	defer g.gate.leave()
+	resp, x, err := g.exchange(ctx, req)
+	g.health.observe(req.OperationName, err)
+	return resp, x, err
+}
 
-	httpReq, err := http.NewRequest("POST", gqlURL, bytes.NewReader(body))
+func (g *GQLClient) exchange(ctx context.Context, req *GQLRequest) (*GQLResponse, *gqlExchange, error) {
+	x := &gqlExchange{operation: req.OperationName}
+	body, err := json.Marshal(req)
 	if err != nil {
-		return nil, fmt.Errorf("create http request: %w", err)
+		return nil, x, fmt.Errorf("marshal gql request: %w", err)
 	}
+	x.request = body
 
-	g.setHeaders(httpReq)
-
-	resp, err := g.httpClient.Do(httpReq)
+	resp, err := g.post(ctx, body)
 	if err != nil {
-		return nil, fmt.Errorf("gql request: %w", err)
+		return nil, x, fmt.Errorf("gql request: %w", err)
 	}
 	defer resp.Body.Close()
+	x.status = resp.StatusCode
 
 	respBody, err := io.ReadAll(io.LimitReader(resp.Body, maxGQLBody))
+	x.response = respBody
 	if err != nil {
-		return nil, fmt.Errorf("read gql response: %w", err)
+		return nil, x, fmt.Errorf("read gql response: %w", err)
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("gql status %d: %s", resp.StatusCode, string(respBody))
+		return nil, x, fmt.Errorf("gql status %d: %s", resp.StatusCode, string(respBody))
 	}
 
 	var gqlResp GQLResponse
 	if err := json.Unmarshal(respBody, &gqlResp); err != nil {
-		return nil, fmt.Errorf("unmarshal gql response: %w", err)
+		return nil, x, fmt.Errorf("unmarshal gql response: %w", err)
 	}
 
 	if len(gqlResp.Errors) > 0 {
-		return &gqlResp, fmt.Errorf("gql error: %s", gqlResp.Errors[0].Message)
+		return &gqlResp, x, fmt.Errorf("gql error: %s", gqlResp.Errors[0].Message)
 	}
 
-	return &gqlResp, nil
+	return &gqlResp, x, nil
 }
 
 func (g *GQLClient) doBatch(reqs []GQLRequest) ([]GQLResponse, error) {
-	body, err := json.Marshal(reqs)
+	ctx, err := g.gate.enter()
 	if err != nil {
-		return nil, fmt.Errorf("marshal gql batch: %w", err)
+		return nil, err
 	}
+	defer g.gate.leave()
+	resps, err := g.sendBatch(ctx, reqs)
+	g.health.observe("batch", err)
+	return resps, err
+}
 
-	httpReq, err := http.NewRequest("POST", gqlURL, bytes.NewReader(body))
+func (g *GQLClient) sendBatch(ctx context.Context, reqs []GQLRequest) ([]GQLResponse, error) {
+	body, err := json.Marshal(reqs)
 	if err != nil {
-		return nil, fmt.Errorf("create http request: %w", err)
+		return nil, fmt.Errorf("marshal gql batch: %w", err)
 	}
 
-	g.setHeaders(httpReq)
-
-	resp, err := g.httpClient.Do(httpReq)
+	resp, err := g.post(ctx, body)
 	if err != nil {
 		return nil, fmt.Errorf("gql batch request: %w", err)
 	}
@@ -378,25 +446,20 @@ func (g *GQLClient) GetUserInfo() (*UserInfo, error) {
 		Query: queryGetUserInfo,
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		CurrentUser *gqlUser `json:"currentUser"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return nil, fmt.Errorf("get user info: %w", err)
 	}
-
-	currentUser, ok := resp.Data["currentUser"]
-	if !ok || currentUser == nil {
+	if data.CurrentUser == nil {
 		return nil, fmt.Errorf("invalid auth token or user not found")
 	}
 
-	userMap, ok := currentUser.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected user data format")
-	}
-
 	return &UserInfo{
-		ID:          getString(userMap, "id"),
-		Login:       getString(userMap, "login"),
-		DisplayName: getString(userMap, "displayName"),
+		ID:          data.CurrentUser.ID,
+		Login:       data.CurrentUser.Login,
+		DisplayName: data.CurrentUser.DisplayName,
 	}, nil
 }
 
@@ -442,54 +505,32 @@ func (g *GQLClient) GetChannelInfos(logins []string) []*ChannelInfo {
 // GetChannelInfo returns channel info including live status.
 func (g *GQLClient) GetChannelInfo(login string) (*ChannelInfo, error) {
 	login = strings.ToLower(login)
+	req := channelInfoRequest(login)
 
-	req := &GQLRequest{
-		Query: queryGetChannelInfo,
-		Variables: map[string]interface{}{
-			"login": login,
-		},
-	}
-
-	resp, err := g.do(req)
-	if err != nil {
+	var data channelInfoData
+	if err := g.query(&req, &data); err != nil {
 		return nil, fmt.Errorf("get channel info: %w", err)
 	}
-
-	info := &ChannelInfo{Login: login}
-
-	user, ok := resp.Data["user"]
-	if !ok || user == nil {
-		return nil, fmt.Errorf("channel %q not found", login)
+	if data.User == nil {
+		return nil, fmt.Errorf("%w: %q", ErrChannelNotFound, login)
 	}
 
-	userMap, ok := user.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected channel data format")
-	}
-
-	info.ID = getString(userMap, "id")
-	info.DisplayName = getString(userMap, "displayName")
+	info := data.User.channelInfo()
+	info.Login = login
+	return info, nil
+}
 
-	if stream, ok := userMap["stream"]; ok && stream != nil {
-		if streamMap, ok := stream.(map[string]interface{}); ok {
-			info.IsLive = true
-			info.BroadcastID = getString(streamMap, "id")
-			info.ViewerCount = getInt(streamMap, "viewersCount")
-			if cs := getString(streamMap, "createdAt"); cs != "" {
-				if t, err := time.Parse(time.RFC3339, cs); err == nil {
-					info.StreamCreatedAt = t
-				}
-			}
-			if game, ok := streamMap["game"]; ok && game != nil {
-				if gameMap, ok := game.(map[string]interface{}); ok {
-					info.GameName = getString(gameMap, "displayName")
-					info.GameID = getString(gameMap, "id")
-				}
-			}
-		}
+func channelInfoRequest(login string) GQLRequest {
+	return GQLRequest{
+		Query: queryGetChannelInfo,
+		Variables: map[string]interface{}{
+			"login": login,
+		},
 	}
+}
 
-	return info, nil
+type channelInfoData struct {
+	User *gqlUser `json:"user"`
 }
 
 // GetChannelInfoByID resolves full channel info by ID (handles renames).
@@ -501,47 +542,17 @@ func (g *GQLClient) GetChannelInfoByID(channelID string) (*ChannelInfo, error) {
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		User *gqlUser `json:"user"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return nil, fmt.Errorf("get channel info by id: %w", err)
 	}
-
-	user, ok := resp.Data["user"]
-	if !ok || user == nil {
+	if data.User == nil {
 		return nil, fmt.Errorf("channel ID %s not found", channelID)
 	}
 
-	userMap, ok := user.(map[string]interface{})
-	if !ok {
-		return nil, fmt.Errorf("unexpected data format")
-	}
-
-	info := &ChannelInfo{
-		ID:          getString(userMap, "id"),
-		Login:       getString(userMap, "login"),
-		DisplayName: getString(userMap, "displayName"),
-	}
-
-	if stream, ok := userMap["stream"]; ok && stream != nil {
-		if streamMap, ok := stream.(map[string]interface{}); ok {
-			info.IsLive = true
-			info.BroadcastID = getString(streamMap, "id")
-			info.ViewerCount = getInt(streamMap, "viewersCount")
-			if cs := getString(streamMap, "createdAt"); cs != "" {
-				if t, err := time.Parse(time.RFC3339, cs); err == nil {
-					info.StreamCreatedAt = t
-				}
-			}
-			if game, ok := streamMap["game"]; ok && game != nil {
-				if gameMap, ok := game.(map[string]interface{}); ok {
-					info.GameName = getString(gameMap, "displayName")
-					info.GameID = getString(gameMap, "id")
-				}
-			}
-		}
-	}
-
-	return info, nil
+	return data.User.channelInfo(), nil
 }
 
 // GetChannelNameByID resolves a channel's display name from its ID.
@@ -553,24 +564,24 @@ func (g *GQLClient) GetChannelNameByID(channelID string) (string, error) {
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		User *gqlUser `json:"user"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return "", fmt.Errorf("get channel name: %w", err)
 	}
-
-	user, ok := resp.Data["user"]
-	if !ok || user == nil {
+	if data.User == nil {
 		return "", fmt.Errorf("channel %s not found", channelID)
 	}
 
-	userMap, ok := user.(map[string]interface{})
-	if !ok {
-		return "", fmt.Errorf("unexpected data format")
-	}
-
-	return getString(userMap, "displayName"), nil
+	return data.User.DisplayName, nil
 }
 
+// ErrChannelNotFound is returned (wrapped) by the login lookups when
+// Twitch knows no such user — the channel was deleted, banned, or its
+// streamer changed the login. Look it up by ID to tell which.
+var ErrChannelNotFound = errors.New("channel not found")
+
 // ErrClaimNotFound is returned (wrapped) by ClaimCommunityPoints when
 // Twitch reports NOT_FOUND for a claim — meaning the claim was already
 // consumed (manual click in the web UI, or claim window expired). It's
@@ -592,36 +603,70 @@ func (g *GQLClient) ClaimCommunityPoints(channelID, claimID string) error {
 		},
 	}
 
-	resp, err := g.do(req)
+	var data struct {
+		ClaimCommunityPoints *struct {
+			Error *gqlError `json:"error"`
+		} `json:"claimCommunityPoints"`
+	}
+	x, err := g.claimQuery(req, &data)
 	if err != nil {
-		return fmt.Errorf("%w", err)
+		return g.claimFailed(x, err)
 	}
 
 	// Check for claim-specific error in response data
-	if resp != nil {
-		if cpData, ok := resp.Data["claimCommunityPoints"]; ok && cpData != nil {
-			if cpMap, ok := cpData.(map[string]interface{}); ok {
-				if errData, ok := cpMap["error"]; ok && errData != nil {
-					if errMap, ok := errData.(map[string]interface{}); ok {
-						code := getString(errMap, "code")
-						if code != "" {
-							// NOT_FOUND is terminal — claim already consumed
-							// or expired. Wrap the sentinel so callers can
-							// errors.Is and skip the retry loop.
-							if code == "NOT_FOUND" {
-								return fmt.Errorf("claim rejected: %s: %w", code, ErrClaimNotFound)
-							}
-							return fmt.Errorf("claim rejected: %s", code)
-						}
-					}
-				}
-			}
+	if cp := data.ClaimCommunityPoints; cp != nil {
+		switch code := cp.Error.errorCode(); code {
+		case "":
+		case "NOT_FOUND":
+			// NOT_FOUND is terminal — claim already consumed or
+			// expired. Wrap the sentinel so callers can errors.Is
+			// and skip the retry loop.
+			return g.claimFailed(x, fmt.Errorf("claim rejected: %s: %w", code, ErrClaimNotFound))
+		default:
+			return g.claimFailed(x, fmt.Errorf("claim rejected: %s", code))
 		}
 	}
 
 	return nil
 }
 
+// ClaimMoment claims an active Moment callout. Twitch reports
+// per-claim failures in the response payload (already claimed, window
+// closed) rather than as a GQL error, so both are surfaced as errors.
+func (g *GQLClient) ClaimMoment(momentID string) error {
+	req := &GQLRequest{
+		OperationName: "CommunityMomentCallout_Claim",
+		Variables: map[string]interface{}{
+			"input": map[string]interface{}{
+				"momentID": momentID,
+			},
+		},
+		Extensions: &GQLExtensions{
+			PersistedQuery: &PersistedQuery{
+				Version:    1,
+				SHA256Hash: claimMomentHash,
+			},
+		},
+	}
+
+	var data struct {
+		ClaimCommunityMoment *struct {
+			Error *gqlError `json:"error"`
+		} `json:"claimCommunityMoment"`
+	}
+	x, err := g.claimQuery(req, &data)
+	if err != nil {
+		return g.claimFailed(x, fmt.Errorf("claim moment: %w", err))
+	}
+
+	if cm := data.ClaimCommunityMoment; cm != nil {
+		if code := cm.Error.errorCode(); code != "" {
+			return g.claimFailed(x, fmt.Errorf("moment claim rejected: %s", code))
+		}
+	}
+	return nil
+}
+
 // JoinRaid joins an active raid. Tries persisted query hash first, falls back to raw mutation.
 func (g *GQLClient) JoinRaid(raidID string) error {
 	variables := map[string]interface{}{
@@ -660,54 +705,156 @@ func (g *GQLClient) JoinRaid(raidID string) error {
 
 // GetChannelPointsBalance returns the current points balance for a channel.
 func (g *GQLClient) GetChannelPointsBalance(channelLogin string) (int, error) {
-	req := &GQLRequest{
-		Query: queryChannelPointsBalance,
+	ctx, err := g.GetChannelPointsContext(channelLogin)
+	if err != nil {
+		return 0, err
+	}
+	return ctx.Balance, nil
+}
+
+// GetChannelPointsContext returns the balance and any pending bonus
+// chest for a channel. AvailableClaimID is set when a chest is waiting
+// to be claimed — including chests that spawned while we weren't
+// connected to PubSub, which never produce a claim-available event.
+func (g *GQLClient) GetChannelPointsContext(channelLogin string) (*ChannelPointsContext, error) {
+	req := pointsContextRequest(channelLogin)
+
+	var data pointsContextData
+	if err := g.query(&req, &data); err != nil {
+		return nil, fmt.Errorf("get points context: %w", err)
+	}
+	return data.context(channelLogin)
+}
+
+func pointsContextRequest(channelLogin string) GQLRequest {
+	return GQLRequest{
+		Query: queryChannelPointsContext,
 		Variables: map[string]interface{}{
 			"channelLogin": strings.ToLower(channelLogin),
 		},
 	}
+}
+
+type pointsContextData struct {
+	Community *gqlCommunity `json:"community"`
+}
+
+func (d *pointsContextData) context(channelLogin string) (*ChannelPointsContext, error) {
+	if d.Community == nil {
+		return nil, fmt.Errorf("get points context: %w: %q", ErrChannelNotFound, channelLogin)
+	}
+	ctx := &ChannelPointsContext{Multiplier: 1}
+	if d.Community.Channel == nil || d.Community.Channel.Self == nil {
+		return ctx, nil
+	}
+	cp := d.Community.Channel.Self.CommunityPoints
+	if cp == nil {
+		return ctx, nil
+	}
+	ctx.Balance = int(cp.Balance)
+	if cp.AvailableClaim != nil {
+		ctx.AvailableClaimID = cp.AvailableClaim.ID
+	}
+	for _, m := range cp.ActiveMultipliers {
+		ctx.Multiplier += m.Factor
+		if tier, ok := subTiers[m.ReasonCode]; ok && tier > ctx.SubTier {
+			ctx.SubTier = tier
+		}
+	}
+	return ctx, nil
+}
 
-	resp, err := g.do(req)
-	if err != nil {
-		return 0, fmt.Errorf("get points balance: %w", err)
-	}
-
-	community, ok := resp.Data["community"]
-	if !ok || community == nil {
-		// Try alternative path
-		if ch, ok := resp.Data["channel"]; ok && ch != nil {
-			if chMap, ok := ch.(map[string]interface{}); ok {
-				if self, ok := chMap["self"]; ok && self != nil {
-					if selfMap, ok := self.(map[string]interface{}); ok {
-						if cp, ok := selfMap["communityPoints"]; ok && cp != nil {
-							if cpMap, ok := cp.(map[string]interface{}); ok {
-								return getInt(cpMap, "balance"), nil
-							}
-						}
-					}
-				}
+// subTiers maps the subscription multipliers' reason codes to the tier.
+var subTiers = map[string]int{"SUB_T1": 1, "SUB_T2": 2, "SUB_T3": 3}
+
+// refreshBatchSize caps the operations in one GetChannelRefreshes
+// request — a channel takes one (points) or two (points + info).
+const refreshBatchSize = 10
+
+// ChannelRefresh is one channel's result from GetChannelRefreshes.
+type ChannelRefresh struct {
+	Points    *ChannelPointsContext // nil when PointsErr is set
+	PointsErr error                 // wraps ErrChannelNotFound for an unknown login
+	Info      *ChannelInfo          // nil unless asked for and resolved
+}
+
+// GetChannelRefreshes fetches the points context of every login, and
+// the channel info of those in withInfo, in batched requests of at most
+// refreshBatchSize operations instead of one request each. Logins whose
+// batch failed as a whole are missing from the result.
+func (g *GQLClient) GetChannelRefreshes(logins []string, withInfo map[string]bool) (map[string]*ChannelRefresh, error) {
+	type op struct {
+		login string
+		info  bool
+	}
+	var ops []op
+	for _, login := range logins {
+		login = strings.ToLower(login)
+		ops = append(ops, op{login: login})
+		if withInfo[login] {
+			ops = append(ops, op{login: login, info: true})
+		}
+	}
+
+	out := make(map[string]*ChannelRefresh, len(logins))
+	var firstErr error
+	for start := 0; start < len(ops); start += refreshBatchSize {
+		end := start + refreshBatchSize
+		if end > len(ops) {
+			end = len(ops)
+		}
+		chunk := ops[start:end]
+
+		reqs := make([]GQLRequest, len(chunk))
+		for i, o := range chunk {
+			if o.info {
+				reqs[i] = channelInfoRequest(o.login)
+			} else {
+				reqs[i] = pointsContextRequest(o.login)
 			}
 		}
-		return 0, nil
-	}
-
-	if communityMap, ok := community.(map[string]interface{}); ok {
-		if channel, ok := communityMap["channel"]; ok && channel != nil {
-			if channelMap, ok := channel.(map[string]interface{}); ok {
-				if self, ok := channelMap["self"]; ok && self != nil {
-					if selfMap, ok := self.(map[string]interface{}); ok {
-						if balance, ok := selfMap["balance"]; ok && balance != nil {
-							if balMap, ok := balance.(map[string]interface{}); ok {
-								return getInt(balMap, "availablePoints"), nil
-							}
-						}
-					}
+		resps, err := g.doBatch(reqs)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = fmt.Errorf("refresh batch %d-%d: %w", start, end, err)
+			}
+			continue
+		}
+
+		for i, resp := range resps {
+			if i >= len(chunk) {
+				break
+			}
+			o := chunk[i]
+			r := out[o.login]
+			if r == nil {
+				r = &ChannelRefresh{}
+				out[o.login] = r
+			}
+			var respErr error
+			if len(resp.Errors) > 0 {
+				respErr = fmt.Errorf("gql error: %s", resp.Errors[0].Message)
+			}
+			if o.info {
+				var data channelInfoData
+				if respErr == nil && resp.decode(&data) == nil && data.User != nil {
+					r.Info = data.User.channelInfo()
+					r.Info.Login = o.login
 				}
+				continue
+			}
+			var data pointsContextData
+			if respErr == nil {
+				respErr = resp.decode(&data)
+			}
+			if respErr != nil {
+				r.PointsErr = fmt.Errorf("get points context: %w", respErr)
+				continue
 			}
+			r.Points, r.PointsErr = data.context(o.login)
 		}
 	}
-
-	return 0, nil
+	return out, firstErr
 }
 
 // GetGameStreams queries the game directory for live streams.
@@ -780,43 +927,30 @@ func (g *GQLClient) GetCurrentDropSession(channelID string) (*CurrentDropSession
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data struct {
+		CurrentUser *struct {
+			DropCurrentSession *struct {
+				DropID                 string `json:"dropID"`
+				CurrentMinutesWatched  int    `json:"currentMinutesWatched"`
+				RequiredMinutesWatched int    `json:"requiredMinutesWatched"`
+			} `json:"dropCurrentSession"`
+		} `json:"currentUser"`
+	}
+	if err := g.query(req, &data); err != nil {
 		return nil, fmt.Errorf("drop current session: %w", err)
 	}
-
-	cu, ok := resp.Data["currentUser"].(map[string]interface{})
-	if !ok || cu == nil {
+	if data.CurrentUser == nil || data.CurrentUser.DropCurrentSession == nil {
 		return nil, nil
 	}
-	dcs, ok := cu["dropCurrentSession"].(map[string]interface{})
-	if !ok || dcs == nil {
+	dcs := data.CurrentUser.DropCurrentSession
+	if dcs.DropID == "" {
 		return nil, nil
 	}
-	out := &CurrentDropSession{}
-	if v, ok := dcs["dropID"].(string); ok {
-		out.DropID = v
-	}
-	if v, ok := dcs["currentMinutesWatched"]; ok && v != nil {
-		switch n := v.(type) {
-		case float64:
-			out.CurrentMinutesWatched = int(n)
-		case int:
-			out.CurrentMinutesWatched = n
-		}
-	}
-	if v, ok := dcs["requiredMinutesWatched"]; ok && v != nil {
-		switch n := v.(type) {
-		case float64:
-			out.RequiredMinutesWatched = int(n)
-		case int:
-			out.RequiredMinutesWatched = n
-		}
-	}
-	if out.DropID == "" {
-		return nil, nil
-	}
-	return out, nil
+	return &CurrentDropSession{
+		DropID:                 dcs.DropID,
+		CurrentMinutesWatched:  dcs.CurrentMinutesWatched,
+		RequiredMinutesWatched: dcs.RequiredMinutesWatched,
+	}, nil
 }
 
 // SearchGameCategories proxies Twitch's searchCategories GQL — used for
@@ -838,40 +972,24 @@ func (g *GQLClient) SearchGameCategories(query string, limit int) ([]string, err
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
-		return nil, fmt.Errorf("search categories: %w", err)
+	var data struct {
+		SearchCategories *struct {
+			Edges []struct {
+				Node *gqlGame `json:"node"`
+			} `json:"edges"`
+		} `json:"searchCategories"`
 	}
-
-	sc, ok := resp.Data["searchCategories"]
-	if !ok || sc == nil {
-		return nil, nil
-	}
-	scMap, ok := sc.(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-	edgesRaw, ok := scMap["edges"]
-	if !ok || edgesRaw == nil {
-		return nil, nil
+	if err := g.query(req, &data); err != nil {
+		return nil, fmt.Errorf("search categories: %w", err)
 	}
-	edges, ok := edgesRaw.([]interface{})
-	if !ok {
+	if data.SearchCategories == nil {
 		return nil, nil
 	}
 
 	var out []string
-	for _, e := range edges {
-		em, ok := e.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		node, ok := em["node"].(map[string]interface{})
-		if !ok {
-			continue
-		}
-		if name, ok := node["name"].(string); ok && name != "" {
-			out = append(out, name)
+	for _, e := range data.SearchCategories.Edges {
+		if e.Node != nil && e.Node.Name != "" {
+			out = append(out, e.Node.Name)
 		}
 	}
 	return out, nil
@@ -916,74 +1034,31 @@ func (g *GQLClient) fetchGameStreams(slug string, limit int, systemFilters []str
 		},
 	}
 
-	resp, err := g.do(req)
-	if err != nil {
+	var data gqlGameStreams
+	if err := g.query(req, &data); err != nil {
 		return nil, fmt.Errorf("get game streams: %w", err)
 	}
-
-	game, ok := resp.Data["game"]
-	if !ok || game == nil {
-		return nil, nil
-	}
-	gameMap, ok := game.(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	streams, ok := gameMap["streams"]
-	if !ok || streams == nil {
-		return nil, nil
-	}
-	streamsMap, ok := streams.(map[string]interface{})
-	if !ok {
-		return nil, nil
-	}
-
-	edges, ok := streamsMap["edges"]
-	if !ok || edges == nil {
-		return nil, nil
-	}
-	edgeList, ok := edges.([]interface{})
-	if !ok {
+	if data.Game == nil || data.Game.Streams == nil {
 		return nil, nil
 	}
 
 	var result []GameStream
-	for _, e := range edgeList {
-		eMap, ok := e.(map[string]interface{})
-		if !ok {
-			continue
-		}
-		node, ok := eMap["node"]
-		if !ok || node == nil {
+	for _, e := range data.Game.Streams.Edges {
+		n := e.Node
+		if n == nil || n.Broadcaster == nil || n.Broadcaster.Login == "" {
 			continue
 		}
-		nMap, ok := node.(map[string]interface{})
-		if !ok {
-			continue
-		}
-
 		gs := GameStream{
-			ViewerCount: getInt(nMap, "viewersCount"),
+			BroadcasterID:    n.Broadcaster.ID,
+			BroadcasterLogin: n.Broadcaster.Login,
+			DisplayName:      n.Broadcaster.DisplayName,
+			ViewerCount:      n.ViewersCount,
 		}
-
-		if broadcaster, ok := nMap["broadcaster"]; ok && broadcaster != nil {
-			if bMap, ok := broadcaster.(map[string]interface{}); ok {
-				gs.BroadcasterID = getString(bMap, "id")
-				gs.BroadcasterLogin = getString(bMap, "login")
-				gs.DisplayName = getString(bMap, "displayName")
-			}
-		}
-		if game, ok := nMap["game"]; ok && game != nil {
-			if gMap, ok := game.(map[string]interface{}); ok {
-				gs.GameID = getString(gMap, "id")
-				gs.GameName = getString(gMap, "name")
-			}
-		}
-
-		if gs.BroadcasterLogin != "" {
-			result = append(result, gs)
+		if n.Game != nil {
+			gs.GameID = n.Game.ID
+			gs.GameName = n.Game.Name
 		}
+		result = append(result, gs)
 	}
 
 	return result, nil
@@ -1008,7 +1083,7 @@ func (g *GQLClient) setHeaders(req *http.Request) {
 	req.Header.Set("X-Device-Id", g.deviceID)
 	req.Header.Set("Origin", "https://www.twitch.tv")
 	req.Header.Set("Referer", "https://www.twitch.tv")
-	req.Header.Set("Authorization", "OAuth "+g.authToken)
+	req.Header.Set("Authorization", "OAuth "+g.authToken.get())
 	req.Header.Set("Content-Type", "application/json")
 }
 
@@ -1029,26 +1104,3 @@ func generateSessionID() string {
 	rand.Read(b)
 	return hex.EncodeToString(b)
 }
-
-// Helper to safely get a string from a map.
-func getString(m map[string]interface{}, key string) string {
-	if v, ok := m[key]; ok && v != nil {
-		if s, ok := v.(string); ok {
-			return s
-		}
-	}
-	return ""
-}
-
-// Helper to safely get an int from a map (JSON numbers are float64).
-func getInt(m map[string]interface{}, key string) int {
-	if v, ok := m[key]; ok && v != nil {
-		switch n := v.(type) {
-		case float64:
-			return int(n)
-		case int:
-			return n
-		}
-	}
-	return 0
-}
@@ -0,0 +1,259 @@
+package twitch
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Typed shapes of the GQL responses we read. Each mirrors the selection
+// set of its query; GQLResponse.decode unmarshals the data object into
+// one, so a field Twitch retypes fails with its full JSON path instead
+// of silently reading as zero. Pointers mark objects Twitch returns as
+// null (offline stream, unknown user, no pending claim).
+
+// decode unmarshals the response's data object into out. A null or
+// absent data object leaves out untouched.
+func (r *GQLResponse) decode(out interface{}) error {
+	if len(r.Data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(r.Data, out); err != nil {
+		return fmt.Errorf("decode gql response: %w", err)
+	}
+	return nil
+}
+
+// query sends req and decodes its data object into out.
+func (g *GQLClient) query(req *GQLRequest, out interface{}) error {
+	resp, err := g.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.decode(out)
+}
+
+// gqlTime is an RFC 3339 timestamp that Twitch may send as null or "".
+type gqlTime struct {
+	time.Time
+}
+
+func (t *gqlTime) UnmarshalJSON(b []byte) error {
+	var s *string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	t.Time = time.Time{}
+	if s == nil || *s == "" {
+		return nil
+	}
+	v, err := time.Parse(time.RFC3339, *s)
+	if err != nil {
+		return err
+	}
+	t.Time = v
+	return nil
+}
+
+// gqlError is the per-mutation error object ({ error { code } }) Twitch
+// uses for refusals that aren't GQL errors.
+type gqlError struct {
+	Code string `json:"code"`
+}
+
+// errorCode returns the code of a possibly-null error object.
+func (e *gqlError) errorCode() string {
+	if e == nil {
+		return ""
+	}
+	return e.Code
+}
+
+type gqlGame struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+	Slug        string `json:"slug"`
+}
+
+// title returns displayName, falling back to name: the persisted-hash
+// (Android schema) responses only carry name.
+func (g *gqlGame) title() string {
+	if g.DisplayName != "" {
+		return g.DisplayName
+	}
+	return g.Name
+}
+
+type gqlStream struct {
+	ID           string   `json:"id"`
+	Type         string   `json:"type"`
+	CreatedAt    gqlTime  `json:"createdAt"`
+	ViewersCount int      `json:"viewersCount"`
+	Game         *gqlGame `json:"game"`
+	FreeformTags []struct {
+		Name string `json:"name"`
+	} `json:"freeformTags"`
+}
+
+type gqlUser struct {
+	ID          string     `json:"id"`
+	Login       string     `json:"login"`
+	DisplayName string     `json:"displayName"`
+	Stream      *gqlStream `json:"stream"`
+}
+
+// channelInfo converts a user (with its optional stream) to ChannelInfo.
+func (u *gqlUser) channelInfo() *ChannelInfo {
+	info := &ChannelInfo{
+		ID:          u.ID,
+		Login:       u.Login,
+		DisplayName: u.DisplayName,
+	}
+	if s := u.Stream; s != nil {
+		info.IsLive = true
+		info.BroadcastID = s.ID
+		info.ViewerCount = s.ViewersCount
+		info.StreamCreatedAt = s.CreatedAt.Time
+		info.StreamType = s.Type
+		for _, t := range s.FreeformTags {
+			info.Tags = append(info.Tags, t.Name)
+		}
+		if s.Game != nil {
+			info.GameName = s.Game.DisplayName
+			info.GameID = s.Game.ID
+		}
+	}
+	return info
+}
+
+// gqlBalance is communityPoints.balance: a plain number on the raw query,
+// an object with availablePoints on some persisted ones.
+type gqlBalance int
+
+func (b *gqlBalance) UnmarshalJSON(data []byte) error {
+	var n *int
+	if err := json.Unmarshal(data, &n); err == nil {
+		if n != nil {
+			*b = gqlBalance(*n)
+		}
+		return nil
+	}
+	var obj struct {
+		AvailablePoints int `json:"availablePoints"`
+	}
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+	*b = gqlBalance(obj.AvailablePoints)
+	return nil
+}
+
+type gqlCommunityPoints struct {
+	Balance        gqlBalance `json:"balance"`
+	AvailableClaim *struct {
+		ID string `json:"id"`
+	} `json:"availableClaim"`
+	ActiveMultipliers []struct {
+		Factor     float64 `json:"factor"`
+		ReasonCode string  `json:"reasonCode"`
+	} `json:"activeMultipliers"`
+}
+
+type gqlCustomReward struct {
+	ID                  string  `json:"id"`
+	Title               string  `json:"title"`
+	Prompt              string  `json:"prompt"`
+	Cost                int     `json:"cost"`
+	IsEnabled           bool    `json:"isEnabled"`
+	IsPaused            bool    `json:"isPaused"`
+	IsInStock           bool    `json:"isInStock"`
+	IsUserInputRequired bool    `json:"isUserInputRequired"`
+	CooldownExpiresAt   gqlTime `json:"cooldownExpiresAt"`
+}
+
+// gqlCommunity is community(name:) as read by ChannelPointsContext and
+// ChannelCustomRewards.
+type gqlCommunity struct {
+	Channel *struct {
+		ID   string `json:"id"`
+		Self *struct {
+			CommunityPoints *gqlCommunityPoints `json:"communityPoints"`
+		} `json:"self"`
+		CommunityPointsSettings *struct {
+			CustomRewards []gqlCustomReward `json:"customRewards"`
+		} `json:"communityPointsSettings"`
+	} `json:"channel"`
+}
+
+type gqlGameStreams struct {
+	Game *struct {
+		Streams *struct {
+			Edges []struct {
+				Node *struct {
+					ViewersCount int      `json:"viewersCount"`
+					Broadcaster  *gqlUser `json:"broadcaster"`
+					Game         *gqlGame `json:"game"`
+				} `json:"node"`
+			} `json:"edges"`
+		} `json:"streams"`
+	} `json:"game"`
+}
+
+type gqlDropChannel struct {
+	ID          string `json:"id"`
+	Name        string `json:"name"`
+	DisplayName string `json:"displayName"`
+}
+
+type gqlTimeBasedDrop struct {
+	ID                     string  `json:"id"`
+	Name                   string  `json:"name"`
+	RequiredMinutesWatched int     `json:"requiredMinutesWatched"`
+	StartAt                gqlTime `json:"startAt"`
+	EndAt                  gqlTime `json:"endAt"`
+	PreconditionDrops      []struct {
+		ID string `json:"id"`
+	} `json:"preconditionDrops"`
+	BenefitEdges []struct {
+		Benefit *struct {
+			ID               string `json:"id"`
+			Name             string `json:"name"`
+			DistributionType string `json:"distributionType"`
+		} `json:"benefit"`
+	} `json:"benefitEdges"`
+	Self *struct {
+		CurrentMinutesWatched int    `json:"currentMinutesWatched"`
+		DropInstanceID        string `json:"dropInstanceID"`
+		IsClaimed             bool   `json:"isClaimed"`
+	} `json:"self"`
+}
+
+// gqlDropCampaign is a campaign as the Dashboard (summary only),
+// Inventory and DropCampaignDetails responses return it.
+type gqlDropCampaign struct {
+	ID     string   `json:"id"`
+	Name   string   `json:"name"`
+	Status string   `json:"status"`
+	Game   *gqlGame `json:"game"`
+	Self   *struct {
+		IsAccountConnected *bool `json:"isAccountConnected"`
+	} `json:"self"`
+	StartAt        gqlTime            `json:"startAt"`
+	EndAt          gqlTime            `json:"endAt"`
+	TimeBasedDrops []gqlTimeBasedDrop `json:"timeBasedDrops"`
+	Allow          *struct {
+		Channels []gqlDropChannel `json:"channels"`
+	} `json:"allow"`
+}
+
+// gqlInventory is currentUser.inventory from the Inventory query.
+type gqlInventory struct {
+	DropCampaignsInProgress []gqlDropCampaign  `json:"dropCampaignsInProgress"`
+	GameEventDrops          []gqlGameEventDrop `json:"gameEventDrops"`
+}
+
+type gqlGameEventDrop struct {
+	ID            string  `json:"id"`
+	LastAwardedAt gqlTime `json:"lastAwardedAt"`
+}
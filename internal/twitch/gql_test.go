@@ -0,0 +1,113 @@
+package twitch
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// TestGetChannelRefreshes_Batches: points and info lookups go out in
+// batches of at most refreshBatchSize operations, and each response is
+// matched back to its channel.
+func TestGetChannelRefreshes_Batches(t *testing.T) {
+	g := NewGQLClient("tok")
+	var batches []int
+	g.httpClient.Transport = roundTripFunc(func(r *http.Request) (*http.Response, error) {
+		var reqs []GQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&reqs); err != nil {
+			t.Fatalf("decode batch: %v", err)
+		}
+		batches = append(batches, len(reqs))
+		resps := make([]string, len(reqs))
+		for i, req := range reqs {
+			switch login, _ := req.Variables["channelLogin"].(string); {
+			case req.Query == queryGetChannelInfo:
+				resps[i] = `{"data":{"user":{"id":"1","login":"a","stream":{"id":"b1","viewersCount":5}}}}`
+			case login == "gone":
+				resps[i] = `{"data":{"community":null}}`
+			default:
+				resps[i] = `{"data":{"community":{"channel":{"self":{"communityPoints":{"balance":42}}}}}}`
+			}
+		}
+		body := "[" + strings.Join(resps, ",") + "]"
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(body)), Header: http.Header{}}, nil
+	})
+
+	logins := []string{"a", "gone"}
+	for i := 0; i < 10; i++ {
+		logins = append(logins, "c"+string(rune('a'+i)))
+	}
+	got, err := g.GetChannelRefreshes(logins, map[string]bool{"a": true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(batches) != 2 || batches[0] != refreshBatchSize || batches[1] != 3 {
+		t.Fatalf("batches = %v, want [10 3]", batches)
+	}
+	a := got["a"]
+	if a == nil || a.PointsErr != nil || a.Points.Balance != 42 || a.Info == nil || a.Info.BroadcastID != "b1" {
+		t.Fatalf("a = %+v", a)
+	}
+	if r := got["gone"]; r == nil || !errors.Is(r.PointsErr, ErrChannelNotFound) {
+		t.Fatalf("gone = %+v", r)
+	}
+	if r := got["cj"]; r == nil || r.Points == nil || r.Points.Balance != 42 || r.Info != nil {
+		t.Fatalf("cj = %+v", r)
+	}
+}
+
+func TestChannelInfoIsRerun(t *testing.T) {
+	var data channelInfoData
+	body := `{"user":{"id":"1","login":"a","stream":{"id":"b1","type":"rerun","viewersCount":5,"freeformTags":[{"name":"English"}]}}}`
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatal(err)
+	}
+	info := data.User.channelInfo()
+	if info.StreamType != "rerun" || len(info.Tags) != 1 || info.Tags[0] != "English" || !info.IsRerun() {
+		t.Fatalf("info = %+v, want a rerun tagged English", info)
+	}
+
+	for _, tc := range []struct {
+		info ChannelInfo
+		want bool
+	}{
+		{ChannelInfo{IsLive: true, StreamType: "live"}, false},
+		{ChannelInfo{IsLive: true, StreamType: "premiere"}, false}, // first-run content
+		{ChannelInfo{IsLive: true, StreamType: "live", Tags: []string{"Rerun"}}, true},
+		{ChannelInfo{StreamType: "rerun"}, false}, // offline
+	} {
+		if got := tc.info.IsRerun(); got != tc.want {
+			t.Errorf("IsRerun(%+v) = %v, want %v", tc.info, got, tc.want)
+		}
+	}
+}
+
+func TestPointsContextMultipliers(t *testing.T) {
+	var data pointsContextData
+	body := `{"community":{"channel":{"self":{"communityPoints":{"balance":10,"activeMultipliers":[{"factor":0.4,"reasonCode":"SUB_T2"}]}}}}}`
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := data.context("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ctx.Multiplier != 1.4 || ctx.SubTier != 2 {
+		t.Fatalf("ctx = %+v, want multiplier 1.4 at tier 2", ctx)
+	}
+
+	data = pointsContextData{}
+	if err := json.Unmarshal([]byte(`{"community":{"channel":{"self":{"communityPoints":{"balance":10}}}}}`), &data); err != nil {
+		t.Fatal(err)
+	}
+	if ctx, _ := data.context("a"); ctx.Multiplier != 1 || ctx.SubTier != 0 {
+		t.Fatalf("ctx = %+v, want multiplier 1 without a sub", ctx)
+	}
+}
@@ -0,0 +1,196 @@
+package twitch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const validateURL = "https://id.twitch.tv/oauth2/validate"
+
+// maxRecentGQLErrors bounds the GQL errors kept for Health.
+const maxRecentGQLErrors = 200
+
+// GQL error classes (GQLErrorRecord.Class).
+const (
+	GQLErrorNetwork     = "network"      // no response: DNS, connect, timeout
+	GQLErrorAuth        = "auth"         // 401 or an unauthenticated GQL error
+	GQLErrorIntegrity   = "integrity"    // failed integrity check
+	GQLErrorRateLimited = "rate_limited" // 429
+	GQLErrorServer      = "server"       // 5xx
+	GQLErrorHTTP        = "http"         // other non-200 statuses
+	GQLErrorGQL         = "gql"          // errors[] in a 200 response
+	GQLErrorOther       = "other"        // marshal / read / unmarshal
+)
+
+// ErrTokenInvalid is returned by ValidateToken when Twitch rejects the
+// token (revoked, expired or never valid).
+var ErrTokenInvalid = errors.New("auth token rejected by Twitch")
+
+// TokenInfo is what Twitch's /oauth2/validate reports about a token.
+type TokenInfo struct {
+	ClientID  string   `json:"client_id"`
+	Login     string   `json:"login"`
+	UserID    string   `json:"user_id"`
+	Scopes    []string `json:"scopes"`
+	ExpiresIn int      `json:"expires_in"` // seconds; 0 = doesn't expire
+}
+
+// ValidateToken checks the client's auth token against Twitch's
+// /oauth2/validate. A rejected token returns ErrTokenInvalid.
+func (g *GQLClient) ValidateToken() (*TokenInfo, error) {
+	return ValidateToken(g.authToken.get())
+}
+
+// ValidateToken checks token against Twitch's /oauth2/validate. A
+// rejected token returns ErrTokenInvalid.
+func ValidateToken(token string) (*TokenInfo, error) {
+	req, err := http.NewRequest("GET", validateURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create validate request: %w", err)
+	}
+	req.Header.Set("Authorization", "OAuth "+token)
+	resp, err := authHTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("validate token: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if err != nil {
+		return nil, fmt.Errorf("read validate response: %w", err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return nil, ErrTokenInvalid
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("validate token: status %d", resp.StatusCode)
+	}
+	var info TokenInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("unmarshal validate response: %w", err)
+	}
+	return &info, nil
+}
+
+// GQLErrorRecord is one failed GQL call.
+type GQLErrorRecord struct {
+	Time      time.Time `json:"time"`
+	Operation string    `json:"operation"`
+	Class     string    `json:"class"`
+	Message   string    `json:"message"`
+}
+
+// ClaimStat counts the attempts and failures of one claim mutation.
+type ClaimStat struct {
+	Attempts int `json:"attempts"`
+	Failures int `json:"failures"`
+}
+
+// GQLHealth summarizes the GQL client's traffic since start.
+type GQLHealth struct {
+	Requests int                  // calls sent (a batch counts once)
+	Errors   int                  // calls that failed
+	Recent   []GQLErrorRecord     // up to maxRecentGQLErrors, newest first
+	Claims   map[string]ClaimStat // by operation name
+	LastOK   time.Time            // last call that succeeded; zero if none yet
+}
+
+// gqlHealth collects GQLHealth. The zero value is ready to use.
+type gqlHealth struct {
+	mu       sync.Mutex
+	requests int
+	errors   int
+	recent   []GQLErrorRecord // oldest first
+	claims   map[string]ClaimStat
+	lastOK   time.Time
+}
+
+// observe counts one GQL call and, if it failed, records its class.
+func (h *gqlHealth) observe(operation string, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.requests++
+	if err == nil {
+		h.lastOK = time.Now()
+		return
+	}
+	h.errors++
+	if operation == "" {
+		operation = "query"
+	}
+	h.recent = append(h.recent, GQLErrorRecord{
+		Time:      time.Now(),
+		Operation: operation,
+		Class:     classifyGQLError(err),
+		Message:   err.Error(),
+	})
+	if len(h.recent) > maxRecentGQLErrors {
+		h.recent = h.recent[len(h.recent)-maxRecentGQLErrors:]
+	}
+}
+
+// claim counts a claim attempt, and its failure if failed is set.
+func (h *gqlHealth) claim(operation string, failed bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.claims == nil {
+		h.claims = make(map[string]ClaimStat)
+	}
+	st := h.claims[operation]
+	if failed {
+		st.Failures++
+	} else {
+		st.Attempts++
+	}
+	h.claims[operation] = st
+}
+
+// Health returns a summary of the client's GQL traffic since start.
+func (g *GQLClient) Health() GQLHealth {
+	h := &g.health
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := GQLHealth{
+		Requests: h.requests,
+		Errors:   h.errors,
+		Recent:   make([]GQLErrorRecord, 0, len(h.recent)),
+		Claims:   make(map[string]ClaimStat, len(h.claims)),
+		LastOK:   h.lastOK,
+	}
+	for i := len(h.recent) - 1; i >= 0; i-- {
+		out.Recent = append(out.Recent, h.recent[i])
+	}
+	for op, st := range h.claims {
+		out.Claims[op] = st
+	}
+	return out
+}
+
+// classifyGQLError sorts an error from do/doBatch into a GQLError* class
+// by its wording.
+func classifyGQLError(err error) string {
+	msg := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(msg, "integrity"):
+		return GQLErrorIntegrity
+	case strings.Contains(msg, "gql status 401"), strings.Contains(msg, "unauthorized"),
+		strings.Contains(msg, "unauthenticated"):
+		return GQLErrorAuth
+	case strings.Contains(msg, "gql status 429"):
+		return GQLErrorRateLimited
+	case strings.Contains(msg, "gql status 5"):
+		return GQLErrorServer
+	case strings.Contains(msg, "gql status"):
+		return GQLErrorHTTP
+	case strings.Contains(msg, "gql error"):
+		return GQLErrorGQL
+	case strings.Contains(msg, "gql request"), strings.Contains(msg, "gql batch request"):
+		return GQLErrorNetwork
+	}
+	return GQLErrorOther
+}
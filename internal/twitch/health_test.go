@@ -0,0 +1,46 @@
+package twitch
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestClassifyGQLError(t *testing.T) {
+	cases := map[string]string{
+		"gql error: failed integrity check":              GQLErrorIntegrity,
+		"gql status 401: {}":                             GQLErrorAuth,
+		"gql error: unauthenticated":                     GQLErrorAuth,
+		"gql status 429: slow down":                      GQLErrorRateLimited,
+		"gql status 503: upstream":                       GQLErrorServer,
+		"gql status 404: nope":                           GQLErrorHTTP,
+		"gql error: service timeout":                     GQLErrorGQL,
+		"gql request: dial tcp: i/o timeout":             GQLErrorNetwork,
+		"unmarshal gql response: unexpected end of JSON": GQLErrorOther,
+	}
+	for msg, want := range cases {
+		if got := classifyGQLError(errors.New(msg)); got != want {
+			t.Errorf("classify(%q) = %s, want %s", msg, got, want)
+		}
+	}
+}
+
+func TestGQLHealthCounters(t *testing.T) {
+	g := &GQLClient{}
+	g.health.observe("A", nil)
+	g.health.observe("B", errors.New("gql status 429: x"))
+	g.health.observe("", errors.New("gql error: y"))
+	g.health.claim("ClaimCommunityPoints", false)
+	g.health.claim("ClaimCommunityPoints", false)
+	g.health.claim("ClaimCommunityPoints", true)
+
+	h := g.Health()
+	if h.Requests != 3 || h.Errors != 2 || len(h.Recent) != 2 {
+		t.Fatalf("health = %+v", h)
+	}
+	if h.Recent[0].Operation != "query" || h.Recent[1].Class != GQLErrorRateLimited {
+		t.Fatalf("recent not newest first: %+v", h.Recent)
+	}
+	if st := h.Claims["ClaimCommunityPoints"]; st.Attempts != 2 || st.Failures != 1 {
+		t.Fatalf("claims = %+v", h.Claims)
+	}
+}
@@ -0,0 +1,69 @@
+package twitch
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// hypeTrainDefaultSpan is assumed when a hype-train message carries no
+// usable expiry. A train runs out 5 minutes after the last level-up.
+const hypeTrainDefaultSpan = 5 * time.Minute
+
+// handleHypeTrain parses hype-train-events-v1 messages into
+// EventHypeTrain. Messages that don't change whether (or at what level)
+// a train is running — conductor updates, cooldown expiry — are dropped.
+func (p *PubSubClient) handleHypeTrain(channelID, rawMessage string) {
+	if data, ok := parseHypeTrain(rawMessage, time.Now()); ok {
+		p.emitEvent(FarmerEvent{
+			Type:      EventHypeTrain,
+			ChannelID: channelID,
+			Data:      data,
+		})
+	}
+}
+
+func parseHypeTrain(rawMessage string, now time.Time) (HypeTrainData, bool) {
+	var msg struct {
+		Type string `json:"type"`
+		Data struct {
+			ExpiresAt    int64 `json:"expires_at"`     // unix ms (start)
+			TimeToExpire int64 `json:"time_to_expire"` // unix ms (level-up)
+			Progress     struct {
+				Level struct {
+					Value int `json:"value"`
+				} `json:"level"`
+				RemainingSeconds int `json:"remaining_seconds"`
+			} `json:"progress"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(rawMessage), &msg); err != nil {
+		return HypeTrainData{}, false
+	}
+
+	switch msg.Type {
+	case "hype-train-start", "hype-train-progression", "hype-train-level-up":
+	case "hype-train-end":
+		return HypeTrainData{}, true
+	default:
+		return HypeTrainData{}, false
+	}
+
+	d := msg.Data
+	level := d.Progress.Level.Value
+	if level < 1 {
+		level = 1
+	}
+	var expires time.Time
+	switch {
+	case d.Progress.RemainingSeconds > 0:
+		expires = now.Add(time.Duration(d.Progress.RemainingSeconds) * time.Second)
+	case d.TimeToExpire > 0:
+		expires = time.UnixMilli(d.TimeToExpire)
+	case d.ExpiresAt > 0:
+		expires = time.UnixMilli(d.ExpiresAt)
+	}
+	if !expires.After(now) {
+		expires = now.Add(hypeTrainDefaultSpan)
+	}
+	return HypeTrainData{Active: true, Level: level, ExpiresAt: expires}, true
+}
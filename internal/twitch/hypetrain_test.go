@@ -0,0 +1,67 @@
+package twitch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseHypeTrain(t *testing.T) {
+	now := time.Unix(1_700_000_000, 0)
+
+	cases := []struct {
+		name   string
+		msg    string
+		ok     bool
+		active bool
+		level  int
+		expiry time.Time
+	}{
+		{
+			name:   "start uses remaining_seconds",
+			msg:    `{"type":"hype-train-start","data":{"expires_at":1700000300000,"progress":{"level":{"value":1},"remaining_seconds":240}}}`,
+			ok:     true,
+			active: true,
+			level:  1,
+			expiry: now.Add(240 * time.Second),
+		},
+		{
+			name:   "level-up falls back to time_to_expire",
+			msg:    `{"type":"hype-train-level-up","data":{"time_to_expire":1700000200000,"progress":{"level":{"value":3}}}}`,
+			ok:     true,
+			active: true,
+			level:  3,
+			expiry: time.UnixMilli(1700000200000),
+		},
+		{
+			name:   "no expiry gets the default span",
+			msg:    `{"type":"hype-train-progression","data":{"progress":{"level":{"value":2}}}}`,
+			ok:     true,
+			active: true,
+			level:  2,
+			expiry: now.Add(hypeTrainDefaultSpan),
+		},
+		{
+			name: "end",
+			msg:  `{"type":"hype-train-end","data":{"ending_reason":"EXPIRE"}}`,
+			ok:   true,
+		},
+		{
+			name: "conductor update ignored",
+			msg:  `{"type":"hype-train-conductor-update","data":{}}`,
+		},
+		{
+			name: "garbage",
+			msg:  `not json`,
+		},
+	}
+	for _, c := range cases {
+		got, ok := parseHypeTrain(c.msg, now)
+		if ok != c.ok {
+			t.Errorf("%s: ok = %v, want %v", c.name, ok, c.ok)
+			continue
+		}
+		if got.Active != c.active || got.Level != c.level || !got.ExpiresAt.Equal(c.expiry) {
+			t.Errorf("%s: got %+v, want active=%v level=%d expiry=%v", c.name, got, c.active, c.level, c.expiry)
+		}
+	}
+}
@@ -2,8 +2,8 @@ package twitch
 
 import (
 	"bufio"
-	"crypto/tls"
 	"fmt"
+	"math/rand/v2"
 	"net"
 	"strings"
 	"sync"
@@ -14,33 +14,99 @@ const (
 	ircHost     = "irc.chat.twitch.tv"
 	ircPort     = 6697 // TLS
 	ircPingFreq = 4 * time.Minute
+
+	// Twitch drops JOINs past 20 per 10s for regular accounts, without
+	// an error. JOINs are queued and sent within that window.
+	ircJoinLimit  = 20
+	ircJoinWindow = 10 * time.Second
+	// ircJoinAckTimeout is how long a JOIN may go without the server
+	// echoing it back before it's sent again, at most ircJoinAttempts
+	// times. Channels that never ack are retried after ircRejoinDelay.
+	ircJoinAckTimeout = 20 * time.Second
+	ircJoinAttempts   = 3
+	ircRejoinDelay    = 5 * time.Minute
+	ircJoinTick       = time.Second
 )
 
 // IRCClient manages a connection to Twitch IRC for viewer presence.
 type IRCClient struct {
-	token    string
-	username string
-	logFunc  func(format string, args ...interface{})
+	token     tokenBox
+	username  string
+	anonymous bool // logged in as a justinfan guest, without the token
+	logFunc   func(format string, args ...interface{})
+
+	// Warn, if set, gets connection and join failures in place of the
+	// log func. Set it before Connect.
+	Warn func(format string, args ...interface{})
 
 	mu       sync.Mutex
 	conn     net.Conn
 	writer   *bufio.Writer
-	channels map[string]bool // login -> joined
+	channels map[string]bool // login -> wanted (survives reconnects)
+	joined   map[string]bool // login -> JOIN sent on the current connection
+	ready    bool            // server confirmed auth (376) on the current connection
 	stopCh   chan struct{}
 	stopped  bool
+
+	// JOIN queue, drained by joinLoop within ircJoinLimit per
+	// ircJoinWindow. pending holds JOINs sent but not yet echoed back;
+	// retryAt holds channels that gave up, until they're queued again.
+	queue   []string
+	queued  map[string]bool
+	pending map[string]*pendingJoin
+	retryAt map[string]time.Time
+	joinLog []time.Time // JOINs sent in the last ircJoinWindow (kept across reconnects)
+	kick    chan struct{}
+
+	// onReady fires after every successful (re)connect, once the server
+	// confirmed auth. The farmer uses it to push its live channel list
+	// via SyncChannels so changes made while disconnected are honored.
+	onReady func()
 }
 
 // NewIRCClient creates a new IRC client.
 func NewIRCClient(token, username string, logFunc func(format string, args ...interface{})) *IRCClient {
 	return &IRCClient{
-		token:    token,
+		token:    tokenBox{token: token},
 		username: strings.ToLower(username),
 		logFunc:  logFunc,
 		channels: make(map[string]bool),
+		joined:   make(map[string]bool),
+		queued:   make(map[string]bool),
+		pending:  make(map[string]*pendingJoin),
+		retryAt:  make(map[string]time.Time),
 		stopCh:   make(chan struct{}),
+		kick:     make(chan struct{}, 1),
 	}
 }
 
+// pendingJoin is a JOIN awaiting the server's echo.
+type pendingJoin struct {
+	sentAt   time.Time
+	attempts int
+}
+
+// NewAnonymousIRCClient creates an IRC client that logs in as a random
+// read-only justinfan guest instead of the account: the auth token never
+// goes over IRC, but its JOINs don't show the account in viewer lists.
+func NewAnonymousIRCClient(logFunc func(format string, args ...interface{})) *IRCClient {
+	c := NewIRCClient("", fmt.Sprintf("justinfan%d", 10000+rand.IntN(90000)), logFunc)
+	c.anonymous = true
+	return c
+}
+
+// Anonymous reports whether the client logs in as a justinfan guest.
+func (c *IRCClient) Anonymous() bool { return c.anonymous }
+
+// SetReadyHook registers a callback that runs after every successful
+// (re)connect. When set, the hook is responsible for calling
+// SyncChannels; without one the client rejoins its own channel map.
+func (c *IRCClient) SetReadyHook(fn func()) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.onReady = fn
+}
+
 // Connect establishes the IRC connection and authenticates.
 func (c *IRCClient) Connect() error {
 	c.mu.Lock()
@@ -51,10 +117,12 @@ func (c *IRCClient) Connect() error {
 	c.mu.Unlock()
 
 	go c.connectLoop()
+	go c.joinLoop()
 	return nil
 }
 
 func (c *IRCClient) connectLoop() {
+	backoffMax := ActiveNetwork().IRCBackoffMax
 	backoff := time.Second
 
 	for {
@@ -65,7 +133,7 @@ func (c *IRCClient) connectLoop() {
 		}
 
 		if err := c.connect(); err != nil {
-			c.log("[IRC] Connection error: %v", err)
+			c.warn("[IRC] Connection error: %v", err)
 			c.log("[IRC] Reconnecting in %v...", backoff)
 
 			select {
@@ -74,10 +142,10 @@ func (c *IRCClient) connectLoop() {
 				return
 			}
 
-			// Exponential backoff (max 30s)
+			// Exponential backoff (max NetworkProfile.IRCBackoffMax)
 			backoff = backoff * 2
-			if backoff > 30*time.Second {
-				backoff = 30 * time.Second
+			if backoff > backoffMax {
+				backoff = backoffMax
 			}
 			continue
 		}
@@ -99,8 +167,8 @@ func (c *IRCClient) connectLoop() {
 }
 
 func (c *IRCClient) connect() error {
-	// Connect with TLS
-	conn, err := tls.Dial("tcp", fmt.Sprintf("%s:%d", ircHost, ircPort), nil)
+	// Connect with TLS (through the proxy, if configured)
+	conn, err := dialTLS(fmt.Sprintf("%s:%d", ircHost, ircPort))
 	if err != nil {
 		return fmt.Errorf("dial: %w", err)
 	}
@@ -108,11 +176,19 @@ func (c *IRCClient) connect() error {
 	c.mu.Lock()
 	c.conn = conn
 	c.writer = bufio.NewWriter(conn)
+	c.joined = make(map[string]bool) // fresh connection — nothing joined yet
+	c.queue = nil
+	c.queued = make(map[string]bool)
+	c.pending = make(map[string]*pendingJoin)
+	c.retryAt = make(map[string]time.Time)
+	c.ready = false
 	c.mu.Unlock()
 
-	// Authenticate
-	if err := c.send("PASS oauth:" + c.token); err != nil {
-		return fmt.Errorf("PASS: %w", err)
+	// Authenticate (guests send no PASS)
+	if !c.anonymous {
+		if err := c.send("PASS oauth:" + c.token.get()); err != nil {
+			return fmt.Errorf("PASS: %w", err)
+		}
 	}
 	if err := c.send("NICK " + c.username); err != nil {
 		return fmt.Errorf("NICK: %w", err)
@@ -123,7 +199,11 @@ func (c *IRCClient) connect() error {
 		return fmt.Errorf("CAP: %w", err)
 	}
 
-	c.log("[IRC] Connected as %s", c.username)
+	if c.anonymous {
+		c.log("[IRC] Connected anonymously as %s", c.username)
+	} else {
+		c.log("[IRC] Connected as %s", c.username)
+	}
 
 	// Channels are joined after server confirms auth (376 = end of MOTD) in handleLine
 	return nil
@@ -177,30 +257,213 @@ func (c *IRCClient) handleLine(line string) {
 
 	// Log auth failures
 	if strings.Contains(line, "Login authentication failed") {
-		c.log("[IRC] Authentication failed - check token")
+		c.warn("[IRC] Authentication failed - check token")
 		return
 	}
 
-	// Server confirmed auth (end of MOTD) — now join all tracked channels
+	// Server confirmed auth (end of MOTD) — let the farmer sync its live
+	// channel list, or fall back to rejoining our own map.
 	if strings.Contains(line, " 376 ") {
-		c.rejoinAll()
+		c.mu.Lock()
+		c.ready = true
+		hook := c.onReady
+		c.mu.Unlock()
+		if hook != nil {
+			hook()
+		} else {
+			c.reconcile()
+		}
 		return
 	}
+
+	// The server echoes our own JOINs back once they went through.
+	if login, ok := c.joinAck(line); ok {
+		c.mu.Lock()
+		delete(c.pending, login)
+		c.mu.Unlock()
+	}
+}
+
+// joinAck reports whether line is the echo of our own JOIN, and for
+// which channel (":me!me@me.tmi.twitch.tv JOIN #channel").
+func (c *IRCClient) joinAck(line string) (string, bool) {
+	fields := strings.Fields(line)
+	if len(fields) < 3 || fields[1] != "JOIN" || !strings.HasPrefix(fields[0], ":"+c.username+"!") {
+		return "", false
+	}
+	return strings.ToLower(strings.TrimPrefix(fields[2], "#")), true
+}
+
+// Ready reports whether the client is connected and the server has
+// accepted its login.
+func (c *IRCClient) Ready() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.conn != nil && c.ready
+}
+
+// Joined reports whether the current connection has joined login and
+// the server confirmed the JOIN.
+func (c *IRCClient) Joined(login string) bool {
+	login = strings.ToLower(login)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.ready && c.joined[login] && c.pending[login] == nil
+}
+
+// SyncChannels replaces the wanted channel set with logins and, when
+// connected, JOINs/PARTs the difference against what the current
+// connection has joined. Called by the farmer after every reconnect so
+// channels added or removed while IRC was down end up correct.
+func (c *IRCClient) SyncChannels(logins []string) {
+	wanted := make(map[string]bool, len(logins))
+	for _, l := range logins {
+		wanted[strings.ToLower(l)] = true
+	}
+
+	c.mu.Lock()
+	c.channels = wanted
+	c.mu.Unlock()
+
+	c.reconcile()
 }
 
-// rejoinAll sends JOIN for every channel in the tracked map.
-func (c *IRCClient) rejoinAll() {
+// reconcile queues a JOIN for every wanted channel not yet joined on
+// this connection and PARTs every joined channel no longer wanted.
+func (c *IRCClient) reconcile() {
 	c.mu.Lock()
-	channels := make([]string, 0, len(c.channels))
+	if c.conn == nil || !c.ready {
+		c.mu.Unlock()
+		return
+	}
+	var toPart []string
+	toJoin := 0
 	for ch := range c.channels {
-		channels = append(channels, ch)
+		if c.enqueueLocked(ch) {
+			toJoin++
+		}
+	}
+	for ch := range c.joined {
+		if !c.channels[ch] {
+			toPart = append(toPart, ch)
+		}
+	}
+	c.mu.Unlock()
+
+	for _, ch := range toPart {
+		c.partChannel(ch)
+	}
+	if toJoin > 0 {
+		c.wake()
+	}
+	if toJoin > 0 || len(toPart) > 0 {
+		c.log("[IRC] Synced channels: queued %d joins, parted %d", toJoin, len(toPart))
+	}
+}
+
+// enqueueLocked queues a JOIN for login unless it is already joined,
+// queued or waiting out ircRejoinDelay. Reports whether it was queued.
+// c.mu must be held.
+func (c *IRCClient) enqueueLocked(login string) bool {
+	if c.joined[login] || c.queued[login] {
+		return false
+	}
+	if _, waiting := c.retryAt[login]; waiting {
+		return false
+	}
+	c.queue = append(c.queue, login)
+	c.queued[login] = true
+	return true
+}
+
+// wake nudges joinLoop to drain the queue now.
+func (c *IRCClient) wake() {
+	select {
+	case c.kick <- struct{}{}:
+	default:
+	}
+}
+
+// joinLoop drains the JOIN queue and checks for JOINs the server never
+// acknowledged, until the client is closed.
+func (c *IRCClient) joinLoop() {
+	ticker := time.NewTicker(ircJoinTick)
+	defer ticker.Stop()
+	for {
+		c.flushJoins(time.Now())
+		select {
+		case <-c.stopCh:
+			return
+		case <-c.kick:
+		case <-ticker.C:
+		}
+	}
+}
+
+// flushJoins requeues unacknowledged JOINs, gives up on those out of
+// attempts, and sends as many queued JOINs as the rate window allows.
+func (c *IRCClient) flushJoins(now time.Time) {
+	c.mu.Lock()
+	if c.conn == nil || !c.ready {
+		c.mu.Unlock()
+		return
+	}
+
+	var failed []string
+	for ch, pj := range c.pending {
+		if now.Sub(pj.sentAt) < ircJoinAckTimeout {
+			continue
+		}
+		delete(c.joined, ch)
+		if pj.attempts >= ircJoinAttempts {
+			delete(c.pending, ch)
+			c.retryAt[ch] = now.Add(ircRejoinDelay)
+			failed = append(failed, ch)
+			continue
+		}
+		c.enqueueLocked(ch)
+	}
+	for ch, at := range c.retryAt {
+		if now.Before(at) {
+			continue
+		}
+		delete(c.retryAt, ch)
+		if c.channels[ch] {
+			c.enqueueLocked(ch)
+		}
+	}
+
+	cutoff := now.Add(-ircJoinWindow)
+	for len(c.joinLog) > 0 && !c.joinLog[0].After(cutoff) {
+		c.joinLog = c.joinLog[1:]
+	}
+	var toJoin []string
+	for len(c.queue) > 0 && len(c.joinLog) < ircJoinLimit {
+		ch := c.queue[0]
+		c.queue = c.queue[1:]
+		delete(c.queued, ch)
+		if !c.channels[ch] || c.joined[ch] {
+			continue
+		}
+		c.joinLog = append(c.joinLog, now)
+		pj := c.pending[ch]
+		if pj == nil {
+			pj = &pendingJoin{}
+			c.pending[ch] = pj
+		}
+		pj.sentAt = now
+		pj.attempts++
+		c.joined[ch] = true
+		toJoin = append(toJoin, ch)
 	}
 	c.mu.Unlock()
 
-	for _, ch := range channels {
+	for _, ch := range failed {
+		c.warn("[IRC] Could not join #%s after %d attempts, retrying in %v", ch, ircJoinAttempts, ircRejoinDelay)
+	}
+	for _, ch := range toJoin {
 		c.joinChannel(ch)
 	}
-	c.log("[IRC] Joined %d channel(s)", len(channels))
 }
 
 func (c *IRCClient) send(msg string) error {
@@ -218,24 +481,39 @@ func (c *IRCClient) send(msg string) error {
 	return c.writer.Flush()
 }
 
-// Join adds a channel to the join list and joins if connected.
+// Join adds a channel to the join list and queues its JOIN if
+// connected. Before the server confirmed auth the JOIN is deferred to
+// the ready hook.
 func (c *IRCClient) Join(login string) {
 	login = strings.ToLower(login)
 
 	c.mu.Lock()
 	c.channels[login] = true
-	connected := c.conn != nil
+	queued := c.conn != nil && c.ready && c.enqueueLocked(login)
 	c.mu.Unlock()
 
-	if connected {
-		c.joinChannel(login)
+	if queued {
+		c.wake()
 	}
 }
 
+// joinChannel sends a JOIN flushJoins already accounted for. A failed
+// write leaves it to the ack timeout to send again.
 func (c *IRCClient) joinChannel(login string) {
 	if err := c.send("JOIN #" + login); err != nil {
-		c.log("[IRC] Failed to join #%s: %v", login, err)
+		c.warn("[IRC] Failed to join #%s: %v", login, err)
+	}
+}
+
+func (c *IRCClient) partChannel(login string) {
+	if err := c.send("PART #" + login); err != nil {
+		c.warn("[IRC] Failed to part #%s: %v", login, err)
+		return
 	}
+	c.mu.Lock()
+	delete(c.joined, login)
+	delete(c.pending, login)
+	c.mu.Unlock()
 }
 
 // Part leaves a channel.
@@ -244,13 +522,13 @@ func (c *IRCClient) Part(login string) {
 
 	c.mu.Lock()
 	delete(c.channels, login)
-	connected := c.conn != nil
+	delete(c.retryAt, login) // a queued JOIN is skipped once unwanted
+	// Never-joined channels (skipped temps) need no PART.
+	joined := c.conn != nil && c.ready && c.joined[login]
 	c.mu.Unlock()
 
-	if connected {
-		if err := c.send("PART #" + login); err != nil {
-			c.log("[IRC] Failed to part #%s: %v", login, err)
-		}
+	if joined {
+		c.partChannel(login)
 	}
 }
 
@@ -287,3 +565,11 @@ func (c *IRCClient) log(format string, args ...interface{}) {
 		c.logFunc(format, args...)
 	}
 }
+
+func (c *IRCClient) warn(format string, args ...interface{}) {
+	if c.Warn != nil {
+		c.Warn(format, args...)
+		return
+	}
+	c.log(format, args...)
+}
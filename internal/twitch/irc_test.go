@@ -0,0 +1,207 @@
+package twitch
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// pipeIRC returns a ready client writing into a pipe, and a channel
+// receiving the lines it sends (closed when the client end closes).
+func pipeIRC(t *testing.T) (*IRCClient, net.Conn, <-chan string) {
+	t.Helper()
+	client, server := net.Pipe()
+	t.Cleanup(func() { server.Close() })
+
+	c := NewIRCClient("tok", "me", nil)
+	c.conn = client
+	c.writer = bufio.NewWriter(client)
+	c.ready = true
+
+	lines := make(chan string, 64)
+	go func() {
+		r := bufio.NewReader(server)
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				close(lines)
+				return
+			}
+			lines <- strings.TrimSpace(line)
+		}
+	}()
+	return c, client, lines
+}
+
+func drain(lines <-chan string) []string {
+	var got []string
+	for l := range lines {
+		got = append(got, l)
+	}
+	return got
+}
+
+// TestIRCSyncChannels_ReconcilesAgainstJoined: channels removed while the
+// connection was up must be PARTed, new ones JOINed, and unchanged ones
+// left alone.
+func TestIRCSyncChannels_ReconcilesAgainstJoined(t *testing.T) {
+	c, client, lines := pipeIRC(t)
+	c.channels = map[string]bool{"alpha": true, "bravo": true}
+	c.joined = map[string]bool{"alpha": true, "bravo": true}
+
+	c.SyncChannels([]string{"Bravo", "charlie"})
+	c.flushJoins(time.Now())
+	client.Close()
+
+	got := drain(lines)
+	sort.Strings(got)
+	want := []string{"JOIN #charlie", "PART #alpha"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("sent %v, want %v", got, want)
+	}
+	if c.joined["alpha"] || !c.joined["bravo"] || !c.joined["charlie"] {
+		t.Fatalf("joined set wrong after sync: %v", c.joined)
+	}
+}
+
+// TestIRCSyncChannels_NotReadyOnlyUpdatesWanted: before the server
+// confirmed auth, SyncChannels must not write anything — the ready hook
+// reconciles once 376 arrives.
+func TestIRCSyncChannels_NotReadyOnlyUpdatesWanted(t *testing.T) {
+	c := NewIRCClient("tok", "me", nil)
+	c.SyncChannels([]string{"alpha"})
+	if !c.channels["alpha"] || len(c.joined) != 0 {
+		t.Fatalf("channels=%v joined=%v", c.channels, c.joined)
+	}
+}
+
+// TestIRCSetAuthToken_DropsConnection: a new token must close the live
+// connection so the connect loop logs in again with it.
+func TestIRCSetAuthToken_DropsConnection(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+
+	c := NewIRCClient("old", "me", nil)
+	c.conn = client
+	c.writer = bufio.NewWriter(client)
+
+	c.SetAuthToken("new")
+	if got := c.token.get(); got != "new" {
+		t.Fatalf("token = %q, want new", got)
+	}
+	if _, err := server.Read(make([]byte, 1)); err == nil {
+		t.Fatal("connection still open after SetAuthToken")
+	}
+}
+
+// TestAnonymousIRCClient: a guest client has a justinfan nick, no token,
+// and ignores token swaps.
+func TestAnonymousIRCClient(t *testing.T) {
+	c := NewAnonymousIRCClient(nil)
+	if !strings.HasPrefix(c.username, "justinfan") || c.token.get() != "" {
+		t.Fatalf("username=%q token=%q", c.username, c.token.get())
+	}
+	c.SetAuthToken("secret")
+	if got := c.token.get(); got != "" {
+		t.Fatalf("anonymous client took token %q", got)
+	}
+}
+
+// TestIRCJoinQueue_RateLimited: a burst of channels must go out at most
+// ircJoinLimit per ircJoinWindow, the rest once the window slides.
+func TestIRCJoinQueue_RateLimited(t *testing.T) {
+	c, client, lines := pipeIRC(t)
+	var logins []string
+	for i := 0; i < 50; i++ {
+		logins = append(logins, fmt.Sprintf("ch%02d", i))
+	}
+	c.SyncChannels(logins)
+
+	now := time.Now()
+	c.flushJoins(now)
+	if len(c.joined) != ircJoinLimit || len(c.queue) != 50-ircJoinLimit {
+		t.Fatalf("first flush: joined %d, queued %d", len(c.joined), len(c.queue))
+	}
+	c.flushJoins(now.Add(ircJoinWindow / 2))
+	if len(c.joined) != ircJoinLimit {
+		t.Fatalf("joined %d inside the window, want %d", len(c.joined), ircJoinLimit)
+	}
+	c.flushJoins(now.Add(ircJoinWindow))
+	if len(c.joined) != 2*ircJoinLimit {
+		t.Fatalf("joined %d after the window, want %d", len(c.joined), 2*ircJoinLimit)
+	}
+	client.Close()
+	if got := drain(lines); len(got) != 2*ircJoinLimit {
+		t.Fatalf("sent %d JOINs, want %d", len(got), 2*ircJoinLimit)
+	}
+}
+
+// TestIRCJoinAck: the server's echo of our own JOIN clears it from
+// pending; other users' JOINs don't.
+func TestIRCJoinAck(t *testing.T) {
+	c, client, _ := pipeIRC(t)
+	defer client.Close()
+	c.SyncChannels([]string{"alpha", "bravo"})
+	c.flushJoins(time.Now())
+
+	c.handleLine(":someone!someone@someone.tmi.twitch.tv JOIN #bravo")
+	c.handleLine(":me!me@me.tmi.twitch.tv JOIN #alpha")
+	if c.pending["alpha"] != nil || c.pending["bravo"] == nil {
+		t.Fatalf("pending = %v", c.pending)
+	}
+}
+
+// TestIRCJoined: a channel counts as joined only once Twitch echoed the
+// JOIN, and not at all while the connection is down.
+func TestIRCJoined(t *testing.T) {
+	c, client, _ := pipeIRC(t)
+	defer client.Close()
+	c.SyncChannels([]string{"alpha"})
+	c.flushJoins(time.Now())
+	if c.Joined("alpha") {
+		t.Fatal("joined before the ack")
+	}
+	c.handleLine(":me!me@me.tmi.twitch.tv JOIN #alpha")
+	if !c.Joined("alpha") || c.Joined("bravo") {
+		t.Fatalf("Joined alpha=%v bravo=%v, want true/false", c.Joined("alpha"), c.Joined("bravo"))
+	}
+	c.mu.Lock()
+	c.ready = false
+	c.mu.Unlock()
+	if c.Joined("alpha") {
+		t.Fatal("joined while disconnected")
+	}
+}
+
+// TestIRCJoinRetry: an unacknowledged JOIN is sent again after the ack
+// timeout and, out of attempts, parked until ircRejoinDelay passes.
+func TestIRCJoinRetry(t *testing.T) {
+	c, client, lines := pipeIRC(t)
+	c.SyncChannels([]string{"alpha"})
+
+	now := time.Now()
+	for i := 0; i < ircJoinAttempts; i++ {
+		c.flushJoins(now)
+		now = now.Add(ircJoinAckTimeout)
+	}
+	c.flushJoins(now)
+	if c.joined["alpha"] || c.pending["alpha"] != nil {
+		t.Fatalf("still joined after %d attempts: pending=%v", ircJoinAttempts, c.pending)
+	}
+	if _, ok := c.retryAt["alpha"]; !ok {
+		t.Fatal("alpha not parked for a later rejoin")
+	}
+
+	c.flushJoins(now.Add(ircRejoinDelay))
+	if !c.joined["alpha"] || c.pending["alpha"].attempts != 1 {
+		t.Fatalf("alpha not rejoined after the delay: %v", c.pending["alpha"])
+	}
+	client.Close()
+	if got := drain(lines); len(got) != ircJoinAttempts+1 {
+		t.Fatalf("sent %v, want %d JOINs", got, ircJoinAttempts+1)
+	}
+}
@@ -0,0 +1,110 @@
+package twitch
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// NetworkProfile bundles the reconnect, retry and timeout tunables of
+// the PubSub, EventSub, IRC, GQL and Spade clients. The farmer installs
+// one with SetNetworkProfile before any client is created.
+type NetworkProfile struct {
+	Name string
+
+	// PubSub / EventSub reconnect backoff: starts at ReconnectBase and
+	// doubles per failed attempt up to ReconnectMax.
+	ReconnectBase time.Duration
+	ReconnectMax  time.Duration
+	// StableAfter is how long a PubSub connection must stay up before
+	// the backoff resets and the shard counts as recovered.
+	StableAfter time.Duration
+	// FailThreshold is how many PubSub connection attempts in a row may
+	// fail before the health hook reports PubSub as down.
+	FailThreshold int
+	// PingInterval is how often PubSub shards PING. Twitch expects one
+	// within 5 minutes.
+	PingInterval time.Duration
+	// PongTimeout, when set, drops a PubSub connection whose PING goes
+	// unanswered this long, so it reconnects and resubscribes its topics
+	// instead of sitting on a dead socket until the OS notices.
+	PongTimeout time.Duration
+
+	// IRCBackoffMax caps the IRC reconnect backoff (starts at 1s).
+	IRCBackoffMax time.Duration
+
+	// GQLTimeout bounds every GQL request. GQLRetries is how many times
+	// a request that failed in transport (no HTTP response) is resent.
+	GQLTimeout    time.Duration
+	GQLRetries    int
+	GQLRetryDelay time.Duration
+
+	// Spade heartbeats: per-request timeout, retries after the first
+	// attempt and the linear retry step (attempt n waits n*step).
+	SpadeTimeout   time.Duration
+	SpadeRetries   int
+	SpadeRetryStep time.Duration
+
+	// StreamDownGrace delays acting on a stream-down event. A stream-up
+	// for the same channel within the grace cancels it, so a stream that
+	// blips (or a flapping connection replaying events) doesn't lose its
+	// watch slot or drop pick.
+	StreamDownGrace time.Duration
+}
+
+// DefaultNetwork suits a stable wired or Wi-Fi connection.
+var DefaultNetwork = NetworkProfile{
+	Name:           "default",
+	ReconnectBase:  1 * time.Second,
+	ReconnectMax:   2 * time.Minute,
+	StableAfter:    30 * time.Second,
+	FailThreshold:  3,
+	PingInterval:   4*time.Minute + 30*time.Second,
+	IRCBackoffMax:  30 * time.Second,
+	GQLTimeout:     30 * time.Second,
+	SpadeTimeout:   10 * time.Second,
+	SpadeRetries:   2,
+	SpadeRetryStep: 3 * time.Second,
+}
+
+// FlakyNetwork is tuned for connections that drop out for seconds to
+// minutes at a time (mobile hotspots, congested Wi-Fi): reconnects are
+// retried more often and capped lower so subscriptions come back soon
+// after the link does, dead sockets are detected by PING/PONG within a
+// minute or two, requests get longer timeouts and more retries, and a
+// stream has to stay down for two minutes before it is treated as
+// offline.
+var FlakyNetwork = NetworkProfile{
+	Name:            "flaky",
+	ReconnectBase:   2 * time.Second,
+	ReconnectMax:    30 * time.Second,
+	StableAfter:     2 * time.Minute,
+	FailThreshold:   6,
+	PingInterval:    1 * time.Minute,
+	PongTimeout:     15 * time.Second,
+	IRCBackoffMax:   15 * time.Second,
+	GQLTimeout:      45 * time.Second,
+	GQLRetries:      2,
+	GQLRetryDelay:   3 * time.Second,
+	SpadeTimeout:    20 * time.Second,
+	SpadeRetries:    4,
+	SpadeRetryStep:  5 * time.Second,
+	StreamDownGrace: 2 * time.Minute,
+}
+
+var activeNetwork atomic.Pointer[NetworkProfile]
+
+// SetNetworkProfile installs p for all clients. Timeouts are read when a
+// client is created, backoff and retry settings whenever a connection
+// loop or request starts.
+func SetNetworkProfile(p NetworkProfile) {
+	activeNetwork.Store(&p)
+}
+
+// ActiveNetwork returns the installed profile (DefaultNetwork until
+// SetNetworkProfile is called).
+func ActiveNetwork() NetworkProfile {
+	if p := activeNetwork.Load(); p != nil {
+		return *p
+	}
+	return DefaultNetwork
+}
@@ -48,7 +48,7 @@ type proberChannel struct {
 
 type StreamProber struct {
 	gql        *GQLClient
-	authToken  string
+	authToken  tokenBox
 	userID     string
 	deviceID   string
 	httpClient *http.Client
@@ -64,10 +64,10 @@ type StreamProber struct {
 func NewStreamProber(gql *GQLClient, authToken, userID, deviceID string, logFunc func(string, ...interface{})) *StreamProber {
 	return &StreamProber{
 		gql:        gql,
-		authToken:  authToken,
+		authToken:  tokenBox{token: authToken},
 		userID:     userID,
 		deviceID:   deviceID,
-		httpClient: &http.Client{Timeout: 15 * time.Second},
+		httpClient: NewHTTPClient(15 * time.Second),
 		logFunc:    logFunc,
 		tokens:     make(map[string]*playbackToken),
 		channels:   make(map[string]*proberChannel),
@@ -279,7 +279,7 @@ func (p *StreamProber) primeChannelPage(login string) {
 	req.Header.Set("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
 	req.Header.Set("Accept-Language", "en-US,en;q=0.9")
 	req.Header.Set("Cookie", fmt.Sprintf("auth-token=%s; persistent=%s; unique_id=%s",
-		p.authToken, p.userID, p.deviceID))
+		p.authToken.get(), p.userID, p.deviceID))
 	resp, err := p.httpClient.Do(req)
 	if err != nil {
 		p.log("[Prober] %s page-view failed: %v", login, err)
@@ -0,0 +1,100 @@
+package twitch
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func TestParseProxyURL(t *testing.T) {
+	for raw, ok := range map[string]bool{
+		"http://proxy.lan:3128":     true,
+		"SOCKS5://user:pw@10.0.0.1": true,
+		"socks5h://127.0.0.1:9050":  true,
+		"https://proxy.lan:443":     false,
+		"ftp://proxy.lan":           false,
+		"socks5://":                 false,
+		"proxy.lan:8080":            false,
+	} {
+		_, err := ParseProxyURL(raw)
+		if (err == nil) != ok {
+			t.Errorf("ParseProxyURL(%q) err = %v, want ok=%v", raw, err, ok)
+		}
+	}
+
+	u, _ := ParseProxyURL("socks5://10.0.0.1")
+	if got := proxyHostPort(u); got != "10.0.0.1:1080" {
+		t.Errorf("default socks5 port: got %s", got)
+	}
+}
+
+// TestSocks5Connect walks the handshake with username/password auth
+// against a scripted server and checks the CONNECT request carries the
+// host name, not a resolved address.
+func TestSocks5Connect(t *testing.T) {
+	client, server := net.Pipe()
+	defer client.Close()
+	defer server.Close()
+
+	got := make(chan []byte, 1)
+	go func() {
+		buf := make([]byte, 512)
+		io.ReadFull(server, buf[:4]) // 05 02 00 02
+		server.Write([]byte{0x05, 0x02})
+		n, _ := server.Read(buf) // 01 len user len pass
+		if !bytes.Equal(buf[:n], []byte("\x01\x02me\x02pw")) {
+			server.Write([]byte{0x01, 0x01})
+			return
+		}
+		server.Write([]byte{0x01, 0x00})
+		n, _ = server.Read(buf)
+		got <- append([]byte(nil), buf[:n]...)
+		// Reply with an IPv4 bound address.
+		server.Write([]byte{0x05, 0x00, 0x00, 0x01, 127, 0, 0, 1, 0x1a, 0x2b})
+	}()
+
+	u, _ := url.Parse("socks5://me:pw@proxy")
+	if err := socks5Connect(client, u, "irc.chat.twitch.tv:6697"); err != nil {
+		t.Fatalf("socks5Connect: %v", err)
+	}
+	want := append([]byte{0x05, 0x01, 0x00, 0x03, 18}, "irc.chat.twitch.tv"...)
+	want = append(want, 0x1a, 0x29)
+	if req := <-got; !bytes.Equal(req, want) {
+		t.Fatalf("connect request = %x, want %x", req, want)
+	}
+}
+
+func TestHTTPConnect(t *testing.T) {
+	for _, status := range []int{http.StatusOK, http.StatusProxyAuthRequired} {
+		client, server := net.Pipe()
+		reqs := make(chan *http.Request, 1)
+		go func() {
+			req, err := http.ReadRequest(bufio.NewReader(server))
+			if err != nil {
+				return
+			}
+			reqs <- req
+			resp := &http.Response{StatusCode: status, ProtoMajor: 1, ProtoMinor: 1}
+			resp.Write(server)
+		}()
+
+		u, _ := url.Parse("http://me:pw@proxy:3128")
+		err := httpConnect(client, u, "irc.chat.twitch.tv:6697")
+		req := <-reqs
+		if req.Method != http.MethodConnect || req.Host != "irc.chat.twitch.tv:6697" {
+			t.Errorf("request = %s %s", req.Method, req.Host)
+		}
+		if user, pass, _ := (&http.Request{Header: http.Header{"Authorization": req.Header["Proxy-Authorization"]}}).BasicAuth(); user != "me" || pass != "pw" {
+			t.Errorf("proxy auth = %q/%q", user, pass)
+		}
+		if (err == nil) != (status == http.StatusOK) {
+			t.Errorf("status %d: err = %v", status, err)
+		}
+		client.Close()
+		server.Close()
+	}
+}
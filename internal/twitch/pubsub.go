@@ -4,201 +4,223 @@ import (
 	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"strings"
 	"sync"
 	"time"
-
-	"github.com/gorilla/websocket"
 )
 
 const (
 	pubsubURL        = "wss://pubsub-edge.twitch.tv/v1"
-	pingInterval     = 4*time.Minute + 30*time.Second // Twitch expects pings within 5 min
-	reconnectBase    = 1 * time.Second
-	reconnectMax     = 2 * time.Minute
 	eventSendTimeout = 2 * time.Second
 )
 
-// PubSubClient manages a WebSocket connection to Twitch PubSub.
+// maxTopicsPerShard is Twitch's per-connection LISTEN cap. Past it the
+// server rejects LISTENs, so the client opens another connection.
+const maxTopicsPerShard = 50
+
+// PubSubClient manages a pool of WebSocket connections to Twitch PubSub.
+// Topics are packed onto connections (shards) of at most
+// maxTopicsPerShard each; a new shard is opened when every existing one
+// is full, and Unlisten consolidates topics back onto fewer shards once
+// they fit. Callers see a single Listen/Unlisten API and one event
+// channel regardless of how many connections are open.
 type PubSubClient struct {
-	authToken string
+	authToken tokenBox
 	events    chan FarmerEvent
 
-	mu      sync.Mutex
-	writeMu sync.Mutex // serializes all WebSocket writes
-	conn    *websocket.Conn
-	topics  map[string]bool
-	closed  bool
-	closeCh chan struct{}
+	mu          sync.Mutex
+	shards      []*pubsubShard
+	topicShard  map[string]*pubsubShard // topic -> owning shard
+	topicOwner  map[string]string       // topic -> ListenOwned owner; absent = unowned
+	nextShardID int
+	started     bool // Connect called — new shards start their own loop
+	closed      bool
+	closeCh     chan struct{}
+
+	unhealthy map[*pubsubShard]bool // shards past NetworkProfile.FailThreshold
+	onHealth  func(healthy bool)
+	onTopic   func(topic string, err error)
+
+	recMu    sync.Mutex
+	recorder io.Writer // RecordTo capture file; nil = off
 }
 
 // NewPubSubClient creates a new PubSub client. Events are delivered on the returned channel.
 func NewPubSubClient(authToken string, events chan FarmerEvent) *PubSubClient {
 	return &PubSubClient{
-		authToken: authToken,
-		events:    events,
-		topics:    make(map[string]bool),
-		closeCh:   make(chan struct{}),
+		authToken:  tokenBox{token: authToken},
+		events:     events,
+		topicShard: make(map[string]*pubsubShard),
+		topicOwner: make(map[string]string),
+		closeCh:    make(chan struct{}),
+		unhealthy:  make(map[*pubsubShard]bool),
 	}
 }
 
-// Connect establishes the WebSocket connection with auto-reconnect.
-func (p *PubSubClient) Connect() error {
-	return p.connectWithRetry()
+// SetHealthHook registers a callback for pool health transitions: false
+// once any shard has failed NetworkProfile.FailThreshold connection
+// attempts in a row, true when every such shard has held a connection
+// for NetworkProfile.StableAfter again.
+func (p *PubSubClient) SetHealthHook(fn func(healthy bool)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onHealth = fn
 }
 
-func (p *PubSubClient) connectWithRetry() error {
-	backoff := reconnectBase
-
-	for {
-		select {
-		case <-p.closeCh:
-			return nil
-		default:
-		}
-
-		connectedAt := time.Now()
-		err := p.connectOnce()
-		if err == nil {
-			disconnectReason := p.readLoop()
-
-			// readLoop exited, check if intentionally closed
-			p.mu.Lock()
-			if p.closed {
-				p.mu.Unlock()
-				return nil
-			}
-			p.mu.Unlock()
-
-			// Only reset backoff if connection was stable (lasted > 30s)
-			if time.Since(connectedAt) > 30*time.Second {
-				backoff = reconnectBase
-			}
+// SetTopicHook registers a callback for topics whose LISTEN keeps
+// failing: it gets the error once a topic has failed listenWarnAfter
+// times in a row (retries go on with backoff), and nil when such a
+// topic subscribes again or is unlistened.
+func (p *PubSubClient) SetTopicHook(fn func(topic string, err error)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onTopic = fn
+}
 
-			p.sendError(fmt.Errorf("disconnected (%s), reconnecting in %v", disconnectReason, backoff))
-		} else {
-			p.sendError(fmt.Errorf("connection failed: %v, retrying in %v", err, backoff))
+// reportTopics passes a shard's topic reports to the topic hook.
+func (p *PubSubClient) reportTopics(reports []topicReport) {
+	if len(reports) == 0 {
+		return
+	}
+	p.mu.Lock()
+	hook := p.onTopic
+	p.mu.Unlock()
+	if hook == nil {
+		return
+	}
+	for _, r := range reports {
+		var err error
+		if r.err != "" {
+			err = errors.New(r.err)
 		}
+		hook(r.topic, err)
+	}
+}
 
-		select {
-		case <-time.After(backoff):
-		case <-p.closeCh:
-			return nil
-		}
+// setShardHealth records a shard's health and fires the hook when the
+// pool as a whole flips between healthy and unhealthy.
+func (p *PubSubClient) setShardHealth(s *pubsubShard, healthy bool) {
+	p.mu.Lock()
+	wasHealthy := len(p.unhealthy) == 0
+	if healthy {
+		delete(p.unhealthy, s)
+	} else {
+		p.unhealthy[s] = true
+	}
+	isHealthy := len(p.unhealthy) == 0
+	hook := p.onHealth
+	p.mu.Unlock()
 
-		backoff *= 2
-		if backoff > reconnectMax {
-			backoff = reconnectMax
-		}
+	if hook != nil && wasHealthy != isHealthy {
+		hook(isHealthy)
 	}
 }
 
-func (p *PubSubClient) connectOnce() error {
-	conn, _, err := websocket.DefaultDialer.Dial(pubsubURL, nil)
-	if err != nil {
-		return fmt.Errorf("dial pubsub: %w", err)
-	}
+// Healthy reports whether no connection is past the network profile's
+// failure threshold (the state SetHealthHook reports changes of).
+func (p *PubSubClient) Healthy() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.unhealthy) == 0
+}
 
+// Connect starts every shard's connection loop (each with its own
+// auto-reconnect) and blocks until Close.
+func (p *PubSubClient) Connect() error {
 	p.mu.Lock()
-	// Close old connection before replacing
-	if p.conn != nil {
-		p.conn.Close()
+	if p.closed {
+		p.mu.Unlock()
+		return nil
 	}
-	p.conn = conn
-	topics := make([]string, 0, len(p.topics))
-	for t := range p.topics {
-		topics = append(topics, t)
+	p.started = true
+	if len(p.shards) == 0 {
+		p.newShardLocked()
+	}
+	for _, s := range p.shards {
+		go s.run()
 	}
 	p.mu.Unlock()
 
-	// Subscribe in batches to avoid "message too big" (Twitch rejects large LISTEN frames)
-	const batchSize = 10
-	for i := 0; i < len(topics); i += batchSize {
-		end := i + batchSize
-		if end > len(topics) {
-			end = len(topics)
-		}
-		if err := p.sendListen(topics[i:end]); err != nil {
-			conn.Close()
-			return fmt.Errorf("resubscribe batch: %w", err)
+	<-p.closeCh
+	return nil
+}
+
+// Connections returns the number of open PubSub connections (shards).
+func (p *PubSubClient) Connections() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.shards)
+}
+
+// newShardLocked appends an empty shard to the pool. Caller holds p.mu
+// and is responsible for starting it if the client is running.
+func (p *PubSubClient) newShardLocked() *pubsubShard {
+	s := newPubSubShard(p.nextShardID, p)
+	p.nextShardID++
+	p.shards = append(p.shards, s)
+	return s
+}
+
+// shardWithRoomLocked returns the first shard below maxTopicsPerShard,
+// or nil if all are full. Filling the earliest shards first keeps the
+// pool compact. Caller holds p.mu.
+func (p *PubSubClient) shardWithRoomLocked() *pubsubShard {
+	for _, s := range p.shards {
+		if s.topicCount() < maxTopicsPerShard {
+			return s
 		}
 	}
-
-	p.sendError(fmt.Errorf("connected, subscribed to %d topics", len(topics)))
 	return nil
 }
 
-func (p *PubSubClient) readLoop() string {
-	pingTicker := time.NewTicker(pingInterval)
-	defer pingTicker.Stop()
-
-	// done channel stops the ping goroutine when readLoop exits
-	done := make(chan struct{})
-	defer close(done)
-
-	// Start ping goroutine
-	go func() {
-		for {
-			select {
-			case <-pingTicker.C:
-				p.mu.Lock()
-				conn := p.conn
-				p.mu.Unlock()
-				if conn == nil {
-					return
-				}
-				msg := PubSubOutgoing{Type: PubSubTypePing}
-				data, _ := json.Marshal(msg)
-				if err := p.writeMessage(data); err != nil {
-					return
-				}
-			case <-done:
-				return
-			case <-p.closeCh:
-				return
+// rebalanceLocked retires the least-loaded shard for as long as the
+// remaining shards have room for all of its topics, so churn (temp drop
+// channels coming and going) doesn't leave a trail of half-empty
+// connections. Returns the topics to LISTEN on their new shards and the
+// shards to stop. Caller holds p.mu.
+func (p *PubSubClient) rebalanceLocked() (moves map[*pubsubShard][]string, retired []*pubsubShard) {
+	moves = make(map[*pubsubShard][]string)
+	for len(p.shards) > 1 {
+		idx := 0
+		for i, s := range p.shards {
+			if s.topicCount() < p.shards[idx].topicCount() {
+				idx = i
 			}
 		}
-	}()
-
-	for {
-		p.mu.Lock()
-		conn := p.conn
-		p.mu.Unlock()
-		if conn == nil {
-			return "connection lost"
-		}
-
-		_, message, err := conn.ReadMessage()
-		if err != nil {
-			return err.Error()
+		victim := p.shards[idx]
+		free := 0
+		for i, s := range p.shards {
+			if i != idx {
+				free += maxTopicsPerShard - s.topicCount()
+			}
 		}
-
-		var incoming PubSubIncoming
-		if err := json.Unmarshal(message, &incoming); err != nil {
-			continue
+		if free < victim.topicCount() {
+			break
 		}
 
-		switch incoming.Type {
-		case PubSubTypePong:
-			// Expected response to PING
-		case PubSubTypeReconn:
-			// Server requests reconnect
-			conn.Close()
-			return "server requested reconnect"
-		case PubSubTypeResponse:
-			if incoming.Error != "" {
-				p.sendError(fmt.Errorf("listen error: %s", incoming.Error))
-			}
-		case PubSubTypeMessage:
-			if incoming.Data != nil {
-				p.handleMessage(incoming.Data)
-			}
+		p.shards = append(p.shards[:idx], p.shards[idx+1:]...)
+		for _, t := range victim.topicList() {
+			dest := p.shardWithRoomLocked()
+			dest.addTopic(t)
+			dest.inheritFailure(t, victim)
+			p.topicShard[t] = dest
+			moves[dest] = append(moves[dest], t)
 		}
+		retired = append(retired, victim)
 	}
+	return moves, retired
 }
 
 func (p *PubSubClient) handleMessage(data *PubSubMsgData) {
+	p.record(data)
+	p.dispatch(data)
+}
+
+// dispatch routes a message to its topic's handler.
+func (p *PubSubClient) dispatch(data *PubSubMsgData) {
 	topic := data.Topic
 
 	switch {
@@ -212,9 +234,14 @@ func (p *PubSubClient) handleMessage(data *PubSubMsgData) {
 		p.handleRaid(channelID, data.Message)
 	case strings.HasPrefix(topic, "user-drop-events."):
 		p.handleDropEvent(data.Message)
+	case strings.HasPrefix(topic, "community-momento-user-v1."):
+		p.handleMoment(data.Message)
 	case strings.HasPrefix(topic, "broadcast-settings-update."):
 		channelID := strings.TrimPrefix(topic, "broadcast-settings-update.")
 		p.handleBroadcastSettings(channelID, data.Message)
+	case strings.HasPrefix(topic, "hype-train-events-v1."):
+		channelID := strings.TrimPrefix(topic, "hype-train-events-v1.")
+		p.handleHypeTrain(channelID, data.Message)
 	}
 }
 
@@ -265,8 +292,35 @@ func (p *PubSubClient) handleCommunityPoints(rawMessage string) {
 				},
 			})
 		}
+	case "points-spent":
+		if evt.Data.Balance != nil {
+			if channelID == "" {
+				channelID = evt.Data.Balance.ChannelID
+			}
+			p.emitEvent(FarmerEvent{
+				Type:      EventPointsSpent,
+				ChannelID: channelID,
+				Data: PointsData{
+					TotalPoints: evt.Data.Balance.Balance,
+				},
+			})
+		}
 	case "claim-claimed":
 		// Claim was successfully claimed - handled via points-earned
+	default:
+		// New or unhandled types (Twitch keeps adding them) go to the
+		// farmer as is: logged, or claimed via points_claim_events.
+		var raw struct {
+			Data json.RawMessage `json:"data"`
+		}
+		if err := json.Unmarshal([]byte(rawMessage), &raw); err != nil {
+			return
+		}
+		p.emitEvent(FarmerEvent{
+			Type:      EventPointsUnknown,
+			ChannelID: channelID,
+			Data:      UnknownPointsData{Type: evt.Type, Payload: raw.Data},
+		})
 	}
 }
 
@@ -317,97 +371,116 @@ func (p *PubSubClient) handleRaid(channelID, rawMessage string) {
 	}
 }
 
-func (p *PubSubClient) sendListen(topics []string) error {
-	nonce := generateNonce()
-	msg := PubSubOutgoing{
-		Type:  PubSubTypeListen,
-		Nonce: nonce,
-		Data: &PubSubListen{
-			Topics:    topics,
-			AuthToken: p.authToken,
-		},
-	}
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return err
-	}
-
-	return p.writeMessage(data)
-}
-
-// Listen subscribes to the given PubSub topics.
+// Listen subscribes to the given PubSub topics, opening another
+// connection when the existing ones are full.
 func (p *PubSubClient) Listen(topics []string) error {
 	p.mu.Lock()
+	batches := make(map[*pubsubShard][]string)
+	var spawned []*pubsubShard
 	for _, t := range topics {
-		p.topics[t] = true
+		if _, ok := p.topicShard[t]; ok {
+			continue
+		}
+		s := p.shardWithRoomLocked()
+		if s == nil {
+			s = p.newShardLocked()
+			if p.started {
+				spawned = append(spawned, s)
+			}
+		}
+		s.addTopic(t)
+		p.topicShard[t] = s
+		batches[s] = append(batches[s], t)
 	}
-	conn := p.conn
+	closed := p.closed
 	p.mu.Unlock()
 
-	if conn == nil {
-		return nil // Will subscribe on connect
+	if closed {
+		return nil
+	}
+	for _, s := range spawned {
+		p.sendError(fmt.Errorf("more than %d topics, opening connection %d", maxTopicsPerShard, s.id+1))
+		go s.run() // subscribes its topics on connect
 	}
 
-	return p.sendListen(topics)
+	var firstErr error
+	for s, ts := range batches {
+		if err := s.listen(ts); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
 }
 
-// Unlisten unsubscribes from the given topics.
+// Unlisten unsubscribes from the given topics, then consolidates shards
+// if the remaining topics fit on fewer connections.
 func (p *PubSubClient) Unlisten(topics []string) error {
 	p.mu.Lock()
+	batches := make(map[*pubsubShard][]string)
+	var cleared []topicReport
 	for _, t := range topics {
-		delete(p.topics, t)
+		delete(p.topicOwner, t)
+		s, ok := p.topicShard[t]
+		if !ok {
+			continue
+		}
+		delete(p.topicShard, t)
+		if s.removeTopic(t) {
+			cleared = append(cleared, topicReport{topic: t})
+		}
+		batches[s] = append(batches[s], t)
 	}
+	moves, retired := p.rebalanceLocked()
 	p.mu.Unlock()
+	p.reportTopics(cleared)
 
-	nonce := generateNonce()
-	msg := PubSubOutgoing{
-		Type:  PubSubTypeUnlisten,
-		Nonce: nonce,
-		Data: &PubSubListen{
-			Topics: topics,
-		},
+	var firstErr error
+	for s, ts := range batches {
+		if err := s.unlisten(ts); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	data, err := json.Marshal(msg)
-	if err != nil {
-		return err
+	// LISTEN on the new shard before closing the old one so the topic
+	// is never unsubscribed; a duplicate event in between is harmless
+	// (claims and raids are deduped downstream).
+	for s, ts := range moves {
+		if err := s.listen(ts); err != nil && firstErr == nil {
+			firstErr = err
+		}
 	}
-
-	return p.writeMessage(data)
+	for _, s := range retired {
+		s.stop()
+		p.setShardHealth(s, true)
+	}
+	return firstErr
 }
 
 // Close shuts down the PubSub client.
 func (p *PubSubClient) Close() {
 	p.mu.Lock()
-	defer p.mu.Unlock()
-
 	if p.closed {
+		p.mu.Unlock()
 		return
 	}
 	p.closed = true
 	close(p.closeCh)
+	shards := append([]*pubsubShard(nil), p.shards...)
+	p.mu.Unlock()
 
-	if p.conn != nil {
-		p.conn.Close()
+	for _, s := range shards {
+		s.stop()
 	}
 }
 
-func (p *PubSubClient) writeMessage(data []byte) error {
-	p.mu.Lock()
-	conn := p.conn
-	p.mu.Unlock()
-	if conn == nil {
-		return fmt.Errorf("not connected")
-	}
-	p.writeMu.Lock()
-	defer p.writeMu.Unlock()
-	return conn.WriteMessage(websocket.TextMessage, data)
-}
+// ConnStatus is an EventError payload that reports a routine connection
+// change (such as a successful (re)connect) rather than a failure.
+type ConnStatus string
+
+func (s ConnStatus) Error() string { return string(s) }
 
 func (p *PubSubClient) sendError(err error) {
 	select {
-	case p.events <- FarmerEvent{Type: EventError, Data: err}:
+	case p.events <- FarmerEvent{Type: EventError, Data: err, Queued: time.Now()}:
 	default:
 	}
 }
@@ -416,6 +489,7 @@ func (p *PubSubClient) emitEvent(ev FarmerEvent) {
 	timer := time.NewTimer(eventSendTimeout)
 	defer timer.Stop()
 
+	ev.Queued = time.Now()
 	select {
 	case p.events <- ev:
 	case <-p.closeCh:
@@ -425,6 +499,7 @@ func (p *PubSubClient) emitEvent(ev FarmerEvent) {
 }
 
 func (p *PubSubClient) emitEventDroppable(ev FarmerEvent) {
+	ev.Queued = time.Now()
 	select {
 	case p.events <- ev:
 	default:
@@ -498,3 +573,29 @@ func (p *PubSubClient) handleBroadcastSettings(channelID, rawMessage string) {
 		},
 	})
 }
+
+// handleMoment parses community-momento-user-v1 messages. Twitch sends
+// "active" when a streamer starts a Moment callout; the claim window is
+// short, so the event goes straight to the farmer for an immediate claim.
+func (p *PubSubClient) handleMoment(rawMessage string) {
+	var msg struct {
+		Type string `json:"type"`
+		Data struct {
+			MomentID  string `json:"moment_id"`
+			ChannelID string `json:"channel_id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(rawMessage), &msg); err != nil {
+		return
+	}
+	if msg.Type != "active" || msg.Data.MomentID == "" {
+		return
+	}
+	p.emitEvent(FarmerEvent{
+		Type:      EventMomentAvailable,
+		ChannelID: msg.Data.ChannelID,
+		Data: MomentData{
+			MomentID: msg.Data.MomentID,
+		},
+	})
+}
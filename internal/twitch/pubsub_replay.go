@@ -0,0 +1,84 @@
+package twitch
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// maxReplayLine bounds one capture line when replaying.
+const maxReplayLine = 4 << 20
+
+// PubSubRecord is one captured PubSub message — a line of a capture
+// file written by RecordTo and read by Replay.
+type PubSubRecord struct {
+	Time    time.Time `json:"time"`
+	Topic   string    `json:"topic"`
+	Message string    `json:"message"` // the raw inner JSON, as Twitch sent it
+}
+
+// RecordTo makes the client append every incoming PubSub message to w
+// as a JSON line (nil stops recording). Writes are serialized across
+// shards.
+func (p *PubSubClient) RecordTo(w io.Writer) {
+	p.recMu.Lock()
+	defer p.recMu.Unlock()
+	p.recorder = w
+}
+
+// record writes data to the recorder, if one is set.
+func (p *PubSubClient) record(data *PubSubMsgData) {
+	p.recMu.Lock()
+	defer p.recMu.Unlock()
+	if p.recorder == nil {
+		return
+	}
+	line, err := json.Marshal(PubSubRecord{Time: time.Now(), Topic: data.Topic, Message: data.Message})
+	if err != nil {
+		return
+	}
+	p.recorder.Write(append(line, '\n'))
+}
+
+// Replay feeds a capture file through the same handlers live messages
+// take, in order, so its events reach the client's event channel as if
+// Twitch had just sent them. Returns how many messages were replayed.
+// Replayed messages are not recorded again.
+func (p *PubSubClient) Replay(r io.Reader) (int, error) {
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64<<10), maxReplayLine)
+	n := 0
+	for line := 1; sc.Scan(); line++ {
+		if len(sc.Bytes()) == 0 {
+			continue
+		}
+		var rec PubSubRecord
+		if err := json.Unmarshal(sc.Bytes(), &rec); err != nil {
+			return n, fmt.Errorf("line %d: %w", line, err)
+		}
+		p.dispatch(&PubSubMsgData{Topic: rec.Topic, Message: rec.Message})
+		n++
+	}
+	return n, sc.Err()
+}
+
+// ReplayPubSub replays a capture file without a connection or login,
+// handing every event the handlers produce to fn (parse errors arrive as
+// EventError). Returns how many messages were replayed.
+func ReplayPubSub(r io.Reader, fn func(FarmerEvent)) (int, error) {
+	events := make(chan FarmerEvent, 100)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for ev := range events {
+			fn(ev)
+		}
+	}()
+	p := NewPubSubClient("", events)
+	n, err := p.Replay(r)
+	close(events)
+	<-done
+	return n, err
+}
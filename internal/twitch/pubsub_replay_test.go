@@ -0,0 +1,38 @@
+package twitch
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestPubSubRecordReplay checks that a recorded capture replays into the
+// same events the live messages produced.
+func TestPubSubRecordReplay(t *testing.T) {
+	live := make(chan FarmerEvent, 10)
+	p := NewPubSubClient("", live)
+	var capture bytes.Buffer
+	p.RecordTo(&capture)
+	p.handleMessage(&PubSubMsgData{Topic: "video-playback-by-id.42", Message: `{"type":"stream-up"}`})
+	p.handleMessage(&PubSubMsgData{Topic: "video-playback-by-id.42", Message: `{"type":"viewcount","viewers":7}`})
+	p.RecordTo(nil)
+	p.handleMessage(&PubSubMsgData{Topic: "video-playback-by-id.42", Message: `{"type":"stream-down"}`})
+
+	if got := strings.Count(capture.String(), "\n"); got != 2 {
+		t.Fatalf("captured %d lines, want 2:\n%s", got, capture.String())
+	}
+
+	var replayed []FarmerEvent
+	n, err := ReplayPubSub(&capture, func(ev FarmerEvent) { replayed = append(replayed, ev) })
+	if err != nil || n != 2 {
+		t.Fatalf("ReplayPubSub = %d, %v", n, err)
+	}
+	if len(replayed) != 2 || replayed[0].Type != EventStreamUp || replayed[0].ChannelID != "42" ||
+		replayed[1].Data.(ViewCountData).Viewers != 7 {
+		t.Fatalf("replayed events = %+v", replayed)
+	}
+
+	if _, err := ReplayPubSub(strings.NewReader("not json\n"), func(FarmerEvent) {}); err == nil {
+		t.Fatal("garbage capture replayed without error")
+	}
+}
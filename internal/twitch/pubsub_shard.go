@@ -0,0 +1,551 @@
+package twitch
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// listenBatchSize caps topics per LISTEN frame — Twitch rejects large
+// frames with "message too big".
+const listenBatchSize = 10
+
+// LISTEN verification. Every LISTEN frame carries a nonce that Twitch
+// echoes in its RESPONSE; topics whose LISTEN is rejected or goes
+// unanswered for listenAckTimeout are sent again with backoff, and
+// reported through the client's topic hook once listenWarnAfter tries
+// in a row have failed.
+const (
+	listenAckTimeout = 15 * time.Second
+	listenRetryBase  = 15 * time.Second
+	listenRetryMax   = 10 * time.Minute
+	listenWarnAfter  = 3
+	listenRetryTick  = 5 * time.Second
+)
+
+// pendingListen is a LISTEN frame awaiting its RESPONSE.
+type pendingListen struct {
+	topics []string
+	sentAt time.Time
+}
+
+// topicFailure tracks a topic whose LISTEN keeps failing.
+type topicFailure struct {
+	attempts int
+	err      string
+	retryAt  time.Time
+}
+
+// topicReport is a topic crossing listenWarnAfter (err set) or
+// recovering after it (err empty), for the client's topic hook.
+type topicReport struct {
+	topic string
+	err   string
+}
+
+// pubsubShard is one WebSocket connection in the PubSubClient pool. It
+// owns at most maxTopicsPerShard topics and reconnects independently of
+// the other shards.
+type pubsubShard struct {
+	id     int
+	client *PubSubClient
+
+	mu      sync.Mutex
+	writeMu sync.Mutex // serializes all WebSocket writes
+	conn    *websocket.Conn
+	topics  map[string]bool
+	pending map[string]pendingListen // nonce -> LISTEN awaiting its RESPONSE
+	failed  map[string]*topicFailure // topic -> failed LISTENs in a row
+	stopped bool
+	stopCh  chan struct{} // closed when the pool retires this shard
+}
+
+func newPubSubShard(id int, client *PubSubClient) *pubsubShard {
+	return &pubsubShard{
+		id:      id,
+		client:  client,
+		topics:  make(map[string]bool),
+		pending: make(map[string]pendingListen),
+		failed:  make(map[string]*topicFailure),
+		stopCh:  make(chan struct{}),
+	}
+}
+
+func (s *pubsubShard) addTopic(t string) {
+	s.mu.Lock()
+	s.topics[t] = true
+	s.mu.Unlock()
+}
+
+// removeTopic drops t from the shard. Returns whether t had been
+// reported through the topic hook as failing.
+func (s *pubsubShard) removeTopic(t string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	f := s.failed[t]
+	delete(s.topics, t)
+	delete(s.failed, t)
+	return f != nil && f.attempts >= listenWarnAfter
+}
+
+// inheritFailure carries t's failure record over from the shard it was
+// moved off, so its recovery on s is still reported.
+func (s *pubsubShard) inheritFailure(t string, from *pubsubShard) {
+	from.mu.Lock()
+	f := from.failed[t]
+	delete(from.failed, t)
+	from.mu.Unlock()
+	if f == nil {
+		return
+	}
+	s.mu.Lock()
+	s.failed[t] = f
+	s.mu.Unlock()
+}
+
+// subscribed reports whether t is LISTENed on the live connection:
+// sent, answered without error and not waiting for a retry.
+func (s *pubsubShard) subscribed(t string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil || !s.topics[t] || s.failed[t] != nil {
+		return false
+	}
+	for _, p := range s.pending {
+		for _, pt := range p.topics {
+			if pt == t {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *pubsubShard) hasTopic(t string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.topics[t]
+}
+
+func (s *pubsubShard) topicCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.topics)
+}
+
+func (s *pubsubShard) topicList() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	topics := make([]string, 0, len(s.topics))
+	for t := range s.topics {
+		topics = append(topics, t)
+	}
+	return topics
+}
+
+// logf reports a connection problem through the client's error channel.
+// The first shard keeps the unprefixed messages so single-connection
+// setups log exactly as before.
+func (s *pubsubShard) logf(format string, args ...interface{}) {
+	s.client.sendError(fmt.Errorf("%s", s.prefix(fmt.Sprintf(format, args...))))
+}
+
+// statusf is logf for routine status, sent as a ConnStatus.
+func (s *pubsubShard) statusf(format string, args ...interface{}) {
+	s.client.sendError(ConnStatus(s.prefix(fmt.Sprintf(format, args...))))
+}
+
+// prefix names the shard in msg unless it is the first one.
+func (s *pubsubShard) prefix(msg string) string {
+	if s.id > 0 {
+		return fmt.Sprintf("connection %d: %s", s.id+1, msg)
+	}
+	return msg
+}
+
+// done reports whether the shard should stop reconnecting.
+func (s *pubsubShard) done() bool {
+	select {
+	case <-s.client.closeCh:
+		return true
+	case <-s.stopCh:
+		return true
+	default:
+		return false
+	}
+}
+
+// run keeps the shard connected with exponential backoff until the
+// client closes or the pool retires the shard.
+func (s *pubsubShard) run() {
+	net := ActiveNetwork()
+	backoff := net.ReconnectBase
+	failures := 0
+
+	for {
+		if s.done() {
+			return
+		}
+
+		connectedAt := time.Now()
+		err := s.connectOnce()
+		if err == nil {
+			disconnectReason := s.readLoop()
+
+			// readLoop exited, check if intentionally closed
+			if s.done() {
+				return
+			}
+
+			// Only reset backoff if connection was stable
+			if time.Since(connectedAt) > net.StableAfter {
+				backoff = net.ReconnectBase
+				failures = 0
+			} else {
+				failures++
+			}
+
+			s.logf("disconnected (%s), reconnecting in %v", disconnectReason, backoff)
+		} else {
+			failures++
+			s.logf("connection failed: %v, retrying in %v", err, backoff)
+		}
+		if failures >= net.FailThreshold {
+			s.client.setShardHealth(s, false)
+		}
+
+		select {
+		case <-time.After(backoff):
+		case <-s.client.closeCh:
+			return
+		case <-s.stopCh:
+			return
+		}
+
+		backoff *= 2
+		if backoff > net.ReconnectMax {
+			backoff = net.ReconnectMax
+		}
+	}
+}
+
+func (s *pubsubShard) connectOnce() error {
+	conn, _, err := wsDialer().Dial(pubsubURL, nil)
+	if err != nil {
+		return fmt.Errorf("dial pubsub: %w", err)
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		conn.Close()
+		return fmt.Errorf("shard retired")
+	}
+	// Close old connection before replacing
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.conn = conn
+	topics := make([]string, 0, len(s.topics))
+	for t := range s.topics {
+		topics = append(topics, t)
+	}
+	// Nonces of the old connection get no answer any more; the
+	// resubscribe below settles every topic, failed ones included.
+	s.pending = make(map[string]pendingListen)
+	for _, f := range s.failed {
+		f.retryAt = time.Now().Add(listenRetryMax)
+	}
+	s.mu.Unlock()
+
+	if err := s.sendBatched(PubSubTypeListen, topics); err != nil {
+		conn.Close()
+		return fmt.Errorf("resubscribe batch: %w", err)
+	}
+
+	s.statusf("connected, subscribed to %d topics", len(topics))
+	return nil
+}
+
+func (s *pubsubShard) readLoop() string {
+	net := ActiveNetwork()
+	pingTicker := time.NewTicker(net.PingInterval)
+	defer pingTicker.Stop()
+
+	// done channel stops the ping goroutine when readLoop exits
+	done := make(chan struct{})
+	defer close(done)
+
+	// A connection that stays up clears an earlier unhealthy report.
+	stable := time.AfterFunc(net.StableAfter, func() {
+		s.client.setShardHealth(s, true)
+	})
+	defer stable.Stop()
+
+	// Start ping goroutine; it also retries failed LISTENs
+	retryTicker := time.NewTicker(listenRetryTick)
+	defer retryTicker.Stop()
+	go func() {
+		for {
+			select {
+			case now := <-retryTicker.C:
+				if retry := s.expireListens(now); len(retry) > 0 {
+					if err := s.listen(retry); err != nil {
+						return
+					}
+				}
+			case <-pingTicker.C:
+				msg := PubSubOutgoing{Type: PubSubTypePing}
+				data, _ := json.Marshal(msg)
+				if err := s.writeMessage(data); err != nil {
+					return
+				}
+				if net.PongTimeout > 0 {
+					// No PONG in time: the read fails and the shard
+					// reconnects and resubscribes its topics.
+					s.setReadDeadline(time.Now().Add(net.PongTimeout))
+				}
+			case <-done:
+				return
+			case <-s.client.closeCh:
+				return
+			case <-s.stopCh:
+				return
+			}
+		}
+	}()
+
+	for {
+		s.mu.Lock()
+		conn := s.conn
+		s.mu.Unlock()
+		if conn == nil {
+			return "connection lost"
+		}
+
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err.Error()
+		}
+
+		var incoming PubSubIncoming
+		if err := json.Unmarshal(message, &incoming); err != nil {
+			continue
+		}
+
+		switch incoming.Type {
+		case PubSubTypePong:
+			// Expected response to PING
+			if net.PongTimeout > 0 {
+				s.setReadDeadline(time.Time{})
+			}
+		case PubSubTypeReconn:
+			// Server requests reconnect
+			conn.Close()
+			return "server requested reconnect"
+		case PubSubTypeResponse:
+			s.handleResponse(incoming.Nonce, incoming.Error, time.Now())
+		case PubSubTypeMessage:
+			if incoming.Data != nil {
+				s.client.handleMessage(incoming.Data)
+			}
+		}
+	}
+}
+
+// setReadDeadline sets the read deadline of the live connection, if any.
+func (s *pubsubShard) setReadDeadline(t time.Time) {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn != nil {
+		_ = conn.SetReadDeadline(t)
+	}
+}
+
+// listen subscribes topics on the live connection. Without one the
+// topics are already in s.topics and get subscribed on connect.
+func (s *pubsubShard) listen(topics []string) error {
+	if !s.connected() {
+		return nil
+	}
+	return s.sendBatched(PubSubTypeListen, topics)
+}
+
+// unlisten unsubscribes topics on the live connection, if any.
+func (s *pubsubShard) unlisten(topics []string) error {
+	if !s.connected() {
+		return nil
+	}
+	return s.sendBatched(PubSubTypeUnlisten, topics)
+}
+
+func (s *pubsubShard) connected() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.conn != nil
+}
+
+// sendBatched sends LISTEN/UNLISTEN frames of at most listenBatchSize
+// topics each.
+func (s *pubsubShard) sendBatched(msgType string, topics []string) error {
+	for i := 0; i < len(topics); i += listenBatchSize {
+		end := i + listenBatchSize
+		if end > len(topics) {
+			end = len(topics)
+		}
+		msg := PubSubOutgoing{
+			Type:  msgType,
+			Nonce: generateNonce(),
+			Data: &PubSubListen{
+				Topics: topics[i:end],
+			},
+		}
+		if msgType == PubSubTypeListen {
+			msg.Data.AuthToken = s.client.authToken.get()
+		}
+		data, err := json.Marshal(msg)
+		if err != nil {
+			return err
+		}
+		if msgType == PubSubTypeListen {
+			s.mu.Lock()
+			s.pending[msg.Nonce] = pendingListen{topics: topics[i:end], sentAt: time.Now()}
+			s.mu.Unlock()
+		}
+		if err := s.writeMessage(data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// handleResponse matches a RESPONSE to its LISTEN by nonce and settles
+// the LISTEN's topics. Responses to UNLISTENs and PINGs aren't tracked;
+// an error on one is only logged.
+func (s *pubsubShard) handleResponse(nonce, errMsg string, now time.Time) {
+	s.mu.Lock()
+	pl, ok := s.pending[nonce]
+	if !ok {
+		s.mu.Unlock()
+		if errMsg != "" {
+			s.logf("listen error: %s", errMsg)
+		}
+		return
+	}
+	delete(s.pending, nonce)
+	reports := s.settleLocked(pl.topics, errMsg, now)
+	s.mu.Unlock()
+
+	if errMsg != "" {
+		s.logf("listen error for %d topics: %s", len(pl.topics), errMsg)
+	}
+	s.client.reportTopics(reports)
+}
+
+// expireListens fails LISTENs left unanswered for listenAckTimeout and
+// returns the failed topics due for another try at now.
+func (s *pubsubShard) expireListens(now time.Time) []string {
+	s.mu.Lock()
+	var reports []topicReport
+	expired := 0
+	for nonce, pl := range s.pending {
+		if now.Sub(pl.sentAt) < listenAckTimeout {
+			continue
+		}
+		delete(s.pending, nonce)
+		expired += len(pl.topics)
+		reports = append(reports, s.settleLocked(pl.topics, "no response", now)...)
+	}
+	var retry []string
+	for t, f := range s.failed {
+		if !now.Before(f.retryAt) {
+			// The retry's own RESPONSE (or timeout) reschedules it.
+			f.retryAt = now.Add(listenRetryMax)
+			retry = append(retry, t)
+		}
+	}
+	s.mu.Unlock()
+
+	if expired > 0 {
+		s.logf("no LISTEN response for %d topics", expired)
+	}
+	s.client.reportTopics(reports)
+	return retry
+}
+
+// settleLocked records the outcome of a LISTEN for the topics the shard
+// still owns: success clears a topic's failures, an error schedules a
+// retry with backoff. Returns the topics to report. Caller holds s.mu.
+func (s *pubsubShard) settleLocked(topics []string, errMsg string, now time.Time) []topicReport {
+	var reports []topicReport
+	for _, t := range topics {
+		if !s.topics[t] {
+			continue
+		}
+		f := s.failed[t]
+		if errMsg == "" {
+			if f != nil {
+				delete(s.failed, t)
+				if f.attempts >= listenWarnAfter {
+					reports = append(reports, topicReport{topic: t})
+				}
+			}
+			continue
+		}
+		if f == nil {
+			f = &topicFailure{}
+			s.failed[t] = f
+		}
+		f.attempts++
+		f.err = errMsg
+		backoff := listenRetryBase << (f.attempts - 1)
+		if backoff > listenRetryMax || backoff <= 0 {
+			backoff = listenRetryMax
+		}
+		f.retryAt = now.Add(backoff)
+		if f.attempts == listenWarnAfter {
+			reports = append(reports, topicReport{topic: t, err: errMsg})
+		}
+	}
+	return reports
+}
+
+func (s *pubsubShard) writeMessage(data []byte) error {
+	s.mu.Lock()
+	conn := s.conn
+	s.mu.Unlock()
+	if conn == nil {
+		return fmt.Errorf("not connected")
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return conn.WriteMessage(websocket.TextMessage, data)
+}
+
+// reconnect drops the current connection; run reconnects and LISTENs
+// to the shard's topics again.
+func (s *pubsubShard) reconnect() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
+
+// stop retires the shard: no more reconnects, connection closed.
+func (s *pubsubShard) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	close(s.stopCh)
+	if s.conn != nil {
+		s.conn.Close()
+	}
+}
@@ -0,0 +1,243 @@
+package twitch
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func testTopics(prefix string, n int) []string {
+	topics := make([]string, n)
+	for i := range topics {
+		topics[i] = fmt.Sprintf("%s.%d", prefix, i)
+	}
+	return topics
+}
+
+// TestPubSubListen_ShardsPastTopicLimit: topics beyond one connection's
+// cap must spill onto new shards, each at most maxTopicsPerShard.
+func TestPubSubListen_ShardsPastTopicLimit(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 16))
+	if err := p.Listen(testTopics("raid", 120)); err != nil {
+		t.Fatal(err)
+	}
+	if got := p.Connections(); got != 3 {
+		t.Fatalf("120 topics should need 3 shards, got %d", got)
+	}
+	total := 0
+	for _, s := range p.shards {
+		if n := s.topicCount(); n > maxTopicsPerShard {
+			t.Fatalf("shard %d holds %d topics, cap is %d", s.id, n, maxTopicsPerShard)
+		}
+		total += s.topicCount()
+	}
+	if total != 120 {
+		t.Fatalf("topics lost or duplicated across shards: %d", total)
+	}
+
+	// Re-listening an existing topic must not grow the pool.
+	p.Listen([]string{"raid.0"})
+	if got := p.Connections(); got != 3 {
+		t.Fatalf("duplicate Listen opened a shard: %d", got)
+	}
+}
+
+// TestPubSubUnlisten_ConsolidatesShards: once the remaining topics fit
+// on fewer connections, Unlisten must retire the emptiest shard and
+// move its topics rather than keep a half-empty connection open.
+func TestPubSubUnlisten_ConsolidatesShards(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 16))
+	all := testTopics("raid", 120)
+	p.Listen(all)
+
+	p.Unlisten(all[:60])
+	if got := p.Connections(); got != 2 {
+		t.Fatalf("60 remaining topics should fit on 2 shards, got %d", got)
+	}
+	for _, topic := range all[60:] {
+		s, ok := p.topicShard[topic]
+		if !ok || !s.topics[topic] {
+			t.Fatalf("topic %s lost during rebalance", topic)
+		}
+	}
+
+	p.Unlisten(all[60:110])
+	if got := p.Connections(); got != 1 {
+		t.Fatalf("10 remaining topics should fit on 1 shard, got %d", got)
+	}
+	if got := p.shards[0].topicCount(); got != 10 {
+		t.Fatalf("surviving shard holds %d topics, want 10", got)
+	}
+}
+
+// TestPubSubHealthHook_FiresOnPoolTransitions: the hook reports the pool,
+// not individual shards — a second failing shard or a repeat report must
+// not fire again, and recovery only fires once every shard is back.
+func TestPubSubHealthHook_FiresOnPoolTransitions(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 16))
+	p.Listen(testTopics("raid", 60))
+	var got []bool
+	p.SetHealthHook(func(healthy bool) { got = append(got, healthy) })
+
+	a, b := p.shards[0], p.shards[1]
+	p.setShardHealth(a, false)
+	p.setShardHealth(b, false)
+	p.setShardHealth(a, false)
+	p.setShardHealth(a, true)
+	p.setShardHealth(b, true)
+	p.setShardHealth(b, true)
+
+	if fmt.Sprint(got) != "[false true]" {
+		t.Fatalf("hook calls = %v, want [false true]", got)
+	}
+}
+
+// TestPubSubListenResponse_RetriesAndReports: a rejected LISTEN is
+// matched by nonce, retried with backoff, reported once it has failed
+// listenWarnAfter times and cleared when it finally succeeds.
+func TestPubSubListenResponse_RetriesAndReports(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 64))
+	p.Listen([]string{"raid.1"})
+	var got []string
+	p.SetTopicHook(func(topic string, err error) { got = append(got, fmt.Sprintf("%s=%v", topic, err)) })
+
+	s := p.shards[0]
+	now := time.Now()
+	send := func(nonce string) {
+		s.mu.Lock()
+		s.pending[nonce] = pendingListen{topics: []string{"raid.1"}, sentAt: now}
+		s.mu.Unlock()
+	}
+
+	send("a")
+	s.handleResponse("a", "ERR_BADAUTH", now)
+	if retry := s.expireListens(now); len(retry) != 0 {
+		t.Fatalf("retried before backoff: %v", retry)
+	}
+	now = now.Add(listenRetryBase)
+	if retry := s.expireListens(now); len(retry) != 1 {
+		t.Fatalf("retry after backoff = %v", retry)
+	}
+	send("b")
+	now = now.Add(listenAckTimeout)
+	s.expireListens(now) // "b" unanswered: second failure
+	send("c")
+	s.handleResponse("c", "ERR_BADAUTH", now)
+	send("d")
+	s.handleResponse("d", "", now)
+	s.handleResponse("unknown", "", now)
+
+	if want := "[raid.1=ERR_BADAUTH raid.1=<nil>]"; fmt.Sprint(got) != want {
+		t.Fatalf("hook calls = %v, want %s", got, want)
+	}
+	if len(s.failed) != 0 || len(s.pending) != 0 {
+		t.Fatalf("left failed=%v pending=%v", s.failed, s.pending)
+	}
+}
+
+// TestCommunityPoints_UnknownTypeForwarded: a community-points message of
+// a type without a handler must reach the farmer with its data payload.
+func TestCommunityPoints_UnknownTypeForwarded(t *testing.T) {
+	events := make(chan FarmerEvent, 1)
+	p := NewPubSubClient("tok", events)
+	p.handleCommunityPoints(`{"type":"goal-contribution-back","data":{"channel_id":"42","claim":{"id":"c1"}}}`)
+
+	select {
+	case ev := <-events:
+		d, ok := ev.Data.(UnknownPointsData)
+		if ev.Type != EventPointsUnknown || ev.ChannelID != "42" || !ok ||
+			d.Type != "goal-contribution-back" || string(d.Payload) != `{"channel_id":"42","claim":{"id":"c1"}}` {
+			t.Fatalf("event = %+v", ev)
+		}
+	default:
+		t.Fatal("unknown type was dropped")
+	}
+}
+
+// TestPubSubListenOwned_ReleasedByOwner: re-adding a channel must not
+// duplicate its topics, and UnlistenOwner releases exactly its topics.
+func TestPubSubListenOwned_ReleasedByOwner(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 16))
+	p.Listen([]string{"user.1"})
+	p.ListenOwned("10", []string{"raid.10", "hype.10"})
+	p.ListenOwned("20", []string{"raid.20"})
+	p.ListenOwned("10", []string{"raid.10", "hype.10"})
+
+	if got := fmt.Sprint(p.Topics()); got != "[hype.10 raid.10 raid.20 user.1]" {
+		t.Fatalf("Topics() = %s", got)
+	}
+	if got := p.shards[0].topicCount(); got != 4 {
+		t.Fatalf("shard holds %d topics, want 4", got)
+	}
+	p.UnlistenOwner("10")
+	if got := fmt.Sprint(p.Topics()); got != "[raid.20 user.1]" {
+		t.Fatalf("after UnlistenOwner Topics() = %s", got)
+	}
+	if got := p.OwnedTopics("10"); len(got) != 0 {
+		t.Fatalf("owner 10 still owns %v", got)
+	}
+}
+
+// TestPubSubVerify_RepairsBookkeeping: a topic duplicated on a second
+// shard, one its shard lost and one whose owner is gone must all be
+// put right, leaving every topic on exactly one shard.
+func TestPubSubVerify_RepairsBookkeeping(t *testing.T) {
+	p := NewPubSubClient("tok", make(chan FarmerEvent, 16))
+	p.Listen(testTopics("raid", 60))
+	p.ListenOwned("gone", []string{"hype.gone"})
+	p.ListenOwned("here", []string{"hype.here"})
+
+	a, b := p.shards[0], p.shards[1]
+	b.addTopic("raid.0")       // duplicate of a topic on a
+	a.removeTopic("raid.1")    // lost by its shard
+	b.addTopic("raid.unknown") // never listened through the pool
+
+	check, err := p.Verify(func(owner string) bool { return owner != "gone" })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(check.Strays); got != "[raid.0 raid.unknown]" {
+		t.Errorf("Strays = %s", got)
+	}
+	if got := fmt.Sprint(check.Missing); got != "[raid.1]" {
+		t.Errorf("Missing = %s", got)
+	}
+	if got := fmt.Sprint(check.Orphaned); got != "[hype.gone]" {
+		t.Errorf("Orphaned = %s", got)
+	}
+
+	held := make(map[string]int)
+	for _, s := range p.shards {
+		for _, topic := range s.topicList() {
+			held[topic]++
+			if p.topicShard[topic] != s {
+				t.Errorf("%s held by shard %d, pool says another", topic, s.id)
+			}
+		}
+	}
+	if len(held) != 61 || held["raid.1"] != 1 || held["hype.here"] != 1 {
+		t.Errorf("held %d topics after repair: %v", len(held), held)
+	}
+	for topic, n := range held {
+		if n != 1 {
+			t.Errorf("%s on %d shards", topic, n)
+		}
+	}
+
+	if check, _ := p.Verify(nil); !check.Empty() {
+		t.Errorf("second Verify found %+v", check)
+	}
+}
+
+func TestReasonLabel(t *testing.T) {
+	for code, want := range map[string]string{
+		"WATCH_STREAK":                  "watch streak",
+		"CLAIM":                         "bonus claim",
+		"COMMUNITY_GOAL_CONTRIBUTION_X": "other (COMMUNITY_GOAL_CONTRIBUTION_X)",
+		"":                              "unknown reason",
+	} {
+		if got := ReasonLabel(code); got != want {
+			t.Errorf("ReasonLabel(%q) = %q, want %q", code, got, want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package twitch
+
+import "sort"
+
+// TopicCheck is what Verify found wrong with the topic bookkeeping and
+// repaired. Each list is sorted.
+type TopicCheck struct {
+	// Strays are topics a shard held without owning them (a duplicate
+	// of a topic on another shard, or one the pool had forgotten); they
+	// were unlistened there.
+	Strays []string
+	// Missing are topics the pool counted as subscribed that their shard
+	// no longer held; they were listened again.
+	Missing []string
+	// Orphaned are topics whose owner (ListenOwned) is gone; they were
+	// unlistened.
+	Orphaned []string
+}
+
+// Empty reports whether Verify found nothing to repair.
+func (c TopicCheck) Empty() bool {
+	return len(c.Strays) == 0 && len(c.Missing) == 0 && len(c.Orphaned) == 0
+}
+
+// ListenOwned subscribes topics like Listen and records owner (a
+// channel ID) as theirs, so UnlistenOwner can release them together
+// and Verify can drop them once the owner is gone.
+func (p *PubSubClient) ListenOwned(owner string, topics []string) error {
+	p.mu.Lock()
+	for _, t := range topics {
+		p.topicOwner[t] = owner
+	}
+	p.mu.Unlock()
+	return p.Listen(topics)
+}
+
+// UnlistenOwner unsubscribes every topic owned by owner.
+func (p *PubSubClient) UnlistenOwner(owner string) error {
+	topics := p.OwnedTopics(owner)
+	if len(topics) == 0 {
+		return nil
+	}
+	return p.Unlisten(topics)
+}
+
+// OwnedTopics returns the subscribed topics owned by owner, sorted.
+func (p *PubSubClient) OwnedTopics(owner string) []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var topics []string
+	for t, o := range p.topicOwner {
+		if o == owner {
+			topics = append(topics, t)
+		}
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// OwnerSubscribed reports whether owner has topics and every one of
+// them is confirmed subscribed on a live connection.
+func (p *PubSubClient) OwnerSubscribed(owner string) bool {
+	p.mu.Lock()
+	var shards []*pubsubShard
+	var topics []string
+	for t, o := range p.topicOwner {
+		if o == owner {
+			topics = append(topics, t)
+			shards = append(shards, p.topicShard[t])
+		}
+	}
+	p.mu.Unlock()
+	if len(topics) == 0 {
+		return false
+	}
+	for i, t := range topics {
+		if shards[i] == nil || !shards[i].subscribed(t) {
+			return false
+		}
+	}
+	return true
+}
+
+// Topics returns every subscribed topic, sorted.
+func (p *PubSubClient) Topics() []string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	topics := make([]string, 0, len(p.topicShard))
+	for t := range p.topicShard {
+		topics = append(topics, t)
+	}
+	sort.Strings(topics)
+	return topics
+}
+
+// Verify checks the pool's topic map against what each shard holds and
+// repairs the difference: every topic must sit on exactly one open
+// shard, and every owned topic's owner must still be alive. alive is
+// called without the client's lock held; nil skips the owner check.
+func (p *PubSubClient) Verify(alive func(owner string) bool) (TopicCheck, error) {
+	var check TopicCheck
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return check, nil
+	}
+	open := make(map[*pubsubShard]bool, len(p.shards))
+	strays := make(map[*pubsubShard][]string)
+	var cleared []topicReport
+	for _, s := range p.shards {
+		open[s] = true
+		for _, t := range s.topicList() {
+			if p.topicShard[t] == s {
+				continue
+			}
+			if s.removeTopic(t) {
+				cleared = append(cleared, topicReport{topic: t})
+			}
+			strays[s] = append(strays[s], t)
+			check.Strays = append(check.Strays, t)
+		}
+	}
+	for t := range p.topicOwner {
+		if _, ok := p.topicShard[t]; !ok {
+			delete(p.topicOwner, t) // unlistened behind our back
+		}
+	}
+	for t, s := range p.topicShard {
+		if open[s] && s.hasTopic(t) {
+			continue
+		}
+		delete(p.topicShard, t) // Listen below places it again
+		check.Missing = append(check.Missing, t)
+	}
+	owners := make(map[string]string, len(p.topicOwner))
+	for t, o := range p.topicOwner {
+		owners[t] = o
+	}
+	p.mu.Unlock()
+	p.reportTopics(cleared)
+
+	var firstErr error
+	for s, ts := range strays {
+		if err := s.unlisten(ts); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(check.Missing) > 0 {
+		if err := p.Listen(check.Missing); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	if alive != nil {
+		dead := make(map[string]bool)
+		for t, o := range owners {
+			isDead, seen := dead[o]
+			if !seen {
+				isDead = !alive(o)
+				dead[o] = isDead
+			}
+			if isDead {
+				check.Orphaned = append(check.Orphaned, t)
+			}
+		}
+		if len(check.Orphaned) > 0 {
+			if err := p.Unlisten(check.Orphaned); err != nil && firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+	sort.Strings(check.Strays)
+	sort.Strings(check.Missing)
+	sort.Strings(check.Orphaned)
+	return check, firstErr
+}
@@ -0,0 +1,153 @@
+package twitch
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	queryChannelCustomRewards = `query ChannelCustomRewards($channelLogin: String!) {
+		community(name: $channelLogin) {
+			channel {
+				id
+				communityPointsSettings {
+					customRewards {
+						id title prompt cost
+						isEnabled isPaused isInStock isUserInputRequired
+						cooldownExpiresAt
+					}
+				}
+			}
+		}
+	}`
+
+	mutationRedeemCustomReward = `mutation RedeemCustomReward($input: RedeemCommunityPointsCustomRewardInput!) {
+		redeemCommunityPointsCustomReward(input: $input) {
+			redemption { id }
+			error { code }
+		}
+	}`
+)
+
+// CustomReward is a channel's custom channel-points reward as the viewer
+// sees it.
+type CustomReward struct {
+	ID                  string
+	Title               string
+	Prompt              string
+	Cost                int
+	IsEnabled           bool
+	IsPaused            bool
+	IsInStock           bool // false once a per-stream / per-user limit is hit
+	IsUserInputRequired bool
+	CooldownExpiresAt   time.Time // zero when not cooling down
+}
+
+// Redeemable reports whether the reward can be redeemed right now,
+// ignoring balance. The reason is "" when it can.
+func (r CustomReward) Redeemable(now time.Time) (bool, string) {
+	switch {
+	case !r.IsEnabled:
+		return false, "disabled"
+	case r.IsPaused:
+		return false, "paused"
+	case !r.IsInStock:
+		return false, "out of stock"
+	case r.CooldownExpiresAt.After(now):
+		return false, "on cooldown"
+	}
+	return true, ""
+}
+
+// GetCustomRewards returns the channel ID and custom rewards of a
+// channel.
+func (g *GQLClient) GetCustomRewards(channelLogin string) (string, []CustomReward, error) {
+	req := &GQLRequest{
+		Query: queryChannelCustomRewards,
+		Variables: map[string]interface{}{
+			"channelLogin": strings.ToLower(channelLogin),
+		},
+	}
+
+	var data struct {
+		Community *gqlCommunity `json:"community"`
+	}
+	if err := g.query(req, &data); err != nil {
+		return "", nil, fmt.Errorf("get custom rewards: %w", err)
+	}
+	if data.Community == nil || data.Community.Channel == nil {
+		return "", nil, fmt.Errorf("get custom rewards: channel %s not found", channelLogin)
+	}
+	channel := data.Community.Channel
+	var list []gqlCustomReward
+	if channel.CommunityPointsSettings != nil {
+		list = channel.CommunityPointsSettings.CustomRewards
+	}
+
+	rewards := make([]CustomReward, 0, len(list))
+	for _, m := range list {
+		rewards = append(rewards, CustomReward{
+			ID:                  m.ID,
+			Title:               m.Title,
+			Prompt:              m.Prompt,
+			Cost:                m.Cost,
+			IsEnabled:           m.IsEnabled,
+			IsPaused:            m.IsPaused,
+			IsInStock:           m.IsInStock,
+			IsUserInputRequired: m.IsUserInputRequired,
+			CooldownExpiresAt:   m.CooldownExpiresAt.Time,
+		})
+	}
+	return channel.ID, rewards, nil
+}
+
+// RedeemCustomReward redeems a custom reward and returns the redemption
+// ID. input is sent as the viewer's text for rewards that require it.
+// Twitch reports refusals (not enough points, cooldown, limit reached)
+// in the payload; those come back as errors carrying the code.
+func (g *GQLClient) RedeemCustomReward(channelID string, reward CustomReward, input string) (string, error) {
+	payload := map[string]interface{}{
+		"channelID":     channelID,
+		"rewardID":      reward.ID,
+		"title":         reward.Title,
+		"cost":          reward.Cost,
+		"prompt":        nil,
+		"transactionID": generateNonce(),
+	}
+	if input != "" {
+		payload["textInput"] = input
+	}
+	if reward.Prompt != "" {
+		payload["prompt"] = reward.Prompt
+	}
+	req := &GQLRequest{
+		OperationName: "RedeemCustomReward",
+		Query:         mutationRedeemCustomReward,
+		Variables: map[string]interface{}{
+			"input": payload,
+		},
+	}
+
+	var data struct {
+		Redeem *struct {
+			Redemption *struct {
+				ID string `json:"id"`
+			} `json:"redemption"`
+			Error *gqlError `json:"error"`
+		} `json:"redeemCommunityPointsCustomReward"`
+	}
+	if err := g.query(req, &data); err != nil {
+		return "", fmt.Errorf("redeem reward: %w", err)
+	}
+	if data.Redeem == nil {
+		return "", nil
+	}
+	if code := data.Redeem.Error.errorCode(); code != "" {
+		return "", fmt.Errorf("redeem rejected: %s", code)
+	}
+	if data.Redeem.Redemption == nil {
+		return "", nil
+	}
+	return data.Redeem.Redemption.ID, nil
+}
@@ -0,0 +1,80 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrShutdown is returned for requests made after a client's Shutdown.
+var ErrShutdown = errors.New("twitch: client is shut down")
+
+// requestGate tracks a client's requests in flight so Shutdown can let
+// them finish — a claim that reached Twitch should get its answer —
+// before cutting off whatever is left. The zero value is open.
+type requestGate struct {
+	mu       sync.Mutex
+	closed   bool
+	ctx      context.Context // cancelled once the wait is over
+	cancel   context.CancelFunc
+	inflight sync.WaitGroup
+}
+
+// enter registers a request and returns the context to send it with,
+// or ErrShutdown once shutdown has begun. Every successful enter needs
+// a leave.
+func (g *requestGate) enter() (context.Context, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return nil, ErrShutdown
+	}
+	if g.ctx == nil {
+		g.ctx, g.cancel = context.WithCancel(context.Background())
+	}
+	g.inflight.Add(1)
+	return g.ctx, nil
+}
+
+func (g *requestGate) leave() {
+	g.inflight.Done()
+}
+
+// shutdown refuses new requests, waits for those in flight until ctx is
+// done and then cancels the rest. It returns ctx's error if requests
+// had to be cut off.
+func (g *requestGate) shutdown(ctx context.Context) error {
+	g.mu.Lock()
+	g.closed = true
+	cancel := g.cancel
+	g.mu.Unlock()
+	if cancel == nil {
+		return nil // never used
+	}
+	defer cancel()
+
+	done := make(chan struct{})
+	go func() {
+		g.inflight.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// sleep waits d, or less when ctx is cancelled first.
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,44 @@
+package twitch
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRequestGate(t *testing.T) {
+	var g requestGate
+	reqCtx, err := g.enter()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The request in flight finishes within the deadline.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		g.leave()
+	}()
+	if err := g.shutdown(context.Background()); err != nil {
+		t.Fatalf("shutdown = %v, want nil after the request finished", err)
+	}
+	if reqCtx.Err() == nil {
+		t.Error("request context not cancelled after shutdown")
+	}
+	if _, err := g.enter(); !errors.Is(err, ErrShutdown) {
+		t.Errorf("enter after shutdown = %v, want ErrShutdown", err)
+	}
+
+	// A request that doesn't finish in time is cancelled.
+	var slow requestGate
+	reqCtx, _ = slow.enter()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := slow.shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("shutdown = %v, want DeadlineExceeded", err)
+	}
+	if reqCtx.Err() == nil {
+		t.Error("stuck request not cancelled")
+	}
+	slow.leave()
+}
@@ -1,10 +1,12 @@
 package twitch
 
 import (
+	"context"
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
+	"math/rand/v2"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -31,6 +33,22 @@ const (
 	browserUserAgent = "Dalvik/2.1.0 (Linux; U; Android 16; SM-S911B Build/TP1A.220624.014) tv.twitch.android.app/25.3.0/2503006"
 )
 
+// defaultHeartbeatJitter spreads heartbeats ± around heartbeatInterval
+// unless SetJitter says otherwise: a browser tab never fires on the exact
+// same second, a fixed 60.000s cadence stands out.
+const defaultHeartbeatJitter = 10 * time.Second
+
+// HeartbeatFailLimit is the number of heartbeats in a row (each already
+// retried) a channel may fail before it is considered unwatchable.
+const HeartbeatFailLimit = 3
+
+// HeartbeatHealth is how a watched channel's heartbeats are going.
+type HeartbeatHealth struct {
+	LastSuccess time.Time // last heartbeat Twitch accepted; zero if none yet
+	Failures    int       // heartbeats failed in a row since then
+	LastSent    time.Time // last heartbeat sent, accepted or not
+}
+
 // SpadeTracker sends minute-watched heartbeats for watch credit. It
 // POSTs the legacy event payload to the beacon/spade endpoint resolved
 // at Start() (see fetchSpadeURL). Since 2026-07-10 this pipeline carries
@@ -40,17 +58,35 @@ const (
 // See sendHeartbeat for the long-form pipeline rationale.
 type SpadeTracker struct {
 	userID     string
-	authToken  string
+	authToken  tokenBox
 	deviceID   string // kept for legacy fallback; no longer used by GQL path
 	spadeURL   string // POST target for channel-points heartbeats; resolved at Start()
+	urlErr     error  // why Start fell back to spadeURLFallback, if it did
 	gql        *GQLClient
 	httpClient *http.Client
 	logFunc    func(string, ...interface{})
 
+	// OnHeartbeat, if set, is called after every heartbeat with the
+	// channel's updated health. Set it before Start.
+	OnHeartbeat func(channelID string, h HeartbeatHealth)
+
+	// Warn, if set, gets heartbeat failures in place of the log func.
+	// Set it before Start.
+	Warn func(string, ...interface{})
+
+	// Per-session player identity sent with every heartbeat, like a web
+	// player tab keeps for its lifetime.
+	loginSessionID string
+	viewport       playerSize
+
 	mu       sync.Mutex
+	jitter   time.Duration
 	channels map[string]*spadeChannel // channelID -> channel
 	stopCh   chan struct{}
 	stopped  bool
+
+	gate  requestGate    // heartbeats in flight, for Shutdown
+	loops sync.WaitGroup // running heartbeatLoops
 }
 
 type spadeChannel struct {
@@ -60,6 +96,7 @@ type spadeChannel struct {
 	gameName     string
 	gameID       string
 	stopCh       chan struct{}
+	health       HeartbeatHealth // guarded by SpadeTracker.mu
 }
 
 // NewSpadeTracker creates a new Spade tracker for sending watch heartbeats.
@@ -67,15 +104,40 @@ type spadeChannel struct {
 // mutation through it (the only credit-honored path on modern Twitch).
 func NewSpadeTracker(userID, authToken, deviceID string, gql *GQLClient, logFunc func(string, ...interface{})) *SpadeTracker {
 	return &SpadeTracker{
-		userID:     userID,
-		authToken:  authToken,
-		deviceID:   deviceID,
-		gql:        gql,
-		httpClient: &http.Client{Timeout: 10 * time.Second},
-		logFunc:    logFunc,
-		channels:   make(map[string]*spadeChannel),
-		stopCh:     make(chan struct{}),
+		userID:         userID,
+		authToken:      tokenBox{token: authToken},
+		deviceID:       deviceID,
+		gql:            gql,
+		httpClient:     NewHTTPClient(ActiveNetwork().SpadeTimeout),
+		logFunc:        logFunc,
+		loginSessionID: generateSessionID(),
+		viewport:       viewportSizes[rand.IntN(len(viewportSizes))],
+		jitter:         defaultHeartbeatJitter,
+		channels:       make(map[string]*spadeChannel),
+		stopCh:         make(chan struct{}),
+	}
+}
+
+// SetJitter sets how far each heartbeat may land before or after its
+// 60s mark; 0 sends every heartbeat on the mark. Takes effect from the
+// next heartbeat.
+func (s *SpadeTracker) SetJitter(d time.Duration) {
+	d = max(d, 0)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.jitter = d
+}
+
+// nextHeartbeat returns the wait until the next heartbeat: the interval
+// plus or minus up to the jitter.
+func (s *SpadeTracker) nextHeartbeat() time.Duration {
+	s.mu.Lock()
+	j := s.jitter
+	s.mu.Unlock()
+	if j <= 0 {
+		return heartbeatInterval
 	}
+	return heartbeatInterval - j + time.Duration(rand.Int64N(int64(2*j)+1))
 }
 
 // Start initializes the Spade tracker and fetches the Spade URL.
@@ -86,10 +148,17 @@ func (s *SpadeTracker) Start() error {
 	} else {
 		s.spadeURL = spadeURL
 	}
+	s.urlErr = err
 	s.log("[Spade] using URL: %s", s.spadeURL)
 	return err
 }
 
+// URL returns the heartbeat URL and, when Start couldn't resolve it from
+// Twitch's page and fell back to the beacon default, why not.
+func (s *SpadeTracker) URL() (string, error) {
+	return s.spadeURL, s.urlErr
+}
+
 // StartWatching begins sending heartbeats for a channel.
 // Returns false if at max capacity OR after Stop() has been called.
 func (s *SpadeTracker) StartWatching(channelID, channelLogin, broadcastID, gameName, gameID string) bool {
@@ -131,6 +200,7 @@ func (s *SpadeTracker) StartWatching(channelID, channelLogin, broadcastID, gameN
 	}
 	s.channels[channelID] = ch
 
+	s.loops.Add(1)
 	go s.heartbeatLoop(ch)
 	return true
 }
@@ -171,34 +241,58 @@ func (s *SpadeTracker) ActiveSlots() int {
 	return maxWatchedChannels - len(s.channels)
 }
 
-// Stop shuts down all heartbeat loops.
-func (s *SpadeTracker) Stop() {
+// Health returns the heartbeat health of a watched channel; false if the
+// channel isn't being watched.
+func (s *SpadeTracker) Health(channelID string) (HeartbeatHealth, bool) {
 	s.mu.Lock()
-	if s.stopped {
-		s.mu.Unlock()
-		return
+	defer s.mu.Unlock()
+	ch, ok := s.channels[channelID]
+	if !ok {
+		return HeartbeatHealth{}, false
 	}
-	s.stopped = true
-	close(s.stopCh)
+	return ch.health, true
+}
 
-	for id, ch := range s.channels {
-		close(ch.stopCh)
-		delete(s.channels, id)
+// Stop shuts down all heartbeat loops, cancelling heartbeats in flight.
+func (s *SpadeTracker) Stop() {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_ = s.Shutdown(ctx)
+}
+
+// Shutdown stops all heartbeat loops and waits for them to exit. A
+// heartbeat in flight may finish until ctx is done and is cancelled
+// after that, in which case ctx's error is returned.
+func (s *SpadeTracker) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	if !s.stopped {
+		s.stopped = true
+		close(s.stopCh)
+		for id, ch := range s.channels {
+			close(ch.stopCh)
+			delete(s.channels, id)
+		}
 	}
 	s.mu.Unlock()
+
+	err := s.gate.shutdown(ctx)
+	s.loops.Wait()
+	return err
 }
 
 func (s *SpadeTracker) heartbeatLoop(ch *spadeChannel) {
+	defer s.loops.Done()
 	// Send first heartbeat immediately
-	s.sendHeartbeat(ch)
+	s.recordHeartbeat(ch, s.sendHeartbeat(ch))
 
-	ticker := time.NewTicker(heartbeatInterval)
-	defer ticker.Stop()
+	timer := time.NewTimer(s.nextHeartbeat())
+	defer timer.Stop()
 
 	for {
 		select {
-		case <-ticker.C:
-			s.sendHeartbeat(ch)
+		case <-timer.C:
+			s.recordHeartbeat(ch, s.sendHeartbeat(ch))
+			timer.Reset(s.nextHeartbeat())
 		case <-ch.stopCh:
 			return
 		case <-s.stopCh:
@@ -207,9 +301,26 @@ func (s *SpadeTracker) heartbeatLoop(ch *spadeChannel) {
 	}
 }
 
-const heartbeatMaxRetries = 2
+// recordHeartbeat updates ch's health with a heartbeat's outcome and
+// hands it to OnHeartbeat.
+func (s *SpadeTracker) recordHeartbeat(ch *spadeChannel, ok bool) {
+	s.mu.Lock()
+	now := time.Now()
+	if ok {
+		ch.health = HeartbeatHealth{LastSuccess: now}
+	} else {
+		ch.health.Failures++
+	}
+	ch.health.LastSent = now
+	h := ch.health
+	s.mu.Unlock()
+	if s.OnHeartbeat != nil {
+		s.OnHeartbeat(ch.channelID, h)
+	}
+}
 
-// sendHeartbeat posts the minute-watched event for watch credit.
+// sendHeartbeat posts the minute-watched event for watch credit and
+// reports whether Twitch accepted it.
 //
 // Pipeline history:
 //
@@ -232,7 +343,7 @@ const heartbeatMaxRetries = 2
 // points went silent for hours until the user noticed (real Twitch web
 // session re-credited cpt_blackshark immediately, confirming the bot
 // alone wasn't reaching the WATCH-credit pipeline).
-func (s *SpadeTracker) sendHeartbeat(ch *spadeChannel) {
+func (s *SpadeTracker) sendHeartbeat(ch *spadeChannel) bool {
 	// Snapshot the mutable fields under s.mu. UpdateBroadcastID and
 	// StartWatching write to ch.broadcastID/gameName/gameID under the
 	// same lock; without snapshotting we'd race against them on every
@@ -253,56 +364,61 @@ func (s *SpadeTracker) sendHeartbeat(ch *spadeChannel) {
 	uidInt, err := strconv.ParseInt(s.userID, 10, 64)
 	if err != nil {
 		s.log("[Spade] skip heartbeat for %s: non-numeric user_id %q: %v", ch.channelLogin, s.userID, err)
-		return
+		return false
 	}
 
+	props := map[string]interface{}{
+		"channel_id":     channelID,
+		"broadcast_id":   broadcastID,
+		"player":         "site",
+		"user_id":        uidInt,
+		"channel":        channelLogin,
+		"client_time":    time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
+		"game":           gameName,
+		"game_id":        gameID,
+		"hidden":         false,
+		"is_live":        true,
+		"live":           true,
+		"location":       "channel",
+		"logged_in":      true,
+		"minutes_logged": 1,
+		"muted":          false,
+	}
+	s.addPlayerProperties(props)
 	payload := []map[string]interface{}{
-		{
-			"event": "minute-watched",
-			"properties": map[string]interface{}{
-				"channel_id":     channelID,
-				"broadcast_id":   broadcastID,
-				"player":         "site",
-				"user_id":        uidInt,
-				"channel":        channelLogin,
-				"client_time":    time.Now().UTC().Format("2006-01-02T15:04:05.000Z"),
-				"game":           gameName,
-				"game_id":        gameID,
-				"hidden":         false,
-				"is_live":        true,
-				"live":           true,
-				"location":       "channel",
-				"logged_in":      true,
-				"minutes_logged": 1,
-				"muted":          false,
-			},
-		},
+		{"event": "minute-watched", "properties": props},
 	}
 
 	jsonData, err := json.Marshal(payload)
 	if err != nil {
-		return
+		return false
 	}
 
 	encoded := base64.StdEncoding.EncodeToString(jsonData)
 	body := url.Values{"data": {encoded}}.Encode()
 
-	for attempt := range heartbeatMaxRetries + 1 {
-		req, err := http.NewRequest("POST", s.spadeURL, strings.NewReader(body))
+	ctx, err := s.gate.enter()
+	if err != nil {
+		return false
+	}
+	defer s.gate.leave()
+
+	net := ActiveNetwork()
+	for attempt := range net.SpadeRetries + 1 {
+		req, err := http.NewRequestWithContext(ctx, "POST", s.spadeURL, strings.NewReader(body))
 		if err != nil {
-			return
+			return false
 		}
 		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		req.Header.Set("User-Agent", browserUserAgent)
 
 		resp, err := s.httpClient.Do(req)
 		if err != nil {
-			if attempt < heartbeatMaxRetries {
-				time.Sleep(time.Duration(attempt+1) * 3 * time.Second)
+			if attempt < net.SpadeRetries && s.retryWait(attempt) {
 				continue
 			}
-			s.log("[Spade] heartbeat failed for %s after %d attempts: %v", channelLogin, attempt+1, err)
-			return
+			s.warn("[Spade] heartbeat failed for %s after %d attempts: %v", channelLogin, attempt+1, err)
+			return false
 		}
 		_, _ = io.Copy(io.Discard, resp.Body)
 		_ = resp.Body.Close()
@@ -312,15 +428,54 @@ func (s *SpadeTracker) sendHeartbeat(ch *spadeChannel) {
 		// technically valid but the credit subsystem rejected it
 		// (anti-cheat). Treating that as success would mask failures.
 		if resp.StatusCode == http.StatusNoContent {
-			return
+			return true
 		}
-		if attempt < heartbeatMaxRetries {
-			time.Sleep(time.Duration(attempt+1) * 3 * time.Second)
+		if attempt < net.SpadeRetries && s.retryWait(attempt) {
 			continue
 		}
 		s.log("[Spade] heartbeat for %s returned HTTP %d after %d attempts", channelLogin, resp.StatusCode, attempt+1)
-		return
+		return false
 	}
+	return false
+}
+
+// retryWait sleeps before heartbeat retry attempt+1 and reports whether
+// to go ahead: a Stop during the wait ends the retries.
+func (s *SpadeTracker) retryWait(attempt int) bool {
+	t := time.NewTimer(time.Duration(attempt+1) * ActiveNetwork().SpadeRetryStep)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-s.stopCh:
+		return false
+	}
+}
+
+// playerSize is a browser viewport in CSS pixels.
+type playerSize struct{ width, height int }
+
+// viewportSizes are common desktop viewports; each tracker picks one
+// for its session.
+var viewportSizes = []playerSize{
+	{1920, 969}, {1536, 730}, {1366, 641}, {1440, 789}, {2560, 1271},
+}
+
+// addPlayerProperties adds the session and player fields the web player
+// sends with minute-watched to a heartbeat's properties. They describe
+// the default layout: side nav and chat open, video 16:9 in between.
+// None of them decides credit; they only keep the heartbeat from
+// looking like a bare script.
+func (s *SpadeTracker) addPlayerProperties(props map[string]interface{}) {
+	vidWidth := s.viewport.width - 240 - 340 // side nav, chat
+	props["device_id"] = s.deviceID
+	props["login_session_id"] = s.loginSessionID
+	props["viewport_width"] = s.viewport.width
+	props["viewport_height"] = s.viewport.height
+	props["vid_display_width"] = vidWidth
+	props["vid_display_height"] = vidWidth * 9 / 16
+	props["player_size_mode"] = "default"
+	props["content_mode"] = "live"
 }
 
 func (s *SpadeTracker) log(format string, args ...interface{}) {
@@ -329,6 +484,14 @@ func (s *SpadeTracker) log(format string, args ...interface{}) {
 	}
 }
 
+func (s *SpadeTracker) warn(format string, args ...interface{}) {
+	if s.Warn != nil {
+		s.Warn(format, args...)
+		return
+	}
+	s.log(format, args...)
+}
+
 func (s *SpadeTracker) fetchSpadeURL() (string, error) {
 	// Step 1: Fetch Twitch page to find the settings JS URL
 	req, err := http.NewRequest("GET", "https://www.twitch.tv", nil)
@@ -0,0 +1,74 @@
+package twitch
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecordHeartbeat(t *testing.T) {
+	s := NewSpadeTracker("1", "", "", nil, nil)
+	var got []HeartbeatHealth
+	s.OnHeartbeat = func(channelID string, h HeartbeatHealth) {
+		if channelID != "42" {
+			t.Errorf("OnHeartbeat channel = %q", channelID)
+		}
+		got = append(got, h)
+	}
+	ch := &spadeChannel{channelID: "42", stopCh: make(chan struct{})}
+	s.channels["42"] = ch
+
+	s.recordHeartbeat(ch, false)
+	s.recordHeartbeat(ch, false)
+	if h, ok := s.Health("42"); !ok || h.Failures != 2 || !h.LastSuccess.IsZero() {
+		t.Fatalf("after two failures Health = %+v, %v", h, ok)
+	}
+	s.recordHeartbeat(ch, true)
+	h, _ := s.Health("42")
+	if h.Failures != 0 || h.LastSuccess.IsZero() {
+		t.Fatalf("after a success Health = %+v", h)
+	}
+	s.recordHeartbeat(ch, false)
+	if h2, _ := s.Health("42"); h2.Failures != 1 || !h2.LastSuccess.Equal(h.LastSuccess) {
+		t.Fatalf("a failure after a success Health = %+v", h2)
+	}
+	if len(got) != 4 || got[1].Failures != 2 {
+		t.Fatalf("OnHeartbeat calls = %+v", got)
+	}
+
+	if _, ok := s.Health("7"); ok {
+		t.Fatal("Health reported an unwatched channel")
+	}
+}
+
+func TestNextHeartbeatJitter(t *testing.T) {
+	s := NewSpadeTracker("1", "", "", nil, nil)
+	s.SetJitter(5 * time.Second)
+	seen := map[time.Duration]bool{}
+	for range 200 {
+		d := s.nextHeartbeat()
+		if d < 55*time.Second || d > 65*time.Second {
+			t.Fatalf("nextHeartbeat = %v, want 60s ± 5s", d)
+		}
+		seen[d] = true
+	}
+	if len(seen) < 2 {
+		t.Fatal("nextHeartbeat never varies")
+	}
+	s.SetJitter(0)
+	if d := s.nextHeartbeat(); d != heartbeatInterval {
+		t.Fatalf("nextHeartbeat with jitter off = %v, want %v", d, heartbeatInterval)
+	}
+}
+
+func TestPlayerProperties(t *testing.T) {
+	s := NewSpadeTracker("1", "", "device", nil, nil)
+	props := map[string]interface{}{}
+	s.addPlayerProperties(props)
+	if props["device_id"] != "device" || len(props["login_session_id"].(string)) != 32 {
+		t.Fatalf("session fields = %v", props)
+	}
+	w, h := props["vid_display_width"].(int), props["vid_display_height"].(int)
+	if w <= 0 || w >= props["viewport_width"].(int) || h != w*9/16 {
+		t.Fatalf("player size %dx%d in viewport %v", w, h, props["viewport_width"])
+	}
+}
@@ -0,0 +1,72 @@
+package twitch
+
+import "sync"
+
+// tokenBox holds a client's OAuth token so SetAuthToken can replace it
+// while requests are in flight: calls already sent finish with the old
+// token, the next ones pick up the new one.
+type tokenBox struct {
+	mu    sync.RWMutex
+	token string
+}
+
+func (b *tokenBox) get() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.token
+}
+
+func (b *tokenBox) set(token string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.token = token
+}
+
+// SetAuthToken replaces the token GQL requests are sent with.
+func (g *GQLClient) SetAuthToken(token string) {
+	g.authToken.set(token)
+}
+
+// SetAuthToken replaces the token topics are LISTENed with and
+// reconnects every connection, so its topics are subscribed again under
+// the new token.
+func (p *PubSubClient) SetAuthToken(token string) {
+	p.authToken.set(token)
+	p.mu.Lock()
+	shards := append([]*pubsubShard(nil), p.shards...)
+	p.mu.Unlock()
+	for _, s := range shards {
+		s.reconnect()
+	}
+}
+
+// SetAuthToken replaces the token IRC logs in with and drops the
+// connection, which reconnects and rejoins under the new token. No-op
+// for an anonymous client.
+func (c *IRCClient) SetAuthToken(token string) {
+	if c.anonymous {
+		return
+	}
+	c.token.set(token)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.conn != nil {
+		c.conn.Close()
+	}
+}
+
+// SetAuthToken replaces the token Helix subscription calls are sent
+// with. Subscriptions on the current session stay as they are.
+func (c *EventSubClient) SetAuthToken(token string) {
+	c.authToken.set(token)
+}
+
+// SetAuthToken replaces the tracker's token.
+func (s *SpadeTracker) SetAuthToken(token string) {
+	s.authToken.set(token)
+}
+
+// SetAuthToken replaces the token the prober's page views carry.
+func (p *StreamProber) SetAuthToken(token string) {
+	p.authToken.set(token)
+}
@@ -1,6 +1,10 @@
 package twitch
 
-import "time"
+import (
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // PubSub message types
 const (
@@ -135,8 +139,10 @@ type PersistedQuery struct {
 	SHA256Hash string `json:"sha256Hash"`
 }
 
+// GQLResponse is one GQL reply. Data is kept raw and decoded into the
+// query's own typed shape (see gql_response.go).
 type GQLResponse struct {
-	Data   map[string]interface{} `json:"data"`
+	Data   json.RawMessage `json:"data"`
 	Errors []struct {
 		Message string `json:"message"`
 	} `json:"errors,omitempty"`
@@ -149,6 +155,20 @@ type UserInfo struct {
 	DisplayName string `json:"displayName"`
 }
 
+// ChannelPointsContext is the per-channel result of the ChannelPointsContext
+// query.
+type ChannelPointsContext struct {
+	Balance          int
+	AvailableClaimID string // "" when no bonus chest is pending
+	// Multiplier is what watching earns relative to a viewer without
+	// bonuses: 1 plus the active multipliers' factors (1.2 with a tier 1
+	// sub, 2 with tier 3).
+	Multiplier float64
+	// SubTier is the user's subscription tier (1-3) as shown by its
+	// SUB_T* multiplier; 0 when not subscribed.
+	SubTier int
+}
+
 // Channel info
 type ChannelInfo struct {
 	ID          string `json:"id"`
@@ -164,6 +184,30 @@ type ChannelInfo struct {
 	// offline OR when the GQL response didn't include it (caller should
 	// fall back to time.Now() in that case).
 	StreamCreatedAt time.Time
+	// StreamType is GQL stream.type: "live", "rerun" for a rebroadcast
+	// of recorded video, or "premiere". Empty when offline.
+	StreamType string
+	// Tags are the stream's freeform tags.
+	Tags []string
+}
+
+// IsRerun reports whether the stream is a rebroadcast rather than a
+// live show: Twitch types it "rerun", or the streamer tagged it Rerun.
+// A premiere is first-run content and counts as live. Reruns often grant
+// no drops or watch streaks.
+func (i *ChannelInfo) IsRerun() bool {
+	if !i.IsLive {
+		return false
+	}
+	if strings.EqualFold(i.StreamType, "rerun") {
+		return true
+	}
+	for _, t := range i.Tags {
+		if strings.EqualFold(t, "rerun") || strings.EqualFold(t, "rebroadcast") {
+			return true
+		}
+	}
+	return false
 }
 
 // Stream metadata
@@ -179,6 +223,7 @@ type FarmerEvent struct {
 	Type      FarmerEventType
 	ChannelID string
 	Data      interface{}
+	Queued    time.Time // when the sender put it on the queue, for latency metrics
 }
 
 type FarmerEventType int
@@ -195,8 +240,56 @@ const (
 	EventDropProgress // user-drop-events: a drop's currentMinutesWatched changed
 	EventDropClaim    // user-drop-events: a drop instance is ready to claim
 	EventGameChange   // broadcast-settings-update: a watched channel changed game/title
+	// community-momento-user-v1: a streamer activated a Moment
+	EventMomentAvailable
+	// community-points-user-v1 points-spent: balance dropped (redemption,
+	// prediction) — Data is PointsData with TotalPoints only
+	EventPointsSpent
+	// hype-train-events-v1: a Hype Train started, progressed or ended —
+	// Data is HypeTrainData
+	EventHypeTrain
+	// community-points-user-v1 message of a type the client doesn't
+	// handle — Data is UnknownPointsData
+	EventPointsUnknown
 )
 
+// String returns the snake_case name used when events are exposed to
+// clients (web /api/events stream).
+func (t FarmerEventType) String() string {
+	switch t {
+	case EventClaimAvailable:
+		return "claim_available"
+	case EventPointsEarned:
+		return "points_earned"
+	case EventStreamUp:
+		return "stream_up"
+	case EventStreamDown:
+		return "stream_down"
+	case EventRaid:
+		return "raid"
+	case EventViewCount:
+		return "view_count"
+	case EventError:
+		return "error"
+	case EventDropProgress:
+		return "drop_progress"
+	case EventDropClaim:
+		return "drop_claim"
+	case EventGameChange:
+		return "game_change"
+	case EventMomentAvailable:
+		return "moment_available"
+	case EventPointsSpent:
+		return "points_spent"
+	case EventHypeTrain:
+		return "hype_train"
+	case EventPointsUnknown:
+		return "points_unknown"
+	default:
+		return "unknown"
+	}
+}
+
 // ClaimData holds data for a claim-available event.
 type ClaimData struct {
 	ClaimID string
@@ -209,6 +302,35 @@ type PointsData struct {
 	ReasonCode    string
 }
 
+// reasonLabels names the point gain reason codes Twitch is known to send.
+var reasonLabels = map[string]string{
+	"WATCH":        "watch",
+	"CLAIM":        "bonus claim",
+	"WATCH_STREAK": "watch streak",
+	"RAID":         "raid",
+}
+
+// ReasonLabel describes a point gain reason code for logs and
+// notifications. A code Twitch added since is shown as "other (CODE)",
+// a missing one as "unknown reason".
+func ReasonLabel(code string) string {
+	if label, ok := reasonLabels[code]; ok {
+		return label
+	}
+	if code == "" {
+		return "unknown reason"
+	}
+	return "other (" + code + ")"
+}
+
+// UnknownPointsData holds a community-points message of a type the
+// client has no handler for, so the farmer can log it or claim it by a
+// configured rule.
+type UnknownPointsData struct {
+	Type    string          // the message's "type", e.g. "community-goal-contribution"
+	Payload json.RawMessage // its "data" object, as sent
+}
+
 // RaidData holds data for a raid event.
 type RaidData struct {
 	RaidID            string
@@ -246,6 +368,20 @@ type DropClaimData struct {
 	DropInstanceID string // empty if Twitch is just notifying that a drop is now claimable
 }
 
+// MomentData is the payload for EventMomentAvailable.
+type MomentData struct {
+	MomentID string
+}
+
+// HypeTrainData is the payload for EventHypeTrain. Active is false once
+// the train ended; ExpiresAt is when it runs out without further
+// contributions.
+type HypeTrainData struct {
+	Active    bool
+	Level     int
+	ExpiresAt time.Time
+}
+
 // GameChangeData is the payload for EventGameChange (v1.8.0 WebSocket).
 type GameChangeData struct {
 	OldGameName string
@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -31,16 +32,20 @@ type tabID int
 const (
 	tabChannels tabID = iota
 	tabDrops
+	tabLogs
+	tabStats
 	tabHelp
+
+	numTabs = 5
 )
 
 // Model is the Bubbletea app model.
 type Model struct {
-	farmer *farmer.Farmer
+	farmer Backend
 	width  int
 	height int
 
-	// Active tab — switched via 1/2/3 number keys or Tab/Shift-Tab.
+	// Active tab — switched via 1-5 number keys or Tab/Shift-Tab.
 	activeTab tabID
 
 	// Twitch-catalog autocomplete state for the inputAddGameName prompt.
@@ -53,7 +58,10 @@ type Model struct {
 	gameSearchResults []string
 	gameSearchCursor  int
 
-	// Channel table scroll (tab 1).
+	// Channel table selection + scroll (tab 1). channelCursor indexes
+	// the visible (non-temp) channel list; quick-action keys operate on
+	// that row. channelScroll follows the cursor.
+	channelCursor int
 	channelScroll int
 
 	// Drops tab cursor state. focusedPanel selects which of the three
@@ -65,12 +73,29 @@ type Model struct {
 	dropsGameCursor     int
 	dropsSettingsCursor int
 
+	// Logs tab scroll position, in entries back from the newest. 0
+	// follows the tail; scrolling up pins the view while new lines
+	// keep arriving.
+	logScroll int
+
+	// Stats tab: 'v' swaps the session numbers for the comparison of
+	// today/yesterday and session/7-day average, 'b' for the points by
+	// reason code. At most one is set.
+	statsCompare bool
+	statsReasons bool
+
 	// Input mode (text-input modals — channel add/remove/priority + game
 	// name prompt). Drops-tab inline interaction does NOT use this; only
 	// the channels-tab text-input flows still go through it.
 	inputMode  inputState
 	inputValue string
 
+	// Channel settings form ('e'): the channel being edited ("" = form
+	// closed), its settings as last applied, and the highlighted row.
+	editLogin    string
+	editSettings farmer.ChannelSettings
+	editCursor   int
+
 
 	// Error display
 	errMsg    string
@@ -80,7 +105,12 @@ type Model struct {
 	// running instead of exiting (used on Windows to hide the console).
 	OnQuit func()
 
+	// DetachOnQuit makes 'q' close the TUI without stopping the farmer
+	// (RunDetachable reports it). Ctrl+C still quits.
+	DetachOnQuit bool
+
 	quitting bool
+	detached bool
 }
 
 // dropsPanel identifies which of the drops-tab sub-panels has the cursor.
@@ -106,12 +136,15 @@ const (
 	inputRemoveChannel
 	inputSetPriority
 	inputAddGameName
+	inputSetGoal      // points goal row of the channel settings form
+	inputSetMaxPoints // max points row of the channel settings form
+	inputBlacklist
 )
 
 // NewModel creates a new UI model.
-func NewModel(f *farmer.Farmer) Model {
+func NewModel(b Backend) Model {
 	return Model{
-		farmer:           f,
+		farmer:           b,
 		width:            120,
 		height:           40,
 		gameSearchCursor: -1, // -1 = no suggestion selected, Enter saves typed text
@@ -144,7 +177,7 @@ func (m Model) maybeSearchGames() tea.Cmd {
 // output. The 250ms wait + a typical 200-500ms GQL turnaround means
 // at most a handful of goroutines are alive at once during fast
 // typing, all completing within a second or two.
-func searchGamesCmd(f *farmer.Farmer, query string) tea.Cmd {
+func searchGamesCmd(f Backend, query string) tea.Cmd {
 	return func() tea.Msg {
 		time.Sleep(250 * time.Millisecond)
 		results, err := f.SearchGameCategories(query, 10)
@@ -252,6 +285,11 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.OnQuit()
 			return m, nil
 		}
+		if m.DetachOnQuit && msg.String() == "q" {
+			m.quitting = true
+			m.detached = true
+			return m, tea.Quit
+		}
 		m.quitting = true
 		m.farmer.Stop()
 		return m, tea.Quit
@@ -262,13 +300,26 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.activeTab = tabDrops
 		return m, nil
 	case "3":
+		m.activeTab = tabLogs
+		return m, nil
+	case "4":
+		m.activeTab = tabStats
+		return m, nil
+	case "5":
 		m.activeTab = tabHelp
 		return m, nil
+	case "P":
+		if m.farmer.GetStats().Paused {
+			m.farmer.Resume()
+		} else {
+			m.farmer.Pause()
+		}
+		return m, nil
 	case "tab":
-		m.activeTab = (m.activeTab + 1) % 3
+		m.activeTab = (m.activeTab + 1) % numTabs
 		return m, nil
 	case "shift+tab":
-		m.activeTab = (m.activeTab + 2) % 3
+		m.activeTab = (m.activeTab + numTabs - 1) % numTabs
 		return m, nil
 	}
 
@@ -278,49 +329,125 @@ func (m Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m.handleChannelsKey(msg)
 	case tabDrops:
 		return m.handleDropsKey(msg)
+	case tabLogs:
+		return m.handleLogsKey(msg)
+	case tabStats:
+		return m.handleStatsKey(msg)
 	case tabHelp:
-		// No interactive keys yet — Help tab is read-only.
+		// Read-only tab.
 		return m, nil
 	}
 	return m, nil
 }
 
 // handleChannelsKey dispatches keys for the Channels tab — text-input
-// modal triggers (a/d/p) and channel-table scroll (j/k/home/end). The
-// old 'g' (wanted-games modal) and 't' (toggle campaign modal) keys
-// are gone — both flows live in the Drops tab now as inline panels.
+// modal triggers (a/d/p), cursor movement (j/k/home/end), the quick
+// actions on the highlighted row (o/space/t/w/r) and its settings form
+// (e). The old 'g' (wanted-games modal) and 't' (toggle campaign modal)
+// keys are gone — both flows live in the Drops tab now as inline panels;
+// 't' is reused for the priority toggle.
 func (m Model) handleChannelsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.editLogin != "" {
+		return m.handleSettingsKey(msg)
+	}
+
+	visible := m.visibleChannels()
+	if m.channelCursor >= len(visible) {
+		m.channelCursor = max0(len(visible) - 1)
+	}
+	selected, hasSelected := channels.Snapshot{}, len(visible) > 0
+	if hasSelected {
+		selected = visible[m.channelCursor]
+	}
+
 	switch msg.String() {
 	case "a":
 		m.inputMode = inputAddChannel
 		m.inputValue = ""
 		return m, nil
 	case "d":
+		// Prefill with the highlighted channel — Enter confirms,
+		// backspace to type a different login.
 		m.inputMode = inputRemoveChannel
-		m.inputValue = ""
+		m.inputValue = selected.Login
 		return m, nil
 	case "p":
 		m.inputMode = inputSetPriority
 		m.inputValue = ""
+		if hasSelected {
+			m.inputValue = selected.Login + " "
+		}
 		return m, nil
 	case "up", "k":
-		if m.channelScroll > 0 {
-			m.channelScroll--
+		if m.channelCursor > 0 {
+			m.channelCursor--
 		}
-		return m, nil
 	case "down", "j":
-		m.channelScroll++
-		return m, nil
+		if m.channelCursor < len(visible)-1 {
+			m.channelCursor++
+		}
 	case "home":
-		m.channelScroll = 0
-		return m, nil
+		m.channelCursor = 0
 	case "end":
-		m.channelScroll = 9999 // clamped in View
+		m.channelCursor = max0(len(visible) - 1)
+	case "e":
+		if hasSelected {
+			m = m.openChannelSettings(selected.Login)
+		}
+		return m, nil
+	case "o", "O":
+		if hasSelected {
+			m.setChannelActionErr(openURL("https://www.twitch.tv/" + selected.Login))
+		}
+		return m, nil
+	case " ", "space":
+		if hasSelected {
+			m.setChannelActionErr(m.farmer.SetPausedLive(selected.Login, !selected.Paused))
+		}
+		return m, nil
+	case "t":
+		if hasSelected {
+			pri := 1
+			if selected.Priority == 1 {
+				pri = 2
+			}
+			m.setChannelActionErr(m.farmer.SetPriorityLive(selected.Login, pri))
+		}
+		return m, nil
+	case "w":
+		if hasSelected {
+			m.setChannelActionErr(m.farmer.ForceWatchLive(selected.Login))
+		}
 		return m, nil
+	case "r":
+		if hasSelected {
+			m.setChannelActionErr(m.farmer.RefreshChannelLive(selected.Login))
+		}
+		return m, nil
+	default:
+		return m, nil
+	}
+
+	// Cursor moved — keep it inside the scroll window.
+	rows := m.channelRowBudget(len(visible))
+	if m.channelCursor < m.channelScroll {
+		m.channelScroll = m.channelCursor
+	} else if m.channelCursor >= m.channelScroll+rows {
+		m.channelScroll = m.channelCursor - rows + 1
 	}
 	return m, nil
 }
 
+// setChannelActionErr surfaces a quick-action failure in the footer.
+// Successes are reported by the farmer's own log lines.
+func (m *Model) setChannelActionErr(err error) {
+	if err == nil {
+		return
+	}
+	m.errMsg = fmt.Sprintf("Error: %v", err)
+	m.errExpiry = time.Now().Add(5 * time.Second)
+}
+
 // handleDropsKey dispatches keys for the Drops tab. The cursor is
 // unified across the three stacked panels (Drop Campaigns, Wanted
 // Games, Settings) — j/k overflows panel boundaries so the user
@@ -334,8 +461,8 @@ func (m Model) handleChannelsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 // (everything else is selection or boolean toggle).
 func (m Model) handleDropsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	drops := m.farmer.GetActiveDrops()
-	games := m.farmer.Config().GetGamesToWatch()
-	settings := dropsSettings(m.farmer.Config())
+	games := m.farmer.GetGamesToWatch()
+	settings := dropsSettings()
 
 	switch msg.String() {
 	case "down", "j":
@@ -356,11 +483,29 @@ func (m Model) handleDropsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.inputMode = inputAddGameName
 		m.inputValue = ""
 		return m, nil
+	case "x":
+		// Prefilled with the highlighted campaign's channel — the usual
+		// reason to blacklist is an auto-picked streamer.
+		m.inputMode = inputBlacklist
+		m.inputValue = ""
+		if m.dropsFocusedPanel == dropsPanelCampaigns && m.dropsCampaignCursor < len(drops) {
+			m.inputValue = drops[m.dropsCampaignCursor].ChannelLogin
+		}
+		return m, nil
+	case "s":
+		// Keep the highlighted campaign's auto-picked channel: save
+		// it to the config so it stays once the pick moves on.
+		if m.dropsFocusedPanel == dropsPanelCampaigns && m.dropsCampaignCursor < len(drops) {
+			if login := drops[m.dropsCampaignCursor].ChannelLogin; login != "" {
+				m.setChannelActionErr(m.farmer.PromoteChannel(login))
+			}
+		}
+		return m, nil
 	case "-":
 		m.dropsFocusedPanel = dropsPanelGames
 		if m.dropsGameCursor < len(games) {
-			m.farmer.Config().RemoveGameFromWatch(games[m.dropsGameCursor])
-			_ = m.farmer.Config().Save()
+			next := append(append([]string(nil), games[:m.dropsGameCursor]...), games[m.dropsGameCursor+1:]...)
+			m.setChannelActionErr(m.farmer.SetGamesToWatch(next))
 			if m.dropsGameCursor > 0 && m.dropsGameCursor >= len(games)-1 {
 				m.dropsGameCursor--
 			}
@@ -369,16 +514,14 @@ func (m Model) handleDropsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case "u":
 		m.dropsFocusedPanel = dropsPanelGames
 		if m.dropsGameCursor > 0 && m.dropsGameCursor < len(games) {
-			m.farmer.Config().MoveGameToWatch(games[m.dropsGameCursor], -1)
-			_ = m.farmer.Config().Save()
+			m.setChannelActionErr(m.farmer.SetGamesToWatch(swapGames(games, m.dropsGameCursor, m.dropsGameCursor-1)))
 			m.dropsGameCursor--
 		}
 		return m, nil
 	case "d":
 		m.dropsFocusedPanel = dropsPanelGames
 		if m.dropsGameCursor < len(games)-1 {
-			m.farmer.Config().MoveGameToWatch(games[m.dropsGameCursor], +1)
-			_ = m.farmer.Config().Save()
+			m.setChannelActionErr(m.farmer.SetGamesToWatch(swapGames(games, m.dropsGameCursor, m.dropsGameCursor+1)))
 			m.dropsGameCursor++
 		}
 		return m, nil
@@ -386,6 +529,24 @@ func (m Model) handleDropsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+// swapGames returns a copy of games with entries i and j swapped.
+func swapGames(games []string, i, j int) []string {
+	out := append([]string(nil), games...)
+	out[i], out[j] = out[j], out[i]
+	return out
+}
+
+// containsFold reports whether games already has game, ignoring case
+// (wanted_games matching is case-insensitive).
+func containsFold(games []string, game string) bool {
+	for _, g := range games {
+		if strings.EqualFold(g, game) {
+			return true
+		}
+	}
+	return false
+}
+
 // dropsCursorDown advances the unified cursor by one row, overflowing
 // to the next panel when the current panel's last row is already
 // active. Wraps around from Settings → Campaigns.
@@ -491,13 +652,16 @@ func (m Model) dropsToggle(drops []drops.ActiveDrop, settings []dropsSetting) Mo
 	case dropsPanelSettings:
 		if m.dropsSettingsCursor < len(settings) {
 			s := settings[m.dropsSettingsCursor]
-			s.toggle(m.farmer.Config())
-			_ = m.farmer.Config().Save()
+			on := !m.farmer.GetBoolSetting(s.key)
+			if err := m.farmer.SetBoolSetting(s.key, on); err != nil {
+				m.setChannelActionErr(err)
+				return m
+			}
 			if s.restart {
 				m.errMsg = fmt.Sprintf("%s — restart required", s.label)
 			} else {
 				state := "off"
-				if s.get(m.farmer.Config()) {
+				if on {
 					state = "on"
 				}
 				m.errMsg = fmt.Sprintf("%s → %s", s.label, state)
@@ -514,12 +678,15 @@ func (m Model) submitInput() (tea.Model, tea.Cmd) {
 
 	switch m.inputMode {
 	case inputAddChannel:
-		// Channel logins are canonically lowercase on Twitch.
-		value := strings.ToLower(raw)
+		// A login, twitch.tv URL or channel ID; AddChannelLive resolves it.
+		value := raw
 		if value != "" {
 			if err := m.farmer.AddChannelLive(value); err != nil {
 				m.errMsg = fmt.Sprintf("Error: %v", err)
 				m.errExpiry = time.Now().Add(5 * time.Second)
+			} else if w := m.farmer.CapacityWarning(); w != "" {
+				m.errMsg = "Warning: " + w
+				m.errExpiry = time.Now().Add(8 * time.Second)
 			}
 		}
 	case inputRemoveChannel:
@@ -549,6 +716,58 @@ func (m Model) submitInput() (tea.Model, tea.Cmd) {
 				}
 			}
 		}
+	case inputSetGoal:
+		// Empty clears the goal, like 0.
+		goal := 0
+		if raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				m.errMsg = "Goal must be a whole number of points (0 or empty clears it)"
+				m.errExpiry = time.Now().Add(5 * time.Second)
+				break
+			}
+			goal = n
+		}
+		s := m.editSettings
+		s.Goal = goal
+		m.applyChannelSettings(s)
+	case inputSetMaxPoints:
+		// Empty clears the cap, like 0.
+		limit := 0
+		if raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil || n < 0 {
+				m.errMsg = "Max points must be a whole number of points (0 or empty clears it)"
+				m.errExpiry = time.Now().Add(5 * time.Second)
+				break
+			}
+			limit = n
+		}
+		s := m.editSettings
+		s.MaxPoints = limit
+		m.applyChannelSettings(s)
+	case inputBlacklist:
+		// Toggle: an entry already on the list is removed.
+		if raw != "" {
+			list := m.farmer.GetBlacklist()
+			next := make([]string, 0, len(list)+1)
+			for _, e := range list {
+				if !strings.EqualFold(e, raw) {
+					next = append(next, e)
+				}
+			}
+			word := "removed from the blacklist"
+			if len(next) == len(list) {
+				next = append(next, raw)
+				word = "blacklisted"
+			}
+			if err := m.farmer.SetBlacklist(next); err != nil {
+				m.setChannelActionErr(err)
+			} else {
+				m.errMsg = fmt.Sprintf("%q %s", raw, word)
+				m.errExpiry = time.Now().Add(3 * time.Second)
+			}
+		}
 	case inputAddGameName:
 		// Save EITHER the highlighted suggestion (if cursor is on one)
 		// OR the typed text verbatim. Twitch is case-sensitive on game
@@ -561,9 +780,11 @@ func (m Model) submitInput() (tea.Model, tea.Cmd) {
 			save = raw
 		}
 		if save != "" {
-			m.farmer.Config().AddGameToWatch(save)
-			_ = m.farmer.Config().Save()
-			games := m.farmer.Config().GetGamesToWatch()
+			games := m.farmer.GetGamesToWatch()
+			if !containsFold(games, save) {
+				games = append(games, save)
+				m.setChannelActionErr(m.farmer.SetGamesToWatch(games))
+			}
 			if len(games) > 0 {
 				m.dropsFocusedPanel = dropsPanelGames
 				m.dropsGameCursor = len(games) - 1
@@ -582,6 +803,9 @@ func (m Model) submitInput() (tea.Model, tea.Cmd) {
 // View implements tea.Model. Renders the persistent header + tab bar,
 // then dispatches to the tab-specific view.
 func (m Model) View() string {
+	if m.detached {
+		return "Detaching...\n"
+	}
 	if m.quitting {
 		return "Shutting down...\n"
 	}
@@ -594,7 +818,7 @@ func (m Model) View() string {
 	stats := m.farmer.GetStats()
 
 	header := []string{
-		renderHeader(username, stats.Uptime),
+		renderHeader(username, stats.Uptime, m.farmer.GetDailySummary()),
 		renderTabBar(m.activeTab),
 		"",
 	}
@@ -611,131 +835,111 @@ func (m Model) View() string {
 		return headerStr + "\n" + m.viewChannelsTab(stats)
 	case tabDrops:
 		return headerStr + "\n" + m.viewDropsTab()
+	case tabLogs:
+		return headerStr + "\n" + m.viewLogsTab()
+	case tabStats:
+		return headerStr + "\n" + m.viewStatsTab(stats)
 	case tabHelp:
 		return headerStr + "\n" + m.viewHelpTab()
 	}
 	return headerStr
 }
 
-// viewChannelsTab renders the original channel-list / stats / drops-mini-
-// table / event-log layout. The math for available-line allocation is
-// the same as the pre-tab single-screen view; only the per-tab "header
-// overhead" differs (header line + tab bar = 3 lines).
+// viewChannelsTab renders the full-height channel table above the
+// stats bar. The event log and drop campaigns have their own tabs.
 func (m Model) viewChannelsTab(stats farmer.Stats) string {
 	var sections []string
 
-	allChannels := m.farmer.GetChannels()
-	// Hide temp drop-pick channels from the user's channel list — they
-	// were never added by the user and surface anyway in the mini-drops
-	// table below + the Drops tab. Stats counters (Watching: 3/2) still
-	// include them since they ARE genuinely being watched; this is a
-	// pure display filter, no behavior change.
-	visibleChannels := make([]channels.Snapshot, 0, len(allChannels))
-	for _, c := range allChannels {
-		if c.IsTemporary {
-			continue
-		}
-		visibleChannels = append(visibleChannels, c)
-	}
-
-	// Channels tab only surfaces the actively-farming drop(s) — the full
-	// table (including COMPLETED + QUEUED + IDLE rows) lives on the
-	// Drops tab. Filter here so the channels view stays focused on
-	// "what is happening RIGHT NOW".
-	allDrops := m.farmer.GetActiveDrops()
-	var activeDrops []drops.ActiveDrop
-	for _, d := range allDrops {
-		if d.Status == "ACTIVE" {
-			activeDrops = append(activeDrops, d)
-		}
-	}
-	dropsTable := renderDropsTable(activeDrops, m.width)
-	hasDropsTable := dropsTable != ""
-
-	// Header overhead: header(1) + tab_bar(1) + spacer(1) = 3 lines
-	// already consumed before this tab body. Tab body overhead:
-	// ch_header(1) + spacer(1) + stats_border(3) + spacer(1) +
-	// log_title(1) + log_border(2) + help(1) = 10, plus 2 buffer.
-	overhead := 12 + 3
-	if banner := renderUpdateBanner(m.farmer.GetUpdateInfo()); banner != "" {
-		overhead += 2
-	}
-	if hasDropsTable {
-		overhead += len(activeDrops) + 3
-	}
-
-	available := m.height - overhead
-	if available < 10 {
-		available = 10
-	}
-
-	maxChannelRows := m.height / 2
-	minLogContent := 4
-
-	if available-maxChannelRows < minLogContent {
-		maxChannelRows = available - minLogContent
-	}
-	if maxChannelRows < 3 {
-		maxChannelRows = 3
-	}
-
-	channelRows := len(visibleChannels)
-	if channelRows > maxChannelRows {
-		channelRows = maxChannelRows
-	}
+	visibleChannels := m.visibleChannels()
+	channelRows := m.channelRowBudget(len(visibleChannels))
 
 	maxScroll := len(visibleChannels) - channelRows
 	if maxScroll < 0 {
 		maxScroll = 0
 	}
+	cursor := m.channelCursor
+	if cursor >= len(visibleChannels) {
+		cursor = max0(len(visibleChannels) - 1)
+	}
 	scroll := m.channelScroll
+	if cursor >= scroll+channelRows {
+		scroll = cursor - channelRows + 1
+	}
 	if scroll > maxScroll {
 		scroll = maxScroll
 	}
 
-	sections = append(sections, renderChannelTableScrollable(visibleChannels, m.width, channelRows, scroll))
+	sections = append(sections, renderChannelTableScrollable(visibleChannels, m.width, channelRows, scroll, cursor))
 	sections = append(sections, "")
 	sections = append(sections, renderStatsBar(stats, m.width))
 	sections = append(sections, "")
 
-	if hasDropsTable {
-		sections = append(sections, dropsTable)
-		sections = append(sections, "")
-	}
-
-	logContent := available - channelRows
-	if scroll > 0 {
-		logContent--
-	}
-	if scroll < maxScroll {
-		logContent--
+	if m.editLogin != "" && m.inputMode == inputNone {
+		sections = append(sections, m.renderChannelSettings())
 	}
-	if logContent < minLogContent {
-		logContent = minLogContent
-	}
-	logHeight := logContent + 2
-
-	logs := m.farmer.GetLogs()
-	sections = append(sections, renderEventLog(logs, logHeight, m.width))
-
 	if m.inputMode != inputNone {
 		sections = append(sections, m.renderInput())
 	} else if m.errMsg != "" && time.Now().Before(m.errExpiry) {
 		sections = append(sections, lipgloss.NewStyle().Foreground(colorRed).Render("  "+m.errMsg))
-	} else {
+	} else if m.editLogin == "" {
 		sections = append(sections, renderHelpBar())
 	}
 
 	return strings.Join(sections, "\n")
 }
 
+// visibleChannels returns the Channels-tab rows. Temp drop-pick channels
+// are hidden from the user's channel list — they were never added by the
+// user and surface anyway on the Drops tab. Stats counters (Watching:
+// 3/2) still include them since they ARE genuinely being watched; this
+// is a pure display filter, no behavior change.
+func (m Model) visibleChannels() []channels.Snapshot {
+	allChannels := m.farmer.GetChannels()
+	visible := make([]channels.Snapshot, 0, len(allChannels))
+	for _, c := range allChannels {
+		if c.IsTemporary {
+			continue
+		}
+		visible = append(visible, c)
+	}
+	return visible
+}
+
+// channelRowBudget returns how many channel-table rows fit on the
+// Channels tab. Shared by the view and the cursor handler so the
+// scroll window follows the selection.
+func (m Model) channelRowBudget(visibleCount int) int {
+	// Header overhead: header(1) + tab_bar(1) + spacer(1) = 3 lines
+	// already consumed before this tab body. Tab body overhead:
+	// ch_header(2) + scroll_indicators(2) + spacer(1) + stats_border(3)
+	// + spacer(1) + help(1) = 10, plus 1 buffer.
+	overhead := 11 + 3
+	if m.editLogin != "" {
+		// The settings form (title, rows, key hints) replaces the help
+		// line and leaves room for an error under it.
+		overhead += len(channelSettingRows) + 2
+	}
+	if banner := renderUpdateBanner(m.farmer.GetUpdateInfo()); banner != "" {
+		overhead += 2
+	}
+
+	rows := m.height - overhead
+	if rows < 3 {
+		rows = 3
+	}
+	if visibleCount < rows {
+		rows = visibleCount
+	}
+	return rows
+}
+
 // viewDropsTab renders the three stacked Drops-tab panels (Drop
 // Campaigns / Wanted Games / Settings) with the unified cursor + per-
 // panel help footer.
 func (m Model) viewDropsTab() string {
 	rows := m.farmer.GetActiveDrops()
-	games := m.farmer.Config().GetGamesToWatch()
-	settings := dropsSettings(m.farmer.Config())
+	games := m.farmer.GetGamesToWatch()
+	settings := dropsSettings()
 
 	// Clamp cursors so a stale state from a previous render doesn't
 	// point past the end of the panel's data (e.g., user removed a
@@ -755,12 +959,13 @@ func (m Model) viewDropsTab() string {
 		renderDropsCampaignsPanel(rows, m.dropsCampaignCursor, m.dropsFocusedPanel == dropsPanelCampaigns),
 		"",
 		renderDropsGamesPanel(games, m.dropsGameCursor, m.dropsFocusedPanel == dropsPanelGames),
+		renderBlacklistLine(m.farmer.GetBlacklist()),
 		"",
-		renderDropsSettingsPanel(m.farmer.Config(), settings, m.dropsSettingsCursor, m.dropsFocusedPanel == dropsPanelSettings),
+		renderDropsSettingsPanel(m.farmer, settings, m.dropsSettingsCursor, m.dropsFocusedPanel == dropsPanelSettings),
 		"",
 	)
 
-	if m.inputMode == inputAddGameName {
+	if m.inputMode == inputAddGameName || m.inputMode == inputBlacklist {
 		sections = append(sections, m.renderInput())
 	} else if m.errMsg != "" && time.Now().Before(m.errExpiry) {
 		sections = append(sections, lipgloss.NewStyle().Foreground(colorRed).Render("  "+m.errMsg))
@@ -792,7 +997,7 @@ func (m Model) renderInput() string {
 	var prompt, hint string
 	switch m.inputMode {
 	case inputAddChannel:
-		prompt = "Add channel: "
+		prompt = "Add channel (login, URL or ID): "
 		hint = "  (Enter to confirm, Esc to cancel)"
 	case inputRemoveChannel:
 		prompt = "Remove channel: "
@@ -800,6 +1005,15 @@ func (m Model) renderInput() string {
 	case inputSetPriority:
 		prompt = "Set priority (name 1|2): "
 		hint = "  (1=always watch, 2=rotate)"
+	case inputBlacklist:
+		prompt = "Blacklist channel or game: "
+		hint = "  (Enter adds it, or removes it if listed; Esc to cancel)"
+	case inputSetGoal:
+		prompt = "Points goal for " + m.editLogin + ": "
+		hint = "  (0 or empty clears it, Esc to cancel)"
+	case inputSetMaxPoints:
+		prompt = "Max points for " + m.editLogin + ": "
+		hint = "  (no points slot at or over it; 0 or empty clears it, Esc to cancel)"
 	case inputAddGameName:
 		prompt = "Add game name: "
 		hint = "  (Enter to confirm, Esc to cancel)"
@@ -842,10 +1056,29 @@ func (m Model) renderInput() string {
 
 // Run starts the Bubbletea program.
 func Run(f *farmer.Farmer) error {
+	return RunBackend(Local{f})
+}
+
+// RunBackend runs the TUI against any Backend — used directly for a
+// remote instance, where quitting only disconnects.
+func RunBackend(b Backend) error {
 	p := tea.NewProgram(
-		NewModel(f),
+		NewModel(b),
 		tea.WithAltScreen(),
 	)
 	_, err := p.Run()
 	return err
 }
+
+// RunDetachable runs the TUI like Run, but 'q' closes it with the
+// farmer still running and reports detached=true; Ctrl+C stops the
+// farmer as usual.
+func RunDetachable(f *farmer.Farmer) (detached bool, err error) {
+	m := NewModel(Local{f})
+	m.DetachOnQuit = true
+	final, err := tea.NewProgram(m, tea.WithAltScreen()).Run()
+	if fm, ok := final.(Model); ok {
+		detached = fm.detached
+	}
+	return detached, err
+}
@@ -0,0 +1,133 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// Backend is everything the TUI reads and drives. The in-process farmer
+// is wrapped by Local; remote.Client implements it over the web API of
+// a headless instance (twitchpoint attach <url>).
+//
+// The getters are called on every render, so implementations must
+// answer from memory.
+type Backend interface {
+	GetUser() *twitch.UserInfo
+	GetStats() farmer.Stats
+	GetChannels() []channels.Snapshot
+	GetActiveDrops() []drops.ActiveDrop
+	GetLogs() []farmer.LogEntry
+	GetUpdateInfo() farmer.UpdateInfo
+	GetRedemptions() []points.Redemption
+	GetDailySummary() farmer.DailySummary
+	// GetComparison backs the Stats tab's comparison view ('v').
+	GetComparison() farmer.Comparison
+	// GetReasonSummary backs the Stats tab's reason breakdown ('b').
+	GetReasonSummary() farmer.ReasonSummary
+	CapacityWarning() string
+	SearchGameCategories(query string, limit int) ([]string, error)
+
+	AddChannelLive(login string) error
+	RemoveChannelLive(login string) error
+	SetPriorityLive(login string, priority int) error
+	SetPausedLive(login string, paused bool) error
+	ForceWatchLive(login string) error
+	RefreshChannelLive(login string) error
+	// PromoteChannel saves a temporary drop channel to the config ('s'
+	// on the Drops tab).
+	PromoteChannel(login string) error
+	// GetChannelSettings / SetChannelSettingsLive back the channel
+	// settings form ('e' on the Channels tab).
+	GetChannelSettings(login string) (farmer.ChannelSettings, error)
+	SetChannelSettingsLive(login string, s farmer.ChannelSettings) error
+	SetCampaignEnabled(campaignID string, enabled bool) error
+	RotateNow()
+	CheckDropsNow() error
+	// Pause / Resume stop and restart all farming (farmer.Pause).
+	Pause()
+	Resume()
+
+	// GetGamesToWatch / SetGamesToWatch read and replace (and persist)
+	// the wanted_games list.
+	GetGamesToWatch() []string
+	SetGamesToWatch(games []string) error
+	// GetBlacklist / SetBlacklist read and replace (and persist) the
+	// auto-selection blacklist.
+	GetBlacklist() []string
+	SetBlacklist(entries []string) error
+
+	// GetBoolSetting / SetBoolSetting read and persist the Drops-tab
+	// settings, keyed by their config.json name (see dropsSettings).
+	GetBoolSetting(key string) bool
+	SetBoolSetting(key string, v bool) error
+
+	// Stop is called when the user quits the TUI: it stops a local
+	// farmer, or disconnects a remote client.
+	Stop()
+}
+
+// Local adapts the in-process farmer to Backend; the wanted-games and
+// settings parts go straight to its config.
+type Local struct {
+	*farmer.Farmer
+}
+
+var _ Backend = Local{}
+
+// GetGamesToWatch implements Backend.
+func (l Local) GetGamesToWatch() []string {
+	return l.Config().GetGamesToWatch()
+}
+
+// SetGamesToWatch implements Backend.
+func (l Local) SetGamesToWatch(games []string) error {
+	l.Config().SetGamesToWatch(games)
+	l.Config().SaveSoon()
+	return nil
+}
+
+// GetBlacklist implements Backend.
+func (l Local) GetBlacklist() []string {
+	return l.Config().GetBlacklist()
+}
+
+// GetBoolSetting implements Backend.
+func (l Local) GetBoolSetting(key string) bool {
+	c := l.Config()
+	switch key {
+	case "drops_enabled":
+		return c.GetDropsEnabled()
+	case "auto_claim":
+		return c.GetAutoClaim()
+	case "irc_enabled":
+		return c.GetIrcEnabled()
+	case "web_enabled":
+		return c.GetWebEnabled()
+	}
+	return false
+}
+
+// SetBoolSetting implements Backend. Drops and IRC are switched (and
+// saved) through the farmer so they apply right away.
+func (l Local) SetBoolSetting(key string, v bool) error {
+	c := l.Config()
+	switch key {
+	case "drops_enabled":
+		return l.SetSubsystemEnabled(farmer.SubsystemDrops, v)
+	case "auto_claim":
+		c.SetAutoClaim(v)
+	case "irc_enabled":
+		return l.SetSubsystemEnabled(farmer.SubsystemIRC, v)
+	case "web_enabled":
+		c.SetWebEnabled(v)
+	default:
+		return fmt.Errorf("unknown setting %q", key)
+	}
+	c.SaveSoon()
+	return nil
+}
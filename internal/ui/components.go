@@ -2,18 +2,18 @@ package ui
 
 import (
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/miwi/twitchpoint/internal/channels"
-	"github.com/miwi/twitchpoint/internal/drops"
 	"github.com/miwi/twitchpoint/internal/farmer"
 )
 
 // renderTabBar renders the top-level tab navigation strip. The active tab
 // gets the purple background; inactive tabs are grey. Number prefix is
-// the direct-jump key (1/2/3); Tab/Shift-Tab also cycles.
+// the direct-jump key (1-5); Tab/Shift-Tab also cycles.
 func renderTabBar(active tabID) string {
 	tabs := []struct {
 		id    tabID
@@ -21,7 +21,9 @@ func renderTabBar(active tabID) string {
 	}{
 		{tabChannels, "1 Channels"},
 		{tabDrops, "2 Drops"},
-		{tabHelp, "3 Help"},
+		{tabLogs, "3 Logs"},
+		{tabStats, "4 Stats"},
+		{tabHelp, "5 Help"},
 	}
 	var rendered []string
 	for _, t := range tabs {
@@ -34,13 +36,15 @@ func renderTabBar(active tabID) string {
 	return lipgloss.JoinHorizontal(lipgloss.Top, rendered...)
 }
 
-// renderHeader renders the top header bar.
-func renderHeader(username string, uptime time.Duration) string {
+// renderHeader renders the top header bar, including today's tally.
+func renderHeader(username string, uptime time.Duration, today farmer.DailySummary) string {
 	title := headerStyle.Render(" TwitchPoint Farmer ")
 	user := subtitleStyle.Render(fmt.Sprintf(" User: %s ", username))
 	uptimeStr := subtitleStyle.Render(fmt.Sprintf(" Uptime: %s ", formatDuration(uptime)))
+	todayStr := statLabelStyle.Render(" Today: ") + statValueStyle.Render(fmt.Sprintf("+%s pts, %d claims, %d drop min ",
+		formatNumber(today.Points), today.Claims, today.DropMinutes))
 
-	return lipgloss.JoinHorizontal(lipgloss.Center, title, "  ", user, "  ", uptimeStr)
+	return lipgloss.JoinHorizontal(lipgloss.Center, title, "  ", user, "  ", uptimeStr, "  ", todayStr)
 }
 
 // Channel-table column widths. Used by both the header and row renderers.
@@ -95,8 +99,9 @@ func channelTableHeader() string {
 	return tableHeaderStyle.Render("  " + strings.Join(cells, " "))
 }
 
-// renderChannelRow renders a single channel row.
-func renderChannelRow(ch channels.Snapshot) string {
+// renderChannelRow renders a single channel row. The selected row gets
+// the same ▸ marker as the Drops-tab cursor.
+func renderChannelRow(ch channels.Snapshot, selected bool) string {
 	pri := subtitleStyle.Render("P2")
 	if ch.HasActiveDrop {
 		pri = dropStyle.Render("P0")
@@ -105,12 +110,16 @@ func renderChannelRow(ch channels.Snapshot) string {
 	}
 
 	status := offlineStyle.Render("OFFLINE")
-	if ch.IsOnline {
+	if ch.InHypeTrain(time.Now()) {
+		status = hypeTrainStyle.Render(fmt.Sprintf("HYPE Lv%d", ch.HypeTrainLevel))
+	} else if ch.IsOnline {
 		status = onlineStyle.Render("LIVE")
 	}
 
 	watching := subtitleStyle.Render("-")
-	if ch.IsWatching {
+	if ch.Paused {
+		watching = offlineStyle.Render("PAUSED")
+	} else if ch.IsWatching {
 		watching = watchingStyle.Render("ACTIVE")
 	}
 
@@ -119,6 +128,9 @@ func renderChannelRow(ch channels.Snapshot) string {
 		pct := (ch.DropProgress * 100) / ch.DropRequired
 		game = fmt.Sprintf("%s %d%%", ch.GameName, pct)
 	}
+	if ch.IsRerun && game != "" {
+		game = "RERUN " + game
+	}
 	if len(game) > chColGame {
 		game = game[:chColGame-2] + ".."
 	}
@@ -130,6 +142,9 @@ func renderChannelRow(ch channels.Snapshot) string {
 	if ch.IsTemporary {
 		name = ch.DisplayName + " [TEMP]"
 	}
+	if ch.SubTier > 0 {
+		name += fmt.Sprintf(" [T%d]", ch.SubTier)
+	}
 	if len(name) > chColName {
 		name = name[:chColName-2] + ".."
 	}
@@ -165,11 +180,16 @@ func renderChannelRow(ch channels.Snapshot) string {
 		padCell(claims,    chColClaims,    true),
 		padCell(lastClaim, chColLastClaim, false),
 	}
-	return "  " + strings.Join(cells, " ")
+	marker := "  "
+	if selected {
+		marker = cursorStyle.Render("▸ ")
+	}
+	return marker + strings.Join(cells, " ")
 }
 
-// renderChannelTableScrollable renders the channel table with scroll support.
-func renderChannelTableScrollable(channels []channels.Snapshot, width, maxRows, scroll int) string {
+// renderChannelTableScrollable renders the channel table with scroll
+// support. cursor is the index of the selected row in channels.
+func renderChannelTableScrollable(channels []channels.Snapshot, width, maxRows, scroll, cursor int) string {
 	if len(channels) == 0 {
 		return subtitleStyle.Render("  No channels configured. Press 'a' to add a channel.")
 	}
@@ -187,8 +207,8 @@ func renderChannelTableScrollable(channels []channels.Snapshot, width, maxRows,
 	if end > len(channels) {
 		end = len(channels)
 	}
-	for _, ch := range channels[scroll:end] {
-		parts = append(parts, renderChannelRow(ch))
+	for i, ch := range channels[scroll:end] {
+		parts = append(parts, renderChannelRow(ch, scroll+i == cursor))
 	}
 
 	// Scroll indicator bottom
@@ -200,103 +220,80 @@ func renderChannelTableScrollable(channels []channels.Snapshot, width, maxRows,
 	return strings.Join(parts, "\n")
 }
 
-// Drops mini-table column widths (Channels-tab ACTIVE-only mini-view).
-const (
-	dColCampaign = 24
-	dColGame     = 18
-	dColProgress = 16
-	dColChannel  = 16
-	dColStatus   = 10
-)
-
-// renderDropsTable renders the active drop campaigns table.
-func renderDropsTable(drops []drops.ActiveDrop, width int) string {
-	if len(drops) == 0 {
-		return ""
+// renderProgressBar draws a fixed-width bar for pct (0-100), e.g.
+// "██████░░░░".
+func renderProgressBar(pct, width int) string {
+	if pct < 0 {
+		pct = 0
 	}
-
-	headerCells := []string{
-		padCell("Campaign", dColCampaign, false),
-		padCell("Game",     dColGame,     false),
-		padCell("Progress", dColProgress, false),
-		padCell("Channel",  dColChannel,  false),
-		padCell("Status",   dColStatus,   false),
+	if pct > 100 {
+		pct = 100
 	}
-	headerLine := tableHeaderStyle.Render("  " + strings.Join(headerCells, " "))
-
-	var rows []string
-	for _, drop := range drops {
-		campaign := drop.CampaignName
-		if len(campaign) > dColCampaign {
-			campaign = campaign[:dColCampaign-2] + ".."
-		}
-
-		game := drop.GameName
-		if len(game) > dColGame {
-			game = game[:dColGame-2] + ".."
-		}
-
-		progress := "-"
-		if drop.Required > 0 {
-			progress = fmt.Sprintf("%d/%dmin (%d%%)", drop.Progress, drop.Required, drop.Percent)
-		}
-		if len(progress) > dColProgress {
-			progress = progress[:dColProgress-2] + ".."
-		}
-
-		channel := drop.ChannelLogin
-		if channel == "" {
-			channel = "-"
-		}
-		if drop.IsAutoSelected && channel != "-" {
-			channel += " [AUTO]"
-		}
-		if len(channel) > dColChannel {
-			channel = channel[:dColChannel-2] + ".."
-		}
-
-		statusLabel := drop.Status
-		if statusLabel == "" {
-			if !drop.IsEnabled {
-				statusLabel = "DISABLED"
-			} else {
-				statusLabel = "ACTIVE"
-			}
-		}
-		var status string
-		switch statusLabel {
-		case "ACTIVE":
-			status = dropStyle.Render(statusLabel)
-		default:
-			status = subtitleStyle.Render(statusLabel)
-		}
+	filled := pct * width / 100
+	return dropStyle.Render(strings.Repeat("█", filled)) +
+		subtitleStyle.Render(strings.Repeat("░", width-filled))
+}
 
-		cells := []string{
-			padCell(campaign, dColCampaign, false),
-			padCell(game,     dColGame,     false),
-			padCell(progress, dColProgress, false),
-			padCell(channel,  dColChannel,  false),
-			padCell(status,   dColStatus,   false),
-		}
-		rendered := "  " + strings.Join(cells, " ")
-		if drop.IsAutoDiscovered {
-			rendered += "  " + autoTagStyle.Render("[AUTO]")
-		}
-		rows = append(rows, rendered)
+// formatETA renders a minutes-remaining estimate as "45m" / "2h 05m".
+func formatETA(minutes int) string {
+	if minutes <= 0 {
+		return "-"
+	}
+	if minutes < 60 {
+		return fmt.Sprintf("%dm", minutes)
 	}
+	return fmt.Sprintf("%dh %02dm", minutes/60, minutes%60)
+}
 
-	title := titleStyle.Render(" Drop Campaigns ")
-	return title + "\n" + headerLine + "\n" + strings.Join(rows, "\n")
+// formatCountdown formats the time left until t as mm:ss, or "-" for
+// the zero time (not scheduled).
+func formatCountdown(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	secs := int(time.Until(t).Round(time.Second) / time.Second)
+	if secs < 0 {
+		secs = 0
+	}
+	return fmt.Sprintf("%02d:%02d", secs/60, secs%60)
 }
 
 // renderStatsBar renders the aggregate stats bar.
 func renderStatsBar(stats farmer.Stats, width int) string {
 	items := []string{
 		statLabelStyle.Render("Points Earned: ") + statValueStyle.Render(formatNumber(stats.TotalPointsEarned)),
-		statLabelStyle.Render("Claims: ") + statValueStyle.Render(fmt.Sprintf("%d", stats.TotalClaimsMade)),
-		statLabelStyle.Render("Online: ") + statValueStyle.Render(fmt.Sprintf("%d/%d", stats.ChannelsOnline, stats.ChannelsTotal)),
-		statLabelStyle.Render("Watching: ") + statValueStyle.Render(fmt.Sprintf("%d/2", stats.ChannelsWatching)),
-		statLabelStyle.Render("Drops: ") + dropStyle.Render(fmt.Sprintf("%d", stats.ActiveDrops)),
+		statLabelStyle.Render("Rate: ") + statValueStyle.Render(formatNumber(stats.PointsPerHour)+"/h"),
+	}
+	if stats.Paused {
+		items = append([]string{offlineStyle.Render("PAUSED (P to resume)")}, items...)
+	}
+	// Spent only shows once the user has redeemed something this session.
+	if stats.TotalPointsSpent > 0 {
+		items = append(items, statLabelStyle.Render("Spent: ")+statValueStyle.Render(formatNumber(stats.TotalPointsSpent)))
+	}
+	items = append(items,
+		statLabelStyle.Render("Claims: ")+statValueStyle.Render(fmt.Sprintf("%d", stats.TotalClaimsMade)),
+		statLabelStyle.Render("Moments: ")+statValueStyle.Render(fmt.Sprintf("%d", stats.TotalMoments)),
+		statLabelStyle.Render("Online: ")+statValueStyle.Render(fmt.Sprintf("%d/%d", stats.ChannelsOnline, stats.ChannelsTotal)),
+		statLabelStyle.Render("Watching: ")+statValueStyle.Render(fmt.Sprintf("%d/2", stats.ChannelsWatching)),
+		statLabelStyle.Render("Drops: ")+dropStyle.Render(fmt.Sprintf("%d", stats.ActiveDrops)),
+		statLabelStyle.Render("Rotation: ")+statValueStyle.Render(formatCountdown(stats.NextRotation)),
+	)
+	if !stats.NextDropCheck.IsZero() {
+		items = append(items, statLabelStyle.Render("Drop check: ")+statValueStyle.Render(formatCountdown(stats.NextDropCheck)))
+	}
+	// Capacity only shows once it matters — past 80% of the practical
+	// channel limit (see farmer.Capacity for the limiting factor).
+	if stats.Capacity.NearLimit {
+		capStyle := statValueStyle
+		if stats.Capacity.OverLimit {
+			capStyle = offlineStyle
+		}
+		items = append(items, statLabelStyle.Render("Capacity: ")+
+			capStyle.Render(fmt.Sprintf("%d/%d (%s)", stats.Capacity.Channels, stats.Capacity.Max, stats.Capacity.LimitingFactor)))
+	}
+	if stats.LogFileError != "" {
+		items = append(items, statLabelStyle.Render("Debug log: ")+offlineStyle.Render("memory only"))
 	}
 
 	content := strings.Join(items, "    ")
@@ -322,7 +319,16 @@ func renderEventLog(logs []farmer.LogEntry, height, width int) string {
 	for i := start; i < len(logs); i++ {
 		entry := logs[i]
 		timeStr := logTimeStyle.Render(entry.Time.Format("15:04:05"))
-		msg := logMessageStyle.Render(entry.Message)
+		msgStyle := logMessageStyle
+		switch {
+		case entry.Level >= slog.LevelError:
+			msgStyle = logErrorStyle
+		case entry.Level >= slog.LevelWarn:
+			msgStyle = logWarnStyle
+		case entry.Level < slog.LevelInfo:
+			msgStyle = logDebugStyle
+		}
+		msg := msgStyle.Render(entry.Message)
 		line := fmt.Sprintf(" %s  %s", timeStr, msg)
 
 		// Truncate if too wide. The width check uses byte-length on the
@@ -349,16 +355,19 @@ func renderEventLog(logs []farmer.LogEntry, height, width int) string {
 	return title + "\n" + logBorderStyle.Width(width - 2).Height(visibleLines).Render(content)
 }
 
-// renderHelpBar renders the Channels-tab footer help line. Drops-tab
-// has its own per-panel footer (renderDropsHelpFooter) and Help-tab
-// is itself the keybind reference, so this is Channels-only.
+// renderHelpBar renders the Channels-tab footer help line. Drops and
+// Logs have their own footers (renderDropsHelpFooter,
+// renderLogsHelpFooter) and Help-tab is itself the keybind reference,
+// so this is Channels-only.
 func renderHelpBar() string {
 	keys := []struct{ key, desc string }{
 		{"a", "add channel"},
 		{"d", "remove channel"},
 		{"p", "set priority"},
-		{"↑↓", "scroll"},
-		{"1/2/3", "tab"},
+		{"e", "settings"},
+		{"↑↓", "select"},
+		{"o/spc/t/w/r", "row actions"},
+		{"1-5", "tab"},
 		{"q", "quit"},
 	}
 
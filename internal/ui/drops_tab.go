@@ -5,7 +5,6 @@ import (
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
-	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/drops"
 )
 
@@ -15,53 +14,29 @@ import (
 // represents.
 type dropsSetting struct {
 	label   string
-	get     func(*config.Config) bool
-	toggle  func(*config.Config)
-	restart bool // true if the toggle takes effect on next farmer restart only
+	key     string // config.json key, passed to Backend.Get/SetBoolSetting
+	restart bool   // true if the toggle takes effect on next farmer restart only
 }
 
-// dropsSettings returns the runtime-toggleable boot config flags. All
-// three currently require a farmer restart to take effect — the Drops
-// tab's Space-toggle persists the change to config.json immediately
-// (via cfg.Save()) and the (restart required) hint reminds the user.
-//
-// Closures route through the lock-aware Get*/Set* methods so the
-// settings panel doesn't bypass Config.mu on direct field access.
-func dropsSettings(_ *config.Config) []dropsSetting {
+// dropsSettings returns the runtime-toggleable boot config flags. The
+// Drops tab's Space-toggle persists the change to config.json right
+// away (through the Backend). Only the web UI switch needs a farmer
+// restart to take effect; the (restart required) hint reminds the user.
+func dropsSettings() []dropsSetting {
 	return []dropsSetting{
-		{
-			label:   "Drops mining enabled",
-			get:     func(c *config.Config) bool { return c.GetDropsEnabled() },
-			toggle:  func(c *config.Config) { c.SetDropsEnabled(!c.GetDropsEnabled()) },
-			restart: true,
-		},
-		{
-			// Auto-claim is checked at runtime by the inventory cycle, so
-			// the toggle is live — no restart hint.
-			label:   "Auto-claim completed drops",
-			get:     func(c *config.Config) bool { return c.GetAutoClaim() },
-			toggle:  func(c *config.Config) { c.SetAutoClaim(!c.GetAutoClaim()) },
-			restart: false,
-		},
-		{
-			label:   "IRC viewer presence",
-			get:     func(c *config.Config) bool { return c.GetIrcEnabled() },
-			toggle:  func(c *config.Config) { c.SetIrcEnabled(!c.GetIrcEnabled()) },
-			restart: true,
-		},
-		{
-			label:   "Web UI enabled",
-			get:     func(c *config.Config) bool { return c.GetWebEnabled() },
-			toggle:  func(c *config.Config) { c.SetWebEnabled(!c.GetWebEnabled()) },
-			restart: true,
-		},
+		// Drops and IRC are switched as subsystems, auto-claim is checked
+		// at runtime by the inventory cycle — all live, no restart hint.
+		{label: "Drops mining enabled", key: "drops_enabled", restart: false},
+		{label: "Auto-claim completed drops", key: "auto_claim", restart: false},
+		{label: "IRC viewer presence", key: "irc_enabled", restart: false},
+		{label: "Web UI enabled", key: "web_enabled", restart: true},
 	}
 }
 
 // renderDropsCampaignsPanel draws the Drop Campaigns panel of the Drops
-// tab — a cursor-driven version of the existing renderDropsTable. The
-// active row gets a ▸ marker + cursor highlight when this panel is
-// focused.
+// tab: one row per campaign with a progress bar for the next drop and
+// its ETA. The active row gets a ▸ marker + cursor highlight when this
+// panel is focused.
 func renderDropsCampaignsPanel(rows []drops.ActiveDrop, cursor int, focused bool) string {
 	title := renderPanelTitle("Drop Campaigns", focused)
 
@@ -73,7 +48,8 @@ func renderDropsCampaignsPanel(rows []drops.ActiveDrop, cursor int, focused bool
 	const (
 		campaignW = 24
 		gameW     = 18
-		progressW = 16
+		progressW = 15 // 10-cell bar + " 100%"
+		etaW      = 7
 		channelW  = 16
 		statusW   = 10
 	)
@@ -84,6 +60,7 @@ func renderDropsCampaignsPanel(rows []drops.ActiveDrop, cursor int, focused bool
 		padCell("Campaign", campaignW, false),
 		padCell("Game",     gameW,     false),
 		padCell("Progress", progressW, false),
+		padCell("ETA",      etaW,      true),
 		padCell("Channel",  channelW,  false),
 		padCell("Status",   statusW,   false),
 	}
@@ -98,10 +75,17 @@ func renderDropsCampaignsPanel(rows []drops.ActiveDrop, cursor int, focused bool
 		campaign := truncate(d.CampaignName, campaignW)
 		game := truncate(d.GameName, gameW)
 		progress := "-"
+		eta := "-"
 		if d.Required > 0 {
-			progress = fmt.Sprintf("%d/%dmin (%d%%)", d.Progress, d.Required, d.Percent)
+			progress = renderProgressBar(d.Percent, 10) + fmt.Sprintf(" %3d%%", d.Percent)
+			eta = formatETA(d.EtaMinutes)
+			switch {
+			case d.NeedsPriority:
+				eta = offlineStyle.Render("^" + eta)
+			case d.AtRisk:
+				eta = offlineStyle.Render("!" + eta)
+			}
 		}
-		progress = truncate(progress, progressW)
 		channel := d.ChannelLogin
 		if channel == "" {
 			channel = "-"
@@ -131,6 +115,7 @@ func renderDropsCampaignsPanel(rows []drops.ActiveDrop, cursor int, focused bool
 			padCell(campaign, campaignW, false),
 			padCell(game,     gameW,     false),
 			padCell(progress, progressW, false),
+			padCell(eta,      etaW,      true),
 			padCell(channel,  channelW,  false),
 			padCell(status,   statusW,   false),
 		}
@@ -179,13 +164,13 @@ func renderDropsGamesPanel(games []string, cursor int, focused bool) string {
 
 // renderDropsSettingsPanel draws boolean config toggles. Each row shows
 // the current state as [x] / [ ] and (restart required) when applicable.
-func renderDropsSettingsPanel(cfg *config.Config, settings []dropsSetting, cursor int, focused bool) string {
+func renderDropsSettingsPanel(b Backend, settings []dropsSetting, cursor int, focused bool) string {
 	title := renderPanelTitle("Settings", focused)
 
 	var rows []string
 	for i, s := range settings {
 		box := "[ ]"
-		if s.get(cfg) {
+		if b.GetBoolSetting(s.key) {
 			box = "[x]"
 		}
 		hint := ""
@@ -225,6 +210,7 @@ func renderDropsHelpFooter(focused dropsPanel) string {
 	switch focused {
 	case dropsPanelCampaigns:
 		parts = append(parts, helpKeyStyle.Render("space")+helpStyle.Render(" toggle disable"))
+		parts = append(parts, helpKeyStyle.Render("s")+helpStyle.Render(" keep channel"))
 	case dropsPanelSettings:
 		parts = append(parts, helpKeyStyle.Render("space")+helpStyle.Render(" toggle setting"))
 	case dropsPanelGames:
@@ -236,8 +222,9 @@ func renderDropsHelpFooter(focused dropsPanel) string {
 		{"+", "add game"},
 		{"-", "remove game"},
 		{"u/d", "reorder"},
+		{"x", "blacklist"},
 		{"j/k", "navigate"},
-		{"1/2/3", "tab"},
+		{"1-5", "tab"},
 		{"q", "quit"},
 	}
 	for _, a := range always {
@@ -247,15 +234,26 @@ func renderDropsHelpFooter(focused dropsPanel) string {
 	return helpStyle.Render("  " + strings.Join(parts, "  |  "))
 }
 
+// renderBlacklistLine lists the auto-selection blacklist under the
+// Wanted Games panel; 'x' adds or removes an entry.
+func renderBlacklistLine(list []string) string {
+	value := subtitleStyle.Render("(empty — x adds a channel or game)")
+	if len(list) > 0 {
+		value = tableCellStyle.Render(strings.Join(list, ", "))
+	}
+	return "  " + helpKeyStyle.Render("Blacklist: ") + value
+}
+
 // truncate cuts s to fit width, appending ".." when truncation happens.
 // Used by the campaigns table cells to keep column alignment stable
 // when game/campaign names are long.
 func truncate(s string, width int) string {
-	if len(s) <= width {
+	r := []rune(s)
+	if len(r) <= width {
 		return s
 	}
 	if width <= 2 {
-		return s[:width]
+		return string(r[:width])
 	}
-	return s[:width-2] + ".."
+	return string(r[:width-2]) + ".."
 }
@@ -14,17 +14,26 @@ func renderHelpScreen() string {
 	sections = append(sections, titleStyle.Render(" Tab Navigation "))
 	sections = append(sections, helpRow("1", "Channels tab"))
 	sections = append(sections, helpRow("2", "Drops tab"))
-	sections = append(sections, helpRow("3", "Help tab (this view)"))
+	sections = append(sections, helpRow("3", "Logs tab"))
+	sections = append(sections, helpRow("4", "Stats tab"))
+	sections = append(sections, helpRow("5", "Help tab (this view)"))
 	sections = append(sections, helpRow("Tab / Shift+Tab", "cycle tabs"))
-	sections = append(sections, helpRow("q / Ctrl+C", "quit"))
+	sections = append(sections, helpRow("P", "pause / resume all farming (heartbeats and claims)"))
+	sections = append(sections, helpRow("q / Ctrl+C", "quit (q detaches to the background with quit_to_background)"))
 	sections = append(sections, "")
 
 	sections = append(sections, titleStyle.Render(" Channels Tab "))
 	sections = append(sections, helpRow("a", "add channel"))
 	sections = append(sections, helpRow("d", "remove channel"))
 	sections = append(sections, helpRow("p", "set priority (name 1=always-watch | 2=rotate)"))
-	sections = append(sections, helpRow("j / k or ↑ / ↓", "scroll channel table"))
+	sections = append(sections, helpRow("e", "edit selected channel's settings (priority, pause, goal, mode, IRC/PubSub/Spade)"))
+	sections = append(sections, helpRow("j / k or ↑ / ↓", "select channel"))
 	sections = append(sections, helpRow("home / end", "jump to top/bottom"))
+	sections = append(sections, helpRow("o", "open selected channel in browser"))
+	sections = append(sections, helpRow("space", "pause / resume selected channel"))
+	sections = append(sections, helpRow("t", "toggle selected channel's priority (1 ↔ 2)"))
+	sections = append(sections, helpRow("w", "watch selected channel now (one rotation)"))
+	sections = append(sections, helpRow("r", "refresh selected channel's balance"))
 	sections = append(sections, "")
 
 	sections = append(sections, titleStyle.Render(" Drops Tab "))
@@ -33,6 +42,21 @@ func renderHelpScreen() string {
 	sections = append(sections, helpRow("+", "add game (Wanted Games panel)"))
 	sections = append(sections, helpRow("-", "remove game (Wanted Games panel)"))
 	sections = append(sections, helpRow("u / d", "reorder game up/down (Wanted Games panel)"))
+	sections = append(sections, helpRow("x", "blacklist a channel or game for auto-selection (again to remove)"))
+	sections = append(sections, helpRow("s", "keep the highlighted campaign's temporary channel (save it to the config)"))
+	sections = append(sections, "")
+
+	sections = append(sections, titleStyle.Render(" Logs Tab "))
+	sections = append(sections, helpRow("j / k or ↑ / ↓", "scroll one line"))
+	sections = append(sections, helpRow("pgup / pgdn", "scroll one page"))
+	sections = append(sections, helpRow("home / end", "oldest entry / follow new entries"))
+	sections = append(sections, "")
+
+	sections = append(sections, titleStyle.Render(" Stats Tab "))
+	sections = append(sections, helpRow("r", "run the points rotation now"))
+	sections = append(sections, helpRow("c", "run the drops check now"))
+	sections = append(sections, helpRow("v", "compare today/yesterday and session/7-day average"))
+	sections = append(sections, helpRow("b", "points by reason code (WATCH, CLAIM, RAID, ...)"))
 	sections = append(sections, "")
 
 	sections = append(sections, titleStyle.Render(" How TwitchPoint farms "))
@@ -64,6 +88,9 @@ func renderHelpScreen() string {
 	sections = append(sections, paragraph(
 		"Watcher's current channel is skipped by points rotation to avoid double-tracking.",
 	))
+	sections = append(sections, paragraph(
+		"A channel with a running Hype Train ("+hypeTrainStyle.Render("HYPE Lv N")+") jumps ahead of P0 until the train ends.",
+	))
 	sections = append(sections, "")
 	sections = append(sections, paragraph(
 		"Drop campaigns marked "+autoTagStyle.Render("[AUTO]")+" are farmed automatically because",
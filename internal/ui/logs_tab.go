@@ -0,0 +1,91 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// logPageRows is how many log lines fit on the Logs tab: everything
+// below the header(3) except the log title(1), border(2) and footer
+// help(1) plus 1 buffer.
+func (m Model) logPageRows() int {
+	overhead := 3 + 5
+	if banner := renderUpdateBanner(m.farmer.GetUpdateInfo()); banner != "" {
+		overhead += 2
+	}
+	rows := m.height - overhead
+	if rows < 3 {
+		rows = 3
+	}
+	return rows
+}
+
+// handleLogsKey scrolls the Logs tab. logScroll counts entries back
+// from the newest, so new log lines don't move a pinned view.
+func (m Model) handleLogsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	total := len(m.farmer.GetLogs())
+	page := m.logPageRows()
+	maxScroll := max0(total - page)
+
+	switch msg.String() {
+	case "up", "k":
+		m.logScroll++
+	case "down", "j":
+		m.logScroll--
+	case "pgup", "ctrl+u":
+		m.logScroll += page
+	case "pgdown", "ctrl+d":
+		m.logScroll -= page
+	case "home", "g":
+		m.logScroll = maxScroll
+	case "end", "G":
+		m.logScroll = 0
+	}
+	if m.logScroll > maxScroll {
+		m.logScroll = maxScroll
+	}
+	if m.logScroll < 0 {
+		m.logScroll = 0
+	}
+	return m, nil
+}
+
+// viewLogsTab renders the full-height, scrollable event log.
+func (m Model) viewLogsTab() string {
+	logs := m.farmer.GetLogs()
+	page := m.logPageRows()
+
+	scroll := m.logScroll
+	if maxScroll := max0(len(logs) - page); scroll > maxScroll {
+		scroll = maxScroll
+	}
+	// renderEventLog shows the tail of what it's given; cut the newer
+	// entries off to scroll back.
+	visible := logs[:len(logs)-scroll]
+
+	return renderEventLog(visible, page+2, m.width) + "\n" + renderLogsHelpFooter(scroll, len(logs))
+}
+
+// renderLogsHelpFooter shows the scroll keys and, when scrolled back,
+// how far behind the tail the view is.
+func renderLogsHelpFooter(scroll, total int) string {
+	keys := []struct{ key, desc string }{
+		{"↑↓", "scroll"},
+		{"pgup/pgdn", "page"},
+		{"home/end", "oldest/follow"},
+		{"1-5", "tab"},
+		{"q", "quit"},
+	}
+	var parts []string
+	if scroll > 0 {
+		parts = append(parts, statValueStyle.Render(fmt.Sprintf("%d newer of %d", scroll, total)))
+	} else {
+		parts = append(parts, subtitleStyle.Render("following"))
+	}
+	for _, k := range keys {
+		parts = append(parts, helpKeyStyle.Render(k.key)+helpStyle.Render(" "+k.desc))
+	}
+	return helpStyle.Render("  " + strings.Join(parts, "  |  "))
+}
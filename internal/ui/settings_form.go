@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// channelSettingRows are the rows of the channel settings form ('e' on
+// the Channels tab), top to bottom.
+var channelSettingRows = []string{
+	"Priority",
+	"Paused",
+	"Moments auto-claim",
+	"Points goal",
+	"Max points",
+	"Mode",
+	"IRC presence",
+	"PubSub events",
+	"Spade heartbeats",
+}
+
+// channelModes is the order Space cycles a channel's mode through.
+var channelModes = []string{"both", "points", "drops"}
+
+// openChannelSettings loads the selected channel's settings into the
+// form. Temp drop channels have none (they aren't in config).
+func (m Model) openChannelSettings(login string) Model {
+	s, err := m.farmer.GetChannelSettings(login)
+	if err != nil {
+		m.setChannelActionErr(err)
+		return m
+	}
+	m.editLogin = login
+	m.editSettings = s
+	m.editCursor = 0
+	return m
+}
+
+// handleSettingsKey drives the open settings form: j/k select a row,
+// Space/Enter change it (the goal and max points rows open a number prompt) and
+// Esc/e close the form. Every change is applied right away. The global
+// keys (tabs, P, q) are handled before this.
+func (m Model) handleSettingsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "e":
+		m.editLogin = ""
+	case "up", "k":
+		if m.editCursor > 0 {
+			m.editCursor--
+		}
+	case "down", "j":
+		if m.editCursor < len(channelSettingRows)-1 {
+			m.editCursor++
+		}
+	case " ", "space", "enter":
+		s := m.editSettings
+		switch channelSettingRows[m.editCursor] {
+		case "Priority":
+			s.Priority = 3 - s.Priority // 1 <-> 2
+		case "Paused":
+			s.Paused = !s.Paused
+		case "Moments auto-claim":
+			s.Moments = !s.Moments
+		case "Points goal":
+			m.inputMode = inputSetGoal
+			m.inputValue = ""
+			if s.Goal > 0 {
+				m.inputValue = strconv.Itoa(s.Goal)
+			}
+			return m, nil
+		case "Max points":
+			m.inputMode = inputSetMaxPoints
+			m.inputValue = ""
+			if s.MaxPoints > 0 {
+				m.inputValue = strconv.Itoa(s.MaxPoints)
+			}
+			return m, nil
+		case "Mode":
+			s.Mode = channelModes[0]
+			for i, mode := range channelModes {
+				if mode == m.editSettings.Mode {
+					s.Mode = channelModes[(i+1)%len(channelModes)]
+				}
+			}
+		case "IRC presence":
+			s.IRC = !s.IRC
+		case "PubSub events":
+			s.PubSub = !s.PubSub
+		case "Spade heartbeats":
+			s.Spade = !s.Spade
+		}
+		m.applyChannelSettings(s)
+	}
+	return m, nil
+}
+
+// applyChannelSettings sends s for the channel being edited and keeps it
+// as the form's state if the farmer accepted it.
+func (m *Model) applyChannelSettings(s farmer.ChannelSettings) {
+	if err := m.farmer.SetChannelSettingsLive(m.editLogin, s); err != nil {
+		m.setChannelActionErr(err)
+		return
+	}
+	m.editSettings = s
+}
+
+// renderChannelSettings draws the settings form that replaces the help
+// bar while it is open.
+func (m Model) renderChannelSettings() string {
+	s := m.editSettings
+	values := []string{
+		map[int]string{1: "1 (always watch)", 2: "2 (rotate)"}[s.Priority],
+		yesNo(s.Paused),
+		onOff(s.Moments),
+		"none",
+		"none",
+		s.Mode,
+		onOff(s.IRC),
+		onOff(s.PubSub),
+		onOff(s.Spade),
+	}
+	if s.Goal > 0 {
+		values[3] = strconv.Itoa(s.Goal)
+	}
+	if s.MaxPoints > 0 {
+		values[4] = strconv.Itoa(s.MaxPoints)
+	}
+
+	lines := []string{renderPanelTitle("Settings: "+m.editLogin, true)}
+	for i, label := range channelSettingRows {
+		row := fmt.Sprintf("%-20s %s", label, values[i])
+		if i == m.editCursor {
+			lines = append(lines, "  "+cursorStyle.Render("▸ ")+lipgloss.NewStyle().Foreground(colorWhite).Bold(true).Render(row))
+		} else {
+			lines = append(lines, "    "+tableCellStyle.Render(row))
+		}
+	}
+	lines = append(lines, "  "+helpKeyStyle.Render("↑↓")+helpStyle.Render(" select  ")+
+		helpKeyStyle.Render("space/enter")+helpStyle.Render(" change (saved right away)  ")+
+		helpKeyStyle.Render("esc")+helpStyle.Render(" close"))
+	return strings.Join(lines, "\n")
+}
+
+func yesNo(v bool) string {
+	if v {
+		return "yes"
+	}
+	return "no"
+}
+
+func onOff(v bool) string {
+	if v {
+		return "on"
+	}
+	return "off"
+}
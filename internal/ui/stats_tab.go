@@ -0,0 +1,422 @@
+package ui
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+// Stats-tab per-channel table column widths.
+const (
+	stColName    = 18
+	stColEarned  = 10
+	stColRate    = 9
+	stColSpent   = 9
+	stColBalance = 10
+	stColClaims  = 7
+)
+
+// Stats-tab comparison view column widths.
+const (
+	cmpColLabel = 16
+	cmpColHours = 7
+	cmpColTotal = 9
+	cmpColRate  = 9
+)
+
+// Stats-tab reason breakdown column widths.
+const (
+	rsnColReason      = 16
+	rsnColPoints      = 10
+	rsnColShare       = 6
+	rsnChannelReasons = 4 // reason columns of the per-channel table, besides Other
+)
+
+// cmpRegression is the rate change (percent) from which the comparison
+// view marks a period red: a drop this steep is rarely bad luck.
+const cmpRegression = -25
+
+// viewStatsTab renders the aggregate session numbers and a per-channel
+// earnings breakdown, best earners first.
+func (m Model) viewStatsTab(stats farmer.Stats) string {
+	var sections []string
+	var reasons farmer.ReasonSummary
+	switch {
+	case m.statsReasons:
+		reasons = m.farmer.GetReasonSummary()
+		sections = renderReasons(reasons)
+	case m.statsCompare:
+		sections = append(sections, renderComparison(m.farmer.GetComparison())...)
+	default:
+		sections = m.statsSections(stats)
+	}
+
+	// Header(3) + the sections above (one line each) + table
+	// title/header(3) + "more" line(1) + footer(2) + 1 buffer.
+	rows := m.height - 3 - len(sections) - 7
+	if m.statsReasons {
+		if reasons.Error == "" {
+			sections = append(sections, renderReasonChannels(reasons.Week, rows))
+		}
+	} else {
+		sections = append(sections, renderEarnersTable(m.farmer.GetChannels(), rows))
+	}
+
+	sections = append(sections, "")
+	if m.errMsg != "" && time.Now().Before(m.errExpiry) {
+		sections = append(sections, lipgloss.NewStyle().Foreground(colorRed).Render("  "+m.errMsg))
+	} else {
+		sections = append(sections, renderStatsHelpFooter(m.statsCompare, m.statsReasons))
+	}
+
+	return strings.Join(sections, "\n")
+}
+
+// statsSections renders the Session, Channels and Schedule sections.
+func (m Model) statsSections(stats farmer.Stats) []string {
+	redeemed := 0
+	for _, r := range m.farmer.GetRedemptions() {
+		if r.Error == "" {
+			redeemed++
+		}
+	}
+
+	var sections []string
+	sections = append(sections, titleStyle.Render(" Session "))
+	sections = append(sections,
+		statRow("Uptime", formatDuration(stats.Uptime)),
+		statRow("Points earned", formatNumber(stats.TotalPointsEarned)),
+		statRow("Rate", formatNumber(stats.PointsPerHour)+"/h"),
+		statRow("Points spent", formatNumber(stats.TotalPointsSpent)),
+		statRow("Net", formatSigned(stats.TotalPointsEarned-stats.TotalPointsSpent)),
+		statRow("Bonus claims", fmt.Sprintf("%d", stats.TotalClaimsMade)),
+		statRow("Moments", fmt.Sprintf("%d", stats.TotalMoments)),
+		statRow("Auto-redeems", fmt.Sprintf("%d", redeemed)),
+		"",
+	)
+
+	sections = append(sections, titleStyle.Render(" Channels "))
+	sections = append(sections,
+		statRow("Online", fmt.Sprintf("%d/%d", stats.ChannelsOnline, stats.ChannelsTotal)),
+		statRow("Watching", fmt.Sprintf("%d/2", stats.ChannelsWatching)),
+		statRow("Active drops", fmt.Sprintf("%d", stats.ActiveDrops)),
+		statRow("Capacity", fmt.Sprintf("%d/%d (%s)", stats.Capacity.Channels, stats.Capacity.Max, stats.Capacity.LimitingFactor)),
+		"",
+	)
+
+	sections = append(sections, titleStyle.Render(" Schedule "))
+	sections = append(sections,
+		statRow("Next rotation", formatCountdown(stats.NextRotation)),
+		statRow("Next drop check", formatCountdown(stats.NextDropCheck)),
+		"",
+	)
+	return sections
+}
+
+// renderComparison renders today against yesterday and the session
+// against the 7-day average, one line per row. The change rows compare
+// hourly rates, so a day or session still in progress isn't behind
+// just for being shorter.
+func renderComparison(c farmer.Comparison) []string {
+	header := []string{
+		padCell("", cmpColLabel, false),
+		padCell("Hours", cmpColHours, true),
+		padCell("Points", cmpColTotal, true),
+		padCell("Pts/h", cmpColRate, true),
+		padCell("Claims", cmpColTotal, true),
+		padCell("Claims/h", cmpColRate, true),
+		padCell("Drop min", cmpColTotal, true),
+		padCell("Drop/h", cmpColRate, true),
+	}
+	row := func(label string, p farmer.PeriodStats) string {
+		cells := []string{
+			padCell(label, cmpColLabel, false),
+			padCell(fmt.Sprintf("%.1f", p.Hours), cmpColHours, true),
+			padCell(formatNumber(p.Points), cmpColTotal, true),
+			padCell(formatNumber(int(p.PointsPerHour+0.5)), cmpColRate, true),
+			padCell(fmt.Sprintf("%d", p.Claims), cmpColTotal, true),
+			padCell(fmt.Sprintf("%.1f", p.ClaimsPerHour), cmpColRate, true),
+			padCell(fmt.Sprintf("%d", p.DropMinutes), cmpColTotal, true),
+			padCell(fmt.Sprintf("%.1f", p.DropMinutesPerHour), cmpColRate, true),
+		}
+		return "  " + strings.Join(cells, " ")
+	}
+	change := func(label string, cur, base farmer.PeriodStats) string {
+		cells := []string{
+			padCell(label, cmpColLabel, false),
+			padCell("", cmpColHours, true),
+			padCell("", cmpColTotal, true),
+			padCell(formatChange(cur.PointsPerHour, base.PointsPerHour), cmpColRate, true),
+			padCell("", cmpColTotal, true),
+			padCell(formatChange(cur.ClaimsPerHour, base.ClaimsPerHour), cmpColRate, true),
+			padCell("", cmpColTotal, true),
+			padCell(formatChange(cur.DropMinutesPerHour, base.DropMinutesPerHour), cmpColRate, true),
+		}
+		return "  " + strings.Join(cells, " ")
+	}
+
+	weekLabel := "7-day average"
+	if c.WeekDays > 0 && c.WeekDays < 7 {
+		weekLabel = fmt.Sprintf("%d-day average", c.WeekDays)
+	}
+	lines := []string{
+		titleStyle.Render(" Comparison "),
+		tableHeaderStyle.Render("  " + strings.Join(header, " ")),
+		row("Today", c.Today),
+		row("Yesterday", c.Yesterday),
+		change("  change", c.Today, c.Yesterday),
+		"",
+		row("Session", c.Session),
+		row(weekLabel, c.WeekAverage),
+		change("  change", c.Session, c.WeekAverage),
+		"",
+	}
+	if c.WeekDays == 0 {
+		lines = append(lines, subtitleStyle.Render("  No earlier days recorded yet — the averages fill in as the farmer runs."), "")
+	}
+	return lines
+}
+
+// renderReasons renders the points by reason code this session, today,
+// over the last 7 days and all time, with each reason's share of the
+// all-time total, biggest earners first.
+func renderReasons(s farmer.ReasonSummary) []string {
+	lines := []string{titleStyle.Render(" Points by Reason ")}
+	if s.Error != "" {
+		return append(lines, subtitleStyle.Render("  "+s.Error), "")
+	}
+	if len(s.All.Reasons) == 0 {
+		return append(lines, subtitleStyle.Render("  No points recorded yet."), "")
+	}
+
+	header := []string{
+		padCell("Reason", rsnColReason, false),
+		padCell("Session", rsnColPoints, true),
+		padCell("Today", rsnColPoints, true),
+		padCell("7 days", rsnColPoints, true),
+		padCell("All time", rsnColPoints, true),
+		padCell("Share", rsnColShare, true),
+	}
+	lines = append(lines, tableHeaderStyle.Render("  "+strings.Join(header, " ")))
+	row := func(label string, session, today, week, all int) string {
+		share := "-"
+		if s.All.Points > 0 {
+			share = fmt.Sprintf("%d%%", int(math.Round(float64(all)*100/float64(s.All.Points))))
+		}
+		cells := []string{
+			padCell(truncate(label, rsnColReason), rsnColReason, false),
+			padCell(dashIfZero(session, ""), rsnColPoints, true),
+			padCell(dashIfZero(today, ""), rsnColPoints, true),
+			padCell(dashIfZero(week, ""), rsnColPoints, true),
+			padCell(dashIfZero(all, ""), rsnColPoints, true),
+			padCell(share, rsnColShare, true),
+		}
+		return "  " + strings.Join(cells, " ")
+	}
+	for _, r := range s.All.Reasons {
+		lines = append(lines, row(r.Reason,
+			reasonPoints(s.Session.Reasons, r.Reason),
+			reasonPoints(s.Today.Reasons, r.Reason),
+			reasonPoints(s.Week.Reasons, r.Reason),
+			r.Points))
+	}
+	lines = append(lines, row("Total", s.Session.Points, s.Today.Points, s.Week.Points, s.All.Points), "")
+	return lines
+}
+
+// renderReasonChannels lists channels by points earned over the last 7
+// days, split into the week's biggest reason codes and the rest, capped
+// at maxRows.
+func renderReasonChannels(week history.Breakdown, maxRows int) string {
+	title := titleStyle.Render(" By Channel, last 7 days ")
+	if len(week.Channels) == 0 {
+		return title + "\n" + subtitleStyle.Render("  No points in the last 7 days.")
+	}
+
+	var cols []string
+	for _, r := range week.Reasons {
+		if len(cols) == rsnChannelReasons {
+			break
+		}
+		cols = append(cols, r.Reason)
+	}
+	headerCells := []string{padCell("Channel", stColName, false), padCell("Total", rsnColPoints, true)}
+	for _, c := range cols {
+		headerCells = append(headerCells, padCell(truncate(c, rsnColPoints), rsnColPoints, true))
+	}
+	headerCells = append(headerCells, padCell("Other", rsnColPoints, true))
+	lines := []string{title, tableHeaderStyle.Render("  " + strings.Join(headerCells, " "))}
+
+	if maxRows < 3 {
+		maxRows = 3
+	}
+	shown := week.Channels
+	if len(shown) > maxRows {
+		shown = shown[:maxRows]
+	}
+	for _, ch := range shown {
+		cells := []string{padCell(truncate(ch.Login, stColName), stColName, false), padCell(dashIfZero(ch.Points, ""), rsnColPoints, true)}
+		other := ch.Points
+		for _, c := range cols {
+			p := reasonPoints(ch.Reasons, c)
+			other -= p
+			cells = append(cells, padCell(dashIfZero(p, ""), rsnColPoints, true))
+		}
+		cells = append(cells, padCell(dashIfZero(other, ""), rsnColPoints, true))
+		lines = append(lines, "  "+strings.Join(cells, " "))
+	}
+	if rest := len(week.Channels) - len(shown); rest > 0 {
+		lines = append(lines, subtitleStyle.Render(fmt.Sprintf("  … %d more", rest)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// reasonPoints returns the points of reason in totals, 0 if absent.
+func reasonPoints(totals []history.ReasonTotal, reason string) int {
+	for _, t := range totals {
+		if t.Reason == reason {
+			return t.Points
+		}
+	}
+	return 0
+}
+
+// formatChange formats the change from base to cur in percent, red
+// from cmpRegression down; "-" when there's nothing to compare with.
+func formatChange(cur, base float64) string {
+	if base <= 0 {
+		return "-"
+	}
+	pct := int(math.Round((cur - base) / base * 100))
+	text := fmt.Sprintf("%+d%%", pct)
+	if pct <= cmpRegression {
+		return lipgloss.NewStyle().Foreground(colorRed).Render(text)
+	}
+	return text
+}
+
+// handleStatsKey dispatches the Stats-tab actions: run the points
+// rotation or the drops check now instead of waiting for the countdown,
+// or switch to the comparison or reason breakdown view.
+func (m Model) handleStatsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "r":
+		m.farmer.RotateNow()
+	case "c":
+		m.setChannelActionErr(m.farmer.CheckDropsNow())
+	case "v":
+		m.statsCompare = !m.statsCompare
+		m.statsReasons = false
+	case "b":
+		m.statsReasons = !m.statsReasons
+		m.statsCompare = false
+	}
+	return m, nil
+}
+
+// renderStatsHelpFooter lists the Stats-tab keys.
+func renderStatsHelpFooter(comparing, reasons bool) string {
+	view, breakdown := "compare days", "by reason"
+	if comparing {
+		view = "session view"
+	}
+	if reasons {
+		breakdown = "session view"
+	}
+	keys := []struct{ key, desc string }{
+		{"r", "rotate now"},
+		{"c", "check drops now"},
+		{"v", view},
+		{"b", breakdown},
+		{"1-5", "tab"},
+		{"q", "quit"},
+	}
+	var parts []string
+	for _, k := range keys {
+		parts = append(parts, helpKeyStyle.Render(k.key)+helpStyle.Render(" "+k.desc))
+	}
+	return helpStyle.Render("  " + strings.Join(parts, "  |  "))
+}
+
+// renderEarnersTable lists channels by points earned this session,
+// capped at maxRows.
+func renderEarnersTable(chs []channels.Snapshot, maxRows int) string {
+	title := titleStyle.Render(" Earnings by Channel ")
+	sort.SliceStable(chs, func(i, j int) bool {
+		return chs[i].PointsEarnedSession > chs[j].PointsEarnedSession
+	})
+	if len(chs) == 0 {
+		return title + "\n" + subtitleStyle.Render("  No channels tracked.")
+	}
+
+	headerCells := []string{
+		padCell("Channel", stColName, false),
+		padCell("Earned", stColEarned, true),
+		padCell("Rate", stColRate, true),
+		padCell("Spent", stColSpent, true),
+		padCell("Balance", stColBalance, true),
+		padCell("Claims", stColClaims, true),
+	}
+	lines := []string{title, tableHeaderStyle.Render("  " + strings.Join(headerCells, " "))}
+
+	if maxRows < 3 {
+		maxRows = 3
+	}
+	shown := chs
+	if len(shown) > maxRows {
+		shown = shown[:maxRows]
+	}
+	for _, ch := range shown {
+		cells := []string{
+			padCell(truncate(ch.DisplayName, stColName), stColName, false),
+			padCell(dashIfZero(ch.PointsEarnedSession, "+"), stColEarned, true),
+			padCell(formatRate(ch.PointsPerHour), stColRate, true),
+			padCell(dashIfZero(ch.PointsSpentSession, "-"), stColSpent, true),
+			padCell(dashIfZero(ch.PointsBalance, ""), stColBalance, true),
+			padCell(fmt.Sprintf("%d", ch.ClaimsMade), stColClaims, true),
+		}
+		lines = append(lines, "  "+strings.Join(cells, " "))
+	}
+	if rest := len(chs) - len(shown); rest > 0 {
+		lines = append(lines, subtitleStyle.Render(fmt.Sprintf("  … %d more", rest)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// statRow renders one "label  value" line of the Stats tab.
+func statRow(label, value string) string {
+	return "  " + statLabelStyle.Render(fmt.Sprintf("%-16s", label)) + statValueStyle.Render(value)
+}
+
+// dashIfZero formats n with formatNumber and a sign prefix, or "-" for 0.
+func dashIfZero(n int, prefix string) string {
+	if n == 0 {
+		return "-"
+	}
+	return prefix + formatNumber(n)
+}
+
+// formatRate formats a points/hour rate, or "-" for 0.
+func formatRate(n int) string {
+	if n == 0 {
+		return "-"
+	}
+	return formatNumber(n) + "/h"
+}
+
+// formatSigned formats n with an explicit sign.
+func formatSigned(n int) string {
+	if n < 0 {
+		return "-" + formatNumber(-n)
+	}
+	return "+" + formatNumber(n)
+}
@@ -53,6 +53,10 @@ var (
 			Foreground(colorGreen).
 			Bold(true)
 
+	hypeTrainStyle = lipgloss.NewStyle().
+			Foreground(colorYellow).
+			Bold(true)
+
 	// Stats bar
 	statsBarStyle = lipgloss.NewStyle().
 			Border(lipgloss.RoundedBorder()).
@@ -77,6 +81,15 @@ var (
 	logMessageStyle = lipgloss.NewStyle().
 			Foreground(colorWhite)
 
+	logWarnStyle = lipgloss.NewStyle().
+			Foreground(colorYellow)
+
+	logErrorStyle = lipgloss.NewStyle().
+			Foreground(colorRed)
+
+	logDebugStyle = lipgloss.NewStyle().
+			Foreground(colorGray)
+
 	// Help bar
 	helpStyle = lipgloss.NewStyle().
 			Foreground(colorGray)
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/audit"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+)
+
+// ActivityResponse is one /api/activity entry.
+type ActivityResponse struct {
+	Time      time.Time `json:"time"`
+	Kind      string    `json:"kind"` // bonus_claim, moment_claim, raid_join, drop_claim, redemption
+	ChannelID string    `json:"channel_id,omitempty"`
+	Channel   string    `json:"channel,omitempty"`
+	Detail    string    `json:"detail,omitempty"`
+	Points    int       `json:"points,omitempty"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func activityResponse(a points.Activity) ActivityResponse {
+	return ActivityResponse{
+		Time:      a.At,
+		Kind:      a.Kind,
+		ChannelID: a.ChannelID,
+		Channel:   a.Channel,
+		Detail:    a.Detail,
+		Points:    a.Points,
+		Error:     a.Error,
+	}
+}
+
+// handleActivity returns the activity feed — bonus and Moment claims,
+// raid joins, drop claims and auto-redemptions — newest first. New
+// entries are also pushed as "activity" events on /api/events.
+// GET /api/activity[?kind=bonus_claim,raid_join][&limit=50] -> [{"time", "kind", "channel", "detail", ...}]
+func (s *Server) handleActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	limit := 0
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	var kinds map[string]bool
+	if k := r.URL.Query().Get("kind"); k != "" {
+		kinds = make(map[string]bool)
+		for _, kind := range strings.Split(k, ",") {
+			kinds[strings.TrimSpace(kind)] = true
+		}
+	}
+
+	feed := s.farmer.GetActivity()
+	resp := make([]ActivityResponse, 0, len(feed))
+	for i := len(feed) - 1; i >= 0; i-- {
+		if kinds != nil && !kinds[feed[i].Kind] {
+			continue
+		}
+		resp = append(resp, activityResponse(feed[i]))
+		if limit > 0 && len(resp) == limit {
+			break
+		}
+	}
+	jsonResponse(w, resp)
+}
+
+// handleAudit returns the audit log (audit.jsonl, written while
+// audit_log is on) newest first. Unlike the activity feed it covers
+// every session. from/to take the same forms as /api/history. Needs
+// web_token, like /api/failures.
+// GET /api/audit[?from=..&to=..][&kind=bonus_claim,drop_claim][&channel=name|id][&limit=100] -> [{"time", "kind", "channel", "result", ...}]
+func (s *Server) handleAudit(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	params := r.URL.Query()
+	q := audit.Query{Channel: strings.TrimSpace(params.Get("channel"))}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		if v := params.Get(p.name); v != "" {
+			t, err := parseHistoryTime(v)
+			if err != nil {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, p.name+": "+err.Error())
+				return
+			}
+			*p.dst = t
+		}
+	}
+	if l := params.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be a positive number")
+			return
+		}
+		q.Limit = n
+	}
+	if k := params.Get("kind"); k != "" {
+		q.Kinds = make(map[string]bool)
+		for _, kind := range strings.Split(k, ",") {
+			q.Kinds[strings.TrimSpace(kind)] = true
+		}
+	}
+
+	entries, err := s.farmer.Audit(q)
+	switch {
+	case errors.Is(err, farmer.ErrAuditDisabled):
+		s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	case err != nil:
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	if entries == nil {
+		entries = []audit.Entry{}
+	}
+	jsonResponse(w, entries)
+}
@@ -0,0 +1,50 @@
+package web
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/config"
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// TestAuthorized_SensitiveEndpoints: the endpoints that expose more than
+// the dashboard shows answer 401 without web_token, or to a remote
+// client when none is set.
+func TestAuthorized_SensitiveEndpoints(t *testing.T) {
+	cfg, err := config.Load(filepath.Join(t.TempDir(), "config.json"))
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	s := New(farmer.New(cfg, "test"), 0)
+	paths := []string{"/api/audit", "/api/failures", "/api/account", "/api/logs/files"}
+
+	do := func(path, remote, token string) int {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = remote
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		s.mux.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for _, p := range paths {
+		if code := do(p, "192.0.2.1:5000", ""); code != http.StatusUnauthorized {
+			t.Errorf("%s from a remote client without web_token = %d, want 401", p, code)
+		}
+	}
+
+	cfg.WebToken = "secret"
+	for _, p := range paths {
+		if code := do(p, "127.0.0.1:5000", "wrong"); code != http.StatusUnauthorized {
+			t.Errorf("%s with a wrong token = %d, want 401", p, code)
+		}
+	}
+	if code := do("/api/audit", "192.0.2.1:5000", "secret"); code == http.StatusUnauthorized {
+		t.Error("/api/audit with web_token = 401")
+	}
+}
@@ -0,0 +1,45 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+)
+
+// TestFilterTemporary: ?temporary=true keeps only the temporary drop
+// channels, false only the configured ones, empty all; anything else is
+// an error.
+func TestFilterTemporary(t *testing.T) {
+	chs := []channels.Snapshot{
+		{Login: "alpha"},
+		{Login: "drop1", IsTemporary: true},
+		{Login: "beta"},
+	}
+	logins := func(chs []channels.Snapshot) string {
+		var s string
+		for _, ch := range chs {
+			s += ch.Login + " "
+		}
+		return s
+	}
+
+	for v, want := range map[string]string{
+		"":      "alpha drop1 beta ",
+		"true":  "drop1 ",
+		"1":     "drop1 ",
+		"false": "alpha beta ",
+	} {
+		got, err := filterTemporary(chs, v)
+		if err != nil {
+			t.Errorf("temporary=%q: %v", v, err)
+			continue
+		}
+		if logins(got) != want {
+			t.Errorf("temporary=%q kept %q, want %q", v, logins(got), want)
+		}
+	}
+
+	if _, err := filterTemporary(chs, "maybe"); err == nil {
+		t.Error("temporary=maybe: want an error")
+	}
+}
@@ -0,0 +1,14 @@
+package web
+
+import "net/http"
+
+// handleCompare serves today vs yesterday and this session vs the
+// 7-day average (points, claims, drop minutes and their hourly rates).
+// GET /api/stats/compare
+func (s *Server) handleCompare(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	jsonResponse(w, s.farmer.GetComparison())
+}
@@ -0,0 +1,54 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/miwi/twitchpoint/internal/drops"
+)
+
+// handleDropHistory returns the completed-campaigns archive, newest
+// first: every campaign the farmer worked on that has since been fully
+// claimed, expired or left the inventory, with the channels used, the
+// minutes credited and the progress timeline. tracking=true lists the
+// campaigns still being farmed instead.
+// GET /api/drops/history[?game=Rust][&limit=20][&tracking=true]
+func (s *Server) handleDropHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	limit := 0
+	if l := q.Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	tracking := false
+	if t := q.Get("tracking"); t != "" {
+		v, err := strconv.ParseBool(t)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "tracking must be true or false")
+			return
+		}
+		tracking = v
+	}
+	game := strings.TrimSpace(q.Get("game"))
+
+	resp := []drops.CampaignRecord{}
+	for _, rec := range s.farmer.GetDropHistory(tracking) {
+		if game != "" && !strings.EqualFold(rec.Game, game) {
+			continue
+		}
+		resp = append(resp, rec)
+		if limit > 0 && len(resp) == limit {
+			break
+		}
+	}
+	jsonResponse(w, resp)
+}
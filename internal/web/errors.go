@@ -0,0 +1,233 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// API error codes (the "code" field of an error response). Clients
+// should branch on these rather than on the message text.
+const (
+	ErrCodeMethodNotAllowed = "method_not_allowed"
+	ErrCodeInvalidBody      = "invalid_body"
+	ErrCodeInvalidParameter = "invalid_parameter"
+	ErrCodeMissingParameter = "missing_parameter"
+	ErrCodeInvalidRequest   = "invalid_request"
+	ErrCodeUnauthorized     = "unauthorized"
+	ErrCodeNotLoggedIn      = "not_logged_in"
+	ErrCodeNotFound         = "not_found"
+	ErrCodeConflict         = "conflict"
+	ErrCodeSaveFailed       = "save_failed"
+	ErrCodeUpstream         = "upstream_failed"
+	ErrCodeUnavailable      = "unavailable"
+	ErrCodeInternal         = "internal_error"
+)
+
+// defaultLanguage is used when neither the request nor web_language
+// names a supported one.
+const defaultLanguage = "en"
+
+// errorCatalog holds the message of every error code per language.
+var errorCatalog = map[string]map[string]string{
+	ErrCodeMethodNotAllowed: {
+		"en": "This method is not allowed here.",
+		"de": "Diese Methode ist hier nicht erlaubt.",
+		"fr": "Cette méthode n'est pas autorisée ici.",
+		"es": "Este método no está permitido aquí.",
+	},
+	ErrCodeInvalidBody: {
+		"en": "The request body is not valid JSON for this endpoint.",
+		"de": "Der Request-Body ist kein gültiges JSON für diesen Endpunkt.",
+		"fr": "Le corps de la requête n'est pas un JSON valide pour ce point d'accès.",
+		"es": "El cuerpo de la petición no es un JSON válido para este endpoint.",
+	},
+	ErrCodeInvalidParameter: {
+		"en": "A parameter has an invalid value.",
+		"de": "Ein Parameter hat einen ungültigen Wert.",
+		"fr": "Un paramètre a une valeur invalide.",
+		"es": "Un parámetro tiene un valor no válido.",
+	},
+	ErrCodeMissingParameter: {
+		"en": "A required parameter is missing.",
+		"de": "Ein erforderlicher Parameter fehlt.",
+		"fr": "Un paramètre obligatoire est manquant.",
+		"es": "Falta un parámetro obligatorio.",
+	},
+	ErrCodeInvalidRequest: {
+		"en": "The request could not be carried out.",
+		"de": "Die Anfrage konnte nicht ausgeführt werden.",
+		"fr": "La requête n'a pas pu être exécutée.",
+		"es": "No se pudo realizar la petición.",
+	},
+	ErrCodeUnauthorized: {
+		"en": "Not authorized: set web_token in the config and send it as a Bearer token or ?token=.",
+		"de": "Nicht autorisiert: web_token in der Konfiguration setzen und als Bearer-Token oder ?token= mitschicken.",
+		"fr": "Non autorisé : définissez web_token dans la configuration et envoyez-le comme jeton Bearer ou ?token=.",
+		"es": "No autorizado: configura web_token y envíalo como token Bearer o ?token=.",
+	},
+	ErrCodeNotLoggedIn: {
+		"en": "Not logged in yet: log in through POST /api/auth/device.",
+		"de": "Noch nicht angemeldet: über POST /api/auth/device anmelden.",
+		"fr": "Pas encore connecté : connectez-vous via POST /api/auth/device.",
+		"es": "Aún no has iniciado sesión: inicia sesión con POST /api/auth/device.",
+	},
+	ErrCodeNotFound: {
+		"en": "Not found.",
+		"de": "Nicht gefunden.",
+		"fr": "Introuvable.",
+		"es": "No encontrado.",
+	},
+	ErrCodeConflict: {
+		"en": "The request conflicts with the current state.",
+		"de": "Die Anfrage widerspricht dem aktuellen Zustand.",
+		"fr": "La requête est en conflit avec l'état actuel.",
+		"es": "La petición entra en conflicto con el estado actual.",
+	},
+	ErrCodeSaveFailed: {
+		"en": "The config could not be saved.",
+		"de": "Die Konfiguration konnte nicht gespeichert werden.",
+		"fr": "La configuration n'a pas pu être enregistrée.",
+		"es": "No se pudo guardar la configuración.",
+	},
+	ErrCodeUpstream: {
+		"en": "Twitch did not answer as expected.",
+		"de": "Twitch hat nicht wie erwartet geantwortet.",
+		"fr": "Twitch n'a pas répondu comme prévu.",
+		"es": "Twitch no respondió como se esperaba.",
+	},
+	ErrCodeUnavailable: {
+		"en": "Not available right now.",
+		"de": "Gerade nicht verfügbar.",
+		"fr": "Indisponible pour le moment.",
+		"es": "No disponible en este momento.",
+	},
+	ErrCodeInternal: {
+		"en": "Something went wrong on the server.",
+		"de": "Auf dem Server ist etwas schiefgelaufen.",
+		"fr": "Une erreur s'est produite sur le serveur.",
+		"es": "Algo salió mal en el servidor.",
+	},
+}
+
+// APIError is the body of every API error response. Error is the
+// server's own (English) message, kept for older clients; Code and
+// Message are what clients should show, Detail what the message adds
+// to the code, if anything.
+type APIError struct {
+	Error   string `json:"error"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Detail  string `json:"detail,omitempty"`
+}
+
+// newAPIError builds the error body for code in lang; detail is what
+// the handler adds about this particular failure, if anything.
+func newAPIError(code, detail, lang string) APIError {
+	e := APIError{Error: detail, Code: code, Message: localize(code, lang), Detail: detail}
+	if detail == "" {
+		e.Error = localize(code, defaultLanguage)
+	}
+	return e
+}
+
+// localize returns the catalog message of code in lang, falling back to
+// English.
+func localize(code, lang string) string {
+	msgs := errorCatalog[code]
+	if m, ok := msgs[lang]; ok {
+		return m
+	}
+	return msgs[defaultLanguage]
+}
+
+// supportedLanguage reports whether the catalog has messages in lang.
+func supportedLanguage(lang string) bool {
+	_, ok := errorCatalog[ErrCodeInternal][lang]
+	return ok
+}
+
+// requestLanguage picks the language of r's error messages: ?lang=,
+// then the preferred supported Accept-Language entry, then
+// web_language, then English.
+func (s *Server) requestLanguage(r *http.Request) string {
+	if l := baseLanguage(r.URL.Query().Get("lang")); supportedLanguage(l) {
+		return l
+	}
+	if l := acceptLanguage(r.Header.Get("Accept-Language")); l != "" {
+		return l
+	}
+	if l := baseLanguage(s.farmer.Config().GetWebLanguage()); supportedLanguage(l) {
+		return l
+	}
+	return defaultLanguage
+}
+
+// acceptLanguage returns the supported language an Accept-Language
+// header prefers most (highest q, earliest on a tie), or "" if it
+// names none. Entries with q=0 are refused, not just least preferred.
+func acceptLanguage(header string) string {
+	type entry struct {
+		lang string
+		q    float64
+	}
+	var entries []entry
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		for _, p := range strings.Split(params, ";") {
+			k, v, ok := strings.Cut(strings.TrimSpace(p), "=")
+			if !ok || strings.TrimSpace(k) != "q" {
+				continue
+			}
+			f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil || f < 0 || f > 1 {
+				f = 0
+			}
+			q = f
+		}
+		if l := baseLanguage(tag); q > 0 && supportedLanguage(l) {
+			entries = append(entries, entry{l, q})
+		}
+	}
+	sort.SliceStable(entries, func(i, j int) bool { return entries[i].q > entries[j].q })
+	if len(entries) == 0 {
+		return ""
+	}
+	return entries[0].lang
+}
+
+// baseLanguage reduces a language tag ("de-AT") to its primary subtag
+// ("de").
+func baseLanguage(tag string) string {
+	tag, _, _ = strings.Cut(strings.TrimSpace(tag), "-")
+	return strings.ToLower(tag)
+}
+
+// ErrorCatalogResponse is the /api/errors response.
+type ErrorCatalogResponse struct {
+	Language  string            `json:"language"`
+	Languages []string          `json:"languages"`
+	Messages  map[string]string `json:"messages"` // code -> message
+}
+
+// handleErrorCatalog lists every error code with its message in the
+// request's language, for clients that render errors themselves.
+// GET /api/errors[?lang=de]
+func (s *Server) handleErrorCatalog(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	lang := s.requestLanguage(r)
+	resp := ErrorCatalogResponse{Language: lang, Messages: make(map[string]string, len(errorCatalog))}
+	for code := range errorCatalog {
+		resp.Messages[code] = localize(code, lang)
+	}
+	for l := range errorCatalog[ErrCodeInternal] {
+		resp.Languages = append(resp.Languages, l)
+	}
+	sort.Strings(resp.Languages)
+	jsonResponse(w, resp)
+}
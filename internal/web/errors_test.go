@@ -0,0 +1,83 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// TestJSONError_Codes: the code is the one the handler passed, whatever
+// the message says; a code-only error still fills error for old clients.
+func TestJSONError_Codes(t *testing.T) {
+	s := &Server{}
+	for _, tc := range []struct {
+		name   string
+		write  func(w http.ResponseWriter, r *http.Request)
+		status int
+		want   APIError
+	}{
+		{
+			name: "detail",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeMissingParameter, "not found in any campaign: id required")
+			},
+			status: http.StatusBadRequest,
+			want: APIError{
+				Error:   "not found in any campaign: id required",
+				Code:    ErrCodeMissingParameter,
+				Message: errorCatalog[ErrCodeMissingParameter]["de"],
+				Detail:  "not found in any campaign: id required",
+			},
+		},
+		{
+			name: "code only",
+			write: func(w http.ResponseWriter, r *http.Request) {
+				s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+			},
+			status: http.StatusMethodNotAllowed,
+			want: APIError{
+				Error:   errorCatalog[ErrCodeMethodNotAllowed]["en"],
+				Code:    ErrCodeMethodNotAllowed,
+				Message: errorCatalog[ErrCodeMethodNotAllowed]["de"],
+			},
+		},
+	} {
+		rec := httptest.NewRecorder()
+		tc.write(rec, httptest.NewRequest(http.MethodGet, "/api/x?lang=de", nil))
+		if rec.Code != tc.status {
+			t.Errorf("%s: status %d, want %d", tc.name, rec.Code, tc.status)
+		}
+		if got := rec.Header().Get("Content-Language"); got != "de" {
+			t.Errorf("%s: Content-Language %q, want de", tc.name, got)
+		}
+		var got APIError
+		if err := json.NewDecoder(rec.Body).Decode(&got); err != nil {
+			t.Fatalf("%s: %v", tc.name, err)
+		}
+		if got != tc.want {
+			t.Errorf("%s: body %+v, want %+v", tc.name, got, tc.want)
+		}
+	}
+}
+
+// TestAcceptLanguage: the highest q wins, ties go to the earlier entry,
+// q=0 refuses a language and unsupported ones are skipped.
+func TestAcceptLanguage(t *testing.T) {
+	for header, want := range map[string]string{
+		"":                           "",
+		"de":                         "de",
+		"fr;q=0.5, de;q=0.9":         "de",
+		"ja, fr-CA;q=0.8, es;q=0.8":  "fr",
+		"de;q=0, fr;q=0.1":           "fr",
+		"de;q=0":                     "",
+		"es;q=0.3, en-US":            "en",
+		"de;q=bogus, fr;q=0.2":       "fr",
+		"  DE-at ; q=0.7 , ja ;q=1":  "de",
+		"fr;level=1;q=0.4, es;q=0.6": "es",
+	} {
+		if got := acceptLanguage(header); got != want {
+			t.Errorf("acceptLanguage(%q) = %q, want %q", header, got, want)
+		}
+	}
+}
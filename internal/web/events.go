@@ -0,0 +1,100 @@
+package web
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+)
+
+// sseKeepAlive is how often a comment line is written on an idle stream
+// so proxies and the browser don't time the connection out.
+const sseKeepAlive = 25 * time.Second
+
+// handleEvents streams live updates as Server-Sent Events.
+//
+// GET /api/events -> text/event-stream with these event names:
+//
+//	event    {"type": "points_earned", "channel_id": "...", "data": {...}}
+//	log      {"time": "15:04:05", "message": "..."}
+//	channel  ChannelResponse (added or changed)
+//	channel_removed  {"channel_id": "..."}
+//	stream_live      farmer.StreamLive (a live_hook channel went live)
+//	channel_live     farmer.StreamLive (any configured channel went live)
+//	drop_claimed     drops.DropClaimed
+//	activity         ActivityResponse (claim, raid join, drop claim, redemption)
+//	auth_failed      farmer.AuthFailed (Twitch started rejecting the auth token)
+//
+// The stream only carries changes — clients load the initial state from
+// the regular /api/* endpoints. Slow clients lose messages instead of
+// blocking the farmer (see farmer.pushBufferSize).
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, "streaming unsupported")
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	events, cancel := s.farmer.Subscribe()
+	defer cancel()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case ev, ok := <-events:
+			if !ok {
+				return
+			}
+			payload, err := json.Marshal(s.pushPayload(ev))
+			if err != nil {
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", ev.Kind, payload); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// pushPayload converts a farmer push into the same JSON shapes the
+// polling endpoints use, so the dashboard can merge them directly.
+func (s *Server) pushPayload(ev farmer.PushEvent) interface{} {
+	switch data := ev.Data.(type) {
+	case farmer.LogEntry:
+		return logResponse(data)
+	case channels.Snapshot:
+		return s.channelResponse(data)
+	case points.Activity:
+		return activityResponse(data)
+	case string:
+		if ev.Kind == farmer.PushKindChannelRemoved {
+			return map[string]string{"channel_id": data}
+		}
+	}
+	return ev.Data
+}
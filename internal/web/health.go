@@ -0,0 +1,63 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// handleHealth reports the farmer's lifecycle state and each subsystem's
+// health. It answers before login and during a restart too.
+// GET /api/health -> {"state": "running", "since": "...", "restarts": 0, "subsystems": [{"name": "gql", "status": "up"}, ...]}
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	jsonResponse(w, s.farmer.Health())
+}
+
+// handleHealthz answers 200 when the farmer runs and its liveness checks
+// pass, 503 otherwise, with the checks either way. It sits outside /api/
+// so it needs no web_token and answers before login — it is meant for
+// Docker's HEALTHCHECK and --healthcheck.
+// GET /healthz -> {"ok": true, "state": "running", "checks": [{"name": "pubsub", "ok": true, "detail": "2 connections"}, ...]}
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	h := s.farmer.Healthz()
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	if !h.OK {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	json.NewEncoder(w).Encode(h)
+}
+
+// handleRestart tears down and restarts every subsystem in the
+// background, answering 202 with the state it moved to; poll
+// /api/health for the outcome. Gated like the debug logs.
+// POST /api/restart
+func (s *Server) handleRestart(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	switch st := s.farmer.Health().State; st {
+	case farmer.StateRunning, farmer.StateFailed:
+	default:
+		s.jsonError(w, r, http.StatusConflict, ErrCodeConflict, "farmer is "+st)
+		return
+	}
+	s.farmer.RestartInBackground()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(map[string]string{"state": farmer.StateRestarting})
+}
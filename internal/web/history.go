@@ -0,0 +1,262 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/history"
+)
+
+// defaultHistorySpan is the range /api/history covers when from is
+// omitted.
+const defaultHistorySpan = 7 * 24 * time.Hour
+
+// defaultChartSpan is the range /api/channels/{login}/chart covers when
+// from is omitted.
+const defaultChartSpan = 30 * 24 * time.Hour
+
+// HistoryResponse is the /api/history response.
+type HistoryResponse struct {
+	Channel string          `json:"channel,omitempty"`
+	From    time.Time       `json:"from"`
+	To      time.Time       `json:"to"`
+	Bucket  string          `json:"bucket"`
+	Total   int             `json:"total"`
+	Series  []history.Point `json:"series"`
+}
+
+// handleHistory serves the earnings history aggregated into buckets.
+// GET /api/history?channel=login&from=2026-03-01&to=2026-03-08&bucket=hour|day|week
+// from/to take RFC 3339 timestamps, local YYYY-MM-DD dates or unix
+// seconds; to defaults to now and from to a week before to.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+
+	to := time.Now()
+	if v := params.Get("to"); v != "" {
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "to: "+err.Error())
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultHistorySpan)
+	if v := params.Get("from"); v != "" {
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from: "+err.Error())
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from must be before to")
+		return
+	}
+	bucket := params.Get("bucket")
+	if bucket == "" {
+		bucket = history.BucketHour
+	}
+
+	q := history.Query{
+		Login:  strings.TrimSpace(params.Get("channel")),
+		From:   from,
+		To:     to,
+		Bucket: bucket,
+	}
+	series, err := s.farmer.History(q)
+	switch {
+	case errors.Is(err, farmer.ErrHistoryDisabled):
+		s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	case err != nil:
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+
+	resp := HistoryResponse{
+		Channel: strings.ToLower(q.Login),
+		From:    from,
+		To:      to,
+		Bucket:  bucket,
+		Series:  series,
+	}
+	if resp.Series == nil {
+		resp.Series = []history.Point{}
+	}
+	for _, p := range series {
+		resp.Total += p.Points
+	}
+	jsonResponse(w, resp)
+}
+
+// BreakdownResponse is the /api/stats/breakdown response: the range's
+// total and the points by reason code, overall and per channel.
+type BreakdownResponse struct {
+	Channel string     `json:"channel,omitempty"`
+	From    *time.Time `json:"from"` // null: all of history
+	To      time.Time  `json:"to"`
+	history.Breakdown
+}
+
+// handleBreakdown serves the points earned by reason code (WATCH,
+// CLAIM, WATCH_STREAK, RAID, ...), overall and per channel, most points
+// first.
+// GET /api/stats/breakdown?range=session|today|week|all&channel=login
+// GET /api/stats/breakdown?from=2026-03-01&to=2026-03-08
+// from/to are parsed like /api/history's; without range or from it
+// covers all of history.
+func (s *Server) handleBreakdown(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+
+	now := time.Now()
+	to := now
+	if v := params.Get("to"); v != "" {
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "to: "+err.Error())
+			return
+		}
+		to = t
+	}
+	var from time.Time
+	switch rng, v := params.Get("range"), params.Get("from"); {
+	case rng != "" && v != "":
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "range and from are exclusive")
+		return
+	case rng != "":
+		t, err := s.farmer.RangeStart(rng, now)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "range: "+err.Error())
+			return
+		}
+		from = t
+	case v != "":
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from: "+err.Error())
+			return
+		}
+		from = t
+	}
+	if !from.IsZero() && !from.Before(to) {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from must be before to")
+		return
+	}
+
+	q := history.Query{Login: strings.TrimSpace(params.Get("channel")), From: from, To: to}
+	b, err := s.farmer.Breakdown(q)
+	switch {
+	case errors.Is(err, farmer.ErrHistoryDisabled):
+		s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	case err != nil:
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	resp := BreakdownResponse{Channel: strings.ToLower(q.Login), To: to, Breakdown: b}
+	if !from.IsZero() {
+		resp.From = &from
+	}
+	jsonResponse(w, resp)
+}
+
+// parseHistoryTime accepts an RFC 3339 timestamp, a YYYY-MM-DD date
+// (local midnight) or unix seconds.
+func parseHistoryTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, v); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", v, time.Local); err == nil {
+		return t, nil
+	}
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognized time %q (want RFC 3339, YYYY-MM-DD or unix seconds)", v)
+}
+
+// ChartResponse is the /api/channels/{login}/chart response.
+type ChartResponse struct {
+	Channel string             `json:"channel"`
+	From    time.Time          `json:"from"`
+	To      time.Time          `json:"to"`
+	Earned  int                `json:"earned"`
+	Spent   int                `json:"spent"`
+	Days    []history.ChartDay `json:"days"`
+}
+
+// handleChannelChart serves a channel's daily earned and spent points
+// and end-of-day balance.
+// GET /api/channels/{login}/chart?from=2026-03-01&to=2026-03-31
+// from/to are parsed like /api/history's; to defaults to now and from
+// to 30 days before to.
+func (s *Server) handleChannelChart(w http.ResponseWriter, r *http.Request, login string) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	params := r.URL.Query()
+
+	to := time.Now()
+	if v := params.Get("to"); v != "" {
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "to: "+err.Error())
+			return
+		}
+		to = t
+	}
+	from := to.Add(-defaultChartSpan)
+	if v := params.Get("from"); v != "" {
+		t, err := parseHistoryTime(v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from: "+err.Error())
+			return
+		}
+		from = t
+	}
+	if !from.Before(to) {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "from must be before to")
+		return
+	}
+
+	days, err := s.farmer.ChannelChart(login, from, to)
+	switch {
+	case errors.Is(err, farmer.ErrHistoryDisabled):
+		s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, err.Error())
+		return
+	case err != nil:
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	resp := ChartResponse{
+		Channel: strings.ToLower(login),
+		From:    from,
+		To:      to,
+		Days:    days,
+	}
+	if resp.Days == nil {
+		resp.Days = []history.ChartDay{}
+	}
+	for _, d := range days {
+		resp.Earned += d.Earned
+		resp.Spent += d.Spent
+	}
+	jsonResponse(w, resp)
+}
@@ -0,0 +1,97 @@
+package web
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// LogFileResponse is a debug log in the /api/logs/files response.
+type LogFileResponse struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+	Current  bool      `json:"current"`
+}
+
+// authorized gates endpoints that expose more than the dashboard shows
+// (full debug logs contain channel IDs, campaign data and GQL errors).
+// With web_token set, the request must carry it as a Bearer header or
+// ?token= (for plain download links). Without a token only loopback
+// clients are allowed, so a web_bind of 0.0.0.0 doesn't silently leak
+// logs to the LAN.
+func (s *Server) authorized(r *http.Request) bool {
+	token := s.farmer.Config().GetWebToken()
+	if token == "" {
+		host, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			return false
+		}
+		ip := net.ParseIP(host)
+		return ip != nil && ip.IsLoopback()
+	}
+
+	got := r.URL.Query().Get("token")
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		got = strings.TrimPrefix(auth, "Bearer ")
+	}
+	return subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// handleLogFiles dispatches /api/logs/{files,download}.
+func (s *Server) handleLogFiles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+
+	switch strings.TrimPrefix(r.URL.Path, "/api/logs/") {
+	case "files":
+		s.handleLogFileList(w, r)
+	case "download":
+		s.handleLogFileDownload(w, r)
+	default:
+		s.jsonCodeError(w, r, http.StatusNotFound, ErrCodeNotFound)
+	}
+}
+
+// handleLogFileList lists the debug logs on disk, newest first.
+// GET /api/logs/files -> [{"name": "debug-2026-05-01.log", "size": 1234, ...}]
+func (s *Server) handleLogFileList(w http.ResponseWriter, r *http.Request) {
+	files, err := s.farmer.ListLogFiles()
+	if err != nil {
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	resp := make([]LogFileResponse, len(files))
+	for i, f := range files {
+		resp[i] = LogFileResponse{Name: f.Name, Size: f.Size, Modified: f.Modified, Current: f.Current}
+	}
+	jsonResponse(w, resp)
+}
+
+// handleLogFileDownload serves one debug log as an attachment.
+// GET /api/logs/download[?file=debug-2026-05-01.log] — defaults to today's file.
+func (s *Server) handleLogFileDownload(w http.ResponseWriter, r *http.Request) {
+	file, err := s.farmer.OpenLogFile(r.URL.Query().Get("file"))
+	if err != nil {
+		s.jsonError(w, r, http.StatusNotFound, ErrCodeNotFound, err.Error())
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+info.Name()+`"`)
+	http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+}
@@ -0,0 +1,69 @@
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/miwi/twitchpoint/internal/farmer"
+)
+
+// handler wraps the mux so that, until the farmer has started, only the
+// static files and the lifecycle endpoints (login, health, restart)
+// answer — everything else would reach subsystems that don't exist yet,
+// or are being torn down by a restart.
+func (s *Server) handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.farmer.Started() && strings.HasPrefix(r.URL.Path, "/api/") && !lifecyclePath(r.URL.Path) {
+			switch s.farmer.Health().State {
+			case farmer.StateRestarting:
+				s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "restarting: try again shortly")
+			case farmer.StateFailed:
+				s.jsonError(w, r, http.StatusServiceUnavailable, ErrCodeUnavailable, "restart failed: see GET /api/health, then POST /api/restart or log in again")
+			default:
+				s.jsonCodeError(w, r, http.StatusServiceUnavailable, ErrCodeNotLoggedIn)
+			}
+			return
+		}
+		if strings.HasPrefix(r.URL.Path, "/api/") && s.farmer.Started() && s.wantUTC(r) {
+			w = utcWriter{w}
+		}
+		s.mux.ServeHTTP(w, r)
+	})
+}
+
+// lifecyclePath reports whether path answers before the farmer has
+// started: login, health and restart (which retries a failed one).
+func lifecyclePath(path string) bool {
+	switch path {
+	case "/api/auth/device", "/api/health", "/api/restart":
+		return true
+	}
+	return false
+}
+
+// handleDeviceLogin runs the Twitch device-code login from the browser.
+// POST requests a code (or returns the pending one) and polls for the
+// authorization in the background; GET reports progress. While a headless
+// farmer waits for its first login there is no account to protect, so
+// anyone who can reach the web UI may log in; once it runs, replacing the
+// token is gated like the debug logs.
+// GET|POST /api/auth/device -> {"state": "pending", "user_code": "ABCD1234", "verification_uri": "...", "expires_at": "...", "waiting": true, "running": false}
+func (s *Server) handleDeviceLogin(w http.ResponseWriter, r *http.Request) {
+	if s.farmer.Started() && !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, s.farmer.DeviceLoginStatus())
+	case http.MethodPost:
+		st, err := s.farmer.StartDeviceLogin()
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadGateway, ErrCodeUpstream, err.Error())
+			return
+		}
+		jsonResponse(w, st)
+	default:
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+	}
+}
@@ -5,13 +5,18 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/fs"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/config"
 	"github.com/miwi/twitchpoint/internal/drops"
 	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
 )
 
 const maxJSONBodyBytes = 16 * 1024
@@ -28,6 +33,16 @@ type Server struct {
 	bind   string // host portion (default 127.0.0.1, configurable via web_bind)
 	port   int
 	mux    *http.ServeMux
+	boot   bootSettings // restart-only settings as they were at startup
+}
+
+// bootSettings holds the settings that only take effect on a restart,
+// captured when the server is created so /api/settings can tell which
+// saved values aren't live yet.
+type bootSettings struct {
+	WebEnabled     bool
+	Transport      string
+	NetworkProfile string
 }
 
 // New creates a new web server. The bind host comes from
@@ -38,11 +53,17 @@ func New(f *farmer.Farmer, port int) *Server {
 	if strings.TrimSpace(bind) == "" {
 		bind = "127.0.0.1"
 	}
+	cfg := f.Config()
 	s := &Server{
 		farmer: f,
 		bind:   bind,
 		port:   port,
 		mux:    http.NewServeMux(),
+		boot: bootSettings{
+			WebEnabled:     cfg.GetWebEnabled(),
+			Transport:      cfg.GetTransport(),
+			NetworkProfile: cfg.GetNetworkProfile(),
+		},
 	}
 	s.setupRoutes()
 	return s
@@ -57,16 +78,50 @@ func (s *Server) Addr() string {
 func (s *Server) setupRoutes() {
 	// API routes
 	s.mux.HandleFunc("/api/stats", s.handleStats)
+	s.mux.HandleFunc("/api/stats/compare", s.handleCompare)
+	s.mux.HandleFunc("/api/stats/breakdown", s.handleBreakdown)
+	s.mux.HandleFunc("/api/metrics", s.handleMetrics)
 	s.mux.HandleFunc("/api/channels", s.handleChannels)
 	s.mux.HandleFunc("/api/channels/", s.handleChannel)
+	s.mux.HandleFunc("/api/follows/import", s.handleImportFollows)
 	s.mux.HandleFunc("/api/logs", s.handleLogs)
+	s.mux.HandleFunc("/api/logs/", s.handleLogFiles)
+	s.mux.HandleFunc("/api/redemptions", s.handleRedemptions)
+	s.mux.HandleFunc("/api/activity", s.handleActivity)
+	s.mux.HandleFunc("/api/audit", s.handleAudit)
+	s.mux.HandleFunc("/api/failures", s.handleFailures)
+	s.mux.HandleFunc("/api/account", s.handleAccount)
+	s.mux.HandleFunc("/api/replay", s.handleReplay)
+	s.mux.HandleFunc("/api/history", s.handleHistory)
+	s.mux.HandleFunc("/api/events", s.handleEvents)
 	s.mux.HandleFunc("/api/drops", s.handleDrops)
 	s.mux.HandleFunc("/api/drops/", s.handleDropAction)
+	s.mux.HandleFunc("/api/drops/plan", s.handleDropPlan)
+	s.mux.HandleFunc("/api/drops/history", s.handleDropHistory)
+	s.mux.HandleFunc("/api/drops/check", s.handleDropCheck)
+	s.mux.HandleFunc("/api/rotate", s.handleRotate)
+	s.mux.HandleFunc("/api/pause", s.handlePause)
+	s.mux.HandleFunc("/api/resume", s.handlePause)
+	s.mux.HandleFunc("/api/campaigns/available", s.handleAvailableCampaigns)
 	s.mux.HandleFunc("/api/wanted_games", s.handleWantedGames)
+	s.mux.HandleFunc("/api/blacklist", s.handleBlacklist)
 	s.mux.HandleFunc("/api/games/search", s.handleGamesSearch)
 	s.mux.HandleFunc("/api/settings", s.handleSettings)
+	s.mux.HandleFunc("/api/subsystems", s.handleSubsystems)
+	s.mux.HandleFunc("/api/tui", s.handleTUI)
+	s.mux.HandleFunc("/api/auth/device", s.handleDeviceLogin)
+	s.mux.HandleFunc("/api/health", s.handleHealth)
+	s.mux.HandleFunc("/healthz", s.handleHealthz)
+	s.mux.HandleFunc("/api/restart", s.handleRestart)
+	s.mux.HandleFunc("/api/errors", s.handleErrorCatalog)
 
-	// Static files (embedded)
+	// Static files (embedded), unless the dashboard is switched off
+	if s.farmer.Config().GetWebAPIOnly() {
+		s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+			s.jsonError(w, r, http.StatusNotFound, ErrCodeNotFound, "not found: web_api_only is set, only /api/ and /healthz are served")
+		})
+		return
+	}
 	staticFS, _ := fs.Sub(staticFiles, "static")
 	s.mux.Handle("/", http.FileServer(http.FS(staticFS)))
 }
@@ -78,7 +133,7 @@ func (s *Server) setupRoutes() {
 func (s *Server) Start() error {
 	srv := &http.Server{
 		Addr:    s.Addr(),
-		Handler: s.mux,
+		Handler: s.handler(),
 		// Slowloris protection: cap how long the server waits for
 		// the request line + headers. Far above any reasonable
 		// browser/curl, well below "wedged forever".
@@ -92,17 +147,34 @@ func (s *Server) Start() error {
 	return srv.ListenAndServe()
 }
 
-// jsonResponse writes a JSON response with proper headers.
+// jsonResponse writes a JSON response with proper headers, with its
+// timestamps in UTC when the request asked for that (utcWriter).
 func jsonResponse(w http.ResponseWriter, data interface{}) {
+	if _, ok := w.(utcWriter); ok {
+		data = inUTC(data)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
 }
 
-// jsonError writes a JSON error response.
-func jsonError(w http.ResponseWriter, message string, code int) {
+// jsonError writes a JSON error response (an APIError) with the given
+// error code, message as its detail, and the code's message in the
+// request's language.
+func (s *Server) jsonError(w http.ResponseWriter, r *http.Request, status int, code, message string) {
+	s.writeAPIError(w, r, status, code, message)
+}
+
+// jsonCodeError is jsonError for a failure the code says all about.
+func (s *Server) jsonCodeError(w http.ResponseWriter, r *http.Request, status int, code string) {
+	s.writeAPIError(w, r, status, code, "")
+}
+
+func (s *Server) writeAPIError(w http.ResponseWriter, r *http.Request, status int, code, detail string) {
+	lang := s.requestLanguage(r)
+	w.Header().Set("Content-Language", lang)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(code)
-	json.NewEncoder(w).Encode(map[string]string{"error": message})
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(newAPIError(code, detail, lang))
 }
 
 func decodeJSONBody(w http.ResponseWriter, r *http.Request, dst interface{}) error {
@@ -117,11 +189,30 @@ type StatsResponse struct {
 	UserID           string `json:"user_id"`
 	Uptime           string `json:"uptime"`
 	TotalPoints      int    `json:"total_points"`
+	TotalSpent       int    `json:"total_spent"`
+	PointsPerHour    int    `json:"points_per_hour"`
 	TotalClaims      int    `json:"total_claims"`
+	TotalMoments     int    `json:"total_moments"`
 	ChannelsOnline   int    `json:"channels_online"`
 	ChannelsWatching int    `json:"channels_watching"`
 	ChannelsTotal    int    `json:"channels_total"`
 	ActiveDrops      int    `json:"active_drops"`
+	Paused           bool   `json:"paused"` // all farming paused (POST /api/pause)
+
+	// Seconds until the next points rotation / drops inventory check;
+	// -1 when not scheduled (drops disabled, loop not started yet).
+	NextRotationIn  int `json:"next_rotation_in"`
+	NextDropCheckIn int `json:"next_drop_check_in"`
+
+	// Channel capacity (practical limit before events start dropping)
+	ChannelCapacity   int    `json:"channel_capacity"`
+	CapacityFactor    string `json:"capacity_factor"`
+	CapacityReason    string `json:"capacity_reason"`
+	CapacityNearLimit bool   `json:"capacity_near_limit"`
+	CapacityOverLimit bool   `json:"capacity_over_limit"`
+
+	// Why the debug log is memory-only; empty while it's written to disk
+	LogFileError string `json:"log_file_error,omitempty"`
 
 	// Update notification
 	HasStableUpdate bool   `json:"has_stable_update"`
@@ -135,7 +226,7 @@ type StatsResponse struct {
 
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
@@ -149,11 +240,26 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 		UserID:           user.ID,
 		Uptime:           formatDuration(stats.Uptime),
 		TotalPoints:      stats.TotalPointsEarned,
+		TotalSpent:       stats.TotalPointsSpent,
+		PointsPerHour:    stats.PointsPerHour,
 		TotalClaims:      stats.TotalClaimsMade,
+		TotalMoments:     stats.TotalMoments,
 		ChannelsOnline:   stats.ChannelsOnline,
 		ChannelsWatching: stats.ChannelsWatching,
 		ChannelsTotal:    stats.ChannelsTotal,
 		ActiveDrops:      stats.ActiveDrops,
+		Paused:           stats.Paused,
+
+		NextRotationIn:  secondsUntil(stats.NextRotation),
+		NextDropCheckIn: secondsUntil(stats.NextDropCheck),
+
+		ChannelCapacity:   stats.Capacity.Max,
+		CapacityFactor:    stats.Capacity.LimitingFactor,
+		CapacityReason:    stats.Capacity.Reason,
+		CapacityNearLimit: stats.Capacity.NearLimit,
+		CapacityOverLimit: stats.Capacity.OverLimit,
+
+		LogFileError: stats.LogFileError,
 
 		HasStableUpdate: update.HasStableUpdate,
 		HasBetaUpdate:   update.HasBetaUpdate,
@@ -167,50 +273,209 @@ func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, resp)
 }
 
+// secondsUntil returns the whole seconds until t, 0 once it has passed
+// and -1 for the zero time.
+func secondsUntil(t time.Time) int {
+	if t.IsZero() {
+		return -1
+	}
+	if d := time.Until(t); d > 0 {
+		return int(d.Round(time.Second) / time.Second)
+	}
+	return 0
+}
+
+// handleRotate runs the points rotation now.
+// POST /api/rotate
+func (s *Server) handleRotate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	s.farmer.RotateNow()
+	jsonResponse(w, map[string]interface{}{"status": "ok"})
+}
+
+// handlePause pauses or resumes all farming (see farmer.Pause).
+// POST /api/pause, POST /api/resume -> {"paused": bool}
+func (s *Server) handlePause(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if r.URL.Path == "/api/resume" {
+		s.farmer.Resume()
+	} else {
+		s.farmer.Pause()
+	}
+	jsonResponse(w, map[string]bool{"paused": s.farmer.IsPaused()})
+}
+
+// handleDropCheck runs the drops inventory check now.
+// POST /api/drops/check
+func (s *Server) handleDropCheck(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if err := s.farmer.CheckDropsNow(); err != nil {
+		s.jsonError(w, r, http.StatusConflict, ErrCodeConflict, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"status": "ok"})
+}
+
 // ChannelResponse is a channel in the /api/channels response.
 type ChannelResponse struct {
-	Login         string `json:"login"`
-	DisplayName   string `json:"display_name"`
-	ChannelID     string `json:"channel_id"`
-	Priority      int    `json:"priority"`
-	IsOnline      bool   `json:"is_online"`
-	IsWatching    bool   `json:"is_watching"`
-	GameName      string `json:"game_name"`
-	ViewerCount   int    `json:"viewer_count"`
-	Balance       int    `json:"balance"`
-	Earned        int    `json:"earned"`
-	Claims        int    `json:"claims"`
-	HasActiveDrop bool   `json:"has_active_drop"`
-	DropName      string `json:"drop_name,omitempty"`
-	DropProgress  int    `json:"drop_progress"`
-	DropRequired  int    `json:"drop_required"`
-	IsTemporary   bool   `json:"is_temporary"`
+	Login          string `json:"login"`
+	DisplayName    string `json:"display_name"`
+	ChannelID      string `json:"channel_id"`
+	Priority       int    `json:"priority"`
+	IsOnline       bool   `json:"is_online"`
+	IsWatching     bool   `json:"is_watching"`
+	GameName       string `json:"game_name"`
+	ViewerCount    int    `json:"viewer_count"`
+	Balance        int    `json:"balance"`
+	Earned         int    `json:"earned"`
+	Spent          int    `json:"spent"`
+	PointsPerHour  int    `json:"points_per_hour"`
+	Claims         int    `json:"claims"`
+	HasActiveDrop  bool   `json:"has_active_drop"`
+	DropName       string `json:"drop_name,omitempty"`
+	DropProgress   int    `json:"drop_progress"`
+	DropRequired   int    `json:"drop_required"`
+	IsTemporary    bool   `json:"is_temporary"`
+	MomentsEnabled bool   `json:"moments_enabled"`
+	HypeTrainLevel int    `json:"hype_train_level"`     // 0 when no train is running
+	Goal           int    `json:"goal,omitempty"`       // target balance; 0 = none
+	MaxPoints      int    `json:"max_points,omitempty"` // balance cap; 0 = none
+	Mode           string `json:"mode,omitempty"`       // "points" or "drops"; empty = both
+
+	// Spade heartbeats of a watched channel: when Twitch last accepted
+	// one, how many failed in a row since, and whether the channel is
+	// out of rotation for failing them.
+	HeartbeatOKAt     *time.Time `json:"heartbeat_ok_at,omitempty"`
+	HeartbeatFailures int        `json:"heartbeat_failures"`
+	HeartbeatBenched  bool       `json:"heartbeat_benched"`
+
+	// Inactive says why a live channel looks like a ghost stream
+	// (min_viewers, stale_viewers_minutes) and gets no points slot.
+	Inactive string `json:"inactive,omitempty"`
+
+	// IsRerun marks a stream Twitch reports as a rerun, or
+	// tagged Rerun; rerun_mode decides what the rotation does with it.
+	IsRerun bool `json:"is_rerun"`
+
+	// PointsMultiplier is what watching the channel earns relative to no
+	// bonuses (1.2 with a tier 1 sub) and SubTier the user's
+	// subscription tier there, 0 when not subscribed. Both come with the
+	// balance refresh; the multiplier is 0 until the first one.
+	PointsMultiplier float64 `json:"points_multiplier"`
+	SubTier          int     `json:"sub_tier"`
+
+	// PubSubWarning names a PubSub topic of the channel that keeps
+	// failing to subscribe, and why.
+	PubSubWarning string `json:"pubsub_warning,omitempty"`
+
+	// Which plumbing is active for the channel: its PubSub topics
+	// confirmed (or its stream status on EventSub), IRC joined, Spade
+	// heartbeats running and when one was last sent.
+	PubSubSubscribed  bool       `json:"pubsub_subscribed"`
+	EventSub          bool       `json:"eventsub,omitempty"`
+	IRCJoined         bool       `json:"irc_joined"`
+	SpadeHeartbeating bool       `json:"spade_heartbeating"`
+	LastHeartbeatAt   *time.Time `json:"last_heartbeat_at,omitempty"`
+}
+
+// channelResponse projects a channel snapshot into the API shape. Shared
+// by /api/channels and the /api/events channel diffs.
+func (s *Server) channelResponse(ch channels.Snapshot) ChannelResponse {
+	resp := ChannelResponse{
+		Login:          ch.Login,
+		DisplayName:    ch.DisplayName,
+		ChannelID:      ch.ChannelID,
+		Priority:       ch.Priority,
+		IsOnline:       ch.IsOnline,
+		IsWatching:     ch.IsWatching,
+		GameName:       ch.GameName,
+		ViewerCount:    ch.ViewerCount,
+		Balance:        ch.PointsBalance,
+		Earned:         ch.PointsEarnedSession,
+		Spent:          ch.PointsSpentSession,
+		PointsPerHour:  ch.PointsPerHour,
+		Claims:         ch.ClaimsMade,
+		HasActiveDrop:  ch.HasActiveDrop,
+		DropName:       ch.DropName,
+		DropProgress:   ch.DropProgress,
+		DropRequired:   ch.DropRequired,
+		IsTemporary:    ch.IsTemporary,
+		MomentsEnabled: s.farmer.Config().IsMomentsEnabled(ch.Login),
+		HypeTrainLevel: hypeTrainLevel(ch),
+		Goal:           s.farmer.Config().GetPointsGoal(ch.Login),
+		MaxPoints:      s.farmer.Config().GetMaxPoints(ch.Login),
+		Mode:           s.farmer.Config().GetChannelMode(ch.Login),
+
+		HeartbeatFailures: ch.HeartbeatFailures,
+		HeartbeatBenched:  ch.HeartbeatBenched(time.Now()),
+
+		Inactive:      points.InactiveStream(s.farmer.Config(), ch, time.Now()),
+		IsRerun:       ch.IsRerun,
+		PubSubWarning: ch.PubSubWarning,
+
+		PointsMultiplier: ch.PointsMultiplier,
+		SubTier:          ch.SubTier,
+	}
+	if !ch.HeartbeatOKAt.IsZero() {
+		resp.HeartbeatOKAt = &ch.HeartbeatOKAt
+	}
+	conn := s.farmer.ChannelConnections(ch.ChannelID, ch.Login)
+	resp.PubSubSubscribed, resp.EventSub = conn.PubSub, conn.EventSub
+	resp.IRCJoined, resp.SpadeHeartbeating = conn.IRC, conn.Spade
+	if !conn.LastHeartbeat.IsZero() {
+		resp.LastHeartbeatAt = &conn.LastHeartbeat
+	}
+	return resp
+}
+
+// hypeTrainLevel returns the running Hype Train's level, or 0.
+func hypeTrainLevel(ch channels.Snapshot) int {
+	if !ch.InHypeTrain(time.Now()) {
+		return 0
+	}
+	return ch.HypeTrainLevel
+}
+
+// filterTemporary applies the ?temporary= filter of GET /api/channels:
+// "true" keeps only the temporary drop channels, "false" only the
+// configured ones, and "" keeps all.
+func filterTemporary(chs []channels.Snapshot, v string) ([]channels.Snapshot, error) {
+	if v == "" {
+		return chs, nil
+	}
+	temporary, err := strconv.ParseBool(v)
+	if err != nil {
+		return nil, err
+	}
+	var out []channels.Snapshot
+	for _, ch := range chs {
+		if ch.IsTemporary == temporary {
+			out = append(out, ch)
+		}
+	}
+	return out, nil
 }
 
 func (s *Server) handleChannels(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodGet:
-		channels := s.farmer.GetChannels()
-		resp := make([]ChannelResponse, len(channels))
-		for i, ch := range channels {
-			resp[i] = ChannelResponse{
-				Login:         ch.Login,
-				DisplayName:   ch.DisplayName,
-				ChannelID:     ch.ChannelID,
-				Priority:      ch.Priority,
-				IsOnline:      ch.IsOnline,
-				IsWatching:    ch.IsWatching,
-				GameName:      ch.GameName,
-				ViewerCount:   ch.ViewerCount,
-				Balance:       ch.PointsBalance,
-				Earned:        ch.PointsEarnedSession,
-				Claims:        ch.ClaimsMade,
-				HasActiveDrop: ch.HasActiveDrop,
-				DropName:      ch.DropName,
-				DropProgress:  ch.DropProgress,
-				DropRequired:  ch.DropRequired,
-				IsTemporary:   ch.IsTemporary,
-			}
+		chs, err := filterTemporary(s.farmer.GetChannels(), r.URL.Query().Get("temporary"))
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "temporary must be true or false")
+			return
+		}
+		resp := []ChannelResponse{}
+		for _, ch := range chs {
+			resp = append(resp, s.channelResponse(ch))
 		}
 		jsonResponse(w, resp)
 
@@ -219,21 +484,34 @@ func (s *Server) handleChannels(w http.ResponseWriter, r *http.Request) {
 			Login string `json:"login"`
 		}
 		if err := decodeJSONBody(w, r, &req); err != nil {
-			jsonError(w, "invalid request body", http.StatusBadRequest)
+			s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
 			return
 		}
 		if req.Login == "" {
-			jsonError(w, "login is required", http.StatusBadRequest)
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeMissingParameter, "login is required")
+			return
+		}
+		// login may also be a twitch.tv URL or a channel ID.
+		login, _, err := twitch.ParseChannelRef(req.Login)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
 			return
 		}
 		if err := s.farmer.AddChannelLive(req.Login); err != nil {
-			jsonError(w, err.Error(), http.StatusConflict)
+			s.jsonError(w, r, http.StatusConflict, ErrCodeConflict, err.Error())
 			return
 		}
-		jsonResponse(w, map[string]string{"status": "ok", "login": req.Login})
+		if login == "" {
+			login = req.Login
+		}
+		resp := map[string]string{"status": "ok", "login": login}
+		if warning := s.farmer.CapacityWarning(); warning != "" {
+			resp["warning"] = warning
+		}
+		jsonResponse(w, resp)
 
 	default:
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 	}
 }
 
@@ -242,7 +520,7 @@ func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request) {
 	path := strings.TrimPrefix(r.URL.Path, "/api/channels/")
 	parts := strings.Split(path, "/")
 	if len(parts) == 0 || parts[0] == "" {
-		jsonError(w, "channel login required", http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeMissingParameter, "channel login required")
 		return
 	}
 	login := parts[0]
@@ -253,22 +531,58 @@ func (s *Server) handleChannel(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Check for /moments suffix
+	if len(parts) >= 2 && parts[1] == "moments" {
+		s.handleChannelMoments(w, r, login)
+		return
+	}
+
+	// Check for /goal suffix
+	if len(parts) >= 2 && parts[1] == "goal" {
+		s.handleChannelGoal(w, r, login)
+		return
+	}
+
+	// Check for /mode suffix
+	if len(parts) >= 2 && parts[1] == "mode" {
+		s.handleChannelMode(w, r, login)
+		return
+	}
+
+	// Check for /settings suffix
+	if len(parts) >= 2 && parts[1] == "settings" {
+		s.handleChannelSettings(w, r, login)
+		return
+	}
+
+	// Check for /chart suffix
+	if len(parts) >= 2 && parts[1] == "chart" {
+		s.handleChannelChart(w, r, login)
+		return
+	}
+
+	// Quick actions: /paused, /watch, /refresh, /promote
+	if len(parts) >= 2 && (parts[1] == "paused" || parts[1] == "watch" || parts[1] == "refresh" || parts[1] == "promote") {
+		s.handleChannelAction(w, r, login, parts[1])
+		return
+	}
+
 	switch r.Method {
 	case http.MethodDelete:
 		if err := s.farmer.RemoveChannelLive(login); err != nil {
-			jsonError(w, err.Error(), http.StatusBadRequest)
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
 			return
 		}
 		jsonResponse(w, map[string]string{"status": "ok", "login": login})
 
 	default:
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 	}
 }
 
 func (s *Server) handleChannelPriority(w http.ResponseWriter, r *http.Request, login string) {
 	if r.Method != http.MethodPut {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
@@ -276,46 +590,372 @@ func (s *Server) handleChannelPriority(w http.ResponseWriter, r *http.Request, l
 		Priority int `json:"priority"`
 	}
 	if err := decodeJSONBody(w, r, &req); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
 		return
 	}
 	if req.Priority != 1 && req.Priority != 2 {
-		jsonError(w, "priority must be 1 or 2", http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "priority must be 1 or 2")
 		return
 	}
 
 	if err := s.farmer.SetPriorityLive(login, req.Priority); err != nil {
-		jsonError(w, err.Error(), http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
 		return
 	}
 	jsonResponse(w, map[string]string{"status": "ok", "login": login, "priority": fmt.Sprintf("%d", req.Priority)})
 }
 
+// handleChannelMoments toggles Moments auto-claim for one channel.
+// PUT /api/channels/{login}/moments -> body: {"enabled": false}
+func (s *Server) handleChannelMoments(w http.ResponseWriter, r *http.Request, login string) {
+	if r.Method != http.MethodPut {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+		return
+	}
+
+	if err := s.farmer.SetMomentsEnabledLive(login, req.Enabled); err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"status": "ok", "login": login, "enabled": req.Enabled})
+}
+
+// handleChannelGoal sets or clears a channel's points goal.
+// PUT /api/channels/{login}/goal -> body: {"goal": 50000} (0 clears)
+func (s *Server) handleChannelGoal(w http.ResponseWriter, r *http.Request, login string) {
+	if r.Method != http.MethodPut {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Goal int `json:"goal"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+		return
+	}
+
+	if err := s.farmer.SetPointsGoalLive(login, req.Goal); err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"status": "ok", "login": login, "goal": req.Goal})
+}
+
+// handleChannelMode restricts a channel to points or drops farming.
+// PUT /api/channels/{login}/mode -> body: {"mode": "points"} ("both",
+// "points" or "drops")
+func (s *Server) handleChannelMode(w http.ResponseWriter, r *http.Request, login string) {
+	if r.Method != http.MethodPut {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+		return
+	}
+
+	if err := s.farmer.SetChannelModeLive(login, req.Mode); err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]string{"status": "ok", "login": login, "mode": s.farmer.Config().GetChannelMode(login)})
+}
+
+// handleChannelSettings reads and edits all per-channel settings at
+// once (farmer.ChannelSettings):
+//
+//	GET    /api/channels/{login}/settings
+//	PUT    /api/channels/{login}/settings -> body: any subset, e.g. {"goal": 5000, "irc": false}
+//	DELETE /api/channels/{login}/settings (back to the defaults)
+//
+// Every method answers with the resulting settings.
+func (s *Server) handleChannelSettings(w http.ResponseWriter, r *http.Request, login string) {
+	cur, err := s.farmer.GetChannelSettings(login)
+	if err != nil {
+		s.jsonError(w, r, http.StatusNotFound, ErrCodeNotFound, err.Error())
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, cur)
+		return
+	case http.MethodPut:
+		// Decoding over the current settings leaves omitted fields as
+		// they are.
+		if err := decodeJSONBody(w, r, &cur); err != nil {
+			s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+			return
+		}
+	case http.MethodDelete:
+		cur = farmer.DefaultChannelSettings()
+	default:
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	if err := s.farmer.SetChannelSettingsLive(login, cur); err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+	updated, err := s.farmer.GetChannelSettings(login)
+	if err != nil {
+		s.jsonError(w, r, http.StatusNotFound, ErrCodeNotFound, err.Error())
+		return
+	}
+	jsonResponse(w, updated)
+}
+
+// handleImportFollows adds the user's followed channels.
+// POST /api/follows/import -> body: {"live_only": true, "min_age_days": 30}
+// (both optional; an empty body imports every follow)
+func (s *Server) handleImportFollows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		LiveOnly   bool `json:"live_only"`
+		MinAgeDays int  `json:"min_age_days"`
+	}
+	if r.ContentLength != 0 {
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+			return
+		}
+	}
+	if req.MinAgeDays < 0 {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "min_age_days must not be negative")
+		return
+	}
+
+	added, err := s.farmer.ImportFollows(req.LiveOnly, time.Duration(req.MinAgeDays)*24*time.Hour)
+	if err != nil {
+		s.jsonError(w, r, http.StatusBadGateway, ErrCodeUpstream, err.Error())
+		return
+	}
+	if added == nil {
+		added = []string{}
+	}
+	resp := map[string]interface{}{"added": added}
+	if warn := s.farmer.CapacityWarning(); warn != "" {
+		resp["warning"] = warn
+	}
+	jsonResponse(w, resp)
+}
+
+// handleChannelAction serves the TUI's channel quick actions:
+// PUT /paused {"paused": bool}, POST /watch (force-watch for one
+// rotation) and POST /refresh (re-fetch balance and stream info).
+func (s *Server) handleChannelAction(w http.ResponseWriter, r *http.Request, login, action string) {
+	var err error
+	switch {
+	case action == "paused" && r.Method == http.MethodPut:
+		var req struct {
+			Paused bool `json:"paused"`
+		}
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+			return
+		}
+		err = s.farmer.SetPausedLive(login, req.Paused)
+	case action == "watch" && r.Method == http.MethodPost:
+		err = s.farmer.ForceWatchLive(login)
+	case action == "refresh" && r.Method == http.MethodPost:
+		err = s.farmer.RefreshChannelLive(login)
+	case action == "promote" && r.Method == http.MethodPost:
+		err = s.farmer.PromoteChannel(login)
+	default:
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidRequest, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]string{"status": "ok", "login": login})
+}
+
 // LogResponse is a log entry in the /api/logs response.
 type LogResponse struct {
-	Time    string `json:"time"`
-	Message string `json:"message"`
+	Time      string `json:"time"`
+	Level     string `json:"level"` // "debug", "info", "warn" or "error"
+	Subsystem string `json:"subsystem,omitempty"`
+	Message   string `json:"message"`
+}
+
+func logResponse(e farmer.LogEntry) LogResponse {
+	return LogResponse{
+		Time:      e.Time.Format("15:04:05"),
+		Level:     strings.ToLower(e.Level.String()),
+		Subsystem: e.Subsystem,
+		Message:   e.Message,
+	}
 }
 
+// handleLogs returns the last 50 entries of the UI feed, newest first.
+// GET /api/logs[?level=warn][&subsystem=Drops] -> [{"time", "level", "subsystem", "message"}]
+// level keeps entries at or above it; subsystem matches the "[Drops]"
+// prefix case-insensitively, including sub-prefixes like "[Drops/Watch]".
 func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
+	minLevel := slog.LevelDebug
+	if l := r.URL.Query().Get("level"); l != "" {
+		if err := minLevel.UnmarshalText([]byte(l)); err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "level must be debug, info, warn or error")
+			return
+		}
+	}
+	sub := strings.ToLower(r.URL.Query().Get("subsystem"))
+
 	logs := s.farmer.GetLogs()
+	resp := make([]LogResponse, 0, 50)
+	for i := len(logs) - 1; i >= 0 && len(resp) < 50; i-- {
+		e := logs[i]
+		if e.Level < minLevel {
+			continue
+		}
+		if name := strings.ToLower(e.Subsystem); sub != "" && name != sub && !strings.HasPrefix(name, sub+"/") {
+			continue
+		}
+		resp = append(resp, logResponse(e))
+	}
 
-	// Return last 50 entries (newest first)
-	start := 0
-	if len(logs) > 50 {
-		start = len(logs) - 50
+	jsonResponse(w, resp)
+}
+
+// MetricsResponse is the /api/metrics response: internals for spotting
+// performance regressions, as opposed to the user-facing /api/stats.
+type MetricsResponse struct {
+	EventLoop farmer.EventLoopMetrics `json:"event_loop"`
+}
+
+// handleMetrics reports the farmer's event loop throughput and latency.
+// GET /api/metrics -> {"event_loop": {"handled", "queue_depth", "wait_p95_ms", "handle_p95_ms", ...}}
+func (s *Server) handleMetrics(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
 	}
+	jsonResponse(w, MetricsResponse{EventLoop: s.farmer.EventLoopMetrics()})
+}
 
-	resp := make([]LogResponse, 0, 50)
-	for i := len(logs) - 1; i >= start; i-- {
-		resp = append(resp, LogResponse{
-			Time:    logs[i].Time.Format("15:04:05"),
-			Message: logs[i].Message,
+// handleFailures returns the newest failed claims, newest first, with
+// the sanitized GQL exchange of each, for attaching to bug reports.
+// Gated like the debug log downloads.
+// GET /api/failures[?limit=20] -> {"failures": [{"id", "operation", "error", "request", "response", ...}]}
+func (s *Server) handleFailures(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	limit := 20
+	if l := r.URL.Query().Get("limit"); l != "" {
+		n, err := strconv.Atoi(l)
+		if err != nil || n < 1 {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "limit must be a positive number")
+			return
+		}
+		limit = n
+	}
+	failures, err := s.farmer.RecentClaimFailures(limit)
+	if err != nil {
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"failures": failures})
+}
+
+// handleAccount reports the account health signals (token, GQL error
+// classes, claim rejection rates, watch-to-points conversion) with a
+// verdict. Gated like the debug logs: the token's scopes and the recent
+// errors are more than the dashboard shows.
+// GET /api/account -> {"status": "ok", "findings": [...], "token": {...}, "gql": {...}, "claims": [...], "watch": {...}}
+func (s *Server) handleAccount(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	jsonResponse(w, s.farmer.AccountHealth())
+}
+
+// maxReplayBody caps a POST /api/replay capture.
+const maxReplayBody = 64 << 20
+
+// handleReplay dry-runs a PubSub capture (pubsub_record_file) against the
+// farmer: the body is the capture file itself, and the response lists
+// what the event handling would do, without doing any of it.
+// POST /api/replay -> {"actions": [{"event": "claim_available", ...}]}
+func (s *Server) handleReplay(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	if !s.authorized(r) {
+		s.jsonCodeError(w, r, http.StatusUnauthorized, ErrCodeUnauthorized)
+		return
+	}
+	actions, err := s.farmer.ReplayPubSub(http.MaxBytesReader(w, r.Body, maxReplayBody))
+	if err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, err.Error())
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"actions": actions})
+}
+
+// RedemptionResponse is one auto-redeem attempt.
+type RedemptionResponse struct {
+	Time    time.Time `json:"time"`
+	Channel string    `json:"channel"`
+	Reward  string    `json:"reward"`
+	Cost    int       `json:"cost"`
+	Error   string    `json:"error,omitempty"`
+}
+
+func (s *Server) handleRedemptions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	log := s.farmer.GetRedemptions()
+
+	// Newest first, like /api/logs.
+	resp := make([]RedemptionResponse, 0, len(log))
+	for i := len(log) - 1; i >= 0; i-- {
+		resp = append(resp, RedemptionResponse{
+			Time:    log[i].At.Truncate(time.Second),
+			Channel: log[i].Channel,
+			Reward:  log[i].Reward,
+			Cost:    log[i].Cost,
+			Error:   log[i].Error,
 		})
 	}
 
@@ -324,7 +964,7 @@ func (s *Server) handleLogs(w http.ResponseWriter, r *http.Request) {
 
 func (s *Server) handleDrops(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
@@ -335,12 +975,22 @@ func (s *Server) handleDrops(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, rows)
 }
 
+// handleDropPlan serves the watch planner: per campaign, the remaining
+// watch time, slack until it ends and a warning when it can't finish.
+func (s *Server) handleDropPlan(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	jsonResponse(w, s.farmer.GetDropPlan())
+}
+
 func (s *Server) handleDropAction(w http.ResponseWriter, r *http.Request) {
 	// /api/drops/{campaignID}/{action}
 	path := strings.TrimPrefix(r.URL.Path, "/api/drops/")
 	parts := strings.SplitN(path, "/", 2)
 	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
-		jsonError(w, "invalid path, expected /api/drops/{campaignID}/{action}", http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "invalid path, expected /api/drops/{campaignID}/{action}")
 		return
 	}
 	campaignID, action := parts[0], parts[1]
@@ -350,14 +1000,18 @@ func (s *Server) handleDropAction(w http.ResponseWriter, r *http.Request) {
 		s.handleCampaignToggle(w, r, campaignID)
 	case "pin":
 		s.handleCampaignPin(w, r, campaignID)
+	case "optin":
+		s.handleCampaignOptIn(w, r, campaignID)
+	case "autoselect":
+		s.handleCampaignAutoSelect(w, r, campaignID)
 	default:
-		jsonError(w, "unknown action: "+action, http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "unknown action: "+action)
 	}
 }
 
 func (s *Server) handleCampaignToggle(w http.ResponseWriter, r *http.Request, campaignID string) {
 	if r.Method != http.MethodPut {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
@@ -365,21 +1019,83 @@ func (s *Server) handleCampaignToggle(w http.ResponseWriter, r *http.Request, ca
 		Enabled bool `json:"enabled"`
 	}
 	if err := decodeJSONBody(w, r, &req); err != nil {
-		jsonError(w, "invalid request body", http.StatusBadRequest)
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
 		return
 	}
 
 	if err := s.farmer.SetCampaignEnabled(campaignID, req.Enabled); err != nil {
-		jsonError(w, err.Error(), http.StatusInternalServerError)
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
 		return
 	}
 
 	jsonResponse(w, map[string]interface{}{"status": "ok", "campaign_id": campaignID, "enabled": req.Enabled})
 }
 
+// handleCampaignOptIn enables a campaign from the campaign browser.
+// PUT /api/drops/{campaignID}/optin -> body: {"enabled": true}
+func (s *Server) handleCampaignOptIn(w http.ResponseWriter, r *http.Request, campaignID string) {
+	if r.Method != http.MethodPut {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Enabled bool `json:"enabled"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+		return
+	}
+
+	if err := s.farmer.SetCampaignOptIn(campaignID, req.Enabled); err != nil {
+		s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{"status": "ok", "campaign_id": campaignID, "enabled": req.Enabled})
+}
+
+// handleCampaignAutoSelect sets a campaign's auto-select override.
+// PUT /api/drops/{campaignID}/autoselect -> body: {"mode": "off"} ("" clears)
+func (s *Server) handleCampaignAutoSelect(w http.ResponseWriter, r *http.Request, campaignID string) {
+	if r.Method != http.MethodPut {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+
+	var req struct {
+		Mode string `json:"mode"`
+	}
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		s.jsonCodeError(w, r, http.StatusBadRequest, ErrCodeInvalidBody)
+		return
+	}
+
+	if err := s.farmer.SetCampaignAutoSelect(campaignID, req.Mode); err != nil {
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+		return
+	}
+
+	jsonResponse(w, map[string]interface{}{
+		"status":      "ok",
+		"campaign_id": campaignID,
+		"mode":        s.farmer.Config().GetCampaignAutoSelectOverride(campaignID),
+	})
+}
+
+// handleAvailableCampaigns lists campaigns without prior progress.
+// GET /api/campaigns/available -> [{"campaign_id": ..., "is_opted_in": false, ...}]
+func (s *Server) handleAvailableCampaigns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	jsonResponse(w, s.farmer.GetAvailableCampaigns())
+}
+
 func (s *Server) handleCampaignPin(w http.ResponseWriter, r *http.Request, campaignID string) {
 	if r.Method != http.MethodPut {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 
@@ -387,7 +1103,7 @@ func (s *Server) handleCampaignPin(w http.ResponseWriter, r *http.Request, campa
 		Pinned bool `json:"pinned"`
 	}
 	if err := decodeJSONBody(w, r, &req); err != nil {
-		jsonError(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+		s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, "invalid JSON body: "+err.Error())
 		return
 	}
 
@@ -397,54 +1113,284 @@ func (s *Server) handleCampaignPin(w http.ResponseWriter, r *http.Request, campa
 	} else if cfg.IsCampaignPinned(campaignID) {
 		cfg.SetPinnedCampaign("")
 	}
-	if err := cfg.Save(); err != nil {
-		jsonError(w, "failed to save config: "+err.Error(), http.StatusInternalServerError)
-		return
-	}
+	cfg.SaveSoon()
 
 	jsonResponse(w, map[string]string{
 		"pinned_campaign_id": cfg.GetPinnedCampaign(),
 	})
 }
 
-// SettingsResponse is the /api/settings response. Only the runtime-live
-// flags are exposed for now — boot-time flags (drops_enabled, irc_enabled,
-// web_enabled) require a farmer restart and aren't toggleable from the
-// web UI.
+// SettingsResponse is the /api/settings response. Boot-time settings
+// (web_enabled, web_port, transport, network_profile) are saved right
+// away but only apply after a restart; RestartRequired lists the ones
+// whose saved value differs from what's running.
 type SettingsResponse struct {
-	AutoClaim bool `json:"auto_claim"`
+	AutoClaim               bool            `json:"auto_claim"`
+	DropAutoSelect          string          `json:"drop_auto_select"`
+	IrcSkipTempChannels     bool            `json:"irc_skip_temp_channels"`
+	IrcMode                 string          `json:"irc_mode"`
+	DropMinProgressPercent  int             `json:"drop_min_progress_percent"` // 0 = off
+	DropCheckMinutes        int             `json:"drop_check_minutes"`        // 0 = default (15)
+	RotationIntervalMinutes int             `json:"rotation_interval_minutes"` // 0 = default (5)
+	StreakWindowMinutes     int             `json:"streak_window_minutes"`     // 0 = default (30)
+	StreakPreservation      bool            `json:"streak_preservation"`
+	QuitToBackground        bool            `json:"quit_to_background"`
+	WebEnabled              bool            `json:"web_enabled"`
+	WebPort                 int             `json:"web_port"`
+	IrcEnabled              bool            `json:"irc_enabled"`
+	DropsEnabled            bool            `json:"drops_enabled"`
+	Transport               string          `json:"transport"`
+	NetworkProfile          string          `json:"network_profile"`
+	Schedule                config.Schedule `json:"schedule"`
+	SchedulePause           string          `json:"schedule_pause"` // why the schedule pauses farming now; "" = it doesn't
+	RestartRequired         []string        `json:"restart_required"`
 }
 
-// handleSettings serves the live, runtime-toggleable config flags.
-//
-// GET  /api/settings              -> {"auto_claim": true}
-// PUT  /api/settings              -> body: {"auto_claim": false} → 200 OK
+// settingsRequest is the PUT body; omitted fields stay unchanged.
+type settingsRequest struct {
+	AutoClaim               *bool            `json:"auto_claim"`
+	DropAutoSelect          *string          `json:"drop_auto_select"`
+	IrcSkipTempChannels     *bool            `json:"irc_skip_temp_channels"`
+	IrcMode                 *string          `json:"irc_mode"`
+	DropMinProgressPercent  *int             `json:"drop_min_progress_percent"`
+	DropCheckMinutes        *int             `json:"drop_check_minutes"`
+	RotationIntervalMinutes *int             `json:"rotation_interval_minutes"`
+	StreakWindowMinutes     *int             `json:"streak_window_minutes"`
+	StreakPreservation      *bool            `json:"streak_preservation"`
+	QuitToBackground        *bool            `json:"quit_to_background"`
+	WebEnabled              *bool            `json:"web_enabled"`
+	WebPort                 *int             `json:"web_port"`
+	IrcEnabled              *bool            `json:"irc_enabled"`
+	DropsEnabled            *bool            `json:"drops_enabled"`
+	Transport               *string          `json:"transport"`
+	NetworkProfile          *string          `json:"network_profile"`
+	Schedule                *config.Schedule `json:"schedule"` // replaced as a whole
+}
+
+func (s *Server) settingsResponse() SettingsResponse {
+	cfg := s.farmer.Config()
+	resp := SettingsResponse{
+		AutoClaim:               cfg.GetAutoClaim(),
+		DropAutoSelect:          cfg.GetDropAutoSelect(),
+		IrcSkipTempChannels:     cfg.GetIrcSkipTempChannels(),
+		IrcMode:                 cfg.GetIrcMode(),
+		DropMinProgressPercent:  cfg.GetDropMinProgressPercent(),
+		DropCheckMinutes:        cfg.GetDropCheckMinutes(),
+		RotationIntervalMinutes: cfg.GetRotationIntervalMinutes(),
+		StreakWindowMinutes:     cfg.GetStreakWindowMinutes(),
+		StreakPreservation:      cfg.GetStreakPreservation(),
+		QuitToBackground:        cfg.GetQuitToBackground(),
+		WebEnabled:              cfg.GetWebEnabled(),
+		WebPort:                 cfg.GetWebPort(),
+		IrcEnabled:              cfg.GetIrcEnabled(),
+		DropsEnabled:            cfg.GetDropsEnabled(),
+		Transport:               cfg.GetTransport(),
+		NetworkProfile:          cfg.GetNetworkProfile(),
+		Schedule:                cfg.GetSchedule(),
+		SchedulePause:           s.farmer.SchedulePause(),
+		RestartRequired:         []string{},
+	}
+	for _, c := range []struct {
+		key     string
+		pending bool
+	}{
+		{"web_enabled", resp.WebEnabled != s.boot.WebEnabled},
+		{"web_port", resp.WebPort != 0 && resp.WebPort != s.port},
+		{"transport", resp.Transport != s.boot.Transport},
+		{"network_profile", resp.NetworkProfile != s.boot.NetworkProfile},
+	} {
+		if c.pending {
+			resp.RestartRequired = append(resp.RestartRequired, c.key)
+		}
+	}
+	return resp
+}
+
+// handleSettings serves the settings page. GET returns the current
+// values; PUT applies the fields present in the body (all of them are
+// checked first) and returns the result.
 func (s *Server) handleSettings(w http.ResponseWriter, r *http.Request) {
 	cfg := s.farmer.Config()
 	switch r.Method {
 	case http.MethodGet:
-		jsonResponse(w, SettingsResponse{
-			AutoClaim: cfg.GetAutoClaim(),
-		})
+		jsonResponse(w, s.settingsResponse())
 	case http.MethodPut:
-		var req SettingsResponse
+		var req settingsRequest
 		if err := decodeJSONBody(w, r, &req); err != nil {
-			jsonError(w, "invalid JSON body: "+err.Error(), http.StatusBadRequest)
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, "invalid JSON body: "+err.Error())
 			return
 		}
-		cfg.SetAutoClaim(req.AutoClaim)
-		if err := cfg.Save(); err != nil {
-			jsonError(w, "failed to save config: "+err.Error(), http.StatusInternalServerError)
+		if err := checkSettings(req); err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
 			return
 		}
-		jsonResponse(w, SettingsResponse{
-			AutoClaim: cfg.GetAutoClaim(),
-		})
+		if req.DropAutoSelect != nil {
+			// Saves and re-runs selection itself.
+			if err := s.farmer.SetDropAutoSelect(*req.DropAutoSelect); err != nil {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, err.Error())
+				return
+			}
+		}
+		if req.IrcSkipTempChannels != nil {
+			if err := s.farmer.SetIrcSkipTempChannels(*req.IrcSkipTempChannels); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+		if req.DropMinProgressPercent != nil {
+			// Saves and re-runs selection itself.
+			if err := s.farmer.SetDropMinProgressPercent(*req.DropMinProgressPercent); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+		if req.DropCheckMinutes != nil && *req.DropCheckMinutes != cfg.GetDropCheckMinutes() {
+			// Saves and re-times the next check itself.
+			if err := s.farmer.SetDropCheckMinutes(*req.DropCheckMinutes); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+		if req.IrcMode != nil && *req.IrcMode != cfg.GetIrcMode() {
+			if err := s.farmer.SetIrcMode(*req.IrcMode); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+		if req.Schedule != nil {
+			// Saves and pauses or resumes farming itself.
+			if err := s.farmer.SetSchedule(*req.Schedule); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+		// Saved and applied right away, like POST /api/subsystems.
+		for _, sub := range []struct {
+			name string
+			v    *bool
+		}{
+			{farmer.SubsystemIRC, req.IrcEnabled},
+			{farmer.SubsystemDrops, req.DropsEnabled},
+		} {
+			if sub.v == nil || s.subsystemEnabled(sub.name) == *sub.v {
+				continue
+			}
+			if err := s.farmer.SetSubsystemEnabled(sub.name, *sub.v); err != nil {
+				s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+				return
+			}
+		}
+
+		// The rest are plain config values, saved in one go.
+		changed, rotate := false, false
+		if req.AutoClaim != nil {
+			cfg.SetAutoClaim(*req.AutoClaim)
+			changed = true
+		}
+		if req.RotationIntervalMinutes != nil {
+			if !cfg.SetRotationIntervalMinutes(*req.RotationIntervalMinutes) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, config.CheckRotationIntervalMinutes(*req.RotationIntervalMinutes).Error())
+				return
+			}
+			changed, rotate = true, true
+		}
+		if req.StreakWindowMinutes != nil {
+			if !cfg.SetStreakWindowMinutes(*req.StreakWindowMinutes) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, config.CheckStreakWindowMinutes(*req.StreakWindowMinutes).Error())
+				return
+			}
+			changed, rotate = true, true
+		}
+		if req.StreakPreservation != nil {
+			cfg.SetStreakPreservation(*req.StreakPreservation)
+			changed, rotate = true, true
+		}
+		if req.QuitToBackground != nil {
+			cfg.SetQuitToBackground(*req.QuitToBackground)
+			changed = true
+		}
+		if req.WebEnabled != nil {
+			cfg.SetWebEnabled(*req.WebEnabled)
+			changed = true
+		}
+		if req.WebPort != nil {
+			if !cfg.SetWebPort(*req.WebPort) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, config.CheckWebPort(*req.WebPort).Error())
+				return
+			}
+			changed = true
+		}
+		if req.Transport != nil {
+			if !cfg.SetTransport(*req.Transport) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, config.CheckTransport(*req.Transport).Error())
+				return
+			}
+			changed = true
+		}
+		if req.NetworkProfile != nil {
+			if !cfg.SetNetworkProfile(*req.NetworkProfile) {
+				s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, config.CheckNetworkProfile(*req.NetworkProfile).Error())
+				return
+			}
+			changed = true
+		}
+		if changed {
+			cfg.SaveSoon()
+		}
+		if rotate {
+			s.farmer.RotateNow()
+		}
+		jsonResponse(w, s.settingsResponse())
 	default:
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 	}
 }
 
+// checkSettings runs the config checks over every field of a PUT
+// /api/settings body before anything is applied, so a bad value doesn't
+// leave the config half-updated.
+func checkSettings(req settingsRequest) error {
+	ints := []struct {
+		v     *int
+		check func(int) error
+	}{
+		{req.DropMinProgressPercent, config.CheckDropMinProgressPercent},
+		{req.DropCheckMinutes, config.CheckDropCheckMinutes},
+		{req.RotationIntervalMinutes, config.CheckRotationIntervalMinutes},
+		{req.StreakWindowMinutes, config.CheckStreakWindowMinutes},
+		{req.WebPort, config.CheckWebPort},
+	}
+	for _, f := range ints {
+		if f.v != nil {
+			if err := f.check(*f.v); err != nil {
+				return err
+			}
+		}
+	}
+	strs := []struct {
+		v     *string
+		check func(string) error
+	}{
+		{req.DropAutoSelect, config.CheckDropAutoSelect},
+		{req.Transport, config.CheckTransport},
+		{req.IrcMode, config.CheckIrcMode},
+		{req.NetworkProfile, config.CheckNetworkProfile},
+	}
+	for _, f := range strs {
+		if f.v != nil {
+			if err := f.check(*f.v); err != nil {
+				return err
+			}
+		}
+	}
+	if req.Schedule != nil {
+		if err := req.Schedule.Validate(); err != nil {
+			return fmt.Errorf("schedule: %w", err)
+		}
+	}
+	return nil
+}
+
 func formatDuration(d time.Duration) string {
 	h := int(d.Hours())
 	m := int(d.Minutes()) % 60
@@ -456,7 +1402,7 @@ func formatDuration(d time.Duration) string {
 // GET /api/games/search?q=tarkov[&limit=10] -> {"games": ["Escape from Tarkov", ...]}
 func (s *Server) handleGamesSearch(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 		return
 	}
 	q := r.URL.Query().Get("q")
@@ -472,12 +1418,36 @@ func (s *Server) handleGamesSearch(w http.ResponseWriter, r *http.Request) {
 	}
 	games, err := s.farmer.SearchGameCategories(q, limit)
 	if err != nil {
-		jsonError(w, "search failed: "+err.Error(), http.StatusBadGateway)
+		s.jsonError(w, r, http.StatusBadGateway, ErrCodeUpstream, "search failed: "+err.Error())
 		return
 	}
 	jsonResponse(w, map[string]interface{}{"games": games})
 }
 
+// handleBlacklist reads or replaces the auto-selection blacklist.
+// GET /api/blacklist, PUT /api/blacklist -> body: {"blacklist": ["somestreamer", "Some Game"]}
+func (s *Server) handleBlacklist(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Blacklist []string `json:"blacklist"`
+		}
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, "invalid JSON: "+err.Error())
+			return
+		}
+		if err := s.farmer.SetBlacklist(req.Blacklist); err != nil {
+			s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+	default:
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	jsonResponse(w, map[string]interface{}{"blacklist": s.farmer.Config().GetBlacklist()})
+}
+
 // handleWantedGames serves GET (list) and PUT (atomic replace) for the
 // v1.8.0 wanted_games config field.
 func (s *Server) handleWantedGames(w http.ResponseWriter, r *http.Request) {
@@ -493,18 +1463,15 @@ func (s *Server) handleWantedGames(w http.ResponseWriter, r *http.Request) {
 			Games []string `json:"games"`
 		}
 		if err := decodeJSONBody(w, r, &req); err != nil {
-			jsonError(w, "invalid JSON: "+err.Error(), http.StatusBadRequest)
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, "invalid JSON: "+err.Error())
 			return
 		}
 		cfg.SetGamesToWatch(req.Games)
-		if err := cfg.Save(); err != nil {
-			jsonError(w, "save failed: "+err.Error(), http.StatusInternalServerError)
-			return
-		}
+		cfg.SaveSoon()
 		jsonResponse(w, map[string]interface{}{
 			"games": cfg.GetGamesToWatch(),
 		})
 	default:
-		jsonError(w, "method not allowed", http.StatusMethodNotAllowed)
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
 	}
 }
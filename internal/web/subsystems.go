@@ -0,0 +1,61 @@
+package web
+
+import "net/http"
+
+// subsystemRequest is the POST /api/subsystems body.
+type subsystemRequest struct {
+	Name    string `json:"name"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// handleSubsystems lists the runtime-switchable subsystems (GET) or
+// switches one on or off (POST {"name": "irc", "enabled": false}); the
+// change is saved and applied without a restart. Both answer with the
+// full list.
+func (s *Server) handleSubsystems(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		jsonResponse(w, s.farmer.Subsystems())
+	case http.MethodPost:
+		var req subsystemRequest
+		if err := decodeJSONBody(w, r, &req); err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidBody, "invalid JSON body: "+err.Error())
+			return
+		}
+		if req.Enabled == nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeMissingParameter, "enabled is required")
+			return
+		}
+		if !s.isSubsystem(req.Name) {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "unknown subsystem "+req.Name)
+			return
+		}
+		if err := s.farmer.SetSubsystemEnabled(req.Name, *req.Enabled); err != nil {
+			s.jsonError(w, r, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+			return
+		}
+		jsonResponse(w, s.farmer.Subsystems())
+	default:
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+	}
+}
+
+// isSubsystem reports whether name is a runtime-switchable subsystem.
+func (s *Server) isSubsystem(name string) bool {
+	for _, sub := range s.farmer.Subsystems() {
+		if sub.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// subsystemEnabled reports a subsystem's configured switch.
+func (s *Server) subsystemEnabled(name string) bool {
+	for _, sub := range s.farmer.Subsystems() {
+		if sub.Name == name {
+			return sub.Enabled
+		}
+	}
+	return false
+}
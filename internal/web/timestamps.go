@@ -0,0 +1,103 @@
+package web
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// wantUTC reports whether the API response to r should carry UTC
+// timestamps: ?tz=utc or ?tz=local decide, else api_timestamps.
+func (s *Server) wantUTC(r *http.Request) bool {
+	switch strings.ToLower(r.URL.Query().Get("tz")) {
+	case "utc":
+		return true
+	case "local":
+		return false
+	}
+	return s.farmer.Config().GetAPITimestampsUTC()
+}
+
+// utcWriter is the ResponseWriter of an API request that wants UTC
+// timestamps; jsonResponse converts the times it serializes for it.
+type utcWriter struct {
+	http.ResponseWriter
+}
+
+// Flush passes through to the underlying writer, for streaming handlers.
+func (w utcWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w utcWriter) Unwrap() http.ResponseWriter { return w.ResponseWriter }
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// inUTC returns a copy of data with every time.Time it holds (in
+// exported fields, pointers, slices, maps and interfaces) in UTC.
+func inUTC(data interface{}) interface{} {
+	if data == nil {
+		return nil
+	}
+	return utcValue(reflect.ValueOf(data)).Interface()
+}
+
+func utcValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			return reflect.ValueOf(v.Interface().(time.Time).UTC())
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(utcValue(v.Field(i)))
+			}
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(utcValue(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(utcValue(v.Elem()))
+		return out
+	case reflect.Slice:
+		if v.IsNil() || v.Type().Elem().Kind() == reflect.Uint8 {
+			return v // nil stays null; []byte and json.RawMessage hold no times
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(utcValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(utcValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			out.SetMapIndex(it.Key(), utcValue(it.Value()))
+		}
+		return out
+	}
+	return v
+}
@@ -0,0 +1,56 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestInUTC: every timestamp in the response value comes out in UTC;
+// strings that merely look like one, and the caller's value, don't change.
+func TestInUTC(t *testing.T) {
+	zone := time.FixedZone("UTC+2", 2*60*60)
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, zone)
+	type row struct {
+		At    time.Time  `json:"at"`
+		Until *time.Time `json:"until"`
+		Title string     `json:"title"`
+	}
+	data := map[string]interface{}{
+		"rows":  []row{{At: at, Until: &at, Title: "2026-03-01T12:00:00+02:00"}},
+		"since": at,
+	}
+
+	b, err := json.Marshal(inUTC(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := string(b)
+	for _, want := range []string{`"at":"2026-03-01T10:00:00Z"`, `"until":"2026-03-01T10:00:00Z"`, `"since":"2026-03-01T10:00:00Z"`, `"title":"2026-03-01T12:00:00+02:00"`} {
+		if !strings.Contains(got, want) {
+			t.Errorf("%s lacks %s", got, want)
+		}
+	}
+	if data["rows"].([]row)[0].At.Location() != zone {
+		t.Error("inUTC changed the caller's value")
+	}
+}
+
+// TestJSONResponseUTC: jsonResponse converts only for a utcWriter.
+func TestJSONResponseUTC(t *testing.T) {
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60))
+
+	rec := httptest.NewRecorder()
+	jsonResponse(rec, map[string]time.Time{"at": at})
+	if !strings.Contains(rec.Body.String(), "+02:00") {
+		t.Errorf("local response = %s", rec.Body)
+	}
+
+	rec = httptest.NewRecorder()
+	jsonResponse(utcWriter{rec}, map[string]time.Time{"at": at})
+	if !strings.Contains(rec.Body.String(), `"2026-03-01T10:00:00Z"`) {
+		t.Errorf("UTC response = %s", rec.Body)
+	}
+}
@@ -0,0 +1,85 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/miwi/twitchpoint/internal/channels"
+	"github.com/miwi/twitchpoint/internal/drops"
+	"github.com/miwi/twitchpoint/internal/farmer"
+	"github.com/miwi/twitchpoint/internal/points"
+	"github.com/miwi/twitchpoint/internal/twitch"
+)
+
+// TUIState is the /api/tui response: everything the terminal UI renders,
+// in the farmer's own types, so a remote terminal UI (twitchpoint attach
+// <url>) can draw exactly what a local one would.
+type TUIState struct {
+	User            *twitch.UserInfo
+	Stats           farmer.Stats
+	Channels        []channels.Snapshot
+	Drops           []drops.ActiveDrop
+	Logs            []farmer.LogEntry // only entries at or after ?logs_after
+	Update          farmer.UpdateInfo
+	Redemptions     []points.Redemption
+	Daily           farmer.DailySummary
+	Comparison      farmer.Comparison
+	Reasons         *farmer.ReasonSummary // only with ?reasons=1
+	Games           []string
+	Blacklist       []string
+	Settings        SettingsResponse
+	CapacityWarning string
+}
+
+// handleTUI serves the remote terminal UI's poll.
+// GET /api/tui[?logs_after=<RFC 3339 nano>][&reasons=1] — with
+// logs_after only the log lines at or after that time are sent, so a
+// client polling every second doesn't re-download the whole buffer.
+// The bound is inclusive: lines logged in the same instant as the
+// client's last one, but after its poll, would otherwise be lost. The
+// reason breakdown, which queries the history, is only sent with
+// reasons=1, while the client shows it.
+func (s *Server) handleTUI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		s.jsonCodeError(w, r, http.StatusMethodNotAllowed, ErrCodeMethodNotAllowed)
+		return
+	}
+	var after time.Time
+	if v := r.URL.Query().Get("logs_after"); v != "" {
+		t, err := time.Parse(time.RFC3339Nano, v)
+		if err != nil {
+			s.jsonError(w, r, http.StatusBadRequest, ErrCodeInvalidParameter, "logs_after: "+err.Error())
+			return
+		}
+		after = t
+	}
+
+	logs := s.farmer.GetLogs()
+	start := len(logs)
+	for start > 0 && !logs[start-1].Time.Before(after) {
+		start--
+	}
+
+	var reasons *farmer.ReasonSummary
+	if r.URL.Query().Get("reasons") == "1" {
+		rs := s.farmer.GetReasonSummary()
+		reasons = &rs
+	}
+
+	jsonResponse(w, TUIState{
+		User:            s.farmer.GetUser(),
+		Stats:           s.farmer.GetStats(),
+		Channels:        s.farmer.GetChannels(),
+		Drops:           s.farmer.GetActiveDrops(),
+		Logs:            logs[start:],
+		Update:          s.farmer.GetUpdateInfo(),
+		Redemptions:     s.farmer.GetRedemptions(),
+		Daily:           s.farmer.GetDailySummary(),
+		Comparison:      s.farmer.GetComparison(),
+		Reasons:         reasons,
+		Games:           s.farmer.Config().GetGamesToWatch(),
+		Blacklist:       s.farmer.Config().GetBlacklist(),
+		Settings:        s.settingsResponse(),
+		CapacityWarning: s.farmer.CapacityWarning(),
+	})
+}